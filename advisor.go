@@ -0,0 +1,207 @@
+package ogame
+
+import (
+	"math"
+	"sort"
+)
+
+// advisorMaxSolarPlantLevels maximum solar plant levels added to a mine upgrade to keep the energy balance positive
+const advisorMaxSolarPlantLevels = 5
+
+// DefaultAdvisorTradeRate metal:crystal:deuterium value ratio used to compare the resources
+var DefaultAdvisorTradeRate = Resources{Metal: 3, Crystal: 2, Deuterium: 1}
+
+// AdvisorPlanet state of a planet used by the advisor
+type AdvisorPlanet struct {
+	ID          PlanetID
+	Coordinate  Coordinate
+	Temperature Temperature
+	Buildings   ResourcesBuildings
+	Settings    ResourceSettings
+	ActiveItems []ActiveItem // Active booster items
+}
+
+// AdvisorInputs empire state used to rank the upgrades
+type AdvisorInputs struct {
+	Planets         []AdvisorPlanet
+	Researches      Researches
+	UniverseSpeed   int64
+	Geologist       bool
+	Engineer        bool
+	CommandingStaff bool // All the officers are hired
+	Class           CharacterClass
+	TradeRate       Resources // DefaultAdvisorTradeRate if zero
+}
+
+// BuildRecommendation upgrade ranked by the advisor
+type BuildRecommendation struct {
+	PlanetID       PlanetID   // 0 for researches
+	Coordinate     Coordinate // Empty for researches
+	ID             ID
+	Level          int64     // Level reached by the upgrade
+	Cost           Resources // Includes the solar plants needed to power a mine, and the colony mines for astrophysics
+	ProductionGain Resources // Hourly production gained
+	PaybackHours   float64
+}
+
+// metalValue value of resources in metal, according to the trade rate
+func metalValue(res Resources, rate Resources) float64 {
+	return float64(res.Metal) +
+		float64(res.Crystal)*float64(rate.Metal)/float64(rate.Crystal) +
+		float64(res.Deuterium)*float64(rate.Metal)/float64(rate.Deuterium)
+}
+
+func (in AdvisorInputs) production(p AdvisorPlanet, buildings ResourcesBuildings, researches Researches) (Resources, int64) {
+	return computeAuditedProduction(ProductionAuditInputs{
+		Buildings:       buildings,
+		Settings:        p.Settings,
+		Researches:      researches,
+		Temperature:     p.Temperature,
+		UniverseSpeed:   in.UniverseSpeed,
+		Geologist:       in.Geologist,
+		Engineer:        in.Engineer,
+		CommandingStaff: in.CommandingStaff,
+		Collector:       in.Class.IsCollector(),
+		ActiveItems:     p.ActiveItems,
+	})
+}
+
+func (in AdvisorInputs) productionGain(before, after Resources) Resources {
+	return Resources{Metal: after.Metal - before.Metal, Crystal: after.Crystal - before.Crystal, Deuterium: after.Deuterium - before.Deuterium}
+}
+
+// mineUpgrade upgrades a mine of the planet, adding solar plant levels while the energy balance is negative
+func (in AdvisorInputs) mineUpgrade(p AdvisorPlanet, id ID) BuildRecommendation {
+	current, _ := in.production(p, p.Buildings, in.Researches)
+	buildings := p.Buildings
+	level := buildings.ByID(id) + 1
+	switch id {
+	case MetalMineID:
+		buildings.MetalMine = level
+	case CrystalMineID:
+		buildings.CrystalMine = level
+	case DeuteriumSynthesizerID:
+		buildings.DeuteriumSynthesizer = level
+	}
+	cost := Objs.ByID(id).GetPrice(level)
+	prod, energyUse := in.production(p, buildings, in.Researches)
+	for i := 0; i < advisorMaxSolarPlantLevels && prod.Energy < energyUse; i++ {
+		buildings.SolarPlant++
+		cost = cost.Add(SolarPlant.GetPrice(buildings.SolarPlant))
+		prod, energyUse = in.production(p, buildings, in.Researches)
+	}
+	return BuildRecommendation{PlanetID: p.ID, Coordinate: p.Coordinate, ID: id, Level: level, Cost: cost, ProductionGain: in.productionGain(current, prod)}
+}
+
+// plasmaUpgrade upgrades the plasma technology, the gain is the sum of every planet gain
+func (in AdvisorInputs) plasmaUpgrade() BuildRecommendation {
+	researches := in.Researches
+	researches.PlasmaTechnology++
+	gain := Resources{}
+	for _, p := range in.Planets {
+		before, _ := in.production(p, p.Buildings, in.Researches)
+		after, _ := in.production(p, p.Buildings, researches)
+		gain = gain.Add(in.productionGain(before, after))
+	}
+	level := researches.PlasmaTechnology
+	return BuildRecommendation{ID: PlasmaTechnologyID, Level: level, Cost: PlasmaTechnology.GetPrice(level), ProductionGain: gain}
+}
+
+// astrophysicsUpgrade upgrades astrophysics up to the next colony slot. The colony is assumed to produce as much as
+// the average planet, the cost includes building its mines and solar plant to the average levels.
+func (in AdvisorInputs) astrophysicsUpgrade() BuildRecommendation {
+	level := in.Researches.Astrophysics + 1
+	if level%2 == 0 {
+		level++
+	}
+	cost := Resources{}
+	for lvl := in.Researches.Astrophysics + 1; lvl <= level; lvl++ {
+		cost = cost.Add(Astrophysics.GetPrice(lvl))
+	}
+	avg := ResourcesBuildings{}
+	total := Resources{}
+	for _, p := range in.Planets {
+		avg.MetalMine += p.Buildings.MetalMine
+		avg.CrystalMine += p.Buildings.CrystalMine
+		avg.DeuteriumSynthesizer += p.Buildings.DeuteriumSynthesizer
+		avg.SolarPlant += p.Buildings.SolarPlant
+		prod, _ := in.production(p, p.Buildings, in.Researches)
+		total = total.Add(in.productionGain(Resources{}, prod))
+	}
+	nb := int64(len(in.Planets))
+	if nb > 0 {
+		avg.MetalMine /= nb
+		avg.CrystalMine /= nb
+		avg.DeuteriumSynthesizer /= nb
+		avg.SolarPlant /= nb
+		total = Resources{Metal: total.Metal / nb, Crystal: total.Crystal / nb, Deuterium: total.Deuterium / nb}
+	}
+	for _, id := range []ID{MetalMineID, CrystalMineID, DeuteriumSynthesizerID, SolarPlantID} {
+		for lvl := int64(1); lvl <= avg.ByID(id); lvl++ {
+			cost = cost.Add(Objs.ByID(id).GetPrice(lvl))
+		}
+	}
+	return BuildRecommendation{ID: AstrophysicsID, Level: level, Cost: cost, ProductionGain: total}
+}
+
+// RankBuilds ranks the mines, plasma technology and astrophysics upgrades of the empire by payback time, best first.
+// The payback time is the cost divided by the hourly production gained, both valued in metal with the trade rate.
+func RankBuilds(in AdvisorInputs) []BuildRecommendation {
+	rate := in.TradeRate
+	if rate.Metal <= 0 || rate.Crystal <= 0 || rate.Deuterium <= 0 {
+		rate = DefaultAdvisorTradeRate
+	}
+	candidates := make([]BuildRecommendation, 0)
+	for _, p := range in.Planets {
+		for _, id := range []ID{MetalMineID, CrystalMineID, DeuteriumSynthesizerID} {
+			candidates = append(candidates, in.mineUpgrade(p, id))
+		}
+	}
+	if len(in.Planets) > 0 {
+		candidates = append(candidates, in.plasmaUpgrade(), in.astrophysicsUpgrade())
+	}
+	res := make([]BuildRecommendation, 0)
+	for _, c := range candidates {
+		gain := metalValue(c.ProductionGain, rate)
+		if gain <= 0 {
+			continue
+		}
+		c.PaybackHours = math.Round(metalValue(c.Cost, rate)/gain*100) / 100
+		res = append(res, c)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].PaybackHours < res[j].PaybackHours })
+	return res
+}
+
+// getBuildRecommendations ranks the upgrades of the empire with the current buildings, settings, researches and officers
+func (b *OGame) getBuildRecommendations() ([]BuildRecommendation, error) {
+	officers := b.getOfficers()
+	in := AdvisorInputs{
+		Researches:      b.getCachedResearch(),
+		UniverseSpeed:   b.serverData.Speed,
+		Geologist:       officers.Geologist,
+		Engineer:        officers.Engineer,
+		CommandingStaff: officers.IsCommandingStaff(),
+		Class:           b.characterClass,
+	}
+	for _, planet := range b.GetCachedPlanets() {
+		buildings, err := b.getResourcesBuildings(planet.ID.Celestial())
+		if err != nil {
+			return nil, err
+		}
+		settings, err := b.getResourceSettings(planet.ID)
+		if err != nil {
+			return nil, err
+		}
+		activeItems, _ := b.getActiveItems(planet.ID.Celestial())
+		in.Planets = append(in.Planets, AdvisorPlanet{
+			ID:          planet.ID,
+			Coordinate:  planet.Coordinate,
+			Temperature: planet.Temperature,
+			Buildings:   buildings,
+			Settings:    settings,
+			ActiveItems: activeItems,
+		})
+	}
+	return RankBuilds(in), nil
+}
@@ -0,0 +1,72 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAdvisorTestInputs() AdvisorInputs {
+	return AdvisorInputs{
+		Planets: []AdvisorPlanet{{
+			ID:          1,
+			Coordinate:  Coordinate{1, 2, 8, PlanetType},
+			Temperature: Temperature{Min: 20, Max: 60},
+			Buildings:   ResourcesBuildings{MetalMine: 20, CrystalMine: 16, DeuteriumSynthesizer: 12, SolarPlant: 22},
+			Settings:    ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100},
+		}},
+		Researches:    Researches{PlasmaTechnology: 2, Astrophysics: 3, EnergyTechnology: 8},
+		UniverseSpeed: 1,
+	}
+}
+
+func TestMetalValue(t *testing.T) {
+	assert.Equal(t, 1000.0+1500.0+3000.0, metalValue(Resources{Metal: 1000, Crystal: 1000, Deuterium: 1000}, DefaultAdvisorTradeRate))
+}
+
+func TestRankBuilds(t *testing.T) {
+	res := RankBuilds(newAdvisorTestInputs())
+	assert.Equal(t, 5, len(res))
+	for i := 1; i < len(res); i++ {
+		assert.True(t, res[i-1].PaybackHours <= res[i].PaybackHours)
+	}
+	for _, r := range res {
+		assert.True(t, r.PaybackHours > 0)
+		if r.ID == AstrophysicsID {
+			assert.Equal(t, int64(5), r.Level)
+		}
+		if r.ID == MetalMineID {
+			assert.Equal(t, int64(21), r.Level)
+			assert.Equal(t, PlanetID(1), r.PlanetID)
+		}
+	}
+	assert.Equal(t, 0, len(RankBuilds(AdvisorInputs{})))
+}
+
+func TestRankBuildsEnergy(t *testing.T) {
+	in := newAdvisorTestInputs()
+	in.Planets[0].Buildings.SolarPlant = 17 // Just enough energy for the current mines
+	var metalMine BuildRecommendation
+	for _, r := range RankBuilds(in) {
+		if r.ID == MetalMineID {
+			metalMine = r
+		}
+	}
+	// The solar plant needed to power the new level is part of the cost
+	assert.True(t, metalMine.Cost.Metal > MetalMine.GetPrice(21).Metal)
+}
+
+func TestRankBuildsCollector(t *testing.T) {
+	gain := func(in AdvisorInputs) int64 {
+		for _, r := range RankBuilds(in) {
+			if r.ID == MetalMineID {
+				return r.ProductionGain.Metal
+			}
+		}
+		return 0
+	}
+	in := newAdvisorTestInputs()
+	collector := newAdvisorTestInputs()
+	collector.Class = Collector
+	assert.True(t, gain(collector) > gain(in))
+}
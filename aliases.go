@@ -0,0 +1,50 @@
+package ogame
+
+import "strings"
+
+// SetCelestialAlias defines an alias (eg: "main", "moonbase") that can be used instead of a celestial id
+// anywhere an interface{} celestial argument is accepted.
+func (b *OGame) SetCelestialAlias(alias string, celestialID CelestialID) {
+	b.aliasesMu.Lock()
+	defer b.aliasesMu.Unlock()
+	if b.aliases == nil {
+		b.aliases = make(map[string]CelestialID)
+	}
+	b.aliases[strings.ToLower(alias)] = celestialID
+}
+
+// RemoveCelestialAlias removes an alias
+func (b *OGame) RemoveCelestialAlias(alias string) {
+	b.aliasesMu.Lock()
+	defer b.aliasesMu.Unlock()
+	delete(b.aliases, strings.ToLower(alias))
+}
+
+// GetCelestialAliases returns a copy of all defined aliases
+func (b *OGame) GetCelestialAliases() map[string]CelestialID {
+	b.aliasesMu.RLock()
+	defer b.aliasesMu.RUnlock()
+	res := make(map[string]CelestialID, len(b.aliases))
+	for k, v := range b.aliases {
+		res[k] = v
+	}
+	return res
+}
+
+// ResolveCelestialAlias returns the celestial id for an alias
+func (b *OGame) ResolveCelestialAlias(alias string) (CelestialID, bool) {
+	b.aliasesMu.RLock()
+	defer b.aliasesMu.RUnlock()
+	id, ok := b.aliases[strings.ToLower(alias)]
+	return id, ok
+}
+
+// resolveAlias replaces a string alias by its celestial id, any other value is returned unchanged
+func (b *OGame) resolveAlias(v interface{}) interface{} {
+	if alias, ok := v.(string); ok {
+		if id, ok := b.ResolveCelestialAlias(alias); ok {
+			return int64(id)
+		}
+	}
+	return v
+}
@@ -0,0 +1,21 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCelestialAliases(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.SetCelestialAlias("Main", 123)
+	id, ok := b.ResolveCelestialAlias("main")
+	assert.True(t, ok)
+	assert.Equal(t, CelestialID(123), id)
+	assert.Equal(t, int64(123), b.resolveAlias("MAIN"))
+	assert.Equal(t, "1:2:3", b.resolveAlias("1:2:3"))
+	assert.Equal(t, map[string]CelestialID{"main": 123}, b.GetCelestialAliases())
+	b.RemoveCelestialAlias("main")
+	_, ok = b.ResolveCelestialAlias("main")
+	assert.False(t, ok)
+}
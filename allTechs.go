@@ -0,0 +1,48 @@
+package ogame
+
+// CelestialTechs supplies, facilities, ships and defenses of a celestial
+type CelestialTechs struct {
+	ID         CelestialID
+	Name       string
+	Coordinate Coordinate
+	Supplies   ResourcesBuildings
+	Facilities Facilities
+	Ships      ShipsInfos
+	Defenses   DefensesInfos
+}
+
+// AllTechs techs of every celestial of the empire and the researches
+type AllTechs struct {
+	Researches Researches
+	Celestials []CelestialTechs
+}
+
+// allTechsFromEmpire builds the techs of every celestial from the empire pages
+func allTechsFromEmpire(empire []EmpireCelestial) AllTechs {
+	res := AllTechs{Celestials: make([]CelestialTechs, 0, len(empire))}
+	for i, c := range empire {
+		if i == 0 {
+			res.Researches = c.Researches
+		}
+		res.Celestials = append(res.Celestials, CelestialTechs{
+			ID:         c.ID,
+			Name:       c.Name,
+			Coordinate: c.Coordinate,
+			Supplies:   c.Supplies,
+			Facilities: c.Facilities,
+			Ships:      c.Ships,
+			Defenses:   c.Defenses,
+		})
+	}
+	return res
+}
+
+// getAllTechs fetches the techs of every celestial. The empire pages (2 requests) are used with a commander,
+// otherwise the techs are fetched celestial by celestial.
+func (b *OGame) getAllTechs() (AllTechs, error) {
+	snapshot, err := b.fetchEmpire(false)
+	if err != nil {
+		return AllTechs{}, err
+	}
+	return allTechsFromEmpire(snapshot.Celestials), nil
+}
@@ -0,0 +1,24 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllTechsFromEmpire(t *testing.T) {
+	planetsHTML, _ := ioutil.ReadFile("samples/v8.1/en/empire_planets.html")
+	planets, _ := NewExtractorV6().ExtractEmpire(planetsHTML)
+	moonsHTML, _ := ioutil.ReadFile("samples/v8.1/en/empire_moons.html")
+	moons, _ := NewExtractorV6().ExtractEmpire(moonsHTML)
+	res := allTechsFromEmpire(append(planets, moons...))
+	assert.Equal(t, 11, len(res.Celestials))
+	assert.Equal(t, planets[0].Researches, res.Researches)
+	assert.Equal(t, planets[0].ID, res.Celestials[0].ID)
+	assert.Equal(t, planets[0].Supplies, res.Celestials[0].Supplies)
+	assert.Equal(t, Coordinate{Galaxy: 4, System: 116, Position: 9, Type: MoonType}, res.Celestials[8].Coordinate)
+	assert.Equal(t, moons[0].Facilities, res.Celestials[8].Facilities)
+
+	assert.Equal(t, 0, len(allTechsFromEmpire(nil).Celestials))
+}
@@ -0,0 +1,149 @@
+package ogame
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// MaxAuditEntries number of audit entries kept in memory
+const MaxAuditEntries = 10000
+
+// AuditParams parameters of an audited action
+type AuditParams map[string]interface{}
+
+// AuditEntry a mutating action executed by the bot
+type AuditEntry struct {
+	Time      time.Time
+	Initiator string
+	Action    string
+	Params    AuditParams
+	Success   bool
+	Error     string `json:",omitempty"`
+}
+
+// AuditLog append-only log of the mutating actions (send fleet, build, cancel, delete message, auction bid...).
+// When a file is used, each entry is appended as a JSON line and the previous entries are loaded on creation.
+type AuditLog struct {
+	sync.RWMutex
+	entries []AuditEntry
+	file    *os.File
+}
+
+func newAuditLog() *AuditLog {
+	return &AuditLog{entries: make([]AuditEntry, 0)}
+}
+
+// NewAuditLog creates an audit log backed by filename, an empty filename keeps the log in memory only
+func NewAuditLog(filename string) (*AuditLog, error) {
+	l := newAuditLog()
+	if filename == "" {
+		return l, nil
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			l.append(entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	l.file = f
+	return l, nil
+}
+
+func (l *AuditLog) append(entry AuditEntry) {
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > MaxAuditEntries {
+		l.entries = l.entries[len(l.entries)-MaxAuditEntries:]
+	}
+}
+
+// Append adds an entry to the log
+func (l *AuditLog) Append(entry AuditEntry) error {
+	l.Lock()
+	defer l.Unlock()
+	l.append(entry)
+	if l.file == nil {
+		return nil
+	}
+	by, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = l.file.Write(append(by, '\n'))
+	return err
+}
+
+// Entries returns the entries that happened after since, filtered by action if not empty
+func (l *AuditLog) Entries(since time.Time, action string) []AuditEntry {
+	l.RLock()
+	defer l.RUnlock()
+	res := make([]AuditEntry, 0)
+	for _, e := range l.entries {
+		if e.Time.Before(since) || (action != "" && e.Action != action) {
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// Close closes the file backing the log
+func (l *AuditLog) Close() error {
+	l.Lock()
+	defer l.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// audit records a mutating action with the initiator of the task
+func (b *Prioritize) audit(action string, err error, params AuditParams) {
+	l := b.bot.getAuditLog()
+	if l == nil {
+		return
+	}
+	entry := AuditEntry{Time: time.Now(), Initiator: b.initiator, Action: action, Params: params, Success: err == nil}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := l.Append(entry); err != nil {
+		b.bot.error("failed to write audit log: " + err.Error())
+	}
+}
+
+func (b *OGame) getAuditLog() *AuditLog {
+	b.auditLogMu.RLock()
+	defer b.auditLogMu.RUnlock()
+	return b.auditLog
+}
+
+// SetAuditLog replaces the audit log, nil disables auditing
+func (b *OGame) SetAuditLog(l *AuditLog) {
+	b.auditLogMu.Lock()
+	defer b.auditLogMu.Unlock()
+	b.auditLog = l
+}
+
+// GetAuditLog returns the audited actions that happened after since, filtered by action if not empty
+func (b *OGame) GetAuditLog(since time.Time, action string) []AuditEntry {
+	l := b.getAuditLog()
+	if l == nil {
+		return []AuditEntry{}
+	}
+	return l.Entries(since, action)
+}
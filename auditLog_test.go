@@ -0,0 +1,53 @@
+package ogame
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog_Entries(t *testing.T) {
+	l := newAuditLog()
+	now := time.Now()
+	_ = l.Append(AuditEntry{Time: now.Add(-time.Hour), Action: "Build", Success: true})
+	_ = l.Append(AuditEntry{Time: now, Action: "SendFleet", Success: false, Error: "no ships to send"})
+	assert.Equal(t, 2, len(l.Entries(time.Time{}, "")))
+	assert.Equal(t, 1, len(l.Entries(time.Time{}, "Build")))
+	entries := l.Entries(now.Add(-time.Minute), "")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "SendFleet", entries[0].Action)
+}
+
+func TestAuditLog_File(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "audit")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "audit.log")
+	l, err := NewAuditLog(filename)
+	assert.NoError(t, err)
+	_ = l.Append(AuditEntry{Time: time.Now(), Initiator: "farmer", Action: "CancelFleet", Params: AuditParams{"fleetID": 123}, Success: true})
+	assert.NoError(t, l.Close())
+
+	l, err = NewAuditLog(filename)
+	assert.NoError(t, err)
+	defer l.Close()
+	entries := l.Entries(time.Time{}, "")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "farmer", entries[0].Initiator)
+	assert.Equal(t, float64(123), entries[0].Params["fleetID"])
+}
+
+func TestPrioritize_Audit(t *testing.T) {
+	b := &OGame{auditLog: newAuditLog()}
+	p := &Prioritize{bot: b, initiator: "bot1"}
+	p.audit("DeleteMessage", errors.New("failed"), AuditParams{"msgID": int64(1)})
+	entries := b.GetAuditLog(time.Time{}, "")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "bot1", entries[0].Initiator)
+	assert.False(t, entries[0].Success)
+	assert.Equal(t, "failed", entries[0].Error)
+}
@@ -0,0 +1,180 @@
+package ogame
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultAutoABMInterval interval at which the event list is read for incoming missiles by the automatic ABM
+const DefaultAutoABMInterval = time.Minute
+
+// autoABMInitiator initiator of the constructions ordered by the automatic ABM
+const autoABMInitiator = "AutoABM"
+
+// Missile silo slots, every level holds 10 slots, an interplanetary missile takes 2 of them
+const (
+	missileSiloSlotsPerLevel int64 = 10
+	abmSiloSlots             int64 = 1
+	ipmSiloSlots             int64 = 2
+)
+
+// MissileDefense anti-ballistic missiles situation of a celestial targeted by interplanetary missiles
+type MissileDefense struct {
+	CelestialID           CelestialID
+	Coordinate            Coordinate
+	Incoming              int64 // Interplanetary missiles flying to the celestial
+	AntiBallisticMissiles int64
+	Queued                int64 // Anti-ballistic missiles in the shipyard queue
+	Needed                int64 // Anti-ballistic missiles missing to intercept every incoming missile
+	Built                 int64 // Anti-ballistic missiles ordered, less than Needed if the silo is full or resources are lacking
+	Err                   error
+}
+
+// abmToBuild returns the anti-ballistic missiles missing to intercept the incoming missiles, and how many of them fit
+// in the free slots of the missile silo. Queued missiles are counted as available and as using their slots.
+func abmToBuild(incoming, abm, ipm, queuedABM, queuedIPM, siloLevel int64) (needed, toBuild int64) {
+	needed = MaxInt(incoming-abm-queuedABM, 0)
+	used := (abm+queuedABM)*abmSiloSlots + (ipm+queuedIPM)*ipmSiloSlots
+	free := MaxInt(siloLevel*missileSiloSlotsPerLevel-used, 0) / abmSiloSlots
+	return needed, MinInt(needed, free)
+}
+
+// incomingMissiles returns the interplanetary missiles flying to each destination
+func incomingMissiles(attacks []AttackEvent) map[Coordinate]int64 {
+	res := make(map[Coordinate]int64)
+	for _, a := range attacks {
+		if a.MissionType == MissileAttack && a.Missiles > 0 {
+			res[a.Destination] += a.Missiles
+		}
+	}
+	return res
+}
+
+// defendAgainstMissiles orders the anti-ballistic missiles needed on the celestial, as far as the missile silo and the
+// resources allow
+func (b *OGame) defendAgainstMissiles(celestialID CelestialID, coord Coordinate, incoming int64) MissileDefense {
+	res := MissileDefense{CelestialID: celestialID, Coordinate: coord, Incoming: incoming}
+	res.Err = b.WithPriority(Critical).SetInitiator(autoABMInitiator).Tx(func(tx Prioritizable) error {
+		defenses, err := tx.GetDefense(celestialID)
+		if err != nil {
+			return err
+		}
+		facilities, err := tx.GetFacilities(celestialID)
+		if err != nil {
+			return err
+		}
+		production, _, err := tx.GetProduction(celestialID)
+		if err != nil {
+			return err
+		}
+		var queuedABM, queuedIPM int64
+		for _, item := range production {
+			switch item.ID {
+			case AntiBallisticMissilesID:
+				queuedABM += item.Nbr
+			case InterplanetaryMissilesID:
+				queuedIPM += item.Nbr
+			}
+		}
+		res.AntiBallisticMissiles = defenses.AntiBallisticMissiles
+		res.Queued = queuedABM
+		needed, toBuild := abmToBuild(incoming, defenses.AntiBallisticMissiles, defenses.InterplanetaryMissiles,
+			queuedABM, queuedIPM, facilities.MissileSilo)
+		res.Needed = needed
+		if toBuild <= 0 {
+			return nil
+		}
+		resources, err := tx.GetResources(celestialID)
+		if err != nil {
+			return err
+		}
+		toBuild = MinInt(toBuild, resources.Div(AntiBallisticMissiles.GetPrice(1)))
+		if toBuild <= 0 {
+			return nil
+		}
+		if err := tx.BuildDefense(celestialID, AntiBallisticMissilesID, toBuild); err != nil {
+			return err
+		}
+		res.Built = toBuild
+		return nil
+	})
+	return res
+}
+
+// checkIncomingMissiles reads the event list and defends the celestials targeted by new missile attacks.
+// handled holds the ids of the attacks already processed, the ones no longer in the event list are forgotten. An
+// attack is only handled once the defense of its target succeeded, a failed defense is retried on the next check.
+func (b *OGame) checkIncomingMissiles(handled map[int64]bool) ([]MissileDefense, error) {
+	attacks, err := b.WithPriority(Critical).GetAttacks()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool)
+	newAttacks := make(map[Coordinate][]int64)
+	coords := make([]Coordinate, 0) // Targets of the new attacks, in arrival order
+	for _, a := range attacks {
+		if a.MissionType != MissileAttack || a.Missiles <= 0 {
+			continue
+		}
+		seen[a.ID] = true
+		if !handled[a.ID] {
+			if _, ok := newAttacks[a.Destination]; !ok {
+				coords = append(coords, a.Destination)
+			}
+			newAttacks[a.Destination] = append(newAttacks[a.Destination], a.ID)
+		}
+	}
+	for id := range handled {
+		if !seen[id] {
+			delete(handled, id)
+		}
+	}
+	// Every missile flying to a celestial is counted, the missiles of the attacks already handled are covered by
+	// the anti-ballistic missiles ordered before
+	incoming := incomingMissiles(attacks)
+	res := make([]MissileDefense, 0)
+	for _, coord := range coords {
+		celestial := b.GetCachedCelestial(coord)
+		if celestial == nil {
+			continue
+		}
+		defense := b.defendAgainstMissiles(celestial.GetID(), coord, incoming[coord])
+		if defense.Err == nil {
+			for _, id := range newAttacks[coord] {
+				handled[id] = true
+			}
+		}
+		res = append(res, defense)
+	}
+	return res, nil
+}
+
+// StartAutoABM reads the event list every interval until the returned function is called. When interplanetary
+// missiles are flying to one of the celestials, the anti-ballistic missiles needed to intercept them are ordered on
+// it as far as its missile silo and resources allow. A missile.defense event is emitted for every new missile attack,
+// whether anti-ballistic missiles were ordered or not, and for every retry of a failed defense.
+func (b *OGame) StartAutoABM(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultAutoABMInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		handled := make(map[int64]bool)
+		for {
+			if b.isEnabled() && b.IsLoggedIn() {
+				if defenses, err := b.checkIncomingMissiles(handled); err == nil {
+					for _, d := range defenses {
+						b.emitEvent(NewMissileDefenseEvent(d))
+					}
+				}
+			}
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,61 @@
+package ogame
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbmToBuild(t *testing.T) {
+	// Enough ABM
+	needed, toBuild := abmToBuild(5, 5, 0, 0, 0, 1)
+	assert.Equal(t, int64(0), needed)
+	assert.Equal(t, int64(0), toBuild)
+
+	// Queued ABM are counted
+	needed, toBuild = abmToBuild(8, 3, 0, 2, 0, 2)
+	assert.Equal(t, int64(3), needed)
+	assert.Equal(t, int64(3), toBuild)
+
+	// Silo level 2: 20 slots, 3 ABM + 5 IPM use 13 of them
+	needed, toBuild = abmToBuild(20, 3, 5, 0, 0, 2)
+	assert.Equal(t, int64(17), needed)
+	assert.Equal(t, int64(7), toBuild)
+
+	// Queued IPM use their slots
+	needed, toBuild = abmToBuild(20, 0, 0, 0, 5, 1)
+	assert.Equal(t, int64(20), needed)
+	assert.Equal(t, int64(0), toBuild)
+
+	// No silo
+	needed, toBuild = abmToBuild(4, 0, 0, 0, 0, 0)
+	assert.Equal(t, int64(4), needed)
+	assert.Equal(t, int64(0), toBuild)
+}
+
+func TestIncomingMissiles(t *testing.T) {
+	planet := Coordinate{1, 2, 3, PlanetType}
+	moon := Coordinate{1, 2, 3, MoonType}
+	res := incomingMissiles([]AttackEvent{
+		{ID: 1, MissionType: MissileAttack, Destination: planet, Missiles: 10},
+		{ID: 2, MissionType: Attack, Destination: planet},
+		{ID: 3, MissionType: MissileAttack, Destination: planet, Missiles: 4},
+		{ID: 4, MissionType: MissileAttack, Destination: moon, Missiles: 2},
+	})
+	assert.Equal(t, map[Coordinate]int64{planet: 14, moon: 2}, res)
+}
+
+func TestNewMissileDefenseEvent_JSON(t *testing.T) {
+	e := NewMissileDefenseEvent(MissileDefense{CelestialID: 123, Coordinate: Coordinate{1, 2, 3, PlanetType},
+		Incoming: 10, AntiBallisticMissiles: 2, Queued: 1, Needed: 7, Built: 5})
+	assert.Equal(t, MissileDefenseEvent, e.Type)
+	by, err := json.Marshal(e.Data)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"celestial_id":123,"coordinate":{"galaxy":1,"system":2,"position":3,"type":"planet"},
+		"incoming":10,"anti_ballistic_missiles":2,"queued":1,"needed":7,"built":5,"error":""}`, string(by))
+
+	e = NewMissileDefenseEvent(MissileDefense{CelestialID: 123, Err: errors.New("not enough resources")})
+	assert.Equal(t, "not enough resources", e.Data.(EventMissileDefenseData).Error)
+}
@@ -0,0 +1,201 @@
+package ogame
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultAutoRecyclersInterval interval at which the combat reports are read by the automatic recyclers
+const DefaultAutoRecyclersInterval = 5 * time.Minute
+
+// autoRecyclersInitiator initiator of the fleets sent by the automatic recyclers
+const autoRecyclersInitiator = "AutoRecyclers"
+
+// AutoRecyclersParams parameters of the automatic recyclers
+type AutoRecyclersParams struct {
+	Threshold int64         // Metal and crystal of the debris field from which recyclers are sent
+	Reserve   int64         // Recyclers kept on every celestial
+	Interval  time.Duration // DefaultAutoRecyclersInterval if 0
+}
+
+// RecyclersDispatch recyclers sent to the debris field of a combat
+type RecyclersDispatch struct {
+	ReportID    int64 // Combat report
+	Coordinate  Coordinate
+	Debris      Resources   // Debris field found in the galaxy
+	Needed      int64       // Recyclers needed to harvest the debris field
+	Sent        int64       // Less than Needed if the closest celestial with recyclers has not enough of them
+	CelestialID CelestialID // Celestial the recyclers were sent from
+	FleetID     FleetID
+	Err         error
+}
+
+// recyclersToSend returns the recyclers to send to harvest debris, at most the available ones above the reserve
+func recyclersToSend(debris, cargo, available, reserve int64) (needed, toSend int64) {
+	if cargo <= 0 {
+		return 0, 0
+	}
+	needed = (debris + cargo - 1) / cargo
+	return needed, MaxInt(MinInt(needed, available-reserve), 0)
+}
+
+// celestialsByDistance returns the celestials sorted by distance to a coordinate, closest first
+func (b *OGame) celestialsByDistance(coord Coordinate) []Celestial {
+	celestials := b.GetCachedCelestials()
+	sort.SliceStable(celestials, func(i, j int) bool {
+		return b.Distance(celestials[i].GetCoordinate(), coord) < b.Distance(celestials[j].GetCoordinate(), coord)
+	})
+	return celestials
+}
+
+// recyclersFlying returns true if recyclers already fly to the debris field
+func recyclersFlying(fleets []Fleet, coord Coordinate) bool {
+	for _, f := range fleets {
+		if f.Mission == RecycleDebrisField && !f.ReturnFlight && f.Destination.Equal(coord.Debris()) {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchRecyclers sends recyclers to the debris field of a combat report from the closest celestial having some
+// above the reserve. Nothing is sent if the debris field is under the threshold, eg: already harvested, or if recyclers
+// already fly to it. done is false when the report has to be checked again: the galaxy or the fleets could not be
+// read, or the recyclers could not be sent.
+func (b *OGame) dispatchRecyclers(report CombatReportSummary, params AutoRecyclersParams) (res RecyclersDispatch, done bool) {
+	res = RecyclersDispatch{ReportID: report.ID, Coordinate: report.Destination.Debris()}
+	res.Err = b.WithPriority(Normal).SetInitiator(autoRecyclersInitiator).Tx(func(tx Prioritizable) error {
+		dest := report.Destination
+		systemInfos, err := tx.GalaxyInfos(dest.Galaxy, dest.System)
+		if err != nil {
+			return err
+		}
+		planetInfos := systemInfos.Position(dest.Position)
+		if planetInfos == nil {
+			done = true
+			return nil
+		}
+		res.Debris = Resources{Metal: planetInfos.Debris.Metal, Crystal: planetInfos.Debris.Crystal}
+		if res.Debris.Metal+res.Debris.Crystal < params.Threshold {
+			done = true
+			return nil
+		}
+		fleets, _ := tx.GetFleets()
+		if recyclersFlying(fleets, dest) {
+			done = true
+			return nil
+		}
+		cargo := b.fleetCargo(ShipsInfos{Recycler: 1})
+		for _, celestial := range b.celestialsByDistance(dest) {
+			ships, err := tx.GetShips(celestial.GetID())
+			if err != nil {
+				return err
+			}
+			needed, toSend := recyclersToSend(res.Debris.Metal+res.Debris.Crystal, cargo, ships.Recycler, params.Reserve)
+			res.Needed = needed
+			if toSend <= 0 {
+				continue
+			}
+			fleet, err := tx.SendFleet(celestial.GetID(), []Quantifiable{{ID: RecyclerID, Nbr: toSend}}, HundredPercent,
+				res.Coordinate, RecycleDebrisField, Resources{}, 0, 0)
+			if err != nil {
+				return err
+			}
+			res.Sent = toSend
+			res.CelestialID = celestial.GetID()
+			res.FleetID = fleet.ID
+			done = true
+			return nil
+		}
+		return errors.New("no recyclers available above the reserve")
+	})
+	if res.Err != nil {
+		done = false
+	}
+	return res, done
+}
+
+// autoRecyclersState combat reports processed by the automatic recyclers
+type autoRecyclersState struct {
+	handled map[int64]bool // Recyclers sent, or nothing to send
+	failed  map[int64]bool // Dispatch failed once, the failure was reported and the report is retried silently
+}
+
+func newAutoRecyclersState() *autoRecyclersState {
+	return &autoRecyclersState{handled: make(map[int64]bool), failed: make(map[int64]bool)}
+}
+
+// handleCombatReports dispatches the recyclers of the reports not handled yet, when seed is true the reports are only
+// marked as handled. A report is handled once recyclers were sent or its debris field is definitively below the
+// threshold, the failed dispatches are retried on the next check and only their first failure is returned.
+func (s *autoRecyclersState) handleCombatReports(reports []CombatReportSummary, params AutoRecyclersParams, seed bool,
+	dispatch func(CombatReportSummary) (RecyclersDispatch, bool)) []RecyclersDispatch {
+	res := make([]RecyclersDispatch, 0)
+	for _, report := range reports {
+		if s.handled[report.ID] {
+			continue
+		}
+		if seed || report.DebrisField < params.Threshold || report.DebrisField <= 0 {
+			s.handled[report.ID] = true
+			continue
+		}
+		d, done := dispatch(report)
+		if done {
+			s.handled[report.ID] = true
+			delete(s.failed, report.ID)
+		}
+		if d.Sent > 0 || (d.Err != nil && !s.failed[report.ID]) {
+			res = append(res, d)
+		}
+		if d.Err != nil {
+			s.failed[report.ID] = true
+		}
+	}
+	return res
+}
+
+// checkCombatReports reads the combat reports and sends recyclers to the debris fields of the new ones
+func (b *OGame) checkCombatReports(state *autoRecyclersState, params AutoRecyclersParams, seed bool) ([]RecyclersDispatch, error) {
+	reports, err := b.WithPriority(Normal).GetCombatReportMessages()
+	if err != nil {
+		return nil, err
+	}
+	return state.handleCombatReports(reports, params, seed, func(report CombatReportSummary) (RecyclersDispatch, bool) {
+		return b.dispatchRecyclers(report, params)
+	}), nil
+}
+
+// StartAutoRecyclers reads the combat reports every interval until the returned function is called. The combat
+// reports are the ones of the combats the bot was attacker or defender of. When a new combat left a debris field of
+// at least the threshold, the recyclers needed to harvest it are sent from the closest celestial that has recyclers
+// above the reserve. The reports present when started are ignored. A recyclers.dispatched event is emitted for every
+// debris field recyclers were sent to, or failed to be sent to, the failed dispatches are retried every interval.
+func (b *OGame) StartAutoRecyclers(params AutoRecyclersParams) (stop func()) {
+	if params.Interval <= 0 {
+		params.Interval = DefaultAutoRecyclersInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		state := newAutoRecyclersState()
+		seeded := false
+		for {
+			if b.isEnabled() && b.IsLoggedIn() {
+				if dispatches, err := b.checkCombatReports(state, params, !seeded); err == nil {
+					seeded = true
+					for _, d := range dispatches {
+						b.emitEvent(NewRecyclersDispatchedEvent(d))
+					}
+				}
+			}
+			select {
+			case <-time.After(params.Interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
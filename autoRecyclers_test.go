@@ -0,0 +1,99 @@
+package ogame
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecyclersToSend(t *testing.T) {
+	needed, toSend := recyclersToSend(50001, 20000, 10, 2)
+	assert.Equal(t, int64(3), needed)
+	assert.Equal(t, int64(3), toSend)
+
+	// The reserve is kept
+	needed, toSend = recyclersToSend(100000, 20000, 6, 2)
+	assert.Equal(t, int64(5), needed)
+	assert.Equal(t, int64(4), toSend)
+
+	needed, toSend = recyclersToSend(100000, 20000, 1, 2)
+	assert.Equal(t, int64(5), needed)
+	assert.Equal(t, int64(0), toSend)
+
+	needed, toSend = recyclersToSend(100000, 0, 10, 0)
+	assert.Equal(t, int64(0), needed)
+	assert.Equal(t, int64(0), toSend)
+}
+
+func TestRecyclersFlying(t *testing.T) {
+	coord := Coordinate{1, 2, 3, PlanetType}
+	fleets := []Fleet{
+		{Mission: Attack, Destination: coord},
+		{Mission: RecycleDebrisField, Destination: coord.Debris(), ReturnFlight: true},
+	}
+	assert.False(t, recyclersFlying(fleets, coord))
+	fleets = append(fleets, Fleet{Mission: RecycleDebrisField, Destination: coord.Debris()})
+	assert.True(t, recyclersFlying(fleets, coord))
+	assert.False(t, recyclersFlying(fleets, Coordinate{1, 2, 4, PlanetType}))
+}
+
+func TestCelestialsByDistance(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.serverData.Galaxies = 9
+	b.serverData.Systems = 499
+	b.planets = []Planet{
+		{ID: 1, Coordinate: Coordinate{2, 100, 8, PlanetType}},
+		{ID: 2, Coordinate: Coordinate{1, 240, 8, PlanetType}},
+		{ID: 3, Coordinate: Coordinate{1, 150, 8, PlanetType}},
+	}
+	celestials := b.celestialsByDistance(Coordinate{1, 200, 4, PlanetType})
+	ids := make([]CelestialID, 0)
+	for _, c := range celestials {
+		ids = append(ids, c.GetID())
+	}
+	assert.Equal(t, []CelestialID{2, 3, 1}, ids)
+}
+
+func TestNewRecyclersDispatchedEvent_JSON(t *testing.T) {
+	e := NewRecyclersDispatchedEvent(RecyclersDispatch{ReportID: 42, Coordinate: Coordinate{1, 2, 3, DebrisType},
+		Debris: Resources{Metal: 60000, Crystal: 20000}, Needed: 4, Sent: 3, CelestialID: 123, FleetID: 7})
+	assert.Equal(t, RecyclersDispatchedEvent, e.Type)
+	by, err := json.Marshal(e.Data)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"report_id":42,"coordinate":{"galaxy":1,"system":2,"position":3,"type":"debris"},
+		"debris_metal":60000,"debris_crystal":20000,"needed":4,"sent":3,"celestial_id":123,"fleet_id":7,"error":""}`, string(by))
+
+	e = NewRecyclersDispatchedEvent(RecyclersDispatch{ReportID: 42, Err: errors.New("no recyclers available above the reserve")})
+	assert.Equal(t, "no recyclers available above the reserve", e.Data.(EventRecyclersDispatchedData).Error)
+}
+
+func TestHandleCombatReports(t *testing.T) {
+	params := AutoRecyclersParams{Threshold: 10000}
+	state := newAutoRecyclersState()
+	reports := []CombatReportSummary{{ID: 1, DebrisField: 50000}, {ID: 2, DebrisField: 500}}
+	assert.Equal(t, 0, len(state.handleCombatReports(reports, params, true, nil)))
+
+	reports = append(reports, CombatReportSummary{ID: 3, DebrisField: 50000}, CombatReportSummary{ID: 4, DebrisField: 80000})
+	calls := make(map[int64]int)
+	sendErr := errors.New("no recyclers available above the reserve")
+	dispatch := func(report CombatReportSummary) (RecyclersDispatch, bool) {
+		calls[report.ID]++
+		if report.ID == 3 && calls[report.ID] < 3 {
+			return RecyclersDispatch{ReportID: report.ID, Err: sendErr}, false
+		}
+		if report.ID == 4 {
+			return RecyclersDispatch{ReportID: report.ID}, true // Already harvested
+		}
+		return RecyclersDispatch{ReportID: report.ID, Sent: 3}, true
+	}
+	// The failure is reported once, the report is retried until the recyclers are sent
+	dispatches := state.handleCombatReports(reports, params, false, dispatch)
+	assert.Equal(t, []RecyclersDispatch{{ReportID: 3, Err: sendErr}}, dispatches)
+	assert.Equal(t, 0, len(state.handleCombatReports(reports, params, false, dispatch)))
+	dispatches = state.handleCombatReports(reports, params, false, dispatch)
+	assert.Equal(t, []RecyclersDispatch{{ReportID: 3, Sent: 3}}, dispatches)
+	assert.Equal(t, 0, len(state.handleCombatReports(reports, params, false, dispatch)))
+	assert.Equal(t, map[int64]int{3: 3, 4: 1}, calls)
+}
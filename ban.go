@@ -0,0 +1,107 @@
+package ogame
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// AccountBanError returned when the lobby reports the account as banned or locked, and notified when the account is
+// put in vacation mode. Reason and Until are filled when they are known.
+type AccountBanError struct {
+	Err    error // ErrAccountBanned, ErrAccountLocked or ErrAccountVacationForced
+	Reason string
+	Until  time.Time // Zero value when the ban is permanent or the expiry is unknown
+}
+
+func (e *AccountBanError) Error() string {
+	msg := e.Err.Error()
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if !e.Until.IsZero() {
+		msg += " (until " + e.Until.Format(time.RFC3339) + ")"
+	}
+	return msg
+}
+
+// Cause returns the underlying sentinel error, compatible with errors.Cause
+func (e *AccountBanError) Cause() error {
+	return e.Err
+}
+
+// IsAccountBanError returns the ban details if err is (or wraps) an AccountBanError
+func IsAccountBanError(err error) (*AccountBanError, bool) {
+	type causer interface{ Cause() error }
+	for err != nil {
+		if banErr, ok := err.(*AccountBanError); ok {
+			return banErr, true
+		}
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return nil, false
+}
+
+var banDateRgx = regexp.MustCompile(`(\d{2})\.(\d{2})\.(\d{4}) (\d{2}):(\d{2}):(\d{2})`)
+
+// accountBanFromLobby returns the ban of a lobby account, nil if it can be played. The lobby reports the bans with
+// their reason and expiry, and the accounts locked by the game operators as blocked.
+func accountBanFromLobby(a account) *AccountBanError {
+	if a.BannedUntil != nil || a.BannedReason != nil {
+		res := &AccountBanError{Err: ErrAccountBanned}
+		if a.BannedReason != nil {
+			res.Reason = *a.BannedReason
+		}
+		if a.BannedUntil != nil {
+			res.Until = parseLobbyTime(*a.BannedUntil)
+		}
+		return res
+	}
+	if a.Blocked {
+		return &AccountBanError{Err: ErrAccountLocked}
+	}
+	return nil
+}
+
+// extractVacationUntil returns the end of the vacation mode shown by the advice of the page,
+// zero if the account is not in vacation mode or the date is not displayed
+func extractVacationUntil(doc *goquery.Document, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	title := doc.Find("div#advice-bar a").AttrOr("title", "")
+	if m := banDateRgx.FindStringSubmatch(title); len(m) == 7 {
+		until, _ := time.ParseInLocation("02.01.2006 15:04:05", m[0], loc)
+		return until
+	}
+	return time.Time{}
+}
+
+// updateVacationMode records the vacation mode of a full page. The bot never enables the vacation mode itself, so the
+// account entering it is reported as forced (game operators, lobby validation, or the owner playing elsewhere).
+func (b *OGame) updateVacationMode(doc *goquery.Document) {
+	enabled := b.extractor.ExtractIsInVacationFromDoc(doc)
+	wasEnabled := b.isVacationModeEnabled
+	b.isVacationModeEnabled = enabled
+	if enabled && !wasEnabled {
+		b.notifyAccountBanned(&AccountBanError{Err: ErrAccountVacationForced, Until: extractVacationUntil(doc, b.location)})
+	}
+}
+
+// OnAccountBanned register a callback that is notified when the bot detects that the account is banned, locked or put
+// in vacation mode
+func (b *OGame) OnAccountBanned(clb func(err *AccountBanError)) {
+	b.accountBannedCallbacks = append(b.accountBannedCallbacks, clb)
+}
+
+func (b *OGame) notifyAccountBanned(err *AccountBanError) {
+	b.critical(err.Error())
+	for _, clb := range b.accountBannedCallbacks {
+		clb(err)
+	}
+}
@@ -0,0 +1,66 @@
+package ogame
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountBanFromLobby(t *testing.T) {
+	var accounts []account
+	by := []byte(`[
+		{"server":{"language":"en","number":1},"id":1,"name":"a","blocked":false,"bannedUntil":null,"bannedReason":null},
+		{"server":{"language":"en","number":2},"id":2,"name":"b","blocked":false,"bannedUntil":"2021-03-21T10:15:00+0000","bannedReason":"Botting"},
+		{"server":{"language":"en","number":3},"id":3,"name":"c","blocked":true,"bannedUntil":null,"bannedReason":null}
+	]`)
+	assert.NoError(t, json.Unmarshal(by, &accounts))
+
+	assert.Nil(t, accountBanFromLobby(accounts[0]))
+
+	banErr := accountBanFromLobby(accounts[1])
+	assert.NotNil(t, banErr)
+	assert.Equal(t, ErrAccountBanned, banErr.Err)
+	assert.Equal(t, "Botting", banErr.Reason)
+	assert.True(t, time.Date(2021, 3, 21, 10, 15, 0, 0, time.UTC).Equal(banErr.Until))
+
+	banErr = accountBanFromLobby(accounts[2])
+	assert.NotNil(t, banErr)
+	assert.Equal(t, ErrAccountLocked, banErr.Err)
+	assert.True(t, banErr.Until.IsZero())
+}
+
+func TestUpdateVacationMode(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.extractor = NewExtractorV6()
+	b.location = time.UTC
+	var notified []*AccountBanError
+	b.OnAccountBanned(func(err *AccountBanError) { notified = append(notified, err) })
+
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/es/shipyard.html")
+	doc, _ := goquery.NewDocumentFromReader(bytes.NewReader(pageHTMLBytes))
+	b.updateVacationMode(doc)
+	assert.False(t, b.isVacationModeEnabled)
+	assert.Equal(t, 0, len(notified))
+
+	pageHTMLBytes, _ = ioutil.ReadFile("samples/es/overview_vacation.html")
+	doc, _ = goquery.NewDocumentFromReader(bytes.NewReader(pageHTMLBytes))
+	b.updateVacationMode(doc)
+	b.updateVacationMode(doc)
+	assert.True(t, b.isVacationModeEnabled)
+	assert.Equal(t, 1, len(notified))
+	assert.Equal(t, ErrAccountVacationForced, notified[0].Err)
+	assert.Equal(t, time.Date(2019, 9, 4, 7, 54, 28, 0, time.UTC), notified[0].Until)
+}
+
+func TestIsAccountBanError(t *testing.T) {
+	_, ok := IsAccountBanError(ErrNotLogged)
+	assert.False(t, ok)
+	banErr, ok := IsAccountBanError(&AccountBanError{Err: ErrAccountLocked})
+	assert.True(t, ok)
+	assert.Equal(t, ErrAccountLocked, banErr.Cause())
+}
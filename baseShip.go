@@ -1,5 +1,11 @@
 package ogame
 
+// Cargo bonus in percent per hyperspace technology level
+const (
+	DefaultCargoHyperspaceTechMultiplier  int64 = 5
+	PioneersCargoHyperspaceTechMultiplier int64 = 2
+)
+
 // BaseShip base struct for ships
 type BaseShip struct {
 	BaseDefender
@@ -10,16 +16,26 @@ type BaseShip struct {
 
 // GetCargoCapacity returns ship cargo capacity
 func (b BaseShip) GetCargoCapacity(techs Researches, probeRaids, isCollector, isPioneers bool) int64 {
-	if b.GetID() == EspionageProbeID && !probeRaids {
-		return 0
-	}
-	hyperspaceBonus := 0.05
+	return cargoCapacity(b.ID, b.BaseCargoCapacity, techs, probeRaids, isCollector, defaultCargoHyperspaceTechMultiplier(isPioneers))
+}
+
+// defaultCargoHyperspaceTechMultiplier returns the cargo bonus per hyperspace technology level of the servers that
+// do not report their cargoHyperspaceTechMultiplier setting
+func defaultCargoHyperspaceTechMultiplier(isPioneers bool) int64 {
 	if isPioneers {
-		hyperspaceBonus = 0.02
+		return PioneersCargoHyperspaceTechMultiplier
 	}
-	cargo := b.BaseCargoCapacity + int64(float64(b.BaseCargoCapacity*techs.HyperspaceTechnology)*hyperspaceBonus)
-	if isCollector && (b.ID == SmallCargoID || b.ID == LargeCargoID) {
-		cargo += int64(float64(b.BaseCargoCapacity) * 0.25)
+	return DefaultCargoHyperspaceTechMultiplier
+}
+
+// cargoCapacity hyperspaceMultiplier is the cargo bonus in percent per hyperspace technology level
+func cargoCapacity(id ID, baseCargoCapacity int64, techs Researches, probeRaids, isCollector bool, hyperspaceMultiplier int64) int64 {
+	if id == EspionageProbeID && !probeRaids {
+		return 0
+	}
+	cargo := baseCargoCapacity + baseCargoCapacity*techs.HyperspaceTechnology*hyperspaceMultiplier/100
+	if isCollector && (id == SmallCargoID || id == LargeCargoID) {
+		cargo += int64(float64(baseCargoCapacity) * 0.25)
 	}
 	return cargo
 }
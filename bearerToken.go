@@ -0,0 +1,123 @@
+package ogame
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	cookiejar "github.com/orirawlings/persistent-cookiejar"
+)
+
+// Bearer token lifecycle defaults
+const (
+	// DefaultBearerTokenLifetime estimated validity of a gameforge bearer token, the lobby does not return it
+	DefaultBearerTokenLifetime = 24 * time.Hour
+	// DefaultBearerTokenRefreshMargin time before the expiry at which StartBearerTokenRefresher gets a new token
+	DefaultBearerTokenRefreshMargin = time.Hour
+	bearerTokenCheckInterval        = 5 * time.Minute
+)
+
+// bearerTokenCookieMaxLifetime cookies expiring after this are session cookies, their expiry is unknown
+const bearerTokenCookieMaxLifetime = 10 * 365 * 24 * time.Hour
+
+// setBearerToken stores the token and its expiry, and saves it in the gf-token cookie so it is persisted with
+// the cookies and reused after a restart
+func (b *OGame) setBearerToken(token string, expiresAt time.Time) {
+	b.bearerTokenMu.Lock()
+	b.bearerToken = token
+	b.bearerTokenExpiresAt = expiresAt
+	b.bearerTokenMu.Unlock()
+	if b.Client == nil || b.Client.Jar == nil {
+		return
+	}
+	u, _ := url.Parse("https://gameforge.com")
+	cookies := b.Client.Jar.Cookies(u)
+	cookie := &http.Cookie{
+		Name:    gfTokenCookieName,
+		Value:   token,
+		Path:    "/",
+		Domain:  ".gameforge.com",
+		Expires: expiresAt,
+	}
+	cookies = append(cookies, cookie)
+	b.Client.Jar.SetCookies(u, cookies)
+}
+
+// bearerTokenFromCookies returns the token saved in the gf-token cookie, the expiry is zero when unknown
+func (b *OGame) bearerTokenFromCookies() (token string, expiresAt time.Time) {
+	if b.Client == nil {
+		return "", time.Time{}
+	}
+	jar, ok := b.Client.Jar.(*cookiejar.Jar)
+	if !ok {
+		return "", time.Time{}
+	}
+	for _, c := range jar.AllCookies() {
+		if c.Name == gfTokenCookieName {
+			if c.Expires.Before(time.Now().Add(bearerTokenCookieMaxLifetime)) {
+				expiresAt = c.Expires
+			}
+			return c.Value, expiresAt
+		}
+	}
+	return "", time.Time{}
+}
+
+// GetBearerToken returns the gameforge bearer token used to log in and its expiry.
+// The token given to the bot takes precedence over the one saved in the cookies, the expiry is zero when unknown.
+func (b *OGame) GetBearerToken() (token string, expiresAt time.Time) {
+	b.bearerTokenMu.RLock()
+	token, expiresAt = b.bearerToken, b.bearerTokenExpiresAt
+	b.bearerTokenMu.RUnlock()
+	if token != "" {
+		return token, expiresAt
+	}
+	return b.bearerTokenFromCookies()
+}
+
+// RefreshBearerToken gets a new bearer token from the lobby with the bot credentials, the game session is kept
+func (b *OGame) RefreshBearerToken() error {
+	if b.Username == "" || b.password == "" {
+		return errors.New("credentials are required to refresh the bearer token")
+	}
+	gameEnvironmentID, platformGameID, err := getConfiguration(b)
+	if err != nil {
+		return err
+	}
+	if _, err := postSessions(b, gameEnvironmentID, platformGameID, b.Username, b.password, b.otpSecret); err != nil {
+		return err
+	}
+	return b.saveCookies()
+}
+
+// needsBearerTokenRefresh returns true if the token expires within margin, an unknown expiry is never refreshed
+func needsBearerTokenRefresh(expiresAt time.Time, margin time.Duration, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Add(margin).Before(expiresAt)
+}
+
+// StartBearerTokenRefresher gets a new bearer token margin (DefaultBearerTokenRefreshMargin if 0) before the current
+// one expires, until the returned function is called, so the bot is not logged out in the middle of its work
+func (b *OGame) StartBearerTokenRefresher(margin time.Duration) (stop func()) {
+	if margin <= 0 {
+		margin = DefaultBearerTokenRefreshMargin
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			if _, expiresAt := b.GetBearerToken(); b.isEnabled() && needsBearerTokenRefresh(expiresAt, margin, time.Now()) {
+				if err := b.RefreshBearerToken(); err != nil {
+					b.error("failed to refresh bearer token: " + err.Error())
+				}
+			}
+			select {
+			case <-time.After(bearerTokenCheckInterval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
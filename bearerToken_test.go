@@ -0,0 +1,48 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsBearerTokenRefresh(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, needsBearerTokenRefresh(time.Time{}, time.Hour, now))
+	assert.False(t, needsBearerTokenRefresh(now.Add(2*time.Hour), time.Hour, now))
+	assert.True(t, needsBearerTokenRefresh(now.Add(time.Hour), time.Hour, now))
+	assert.True(t, needsBearerTokenRefresh(now.Add(-time.Minute), time.Hour, now))
+}
+
+func TestGetBearerToken(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ogame")
+	defer os.RemoveAll(dir)
+	cookiesFilename := filepath.Join(dir, "cookies.txt")
+	b, _ := NewNoLogin("", "", "", "", "", "", cookiesFilename, 0, nil)
+	token, expiresAt := b.GetBearerToken()
+	assert.Equal(t, "", token)
+	assert.True(t, expiresAt.IsZero())
+
+	expiry := time.Now().Add(DefaultBearerTokenLifetime).Truncate(time.Second)
+	b.setBearerToken("abc", expiry)
+	token, expiresAt = b.GetBearerToken()
+	assert.Equal(t, "abc", token)
+	assert.True(t, expiry.Equal(expiresAt))
+	assert.Equal(t, "abc", b.GetSessionCredentials().BearerToken)
+
+	// A token given with the credentials has an unknown expiry
+	b.SetOGameCredentials("", "", "", "def")
+	token, expiresAt = b.GetBearerToken()
+	assert.Equal(t, "def", token)
+	assert.True(t, expiresAt.IsZero())
+
+	// The token is reloaded from the cookies with its expiry
+	b.SetOGameCredentials("", "", "", "")
+	token, expiresAt = b.GetBearerToken()
+	assert.Equal(t, "abc", token)
+	assert.True(t, expiry.Equal(expiresAt))
+}
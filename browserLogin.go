@@ -0,0 +1,74 @@
+package ogame
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// BrowserLoginResult what a browser login obtained from the lobby
+type BrowserLoginResult struct {
+	BearerToken string
+	Cookies     []*http.Cookie // Cookies of the gameforge domains, the gf-token cookie included
+}
+
+// BrowserLogin logs in the lobby by driving a real browser (eg: chromedp) and returns the obtained token and cookies.
+// It is used as a fallback when the HTTP login flow fails, after a gameforge change or when a captcha blocks it.
+type BrowserLogin func(ctx context.Context, lobby, username, password string) (BrowserLoginResult, error)
+
+// isCredentialsError returns true for the login errors a browser login would fail with too. The captcha and blocked
+// login errors are not, a browser gets past them.
+func isCredentialsError(err error) bool {
+	if _, ok := err.(*AccountBanError); ok {
+		return true
+	}
+	return err == ErrBadCredentials || err == ErrOTPRequired || err == ErrOTPInvalid || err == ErrAccountBlocked
+}
+
+// isLoginBlockedResponse returns true when the sessions api answered with a rate limit or an html page (anti-bot
+// challenge, waf) rather than its json response
+func isLoginBlockedResponse(statusCode int, by []byte) bool {
+	return statusCode == http.StatusTooManyRequests || bytes.Contains(bytes.ToLower(by), []byte("<html"))
+}
+
+// SetBrowserLogin sets the browser login used when the HTTP login flow fails, nil disables it
+func (b *OGame) SetBrowserLogin(browserLogin BrowserLogin) {
+	b.browserLoginMu.Lock()
+	defer b.browserLoginMu.Unlock()
+	b.browserLogin = browserLogin
+}
+
+func (b *OGame) getBrowserLogin() BrowserLogin {
+	b.browserLoginMu.RLock()
+	defer b.browserLoginMu.RUnlock()
+	return b.browserLogin
+}
+
+// loginWithBrowser logs in the lobby with the browser login and feeds the token and cookies back to the HTTP client
+func (b *OGame) loginWithBrowser(browserLogin BrowserLogin) (string, error) {
+	b.debug("login with browser")
+	res, err := browserLogin(b.ctx, b.lobby, b.Username, b.password)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range res.Cookies {
+		if res.BearerToken == "" && c.Name == gfTokenCookieName {
+			res.BearerToken = c.Value
+		}
+		domain := c.Domain
+		if len(domain) > 0 && domain[0] == '.' {
+			domain = domain[1:]
+		}
+		if u, err := url.Parse("https://" + domain); err == nil && domain != "" {
+			b.Client.Jar.SetCookies(u, []*http.Cookie{c})
+		}
+	}
+	if res.BearerToken == "" {
+		return "", errors.New("browser login did not get a bearer token")
+	}
+	b.setBearerToken(res.BearerToken, time.Now().Add(DefaultBearerTokenLifetime))
+	return res.BearerToken, nil
+}
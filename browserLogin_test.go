@@ -0,0 +1,61 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCredentialsError(t *testing.T) {
+	assert.True(t, isCredentialsError(ErrBadCredentials))
+	assert.True(t, isCredentialsError(ErrOTPInvalid))
+	assert.False(t, isCredentialsError(errors.New("captcha required, abc")))
+	assert.False(t, isCredentialsError(newCaptchaRequiredError("abc")))
+	assert.False(t, isCredentialsError(ErrLoginBlocked))
+}
+
+func TestIsLoginBlockedResponse(t *testing.T) {
+	assert.True(t, isLoginBlockedResponse(429, nil))
+	assert.True(t, isLoginBlockedResponse(403, []byte(`<!DOCTYPE html><HTML><head><title>Access denied</title></head></HTML>`)))
+	assert.False(t, isLoginBlockedResponse(403, []byte(`{"error":"invalid credentials"}`)))
+	assert.False(t, isLoginBlockedResponse(403, nil))
+}
+
+func TestLoginWithBrowser(t *testing.T) {
+	b, _ := NewNoLogin("user@example.com", "secret", "", "", "", "", "", 0, nil)
+	b.setOGameLobby(Lobby)
+	var gotLobby, gotUsername string
+	browserLogin := func(ctx context.Context, lobby, username, password string) (BrowserLoginResult, error) {
+		gotLobby, gotUsername = lobby, username
+		return BrowserLoginResult{Cookies: []*http.Cookie{
+			{Name: gfTokenCookieName, Value: "abc", Domain: ".gameforge.com", Path: "/"},
+			{Name: "other", Value: "1", Domain: "lobby.ogame.gameforge.com", Path: "/"},
+		}}, nil
+	}
+	b.SetBrowserLogin(browserLogin)
+	assert.NotNil(t, b.getBrowserLogin())
+	token, err := b.loginWithBrowser(b.getBrowserLogin())
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", token)
+	assert.Equal(t, Lobby, gotLobby)
+	assert.Equal(t, "user@example.com", gotUsername)
+	bearerToken, expiresAt := b.GetBearerToken()
+	assert.Equal(t, "abc", bearerToken)
+	assert.False(t, expiresAt.IsZero())
+	u, _ := url.Parse("https://lobby.ogame.gameforge.com/")
+	names := make([]string, 0)
+	for _, c := range b.Client.Jar.Cookies(u) {
+		names = append(names, c.Name)
+	}
+	assert.Contains(t, names, "other")
+	assert.Contains(t, names, gfTokenCookieName)
+
+	_, err = b.loginWithBrowser(func(ctx context.Context, lobby, username, password string) (BrowserLoginResult, error) {
+		return BrowserLoginResult{}, nil
+	})
+	assert.NotNil(t, err)
+}
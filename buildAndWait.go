@@ -0,0 +1,98 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// getConstructions returns the building and research being built and their countdowns, like constructionsBeingBuilt
+// but failing if the overview page cannot be read
+func (b *OGame) getConstructions(celestialID CelestialID) (buildingID ID, buildingCountdown int64, researchID ID, researchCountdown int64, err error) {
+	pageHTML, err := b.getPage(OverviewPage, celestialID)
+	if err != nil {
+		return
+	}
+	buildingID, buildingCountdown, researchID, researchCountdown = b.extractor.ExtractConstructions(pageHTML)
+	return
+}
+
+// constructionLevel returns the level of the building of the celestial, or of the research
+func (b *OGame) constructionLevel(celestialID CelestialID, id ID) (int64, error) {
+	if id.IsTech() {
+		return b.WithPriority(Normal).GetResearch().ByID(id), nil
+	}
+	return b.buildingLevel(celestialID, id)
+}
+
+// waitConstruction polls the construction of id until it is not in progress anymore, waiting for its countdown
+// between the polls. The construction is finished if its level went past before, canceled otherwise.
+func waitConstruction(ctx context.Context, id ID, before int64, poll func() (currentID ID, countdown int64, err error), level func() (int64, error)) error {
+	started := false
+	for {
+		currentID, countdown, err := poll()
+		if err != nil {
+			return err
+		}
+		if currentID != id {
+			after, err := level()
+			if err != nil {
+				return err
+			}
+			if after > before {
+				return nil
+			}
+			if !started {
+				return ErrConstructionNotStarted
+			}
+			return ErrConstructionCanceled
+		}
+		started = true
+		select {
+		case <-time.After(time.Duration(countdown+1) * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// BuildAndWait starts the construction of the building or research id on the celestial, and waits until it is done.
+// The end of the construction is verified on the overview page once its countdown is over, and its level is compared
+// with the level before the construction. A construction.finished event is emitted once done.
+// If ctx is done first, ctx error is returned and the construction keeps going.
+func (b *OGame) BuildAndWait(ctx context.Context, celestialID CelestialID, id ID) error {
+	if !id.IsBuilding() && !id.IsTech() {
+		return errors.New("invalid id " + id.String() + ", only buildings and researches can be waited for")
+	}
+	before, err := b.constructionLevel(celestialID, id)
+	if err != nil {
+		return err
+	}
+	if id.IsBuilding() {
+		err = b.WithPriority(Normal).BuildBuilding(celestialID, id)
+	} else {
+		err = b.WithPriority(Normal).BuildTechnology(celestialID, id)
+	}
+	if err != nil {
+		return err
+	}
+	poll := func() (ID, int64, error) {
+		tx := b.withPriority(Normal).begin("BuildAndWait")
+		defer tx.done()
+		buildingID, buildingCountdown, researchID, researchCountdown, err := b.getConstructions(celestialID)
+		if id.IsTech() {
+			return researchID, researchCountdown, err
+		}
+		return buildingID, buildingCountdown, err
+	}
+	level := func() (int64, error) { return b.constructionLevel(celestialID, id) }
+	if err := waitConstruction(ctx, id, before, poll, level); err != nil {
+		return err
+	}
+	eventCelestialID := celestialID
+	if id.IsTech() {
+		eventCelestialID = 0
+	}
+	b.emitEvent(NewConstructionFinishedEvent(eventCelestialID, id, time.Now()))
+	return nil
+}
@@ -0,0 +1,57 @@
+package ogame
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAndWait_InvalidID(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	err := b.BuildAndWait(context.Background(), 123, SmallCargoID)
+	assert.EqualError(t, err, "invalid id SmallCargo, only buildings and researches can be waited for")
+}
+
+func TestWaitConstruction_Finished(t *testing.T) {
+	polls := []ID{MetalMineID, 0}
+	poll := func() (ID, int64, error) {
+		id := polls[0]
+		polls = polls[1:]
+		return id, 0, nil
+	}
+	err := waitConstruction(context.Background(), MetalMineID, 5, poll, func() (int64, error) { return 6, nil })
+	assert.NoError(t, err)
+	assert.Empty(t, polls)
+}
+
+func TestWaitConstruction_FinishedBeforeFirstPoll(t *testing.T) {
+	poll := func() (ID, int64, error) { return 0, 0, nil }
+	err := waitConstruction(context.Background(), MetalMineID, 5, poll, func() (int64, error) { return 6, nil })
+	assert.NoError(t, err)
+}
+
+func TestWaitConstruction_Canceled(t *testing.T) {
+	polls := []ID{MetalMineID, CrystalMineID}
+	poll := func() (ID, int64, error) {
+		id := polls[0]
+		polls = polls[1:]
+		return id, 0, nil
+	}
+	err := waitConstruction(context.Background(), MetalMineID, 5, poll, func() (int64, error) { return 5, nil })
+	assert.Equal(t, ErrConstructionCanceled, err)
+}
+
+func TestWaitConstruction_NotStarted(t *testing.T) {
+	poll := func() (ID, int64, error) { return 0, 0, nil }
+	err := waitConstruction(context.Background(), MetalMineID, 5, poll, func() (int64, error) { return 5, nil })
+	assert.Equal(t, ErrConstructionNotStarted, err)
+}
+
+func TestWaitConstruction_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	poll := func() (ID, int64, error) { return MetalMineID, 3600, nil }
+	err := waitConstruction(ctx, MetalMineID, 5, poll, func() (int64, error) { return 5, nil })
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,111 @@
+package ogame
+
+import (
+	"fmt"
+	"time"
+)
+
+// CacheKind kind of state cached by the bot
+type CacheKind string
+
+// Kinds of cached state
+const (
+	PlanetsCache        CacheKind = "planets"
+	ResearchesCache     CacheKind = "researches"
+	CharacterClassCache CacheKind = "characterClass"
+	ServerDataCache     CacheKind = "serverData"
+	EmpireCache         CacheKind = "empire"
+	GalaxyCacheKind     CacheKind = "galaxy"
+)
+
+// CacheKinds every kind of cached state
+var CacheKinds = []CacheKind{PlanetsCache, ResearchesCache, CharacterClassCache, ServerDataCache, EmpireCache, GalaxyCacheKind}
+
+// ParseCacheKind returns the cache kind named s
+func ParseCacheKind(s string) (CacheKind, error) {
+	for _, kind := range CacheKinds {
+		if string(kind) == s {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("invalid cache kind %q", s)
+}
+
+// CacheInfo state of a cache
+type CacheInfo struct {
+	Kind     CacheKind
+	Cached   bool
+	CachedAt time.Time // Zero when unknown
+}
+
+// GetCacheInfo returns the state of every cache
+func (b *OGame) GetCacheInfo() []CacheInfo {
+	b.planetsMu.RLock()
+	planetsCached, planetsCachedAt := len(b.planets) > 0, b.planetsCachedAt
+	b.planetsMu.RUnlock()
+	b.empireCacheMu.Lock()
+	var empireCachedAt time.Time
+	for _, cached := range b.empireCache {
+		if cached.cachedAt.After(empireCachedAt) {
+			empireCachedAt = cached.cachedAt
+		}
+	}
+	if b.empireSnapshot != nil && b.empireSnapshot.Time.After(empireCachedAt) {
+		empireCachedAt = b.empireSnapshot.Time
+	}
+	empireCached := len(b.empireCache) > 0 || b.empireSnapshot != nil
+	b.empireCacheMu.Unlock()
+	galaxyCache := b.getGalaxyCache()
+	return []CacheInfo{
+		{Kind: PlanetsCache, Cached: planetsCached, CachedAt: planetsCachedAt},
+		{Kind: ResearchesCache, Cached: b.researches != nil, CachedAt: b.researchesCachedAt},
+		// The character class is refreshed with the planets, on every full page
+		{Kind: CharacterClassCache, Cached: planetsCached, CachedAt: planetsCachedAt},
+		{Kind: ServerDataCache, Cached: !b.serverDataCachedAt.IsZero(), CachedAt: b.serverDataCachedAt},
+		{Kind: EmpireCache, Cached: empireCached, CachedAt: empireCachedAt},
+		{Kind: GalaxyCacheKind, Cached: galaxyCache != nil && galaxyCache.Len() > 0},
+	}
+}
+
+// invalidateCache drops the cached state of the given kinds, every kind if none is given.
+// The planets, character class and server data are reloaded from the game right away since the bot relies on them,
+// the researches, empire and galaxy systems are fetched again the next time they are needed.
+func (b *OGame) invalidateCache(kinds ...CacheKind) error {
+	if len(kinds) == 0 {
+		kinds = CacheKinds
+	}
+	reloadOverview := false
+	for _, kind := range kinds {
+		switch kind {
+		case PlanetsCache, CharacterClassCache:
+			reloadOverview = true
+		case ResearchesCache:
+			b.researches = nil
+			b.researchesCachedAt = time.Time{}
+		case ServerDataCache:
+			if err := b.loadServerData(); err != nil {
+				return err
+			}
+		case EmpireCache:
+			b.empireCacheMu.Lock()
+			b.empireCache = nil
+			b.empireSnapshot = nil
+			b.empireCacheMu.Unlock()
+		case GalaxyCacheKind:
+			if galaxyCache := b.getGalaxyCache(); galaxyCache != nil {
+				if err := galaxyCache.Clear(); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("invalid cache kind %q", kind)
+		}
+	}
+	if reloadOverview {
+		// A full page refreshes the planets and the character class
+		if _, err := b.getPage(OverviewPage, CelestialID(0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
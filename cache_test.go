@@ -0,0 +1,31 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCacheKind(t *testing.T) {
+	kind, err := ParseCacheKind("researches")
+	assert.NoError(t, err)
+	assert.Equal(t, ResearchesCache, kind)
+	_, err = ParseCacheKind("unknown")
+	assert.Error(t, err)
+}
+
+func TestOGame_InvalidateCache(t *testing.T) {
+	b := &OGame{researches: &Researches{Astrophysics: 3}, researchesCachedAt: time.Now()}
+	b.cacheEmpireJSON(0, "empire")
+	info := b.GetCacheInfo()
+	assert.Equal(t, CacheInfo{Kind: ResearchesCache, Cached: true, CachedAt: b.researchesCachedAt}, info[1])
+	assert.True(t, info[4].Cached)
+	assert.False(t, info[0].Cached)
+
+	assert.NoError(t, b.invalidateCache(ResearchesCache, EmpireCache))
+	info = b.GetCacheInfo()
+	assert.False(t, info[1].Cached)
+	assert.False(t, info[4].Cached)
+	assert.Error(t, b.invalidateCache("unknown"))
+}
@@ -0,0 +1,367 @@
+package ogame
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CaptchaSolver a service able to solve the gameforge captcha challenge
+type CaptchaSolver interface {
+	// Solve returns the index (0 to 3) of the icon matching the question
+	Solve(question, icons []byte) (int64, error)
+	// Balance returns the remaining credit on the solver account
+	Balance() (float64, error)
+}
+
+// Captcha solver providers names
+const (
+	NinjaCaptchaProvider       = "ninja"
+	TwoCaptchaProvider         = "2captcha"
+	AntiCaptchaProvider        = "anti-captcha"
+	captchaIconsCount          = 4
+	captchaMaxPolls            = 24
+	captchaCoordinatesQuestion = "Click on the icon (bottom row) that matches the image at the top"
+)
+
+// captchaPollInterval delay between two checks for the answer of a solver service
+var captchaPollInterval = 5 * time.Second
+
+// NewCaptchaSolver creates a solver for the given provider name (ninja, 2captcha, anti-captcha)
+func NewCaptchaSolver(provider, apiKey string) (CaptchaSolver, error) {
+	switch strings.ToLower(provider) {
+	case NinjaCaptchaProvider:
+		return &NinjaCaptchaSolver{APIKey: apiKey}, nil
+	case TwoCaptchaProvider:
+		return &TwoCaptchaSolver{APIKey: apiKey}, nil
+	case AntiCaptchaProvider:
+		return &AntiCaptchaSolver{APIKey: apiKey}, nil
+	}
+	return nil, errors.New("unknown captcha provider " + provider)
+}
+
+// SolverCallback turns a CaptchaSolver into a CaptchaCallback, the challenge is submitted up to maxTries times
+func SolverCallback(solver CaptchaSolver, maxTries int) CaptchaCallback {
+	return func(question, icons []byte) (answer int64, err error) {
+		for try := 0; try < maxTries; try++ {
+			if answer, err = solver.Solve(question, icons); err == nil {
+				return answer, nil
+			}
+		}
+		return 0, err
+	}
+}
+
+// NinjaSolver direct integration of ogame.ninja captcha auto solver service
+func NinjaSolver(apiKey string) CaptchaCallback {
+	return SolverCallback(&NinjaCaptchaSolver{APIKey: apiKey}, 1)
+}
+
+func captchaHTTPClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return http.DefaultClient
+}
+
+// NinjaCaptchaSolver ogame.ninja captcha solver service
+type NinjaCaptchaSolver struct {
+	APIKey string
+	Client *http.Client // Optional, http.DefaultClient is used if nil
+}
+
+// Solve implements CaptchaSolver
+func (s *NinjaCaptchaSolver) Solve(question, icons []byte) (int64, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("question", "question.png")
+	_, _ = io.Copy(part, bytes.NewReader(question))
+	part1, _ := writer.CreateFormFile("icons", "icons.png")
+	_, _ = io.Copy(part1, bytes.NewReader(icons))
+	_ = writer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, "https://www.ogame.ninja/api/v1/captcha/solve", body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	req.Header.Set("NJA_API_KEY", s.APIKey)
+	resp, err := captchaHTTPClient(s.Client).Do(req)
+	if err != nil {
+		return 0, errors.New("failed to auto solve captcha: " + err.Error())
+	}
+	defer resp.Body.Close()
+	by, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.New("failed to auto solve captcha: " + err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return 0, errors.New("failed to auto solve captcha: " + string(by))
+	}
+	var answerJson struct {
+		Answer int64 `json:"answer"`
+	}
+	if err := json.Unmarshal(by, &answerJson); err != nil {
+		return 0, errors.New("failed to auto solve captcha: " + err.Error())
+	}
+	return answerJson.Answer, nil
+}
+
+// Balance implements CaptchaSolver, ogame.ninja does not expose a balance
+func (s *NinjaCaptchaSolver) Balance() (float64, error) {
+	return 0, ErrBalanceUnsupported
+}
+
+// composeCaptchaImage stacks the question image on top of the icons image,
+// so generic "click on the image" services can solve the challenge.
+// Returns the png image, the y offset of the icons row and the width of the icons row.
+func composeCaptchaImage(question, icons []byte) ([]byte, int, int, error) {
+	questionImg, _, err := image.Decode(bytes.NewReader(question))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	iconsImg, _, err := image.Decode(bytes.NewReader(icons))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	qb, ib := questionImg.Bounds(), iconsImg.Bounds()
+	width := qb.Dx()
+	if ib.Dx() > width {
+		width = ib.Dx()
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, qb.Dy()+ib.Dy()))
+	draw.Draw(dst, image.Rect(0, 0, qb.Dx(), qb.Dy()), questionImg, qb.Min, draw.Src)
+	draw.Draw(dst, image.Rect(0, qb.Dy(), ib.Dx(), qb.Dy()+ib.Dy()), iconsImg, ib.Min, draw.Src)
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, dst); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), qb.Dy(), ib.Dx(), nil
+}
+
+// iconIndexFromX converts the x coordinate of a click on the icons row into an icon index
+func iconIndexFromX(x, iconsWidth int) int64 {
+	if iconsWidth <= 0 {
+		return 0
+	}
+	idx := int64(x * captchaIconsCount / iconsWidth)
+	if idx < 0 {
+		idx = 0
+	} else if idx >= captchaIconsCount {
+		idx = captchaIconsCount - 1
+	}
+	return idx
+}
+
+// TwoCaptchaSolver 2captcha.com solver, the challenge is sent as a coordinates captcha
+type TwoCaptchaSolver struct {
+	APIKey  string
+	Client  *http.Client // Optional, http.DefaultClient is used if nil
+	BaseURL string       // Optional, defaults to https://2captcha.com
+}
+
+func (s *TwoCaptchaSolver) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return "https://2captcha.com"
+}
+
+type twoCaptchaResponse struct {
+	Status  int             `json:"status"`
+	Request json.RawMessage `json:"request"`
+}
+
+func (r twoCaptchaResponse) requestString() string {
+	var str string
+	if err := json.Unmarshal(r.Request, &str); err != nil {
+		return string(r.Request)
+	}
+	return str
+}
+
+func (s *TwoCaptchaSolver) get(vals url.Values) (twoCaptchaResponse, error) {
+	var res twoCaptchaResponse
+	vals.Set("key", s.APIKey)
+	vals.Set("json", "1")
+	resp, err := captchaHTTPClient(s.Client).Get(s.baseURL() + "/res.php?" + vals.Encode())
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	return res, err
+}
+
+// Solve implements CaptchaSolver
+func (s *TwoCaptchaSolver) Solve(question, icons []byte) (int64, error) {
+	img, iconsY, iconsWidth, err := composeCaptchaImage(question, icons)
+	if err != nil {
+		return 0, err
+	}
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("key", s.APIKey)
+	_ = writer.WriteField("method", "post")
+	_ = writer.WriteField("coordinatescaptcha", "1")
+	_ = writer.WriteField("textinstructions", captchaCoordinatesQuestion)
+	_ = writer.WriteField("json", "1")
+	part, _ := writer.CreateFormFile("file", "captcha.png")
+	_, _ = part.Write(img)
+	_ = writer.Close()
+	resp, err := captchaHTTPClient(s.Client).Post(s.baseURL()+"/in.php", writer.FormDataContentType(), body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var created twoCaptchaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, err
+	}
+	if created.Status != 1 {
+		return 0, errors.New("2captcha: " + created.requestString())
+	}
+	captchaID := created.requestString()
+
+	for i := 0; i < captchaMaxPolls; i++ {
+		time.Sleep(captchaPollInterval)
+		res, err := s.get(url.Values{"action": {"get"}, "id": {captchaID}})
+		if err != nil {
+			return 0, err
+		}
+		if res.Status == 1 {
+			var points []struct {
+				X json.Number `json:"x"`
+				Y json.Number `json:"y"`
+			}
+			if err := json.Unmarshal(res.Request, &points); err != nil || len(points) == 0 {
+				return 0, errors.New("2captcha: invalid answer " + string(res.Request))
+			}
+			x, _ := points[0].X.Int64()
+			y, _ := points[0].Y.Int64()
+			if int(y) < iconsY {
+				return 0, fmt.Errorf("2captcha: click outside of the icons row (y=%d)", y)
+			}
+			return iconIndexFromX(int(x), iconsWidth), nil
+		}
+		if msg := res.requestString(); msg != "CAPCHA_NOT_READY" {
+			return 0, errors.New("2captcha: " + msg)
+		}
+	}
+	return 0, errors.New("2captcha: timed out waiting for the answer")
+}
+
+// Balance implements CaptchaSolver
+func (s *TwoCaptchaSolver) Balance() (float64, error) {
+	res, err := s.get(url.Values{"action": {"getbalance"}})
+	if err != nil {
+		return 0, err
+	}
+	if res.Status != 1 {
+		return 0, errors.New("2captcha: " + res.requestString())
+	}
+	return strconv.ParseFloat(res.requestString(), 64)
+}
+
+// AntiCaptchaSolver anti-captcha.com solver, the challenge is sent as an ImageToCoordinatesTask
+type AntiCaptchaSolver struct {
+	APIKey  string
+	Client  *http.Client // Optional, http.DefaultClient is used if nil
+	BaseURL string       // Optional, defaults to https://api.anti-captcha.com
+}
+
+type antiCaptchaResponse struct {
+	ErrorID          int64   `json:"errorId"`
+	ErrorDescription string  `json:"errorDescription"`
+	TaskID           int64   `json:"taskId"`
+	Status           string  `json:"status"`
+	Balance          float64 `json:"balance"`
+	Solution         struct {
+		Coordinates [][]int `json:"coordinates"`
+	} `json:"solution"`
+}
+
+func (s *AntiCaptchaSolver) call(method string, payload map[string]interface{}) (antiCaptchaResponse, error) {
+	var res antiCaptchaResponse
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.anti-captcha.com"
+	}
+	payload["clientKey"] = s.APIKey
+	by, err := json.Marshal(payload)
+	if err != nil {
+		return res, err
+	}
+	resp, err := captchaHTTPClient(s.Client).Post(baseURL+"/"+method, "application/json", bytes.NewReader(by))
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return res, err
+	}
+	if res.ErrorID != 0 {
+		return res, errors.New("anti-captcha: " + res.ErrorDescription)
+	}
+	return res, nil
+}
+
+// Solve implements CaptchaSolver
+func (s *AntiCaptchaSolver) Solve(question, icons []byte) (int64, error) {
+	img, iconsY, iconsWidth, err := composeCaptchaImage(question, icons)
+	if err != nil {
+		return 0, err
+	}
+	created, err := s.call("createTask", map[string]interface{}{
+		"task": map[string]interface{}{
+			"type":    "ImageToCoordinatesTask",
+			"body":    base64.StdEncoding.EncodeToString(img),
+			"comment": captchaCoordinatesQuestion,
+			"mode":    "points",
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < captchaMaxPolls; i++ {
+		time.Sleep(captchaPollInterval)
+		res, err := s.call("getTaskResult", map[string]interface{}{"taskId": created.TaskID})
+		if err != nil {
+			return 0, err
+		}
+		if res.Status != "ready" {
+			continue
+		}
+		if len(res.Solution.Coordinates) == 0 || len(res.Solution.Coordinates[0]) < 2 {
+			return 0, errors.New("anti-captcha: invalid answer")
+		}
+		x, y := res.Solution.Coordinates[0][0], res.Solution.Coordinates[0][1]
+		if y < iconsY {
+			return 0, fmt.Errorf("anti-captcha: click outside of the icons row (y=%d)", y)
+		}
+		return iconIndexFromX(x, iconsWidth), nil
+	}
+	return 0, errors.New("anti-captcha: timed out waiting for the answer")
+}
+
+// Balance implements CaptchaSolver
+func (s *AntiCaptchaSolver) Balance() (float64, error) {
+	res, err := s.call("getBalance", map[string]interface{}{})
+	if err != nil {
+		return 0, err
+	}
+	return res.Balance, nil
+}
@@ -0,0 +1,122 @@
+package ogame
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCaptchaSolver struct {
+	calls   int
+	failFor int
+}
+
+func (s *fakeCaptchaSolver) Solve(question, icons []byte) (int64, error) {
+	s.calls++
+	if s.calls <= s.failFor {
+		return 0, errors.New("failed")
+	}
+	return 2, nil
+}
+
+func (s *fakeCaptchaSolver) Balance() (float64, error) { return 1, nil }
+
+func testCaptchaPNG(w, h int) []byte {
+	buf := &bytes.Buffer{}
+	_ = png.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	return buf.Bytes()
+}
+
+func TestSolverCallback(t *testing.T) {
+	solver := &fakeCaptchaSolver{failFor: 2}
+	answer, err := SolverCallback(solver, 3)(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), answer)
+	assert.Equal(t, 3, solver.calls)
+
+	solver = &fakeCaptchaSolver{failFor: 5}
+	_, err = SolverCallback(solver, 2)(nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 2, solver.calls)
+}
+
+func TestNewCaptchaSolver(t *testing.T) {
+	s, err := NewCaptchaSolver("2Captcha", "key")
+	assert.NoError(t, err)
+	assert.IsType(t, &TwoCaptchaSolver{}, s)
+	_, err = NewCaptchaSolver("unknown", "key")
+	assert.Error(t, err)
+	_, err = (&NinjaCaptchaSolver{}).Balance()
+	assert.Equal(t, ErrBalanceUnsupported, err)
+}
+
+func TestIconIndexFromX(t *testing.T) {
+	assert.Equal(t, int64(0), iconIndexFromX(10, 200))
+	assert.Equal(t, int64(1), iconIndexFromX(60, 200))
+	assert.Equal(t, int64(3), iconIndexFromX(199, 200))
+	assert.Equal(t, int64(3), iconIndexFromX(500, 200))
+}
+
+func TestTwoCaptchaSolver(t *testing.T) {
+	captchaPollInterval = time.Millisecond
+	defer func() { captchaPollInterval = 5 * time.Second }()
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/in.php":
+			_, _ = w.Write([]byte(`{"status":1,"request":"123"}`))
+		case "/res.php":
+			if r.URL.Query().Get("action") == "getbalance" {
+				_, _ = w.Write([]byte(`{"status":1,"request":"4.25"}`))
+				return
+			}
+			polls++
+			if polls < 2 {
+				_, _ = w.Write([]byte(`{"status":0,"request":"CAPCHA_NOT_READY"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":1,"request":[{"x":"130","y":"50"}]}`))
+		}
+	}))
+	defer srv.Close()
+	solver := &TwoCaptchaSolver{APIKey: "key", BaseURL: srv.URL}
+	answer, err := solver.Solve(testCaptchaPNG(200, 40), testCaptchaPNG(200, 50))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), answer)
+	balance, err := solver.Balance()
+	assert.NoError(t, err)
+	assert.Equal(t, 4.25, balance)
+}
+
+func TestAntiCaptchaSolver(t *testing.T) {
+	captchaPollInterval = time.Millisecond
+	defer func() { captchaPollInterval = 5 * time.Second }()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		assert.Equal(t, "key", payload["clientKey"])
+		switch r.URL.Path {
+		case "/createTask":
+			_, _ = w.Write([]byte(`{"errorId":0,"taskId":7}`))
+		case "/getTaskResult":
+			_, _ = w.Write([]byte(`{"errorId":0,"status":"ready","solution":{"coordinates":[[20,60]]}}`))
+		case "/getBalance":
+			_, _ = w.Write([]byte(`{"errorId":1,"errorDescription":"invalid key"}`))
+		}
+	}))
+	defer srv.Close()
+	solver := &AntiCaptchaSolver{APIKey: "key", BaseURL: srv.URL}
+	answer, err := solver.Solve(testCaptchaPNG(200, 40), testCaptchaPNG(200, 50))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), answer)
+	_, err = solver.Balance()
+	assert.EqualError(t, err, "anti-captcha: invalid key")
+}
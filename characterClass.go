@@ -0,0 +1,102 @@
+package ogame
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// CharacterClassChangeCost dark matter needed to switch class, selecting the first class is free
+const CharacterClassChangeCost int64 = 500000
+
+// String returns the name of the character class
+func (c CharacterClass) String() string {
+	switch c {
+	case Collector:
+		return "Collector"
+	case General:
+		return "General"
+	case Discoverer:
+		return "Discoverer"
+	}
+	return "No class"
+}
+
+// characterClassChangeCost returns the dark matter needed to switch from the current class
+func characterClassChangeCost(current CharacterClass) int64 {
+	if current == NoClass {
+		return 0
+	}
+	return CharacterClassChangeCost
+}
+
+// checkCharacterClassChange validates a class switch against the dark matter available
+func checkCharacterClassChange(current, class CharacterClass, darkmatter int64) error {
+	if class != Collector && class != General && class != Discoverer {
+		return ErrInvalidCharacterClass
+	}
+	if class == current {
+		return fmt.Errorf("character class is already %s", class)
+	}
+	if darkmatter < characterClassChangeCost(current) {
+		return ErrNotEnoughDarkMatter
+	}
+	return nil
+}
+
+// setCharacterClass switches the character class, paying the dark matter cost. The class dependent data
+// (empire production) cached by the bot is dropped once the class changed.
+func (b *OGame) setCharacterClass(class CharacterClass) error {
+	planets := b.GetCachedPlanets()
+	if len(planets) == 0 {
+		return ErrInvalidPlanetID
+	}
+	details, err := b.getResourcesDetails(planets[0].GetID())
+	if err != nil {
+		return err
+	}
+	if err := checkCharacterClassChange(b.characterClass, class, details.Darkmatter.Available); err != nil {
+		return err
+	}
+	pageHTML, err := b.getPageContent(url.Values{"page": {"ingame"}, "component": {"characterclassselection"}})
+	if err != nil {
+		return err
+	}
+	m := regexp.MustCompile(`var token = "([^"]+)"`).FindSubmatch(pageHTML)
+	if len(m) != 2 {
+		return errors.New("unable to find token")
+	}
+	params := url.Values{
+		"page":             {"ingame"},
+		"component":        {"characterclassselection"},
+		"characterClassId": {strconv.FormatInt(int64(class), 10)},
+		"action":           {"selectClass"},
+		"ajax":             {"1"},
+		"asJson":           {"1"},
+	}
+	by, err := b.postPageContent(params, url.Values{"token": {string(m[1])}})
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(by, &res); err != nil {
+		return err
+	}
+	if res.Status != "success" {
+		if res.Message != "" {
+			return errors.New(res.Message)
+		}
+		return errors.New("failed to change character class")
+	}
+	b.characterClass = class
+	b.empireCacheMu.Lock()
+	b.empireCache = nil
+	b.empireCacheMu.Unlock()
+	return nil
+}
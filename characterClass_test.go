@@ -0,0 +1,16 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCharacterClassChange(t *testing.T) {
+	assert.Nil(t, checkCharacterClassChange(NoClass, Collector, 0))
+	assert.Nil(t, checkCharacterClassChange(Collector, General, CharacterClassChangeCost))
+	assert.Equal(t, ErrNotEnoughDarkMatter, checkCharacterClassChange(Collector, General, CharacterClassChangeCost-1))
+	assert.Equal(t, ErrInvalidCharacterClass, checkCharacterClassChange(Collector, NoClass, CharacterClassChangeCost))
+	assert.Equal(t, ErrInvalidCharacterClass, checkCharacterClassChange(Collector, CharacterClass(4), CharacterClassChangeCost))
+	assert.EqualError(t, checkCharacterClassChange(Discoverer, Discoverer, CharacterClassChangeCost), "character class is already Discoverer")
+}
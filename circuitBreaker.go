@@ -0,0 +1,188 @@
+package ogame
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults
+const (
+	DefaultCircuitBreakerThreshold = 5
+	DefaultCircuitBreakerCooldown  = 30 * time.Second
+)
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// circuitBreaker stops sending requests to the OGame server after repeated 5xx/timeouts (eg: maintenance).
+// While open, requests fail fast with ErrServerUnavailable. After the cooldown a single probe request is let through,
+// the circuit closes if it succeeds and opens again otherwise.
+type circuitBreaker struct {
+	sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	onChange  func(state string)
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = DefaultCircuitBreakerCooldown
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: CircuitClosed}
+}
+
+func (c *circuitBreaker) setState(state string) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	if c.onChange != nil {
+		go c.onChange(state)
+	}
+}
+
+// allow returns ErrServerUnavailable if the request must not be sent
+func (c *circuitBreaker) allow(now time.Time) error {
+	if c == nil || c.threshold <= 0 {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	switch c.state {
+	case CircuitOpen:
+		if now.Sub(c.openedAt) < c.cooldown {
+			return ErrServerUnavailable
+		}
+		c.setState(CircuitHalfOpen)
+		c.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return ErrServerUnavailable
+		}
+		c.probing = true
+	}
+	return nil
+}
+
+// record updates the breaker with the result of a request, failed is true for 5xx and timeouts
+func (c *circuitBreaker) record(failed bool, now time.Time) {
+	if c == nil || c.threshold <= 0 {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.probing = false
+	if !failed {
+		c.failures = 0
+		c.setState(CircuitClosed)
+		return
+	}
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= c.threshold {
+		c.openedAt = now
+		c.setState(CircuitOpen)
+	}
+}
+
+// release frees the probe slot without recording a result
+func (c *circuitBreaker) release() {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.probing = false
+}
+
+func (c *circuitBreaker) getState() string {
+	if c == nil {
+		return CircuitClosed
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.state
+}
+
+// isServerFailure returns true for the errors that indicate the server is down (timeouts, connection errors)
+func isServerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return true
+	}
+	if _, ok := err.(*net.OpError); ok {
+		return true
+	}
+	if urlErr, ok := err.(interface{ Unwrap() error }); ok {
+		return isServerFailure(urlErr.Unwrap())
+	}
+	return false
+}
+
+// doWithBreaker sends a request through the circuit breaker
+func (b *OGame) doWithBreaker(req *http.Request) (*http.Response, error) {
+	if err := b.circuitBreaker.allow(time.Now()); err != nil {
+		return nil, err
+	}
+	resp, err := b.Client.Do(req)
+	failed := isServerFailure(err) || (err == nil && resp.StatusCode >= http.StatusInternalServerError)
+	// A cancelled request (bot disabled) says nothing about the server health
+	if err != nil && !failed && req.Context().Err() != nil {
+		b.circuitBreaker.release()
+		return resp, err
+	}
+	b.circuitBreaker.record(failed, time.Now())
+	return resp, err
+}
+
+func (b *OGame) onCircuitBreakerChange(state string) {
+	switch state {
+	case CircuitOpen:
+		b.warn("ogame server unavailable, circuit breaker opened")
+		go b.probeServer()
+	case CircuitClosed:
+		b.info("ogame server available, circuit breaker closed")
+	}
+}
+
+// probeServer sends a probe request once the cooldown is over, so the circuit can close without waiting for a task
+func (b *OGame) probeServer() {
+	select {
+	case <-time.After(b.circuitBreaker.cooldown):
+	case <-b.ctx.Done():
+		return
+	}
+	if b.circuitBreaker.getState() != CircuitOpen || b.serverURL == "" {
+		return
+	}
+	req, err := http.NewRequest(http.MethodHead, b.serverURL+"/game/index.php", nil)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+	if resp, err := b.doWithBreaker(req.WithContext(ctx)); err == nil {
+		_ = resp.Body.Close()
+	}
+}
+
+// CircuitBreakerState returns the state of the circuit breaker protecting the OGame server (closed, open, half-open)
+func (b *OGame) CircuitBreakerState() string {
+	return b.circuitBreaker.getState()
+}
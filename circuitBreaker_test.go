@@ -0,0 +1,77 @@
+package ogame
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	c := newCircuitBreaker(3, time.Minute)
+	assert.NoError(t, c.allow(now))
+	c.record(true, now)
+	c.record(true, now)
+	assert.Equal(t, CircuitClosed, c.getState())
+	c.record(true, now)
+	assert.Equal(t, CircuitOpen, c.getState())
+	assert.Equal(t, ErrServerUnavailable, c.allow(now.Add(30*time.Second)))
+
+	// After the cooldown a single probe is allowed
+	assert.NoError(t, c.allow(now.Add(time.Minute)))
+	assert.Equal(t, CircuitHalfOpen, c.getState())
+	assert.Equal(t, ErrServerUnavailable, c.allow(now.Add(time.Minute)))
+
+	// Failed probe opens the circuit again
+	c.record(true, now.Add(time.Minute))
+	assert.Equal(t, CircuitOpen, c.getState())
+	assert.Equal(t, ErrServerUnavailable, c.allow(now.Add(90*time.Second)))
+
+	// Successful probe closes the circuit
+	assert.NoError(t, c.allow(now.Add(2*time.Minute)))
+	c.record(false, now.Add(2*time.Minute))
+	assert.Equal(t, CircuitClosed, c.getState())
+	assert.NoError(t, c.allow(now.Add(2*time.Minute)))
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	c := newCircuitBreaker(-1, 0)
+	for i := 0; i < 10; i++ {
+		c.record(true, time.Now())
+	}
+	assert.NoError(t, c.allow(time.Now()))
+	var nilBreaker *circuitBreaker
+	assert.NoError(t, nilBreaker.allow(time.Now()))
+	assert.Equal(t, CircuitClosed, nilBreaker.getState())
+}
+
+func TestIsServerFailure(t *testing.T) {
+	assert.False(t, isServerFailure(nil))
+	assert.False(t, isServerFailure(errors.New("invalid")))
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer ts.Close()
+	_, err := client.Get(ts.URL)
+	assert.True(t, isServerFailure(err))
+}
+
+func TestExecRawRequest_ServerError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.circuitBreaker = newCircuitBreaker(2, time.Hour)
+	_, _, err := b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.Error(t, err)
+	_, _, err = b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.Error(t, err)
+	_, _, err = b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.Equal(t, ErrServerUnavailable, err)
+}
@@ -3,10 +3,15 @@ package ogame
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// TransportWrapper wraps the transport used by the client (logging, caching, mTLS, request signing...)
+type TransportWrapper func(http.RoundTripper) http.RoundTripper
+
 // OGameClient ...
 type OGameClient struct {
 	http.Client
@@ -15,6 +20,14 @@ type OGameClient struct {
 	rps          int32
 	maxRPS       int32
 	rpsStartTime int64
+
+	wrapperMu        sync.Mutex
+	transportWrapper TransportWrapper
+	wrappedBase      http.RoundTripper
+	wrapped          http.RoundTripper
+
+	governorMu sync.RWMutex
+	governor   *RequestGovernor
 }
 
 // NewOGameClient ...
@@ -54,11 +67,45 @@ func (c *OGameClient) incrRPS() {
 	}
 }
 
+// SetTransportWrapper sets a function that wraps the client transport, nil removes the wrapper.
+// The wrapper is applied again every time the underlying transport changes (eg: proxy change).
+func (c *OGameClient) SetTransportWrapper(wrapper TransportWrapper) {
+	c.wrapperMu.Lock()
+	defer c.wrapperMu.Unlock()
+	c.transportWrapper = wrapper
+	c.wrappedBase, c.wrapped = nil, nil
+}
+
+// getWrappedTransport returns the wrapped version of base, the wrapped transport is cached until base changes
+func (c *OGameClient) getWrappedTransport(base http.RoundTripper) http.RoundTripper {
+	c.wrapperMu.Lock()
+	defer c.wrapperMu.Unlock()
+	if c.transportWrapper == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// Transports that are not comparable (eg: func types) can't be cached and are wrapped on every request
+	if c.wrapped == nil || !reflect.TypeOf(base).Comparable() || c.wrappedBase != base {
+		c.wrappedBase = base
+		c.wrapped = c.transportWrapper(base)
+	}
+	return c.wrapped
+}
+
 // Do executes a request
 func (c *OGameClient) Do(req *http.Request) (*http.Response, error) {
+	if g := c.GetRequestGovernor(); g != nil {
+		if err := g.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
 	c.incrRPS()
 	req.Header.Add("User-Agent", c.UserAgent)
-	return c.Client.Do(req)
+	client := c.Client
+	client.Transport = c.getWrappedTransport(c.Client.Transport)
+	return client.Do(req)
 }
 
 // FakeDo for testing purposes
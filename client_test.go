@@ -33,3 +33,39 @@ func TestOgameClient_Do(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "test", req.Header.Get("User-Agent"))
 }
+
+type signedEchoTransport struct{ body string }
+
+func (t *signedEchoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(t.body + req.Header.Get("X-Signed")))}, nil
+}
+
+func TestOgameClient_TransportWrapper(t *testing.T) {
+	client := NewOGameClient()
+	client.Transport = &signedEchoTransport{}
+	wrapped := 0
+	client.SetTransportWrapper(func(next http.RoundTripper) http.RoundTripper {
+		wrapped++
+		return RoundTripFunc(func(req *http.Request) *http.Response {
+			req.Header.Set("X-Signed", "1")
+			resp, _ := next.RoundTrip(req)
+			return resp
+		})
+	})
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "https://127.0.0.1", nil)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		by, _ := ioutil.ReadAll(resp.Body)
+		assert.Equal(t, "1", string(by))
+	}
+	assert.Equal(t, 1, wrapped)
+
+	// A new underlying transport is wrapped again
+	client.Transport = &signedEchoTransport{body: "new"}
+	req, _ := http.NewRequest("GET", "https://127.0.0.1", nil)
+	resp, _ := client.Do(req)
+	by, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "new1", string(by))
+	assert.Equal(t, 2, wrapped)
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"gopkg.in/urfave/cli.v2"
+)
+
+var version = "0.0.0"
+
+// apiResp response envelope of the ogamed API
+type apiResp struct {
+	Status    string
+	Code      int
+	ErrorCode ogame.ErrorCode
+	Message   string
+	Result    json.RawMessage
+}
+
+// apiError error returned by ogamed
+type apiError struct {
+	Code      int
+	ErrorCode ogame.ErrorCode
+	Message   string
+}
+
+func (e *apiError) Error() string {
+	if e.ErrorCode != "" {
+		return fmt.Sprintf("%s (%s)", e.Message, e.ErrorCode)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// client ogamed API client, authenticates with an api key, a JWT or basic auth
+type client struct {
+	addr       string
+	apiKey     string
+	token      string
+	basicAuth  string
+	httpClient *http.Client
+}
+
+func newClient(c *cli.Context) *client {
+	return &client{
+		addr:       strings.TrimRight(c.String("addr"), "/"),
+		apiKey:     c.String("api-key"),
+		token:      c.String("token"),
+		basicAuth:  c.String("basic-auth"),
+		httpClient: &http.Client{Timeout: c.Duration("timeout")},
+	}
+}
+
+// do sends a request to ogamed and decodes the result in v, the raw result is returned too
+func (cl *client) do(method, path string, form url.Values, v interface{}) (json.RawMessage, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, cl.addr+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if cl.apiKey != "" {
+		req.Header.Set("X-API-Key", cl.apiKey)
+	}
+	if cl.token != "" {
+		req.Header.Set("Authorization", "Bearer "+cl.token)
+	}
+	if cl.basicAuth != "" {
+		username, password := cl.basicAuth, ""
+		if idx := strings.Index(cl.basicAuth, ":"); idx != -1 {
+			username, password = cl.basicAuth[:idx], cl.basicAuth[idx+1:]
+		}
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := cl.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	by, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var res apiResp
+	if err := json.Unmarshal(by, &res); err != nil {
+		if resp.StatusCode >= 400 {
+			return nil, &apiError{Code: resp.StatusCode, Message: strings.TrimSpace(string(by))}
+		}
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	if res.Status == "error" || resp.StatusCode >= 400 {
+		if res.Message == "" {
+			res.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, &apiError{Code: resp.StatusCode, ErrorCode: res.ErrorCode, Message: res.Message}
+	}
+	if v != nil {
+		if err := json.Unmarshal(res.Result, v); err != nil {
+			return nil, fmt.Errorf("invalid result: %v", err)
+		}
+	}
+	return res.Result, nil
+}
+
+// printJSON prints the raw result indented
+func printJSON(raw json.RawMessage) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// shipAliases short names accepted by --ships
+var shipAliases = map[string]ogame.ID{
+	"sc":    ogame.SmallCargoID,
+	"lc":    ogame.LargeCargoID,
+	"lf":    ogame.LightFighterID,
+	"hf":    ogame.HeavyFighterID,
+	"cr":    ogame.CruiserID,
+	"bs":    ogame.BattleshipID,
+	"cs":    ogame.ColonyShipID,
+	"rec":   ogame.RecyclerID,
+	"rc":    ogame.RecyclerID,
+	"ep":    ogame.EspionageProbeID,
+	"probe": ogame.EspionageProbeID,
+	"bomb":  ogame.BomberID,
+	"ds":    ogame.DestroyerID,
+	"des":   ogame.DestroyerID,
+	"rip":   ogame.DeathstarID,
+	"bc":    ogame.BattlecruiserID,
+	"reap":  ogame.ReaperID,
+	"pf":    ogame.PathfinderID,
+}
+
+// parseShips parses ships like "lc:100,sc:5", a ship is a short name, an id or a full name (eg: "Large Cargo")
+func parseShips(values []string) ([]ogame.Quantifiable, error) {
+	ships := make([]ogame.Quantifiable, 0)
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			idx := strings.LastIndex(s, ":")
+			if idx == -1 {
+				return nil, fmt.Errorf("invalid ships %q, expected name:nbr", s)
+			}
+			name, nbrStr := strings.TrimSpace(s[:idx]), strings.TrimSpace(s[idx+1:])
+			nbr, err := strconv.ParseInt(nbrStr, 10, 64)
+			if err != nil || nbr <= 0 {
+				return nil, fmt.Errorf("invalid number of ships %q", nbrStr)
+			}
+			shipID, ok := shipAliases[strings.ToLower(name)]
+			if !ok {
+				if id, err := strconv.ParseInt(name, 10, 64); err == nil {
+					shipID = ogame.ID(id)
+				} else {
+					shipID = ogame.ShipName2ID(name)
+				}
+			}
+			if !shipID.IsShip() {
+				return nil, fmt.Errorf("unknown ship %q", name)
+			}
+			ships = append(ships, ogame.Quantifiable{ID: shipID, Nbr: nbr})
+		}
+	}
+	if len(ships) == 0 {
+		return nil, errors.New("no ships given")
+	}
+	return ships, nil
+}
+
+// missionNames names accepted by --mission
+var missionNames = map[string]ogame.MissionID{
+	"attack":     ogame.Attack,
+	"acs":        ogame.GroupedAttack,
+	"transport":  ogame.Transport,
+	"deploy":     ogame.Park,
+	"park":       ogame.Park,
+	"acsdefend":  ogame.ParkInThatAlly,
+	"hold":       ogame.ParkInThatAlly,
+	"espionage":  ogame.Spy,
+	"spy":        ogame.Spy,
+	"colonize":   ogame.Colonize,
+	"recycle":    ogame.RecycleDebrisField,
+	"harvest":    ogame.RecycleDebrisField,
+	"destroy":    ogame.Destroy,
+	"expedition": ogame.Expedition,
+}
+
+func parseMission(s string) (ogame.MissionID, error) {
+	if mission, ok := missionNames[strings.ToLower(s)]; ok {
+		return mission, nil
+	}
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ogame.MissionID(id), nil
+	}
+	return 0, fmt.Errorf("unknown mission %q", s)
+}
+
+// findCelestial returns the id of the planet or moon at coord (eg: 1:2:3, M:1:2:3 for a moon).
+// An id or an alias is passed through, ogamed resolves it.
+func findCelestial(cl *client, coord string, moon bool) (string, error) {
+	c, err := ogame.ParseCoord(coord)
+	if err != nil {
+		return coord, nil
+	}
+	if moon {
+		c.Type = ogame.MoonType
+	}
+	var planets []ogame.Planet
+	if _, err := cl.do(http.MethodGet, "/bot/planets", nil, &planets); err != nil {
+		return "", err
+	}
+	for _, p := range planets {
+		if p.Coordinate.Galaxy != c.Galaxy || p.Coordinate.System != c.System || p.Coordinate.Position != c.Position {
+			continue
+		}
+		if c.Type != ogame.MoonType {
+			return strconv.FormatInt(int64(p.ID), 10), nil
+		}
+		if p.Moon != nil {
+			return strconv.FormatInt(int64(p.Moon.ID), 10), nil
+		}
+	}
+	return "", fmt.Errorf("no celestial at %s", c)
+}
+
+func planetsAction(c *cli.Context) error {
+	cl := newClient(c)
+	var planets []ogame.Planet
+	raw, err := cl.do(http.MethodGet, "/bot/planets", nil, &planets)
+	if err != nil {
+		return err
+	}
+	if c.Bool("json") {
+		return printJSON(raw)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCOORD\tFIELDS\tMOON")
+	for _, p := range planets {
+		moon := ""
+		if p.Moon != nil {
+			moon = fmt.Sprintf("%s (%d)", p.Moon.Name, p.Moon.ID)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\n", p.ID, p.Name, p.Coordinate, p.Fields.Built, p.Fields.Total, moon)
+	}
+	return w.Flush()
+}
+
+func sendFleetAction(c *cli.Context) error {
+	cl := newClient(c)
+	if c.String("from") == "" || c.String("to") == "" {
+		return errors.New("--from and --to are required")
+	}
+	celestialID, err := findCelestial(cl, c.String("from"), c.Bool("from-moon"))
+	if err != nil {
+		return err
+	}
+	where, err := ogame.ParseCoord(c.String("to"))
+	if err != nil {
+		return fmt.Errorf("invalid destination %q", c.String("to"))
+	}
+	if c.Bool("to-moon") {
+		where.Type = ogame.MoonType
+	} else if c.Bool("to-debris") {
+		where.Type = ogame.DebrisType
+	}
+	ships, err := parseShips(c.StringSlice("ships"))
+	if err != nil {
+		return err
+	}
+	mission, err := parseMission(c.String("mission"))
+	if err != nil {
+		return err
+	}
+	form := url.Values{}
+	for _, s := range ships {
+		form.Add("ships", fmt.Sprintf("%d,%d", s.ID, s.Nbr))
+	}
+	form.Set("galaxy", strconv.FormatInt(where.Galaxy, 10))
+	form.Set("system", strconv.FormatInt(where.System, 10))
+	form.Set("position", strconv.FormatInt(where.Position, 10))
+	form.Set("type", strconv.FormatInt(int64(where.Type), 10))
+	form.Set("mission", strconv.FormatInt(int64(mission), 10))
+	form.Set("speed", strconv.FormatInt(c.Int64("speed"), 10))
+	form.Set("metal", strconv.FormatInt(c.Int64("metal"), 10))
+	form.Set("crystal", strconv.FormatInt(c.Int64("crystal"), 10))
+	form.Set("deuterium", strconv.FormatInt(c.Int64("deuterium"), 10))
+	if duration := c.Int64("duration"); duration > 0 {
+		form.Set("duration", strconv.FormatInt(duration, 10))
+	}
+	path := "/bot/planets/" + url.PathEscape(celestialID) + "/send-fleet"
+	if c.Bool("dry-run") {
+		raw, err := cl.do(http.MethodPost, path+"?dryRun=1", form, nil)
+		if err != nil {
+			return err
+		}
+		return printJSON(raw)
+	}
+	var fleet ogame.Fleet
+	raw, err := cl.do(http.MethodPost, path, form, &fleet)
+	if err != nil {
+		return err
+	}
+	if c.Bool("json") {
+		return printJSON(raw)
+	}
+	fmt.Printf("fleet %d sent: %s %s -> %s, arrives at %s\n", fleet.ID, fleet.Mission, fleet.Origin, fleet.Destination,
+		fleet.ArrivalTime.Format(time.RFC3339))
+	return nil
+}
+
+// getAction sends a GET request to any ogamed route and prints the result
+func getAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("usage: ogamectl get <path>")
+	}
+	path := c.Args().First()
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	raw, err := newClient(c).do(http.MethodGet, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(raw)
+}
+
+func main() {
+	app := cli.App{}
+	app.Name = "ogamectl"
+	app.Usage = "ogamed command line client"
+	app.Version = version
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:    "addr",
+			Usage:   "ogamed address, with the base path if any",
+			Value:   "http://127.0.0.1:8080",
+			EnvVars: []string{"OGAMECTL_ADDR"},
+		},
+		&cli.StringFlag{
+			Name:    "api-key",
+			Usage:   "API key sent in the X-API-Key header",
+			EnvVars: []string{"OGAMECTL_API_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "token",
+			Usage:   "JWT sent in the Authorization header",
+			EnvVars: []string{"OGAMECTL_TOKEN"},
+		},
+		&cli.StringFlag{
+			Name:    "basic-auth",
+			Usage:   "Basic auth credentials (user:password)",
+			EnvVars: []string{"OGAMECTL_BASIC_AUTH"},
+		},
+		&cli.DurationFlag{
+			Name:    "timeout",
+			Usage:   "Request timeout",
+			Value:   2 * time.Minute,
+			EnvVars: []string{"OGAMECTL_TIMEOUT"},
+		},
+		&cli.BoolFlag{
+			Name:    "json",
+			Usage:   "Print the raw JSON result",
+			EnvVars: []string{"OGAMECTL_JSON"},
+		},
+	}
+	app.Commands = []*cli.Command{
+		{
+			Name:   "planets",
+			Usage:  "List the planets",
+			Action: planetsAction,
+		},
+		{
+			Name:  "send-fleet",
+			Usage: "Send a fleet, eg: send-fleet --from 1:1:1 --to 1:1:5 --ships lc:100 --mission transport",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "from", Usage: "Origin coordinate (M:1:2:3 for a moon), id or alias"},
+				&cli.BoolFlag{Name: "from-moon", Usage: "Send from the moon at --from"},
+				&cli.StringFlag{Name: "to", Usage: "Destination coordinate (M:1:2:3 for a moon, D:1:2:3 for a debris field)"},
+				&cli.BoolFlag{Name: "to-moon", Usage: "Send to the moon at --to"},
+				&cli.BoolFlag{Name: "to-debris", Usage: "Send to the debris field at --to"},
+				&cli.StringSliceFlag{Name: "ships", Usage: "Ships to send (eg: lc:100,sc:5)"},
+				&cli.StringFlag{Name: "mission", Usage: "Mission name or id", Value: "transport"},
+				&cli.Int64Flag{Name: "speed", Usage: "Speed, 1 (10%) to 10 (100%)", Value: 10},
+				&cli.Int64Flag{Name: "metal", Usage: "Metal to carry"},
+				&cli.Int64Flag{Name: "crystal", Usage: "Crystal to carry"},
+				&cli.Int64Flag{Name: "deuterium", Usage: "Deuterium to carry"},
+				&cli.Int64Flag{Name: "duration", Usage: "Hours of expedition or hold"},
+				&cli.BoolFlag{Name: "dry-run", Usage: "Only validate the fleet"},
+			},
+			Action: sendFleetAction,
+		},
+		{
+			Name:      "get",
+			Usage:     "GET any ogamed route and print the result (eg: get /bot/server)",
+			ArgsUsage: "<path>",
+			Action:    getAction,
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
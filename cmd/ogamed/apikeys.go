@@ -0,0 +1,174 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/labstack/echo"
+)
+
+// API key scopes, each scope includes the previous ones
+const (
+	scopeRead  = "read"  // Routes that only read the game or the daemon state
+	scopeFleet = "fleet" // Every game action (send fleet, build...) and the plugin routes
+	scopeAdmin = "admin" // /admin/*, session management and the daemon settings (aliases, watches, webhooks...)
+)
+
+var scopeLevels = map[string]int{scopeRead: 1, scopeFleet: 2, scopeAdmin: 3}
+
+// routeScopes scope required by each route (method and echo path), declared when the route is registered
+type routeScopes map[string]string
+
+// scopedRoutes registers routes that require scope
+type scopedRoutes struct {
+	e      *echo.Echo
+	scope  string
+	scopes routeScopes
+}
+
+// group returns the registrar of the routes requiring scope
+func (s routeScopes) group(e *echo.Echo, scope string) scopedRoutes {
+	return scopedRoutes{e: e, scope: scope, scopes: s}
+}
+
+// required returns the scope needed to call a route, the routes registered without scope require the admin scope
+func (s routeScopes) required(method, path string) string {
+	if scope, ok := s[method+" "+path]; ok {
+		return scope
+	}
+	return scopeAdmin
+}
+
+// Add registers a route
+func (r scopedRoutes) Add(method, path string, h echo.HandlerFunc) {
+	route := r.e.Add(method, path, h)
+	r.scopes[route.Method+" "+route.Path] = r.scope
+}
+
+// GET registers a GET route
+func (r scopedRoutes) GET(path string, h echo.HandlerFunc) { r.Add(http.MethodGet, path, h) }
+
+// POST registers a POST route
+func (r scopedRoutes) POST(path string, h echo.HandlerFunc) { r.Add(http.MethodPost, path, h) }
+
+// DELETE registers a DELETE route
+func (r scopedRoutes) DELETE(path string, h echo.HandlerFunc) { r.Add(http.MethodDelete, path, h) }
+
+// HEAD registers a HEAD route
+func (r scopedRoutes) HEAD(path string, h echo.HandlerFunc) { r.Add(http.MethodHead, path, h) }
+
+// apiKeyConfig api key defined in the config file
+type apiKeyConfig struct {
+	Name  string `json:"name"`
+	Key   string `json:"key"`
+	Scope string `json:"scope"`
+	// RPS max requests per second for this key, 0 means unlimited
+	RPS float64 `json:"rps"`
+}
+
+type apiKey struct {
+	apiKeyConfig
+	sync.Mutex
+	tokens   float64
+	lastFill time.Time
+}
+
+// allow consumes a token from the key bucket, the bucket holds at most one second of requests
+func (k *apiKey) allow(now time.Time) bool {
+	if k.RPS <= 0 {
+		return true
+	}
+	k.Lock()
+	defer k.Unlock()
+	if k.lastFill.IsZero() {
+		k.tokens = k.RPS
+	} else {
+		k.tokens += now.Sub(k.lastFill).Seconds() * k.RPS
+	}
+	if k.tokens > k.RPS {
+		k.tokens = k.RPS
+	}
+	k.lastFill = now
+	if k.tokens < 1 {
+		return false
+	}
+	k.tokens--
+	return true
+}
+
+func hasScope(granted, required string) bool {
+	return scopeLevels[granted] >= scopeLevels[required]
+}
+
+// buildAPIKeys creates the api keys, the rate limiter state of unchanged keys is kept
+func buildAPIKeys(cfgs []apiKeyConfig, previous []*apiKey) []*apiKey {
+	keys := make([]*apiKey, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.Key == "" || scopeLevels[cfg.Scope] == 0 {
+			continue
+		}
+		key := &apiKey{apiKeyConfig: cfg}
+		for _, prev := range previous {
+			if prev.apiKeyConfig == cfg {
+				key = prev
+				break
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// findAPIKey finds a key using constant time comparisons
+func (r *runtimeConfig) findAPIKey(value string) *apiKey {
+	r.RLock()
+	defer r.RUnlock()
+	var found *apiKey
+	for _, k := range r.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(k.Key)) == 1 {
+			found = k
+		}
+	}
+	return found
+}
+
+// HasAPIKeys returns either or not api keys are configured
+func (r *runtimeConfig) HasAPIKeys() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return len(r.apiKeys) > 0
+}
+
+// APIKeyMiddleware authenticates requests carrying a X-API-Key header, checks the key scope against the scope the
+// route was registered with, and the key rate limit. The basic auth check is skipped for these requests.
+func (r *runtimeConfig) APIKeyMiddleware(scopes routeScopes) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			value := c.Request().Header.Get("X-API-Key")
+			if value == "" {
+				return next(c)
+			}
+			key := r.findAPIKey(value)
+			if key == nil {
+				return c.JSON(http.StatusUnauthorized, handlers.ErrorResp(401, "invalid api key"))
+			}
+			if !hasScope(key.Scope, scopes.required(c.Request().Method, c.Path())) {
+				return c.JSON(http.StatusForbidden, handlers.ErrorResp(403, "api key scope does not allow this action"))
+			}
+			if !key.allow(time.Now()) {
+				return c.JSON(http.StatusTooManyRequests, handlers.ErrorResp(429, "api key rate limit exceeded"))
+			}
+			c.Set("apiKey", key.Name)
+			return next(c)
+		}
+	}
+}
+
+// isAPIKeyAuthenticated returns either or not the request was authenticated by an api key
+func isAPIKeyAuthenticated(c echo.Context) bool {
+	_, ok := c.Get("apiKey").(string)
+	return ok
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyMiddleware_Scopes(t *testing.T) {
+	r := newRuntimeConfig("", config{})
+	r.apiKeys = buildAPIKeys([]apiKeyConfig{
+		{Name: "reader", Key: "read-key", Scope: scopeRead},
+		{Name: "fleet", Key: "fleet-key", Scope: scopeFleet},
+		{Name: "admin", Key: "admin-key", Scope: scopeAdmin},
+	}, nil)
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e := echo.New()
+	scopes := make(routeScopes)
+	e.Use(r.APIKeyMiddleware(scopes))
+	scopes.group(e, scopeRead).GET("/bot/planets", ok)
+	scopes.group(e, scopeFleet).POST("/bot/planets/:planetID/send-fleet", ok)
+	scopes.group(e, scopeAdmin).POST("/bot/aliases/:alias", ok)
+	e.GET("/unscoped", ok)
+
+	tests := []struct {
+		key    string
+		method string
+		path   string
+		code   int
+	}{
+		{"", http.MethodGet, "/bot/planets", http.StatusOK}, // Left to the basic auth
+		{"invalid", http.MethodGet, "/bot/planets", http.StatusUnauthorized},
+		{"read-key", http.MethodGet, "/bot/planets", http.StatusOK},
+		{"read-key", http.MethodPost, "/bot/planets/1/send-fleet", http.StatusForbidden},
+		{"read-key", http.MethodPost, "/bot/aliases/main", http.StatusForbidden},
+		{"fleet-key", http.MethodGet, "/bot/planets", http.StatusOK},
+		{"fleet-key", http.MethodPost, "/bot/planets/1/send-fleet", http.StatusOK},
+		{"fleet-key", http.MethodPost, "/bot/aliases/main", http.StatusForbidden},
+		{"fleet-key", http.MethodGet, "/unscoped", http.StatusForbidden},
+		{"admin-key", http.MethodPost, "/bot/aliases/main", http.StatusOK},
+		{"admin-key", http.MethodGet, "/unscoped", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, tt.code, rec.Code, tt.key+" "+tt.method+" "+tt.path)
+	}
+}
+
+func TestRouteScopes_Required(t *testing.T) {
+	scopes := routeScopes{"GET /bot/planets": scopeRead}
+	assert.Equal(t, scopeRead, scopes.required(http.MethodGet, "/bot/planets"))
+	assert.Equal(t, scopeAdmin, scopes.required(http.MethodPost, "/bot/planets"))
+	assert.Equal(t, scopeAdmin, scopes.required(http.MethodGet, ""))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/labstack/echo"
+)
+
+// brokerRoutes routes available in session broker mode: login, captcha, session credentials and raw passthrough.
+// Every other route (game logic) answers 404.
+var brokerRoutes = map[string]bool{
+	"/":                      true,
+	"/status":                true,
+	"/healthz":               true,
+	"/readyz":                true,
+	"/admin/reload":          true,
+	"/auth/token":            true,
+	"/auth/refresh":          true,
+	"/bot/captcha":           true,
+	"/bot/captcha/icons":     true,
+	"/bot/captcha/question":  true,
+	"/bot/captcha/solve":     true,
+	"/bot/login":             true,
+	"/bot/logout":            true,
+	"/bot/session":           true,
+	"/bot/server-url":        true,
+	"/bot/safe-mode":         true,
+	"/bot/resume":            true,
+	"/bot/proxies":           true,
+	"/bot/page-content":      true,
+	"/game/index.php":        true,
+	"/game/allianceInfo.php": true,
+	"/cdn/*":                 true,
+	"/assets/css/*":          true,
+	"/headerCache/*":         true,
+	"/favicon.ico":           true,
+	"/game/sw.js":            true,
+	"/api/*":                 true,
+}
+
+// sessionBrokerMiddleware disables all the game logic routes
+func sessionBrokerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !brokerRoutes[c.Path()] {
+			return c.JSON(http.StatusNotFound, handlers.ErrorResp(404, "not available in session broker mode"))
+		}
+		return next(c)
+	}
+}
+
+// keepSessionAlive periodically loads a page so an expired session is restored (re-login) before clients need it
+func keepSessionAlive(bot *ogame.OGame, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if !bot.IsLoggedIn() {
+				continue
+			}
+			if _, err := bot.GetPageContent(url.Values{"page": {"ingame"}, "component": {"overview"}}); err != nil {
+				log.Println("session keep alive failed: " + err.Error())
+			}
+		}
+	}()
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"time"
+
+	"github.com/alaingilbert/ogame"
+)
+
+// Login modes of the --login-mode flag
+const (
+	loginModeHTTP    = "http"
+	loginModeBrowser = "browser"
+)
+
+// newBrowserLogin creates the headless browser login used by --login-mode browser.
+// It is nil unless ogamed is built with the chromedp tag (go build -tags chromedp ./cmd/ogamed).
+var newBrowserLogin func(headless bool, timeout time.Duration) ogame.BrowserLogin
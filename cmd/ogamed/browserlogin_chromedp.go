@@ -0,0 +1,79 @@
+//go:build chromedp
+// +build chromedp
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// gfTokenCookieName cookie holding the gameforge bearer token once logged in the lobby
+const gfTokenCookieName = "gf-token-production"
+
+func init() {
+	newBrowserLogin = chromedpLogin
+}
+
+// chromedpLogin drives the lobby login form in chrome. When a captcha shows up, a non headless browser lets a human
+// solve it in the window, the login waits for the gf-token cookie until the timeout.
+func chromedpLogin(headless bool, timeout time.Duration) ogame.BrowserLogin {
+	return func(ctx context.Context, lobby, username, password string) (ogame.BrowserLoginResult, error) {
+		var res ogame.BrowserLoginResult
+		opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.Flag("headless", headless))
+		allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+		defer cancelAlloc()
+		browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+		defer cancelBrowser()
+		browserCtx, cancelTimeout := context.WithTimeout(browserCtx, timeout)
+		defer cancelTimeout()
+
+		err := chromedp.Run(browserCtx,
+			chromedp.Navigate("https://"+lobby+".ogame.gameforge.com/en_GB/"),
+			chromedp.WaitVisible(`#loginRegisterTabs`, chromedp.ByQuery),
+			chromedp.Click(`#loginRegisterTabs .tabsList li:first-child`, chromedp.ByQuery),
+			chromedp.SendKeys(`#loginForm input[name=email]`, username, chromedp.ByQuery),
+			chromedp.SendKeys(`#loginForm input[name=password]`, password, chromedp.ByQuery),
+			chromedp.Click(`#loginForm button[type=submit]`, chromedp.ByQuery),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				for {
+					cookies, err := network.GetAllCookies().Do(ctx)
+					if err != nil {
+						return err
+					}
+					for _, c := range cookies {
+						if c.Name == gfTokenCookieName && c.Value != "" {
+							res.BearerToken = c.Value
+						}
+					}
+					if res.BearerToken != "" {
+						for _, c := range cookies {
+							res.Cookies = append(res.Cookies, &http.Cookie{
+								Name:     c.Name,
+								Value:    c.Value,
+								Path:     c.Path,
+								Domain:   c.Domain,
+								Expires:  time.Unix(int64(c.Expires), 0),
+								Secure:   c.Secure,
+								HttpOnly: c.HTTPOnly,
+							})
+						}
+						return nil
+					}
+					select {
+					case <-time.After(time.Second):
+					case <-ctx.Done():
+						return errors.New("browser login timed out, captcha not solved ?")
+					}
+				}
+			}),
+		)
+		return res, err
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/labstack/echo"
+)
+
+// buildAndWaitPayload result of a build-and-wait request, posted to its callback
+type buildAndWaitPayload struct {
+	Type        string            `json:"type"` // construction_finished | construction_failed
+	Time        time.Time         `json:"time"`
+	CelestialID ogame.CelestialID `json:"celestial_id"`
+	ID          ogame.ID          `json:"id"`
+	Error       string            `json:"error,omitempty"`
+}
+
+func newBuildAndWaitPayload(celestialID ogame.CelestialID, id ogame.ID, err error) buildAndWaitPayload {
+	payload := buildAndWaitPayload{Type: "construction_finished", Time: time.Now(), CelestialID: celestialID, ID: id}
+	if err != nil {
+		payload.Type = "construction_failed"
+		payload.Error = err.Error()
+	}
+	return payload
+}
+
+// BuildAndWaitHandler starts the construction of a building or research and answers once it is done.
+// With a callback url, it answers right away and the result is posted to the callback when the construction is done,
+// signed like the webhooks if secret is given. The callback host must be listed in the callback_hosts of the config.
+// curl 127.0.0.1:1234/bot/planets/123/build-and-wait/1
+// curl 127.0.0.1:1234/bot/planets/123/build-and-wait/1 -d 'callback=https://example.com/hook&secret=s3cr3t'
+func (n *webhookNotifier) BuildAndWaitHandler(c echo.Context) error {
+	if handlers.InTx(c) {
+		return handlers.TxNotSupported(c)
+	}
+	celestialID, ok := n.bot.ResolveCelestialAlias(c.Param("planetID"))
+	if !ok {
+		id, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, handlers.ErrorResp(400, "invalid planet id"))
+		}
+		celestialID = ogame.CelestialID(id)
+	}
+	ogameID, err := strconv.ParseInt(c.Param("ogameID"), 10, 64)
+	if err != nil || (!ogame.ID(ogameID).IsBuilding() && !ogame.ID(ogameID).IsTech()) {
+		return c.JSON(http.StatusBadRequest, handlers.ErrorResp(400, "invalid ogame id"))
+	}
+	id := ogame.ID(ogameID)
+	hook := webhookConfig{URL: c.FormValue("callback"), Secret: c.FormValue("secret")}
+	if hook.URL == "" {
+		if err := n.bot.BuildAndWait(c.Request().Context(), celestialID, id); err != nil {
+			resp := handlers.ErrorRespOf(err, http.StatusInternalServerError)
+			return c.JSON(resp.Code, resp)
+		}
+		return c.JSON(http.StatusOK, handlers.SuccessResp(newBuildAndWaitPayload(celestialID, id, nil)))
+	}
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return c.JSON(http.StatusBadRequest, handlers.ErrorResp(400, "invalid callback"))
+	}
+	// The callback is posted from the daemon network, only the hosts of the config file can be reached
+	if !n.cfg.AllowsCallback(u) {
+		return c.JSON(http.StatusForbidden, handlers.ErrorResp(403, "callback host not allowed, add it to callback_hosts"))
+	}
+	go func() {
+		payload := newBuildAndWaitPayload(celestialID, id, n.bot.BuildAndWait(context.Background(), celestialID, id))
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		n.send(hook, payload.Type, body)
+	}()
+	resp := handlers.SuccessResp(nil)
+	resp.Code = http.StatusAccepted
+	return c.JSON(http.StatusAccepted, resp)
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// config settings that can be changed without restarting ogamed.
+// Every field is optional, an empty value keeps the value given by the command line flags.
+type config struct {
+	Proxy             string `json:"proxy"`
+	ProxyUsername     string `json:"proxy_username"`
+	ProxyPassword     string `json:"proxy_password"`
+	ProxyType         string `json:"proxy_type"`
+	ProxyLoginOnly    *bool  `json:"proxy_login_only"`
+	BasicAuthUsername string `json:"basic_auth_username"`
+	BasicAuthPassword string `json:"basic_auth_password"`
+	UserAgent         string `json:"user_agent"`
+	// Aliases celestial aliases (eg: "main", "moonbase") usable instead of celestial ids
+	Aliases map[string]int64 `json:"aliases"`
+	// APIKeys keys sent in the X-API-Key header, with a scope (read, fleet, admin) and an optional rate limit
+	APIKeys []apiKeyConfig `json:"api_keys"`
+	// Webhooks urls called when the bot starts being under attack, payloads are signed when a secret is set
+	Webhooks []webhookConfig `json:"webhooks"`
+	// BuildTemplates named lists of building levels (eg: "colony"), built in order by the colonize workflows
+	BuildTemplates map[string][]buildTemplateItemConfig `json:"build_templates"`
+	// FleetTemplates named fleet dispatches (eg: "expo1"), sent by the send-fleet-template cron action
+	FleetTemplates map[string]fleetTemplateConfig `json:"fleet_templates"`
+	// Cron recurring actions, invalid entries are logged and skipped
+	Cron []cronEntryConfig `json:"cron"`
+	// CallbackHosts hosts (eg: "hooks.example.com") the build-and-wait callbacks may be posted to, none if empty
+	CallbackHosts []string `json:"callback_hosts"`
+}
+
+// buildTemplateItemConfig building level of a build template, eg: {"id": 1, "level": 10}
+type buildTemplateItemConfig struct {
+	ID    int64 `json:"id"`
+	Level int64 `json:"level"`
+}
+
+func loadConfig(filename string) (config, error) {
+	var cfg config
+	by, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(by, &cfg); err != nil {
+		return cfg, err
+	}
+	err = resolveConfigCredentials(&cfg)
+	return cfg, err
+}
+
+// resolveConfigCredentials resolves the secrets of the config given as credential provider references
+func resolveConfigCredentials(cfg *config) error {
+	secrets := []*string{&cfg.ProxyPassword, &cfg.BasicAuthPassword}
+	for i := range cfg.APIKeys {
+		secrets = append(secrets, &cfg.APIKeys[i].Key)
+	}
+	for i := range cfg.Webhooks {
+		secrets = append(secrets, &cfg.Webhooks[i].Secret)
+	}
+	return resolveCredentials(secrets...)
+}
+
+// runtimeConfig holds the live settings of the daemon, it is safe for concurrent use
+type runtimeConfig struct {
+	sync.RWMutex
+	filename          string
+	defaults          config
+	basicAuthUsername string
+	basicAuthPassword string
+	apiKeys           []*apiKey
+	webhooks          []webhookConfig
+	callbackHosts     []string
+	aliases           map[string]bool // Aliases set from the config file
+	buildTemplates    map[string]bool // Build templates set from the config file
+	fleetTemplates    map[string]bool // Fleet templates set from the config file
+	stopCron          func()
+}
+
+func newRuntimeConfig(filename string, defaults config) *runtimeConfig {
+	return &runtimeConfig{
+		filename:          filename,
+		defaults:          defaults,
+		basicAuthUsername: defaults.BasicAuthUsername,
+		basicAuthPassword: defaults.BasicAuthPassword,
+		aliases:           make(map[string]bool),
+		buildTemplates:    make(map[string]bool),
+		fleetTemplates:    make(map[string]bool),
+	}
+}
+
+// merge overrides the defaults with the non-empty values of cfg
+func (r *runtimeConfig) merge(cfg config) config {
+	res := r.defaults
+	if cfg.Proxy != "" {
+		res.Proxy = cfg.Proxy
+		res.ProxyUsername = cfg.ProxyUsername
+		res.ProxyPassword = cfg.ProxyPassword
+	}
+	if cfg.ProxyType != "" {
+		res.ProxyType = cfg.ProxyType
+	}
+	if cfg.ProxyLoginOnly != nil {
+		res.ProxyLoginOnly = cfg.ProxyLoginOnly
+	}
+	if cfg.BasicAuthUsername != "" || cfg.BasicAuthPassword != "" {
+		res.BasicAuthUsername = cfg.BasicAuthUsername
+		res.BasicAuthPassword = cfg.BasicAuthPassword
+	}
+	if cfg.UserAgent != "" {
+		res.UserAgent = cfg.UserAgent
+	}
+	res.Aliases = cfg.Aliases
+	res.APIKeys = cfg.APIKeys
+	res.Webhooks = cfg.Webhooks
+	res.BuildTemplates = cfg.BuildTemplates
+	res.FleetTemplates = cfg.FleetTemplates
+	res.Cron = cfg.Cron
+	res.CallbackHosts = cfg.CallbackHosts
+	return res
+}
+
+// Reload re-reads the config file and applies it to the bot without losing the session
+func (r *runtimeConfig) Reload(bot *ogame.OGame) error {
+	if r.filename == "" {
+		return nil
+	}
+	fileCfg, err := loadConfig(r.filename)
+	if err != nil {
+		return err
+	}
+	cfg := r.merge(fileCfg)
+	proxyLoginOnly := cfg.ProxyLoginOnly != nil && *cfg.ProxyLoginOnly
+	// Keep the proxy pool unless the config file explicitly sets a single proxy
+	if cfg.Proxy != "" || bot.GetProxyPool() == nil {
+		if err := bot.SetProxy(cfg.Proxy, cfg.ProxyUsername, cfg.ProxyPassword, cfg.ProxyType, proxyLoginOnly, nil); err != nil {
+			return err
+		}
+	}
+	if cfg.UserAgent != "" {
+		bot.SetUserAgent(cfg.UserAgent)
+	}
+	// Remove the aliases of the previous config that are no longer in the file
+	r.Lock()
+	for alias := range r.aliases {
+		if _, ok := cfg.Aliases[alias]; !ok {
+			bot.RemoveCelestialAlias(alias)
+			delete(r.aliases, alias)
+		}
+	}
+	for alias, celestialID := range cfg.Aliases {
+		bot.SetCelestialAlias(alias, ogame.CelestialID(celestialID))
+		r.aliases[alias] = true
+	}
+	r.Unlock()
+	// Remove the build templates of the previous config that are no longer in the file
+	r.Lock()
+	for name := range r.buildTemplates {
+		if _, ok := cfg.BuildTemplates[name]; !ok {
+			bot.RemoveBuildTemplate(name)
+			delete(r.buildTemplates, name)
+		}
+	}
+	r.Unlock()
+	for name, items := range cfg.BuildTemplates {
+		template := make([]ogame.BuildTemplateItem, len(items))
+		for i, item := range items {
+			template[i] = ogame.BuildTemplateItem{ID: ogame.ID(item.ID), Level: item.Level}
+		}
+		if err := bot.SetBuildTemplate(name, template); err != nil {
+			return err
+		}
+		r.Lock()
+		r.buildTemplates[name] = true
+		r.Unlock()
+	}
+	// Remove the templates of the previous config that are no longer in the file
+	r.Lock()
+	for name := range r.fleetTemplates {
+		if _, ok := cfg.FleetTemplates[name]; !ok {
+			bot.RemoveFleetTemplate(name)
+			delete(r.fleetTemplates, name)
+		}
+	}
+	r.Unlock()
+	for name, t := range cfg.FleetTemplates {
+		template, err := t.toFleetTemplate()
+		if err == nil {
+			err = bot.SetFleetTemplate(name, template)
+		}
+		if err != nil {
+			return errors.New("fleet template " + name + ": " + err.Error())
+		}
+		r.Lock()
+		r.fleetTemplates[name] = true
+		r.Unlock()
+	}
+	jobs, errs := compileCronJobs(cfg)
+	for _, err := range errs {
+		log.Println(err)
+	}
+	r.Lock()
+	if r.stopCron != nil {
+		r.stopCron()
+	}
+	r.stopCron = startCronJobs(bot, jobs)
+	r.basicAuthUsername = cfg.BasicAuthUsername
+	r.basicAuthPassword = cfg.BasicAuthPassword
+	r.apiKeys = buildAPIKeys(cfg.APIKeys, r.apiKeys)
+	r.webhooks = cfg.Webhooks
+	r.callbackHosts = cfg.CallbackHosts
+	r.Unlock()
+	log.Println("Configuration reloaded from " + r.filename)
+	return nil
+}
+
+// WatchSIGHUP reloads the configuration every time the process receives a SIGHUP
+func (r *runtimeConfig) WatchSIGHUP(bot *ogame.OGame) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			if err := r.Reload(bot); err != nil {
+				log.Println("failed to reload configuration: " + err.Error())
+			}
+		}
+	}()
+}
+
+// Webhooks returns the webhooks defined in the config file
+func (r *runtimeConfig) Webhooks() []webhookConfig {
+	r.RLock()
+	defer r.RUnlock()
+	return append([]webhookConfig{}, r.webhooks...)
+}
+
+// AllowsCallback returns true if the host of the callback url is one of the callback_hosts of the config file
+func (r *runtimeConfig) AllowsCallback(u *url.URL) bool {
+	r.RLock()
+	defer r.RUnlock()
+	for _, host := range r.callbackHosts {
+		if strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasBasicAuth returns either or not basic auth credentials are configured
+func (r *runtimeConfig) HasBasicAuth() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return len(r.basicAuthUsername) > 0 && len(r.basicAuthPassword) > 0
+}
+
+// ValidateBasicAuth validates the credentials against the current configuration, the username of valid credentials is
+// kept in the context for basicAuthUser
+func (r *runtimeConfig) ValidateBasicAuth(username, password string, c echo.Context) (bool, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if len(r.basicAuthUsername) == 0 || len(r.basicAuthPassword) == 0 {
+		return false, nil
+	}
+	// Be careful to use constant time comparison to prevent timing attacks
+	if subtle.ConstantTimeCompare([]byte(username), []byte(r.basicAuthUsername)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(r.basicAuthPassword)) == 1 {
+		c.Set("basicAuthUser", username)
+		return true, nil
+	}
+	return false, nil
+}
+
+// basicAuthMiddleware checks the basic auth credentials of the requests not authenticated by a token or an api key
+func basicAuthMiddleware(r *runtimeConfig, statusPageEnabled bool) echo.MiddlewareFunc {
+	return middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		Skipper: func(c echo.Context) bool {
+			// With api keys configured, requests without credentials are rejected even if basic auth is not set
+			return (!r.HasBasicAuth() && !r.HasAPIKeys()) || (statusPageEnabled && c.Path() == "/status") ||
+				c.Path() == "/healthz" || c.Path() == "/readyz" ||
+				isJWTAuthenticated(c) || isAPIKeyAuthenticated(c)
+		},
+		Validator: r.ValidateBasicAuth,
+	})
+}
+
+// basicAuthUser returns the user authenticated by the basic auth middleware, if the request was authenticated by it
+func basicAuthUser(c echo.Context) (string, bool) {
+	username, ok := c.Get("basicAuthUser").(string)
+	return username, ok
+}
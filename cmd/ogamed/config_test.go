@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeConfig_Reload_Aliases(t *testing.T) {
+	f, _ := ioutil.TempFile("", "ogamed-config")
+	defer os.Remove(f.Name())
+	bot, _ := ogame.NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	bot.SetCelestialAlias("api", 3) // Set with the api, kept by the reloads
+	r := newRuntimeConfig(f.Name(), config{})
+
+	_ = ioutil.WriteFile(f.Name(), []byte(`{"aliases": {"main": 1, "moonbase": 2}}`), 0600)
+	assert.NoError(t, r.Reload(bot))
+	assert.Equal(t, map[string]ogame.CelestialID{"main": 1, "moonbase": 2, "api": 3}, bot.GetCelestialAliases())
+
+	// The aliases removed from the file are removed from the bot
+	_ = ioutil.WriteFile(f.Name(), []byte(`{"aliases": {"main": 4}}`), 0600)
+	assert.NoError(t, r.Reload(bot))
+	assert.Equal(t, map[string]ogame.CelestialID{"main": 4, "api": 3}, bot.GetCelestialAliases())
+}
+
+func TestRuntimeConfig_Reload_BuildTemplates(t *testing.T) {
+	f, _ := ioutil.TempFile("", "ogamed-config")
+	defer os.Remove(f.Name())
+	bot, _ := ogame.NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	r := newRuntimeConfig(f.Name(), config{})
+
+	_ = ioutil.WriteFile(f.Name(), []byte(`{"build_templates": {"colony": [{"id": 1, "level": 10}], "moon": [{"id": 41, "level": 1}]}}`), 0600)
+	assert.NoError(t, r.Reload(bot))
+	assert.Equal(t, 2, len(bot.GetBuildTemplates()))
+
+	// The templates removed from the file can no longer be used by the workflows
+	_ = ioutil.WriteFile(f.Name(), []byte(`{"build_templates": {"colony": [{"id": 1, "level": 12}]}}`), 0600)
+	assert.NoError(t, r.Reload(bot))
+	assert.Equal(t, map[string][]ogame.BuildTemplateItem{"colony": {{ID: ogame.MetalMineID, Level: 12}}}, bot.GetBuildTemplates())
+}
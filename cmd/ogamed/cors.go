@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// corsConfig cross-origin requests allowed by ogamed
+type corsConfig struct {
+	Origins     []string // Exact origins (eg: "http://localhost:3000"), or "*" for any origin
+	Methods     []string
+	Credentials bool // Allow the browsers to send the basic auth credentials and cookies
+}
+
+// splitCORSList splits a comma separated flag value, empty items are ignored
+func splitCORSList(s string) []string {
+	res := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// newCORSConfig validates the allowed origins and methods, origins are normalized without trailing slash
+func newCORSConfig(origins, methods []string, credentials bool) (corsConfig, error) {
+	cfg := corsConfig{Credentials: credentials}
+	for _, origin := range origins {
+		if origin == "*" {
+			if credentials {
+				return cfg, errors.New("cors credentials cannot be allowed for any origin (*)")
+			}
+			cfg.Origins = append(cfg.Origins, origin)
+			continue
+		}
+		u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return cfg, errors.New("invalid cors origin " + origin + ", expected scheme://host[:port]")
+		}
+		cfg.Origins = append(cfg.Origins, u.Scheme+"://"+u.Host)
+	}
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			cfg.Methods = append(cfg.Methods, method)
+		default:
+			return cfg, errors.New("invalid cors method " + method)
+		}
+	}
+	return cfg, nil
+}
+
+// allowsOrigin returns true if cross-origin requests from origin are allowed
+func (c corsConfig) allowsOrigin(origin string) bool {
+	for _, o := range c.Origins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// middleware returns the CORS middleware, nil if no origin is allowed
+func (c corsConfig) middleware() echo.MiddlewareFunc {
+	if len(c.Origins) == 0 {
+		return nil
+	}
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:     c.Origins,
+		AllowMethods:     c.Methods,
+		AllowCredentials: c.Credentials,
+	})
+}
+
+// checkWebSocketOrigin accepts the WebSocket handshakes of the non-browser clients (no Origin header), of the pages
+// served by ogamed itself and of the allowed origins. The browsers do not apply CORS to WebSockets.
+func (c corsConfig) checkWebSocketOrigin(req *http.Request) error {
+	origin := req.Header.Get(echo.HeaderOrigin)
+	if origin == "" || c.allowsOrigin(origin) {
+		return nil
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == req.Host {
+		return nil
+	}
+	return errors.New("origin not allowed: " + origin)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCORSConfig(t *testing.T) {
+	cfg, err := newCORSConfig(splitCORSList(" http://localhost:3000/, https://dash.example.com ,"), splitCORSList("get,post"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:3000", "https://dash.example.com"}, cfg.Origins)
+	assert.Equal(t, []string{http.MethodGet, http.MethodPost}, cfg.Methods)
+
+	tests := []struct {
+		origins     []string
+		methods     []string
+		credentials bool
+		err         string
+	}{
+		{[]string{"*"}, nil, true, "cors credentials cannot be allowed for any origin (*)"},
+		{[]string{"localhost:3000"}, nil, false, "invalid cors origin localhost:3000, expected scheme://host[:port]"},
+		{[]string{"http://localhost:3000/app"}, nil, false, "invalid cors origin http://localhost:3000/app, expected scheme://host[:port]"},
+		{[]string{"ftp://localhost"}, nil, false, "invalid cors origin ftp://localhost, expected scheme://host[:port]"},
+		{nil, []string{"TRACE"}, false, "invalid cors method TRACE"},
+	}
+	for _, tt := range tests {
+		_, err := newCORSConfig(tt.origins, tt.methods, tt.credentials)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestCORSConfig_CheckWebSocketOrigin(t *testing.T) {
+	cfg, _ := newCORSConfig([]string{"http://localhost:3000"}, nil, false)
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},                      // Not a browser
+		{"http://localhost:3000", true}, // Allowed origin
+		{"http://127.0.0.1:1234", true}, // Page served by ogamed
+		{"http://evil.example.com", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:1234/tasks/ws", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		assert.Equal(t, tt.allowed, cfg.checkWebSocketOrigin(req) == nil, tt.origin)
+	}
+	assert.Nil(t, corsConfig{}.middleware())
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// credentialProvider resolves the reference of a secret (the part after "<provider>:")
+type credentialProvider func(ref string) (string, error)
+
+// credentialProviders secrets given to the flags and the config file can be references resolved by a provider,
+// so they do not show in ps or in the environment:
+//
+//	keyring:<account>     secret of the OS keyring (service ogamed)
+//	vault:<path>#<field>  field of a HashiCorp Vault secret (VAULT_ADDR and VAULT_TOKEN env vars), eg: vault:secret/data/ogame#password
+//	file:<path>           content of a file, ${VAR} are expanded from the environment
+//
+// Any other value is used as is.
+var credentialProviders = map[string]credentialProvider{
+	"keyring": keyringGet,
+	"vault":   vaultSecret,
+	"file":    fileSecret,
+}
+
+// resolveCredential returns the secret value references, other values are returned as is
+func resolveCredential(value string) (string, error) {
+	idx := strings.Index(value, ":")
+	if idx == -1 {
+		return value, nil
+	}
+	provider, ok := credentialProviders[value[:idx]]
+	if !ok {
+		return value, nil
+	}
+	secret, err := provider(value[idx+1:])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s credential: %s", value[:idx], err.Error())
+	}
+	return secret, nil
+}
+
+// resolveCredentials resolves the values in place
+func resolveCredentials(values ...*string) error {
+	for _, v := range values {
+		secret, err := resolveCredential(*v)
+		if err != nil {
+			return err
+		}
+		*v = secret
+	}
+	return nil
+}
+
+// fileSecret returns the content of a file, without the trailing new line, with the ${VAR} expanded
+func fileSecret(path string) (string, error) {
+	by, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return os.ExpandEnv(strings.TrimRight(string(by), "\r\n")), nil
+}
+
+// vaultSecret returns a field of a secret of HashiCorp Vault, the KV v1 and v2 engines are supported.
+// The field can be omitted if the secret has a single field.
+func vaultSecret(ref string) (string, error) {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN are required")
+	}
+	path, field := ref, ""
+	if idx := strings.LastIndex(ref, "#"); idx != -1 {
+		path, field = ref[:idx], ref[idx+1:]
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("vault responded " + resp.Status)
+	}
+	var res struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	return vaultField(res.Data, field)
+}
+
+// vaultField returns the field of the data of a vault secret
+func vaultField(data map[string]interface{}, field string) (string, error) {
+	// KV v2 nests the secret in data.data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	if field == "" {
+		if len(data) != 1 {
+			return "", errors.New("the secret has several fields, add #<field>")
+		}
+		for k := range data {
+			field = k
+		}
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", errors.New("field " + field + " not found")
+	}
+	return value, nil
+}
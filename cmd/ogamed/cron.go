@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+)
+
+// fleetTemplateConfig named fleet dispatch, eg:
+// {"ships": [{"id": 203, "nbr": 50}], "mission": 15, "destination": "1:50:16", "holding_time": 1}
+type fleetTemplateConfig struct {
+	Ships       []fleetTemplateShipConfig `json:"ships"`
+	Mission     int64                     `json:"mission"`
+	Destination string                    `json:"destination"`
+	Speed       float64                   `json:"speed"` // 1 to 10, 10 if 0
+	HoldingTime int64                     `json:"holding_time"`
+	Metal       int64                     `json:"metal"`
+	Crystal     int64                     `json:"crystal"`
+	Deuterium   int64                     `json:"deuterium"`
+}
+
+// fleetTemplateShipConfig ships of a fleet template, nbr -1 sends all of them
+type fleetTemplateShipConfig struct {
+	ID  int64 `json:"id"`
+	Nbr int64 `json:"nbr"`
+}
+
+// cronEntryConfig recurring action, eg: {"cron": "0 */2 * * *", "action": "send-fleet-template", "name": "expo1", "from": "1:50:8"}
+type cronEntryConfig struct {
+	Cron   string `json:"cron"`
+	Action string `json:"action"`
+	Name   string `json:"name"` // Fleet template of send-fleet-template
+	From   string `json:"from"` // Celestial id, alias or coordinate of send-fleet-template
+}
+
+// Cron actions
+const (
+	cronSendFleetTemplate = "send-fleet-template"
+)
+
+// cronJob compiled cron entry
+type cronJob struct {
+	entry    cronEntryConfig
+	schedule ogame.CronSchedule
+	run      func(bot *ogame.OGame) error
+}
+
+func (t fleetTemplateConfig) toFleetTemplate() (ogame.FleetTemplate, error) {
+	res := ogame.FleetTemplate{
+		Mission:     ogame.MissionID(t.Mission),
+		Speed:       ogame.Speed(t.Speed),
+		HoldingTime: t.HoldingTime,
+		Resources:   ogame.Resources{Metal: t.Metal, Crystal: t.Crystal, Deuterium: t.Deuterium},
+	}
+	for _, s := range t.Ships {
+		res.Ships = append(res.Ships, ogame.Quantifiable{ID: ogame.ID(s.ID), Nbr: s.Nbr})
+	}
+	var err error
+	if res.Destination, err = ogame.ParseCoord(t.Destination); err != nil {
+		return res, errors.New("invalid destination " + t.Destination)
+	}
+	return res, nil
+}
+
+// resolveCronCelestial finds the celestial of a celestial id, alias or coordinate
+func resolveCronCelestial(bot *ogame.OGame, from string) (ogame.CelestialID, error) {
+	if id, err := strconv.ParseInt(from, 10, 64); err == nil {
+		return ogame.CelestialID(id), nil
+	}
+	celestial := bot.GetCachedCelestial(from)
+	if celestial == nil {
+		return 0, errors.New("celestial " + from + " not found")
+	}
+	return celestial.GetID(), nil
+}
+
+// compileCronJob validates a cron entry against the config and returns its job
+func compileCronJob(entry cronEntryConfig, cfg config) (cronJob, error) {
+	schedule, err := ogame.ParseCronSchedule(entry.Cron)
+	if err != nil {
+		return cronJob{}, err
+	}
+	job := cronJob{entry: entry, schedule: schedule}
+	switch entry.Action {
+	case cronSendFleetTemplate:
+		if _, ok := cfg.FleetTemplates[entry.Name]; !ok {
+			return cronJob{}, errors.New("fleet template " + entry.Name + " not found")
+		}
+		if entry.From == "" {
+			return cronJob{}, errors.New("missing from")
+		}
+		if _, err := strconv.ParseInt(entry.From, 10, 64); err != nil {
+			if _, isAlias := cfg.Aliases[entry.From]; !isAlias {
+				if _, err := ogame.ParseCoord(entry.From); err != nil {
+					return cronJob{}, errors.New("invalid from " + entry.From)
+				}
+			}
+		}
+		job.run = func(bot *ogame.OGame) error {
+			celestialID, err := resolveCronCelestial(bot, entry.From)
+			if err != nil {
+				return err
+			}
+			_, err = bot.SendFleetTemplate(entry.Name, celestialID)
+			return err
+		}
+	default:
+		return cronJob{}, errors.New("unknown action " + entry.Action)
+	}
+	return job, nil
+}
+
+// compileCronJobs compiles the cron entries of the config, the invalid entries are skipped and reported by index
+func compileCronJobs(cfg config) ([]cronJob, []error) {
+	jobs := make([]cronJob, 0, len(cfg.Cron))
+	var errs []error
+	for i, entry := range cfg.Cron {
+		job, err := compileCronJob(entry, cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cron entry %d (%q %s): %s", i, entry.Cron, entry.Action, err))
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, errs
+}
+
+// startCronJobs runs every job on its schedule until the returned function is called. The jobs are skipped while the
+// bot is logged out or disabled.
+func startCronJobs(bot *ogame.OGame, jobs []cronJob) (stop func()) {
+	done := make(chan struct{})
+	for _, job := range jobs {
+		go func(job cronJob) {
+			for {
+				next := job.schedule.Next(time.Now())
+				if next.IsZero() {
+					return
+				}
+				select {
+				case <-time.After(time.Until(next)):
+				case <-done:
+					return
+				}
+				if !bot.IsEnabled() || !bot.IsLoggedIn() {
+					continue
+				}
+				if err := job.run(bot); err != nil {
+					log.Printf("cron %q %s %s failed: %s", job.entry.Cron, job.entry.Action, job.entry.Name, err)
+				}
+			}
+		}(job)
+	}
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+)
+
+const (
+	readyReachableTTL     = 30 * time.Second
+	readyReachableTimeout = 5 * time.Second
+)
+
+// healthChecker answers the liveness (/healthz) and readiness (/readyz) probes
+type healthChecker struct {
+	sync.Mutex
+	bot           *ogame.OGame
+	startedAt     time.Time
+	reachable     bool
+	reachableErr  string
+	lastReachable time.Time
+	loginState    string // State of the background login, empty when it is not used or succeeded
+}
+
+func newHealthChecker(bot *ogame.OGame) *healthChecker {
+	return &healthChecker{bot: bot, startedAt: time.Now()}
+}
+
+type readyCheck struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// checkReachable requests the game server, the result is cached for readyReachableTTL
+func (h *healthChecker) checkReachable() readyCheck {
+	h.Lock()
+	defer h.Unlock()
+	if time.Since(h.lastReachable) < readyReachableTTL {
+		return readyCheck{OK: h.reachable, Error: h.reachableErr}
+	}
+	h.reachable, h.reachableErr = false, ""
+	serverURL := h.bot.ServerURL()
+	if serverURL == "" {
+		h.reachableErr = "server url unknown"
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), readyReachableTimeout)
+		defer cancel()
+		req, err := http.NewRequest(http.MethodHead, serverURL+"/game/index.php", nil)
+		if err == nil {
+			var resp *http.Response
+			if resp, err = h.bot.GetClient().Do(req.WithContext(ctx)); err == nil {
+				_ = resp.Body.Close()
+				h.reachable = resp.StatusCode < http.StatusInternalServerError
+				if !h.reachable {
+					h.reachableErr = resp.Status
+				}
+			}
+		}
+		if err != nil {
+			h.reachableErr = err.Error()
+		}
+	}
+	h.lastReachable = time.Now()
+	return readyCheck{OK: h.reachable, Error: h.reachableErr}
+}
+
+func (h *healthChecker) setLoginState(state string) {
+	h.Lock()
+	defer h.Unlock()
+	h.loginState = state
+}
+
+func (h *healthChecker) getLoginState() string {
+	h.Lock()
+	defer h.Unlock()
+	return h.loginState
+}
+
+// backgroundLogin logs in once the http server is started, so the captcha page is reachable. A failed login stops
+// ogamed like a failed login at startup, unless retry is given. The login state is reported by /readyz.
+func backgroundLogin(bot *ogame.OGame, h *healthChecker, retry time.Duration) {
+	for {
+		h.setLoginState("login in progress")
+		_, err := bot.LoginWithExistingCookies()
+		if err == nil {
+			h.setLoginState("")
+			return
+		}
+		if retry <= 0 {
+			log.Fatal("failed to login: " + err.Error())
+		}
+		log.Println("failed to login, retrying in " + retry.String() + ": " + err.Error())
+		h.setLoginState("login failed: " + err.Error())
+		time.Sleep(retry)
+	}
+}
+
+// HealthzHandler liveness probe, answers as long as the process is running
+// curl 127.0.0.1:1234/healthz
+func (h *healthChecker) HealthzHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":        "ok",
+		"uptimeSeconds": int64(time.Since(h.startedAt).Seconds()),
+	})
+}
+
+// ReadyzHandler readiness probe, 503 if the bot is not logged in, its session is lost or OGame is unreachable
+// curl 127.0.0.1:1234/readyz
+func (h *healthChecker) ReadyzHandler(c echo.Context) error {
+	checks := map[string]readyCheck{
+		"loggedIn":     {OK: h.bot.IsLoggedIn()},
+		"sessionValid": {OK: h.bot.IsConnected() && !h.bot.IsInSafeMode()},
+		"reachable":    h.checkReachable(),
+	}
+	if !checks["loggedIn"].OK {
+		checks["loggedIn"] = readyCheck{Error: "not logged in"}
+		if state := h.getLoginState(); state != "" {
+			checks["loggedIn"] = readyCheck{Error: state}
+		}
+	}
+	if h.bot.IsInSafeMode() {
+		checks["sessionValid"] = readyCheck{Error: "safe mode"}
+	} else if !checks["sessionValid"].OK {
+		checks["sessionValid"] = readyCheck{Error: "not connected"}
+	}
+	status, code := "ok", http.StatusOK
+	for _, check := range checks {
+		if !check.OK {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+	return c.JSON(code, map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
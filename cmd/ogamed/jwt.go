@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+const jwtIssuer = "ogamed"
+
+// jwtAuth issues and validates the JWTs used as an alternative to basic auth (browser dashboards)
+type jwtAuth struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func newJWTAuth(secret string, ttl time.Duration) *jwtAuth {
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &jwtAuth{secret: []byte(secret), ttl: ttl}
+}
+
+type jwtTokenResp struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (a *jwtAuth) issue(subject string) (jwtTokenResp, error) {
+	now := time.Now()
+	expiresAt := now.Add(a.ttl)
+	claims := jwt.StandardClaims{
+		Subject:   subject,
+		Issuer:    jwtIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+	if err != nil {
+		return jwtTokenResp{}, err
+	}
+	return jwtTokenResp{Token: token, ExpiresAt: time.Unix(expiresAt.Unix(), 0)}, nil
+}
+
+func (a *jwtAuth) parse(token string) (*jwt.StandardClaims, error) {
+	claims := &jwt.StandardClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return a.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != jwtIssuer {
+		return nil, errors.New("invalid issuer")
+	}
+	return claims, nil
+}
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" header
+func bearerToken(c echo.Context) (string, bool) {
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(auth, "Bearer "), true
+}
+
+// Middleware authenticates requests carrying a bearer token, the basic auth check is skipped for them.
+// Invalid or expired tokens are rejected.
+func (a *jwtAuth) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, ok := bearerToken(c)
+		if !ok {
+			return next(c)
+		}
+		claims, err := a.parse(token)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, handlers.ErrorResp(401, "invalid or expired token"))
+		}
+		c.Set("jwtClaims", claims)
+		return next(c)
+	}
+}
+
+// isJWTAuthenticated returns either or not the request was authenticated by the jwt middleware
+func isJWTAuthenticated(c echo.Context) bool {
+	_, ok := c.Get("jwtClaims").(*jwt.StandardClaims)
+	return ok
+}
+
+// TokenHandler issues a token for the user authenticated by basic auth. The requests authenticated by an api key or a
+// token are refused, their Authorization header was not checked.
+// curl -u user:pass -X POST 127.0.0.1:1234/auth/token
+func (a *jwtAuth) TokenHandler(c echo.Context) error {
+	username, ok := basicAuthUser(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, handlers.ErrorResp(401, "basic auth credentials required"))
+	}
+	resp, err := a.issue(username)
+	if err != nil {
+		resp := handlers.ErrorRespOf(err, http.StatusInternalServerError)
+		return c.JSON(resp.Code, resp)
+	}
+	return c.JSON(http.StatusOK, handlers.SuccessResp(resp))
+}
+
+// RefreshHandler issues a new token in exchange of a valid one
+// curl -H "Authorization: Bearer <token>" -X POST 127.0.0.1:1234/auth/refresh
+func (a *jwtAuth) RefreshHandler(c echo.Context) error {
+	claims, ok := c.Get("jwtClaims").(*jwt.StandardClaims)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, handlers.ErrorResp(401, "bearer token required"))
+	}
+	resp, err := a.issue(claims.Subject)
+	if err != nil {
+		resp := handlers.ErrorRespOf(err, http.StatusInternalServerError)
+		return c.JSON(resp.Code, resp)
+	}
+	return c.JSON(http.StatusOK, handlers.SuccessResp(resp))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTAuth_TokenHandler(t *testing.T) {
+	r := newRuntimeConfig("", config{BasicAuthUsername: "user", BasicAuthPassword: "pass"})
+	r.apiKeys = buildAPIKeys([]apiKeyConfig{{Name: "admin", Key: "admin-key", Scope: scopeAdmin}}, nil)
+	a := newJWTAuth("secret", 0)
+	e := echo.New()
+	scopes := make(routeScopes)
+	e.Use(a.Middleware)
+	e.Use(r.APIKeyMiddleware(scopes))
+	e.Use(basicAuthMiddleware(r, false))
+	scopes.group(e, scopeAdmin).POST("/auth/token", a.TokenHandler)
+
+	post := func(setup func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/token", nil)
+		setup(req)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Issued for the basic auth user
+	rec := post(func(req *http.Request) { req.SetBasicAuth("user", "pass") })
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct{ Result jwtTokenResp }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	claims, err := a.parse(resp.Result.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", claims.Subject)
+
+	rec = post(func(req *http.Request) { req.SetBasicAuth("user", "wrong") })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// The Basic header of a request authenticated by an api key is not checked, no token is issued for it
+	rec = post(func(req *http.Request) {
+		req.Header.Set("X-API-Key", "admin-key")
+		req.SetBasicAuth("someone-else", "")
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Nor for a request authenticated by a token
+	rec = post(func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+resp.Result.Token) })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringService service name of the secret key in the OS keyring
+const keyringService = "ogamed"
+
+// errKeyringNotFound the OS keyring has no secret for the account
+var errKeyringNotFound = errors.New("secret not found in the os keyring")
+
+// keyringSecretKey returns the secret key of account stored in the OS keyring (macOS keychain or the Secret Service
+// on linux), a random one is generated and stored the first time.
+// Any other keyring error is returned, a new key would make the existing encrypted cookies unreadable.
+func keyringSecretKey(account string) (string, error) {
+	key, err := keyringGet(account)
+	if err == nil {
+		return key, nil
+	}
+	if err != errKeyringNotFound {
+		return "", err
+	}
+	by := make([]byte, 32)
+	if _, err := rand.Read(by); err != nil {
+		return "", err
+	}
+	key = hex.EncodeToString(by)
+	if err := keyringSet(account, key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func keyringGet(account string) (string, error) {
+	var cmd *exec.Cmd
+	notFoundExitCode := 0
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+		notFoundExitCode = 44 // errSecItemNotFound
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", account)
+		notFoundExitCode = 1 // Without any message, the other errors are printed
+	default:
+		return "", errors.New("os keyring not supported on " + runtime.GOOS)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == notFoundExitCode &&
+			(runtime.GOOS == "darwin" || msg == "") {
+			return "", errKeyringNotFound
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", errors.New("failed to read the secret from the os keyring: " + msg)
+	}
+	key := strings.TrimSpace(string(out))
+	if key == "" {
+		return "", errKeyringNotFound
+	}
+	return key, nil
+}
+
+func keyringSet(account, key string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// The interactive mode reads the command from stdin, the key does not show up in the process list
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader("add-generic-password -U -s " + securityQuote(keyringService) +
+			" -a " + securityQuote(account) + " -w " + securityQuote(key) + "\n")
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label=ogamed cookies", "service", keyringService, "account", account)
+		cmd.Stdin = bytes.NewBufferString(key)
+	default:
+		return errors.New("os keyring not supported on " + runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.New("failed to store the secret key in the os keyring: " + strings.TrimSpace(string(out)))
+	}
+	// The security interactive mode does not fail when its command does
+	if stored, err := keyringGet(account); err != nil || stored != key {
+		return errors.New("failed to store the secret key in the os keyring")
+	}
+	return nil
+}
+
+// securityQuote quotes an argument of a command of the macOS security interactive mode
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
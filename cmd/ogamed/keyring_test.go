@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSecretTool puts a secret-tool script in the PATH, it stores the secret in the store file of dir
+func fakeSecretTool(t *testing.T, dir, lookup string) {
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = store ]; then cat > " + filepath.Join(dir, "store") + "; exit 0; fi\n" + lookup
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0700))
+}
+
+func TestKeyringSecretKey(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("secret-tool is only used on linux")
+	}
+	dir, _ := ioutil.TempDir("", "keyring")
+	defer os.RemoveAll(dir)
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	_ = os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	store := filepath.Join(dir, "store")
+
+	// No secret yet, a key is generated and stored
+	fakeSecretTool(t, dir, "if [ -f "+store+" ]; then cat "+store+"; exit 0; fi\nexit 1\n")
+	key, err := keyringSecretKey("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, 64, len(key))
+	stored, _ := ioutil.ReadFile(store)
+	assert.Equal(t, key, string(stored))
+
+	// The stored key is returned
+	again, err := keyringSecretKey("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	// A keyring failure does not replace the stored key
+	fakeSecretTool(t, dir, "echo 'Cannot autolaunch D-Bus' >&2\nexit 1\n")
+	_, err = keyringSecretKey("bob")
+	assert.EqualError(t, err, "failed to read the secret from the os keyring: Cannot autolaunch D-Bus")
+	stored, _ = ioutil.ReadFile(store)
+	assert.Equal(t, key, string(stored))
+}
+
+func TestSecurityQuote(t *testing.T) {
+	assert.Equal(t, `"bob"`, securityQuote("bob"))
+	assert.Equal(t, `"a \"b\" \\c"`, securityQuote(`a "b" \c`))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// listenAddr returns the network and address ogamed listens on. listen is either unix:///path/to.sock,
+// tcp://host:port or host:port, the --host and --port flags are used when it is empty.
+func listenAddr(listen, host string, port int) (network, address string, err error) {
+	switch {
+	case listen == "":
+		return "tcp", host + ":" + strconv.Itoa(port), nil
+	case strings.HasPrefix(listen, "unix://"):
+		address = strings.TrimPrefix(listen, "unix://")
+		if address == "" {
+			return "", "", errors.New("invalid unix socket path")
+		}
+		return "unix", address, nil
+	case strings.HasPrefix(listen, "tcp://"):
+		return "tcp", strings.TrimPrefix(listen, "tcp://"), nil
+	case strings.Contains(listen, "://"):
+		return "", "", errors.New("invalid listen address " + listen)
+	}
+	return "tcp", listen, nil
+}
+
+// listenUnix listens on a unix socket, a socket left by a previous run is removed
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// The reverse proxy usually runs as another user of the same group
+	if err := os.Chmod(path, 0660); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// normalizeBasePath returns the base path with a leading slash and without trailing slash, empty for the root
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// basePathMiddleware strips the base path from the requests before the routing, so the routes and the AntiGame
+// static handlers see the same paths as when ogamed is served from the root. Requests outside of it are not found.
+func basePathMiddleware(basePath string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			path := req.URL.Path
+			if path != basePath && !strings.HasPrefix(path, basePath+"/") {
+				return echo.NewHTTPError(http.StatusNotFound)
+			}
+			req.URL.Path = strings.TrimPrefix(path, basePath)
+			if req.URL.Path == "" {
+				req.URL.Path = "/"
+			}
+			if req.URL.RawPath != "" {
+				req.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, basePath)
+			}
+			req.RequestURI = strings.TrimPrefix(req.RequestURI, basePath)
+			return next(c)
+		}
+	}
+}
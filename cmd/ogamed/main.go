@@ -1,15 +1,18 @@
 package main
 
 import (
-	"crypto/subtle"
+	"errors"
 	"log"
+	"net/http"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/alaingilbert/ogame"
 	"github.com/alaingilbert/ogame/handlers"
 	"github.com/labstack/echo"
-	"github.com/labstack/echo/middleware"
+	"golang.org/x/crypto/acme/autocert"
 	"gopkg.in/urfave/cli.v2"
 )
 
@@ -40,10 +43,15 @@ func main() {
 		},
 		&cli.StringFlag{
 			Name:    "password",
-			Usage:   "Password to login on ogame",
+			Usage:   "Password to login on ogame, or a reference to it: keyring:<account>, vault:<path>#<field> or file:<path>",
 			Aliases: []string{"p"},
 			EnvVars: []string{"OGAMED_PASSWORD"},
 		},
+		&cli.StringFlag{
+			Name:    "otp-secret",
+			Usage:   "TOTP secret of the two-factor authentication, or a reference to it: keyring:<account>, vault:<path>#<field> or file:<path>",
+			EnvVars: []string{"OGAMED_OTP_SECRET"},
+		},
 		&cli.StringFlag{
 			Name:    "language",
 			Usage:   "Language to login on ogame",
@@ -69,6 +77,12 @@ func main() {
 			Value:   true,
 			EnvVars: []string{"OGAMED_AUTO_LOGIN"},
 		},
+		&cli.DurationFlag{
+			Name:    "auto-login-retry",
+			Usage:   "Retry the background login (manual captcha page) after this delay when it fails, ogamed stops on a failed login if 0",
+			Value:   0,
+			EnvVars: []string{"OGAMED_AUTO_LOGIN_RETRY"},
+		},
 		&cli.StringFlag{
 			Name:    "proxy",
 			Usage:   "Proxy address",
@@ -99,6 +113,24 @@ func main() {
 			Value:   false,
 			EnvVars: []string{"OGAMED_PROXY_LOGIN_ONLY"},
 		},
+		&cli.StringFlag{
+			Name:    "tls-fingerprint",
+			Usage:   "TLS ClientHello fingerprint to mimic (chrome, firefox, ios, randomized, golang)",
+			Value:   "",
+			EnvVars: []string{"OGAMED_TLS_FINGERPRINT"},
+		},
+		&cli.StringFlag{
+			Name:    "proxies",
+			Usage:   "Path to a file listing proxies ([type://][username:password@]host:port, one per line), used instead of --proxy",
+			Value:   "",
+			EnvVars: []string{"OGAMED_PROXIES"},
+		},
+		&cli.BoolFlag{
+			Name:    "proxies-rotate",
+			Usage:   "Use the next healthy proxy of the list for every request",
+			Value:   false,
+			EnvVars: []string{"OGAMED_PROXIES_ROTATE"},
+		},
 		&cli.StringFlag{
 			Name:    "lobby",
 			Usage:   "Lobby to use (lobby | lobby-pioneers)",
@@ -141,24 +173,334 @@ func main() {
 			Value:   "~/.ogame/cert.pem",
 			EnvVars: []string{"OGAMED_TLS_KEYFILE"},
 		},
+		&cli.StringFlag{
+			Name:    "listen",
+			Usage:   "Address to listen on instead of host:port, eg: unix:///var/run/ogamed.sock or tcp://127.0.0.1:8080",
+			Value:   "",
+			EnvVars: []string{"OGAMED_LISTEN"},
+		},
+		&cli.StringFlag{
+			Name:    "base-path",
+			Usage:   "URL prefix ogamed is served under behind a reverse proxy, eg: /ogame (include it in api-new-hostname)",
+			Value:   "",
+			EnvVars: []string{"OGAMED_BASE_PATH"},
+		},
+		&cli.StringFlag{
+			Name:    "acme-domain",
+			Usage:   "Domain(s), comma separated, to get a Let's Encrypt certificate for (replaces the tls key and cert files, ogamed must be reachable on port 443)",
+			Value:   "",
+			EnvVars: []string{"OGAMED_ACME_DOMAIN"},
+		},
+		&cli.StringFlag{
+			Name:    "acme-email",
+			Usage:   "Contact email given to Let's Encrypt",
+			Value:   "",
+			EnvVars: []string{"OGAMED_ACME_EMAIL"},
+		},
+		&cli.StringFlag{
+			Name:    "acme-cache-dir",
+			Usage:   "Directory where the Let's Encrypt certificates are stored, ~/.ogame/acme if empty",
+			Value:   "",
+			EnvVars: []string{"OGAMED_ACME_CACHE_DIR"},
+		},
 		&cli.StringFlag{
 			Name:    "cookies-filename",
 			Usage:   "Path cookies file",
 			Value:   "",
 			EnvVars: []string{"OGAMED_COOKIES_FILENAME"},
 		},
+		&cli.StringFlag{
+			Name:    "secret-key",
+			Usage:   "Key the cookies file (bearer token included) is encrypted with, plain-text if empty",
+			Value:   "",
+			EnvVars: []string{"OGAMED_SECRET_KEY"},
+		},
+		&cli.BoolFlag{
+			Name:    "secret-key-keyring",
+			Usage:   "Encrypt the cookies file with a key stored in the OS keyring (generated on first use)",
+			Value:   false,
+			EnvVars: []string{"OGAMED_SECRET_KEY_KEYRING"},
+		},
 		&cli.BoolFlag{
 			Name:    "cors-enabled",
-			Usage:   "Enable CORS",
+			Usage:   "Enable CORS for the origins of --cors-origins",
 			Value:   true,
 			EnvVars: []string{"CORS_ENABLED"},
 		},
+		&cli.StringFlag{
+			Name:    "cors-origins",
+			Usage:   "Comma separated origins allowed to send cross-origin requests (eg: http://localhost:3000), * for any origin. None if empty",
+			Value:   "",
+			EnvVars: []string{"CORS_ORIGINS"},
+		},
+		&cli.StringFlag{
+			Name:    "cors-methods",
+			Usage:   "Comma separated methods allowed in cross-origin requests",
+			Value:   "GET,HEAD,PUT,PATCH,POST,DELETE",
+			EnvVars: []string{"CORS_METHODS"},
+		},
+		&cli.BoolFlag{
+			Name:    "cors-allow-credentials",
+			Usage:   "Allow cross-origin requests to send credentials (basic auth, cookies), not allowed with --cors-origins=*",
+			Value:   false,
+			EnvVars: []string{"CORS_ALLOW_CREDENTIALS"},
+		},
 		&cli.StringFlag{
 			Name:    "nja-api-key",
 			Usage:   "Ninja API key",
 			Value:   "",
 			EnvVars: []string{"NJA_API_KEY"},
 		},
+		&cli.StringFlag{
+			Name:    "captcha-provider",
+			Usage:   "Captcha solver service (ninja, 2captcha, anti-captcha)",
+			Value:   "",
+			EnvVars: []string{"CAPTCHA_PROVIDER"},
+		},
+		&cli.StringFlag{
+			Name:    "captcha-api-key",
+			Usage:   "API key of the captcha solver service",
+			Value:   "",
+			EnvVars: []string{"CAPTCHA_API_KEY"},
+		},
+		&cli.IntFlag{
+			Name:    "captcha-max-tries",
+			Usage:   "Number of times a captcha is submitted to the solver service before giving up",
+			Value:   3,
+			EnvVars: []string{"CAPTCHA_MAX_TRIES"},
+		},
+		&cli.StringFlag{
+			Name:    "config",
+			Usage:   "Path to a JSON config file, reloaded on SIGHUP or POST /admin/reload",
+			Value:   "",
+			EnvVars: []string{"OGAMED_CONFIG"},
+		},
+		&cli.BoolFlag{
+			Name:    "session-broker",
+			Usage:   "Only handle login/captcha/session refresh and expose the session credentials and raw passthrough endpoints",
+			Value:   false,
+			EnvVars: []string{"OGAMED_SESSION_BROKER"},
+		},
+		&cli.StringFlag{
+			Name:    "jwt-secret",
+			Usage:   "Secret used to sign the JWTs issued by POST /auth/token, enables bearer authentication (requires basic auth credentials)",
+			Value:   "",
+			EnvVars: []string{"OGAMED_JWT_SECRET"},
+		},
+		&cli.DurationFlag{
+			Name:    "jwt-expiry",
+			Usage:   "Validity of the issued JWTs, refresh them with POST /auth/refresh",
+			Value:   time.Hour,
+			EnvVars: []string{"OGAMED_JWT_EXPIRY"},
+		},
+		&cli.StringFlag{
+			Name:    "audit-log-file",
+			Usage:   "File where the mutating actions (send fleet, build, cancel...) are appended, queryable with /bot/audit",
+			Value:   "",
+			EnvVars: []string{"OGAMED_AUDIT_LOG_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "marketplace-history-file",
+			Usage:   "File where the marketplace offers, listings and trades are appended, queryable with /bot/marketplace/prices",
+			Value:   "",
+			EnvVars: []string{"OGAMED_MARKETPLACE_HISTORY_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "task-history-file",
+			Usage:   "File where the tasks that held the bot lock are appended, queryable with /tasks/history",
+			Value:   "",
+			EnvVars: []string{"OGAMED_TASK_HISTORY_FILE"},
+		},
+		&cli.DurationFlag{
+			Name:    "task-history-max-age",
+			Usage:   "Time the tasks are kept in the task history",
+			Value:   ogame.DefaultTaskHistoryMaxAge,
+			EnvVars: []string{"OGAMED_TASK_HISTORY_MAX_AGE"},
+		},
+		&cli.IntFlag{
+			Name:    "task-history-max-records",
+			Usage:   "Tasks kept in the task history",
+			Value:   ogame.DefaultTaskHistoryMaxRecords,
+			EnvVars: []string{"OGAMED_TASK_HISTORY_MAX_RECORDS"},
+		},
+		&cli.IntFlag{
+			Name:    "safe-mode-threshold",
+			Usage:   "Consecutive failed tasks after which the bot pauses all requests until POST /bot/resume, 0 disables safe mode",
+			Value:   ogame.DefaultSafeModeThreshold,
+			EnvVars: []string{"OGAMED_SAFE_MODE_THRESHOLD"},
+		},
+		&cli.DurationFlag{
+			Name:    "request-timeout",
+			Usage:   "Time a request to the OGame server can take (at most 30s), a hung request then fails instead of holding the bot lock",
+			Value:   0,
+			EnvVars: []string{"OGAMED_REQUEST_TIMEOUT"},
+		},
+		&cli.DurationFlag{
+			Name:    "task-deadline",
+			Usage:   "Time a task can hold the bot lock and keep sending requests, disabled if 0",
+			Value:   0,
+			EnvVars: []string{"OGAMED_TASK_DEADLINE"},
+		},
+		&cli.Float64Flag{
+			Name:    "rate-limit",
+			Usage:   "Sustained requests per second sent to OGame, shared by all the tasks and the static proxy, disabled if 0",
+			Value:   0,
+			EnvVars: []string{"OGAMED_RATE_LIMIT"},
+		},
+		&cli.Int64Flag{
+			Name:    "rate-limit-burst",
+			Usage:   "Requests that can be sent at once above the rate limit",
+			Value:   5,
+			EnvVars: []string{"OGAMED_RATE_LIMIT_BURST"},
+		},
+		&cli.DurationFlag{
+			Name:    "galaxy-cache-ttl",
+			Usage:   "Time the galaxy systems are served from the cache (bypass with ?skipCache=1), disabled if 0",
+			Value:   0,
+			EnvVars: []string{"OGAMED_GALAXY_CACHE_TTL"},
+		},
+		&cli.StringFlag{
+			Name:    "galaxy-cache-file",
+			Usage:   "File where the galaxy cache is persisted across restarts",
+			Value:   "",
+			EnvVars: []string{"OGAMED_GALAXY_CACHE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "workflows-file",
+			Usage:   "File where the colonize workflows are saved, so they resume after a restart",
+			Value:   "",
+			EnvVars: []string{"OGAMED_WORKFLOWS_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "static-cache-dir",
+			Usage:   "Directory where /cdn, /assets and /api/*.xml responses are cached according to the game cache headers",
+			Value:   "",
+			EnvVars: []string{"OGAMED_STATIC_CACHE_DIR"},
+		},
+		&cli.DurationFlag{
+			Name:    "events-poll-min-interval",
+			Usage:   "Shortest interval of the events poller feeding /bot/events/stream (used while under attack)",
+			Value:   ogame.DefaultEventsPollerMinInterval,
+			EnvVars: []string{"OGAMED_EVENTS_POLL_MIN_INTERVAL"},
+		},
+		&cli.DurationFlag{
+			Name:    "events-poll-max-interval",
+			Usage:   "Longest interval of the events poller feeding /bot/events/stream (nothing happening)",
+			Value:   ogame.DefaultEventsPollerMaxInterval,
+			EnvVars: []string{"OGAMED_EVENTS_POLL_MAX_INTERVAL"},
+		},
+		&cli.DurationFlag{
+			Name:    "webhooks-poll-interval",
+			Usage:   "Interval at which the under attack status is checked while webhooks are configured, 0 disables the webhooks",
+			Value:   time.Minute,
+			EnvVars: []string{"OGAMED_WEBHOOKS_POLL_INTERVAL"},
+		},
+		&cli.Float64Flag{
+			Name:    "storage-alert-threshold",
+			Usage:   "Storage fill ratio (eg: 0.9) at which storage.threshold_reached events are emitted, 0 disables the storage alerts",
+			Value:   0,
+			EnvVars: []string{"OGAMED_STORAGE_ALERT_THRESHOLD"},
+		},
+		&cli.DurationFlag{
+			Name:    "storage-alert-interval",
+			Usage:   "Interval at which the storages are checked for the storage alerts",
+			Value:   ogame.DefaultStorageAlertInterval,
+			EnvVars: []string{"OGAMED_STORAGE_ALERT_INTERVAL"},
+		},
+		&cli.Float64Flag{
+			Name:    "military-drop-threshold",
+			Usage:   "Military points ratio (eg: 0.2) a watched player must lose for a player.military_drop event, 0 disables the military score alerts",
+			Value:   0,
+			EnvVars: []string{"OGAMED_MILITARY_DROP_THRESHOLD"},
+		},
+		&cli.StringFlag{
+			Name:    "watchlist-file",
+			Usage:   "File where the players watched by the military score alerts are saved, so they are kept after a restart",
+			Value:   "",
+			EnvVars: []string{"OGAMED_WATCHLIST_FILE"},
+		},
+		&cli.DurationFlag{
+			Name:    "military-watch-interval",
+			Usage:   "Interval at which the military score of the watched players is checked",
+			Value:   ogame.DefaultMilitaryWatchInterval,
+			EnvVars: []string{"OGAMED_MILITARY_WATCH_INTERVAL"},
+		},
+		&cli.DurationFlag{
+			Name:    "bearer-token-refresh-margin",
+			Usage:   "Get a new gameforge bearer token this long before the current one expires, 0 disables the refresh",
+			Value:   ogame.DefaultBearerTokenRefreshMargin,
+			EnvVars: []string{"OGAMED_BEARER_TOKEN_REFRESH_MARGIN"},
+		},
+		&cli.DurationFlag{
+			Name:    "constructions-watch-interval",
+			Usage:   "Interval at which the constructions are read to emit construction.finished events, 0 disables the watcher",
+			Value:   0,
+			EnvVars: []string{"OGAMED_CONSTRUCTIONS_WATCH_INTERVAL"},
+		},
+		&cli.DurationFlag{
+			Name:    "auto-abm-interval",
+			Usage:   "Interval at which the event list is read to build anti-ballistic missiles against incoming missiles, 0 disables the automatic ABM",
+			Value:   0,
+			EnvVars: []string{"OGAMED_AUTO_ABM_INTERVAL"},
+		},
+		&cli.Int64Flag{
+			Name:    "auto-recyclers-threshold",
+			Usage:   "Debris of the own combats from which recyclers are sent to harvest it, 0 disables the automatic recyclers",
+			Value:   0,
+			EnvVars: []string{"OGAMED_AUTO_RECYCLERS_THRESHOLD"},
+		},
+		&cli.Int64Flag{
+			Name:    "auto-recyclers-reserve",
+			Usage:   "Recyclers kept on every celestial by the automatic recyclers",
+			Value:   0,
+			EnvVars: []string{"OGAMED_AUTO_RECYCLERS_RESERVE"},
+		},
+		&cli.DurationFlag{
+			Name:    "auto-recyclers-interval",
+			Usage:   "Interval at which the combat reports are read by the automatic recyclers",
+			Value:   ogame.DefaultAutoRecyclersInterval,
+			EnvVars: []string{"OGAMED_AUTO_RECYCLERS_INTERVAL"},
+		},
+		&cli.StringFlag{
+			Name:    "scripts-dir",
+			Usage:   "Directory of the lua scripts run on their schedule or on events",
+			EnvVars: []string{"OGAMED_SCRIPTS_DIR"},
+		},
+		&cli.StringFlag{
+			Name:    "plugins-dir",
+			Usage:   "Directory of the plugin executables to start",
+			EnvVars: []string{"OGAMED_PLUGINS_DIR"},
+		},
+		&cli.StringFlag{
+			Name:    "login-mode",
+			Usage:   "Login mode (http, browser), browser falls back to a headless chrome login when the HTTP login fails (requires building with -tags chromedp)",
+			Value:   loginModeHTTP,
+			EnvVars: []string{"OGAMED_LOGIN_MODE"},
+		},
+		&cli.BoolFlag{
+			Name:    "login-browser-headless",
+			Usage:   "Run the browser login without a window, disable it to solve the captcha by hand",
+			Value:   true,
+			EnvVars: []string{"OGAMED_LOGIN_BROWSER_HEADLESS"},
+		},
+		&cli.DurationFlag{
+			Name:    "login-browser-timeout",
+			Usage:   "Maximum duration of the browser login",
+			Value:   5 * time.Minute,
+			EnvVars: []string{"OGAMED_LOGIN_BROWSER_TIMEOUT"},
+		},
+		&cli.BoolFlag{
+			Name:    "status-page-enabled",
+			Usage:   "Enable the public read-only status page at /status (no authentication)",
+			Value:   false,
+			EnvVars: []string{"OGAMED_STATUS_PAGE_ENABLED"},
+		},
+		&cli.StringFlag{
+			Name:    "trusted-proxies",
+			Usage:   "Reverse proxies (IPs or CIDRs, comma separated) whose X-Forwarded-For header is used to rate limit the status page per client",
+			Value:   "",
+			EnvVars: []string{"OGAMED_TRUSTED_PROXIES"},
+		},
 	}
 	app.Action = start
 	if err := app.Run(os.Args); err != nil {
@@ -170,8 +512,10 @@ func start(c *cli.Context) error {
 	universe := c.String("universe")
 	username := c.String("username")
 	password := c.String("password")
+	otpSecret := c.String("otp-secret")
 	language := c.String("language")
 	autoLogin := c.Bool("auto-login")
+	autoLoginRetry := c.Duration("auto-login-retry")
 	host := c.String("host")
 	port := c.Int("port")
 	proxyAddr := c.String("proxy")
@@ -179,166 +523,485 @@ func start(c *cli.Context) error {
 	proxyPassword := c.String("proxy-password")
 	proxyType := c.String("proxy-type")
 	proxyLoginOnly := c.Bool("proxy-login-only")
+	proxiesFilename := c.String("proxies")
+	proxiesRotate := c.Bool("proxies-rotate")
+	tlsFingerprint := c.String("tls-fingerprint")
 	lobby := c.String("lobby")
 	apiNewHostname := c.String("api-new-hostname")
 	enableTLS := c.Bool("enable-tls")
 	tlsKeyFile := c.String("tls-key-file")
 	tlsCertFile := c.String("tls-cert-file")
+	listen := c.String("listen")
+	basePath := normalizeBasePath(c.String("base-path"))
+	acmeDomain := c.String("acme-domain")
+	acmeEmail := c.String("acme-email")
+	acmeCacheDir := c.String("acme-cache-dir")
 	basicAuthUsername := c.String("basic-auth-username")
 	basicAuthPassword := c.String("basic-auth-password")
 	cookiesFilename := c.String("cookies-filename")
+	secretKey := c.String("secret-key")
+	secretKeyKeyring := c.Bool("secret-key-keyring")
 	corsEnabled := c.Bool("cors-enabled")
+	corsOrigins := splitCORSList(c.String("cors-origins"))
+	corsMethods := splitCORSList(c.String("cors-methods"))
+	corsAllowCredentials := c.Bool("cors-allow-credentials")
 	njaApiKey := c.String("nja-api-key")
+	captchaProvider := c.String("captcha-provider")
+	captchaAPIKey := c.String("captcha-api-key")
+	captchaMaxTries := c.Int("captcha-max-tries")
+	configFilename := c.String("config")
+	statusPageEnabled := c.Bool("status-page-enabled")
+	trustedProxies, err := parseTrustedProxies(c.String("trusted-proxies"))
+	if err != nil {
+		return err
+	}
+	sessionBroker := c.Bool("session-broker")
+	staticCacheDir := c.String("static-cache-dir")
+	auditLogFilename := c.String("audit-log-file")
+	marketplaceHistoryFilename := c.String("marketplace-history-file")
+	taskHistoryFilename := c.String("task-history-file")
+	taskHistoryMaxAge := c.Duration("task-history-max-age")
+	taskHistoryMaxRecords := c.Int("task-history-max-records")
+	safeModeThreshold := c.Int("safe-mode-threshold")
+	requestTimeout := c.Duration("request-timeout")
+	taskDeadline := c.Duration("task-deadline")
+	rateLimit := c.Float64("rate-limit")
+	rateLimitBurst := c.Int64("rate-limit-burst")
+	galaxyCacheTTL := c.Duration("galaxy-cache-ttl")
+	galaxyCacheFilename := c.String("galaxy-cache-file")
+	workflowsFilename := c.String("workflows-file")
+	watchlistFilename := c.String("watchlist-file")
+	jwtSecret := c.String("jwt-secret")
+	jwtExpiry := c.Duration("jwt-expiry")
+	eventsPollMinInterval := c.Duration("events-poll-min-interval")
+	eventsPollMaxInterval := c.Duration("events-poll-max-interval")
+	webhooksPollInterval := c.Duration("webhooks-poll-interval")
+	storageAlertThreshold := c.Float64("storage-alert-threshold")
+	storageAlertInterval := c.Duration("storage-alert-interval")
+	militaryDropThreshold := c.Float64("military-drop-threshold")
+	militaryWatchInterval := c.Duration("military-watch-interval")
+	bearerTokenRefreshMargin := c.Duration("bearer-token-refresh-margin")
+	constructionsWatchInterval := c.Duration("constructions-watch-interval")
+	autoABMInterval := c.Duration("auto-abm-interval")
+	autoRecyclersThreshold := c.Int64("auto-recyclers-threshold")
+	autoRecyclersReserve := c.Int64("auto-recyclers-reserve")
+	autoRecyclersInterval := c.Duration("auto-recyclers-interval")
+	scriptsDir := c.String("scripts-dir")
+	pluginsDir := c.String("plugins-dir")
+	loginMode := c.String("login-mode")
+	loginBrowserHeadless := c.Bool("login-browser-headless")
+	loginBrowserTimeout := c.Duration("login-browser-timeout")
+
+	if err := resolveCredentials(&username, &password, &otpSecret, &proxyPassword, &basicAuthPassword, &njaApiKey, &captchaAPIKey,
+		&jwtSecret, &secretKey); err != nil {
+		return err
+	}
 
 	params := ogame.Params{
-		Universe:        universe,
-		Username:        username,
-		Password:        password,
-		Lang:            language,
-		AutoLogin:       autoLogin,
-		Proxy:           proxyAddr,
-		ProxyUsername:   proxyUsername,
-		ProxyPassword:   proxyPassword,
-		ProxyType:       proxyType,
-		ProxyLoginOnly:  proxyLoginOnly,
-		Lobby:           lobby,
-		APINewHostname:  apiNewHostname,
-		CookiesFilename: cookiesFilename,
-	}
-	if njaApiKey != "" {
-		params.CaptchaCallback = ogame.NinjaSolver(njaApiKey)
+		Universe:                   universe,
+		Username:                   username,
+		Password:                   password,
+		OTPSecret:                  otpSecret,
+		Lang:                       language,
+		AutoLogin:                  autoLogin,
+		Proxy:                      proxyAddr,
+		ProxyUsername:              proxyUsername,
+		ProxyPassword:              proxyPassword,
+		ProxyType:                  proxyType,
+		ProxyLoginOnly:             proxyLoginOnly,
+		Lobby:                      lobby,
+		APINewHostname:             apiNewHostname,
+		CookiesFilename:            cookiesFilename,
+		TLSFingerprint:             tlsFingerprint,
+		AuditLogFilename:           auditLogFilename,
+		MarketplaceHistoryFilename: marketplaceHistoryFilename,
+		TaskHistoryFilename:        taskHistoryFilename,
+		TaskHistoryMaxAge:          taskHistoryMaxAge,
+		TaskHistoryMaxRecords:      taskHistoryMaxRecords,
+		SafeModeThreshold:          int32(safeModeThreshold),
+		GalaxyCacheTTL:             galaxyCacheTTL,
+		GalaxyCacheFilename:        galaxyCacheFilename,
+		WorkflowsFilename:          workflowsFilename,
+		WatchlistFilename:          watchlistFilename,
+		RequestTimeout:             requestTimeout,
+		TaskDeadline:               taskDeadline,
+		RateLimit:                  rateLimit,
+		RateLimitBurst:             rateLimitBurst,
+	}
+	if secretKey == "" && secretKeyKeyring {
+		key, err := keyringSecretKey(username)
+		if err != nil {
+			return err
+		}
+		secretKey = key
+	}
+	params.CookiesSecretKey = secretKey
+	switch loginMode {
+	case loginModeHTTP:
+	case loginModeBrowser:
+		if newBrowserLogin == nil {
+			return errors.New("ogamed was built without browser login support, rebuild it with -tags chromedp")
+		}
+		params.BrowserLogin = newBrowserLogin(loginBrowserHeadless, loginBrowserTimeout)
+	default:
+		return errors.New("invalid login mode " + loginMode)
+	}
+	// Without a solver service, captchas are answered by a human through /bot/captcha
+	manualSolver := ogame.NewManualSolver(10 * time.Minute)
+	if captchaProvider == "" && njaApiKey != "" {
+		captchaProvider, captchaAPIKey = ogame.NinjaCaptchaProvider, njaApiKey
+	}
+	if captchaProvider != "" {
+		solver, err := ogame.NewCaptchaSolver(captchaProvider, captchaAPIKey)
+		if err != nil {
+			return err
+		}
+		if balance, err := solver.Balance(); err == nil {
+			if balance <= 0 {
+				return errors.New("captcha solver account has no balance left")
+			}
+			log.Printf("captcha solver balance: %.2f\n", balance)
+		} else if err != ogame.ErrBalanceUnsupported {
+			log.Println("failed to check captcha solver balance: " + err.Error())
+		}
+		params.CaptchaCallback = ogame.SolverCallback(solver, captchaMaxTries)
+	} else {
+		params.CaptchaCallback = manualSolver.Callback()
+		// Login in background once the http server is started, so the captcha page is reachable
+		params.AutoLogin = false
+	}
+
+	if proxiesFilename != "" {
+		f, err := os.Open(proxiesFilename)
+		if err != nil {
+			return err
+		}
+		proxies, err := ogame.ParseProxyList(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		if params.ProxyPool, err = ogame.NewProxyPool(proxies, proxiesRotate, nil); err != nil {
+			return err
+		}
+		params.ProxyPool.StartHealthChecks(5*time.Minute, "https://lobby.ogame.gameforge.com")
 	}
 
 	bot, err := ogame.NewWithParams(params)
 	if err != nil {
 		return err
 	}
+	health := newHealthChecker(bot)
+	if autoLogin && captchaProvider == "" {
+		go backgroundLogin(bot, health, autoLoginRetry)
+	}
+
+	proxyLoginOnlyDefault := proxyLoginOnly
+	runtimeCfg := newRuntimeConfig(configFilename, config{
+		Proxy:             proxyAddr,
+		ProxyUsername:     proxyUsername,
+		ProxyPassword:     proxyPassword,
+		ProxyType:         proxyType,
+		ProxyLoginOnly:    &proxyLoginOnlyDefault,
+		BasicAuthUsername: basicAuthUsername,
+		BasicAuthPassword: basicAuthPassword,
+	})
+	if err := runtimeCfg.Reload(bot); err != nil {
+		return err
+	}
+	runtimeCfg.WatchSIGHUP(bot)
+	bot.ResumeColonizeWorkflows()
+	bot.OnUniverseMigrated(handlers.RemapEmpireSnapshots)
+	webhooks := newWebhookNotifier(bot, runtimeCfg)
+	webhooks.Watch(webhooksPollInterval)
+	if storageAlertThreshold > 0 {
+		bot.StartStorageAlerts(storageAlertThreshold, storageAlertInterval)
+	}
+	if militaryDropThreshold > 0 {
+		bot.StartMilitaryScoreAlerts(militaryDropThreshold, militaryWatchInterval)
+	}
+	if bearerTokenRefreshMargin > 0 {
+		bot.StartBearerTokenRefresher(bearerTokenRefreshMargin)
+	}
+	if constructionsWatchInterval > 0 {
+		bot.StartConstructionsWatcher(constructionsWatchInterval)
+	}
+	if autoABMInterval > 0 {
+		bot.StartAutoABM(autoABMInterval)
+	}
+	if autoRecyclersThreshold > 0 {
+		bot.StartAutoRecyclers(ogame.AutoRecyclersParams{
+			Threshold: autoRecyclersThreshold,
+			Reserve:   autoRecyclersReserve,
+			Interval:  autoRecyclersInterval,
+		})
+	}
+	if scriptsDir != "" {
+		if _, err := bot.StartScripts(scriptsDir); err != nil {
+			return err
+		}
+	}
+	var plugins *pluginManager
+	if pluginsDir != "" {
+		if plugins, err = startPlugins(bot, pluginsDir); err != nil {
+			return err
+		}
+		defer plugins.Stop()
+	}
+
+	var staticCache *handlers.StaticCache
+	if staticCacheDir != "" {
+		if staticCache, err = handlers.NewStaticCache(staticCacheDir); err != nil {
+			return err
+		}
+	}
 
 	e := echo.New()
+	if basePath != "" {
+		e.Pre(basePathMiddleware(basePath))
+	}
+	var cors corsConfig
 	if corsEnabled {
-		e.Use(middleware.CORS())
+		if cors, err = newCORSConfig(corsOrigins, corsMethods, corsAllowCredentials); err != nil {
+			return err
+		}
+		if mw := cors.middleware(); mw != nil {
+			e.Use(mw)
+		} else {
+			log.Println("CORS: no origin allowed, cross-origin requests are refused (see --cors-origins)")
+		}
 	}
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
 			ctx.Set("bot", bot)
+			ctx.Set("captchaSolver", manualSolver)
 			ctx.Set("version", version)
 			ctx.Set("commit", commit)
 			ctx.Set("date", date)
+			if staticCache != nil {
+				ctx.Set("staticCache", staticCache)
+			}
 			return next(ctx)
 		}
 	})
-	if len(basicAuthUsername) > 0 && len(basicAuthPassword) > 0 {
+	if runtimeCfg.HasBasicAuth() {
 		log.Println("Enable Basic Auth")
-		e.Use(middleware.BasicAuth(func(username, password string, c echo.Context) (bool, error) {
-			// Be careful to use constant time comparison to prevent timing attacks
-			if subtle.ConstantTimeCompare([]byte(username), []byte(basicAuthUsername)) == 1 &&
-				subtle.ConstantTimeCompare([]byte(password), []byte(basicAuthPassword)) == 1 {
-				return true, nil
-			}
-			return false, nil
-		}))
+	}
+	var jwtAuthenticator *jwtAuth
+	if jwtSecret != "" {
+		if !runtimeCfg.HasBasicAuth() {
+			return errors.New("jwt authentication requires basic auth credentials to issue tokens")
+		}
+		log.Println("Enable JWT Auth")
+		jwtAuthenticator = newJWTAuth(jwtSecret, jwtExpiry)
+		e.Use(jwtAuthenticator.Middleware)
+	}
+	scopes := make(routeScopes)
+	e.Use(runtimeCfg.APIKeyMiddleware(scopes))
+	e.Use(basicAuthMiddleware(runtimeCfg, statusPageEnabled))
+	e.Use(handlers.PriorityMiddleware)
+	e.Use(handlers.TxMiddleware)
+	if sessionBroker {
+		log.Println("Session broker mode, game logic disabled")
+		e.Use(sessionBrokerMiddleware)
+		keepSessionAlive(bot, 10*time.Minute)
 	}
 	e.HideBanner = true
 	e.HidePort = true
 	e.Debug = false
-	e.GET("/", handlers.HomeHandler)
-	e.GET("/tasks", handlers.TasksHandler)
+	readRoutes := scopes.group(e, scopeRead)
+	fleetRoutes := scopes.group(e, scopeFleet)
+	adminRoutes := scopes.group(e, scopeAdmin)
+	readRoutes.GET("/", handlers.HomeHandler)
+	readRoutes.GET("/tasks", handlers.TasksHandler)
+	readRoutes.GET("/tasks/history", handlers.TaskHistoryHandler)
+	readRoutes.GET("/tasks/ws", newTaskStream(bot, cors).Handler)
+	readRoutes.GET("/healthz", health.HealthzHandler)
+	readRoutes.GET("/readyz", health.ReadyzHandler)
+	if statusPageEnabled {
+		readRoutes.GET("/status", newStatusTracker(bot, trustedProxies).Handler)
+	}
+	if jwtAuthenticator != nil {
+		adminRoutes.POST("/auth/token", jwtAuthenticator.TokenHandler)
+		adminRoutes.POST("/auth/refresh", jwtAuthenticator.RefreshHandler)
+	}
+	adminRoutes.POST("/admin/reload", func(c echo.Context) error {
+		if err := runtimeCfg.Reload(bot); err != nil {
+			resp := handlers.ErrorRespOf(err, http.StatusInternalServerError)
+			return c.JSON(resp.Code, resp)
+		}
+		return c.JSON(http.StatusOK, handlers.SuccessResp(nil))
+	})
 
-	/*
-		// CAPTCHA Handler
-		e.GET("/bot/captcha", handlers.GetCaptchaHandler)
-		e.GET("/bot/captcha/icons/:challengeID", handlers.GetCaptchaImgHandler)
-		e.GET("/bot/captcha/question/:challengeID", handlers.GetCaptchaTextHandler)
-		e.POST("/bot/captcha/solve", handlers.GetCaptchaSolverHandler)
-	*/
+	// CAPTCHA Handler
+	adminRoutes.GET("/bot/captcha", handlers.GetCaptchaHandler)
+	adminRoutes.GET("/bot/captcha/icons", handlers.GetCaptchaImgHandler)
+	adminRoutes.GET("/bot/captcha/question", handlers.GetCaptchaTextHandler)
+	adminRoutes.POST("/bot/captcha/solve", handlers.GetCaptchaSolverHandler)
 
-	e.GET("/bot/server", handlers.GetServerHandler)
-	e.GET("/bot/server-data", handlers.GetServerDataHandler)
-	e.POST("/bot/set-user-agent", handlers.SetUserAgentHandler)
-	e.GET("/bot/server-url", handlers.ServerURLHandler)
-	e.GET("/bot/language", handlers.GetLanguageHandler)
-	e.GET("/bot/empire/type/:typeID", handlers.GetEmpireHandler)
-	e.POST("/bot/page-content", handlers.PageContentHandler)
-	e.GET("/bot/login", handlers.LoginHandler)
-	e.GET("/bot/logout", handlers.LogoutHandler)
-	e.GET("/bot/username", handlers.GetUsernameHandler)
-	e.GET("/bot/universe-name", handlers.GetUniverseNameHandler)
-	e.GET("/bot/server/speed", handlers.GetUniverseSpeedHandler)
-	e.GET("/bot/server/speed-fleet", handlers.GetUniverseSpeedFleetHandler)
-	e.GET("/bot/server/version", handlers.ServerVersionHandler)
-	e.GET("/bot/server/time", handlers.ServerTimeHandler)
-	e.GET("/bot/is-under-attack", handlers.IsUnderAttackHandler)
-	e.GET("/bot/is-vacation-mode", handlers.IsVacationModeHandler)
-	e.GET("/bot/user-infos", handlers.GetUserInfosHandler)
-	e.GET("/bot/character-class", handlers.GetCharacterClassHandler)
-	e.GET("/bot/has-commander", handlers.HasCommanderHandler)
-	e.GET("/bot/has-admiral", handlers.HasAdmiralHandler)
-	e.GET("/bot/has-engineer", handlers.HasEngineerHandler)
-	e.GET("/bot/has-geologist", handlers.HasGeologistHandler)
-	e.GET("/bot/has-technocrat", handlers.HasTechnocratHandler)
-	e.POST("/bot/send-message", handlers.SendMessageHandler)
-	e.GET("/bot/fleets", handlers.GetFleetsHandler)
-	e.GET("/bot/fleets/slots", handlers.GetSlotsHandler)
-	e.POST("/bot/fleets/:fleetID/cancel", handlers.CancelFleetHandler)
-	e.GET("/bot/espionage-report/:msgid", handlers.GetEspionageReportHandler)
-	e.GET("/bot/espionage-report/:galaxy/:system/:position", handlers.GetEspionageReportForHandler)
-	e.GET("/bot/espionage-report", handlers.GetEspionageReportMessagesHandler)
-	e.POST("/bot/delete-report/:messageID", handlers.DeleteMessageHandler)
-	e.POST("/bot/delete-all-espionage-reports", handlers.DeleteEspionageMessagesHandler)
-	e.POST("/bot/delete-all-reports/:tabIndex", handlers.DeleteMessagesFromTabHandler)
-	e.GET("/bot/attacks", handlers.GetAttacksHandler)
-	e.GET("/bot/get-auction", handlers.GetAuctionHandler)
-	e.POST("/bot/do-auction", handlers.DoAuctionHandler)
-	e.GET("/bot/galaxy-infos/:galaxy/:system", handlers.GalaxyInfosHandler)
-	e.GET("/bot/get-research", handlers.GetResearchHandler)
-	e.GET("/bot/buy-offer-of-the-day", handlers.BuyOfferOfTheDayHandler)
-	e.GET("/bot/price/:ogameID/:nbr", handlers.GetPriceHandler)
-	e.GET("/bot/moons", handlers.GetMoonsHandler)
-	e.GET("/bot/moons/:moonID", handlers.GetMoonHandler)
-	e.GET("/bot/moons/:galaxy/:system/:position", handlers.GetMoonByCoordHandler)
-	e.GET("/bot/celestials/:celestialID/items", handlers.GetCelestialItemsHandler)
-	e.GET("/bot/celestials/:celestialID/items/:itemRef/activate", handlers.ActivateCelestialItemHandler)
-	e.GET("/bot/celestials/:celestialID/techs", handlers.TechsHandler)
-	e.GET("/bot/planets", handlers.GetPlanetsHandler)
-	e.GET("/bot/planets/:planetID", handlers.GetPlanetHandler)
-	e.GET("/bot/planets/:galaxy/:system/:position", handlers.GetPlanetByCoordHandler)
-	e.GET("/bot/planets/:planetID/resources-details", handlers.GetResourcesDetailsHandler)
-	e.GET("/bot/planets/:planetID/resource-settings", handlers.GetResourceSettingsHandler)
-	e.POST("/bot/planets/:planetID/resource-settings", handlers.SetResourceSettingsHandler)
-	e.GET("/bot/planets/:planetID/resources-buildings", handlers.GetResourcesBuildingsHandler)
-	e.GET("/bot/planets/:planetID/defence", handlers.GetDefenseHandler)
-	e.GET("/bot/planets/:planetID/ships", handlers.GetShipsHandler)
-	e.GET("/bot/planets/:planetID/facilities", handlers.GetFacilitiesHandler)
-	e.POST("/bot/planets/:planetID/build/:ogameID/:nbr", handlers.BuildHandler)
-	e.POST("/bot/planets/:planetID/build/cancelable/:ogameID", handlers.BuildCancelableHandler)
-	e.POST("/bot/planets/:planetID/build/production/:ogameID/:nbr", handlers.BuildProductionHandler)
-	e.POST("/bot/planets/:planetID/build/building/:ogameID", handlers.BuildBuildingHandler)
-	e.POST("/bot/planets/:planetID/build/technology/:ogameID", handlers.BuildTechnologyHandler)
-	e.POST("/bot/planets/:planetID/build/defence/:ogameID/:nbr", handlers.BuildDefenseHandler)
-	e.POST("/bot/planets/:planetID/build/ships/:ogameID/:nbr", handlers.BuildShipsHandler)
-	e.POST("/bot/planets/:planetID/teardown/:ogameID", handlers.TeardownHandler)
-	e.GET("/bot/planets/:planetID/production", handlers.GetProductionHandler)
-	e.GET("/bot/planets/:planetID/constructions", handlers.ConstructionsBeingBuiltHandler)
-	e.POST("/bot/planets/:planetID/cancel-building", handlers.CancelBuildingHandler)
-	e.POST("/bot/planets/:planetID/cancel-research", handlers.CancelResearchHandler)
-	e.GET("/bot/planets/:planetID/resources", handlers.GetResourcesHandler)
-	e.POST("/bot/planets/:planetID/send-fleet", handlers.SendFleetHandler)
-	e.POST("/bot/planets/:planetID/send-ipm", handlers.SendIPMHandler)
-	e.GET("/bot/moons/:moonID/phalanx/:galaxy/:system/:position", handlers.PhalanxHandler)
-	e.POST("/bot/moons/:moonID/jump-gate", handlers.JumpGateHandler)
-	e.GET("/game/allianceInfo.php", handlers.GetAlliancePageContentHandler) // Example: //game/allianceInfo.php?allianceId=500127
+	readRoutes.GET("/bot/aliases", handlers.GetAliasesHandler)
+	adminRoutes.POST("/bot/aliases/:alias", handlers.SetAliasHandler)
+	adminRoutes.DELETE("/bot/aliases/:alias", handlers.DeleteAliasHandler)
+	readRoutes.GET("/bot/server", handlers.GetServerHandler)
+	readRoutes.GET("/bot/server-data", handlers.GetServerDataHandler)
+	readRoutes.GET("/bot/lobby/servers", handlers.GetLobbyServersHandler)
+	readRoutes.GET("/bot/lobby/accounts", handlers.GetLobbyAccountsHandler)
+	adminRoutes.POST("/bot/lobby/accounts", handlers.AddAccountHandler)
+	adminRoutes.POST("/bot/set-user-agent", handlers.SetUserAgentHandler)
+	readRoutes.GET("/bot/server-url", handlers.ServerURLHandler)
+	readRoutes.GET("/bot/language", handlers.GetLanguageHandler)
+	readRoutes.GET("/bot/objects", handlers.GetObjNamesHandler)
+	readRoutes.GET("/bot/empire/type/:typeID", handlers.GetEmpireHandler)
+	fleetRoutes.GET("/bot/empire/sync", handlers.SyncEmpireHandler)
+	adminRoutes.POST("/bot/empire/snapshots/:name", handlers.TakeEmpireSnapshotHandler)
+	readRoutes.GET("/bot/empire/snapshots/:name/diff", handlers.GetEmpireSnapshotDiffHandler)
+	readRoutes.GET("/bot/export/empire", handlers.ExportEmpireHandler)
+	adminRoutes.POST("/bot/import/empire", handlers.ImportEmpireHandler)
+	fleetRoutes.POST("/bot/page-content", handlers.PageContentHandler)
+	adminRoutes.GET("/bot/login", handlers.LoginHandler)
+	adminRoutes.GET("/bot/session", handlers.GetSessionCredentialsHandler)
+	adminRoutes.POST("/bot/migrate-universe", handlers.MigrateUniverseHandler)
+	adminRoutes.POST("/bot/switch-universe", handlers.SwitchUniverseHandler)
+	adminRoutes.GET("/bot/logout", handlers.LogoutHandler)
+	readRoutes.GET("/bot/safe-mode", handlers.IsInSafeModeHandler)
+	adminRoutes.GET("/bot/proxies", handlers.GetProxiesHandler)
+	adminRoutes.GET("/bot/audit", handlers.GetAuditLogHandler)
+	adminRoutes.GET("/bot/cache", handlers.GetCacheHandler)
+	adminRoutes.DELETE("/bot/cache", handlers.InvalidateCacheHandler)
+	readRoutes.GET("/bot/marketplace/prices", handlers.GetMarketplacePricesHandler)
+	adminRoutes.POST("/bot/marketplace/prices", handlers.RecordMarketplacePriceHandler)
+	readRoutes.GET("/bot/events/stream", newEventStream(bot, eventsPollMinInterval, eventsPollMaxInterval).Handler)
+	if plugins != nil {
+		plugins.RegisterRoutes(readRoutes, fleetRoutes)
+	}
+	adminRoutes.GET("/bot/webhooks", webhooks.ListHandler)
+	adminRoutes.POST("/bot/webhooks", webhooks.AddHandler)
+	adminRoutes.DELETE("/bot/webhooks/:id", webhooks.RemoveHandler)
+	readRoutes.GET("/bot/phalanx-watches", handlers.GetPhalanxWatchesHandler)
+	adminRoutes.POST("/bot/phalanx-watches", handlers.AddPhalanxWatchHandler)
+	adminRoutes.DELETE("/bot/phalanx-watches/:id", handlers.RemovePhalanxWatchHandler)
+	readRoutes.GET("/bot/watchlist", handlers.GetWatchlistHandler)
+	adminRoutes.POST("/bot/watchlist", handlers.AddWatchedPlayerHandler)
+	adminRoutes.DELETE("/bot/watchlist/:playerID", handlers.RemoveWatchedPlayerHandler)
+	adminRoutes.GET("/bot/escape-rules", handlers.GetEscapeRulesHandler)
+	adminRoutes.POST("/bot/escape-rules", handlers.SetEscapeRuleHandler)
+	adminRoutes.DELETE("/bot/escape-rules/:celestialID", handlers.RemoveEscapeRuleHandler)
+	adminRoutes.POST("/bot/tx", handlers.BeginTxHandler)
+	adminRoutes.DELETE("/bot/tx/:token", handlers.EndTxHandler)
+	adminRoutes.POST("/bot/resume", handlers.ResumeHandler)
+	readRoutes.GET("/bot/flight-time", handlers.FlightTimeHandler)
+	readRoutes.GET("/bot/cargo-capacity", handlers.CargoCapacityHandler)
+	readRoutes.GET("/bot/username", handlers.GetUsernameHandler)
+	readRoutes.GET("/bot/universe-name", handlers.GetUniverseNameHandler)
+	readRoutes.GET("/bot/server/speed", handlers.GetUniverseSpeedHandler)
+	readRoutes.GET("/bot/server/speed-fleet", handlers.GetUniverseSpeedFleetHandler)
+	readRoutes.GET("/bot/server/version", handlers.ServerVersionHandler)
+	readRoutes.GET("/bot/server/time", handlers.ServerTimeHandler)
+	readRoutes.GET("/bot/is-under-attack", handlers.IsUnderAttackHandler)
+	readRoutes.GET("/bot/is-vacation-mode", handlers.IsVacationModeHandler)
+	readRoutes.GET("/bot/user-infos", handlers.GetUserInfosHandler)
+	readRoutes.GET("/bot/character-class", handlers.GetCharacterClassHandler)
+	fleetRoutes.POST("/bot/character-class", handlers.SetCharacterClassHandler)
+	readRoutes.GET("/bot/has-commander", handlers.HasCommanderHandler)
+	readRoutes.GET("/bot/has-admiral", handlers.HasAdmiralHandler)
+	readRoutes.GET("/bot/has-engineer", handlers.HasEngineerHandler)
+	readRoutes.GET("/bot/has-geologist", handlers.HasGeologistHandler)
+	readRoutes.GET("/bot/has-technocrat", handlers.HasTechnocratHandler)
+	readRoutes.GET("/bot/officers", handlers.GetOfficersHandler)
+	fleetRoutes.POST("/bot/send-message", handlers.SendMessageHandler)
+	readRoutes.GET("/bot/fleets", handlers.GetFleetsHandler)
+	readRoutes.GET("/bot/fleets/slots", handlers.GetSlotsHandler)
+	fleetRoutes.POST("/bot/fleets/:fleetID/cancel", handlers.CancelFleetHandler)
+	readRoutes.GET("/bot/espionage-report/:msgid", handlers.GetEspionageReportHandler)
+	readRoutes.GET("/bot/espionage-report/:msgid/loot", handlers.GetEspionageReportLootHandler)
+	readRoutes.GET("/bot/espionage-report/:msgid/share-key", handlers.GetEspionageReportShareKeyHandler)
+	readRoutes.GET("/bot/espionage-report/:msgid/export", handlers.GetEspionageReportExportHandler)
+	readRoutes.GET("/bot/espionage-report/:msgid/ipm-plan", handlers.GetIPMPlanHandler)
+	readRoutes.GET("/bot/moonshot/plan", handlers.GetMoonshotPlanHandler)
+	readRoutes.GET("/bot/api-report/:key", handlers.GetAPIReportHandler)
+	readRoutes.POST("/bot/loot-estimate", handlers.EstimateLootHandler)
+	readRoutes.GET("/bot/espionage-report/:galaxy/:system/:position", handlers.GetEspionageReportForHandler)
+	readRoutes.GET("/bot/espionage-report/:galaxy/:system/:position/diff", handlers.DiffEspionageReportsHandler)
+	readRoutes.GET("/bot/espionage-report", handlers.GetEspionageReportMessagesHandler)
+	readRoutes.GET("/bot/combat-reports", handlers.GetCombatReportMessagesHandler)
+	readRoutes.GET("/bot/messages/search", handlers.SearchMessagesHandler)
+	fleetRoutes.POST("/bot/delete-report/:messageID", handlers.DeleteMessageHandler)
+	fleetRoutes.POST("/bot/delete-all-espionage-reports", handlers.DeleteEspionageMessagesHandler)
+	fleetRoutes.POST("/bot/delete-all-reports/:tabIndex", handlers.DeleteMessagesFromTabHandler)
+	readRoutes.GET("/bot/attacks", handlers.GetAttacksHandler)
+	readRoutes.GET("/bot/get-auction", handlers.GetAuctionHandler)
+	fleetRoutes.POST("/bot/do-auction", handlers.DoAuctionHandler)
+	readRoutes.GET("/bot/galaxy-infos/:galaxy/:system", handlers.GalaxyInfosHandler)
+	readRoutes.GET("/bot/galaxy/inactives", handlers.GetInactiveTargetsHandler)
+	readRoutes.GET("/bot/colonize/spots", handlers.FindColonySpotsHandler)
+	fleetRoutes.POST("/bot/colonize", handlers.StartColonizeWorkflowHandler)
+	readRoutes.GET("/bot/colonize/workflows", handlers.GetColonizeWorkflowsHandler)
+	fleetRoutes.DELETE("/bot/colonize/workflows/:id", handlers.CancelColonizeWorkflowHandler)
+	readRoutes.GET("/bot/build-templates", handlers.GetBuildTemplatesHandler)
+	readRoutes.GET("/bot/fleet-templates", handlers.GetFleetTemplatesHandler)
+	readRoutes.GET("/bot/get-research", handlers.GetResearchHandler)
+	fleetRoutes.GET("/bot/buy-offer-of-the-day", handlers.BuyOfferOfTheDayHandler)
+	readRoutes.GET("/bot/price/:ogameID/:nbr", handlers.GetPriceHandler)
+	readRoutes.GET("/bot/moons", handlers.GetMoonsHandler)
+	readRoutes.GET("/bot/moons/:moonID", handlers.GetMoonHandler)
+	readRoutes.GET("/bot/moons/:galaxy/:system/:position", handlers.GetMoonByCoordHandler)
+	readRoutes.GET("/bot/items", handlers.GetAllItemsHandler)
+	readRoutes.GET("/bot/celestials/:celestialID/items", handlers.GetCelestialItemsHandler)
+	fleetRoutes.GET("/bot/celestials/:celestialID/items/:itemRef/activate", handlers.ActivateCelestialItemHandler)
+	readRoutes.GET("/bot/celestials/techs", handlers.AllTechsHandler)
+	readRoutes.GET("/bot/celestials/:celestialID/techs", handlers.TechsHandler)
+	readRoutes.GET("/bot/planets", handlers.GetPlanetsHandler)
+	readRoutes.GET("/bot/planets/:planetID", handlers.GetPlanetHandler)
+	readRoutes.GET("/bot/planets/:galaxy/:system/:position", handlers.GetPlanetByCoordHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/rename", handlers.RenamePlanetHandler)
+	readRoutes.GET("/bot/planets/:planetID/resources-details", handlers.GetResourcesDetailsHandler)
+	readRoutes.GET("/bot/storage-eta", handlers.GetStorageETAHandler)
+	readRoutes.GET("/bot/expeditions/stats", handlers.GetExpeditionStatsHandler)
+	readRoutes.GET("/bot/expeditions/loops", handlers.GetExpeditionLoopsHandler)
+	fleetRoutes.DELETE("/bot/expeditions/loops/:id", handlers.StopExpeditionLoopHandler)
+	readRoutes.GET("/bot/advisor/next-builds", handlers.GetNextBuildsHandler)
+	readRoutes.GET("/bot/planets/:planetID/resource-settings", handlers.GetResourceSettingsHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/resource-settings", handlers.SetResourceSettingsHandler)
+	fleetRoutes.POST("/bot/planets/resource-settings", handlers.SetResourceSettingsAllHandler)
+	fleetRoutes.POST("/bot/resource-settings/optimize", handlers.OptimizeResourceSettingsHandler)
+	readRoutes.GET("/bot/planets/:planetID/resources-buildings", handlers.GetResourcesBuildingsHandler)
+	readRoutes.GET("/bot/planets/:planetID/defence", handlers.GetDefenseHandler)
+	readRoutes.GET("/bot/planets/:planetID/ships", handlers.GetShipsHandler)
+	readRoutes.GET("/bot/planets/:planetID/facilities", handlers.GetFacilitiesHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/:ogameID/:nbr", handlers.BuildHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/cancelable/:ogameID", handlers.BuildCancelableHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/production/:ogameID/:nbr", handlers.BuildProductionHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/building/:ogameID", handlers.BuildBuildingHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/technology/:ogameID", handlers.BuildTechnologyHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/defence/:ogameID/:nbr", handlers.BuildDefenseHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build/ships/:ogameID/:nbr", handlers.BuildShipsHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/build-and-wait/:ogameID", webhooks.BuildAndWaitHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/teardown/:ogameID", handlers.TeardownHandler)
+	readRoutes.GET("/bot/planets/:planetID/production", handlers.GetProductionHandler)
+	readRoutes.GET("/bot/planets/:planetID/production/queue", handlers.GetProductionQueueHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/queue-ships/:ogameID/:nbr", handlers.QueueShipsHandler)
+	readRoutes.GET("/bot/planets/:planetID/constructions", handlers.ConstructionsBeingBuiltHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/cancel-building", handlers.CancelBuildingHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/cancel-research", handlers.CancelResearchHandler)
+	readRoutes.GET("/bot/planets/:planetID/resources", handlers.GetResourcesHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/send-fleet", handlers.SendFleetHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/send-ipm", handlers.SendIPMHandler)
+	fleetRoutes.POST("/bot/planets/:planetID/ipm-attack/:msgid", handlers.ExecuteIPMPlanHandler)
+	fleetRoutes.GET("/bot/moons/:moonID/phalanx/:galaxy/:system/:position", handlers.PhalanxHandler)
+	fleetRoutes.POST("/bot/moons/:moonID/jump-gate", handlers.JumpGateHandler)
+	readRoutes.GET("/game/allianceInfo.php", handlers.GetAlliancePageContentHandler) // Example: //game/allianceInfo.php?allianceId=500127
 
 	// Get/Post Page Content
-	e.GET("/game/index.php", handlers.GetFromGameHandler)
-	e.POST("/game/index.php", handlers.PostToGameHandler)
+	fleetRoutes.GET("/game/index.php", handlers.GetFromGameHandler)
+	fleetRoutes.POST("/game/index.php", handlers.PostToGameHandler)
 
 	// For AntiGame plugin
 	// Static content
-	e.GET("/cdn/*", handlers.GetStaticHandler)
-	e.GET("/assets/css/*", handlers.GetStaticHandler)
-	e.GET("/headerCache/*", handlers.GetStaticHandler)
-	e.GET("/favicon.ico", handlers.GetStaticHandler)
-	e.GET("/game/sw.js", handlers.GetStaticHandler)
+	readRoutes.GET("/cdn/*", handlers.GetStaticHandler)
+	readRoutes.GET("/assets/css/*", handlers.GetStaticHandler)
+	readRoutes.GET("/headerCache/*", handlers.GetStaticHandler)
+	readRoutes.GET("/favicon.ico", handlers.GetStaticHandler)
+	readRoutes.GET("/game/sw.js", handlers.GetStaticHandler)
 
 	// JSON API
 	/*
@@ -347,13 +1010,51 @@ func start(c *cli.Context) error {
 		/api/players.xml
 		/api/universe.xml
 	*/
-	e.GET("/api/*", handlers.GetStaticHandler)
-	e.HEAD("/api/*", handlers.GetStaticHEADHandler) // AntiGame uses this to check if the cached XML files need to be refreshed
+	readRoutes.GET("/api/*", handlers.GetStaticHandler)
+	readRoutes.HEAD("/api/*", handlers.GetStaticHEADHandler) // AntiGame uses this to check if the cached XML files need to be refreshed
 
+	network, address, err := listenAddr(listen, host, port)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		if acmeDomain != "" || enableTLS {
+			return errors.New("tls is not supported on a unix socket, let the reverse proxy terminate it")
+		}
+		l, err := listenUnix(address)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(address)
+		e.Listener = l
+		log.Println("Listen on unix socket " + address)
+	}
+	if acmeDomain != "" {
+		if acmeCacheDir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			acmeCacheDir = filepath.Join(home, ".ogame", "acme")
+		}
+		domains := strings.Split(acmeDomain, ",")
+		for i := range domains {
+			domains[i] = strings.TrimSpace(domains[i])
+		}
+		e.AutoTLSManager.HostPolicy = autocert.HostWhitelist(domains...)
+		e.AutoTLSManager.Cache = autocert.DirCache(acmeCacheDir)
+		e.AutoTLSManager.Email = acmeEmail
+		log.Println("Enable TLS Support with Let's Encrypt for " + strings.Join(domains, ", "))
+		// e.StartAutoTLS only sets GetCertificate, the manager config also negotiates acme.ALPNProto so the tls-alpn-01
+		// challenges can complete
+		e.TLSServer.TLSConfig = e.AutoTLSManager.TLSConfig()
+		e.TLSServer.Addr = address
+		return e.StartServer(e.TLSServer)
+	}
 	if enableTLS {
 		log.Println("Enable TLS Support")
-		return e.StartTLS(host+":"+strconv.Itoa(port), tlsCertFile, tlsKeyFile)
+		return e.StartTLS(address, tlsCertFile, tlsKeyFile)
 	}
 	log.Println("Disable TLS Support")
-	return e.Start(host + ":" + strconv.Itoa(port))
+	return e.Start(address)
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/alaingilbert/ogame/plugin"
+	"github.com/labstack/echo"
+)
+
+const pluginEventBuffer = 256
+
+// pluginManager plugins started from the plugins directory
+type pluginManager struct {
+	bot         *ogame.OGame
+	plugins     []*plugin.Client
+	events      map[*plugin.Client]chan ogame.Event // Events waiting to be sent to each plugin
+	done        chan struct{}
+	unsubscribe func()
+}
+
+// startPlugins starts the executables of dir as plugins, and feeds them their events and tasks
+func startPlugins(bot *ogame.OGame, dir string) (*pluginManager, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &pluginManager{bot: bot, events: make(map[*plugin.Client]chan ogame.Event), done: make(chan struct{})}
+	for _, f := range files {
+		if f.IsDir() || f.Mode()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		manifest, err := plugin.LoadManifest(path)
+		if err != nil {
+			m.Stop()
+			return nil, err
+		}
+		p, err := plugin.Start(path, manifest, bot, os.Stderr)
+		if err != nil {
+			m.Stop()
+			return nil, err
+		}
+		log.Printf("plugin %s %s started", p.Info.Name, p.Info.Version)
+		m.plugins = append(m.plugins, p)
+	}
+	for _, p := range m.plugins {
+		for _, task := range p.Info.Tasks {
+			if task.Every > 0 {
+				go m.runTask(p, task)
+			}
+		}
+		if len(p.Info.Events) > 0 {
+			m.events[p] = make(chan ogame.Event, pluginEventBuffer)
+			go m.sendEvents(p, m.events[p])
+		}
+	}
+	m.unsubscribe = bot.Subscribe("", m.dispatchEvent)
+	return m, nil
+}
+
+func (m *pluginManager) runTask(p *plugin.Client, task plugin.Task) {
+	for {
+		select {
+		case <-time.After(task.Every):
+		case <-m.done:
+			return
+		}
+		if !m.bot.IsEnabled() {
+			continue
+		}
+		if err := p.RunTask(task.Name); err != nil {
+			log.Printf("plugin %s task %s failed: %v", p.Info.Name, task.Name, err)
+		}
+	}
+}
+
+// dispatchEvent queues the event for the plugins subscribed to it, a plugin whose queue is full misses the event
+// instead of delaying the other plugins and the bot
+func (m *pluginManager) dispatchEvent(e ogame.Event) {
+	for _, p := range m.plugins {
+		for _, t := range p.Info.Events {
+			if t == e.Type {
+				select {
+				case m.events[p] <- e:
+				default:
+					log.Printf("plugin %s event queue full, %s dropped", p.Info.Name, e.Type)
+				}
+				break
+			}
+		}
+	}
+}
+
+// sendEvents sends the queued events to the plugin, in order, until the plugins are stopped
+func (m *pluginManager) sendEvents(p *plugin.Client, events chan ogame.Event) {
+	for {
+		select {
+		case e := <-events:
+			if err := p.OnEvent(e); err != nil {
+				log.Printf("plugin %s event %s failed: %v", p.Info.Name, e.Type, err)
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// Stop stops the plugins
+func (m *pluginManager) Stop() {
+	if m.unsubscribe != nil {
+		m.unsubscribe()
+	}
+	close(m.done)
+	for _, p := range m.plugins {
+		p.Kill()
+	}
+}
+
+// RegisterRoutes registers the routes of the plugins under /plugins/<plugin name>, they require the fleet scope
+func (m *pluginManager) RegisterRoutes(read, fleet scopedRoutes) {
+	read.GET("/bot/plugins", m.ListHandler)
+	for _, p := range m.plugins {
+		prefix := "/plugins/" + p.Info.Name
+		for _, r := range p.Info.Routes {
+			fleet.Add(strings.ToUpper(r.Method), prefix+r.Path, m.routeHandler(p, prefix))
+		}
+	}
+}
+
+// ListHandler lists the started plugins
+// curl 127.0.0.1:1234/bot/plugins
+func (m *pluginManager) ListHandler(c echo.Context) error {
+	infos := make([]plugin.Info, 0, len(m.plugins))
+	for _, p := range m.plugins {
+		infos = append(infos, p.Info)
+	}
+	return c.JSON(http.StatusOK, handlers.SuccessResp(infos))
+}
+
+// pluginStrippedHeaders credentials of the ogamed API and of the proxies in front of it, not sent to the plugins
+var pluginStrippedHeaders = []string{echo.HeaderAuthorization, "X-API-Key", "Cookie", "Proxy-Authorization"}
+
+// pluginRequestHeader returns a copy of the request headers without the credentials
+func pluginRequestHeader(header http.Header) http.Header {
+	res := make(http.Header, len(header))
+	for k, values := range header {
+		res[k] = append([]string{}, values...)
+	}
+	for _, k := range pluginStrippedHeaders {
+		res.Del(k)
+	}
+	return res
+}
+
+// routeHandler forwards the requests of a plugin route to the plugin
+func (m *pluginManager) routeHandler(p *plugin.Client, prefix string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// The plugin calls the bot from its own process, outside of the transaction
+		if handlers.InTx(c) {
+			return handlers.TxNotSupported(c)
+		}
+		body, err := ioutil.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, handlers.ErrorResp(400, "invalid body"))
+		}
+		params := make(map[string]string)
+		for _, name := range c.ParamNames() {
+			params[name] = c.Param(name)
+		}
+		resp, err := p.ServeHTTP(plugin.Request{
+			Method: c.Request().Method,
+			Path:   strings.TrimPrefix(c.Request().URL.Path, prefix),
+			Params: params,
+			Query:  c.QueryParams(),
+			Header: pluginRequestHeader(c.Request().Header),
+			Body:   body,
+		})
+		if err != nil {
+			return c.JSON(http.StatusBadGateway, handlers.ErrorResp(502, err.Error()))
+		}
+		for k, values := range resp.Header {
+			for _, v := range values {
+				c.Response().Header().Add(k, v)
+			}
+		}
+		if resp.Status == 0 {
+			resp.Status = http.StatusOK
+		}
+		contentType := resp.Header.Get(echo.HeaderContentType)
+		if contentType == "" {
+			contentType = echo.MIMEApplicationJSONCharsetUTF8
+		}
+		return c.Blob(resp.Status, contentType, resp.Body)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginRequestHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	header.Set("X-Api-Key", "secret")
+	header.Set("Cookie", "session=1")
+	header.Set("Proxy-Authorization", "Basic eDp5")
+	header.Set("Accept", "application/json")
+	res := pluginRequestHeader(header)
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, res)
+	// The request headers are left untouched
+	assert.Equal(t, "secret", header.Get("X-API-Key"))
+}
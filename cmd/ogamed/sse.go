@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/labstack/echo"
+)
+
+const (
+	sseKeepAliveInterval = 15 * time.Second
+	sseClientBuffer      = 64
+)
+
+// eventStream fans out the bot events to the Server-Sent Events clients.
+// The events poller only runs while at least one client is connected.
+type eventStream struct {
+	sync.Mutex
+	bot         *ogame.OGame
+	minInterval time.Duration
+	maxInterval time.Duration
+	clients     map[chan ogame.Event]struct{}
+	stopPoller  func()
+}
+
+func newEventStream(bot *ogame.OGame, minInterval, maxInterval time.Duration) *eventStream {
+	s := &eventStream{bot: bot, minInterval: minInterval, maxInterval: maxInterval, clients: make(map[chan ogame.Event]struct{})}
+	bot.OnEvent(s.publish)
+	return s
+}
+
+// publish sends the event to every client, slow clients drop events instead of blocking the others
+func (s *eventStream) publish(e ogame.Event) {
+	s.Lock()
+	defer s.Unlock()
+	for ch := range s.clients {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (s *eventStream) subscribe() chan ogame.Event {
+	s.Lock()
+	defer s.Unlock()
+	ch := make(chan ogame.Event, sseClientBuffer)
+	s.clients[ch] = struct{}{}
+	if s.stopPoller == nil {
+		s.stopPoller = s.bot.StartEventsPoller(s.minInterval, s.maxInterval)
+	}
+	return ch
+}
+
+func (s *eventStream) unsubscribe(ch chan ogame.Event) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.clients, ch)
+	if len(s.clients) == 0 && s.stopPoller != nil {
+		s.stopPoller()
+		s.stopPoller = nil
+	}
+}
+
+// Handler streams the events (attacks, own fleets departing/returning...) as Server-Sent Events.
+// types optionally filters the event types, comma separated.
+// curl -N '127.0.0.1:1234/bot/events/stream?types=attack.detected,fleet.returned'
+func (s *eventStream) Handler(c echo.Context) error {
+	flusher, ok := c.Response().Writer.(http.Flusher)
+	if !ok {
+		return c.JSON(http.StatusInternalServerError, handlers.ErrorResp(500, "streaming not supported"))
+	}
+	types := make(map[ogame.EventType]bool)
+	if typesStr := c.QueryParam("types"); typesStr != "" {
+		for _, typ := range strings.Split(typesStr, ",") {
+			types[ogame.EventType(strings.TrimSpace(typ))] = true
+		}
+	}
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+	var id int64
+	for {
+		select {
+		case e := <-ch:
+			if len(types) > 0 && !types[e.Type] {
+				continue
+			}
+			by, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			id++
+			if _, err := fmt.Fprintf(resp, "id: %d\nevent: %s\ndata: %s\n\n", id, e.Type, by); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(resp, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case <-c.Request().Context().Done():
+			return nil
+		}
+	}
+}
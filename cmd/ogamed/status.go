@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+)
+
+const (
+	statusMaxSamples     = 168 // One week of hourly samples
+	statusSampleInterval = time.Hour
+	statusRateLimit      = 10 // Requests per IP per statusRateWindow
+	statusRateWindow     = time.Minute
+)
+
+type pointsSample struct {
+	At     time.Time
+	Points int64
+}
+
+// statusTracker keeps the non-sensitive information displayed on the public status page
+type statusTracker struct {
+	sync.Mutex
+	bot          *ogame.OGame
+	lastActivity time.Time
+	samples      []pointsSample
+	hits         map[string]int
+	windowStart  time.Time
+	proxies      []*net.IPNet // Reverse proxies whose X-Forwarded-For is trusted
+}
+
+// parseTrustedProxies parses comma separated IPs or CIDRs
+func parseTrustedProxies(s string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, errors.New("invalid trusted proxy " + item)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, errors.New("invalid trusted proxy " + item)
+		}
+		res = append(res, ipNet)
+	}
+	return res, nil
+}
+
+func isTrustedProxy(proxies []*net.IPNet, ipStr string) bool {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the client of req. The X-Forwarded-For header can be set by any client, it is only
+// read when the connection comes from a trusted proxy, from the closest hop to the farthest one not trusted.
+func clientIP(req *http.Request, proxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !isTrustedProxy(proxies, ip) {
+		return ip
+	}
+	hops := strings.Split(req.Header.Get(echo.HeaderXForwardedFor), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrustedProxy(proxies, hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func newStatusTracker(bot *ogame.OGame, proxies []*net.IPNet) *statusTracker {
+	t := &statusTracker{bot: bot, hits: make(map[string]int), proxies: proxies}
+	bot.RegisterHTMLInterceptor(func(method, url string, params, payload url.Values, pageHTML []byte) {
+		t.Lock()
+		t.lastActivity = time.Now()
+		t.Unlock()
+	})
+	go func() {
+		for {
+			t.sample()
+			time.Sleep(statusSampleInterval)
+		}
+	}()
+	return t
+}
+
+func (t *statusTracker) sample() {
+	if !t.bot.IsLoggedIn() {
+		return
+	}
+	points := t.bot.GetCachedPlayer().Points
+	t.Lock()
+	defer t.Unlock()
+	t.samples = append(t.samples, pointsSample{At: time.Now(), Points: points})
+	if len(t.samples) > statusMaxSamples {
+		t.samples = t.samples[len(t.samples)-statusMaxSamples:]
+	}
+}
+
+// allow returns either or not the ip is still under the rate limit
+func (t *statusTracker) allow(ip string) bool {
+	t.Lock()
+	defer t.Unlock()
+	if time.Since(t.windowStart) > statusRateWindow {
+		t.windowStart = time.Now()
+		t.hits = make(map[string]int)
+	}
+	t.hits[ip]++
+	return t.hits[ip] <= statusRateLimit
+}
+
+// polyline builds the svg points of the points trend graph
+func (t *statusTracker) polyline(width, height float64) string {
+	if len(t.samples) < 2 {
+		return ""
+	}
+	min, max := t.samples[0].Points, t.samples[0].Points
+	for _, s := range t.samples {
+		min = ogame.MinInt(min, s.Points)
+		max = ogame.MaxInt(max, s.Points)
+	}
+	span := float64(max - min)
+	if span == 0 {
+		span = 1
+	}
+	pts := make([]string, 0, len(t.samples))
+	for i, s := range t.samples {
+		x := float64(i) * width / float64(len(t.samples)-1)
+		y := height - float64(s.Points-min)*height/span
+		pts = append(pts, strconv.FormatFloat(x, 'f', 1, 64)+","+strconv.FormatFloat(y, 'f', 1, 64))
+	}
+	return strings.Join(pts, " ")
+}
+
+var statusTmpl = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>ogamed status</title></head>
+<body>
+<h1>ogamed status</h1>
+<p>Logged in: {{if .LoggedIn}}yes{{else}}no{{end}}</p>
+<p>Last activity: {{if .LastActivity.IsZero}}never{{else}}{{.LastActivity.Format "2006-01-02 15:04:05 MST"}}{{end}}</p>
+{{if .Polyline}}<svg width="600" height="150" style="border:1px solid #ccc"><polyline fill="none" stroke="#337ab7" stroke-width="2" points="{{.Polyline}}"/></svg>{{end}}
+</body>
+</html>`))
+
+// Handler public read-only status page, it never exposes credentials or game details
+func (t *statusTracker) Handler(c echo.Context) error {
+	if !t.allow(clientIP(c.Request(), t.proxies)) {
+		return c.String(http.StatusTooManyRequests, "too many requests")
+	}
+	t.Lock()
+	data := struct {
+		LoggedIn     bool
+		LastActivity time.Time
+		Polyline     string
+	}{t.bot.IsLoggedIn(), t.lastActivity, t.polyline(600, 150)}
+	t.Unlock()
+	var buf bytes.Buffer
+	if err := statusTmpl.Execute(&buf, data); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := parseTrustedProxies(" 10.0.0.1, 192.168.0.0/16,,::1 ")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(proxies))
+	assert.Equal(t, "10.0.0.1/32", proxies[0].String())
+	assert.Equal(t, "192.168.0.0/16", proxies[1].String())
+	assert.Equal(t, "::1/128", proxies[2].String())
+
+	proxies, err = parseTrustedProxies("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(proxies))
+
+	_, err = parseTrustedProxies("10.0.0.1,localhost")
+	assert.EqualError(t, err, "invalid trusted proxy localhost")
+	_, err = parseTrustedProxies("10.0.0.0/33")
+	assert.EqualError(t, err, "invalid trusted proxy 10.0.0.0/33")
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, _ := parseTrustedProxies("10.0.0.1,192.168.0.0/16")
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		xForwardedFor string
+		expected      string
+	}{
+		{"direct client", "1.2.3.4:5000", "", "1.2.3.4"},
+		{"spoofed header from an untrusted client", "1.2.3.4:5000", "5.6.7.8", "1.2.3.4"},
+		{"trusted proxy", "10.0.0.1:5000", "5.6.7.8", "5.6.7.8"},
+		{"trusted proxy without header", "10.0.0.1:5000", "", "10.0.0.1"},
+		{"chain of trusted proxies", "10.0.0.1:5000", "5.6.7.8, 192.168.1.2", "5.6.7.8"},
+		{"client spoofing a hop behind the proxy", "10.0.0.1:5000", "9.9.9.9, 5.6.7.8", "5.6.7.8"},
+		{"only trusted hops", "10.0.0.1:5000", "192.168.1.2", "192.168.1.2"},
+		{"empty hops", "10.0.0.1:5000", "5.6.7.8, ,", "5.6.7.8"},
+		{"remote address without port", "1.2.3.4", "5.6.7.8", "1.2.3.4"},
+		{"ipv6 client", "[2001:db8::1]:5000", "5.6.7.8", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.xForwardedFor)
+			}
+			assert.Equal(t, tt.expected, clientIP(req, proxies))
+		})
+	}
+
+	// Without trusted proxies the header is never read
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "5.6.7.8")
+	assert.Equal(t, "10.0.0.1", clientIP(req, nil))
+}
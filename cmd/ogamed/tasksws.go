@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+	"golang.org/x/net/websocket"
+)
+
+const taskStreamClientBuffer = 256
+
+// taskStream fans out the task lifecycle events to the WebSocket clients.
+// The bot is only subscribed to while at least one client is connected.
+type taskStream struct {
+	sync.Mutex
+	bot         *ogame.OGame
+	cors        corsConfig
+	clients     map[chan ogame.TaskEvent]struct{}
+	unsubscribe func()
+}
+
+func newTaskStream(bot *ogame.OGame, cors corsConfig) *taskStream {
+	return &taskStream{bot: bot, cors: cors, clients: make(map[chan ogame.TaskEvent]struct{})}
+}
+
+// publish sends the event to every client, slow clients drop events instead of blocking the bot lock
+func (s *taskStream) publish(e ogame.TaskEvent) {
+	s.Lock()
+	defer s.Unlock()
+	for ch := range s.clients {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (s *taskStream) subscribe() chan ogame.TaskEvent {
+	s.Lock()
+	defer s.Unlock()
+	ch := make(chan ogame.TaskEvent, taskStreamClientBuffer)
+	s.clients[ch] = struct{}{}
+	if s.unsubscribe == nil {
+		s.unsubscribe = s.bot.SubscribeTasks(s.publish)
+	}
+	return ch
+}
+
+func (s *taskStream) unsubscribeClient(ch chan ogame.TaskEvent) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.clients, ch)
+	if len(s.clients) == 0 && s.unsubscribe != nil {
+		s.unsubscribe()
+		s.unsubscribe = nil
+	}
+}
+
+// Handler streams the task lifecycle events (enqueued, started, finished, failed) as WebSocket messages, in the events
+// JSON schema.
+// types optionally filters the event types, comma separated.
+// websocat 'ws://127.0.0.1:1234/tasks/ws?types=task.finished,task.failed'
+func (s *taskStream) Handler(c echo.Context) error {
+	types := make(map[ogame.EventType]bool)
+	if typesStr := c.QueryParam("types"); typesStr != "" {
+		for _, typ := range strings.Split(typesStr, ",") {
+			types[ogame.EventType(strings.TrimSpace(typ))] = true
+		}
+	}
+	server := websocket.Server{
+		Handshake: func(_ *websocket.Config, req *http.Request) error { return s.cors.checkWebSocketOrigin(req) },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			ch := s.subscribe()
+			defer s.unsubscribeClient(ch)
+			// The client does not send messages, reading detects when it disconnects
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				var msg string
+				for websocket.Message.Receive(ws, &msg) == nil {
+				}
+			}()
+			for {
+				select {
+				case e := <-ch:
+					if len(types) > 0 && !types[e.Type] {
+						continue
+					}
+					if err := websocket.JSON.Send(ws, ogame.NewTaskEvent(e)); err != nil {
+						return
+					}
+				case <-closed:
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
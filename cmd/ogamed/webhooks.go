@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/alaingilbert/ogame/handlers"
+	"github.com/labstack/echo"
+)
+
+const (
+	webhookMaxAttempts = 5
+	webhookTimeout     = 10 * time.Second
+	// Tolerance when matching an attack with a fleet movement seen by a phalanx watch
+	webhookPhalanxArrivalTolerance = 5 * time.Second
+)
+
+// webhookConfig webhook called when the bot starts being under attack, with an attack.under_attack event (EVENTS.md).
+// When Secret is set, the payload is signed with HMAC-SHA256 in the X-Ogamed-Signature header (sha256=<hex>). The
+// signed content is the X-Ogamed-Timestamp header (unix seconds), a dot and the body, so a receiver can reject replays.
+type webhookConfig struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// webhookInfo webhook as listed by GET /bot/webhooks, the secret is never returned
+type webhookInfo struct {
+	ID     int64  `json:"id"`
+	URL    string `json:"url"`
+	Signed bool   `json:"signed"`
+	Source string `json:"source"` // config | api
+}
+
+// webhookAttack attack as sent in the webhook payload
+type webhookAttack struct {
+	ogame.EventAttackData
+	ShipsSource string `json:"ships_source,omitempty"` // event_list | phalanx, empty when the ships are unknown
+}
+
+// webhookUnderAttackData data of the attack.under_attack event posted to the webhooks
+type webhookUnderAttackData struct {
+	Attacks []webhookAttack `json:"attacks"`
+}
+
+// webhookNotifier polls IsUnderAttack and calls the webhooks when it transitions to true
+type webhookNotifier struct {
+	sync.Mutex
+	bot         *ogame.OGame
+	cfg         *runtimeConfig
+	client      *http.Client
+	hooks       map[int64]webhookConfig // Registered with POST /bot/webhooks
+	nextID      int64
+	underAttack bool
+}
+
+// noRedirect answers the redirects as is, they could lead the callbacks outside of the allowed hosts
+func noRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+func newWebhookNotifier(bot *ogame.OGame, cfg *runtimeConfig) *webhookNotifier {
+	return &webhookNotifier{
+		bot:    bot,
+		cfg:    cfg,
+		client: &http.Client{Timeout: webhookTimeout, CheckRedirect: noRedirect},
+		hooks:  make(map[int64]webhookConfig),
+	}
+}
+
+// targets returns the webhooks from the config file followed by the ones registered over the API
+func (n *webhookNotifier) targets() []webhookConfig {
+	res := n.cfg.Webhooks()
+	n.Lock()
+	defer n.Unlock()
+	ids := make([]int64, 0, len(n.hooks))
+	for id := range n.hooks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		res = append(res, n.hooks[id])
+	}
+	return res
+}
+
+// Watch checks IsUnderAttack every interval, only while webhooks are configured
+func (n *webhookNotifier) Watch(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			if len(n.targets()) == 0 || !n.bot.IsLoggedIn() {
+				continue
+			}
+			underAttack, err := n.bot.IsUnderAttack()
+			if err != nil {
+				continue
+			}
+			n.Lock()
+			transition := underAttack && !n.underAttack
+			n.underAttack = underAttack
+			n.Unlock()
+			if transition {
+				n.notify()
+			}
+		}
+	}()
+}
+
+// phalanxShips returns the ships of the attack if a phalanx watch saw the same fleet movement
+func (n *webhookNotifier) phalanxShips(a ogame.AttackEvent) *ogame.ShipsInfos {
+	for _, w := range n.bot.GetPhalanxWatches() {
+		for _, m := range w.Movements {
+			d := m.Fleet.ArrivalTime.Sub(a.ArrivalTime)
+			if m.Fleet.Origin.Equal(a.Origin) && m.Fleet.Destination.Equal(a.Destination) &&
+				d <= webhookPhalanxArrivalTolerance && d >= -webhookPhalanxArrivalTolerance {
+				ships := m.Fleet.Ships
+				return &ships
+			}
+		}
+	}
+	return nil
+}
+
+func (n *webhookNotifier) notify() {
+	attacks, err := n.bot.GetAttacks()
+	if err != nil {
+		log.Println("failed to get attacks for webhooks: " + err.Error())
+		return
+	}
+	data := webhookUnderAttackData{Attacks: make([]webhookAttack, 0, len(attacks))}
+	for _, a := range attacks {
+		source := ""
+		if a.Ships != nil {
+			source = "event_list"
+		} else if a.Ships = n.phalanxShips(a); a.Ships != nil {
+			source = "phalanx"
+		}
+		attack := ogame.NewAttackEvent(a).Data.(ogame.EventAttackData)
+		data.Attacks = append(data.Attacks, webhookAttack{EventAttackData: attack, ShipsSource: source})
+	}
+	event := ogame.Event{SchemaVersion: ogame.EventsSchemaVersion, Type: ogame.UnderAttackEvent, Time: time.Now(), Data: data}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	for _, hook := range n.targets() {
+		go n.send(hook, string(event.Type), body)
+	}
+}
+
+// sign returns the hex HMAC-SHA256 of timestamp.body
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp + "."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// send posts the payload, retrying with an exponential backoff on network errors and non 2xx responses
+func (n *webhookNotifier) send(hook webhookConfig, event string, body []byte) {
+	backoff := time.Second
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		err := n.post(hook, event, body)
+		if err == nil {
+			return
+		}
+		if attempt == webhookMaxAttempts {
+			log.Println("webhook " + hook.URL + " failed: " + err.Error())
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (n *webhookNotifier) post(hook webhookConfig, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Ogamed-Event", event)
+	if hook.Secret != "" {
+		// Every attempt is signed with its own timestamp
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Ogamed-Timestamp", timestamp)
+		req.Header.Set("X-Ogamed-Signature", "sha256="+sign(hook.Secret, timestamp, body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// ListHandler lists the webhooks
+// curl 127.0.0.1:1234/bot/webhooks
+func (n *webhookNotifier) ListHandler(c echo.Context) error {
+	res := make([]webhookInfo, 0)
+	for _, hook := range n.cfg.Webhooks() {
+		res = append(res, webhookInfo{URL: hook.URL, Signed: hook.Secret != "", Source: "config"})
+	}
+	n.Lock()
+	for id, hook := range n.hooks {
+		res = append(res, webhookInfo{ID: id, URL: hook.URL, Signed: hook.Secret != "", Source: "api"})
+	}
+	n.Unlock()
+	sort.SliceStable(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return c.JSON(http.StatusOK, handlers.SuccessResp(res))
+}
+
+// AddHandler registers a webhook called when the bot starts being under attack, secret is optional
+// curl 127.0.0.1:1234/bot/webhooks -d 'url=https://example.com/hook&secret=s3cr3t'
+func (n *webhookNotifier) AddHandler(c echo.Context) error {
+	hook := webhookConfig{URL: c.FormValue("url"), Secret: c.FormValue("secret")}
+	if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return c.JSON(http.StatusBadRequest, handlers.ErrorResp(400, "invalid url"))
+	}
+	n.Lock()
+	n.nextID++
+	id := n.nextID
+	n.hooks[id] = hook
+	n.Unlock()
+	return c.JSON(http.StatusOK, handlers.SuccessResp(webhookInfo{ID: id, URL: hook.URL, Signed: hook.Secret != "", Source: "api"}))
+}
+
+// RemoveHandler removes a webhook registered with POST /bot/webhooks
+// curl 127.0.0.1:1234/bot/webhooks/1 -X DELETE
+func (n *webhookNotifier) RemoveHandler(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, handlers.ErrorResp(400, "invalid id"))
+	}
+	n.Lock()
+	defer n.Unlock()
+	if _, ok := n.hooks[id]; !ok {
+		return c.JSON(http.StatusNotFound, handlers.ErrorResp(404, "webhook not found"))
+	}
+	delete(n.hooks, id)
+	return c.JSON(http.StatusOK, handlers.SuccessResp(nil))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_Post_Signature(t *testing.T) {
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	n := &webhookNotifier{client: srv.Client()}
+	payload := []byte(`{"type":"attack.under_attack"}`)
+
+	// The receiver checks the signature of timestamp.body with the shared secret
+	assert.NoError(t, n.post(webhookConfig{URL: srv.URL, Secret: "secret"}, "attack.under_attack", payload))
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "attack.under_attack", header.Get("X-Ogamed-Event"))
+	timestamp := header.Get("X-Ogamed-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Unix(), sec, 5)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(timestamp + "." + string(payload)))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), header.Get("X-Ogamed-Signature"))
+
+	// Not signed without a secret
+	assert.NoError(t, n.post(webhookConfig{URL: srv.URL}, "attack.under_attack", payload))
+	assert.Equal(t, "", header.Get("X-Ogamed-Signature"))
+	assert.Equal(t, "", header.Get("X-Ogamed-Timestamp"))
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, sign("secret", "1600000000", []byte("body")), sign("secret", "1600000000", []byte("body")))
+	assert.NotEqual(t, sign("secret", "1600000000", []byte("body")), sign("secret", "1600000001", []byte("body")))
+	assert.NotEqual(t, sign("secret", "1600000000", []byte("body")), sign("other", "1600000000", []byte("body")))
+}
@@ -0,0 +1,514 @@
+package ogame
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Colonize workflow statuses
+const (
+	WorkflowRunning  = "running"
+	WorkflowDone     = "done"
+	WorkflowFailed   = "failed"
+	WorkflowCanceled = "canceled"
+)
+
+// Colonize workflow steps, in order
+const (
+	ColonizeStepSend      = "send"      // Send the colony ship
+	ColonizeStepColonize  = "colonize"  // Wait for the colony ship, then for the new planet
+	ColonizeStepRename    = "rename"    // Rename the new planet
+	ColonizeStepTransport = "transport" // Send the transports of the feeder planet
+	ColonizeStepBuild     = "build"     // Build the template, once the transports arrived
+	ColonizeStepDone      = "done"
+)
+
+// Colonize workflow delays
+const (
+	colonizeWorkflowRetryInterval = 5 * time.Minute // Wait before retrying a construction that did not start (resources, busy queue)
+	colonizeWorkflowLoggedOutWait = time.Minute     // Wait while the bot is logged out or disabled
+	colonizeWorkflowArrivalGrace  = 2 * time.Minute // Time the new planet has to show up after the colony ship arrival
+)
+
+// BuildTemplateItem building level of a build template
+type BuildTemplateItem struct {
+	ID    ID
+	Level int64
+}
+
+// ColonizeTransport fleet sent from the feeder planet to the new planet
+type ColonizeTransport struct {
+	Ships     []Quantifiable
+	Resources Resources
+}
+
+// ColonizeParams parameters of a colonize workflow
+type ColonizeParams struct {
+	Origin      CelestialID // Celestial the colony ship is sent from
+	Destination Coordinate
+	Speed       Speed               // HundredPercent if 0
+	Name        string              // New name of the planet, kept if empty
+	Template    string              // Name of the build template applied to the planet, none if empty
+	Feeder      CelestialID         // Celestial the transports are sent from
+	Transports  []ColonizeTransport // Sent once the planet is colonized
+}
+
+// ColonizeWorkflow state of a colonize workflow, persisted after every step so it can be resumed
+type ColonizeWorkflow struct {
+	ID               int64
+	Params           ColonizeParams
+	Template         []BuildTemplateItem // Copy of the build template when the workflow was started
+	Status           string              // running, done, failed or canceled
+	Step             string
+	FleetID          FleetID   // Colony ship fleet
+	ArrivalTime      time.Time // Colony ship arrival
+	PlanetID         PlanetID  // New planet, 0 until colonized
+	TransportsSent   int64
+	TransportArrival time.Time // Arrival of the last transport
+	// TransportPending is set, and saved, before a transport is sent with the number of transports flying to the planet
+	// at that time. A workflow resumed with a pending transport finds it in the fleets instead of sending it again.
+	TransportPending   bool
+	TransportsInFlight int64
+	Built              int64 // Template items done
+	Error              string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// WorkflowStore colonize workflows of the bot. When a file is used, the workflows are saved to it after every change
+// and loaded on creation.
+type WorkflowStore struct {
+	sync.Mutex
+	filename  string
+	workflows map[int64]*ColonizeWorkflow
+	nextID    int64
+	cancels   map[int64]chan struct{} // Closed to stop the runner of a workflow
+}
+
+func newWorkflowStore() *WorkflowStore {
+	return &WorkflowStore{workflows: make(map[int64]*ColonizeWorkflow), cancels: make(map[int64]chan struct{})}
+}
+
+// NewWorkflowStore creates a workflow store backed by filename, an empty filename keeps the workflows in memory only
+func NewWorkflowStore(filename string) (*WorkflowStore, error) {
+	s := newWorkflowStore()
+	s.filename = filename
+	if filename == "" {
+		return s, nil
+	}
+	by, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var workflows []ColonizeWorkflow
+	if err := json.Unmarshal(by, &workflows); err != nil {
+		return nil, err
+	}
+	for i := range workflows {
+		w := workflows[i]
+		s.workflows[w.ID] = &w
+		s.nextID = MaxInt(s.nextID, w.ID)
+	}
+	return s, nil
+}
+
+// save writes the workflows to the file, the caller must hold the lock
+func (s *WorkflowStore) save() error {
+	if s.filename == "" {
+		return nil
+	}
+	by, err := json.Marshal(s.list())
+	if err != nil {
+		return err
+	}
+	tmp := s.filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, by, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.filename)
+}
+
+// list returns the workflows sorted by id, the caller must hold the lock
+func (s *WorkflowStore) list() []ColonizeWorkflow {
+	res := make([]ColonizeWorkflow, 0, len(s.workflows))
+	for _, w := range s.workflows {
+		res = append(res, *w)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+func (s *WorkflowStore) add(w ColonizeWorkflow) (ColonizeWorkflow, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.nextID++
+	w.ID = s.nextID
+	s.workflows[w.ID] = &w
+	return w, s.save()
+}
+
+func (s *WorkflowStore) get(id int64) (ColonizeWorkflow, bool) {
+	s.Lock()
+	defer s.Unlock()
+	w, ok := s.workflows[id]
+	if !ok {
+		return ColonizeWorkflow{}, false
+	}
+	return *w, true
+}
+
+// update saves the workflow, unless it was canceled meanwhile
+func (s *WorkflowStore) update(w ColonizeWorkflow) error {
+	s.Lock()
+	defer s.Unlock()
+	curr, ok := s.workflows[w.ID]
+	if !ok || curr.Status != WorkflowRunning {
+		return nil
+	}
+	w.UpdatedAt = time.Now()
+	*curr = w
+	return s.save()
+}
+
+// Workflows returns the workflows sorted by id
+func (s *WorkflowStore) Workflows() []ColonizeWorkflow {
+	s.Lock()
+	defer s.Unlock()
+	return s.list()
+}
+
+// SetWorkflowStore sets the store of the colonize workflows, call ResumeColonizeWorkflows to resume its running ones
+func (b *OGame) SetWorkflowStore(s *WorkflowStore) {
+	b.workflowsMu.Lock()
+	defer b.workflowsMu.Unlock()
+	b.workflows = s
+}
+
+func (b *OGame) getWorkflowStore() *WorkflowStore {
+	b.workflowsMu.RLock()
+	defer b.workflowsMu.RUnlock()
+	return b.workflows
+}
+
+// SetBuildTemplate sets a named list of building levels, built in order by the colonize workflows
+func (b *OGame) SetBuildTemplate(name string, items []BuildTemplateItem) error {
+	if name == "" {
+		return errors.New("invalid build template name")
+	}
+	for _, item := range items {
+		if !item.ID.IsBuilding() || item.Level <= 0 {
+			return errors.New("invalid build template item " + item.ID.String() + " " + strconv.FormatInt(item.Level, 10))
+		}
+	}
+	b.buildTemplatesMu.Lock()
+	defer b.buildTemplatesMu.Unlock()
+	if b.buildTemplates == nil {
+		b.buildTemplates = make(map[string][]BuildTemplateItem)
+	}
+	b.buildTemplates[name] = append([]BuildTemplateItem{}, items...)
+	return nil
+}
+
+// RemoveBuildTemplate removes a build template, the workflows already started keep their copy
+func (b *OGame) RemoveBuildTemplate(name string) {
+	b.buildTemplatesMu.Lock()
+	defer b.buildTemplatesMu.Unlock()
+	delete(b.buildTemplates, name)
+}
+
+// GetBuildTemplates returns the build templates by name
+func (b *OGame) GetBuildTemplates() map[string][]BuildTemplateItem {
+	b.buildTemplatesMu.RLock()
+	defer b.buildTemplatesMu.RUnlock()
+	res := make(map[string][]BuildTemplateItem, len(b.buildTemplates))
+	for name, items := range b.buildTemplates {
+		res[name] = append([]BuildTemplateItem{}, items...)
+	}
+	return res
+}
+
+// StartColonizeWorkflow sends a colony ship, and once the planet is colonized renames it, sends the transports of the
+// feeder planet and builds the template. The workflow runs in the background and is saved after every step, so it
+// survives restarts when the store is backed by a file (see ResumeColonizeWorkflows).
+func (b *OGame) StartColonizeWorkflow(params ColonizeParams) (ColonizeWorkflow, error) {
+	if b.GetCachedCelestialByID(params.Origin) == nil {
+		return ColonizeWorkflow{}, errors.New("origin celestial not found")
+	}
+	if !params.Destination.IsPlanet() || params.Destination.Position < 1 || params.Destination.Position > 15 {
+		return ColonizeWorkflow{}, errors.New("invalid destination " + params.Destination.String())
+	}
+	if params.Speed == 0 {
+		params.Speed = HundredPercent
+	}
+	if !params.Speed.IsValid(b.CharacterClass()) {
+		return ColonizeWorkflow{}, ErrInvalidSpeed
+	}
+	if len(params.Transports) > 0 && b.GetCachedCelestialByID(params.Feeder) == nil {
+		return ColonizeWorkflow{}, errors.New("feeder celestial not found")
+	}
+	w := ColonizeWorkflow{Params: params, Status: WorkflowRunning, Step: ColonizeStepSend, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if params.Template != "" {
+		template, ok := b.GetBuildTemplates()[params.Template]
+		if !ok {
+			return ColonizeWorkflow{}, errors.New("build template " + params.Template + " not found")
+		}
+		w.Template = template
+	}
+	w, err := b.getWorkflowStore().add(w)
+	if err != nil {
+		return w, err
+	}
+	b.runColonizeWorkflow(w.ID)
+	return w, nil
+}
+
+// GetColonizeWorkflows returns the colonize workflows, running and finished
+func (b *OGame) GetColonizeWorkflows() []ColonizeWorkflow {
+	return b.getWorkflowStore().Workflows()
+}
+
+// CancelColonizeWorkflow stops a running workflow, the fleets already sent are not recalled
+func (b *OGame) CancelColonizeWorkflow(id int64) error {
+	s := b.getWorkflowStore()
+	s.Lock()
+	defer s.Unlock()
+	w, ok := s.workflows[id]
+	if !ok {
+		return errors.New("workflow not found")
+	}
+	if w.Status != WorkflowRunning {
+		return errors.New("workflow is " + w.Status)
+	}
+	w.Status = WorkflowCanceled
+	w.UpdatedAt = time.Now()
+	if cancel, ok := s.cancels[id]; ok {
+		close(cancel)
+		delete(s.cancels, id)
+	}
+	return s.save()
+}
+
+// ResumeColonizeWorkflows resumes the running workflows of the store, eg: after a restart
+func (b *OGame) ResumeColonizeWorkflows() {
+	for _, w := range b.GetColonizeWorkflows() {
+		if w.Status == WorkflowRunning {
+			b.runColonizeWorkflow(w.ID)
+		}
+	}
+}
+
+// runColonizeWorkflow executes the steps of the workflow in the background until it is done, failed or canceled
+func (b *OGame) runColonizeWorkflow(id int64) {
+	s := b.getWorkflowStore()
+	s.Lock()
+	if _, ok := s.cancels[id]; ok {
+		s.Unlock()
+		return
+	}
+	cancel := make(chan struct{})
+	s.cancels[id] = cancel
+	s.Unlock()
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	go func() {
+		defer cancelCtx()
+		defer func() {
+			s.Lock()
+			if s.cancels[id] == cancel {
+				delete(s.cancels, id)
+			}
+			s.Unlock()
+		}()
+		go func() {
+			select {
+			case <-cancel:
+				cancelCtx()
+			case <-ctx.Done():
+			}
+		}()
+		for {
+			w, ok := s.get(id)
+			if !ok || w.Status != WorkflowRunning {
+				return
+			}
+			wait := colonizeWorkflowLoggedOutWait
+			if b.isEnabled() && b.IsLoggedIn() {
+				var err error
+				wait, err = b.colonizeStep(ctx, &w)
+				if err != nil {
+					w.Status = WorkflowFailed
+					w.Error = err.Error()
+				} else if w.Step == ColonizeStepDone {
+					w.Status = WorkflowDone
+				}
+				if err := s.update(w); err != nil {
+					b.error("failed to save colonize workflow:", err)
+				}
+				if w.Status != WorkflowRunning {
+					return
+				}
+			}
+			if wait <= 0 {
+				continue
+			}
+			select {
+			case <-time.After(wait):
+			case <-cancel:
+				return
+			}
+		}
+	}()
+}
+
+// colonizeStep executes the current step of the workflow, or a part of it, and returns the time to wait before the
+// next call. ctx is done when the workflow is canceled.
+func (b *OGame) colonizeStep(ctx context.Context, w *ColonizeWorkflow) (time.Duration, error) {
+	p := w.Params
+	switch w.Step {
+	case ColonizeStepSend:
+		fleet := b.colonyShipFleet(p.Destination)
+		if fleet.ID == 0 {
+			var err error
+			ships := []Quantifiable{{ID: ColonyShipID, Nbr: 1}}
+			if fleet, err = b.WithPriority(Normal).SendFleet(p.Origin, ships, p.Speed, p.Destination, Colonize, Resources{}, 0, 0); err != nil {
+				return 0, err
+			}
+		}
+		w.FleetID = fleet.ID
+		w.ArrivalTime = fleet.ArrivalTime
+		w.Step = ColonizeStepColonize
+		return time.Until(w.ArrivalTime), nil
+
+	case ColonizeStepColonize:
+		if wait := time.Until(w.ArrivalTime); wait > 0 {
+			return wait, nil
+		}
+		for _, planet := range b.WithPriority(Normal).GetPlanets() {
+			if planet.Coordinate.Equal(p.Destination) {
+				w.PlanetID = planet.ID
+				w.Step = ColonizeStepRename
+				return 0, nil
+			}
+		}
+		if time.Since(w.ArrivalTime) > colonizeWorkflowArrivalGrace {
+			return 0, errors.New("colonization of " + p.Destination.String() + " failed")
+		}
+		return 30 * time.Second, nil
+
+	case ColonizeStepRename:
+		if p.Name != "" {
+			if err := b.WithPriority(Normal).RenamePlanet(w.PlanetID.Celestial(), p.Name); err != nil {
+				return 0, err
+			}
+		}
+		w.Step = ColonizeStepTransport
+		return 0, nil
+
+	case ColonizeStepTransport:
+		if w.TransportsSent >= int64(len(p.Transports)) {
+			w.Step = ColonizeStepBuild
+			return 0, nil
+		}
+		fleets, _ := b.WithPriority(Normal).GetFleets()
+		var feeder *Coordinate
+		if c := b.GetCachedCelestialByID(p.Feeder); c != nil {
+			coord := c.GetCoordinate()
+			feeder = &coord
+		}
+		inFlight, lastArrival := colonizeTransportsInFlight(fleets, feeder, p.Destination)
+		if !w.TransportPending {
+			w.TransportPending = true
+			w.TransportsInFlight = inFlight
+			return 0, nil
+		}
+		arrival := lastArrival
+		if inFlight <= w.TransportsInFlight {
+			t := p.Transports[w.TransportsSent]
+			fleet, err := b.WithPriority(Normal).SendFleet(p.Feeder, t.Ships, HundredPercent, p.Destination, Transport, t.Resources, 0, 0)
+			if err != nil {
+				return 0, err
+			}
+			arrival = fleet.ArrivalTime
+		}
+		w.TransportPending = false
+		w.TransportsSent++
+		if arrival.After(w.TransportArrival) {
+			w.TransportArrival = arrival
+		}
+		return 0, nil
+
+	case ColonizeStepBuild:
+		if wait := time.Until(w.TransportArrival); wait > 0 {
+			return wait, nil
+		}
+		if w.Built >= int64(len(w.Template)) {
+			w.Step = ColonizeStepDone
+			return 0, nil
+		}
+		item := w.Template[w.Built]
+		level, err := b.buildingLevel(w.PlanetID.Celestial(), item.ID)
+		if err != nil {
+			return 0, err
+		}
+		if level >= item.Level {
+			w.Built++
+			return 0, nil
+		}
+		if err := b.BuildAndWait(ctx, w.PlanetID.Celestial(), item.ID); err == ErrConstructionNotStarted || err == ErrConstructionCanceled {
+			return colonizeWorkflowRetryInterval, nil
+		} else if err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("invalid colonize workflow step %q", w.Step)
+}
+
+// colonizeTransportsInFlight returns the number of transports flying from the feeder to the destination, and the
+// arrival of the last one. Any origin matches if feeder is nil.
+func colonizeTransportsInFlight(fleets []Fleet, feeder *Coordinate, destination Coordinate) (nbr int64, lastArrival time.Time) {
+	for _, f := range fleets {
+		if f.Mission != Transport || f.ReturnFlight || !f.Destination.Equal(destination) {
+			continue
+		}
+		if feeder != nil && !f.Origin.Equal(*feeder) {
+			continue
+		}
+		nbr++
+		if f.ArrivalTime.After(lastArrival) {
+			lastArrival = f.ArrivalTime
+		}
+	}
+	return
+}
+
+// colonyShipFleet returns the colony ship already flying to the destination, if any, so a workflow interrupted right
+// after sending its colony ship does not send a second one
+func (b *OGame) colonyShipFleet(destination Coordinate) Fleet {
+	fleets, _ := b.WithPriority(Normal).GetFleets()
+	for _, f := range fleets {
+		if f.Mission == Colonize && !f.ReturnFlight && f.Destination.Equal(destination) {
+			return f
+		}
+	}
+	return Fleet{}
+}
+
+// buildingLevel returns the level of a building of the celestial
+func (b *OGame) buildingLevel(celestialID CelestialID, id ID) (int64, error) {
+	if id.IsResourceBuilding() {
+		buildings, err := b.WithPriority(Normal).GetResourcesBuildings(celestialID)
+		return buildings.ByID(id), err
+	}
+	facilities, err := b.WithPriority(Normal).GetFacilities(celestialID)
+	return facilities.ByID(id), err
+}
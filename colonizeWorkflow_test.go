@@ -0,0 +1,137 @@
+package ogame
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowStore_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workflows")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "workflows.json")
+
+	s, err := NewWorkflowStore(filename)
+	assert.NoError(t, err)
+	w1, err := s.add(ColonizeWorkflow{Status: WorkflowRunning, Step: ColonizeStepSend})
+	assert.NoError(t, err)
+	w2, err := s.add(ColonizeWorkflow{Status: WorkflowRunning, Step: ColonizeStepSend})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), w1.ID)
+	assert.Equal(t, int64(2), w2.ID)
+	w1.Step = ColonizeStepBuild
+	w1.PlanetID = 123
+	assert.NoError(t, s.update(w1))
+
+	s, err = NewWorkflowStore(filename)
+	assert.NoError(t, err)
+	workflows := s.Workflows()
+	assert.Equal(t, 2, len(workflows))
+	assert.Equal(t, ColonizeStepBuild, workflows[0].Step)
+	assert.Equal(t, PlanetID(123), workflows[0].PlanetID)
+	w3, err := s.add(ColonizeWorkflow{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), w3.ID)
+}
+
+func TestWorkflowStore_UpdateCanceled(t *testing.T) {
+	s := newWorkflowStore()
+	w, _ := s.add(ColonizeWorkflow{Status: WorkflowRunning, Step: ColonizeStepSend})
+	s.workflows[w.ID].Status = WorkflowCanceled
+	w.Step = ColonizeStepColonize
+	assert.NoError(t, s.update(w))
+	curr, _ := s.get(w.ID)
+	assert.Equal(t, WorkflowCanceled, curr.Status)
+	assert.Equal(t, ColonizeStepSend, curr.Step)
+}
+
+func TestSetBuildTemplate(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	assert.Error(t, b.SetBuildTemplate("", nil))
+	assert.Error(t, b.SetBuildTemplate("colony", []BuildTemplateItem{{ID: LightFighterID, Level: 1}}))
+	assert.Error(t, b.SetBuildTemplate("colony", []BuildTemplateItem{{ID: MetalMineID, Level: 0}}))
+	items := []BuildTemplateItem{{ID: MetalMineID, Level: 5}, {ID: SolarPlantID, Level: 4}}
+	assert.NoError(t, b.SetBuildTemplate("colony", items))
+	assert.Equal(t, map[string][]BuildTemplateItem{"colony": items}, b.GetBuildTemplates())
+	b.RemoveBuildTemplate("colony")
+	assert.Equal(t, 0, len(b.GetBuildTemplates()))
+}
+
+func TestStartColonizeWorkflow(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.planets = []Planet{{ID: 1, Coordinate: Coordinate{1, 1, 8, PlanetType}}}
+	dest := Coordinate{1, 2, 8, PlanetType}
+
+	_, err := b.StartColonizeWorkflow(ColonizeParams{Origin: 2, Destination: dest})
+	assert.EqualError(t, err, "origin celestial not found")
+	_, err = b.StartColonizeWorkflow(ColonizeParams{Origin: 1, Destination: Coordinate{1, 2, 8, MoonType}})
+	assert.Error(t, err)
+	_, err = b.StartColonizeWorkflow(ColonizeParams{Origin: 1, Destination: dest, Template: "colony"})
+	assert.EqualError(t, err, "build template colony not found")
+	_, err = b.StartColonizeWorkflow(ColonizeParams{Origin: 1, Destination: dest, Transports: []ColonizeTransport{{}}})
+	assert.EqualError(t, err, "feeder celestial not found")
+
+	assert.NoError(t, b.SetBuildTemplate("colony", []BuildTemplateItem{{ID: MetalMineID, Level: 5}}))
+	w, err := b.StartColonizeWorkflow(ColonizeParams{Origin: 1, Destination: dest, Template: "colony"})
+	assert.NoError(t, err)
+	assert.Equal(t, WorkflowRunning, w.Status)
+	assert.Equal(t, ColonizeStepSend, w.Step)
+	assert.Equal(t, HundredPercent, w.Params.Speed)
+	assert.Equal(t, []BuildTemplateItem{{ID: MetalMineID, Level: 5}}, w.Template)
+
+	assert.NoError(t, b.CancelColonizeWorkflow(w.ID))
+	assert.EqualError(t, b.CancelColonizeWorkflow(w.ID), "workflow is canceled")
+	assert.EqualError(t, b.CancelColonizeWorkflow(42), "workflow not found")
+	assert.Equal(t, WorkflowCanceled, b.GetColonizeWorkflows()[0].Status)
+}
+
+func TestColonizeStep(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	w := ColonizeWorkflow{Step: ColonizeStepColonize, ArrivalTime: time.Now().Add(time.Hour)}
+	wait, err := b.colonizeStep(context.Background(), &w)
+	assert.NoError(t, err)
+	assert.True(t, wait > 59*time.Minute)
+
+	// No name, no transports, empty template
+	w = ColonizeWorkflow{Step: ColonizeStepRename}
+	for _, step := range []string{ColonizeStepTransport, ColonizeStepBuild, ColonizeStepDone} {
+		_, err = b.colonizeStep(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, step, w.Step)
+	}
+
+	w = ColonizeWorkflow{Step: ColonizeStepBuild, TransportArrival: time.Now().Add(time.Minute)}
+	wait, err = b.colonizeStep(context.Background(), &w)
+	assert.NoError(t, err)
+	assert.True(t, wait > 0)
+	assert.Equal(t, ColonizeStepBuild, w.Step)
+
+	w = ColonizeWorkflow{Step: "unknown"}
+	_, err = b.colonizeStep(context.Background(), &w)
+	assert.Error(t, err)
+}
+
+func TestColonizeTransportsInFlight(t *testing.T) {
+	feeder := Coordinate{1, 100, 8, PlanetType}
+	dest := Coordinate{1, 120, 4, PlanetType}
+	arrival := time.Now().Add(time.Hour)
+	fleets := []Fleet{
+		{Mission: Transport, Origin: feeder, Destination: dest, ArrivalTime: arrival},
+		{Mission: Transport, Origin: feeder, Destination: dest, ArrivalTime: arrival.Add(time.Minute)},
+		{Mission: Transport, Origin: feeder, Destination: dest, ReturnFlight: true, ArrivalTime: arrival.Add(time.Hour)},
+		{Mission: Transport, Origin: Coordinate{2, 1, 1, PlanetType}, Destination: dest, ArrivalTime: arrival.Add(time.Hour)},
+		{Mission: Park, Origin: feeder, Destination: dest, ArrivalTime: arrival.Add(time.Hour)},
+	}
+	nbr, last := colonizeTransportsInFlight(fleets, &feeder, dest)
+	assert.Equal(t, int64(2), nbr)
+	assert.Equal(t, arrival.Add(time.Minute), last)
+
+	nbr, _ = colonizeTransportsInFlight(fleets, nil, dest)
+	assert.Equal(t, int64(3), nbr)
+}
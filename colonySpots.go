@@ -0,0 +1,176 @@
+package ogame
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultColonyPositions positions searched by FindColonySpots when none are given, the biggest planets are found in
+// the middle of the systems, the positions 8 to 12 having the best production
+var DefaultColonyPositions = []int64{8, 9, 10, 11, 12, 4, 5, 6}
+
+// GalaxyRange systems from FromSystem to ToSystem (included) of a galaxy
+type GalaxyRange struct {
+	Galaxy     int64
+	FromSystem int64
+	ToSystem   int64
+}
+
+// ColonySpotsCriteria which free positions are returned by FindColonySpots
+type ColonySpotsCriteria struct {
+	Positions          []int64 // Wanted positions, by order of preference. DefaultColonyPositions if empty
+	Radius             int64   // Systems checked on each side of a spot for its neighborhood, 0 for the spot system only
+	MaxActiveNeighbors int64   // Reject the spots with more planets of active players around, 0 for no limit
+	SafeOnly           bool    // Reject the spots with a planet of an active player ranked better than the bot around
+}
+
+// ColonySpot free position and its neighborhood, the bot planets are not counted as neighbors
+type ColonySpot struct {
+	Coordinate        Coordinate
+	ActiveNeighbors   int64 // Planets of active players
+	StrongNeighbors   int64 // Planets of active players ranked better than the bot
+	InactiveNeighbors int64 // Planets of inactive players, future farming targets
+}
+
+// MaxPlanets returns the number of planets, home planet included, the player can have with the astrophysics level
+func MaxPlanets(astrophysics int64) int64 {
+	return 1 + (astrophysics+1)/2
+}
+
+// isColonizablePosition returns true if a planet can be colonized at the position with the astrophysics level,
+// the outer positions need astrophysics 4 (3 and 13), 6 (2 and 14) and 8 (1 and 15)
+func isColonizablePosition(position, astrophysics int64) bool {
+	switch position {
+	case 3, 13:
+		return astrophysics >= 4
+	case 2, 14:
+		return astrophysics >= 6
+	case 1, 15:
+		return astrophysics >= 8
+	}
+	return position >= 4 && position <= 12
+}
+
+// colonyNeighborhood counts the planets of the other players in the systems
+func colonyNeighborhood(spot *ColonySpot, systems []SystemInfos, botPlayerID, botRank int64) {
+	for _, system := range systems {
+		system.Each(func(p *PlanetInfos) {
+			if p == nil || p.Destroyed || p.Administrator || p.Player.ID == botPlayerID {
+				return
+			}
+			if p.Inactive {
+				spot.InactiveNeighbors++
+				return
+			}
+			if p.Vacation || p.Banned {
+				return
+			}
+			spot.ActiveNeighbors++
+			if p.Player.Rank > 0 && botRank > 0 && p.Player.Rank < botRank {
+				spot.StrongNeighbors++
+			}
+		})
+	}
+}
+
+// rankColonySpots sorts the spots, safest first: fewer strong neighbors, fewer active neighbors, preferred position,
+// then more inactive neighbors
+func rankColonySpots(spots []ColonySpot, positions []int64) {
+	preference := make(map[int64]int)
+	for i, pos := range positions {
+		preference[pos] = i
+	}
+	sort.SliceStable(spots, func(i, j int) bool {
+		a, b := spots[i], spots[j]
+		if a.StrongNeighbors != b.StrongNeighbors {
+			return a.StrongNeighbors < b.StrongNeighbors
+		}
+		if a.ActiveNeighbors != b.ActiveNeighbors {
+			return a.ActiveNeighbors < b.ActiveNeighbors
+		}
+		if pa, pb := preference[a.Coordinate.Position], preference[b.Coordinate.Position]; pa != pb {
+			return pa < pb
+		}
+		return a.InactiveNeighbors > b.InactiveNeighbors
+	})
+}
+
+// colonySpots returns the free positions of the systems of the range matching the criteria, ranked.
+// systems returns the galaxy information of the systems from..to, skipping the ones outside the universe.
+func colonySpots(galaxyRange GalaxyRange, criteria ColonySpotsCriteria, astrophysics, botPlayerID, botRank int64,
+	systems func(from, to int64) ([]SystemInfos, error)) ([]ColonySpot, error) {
+	positions := criteria.Positions
+	if len(positions) == 0 {
+		positions = DefaultColonyPositions
+	}
+	res := make([]ColonySpot, 0)
+	for system := galaxyRange.FromSystem; system <= galaxyRange.ToSystem; system++ {
+		neighborhood, err := systems(system-criteria.Radius, system+criteria.Radius)
+		if err != nil {
+			return res, err
+		}
+		var current *SystemInfos
+		for i := range neighborhood {
+			if neighborhood[i].System() == system {
+				current = &neighborhood[i]
+			}
+		}
+		if current == nil {
+			continue
+		}
+		for _, pos := range positions {
+			if !isColonizablePosition(pos, astrophysics) || current.Position(pos) != nil {
+				continue
+			}
+			spot := ColonySpot{Coordinate: Coordinate{Galaxy: galaxyRange.Galaxy, System: system, Position: pos, Type: PlanetType}}
+			colonyNeighborhood(&spot, neighborhood, botPlayerID, botRank)
+			if criteria.SafeOnly && spot.StrongNeighbors > 0 {
+				continue
+			}
+			if criteria.MaxActiveNeighbors > 0 && spot.ActiveNeighbors > criteria.MaxActiveNeighbors {
+				continue
+			}
+			res = append(res, spot)
+		}
+	}
+	rankColonySpots(res, positions)
+	return res, nil
+}
+
+// findColonySpots scans the systems of the range, and the neighborhood systems around them, for free positions to
+// colonize. The galaxy cache is used when enabled. Fails if the player already has the max planets of its
+// astrophysics level.
+func (b *OGame) findColonySpots(galaxyRange GalaxyRange, criteria ColonySpotsCriteria) ([]ColonySpot, error) {
+	if galaxyRange.FromSystem > galaxyRange.ToSystem {
+		return nil, fmt.Errorf("invalid system range %d-%d", galaxyRange.FromSystem, galaxyRange.ToSystem)
+	}
+	if criteria.Radius < 0 {
+		return nil, fmt.Errorf("invalid radius %d", criteria.Radius)
+	}
+	for _, pos := range criteria.Positions {
+		if pos < 1 || pos > 15 {
+			return nil, fmt.Errorf("invalid position %d", pos)
+		}
+	}
+	astrophysics := b.getCachedResearch().Astrophysics
+	if int64(len(b.GetCachedPlanets())) >= MaxPlanets(astrophysics) {
+		return nil, ErrMaxPlanetsReached
+	}
+	fetched := make(map[int64]SystemInfos)
+	systems := func(from, to int64) ([]SystemInfos, error) {
+		res := make([]SystemInfos, 0)
+		for system := MaxInt(from, 1); system <= MinInt(to, b.serverData.Systems); system++ {
+			infos, ok := fetched[system]
+			if !ok {
+				var err error
+				if infos, err = b.galaxyInfos(galaxyRange.Galaxy, system); err != nil {
+					return res, err
+				}
+				fetched[system] = infos
+			}
+			res = append(res, infos)
+		}
+		return res, nil
+	}
+	return colonySpots(galaxyRange, criteria, astrophysics, b.Player.PlayerID, b.Player.Rank, systems)
+}
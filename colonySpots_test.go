@@ -0,0 +1,98 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxPlanets(t *testing.T) {
+	assert.Equal(t, int64(1), MaxPlanets(0))
+	assert.Equal(t, int64(2), MaxPlanets(1))
+	assert.Equal(t, int64(2), MaxPlanets(2))
+	assert.Equal(t, int64(3), MaxPlanets(3))
+	assert.Equal(t, int64(9), MaxPlanets(15))
+}
+
+func TestIsColonizablePosition(t *testing.T) {
+	assert.True(t, isColonizablePosition(8, 1))
+	assert.False(t, isColonizablePosition(3, 3))
+	assert.True(t, isColonizablePosition(3, 4))
+	assert.False(t, isColonizablePosition(14, 5))
+	assert.True(t, isColonizablePosition(14, 6))
+	assert.False(t, isColonizablePosition(1, 7))
+	assert.True(t, isColonizablePosition(15, 8))
+	assert.False(t, isColonizablePosition(16, 8))
+}
+
+func newTestSystem(galaxy, system int64, planets ...*PlanetInfos) SystemInfos {
+	s := SystemInfos{galaxy: galaxy, system: system}
+	for _, p := range planets {
+		s.planets[p.Coordinate.Position-1] = p
+	}
+	return s
+}
+
+func newTestPlanetInfos(system, position, playerID, rank int64) *PlanetInfos {
+	p := &PlanetInfos{Coordinate: Coordinate{1, system, position, PlanetType}}
+	p.Player.ID = playerID
+	p.Player.Rank = rank
+	return p
+}
+
+func TestColonySpots(t *testing.T) {
+	inactive := newTestPlanetInfos(2, 4, 3, 500)
+	inactive.Inactive = true
+	systems := map[int64]SystemInfos{
+		1: newTestSystem(1, 1, newTestPlanetInfos(1, 8, 1, 10)),                                               // Strong player
+		2: newTestSystem(1, 2, newTestPlanetInfos(2, 8, 2, 200), newTestPlanetInfos(2, 9, 99, 100), inactive), // Weak player and the bot
+		3: newTestSystem(1, 3),
+	}
+	fetch := func(from, to int64) ([]SystemInfos, error) {
+		res := make([]SystemInfos, 0)
+		for s := MaxInt(from, 1); s <= MinInt(to, 3); s++ {
+			res = append(res, systems[s])
+		}
+		return res, nil
+	}
+	criteria := ColonySpotsCriteria{Positions: []int64{8, 9, 3}}
+	spots, err := colonySpots(GalaxyRange{1, 1, 3}, criteria, 2, 99, 100, fetch)
+	assert.NoError(t, err)
+	// Position 3 needs astrophysics 4, occupied positions are skipped, the bot planets are not neighbors
+	assert.Equal(t, []ColonySpot{
+		{Coordinate: Coordinate{1, 3, 8, PlanetType}},
+		{Coordinate: Coordinate{1, 3, 9, PlanetType}},
+		{Coordinate: Coordinate{1, 1, 9, PlanetType}, ActiveNeighbors: 1, StrongNeighbors: 1},
+	}, spots)
+
+	// The neighborhood of system 3 includes the planets of system 2
+	criteria.Radius = 1
+	criteria.SafeOnly = true
+	spots, err = colonySpots(GalaxyRange{1, 1, 3}, criteria, 4, 99, 100, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, []ColonySpot{
+		{Coordinate: Coordinate{1, 3, 8, PlanetType}, ActiveNeighbors: 1, InactiveNeighbors: 1},
+		{Coordinate: Coordinate{1, 3, 9, PlanetType}, ActiveNeighbors: 1, InactiveNeighbors: 1},
+		{Coordinate: Coordinate{1, 3, 3, PlanetType}, ActiveNeighbors: 1, InactiveNeighbors: 1},
+	}, spots)
+
+	criteria.MaxActiveNeighbors = 0
+	criteria.SafeOnly = false
+	criteria.Radius = 0
+	criteria.Positions = nil
+	spots, err = colonySpots(GalaxyRange{1, 3, 5}, criteria, 0, 99, 100, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, len(DefaultColonyPositions), len(spots)) // Systems 4 and 5 are outside the universe
+	assert.Equal(t, int64(8), spots[0].Coordinate.Position)
+}
+
+func TestFindColonySpots_MaxPlanets(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.researches = &Researches{Astrophysics: 0}
+	b.planets = []Planet{{ID: 1}}
+	_, err := b.findColonySpots(GalaxyRange{1, 1, 10}, ColonySpotsCriteria{})
+	assert.Equal(t, ErrMaxPlanetsReached, err)
+	assert.Equal(t, ErrCodeRequirementsNotMet, GetErrorCode(err))
+	_, err = b.findColonySpots(GalaxyRange{1, 10, 1}, ColonySpotsCriteria{})
+	assert.Error(t, err)
+}
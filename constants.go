@@ -1,6 +1,9 @@
 package ogame
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+)
 
 // MissionID represent a mission id
 type MissionID int
@@ -34,6 +37,11 @@ func (m MissionID) String() string {
 	}
 }
 
+// IsValid returns true if the mission is a known mission
+func (m MissionID) IsValid() bool {
+	return m.String() != strconv.FormatInt(int64(m), 10)
+}
+
 // Speed represent a fleet speed
 type Speed float64
 
@@ -96,10 +104,38 @@ func (s Speed) String() string {
 	case HundredPercent:
 		return "100%"
 	default:
+		if s > 0 && s < TenPercent && isSpeedStep(s, HalfPercent) {
+			return strconv.FormatFloat(math.Round(float64(s)*200)/20, 'f', -1, 64) + "%"
+		}
 		return strconv.FormatFloat(float64(s), 'f', 1, 64)
 	}
 }
 
+func isSpeedStep(s, step Speed) bool {
+	n := float64(s / step)
+	return math.Abs(n-math.Round(n)) < 1e-6
+}
+
+// IsValid returns either or not the speed can be selected by a player of the character class.
+// Speeds go by 10% steps, by 5% steps for the General class, and by 0.5% steps below 10% for the General class.
+func (s Speed) IsValid(class CharacterClass) bool {
+	if s <= 0 || s > HundredPercent {
+		return false
+	}
+	if !class.IsGeneral() {
+		return isSpeedStep(s, TenPercent)
+	}
+	if s < TenPercent {
+		return isSpeedStep(s, HalfPercent)
+	}
+	return isSpeedStep(s, FivePercent)
+}
+
+// Payload returns the speed as sent to the fleet dispatch
+func (s Speed) Payload() string {
+	return strconv.FormatFloat(math.Round(float64(s)*20)/20, 'f', -1, 64)
+}
+
 // CelestialType destination type might be planet/moon/debris
 type CelestialType int64
 
@@ -277,4 +313,14 @@ const (
 	SeventyFivePercent Speed = 7.5
 	EightyFivePercent  Speed = 8.5
 	NinetyFivePercent  Speed = 9.5
+	HalfPercent        Speed = 0.05 // General class only, speeds below 10% go by 0.5% steps
+)
+
+// Messages tabs
+const (
+	EspionageMessagesTab    int64 = 20
+	CombatReportMessagesTab int64 = 21
+	ExpeditionMessagesTab   int64 = 22
+	TransportMessagesTab    int64 = 23 // Unions/Transport
+	OtherMessagesTab        int64 = 24
 )
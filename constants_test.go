@@ -25,6 +25,32 @@ func TestConstants_Speed_String(t *testing.T) {
 	assert.Equal(t, "90%", Speed(9).String())
 	assert.Equal(t, "100%", Speed(10).String())
 	assert.Equal(t, "11.0", Speed(11).String())
+	assert.Equal(t, "0.5%", HalfPercent.String())
+	assert.Equal(t, "3.5%", Speed(0.35).String())
+	assert.Equal(t, "5%", FivePercent.String())
+}
+
+func TestConstants_Speed_IsValid(t *testing.T) {
+	assert.True(t, TenPercent.IsValid(Collector))
+	assert.True(t, HundredPercent.IsValid(NoClass))
+	assert.False(t, FiftyFivePercent.IsValid(Collector))
+	assert.False(t, FivePercent.IsValid(Discoverer))
+	assert.False(t, HalfPercent.IsValid(Collector))
+	assert.True(t, FiftyFivePercent.IsValid(General))
+	assert.True(t, FivePercent.IsValid(General))
+	assert.True(t, HalfPercent.IsValid(General))
+	assert.True(t, Speed(0.95).IsValid(General))
+	assert.False(t, Speed(1.05).IsValid(General))
+	assert.False(t, Speed(0.07).IsValid(General))
+	assert.False(t, Speed(0).IsValid(General))
+	assert.False(t, Speed(10.5).IsValid(General))
+}
+
+func TestConstants_Speed_Payload(t *testing.T) {
+	assert.Equal(t, "10", HundredPercent.Payload())
+	assert.Equal(t, "5.5", FiftyFivePercent.Payload())
+	assert.Equal(t, "0.05", HalfPercent.Payload())
+	assert.Equal(t, "0.35", Speed(0.35).Payload())
 }
 
 func TestConstants_MissionID_String(t *testing.T) {
@@ -0,0 +1,111 @@
+package ogame
+
+import (
+	"sync"
+	"time"
+)
+
+// Constructions watcher defaults
+const (
+	DefaultConstructionsWatchInterval = 10 * time.Minute
+	constructionsWatchMinInterval     = 10 * time.Second
+)
+
+// construction building or research being built and its end time
+type construction struct {
+	ID  ID
+	End time.Time
+}
+
+// constructionsSnapshot constructions being built on every celestial, and the research of the account
+type constructionsSnapshot struct {
+	Buildings map[CelestialID]construction
+	Research  construction
+}
+
+// sameConstruction returns true if curr is still the construction prev (the countdowns are off by a few seconds)
+func sameConstruction(prev, curr construction) bool {
+	d := curr.End.Sub(prev.End)
+	return curr.ID == prev.ID && d < time.Minute && d > -time.Minute
+}
+
+// diffConstructions returns the construction.finished events of the constructions of prev that ended by now and
+// are not being built anymore. A construction gone before its end was canceled, the celestials not in curr are skipped.
+func diffConstructions(prev, curr constructionsSnapshot, now time.Time) []Event {
+	events := make([]Event, 0)
+	for celestialID, p := range prev.Buildings {
+		c, ok := curr.Buildings[celestialID]
+		if !ok || p.ID == 0 || p.End.After(now) || sameConstruction(p, c) {
+			continue
+		}
+		events = append(events, NewConstructionFinishedEvent(celestialID, p.ID, p.End))
+	}
+	if p := prev.Research; p.ID != 0 && !p.End.After(now) && !sameConstruction(p, curr.Research) {
+		events = append(events, NewConstructionFinishedEvent(0, p.ID, p.End))
+	}
+	return events
+}
+
+// nextConstructionsPoll wakes up right after the next construction ends, interval at most
+func nextConstructionsPoll(s constructionsSnapshot, now time.Time, interval time.Duration) time.Duration {
+	ends := []construction{s.Research}
+	for _, c := range s.Buildings {
+		ends = append(ends, c)
+	}
+	for _, c := range ends {
+		if d := c.End.Sub(now) + time.Second; c.ID != 0 && c.End.After(now) && d < interval {
+			interval = d
+		}
+	}
+	if interval < constructionsWatchMinInterval {
+		interval = constructionsWatchMinInterval
+	}
+	return interval
+}
+
+// getConstructionsSnapshot reads the constructions being built on every celestial
+func (b *OGame) getConstructionsSnapshot() constructionsSnapshot {
+	now := time.Now()
+	s := constructionsSnapshot{Buildings: make(map[CelestialID]construction)}
+	for _, celestial := range b.GetCachedCelestials() {
+		buildingID, buildingCountdown, researchID, researchCountdown := b.WithPriority(Low).ConstructionsBeingBuilt(celestial.GetID())
+		s.Buildings[celestial.GetID()] = construction{ID: buildingID, End: now.Add(time.Duration(buildingCountdown) * time.Second)}
+		if researchID != 0 && s.Research.ID == 0 {
+			s.Research = construction{ID: researchID, End: now.Add(time.Duration(researchCountdown) * time.Second)}
+		}
+	}
+	return s
+}
+
+// StartConstructionsWatcher reads the constructions of every celestial every interval, and right after a
+// construction ends, until the returned function is called. A construction.finished event is emitted when a building
+// or research is done.
+func (b *OGame) StartConstructionsWatcher(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultConstructionsWatchInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		var prev *constructionsSnapshot
+		for {
+			wait := interval
+			if b.isEnabled() && b.IsLoggedIn() {
+				curr := b.getConstructionsSnapshot()
+				if prev != nil {
+					for _, e := range diffConstructions(*prev, curr, time.Now()) {
+						b.emitEvent(e)
+					}
+				}
+				prev = &curr
+				wait = nextConstructionsPoll(curr, time.Now(), interval)
+			}
+			select {
+			case <-time.After(wait):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
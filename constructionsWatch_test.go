@@ -0,0 +1,49 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConstructions(t *testing.T) {
+	now := time.Now()
+	prev := constructionsSnapshot{
+		Buildings: map[CelestialID]construction{
+			1: {ID: MetalMineID, End: now.Add(-time.Second)},
+			2: {ID: CrystalMineID, End: now.Add(time.Hour)},
+			3: {ID: ShipyardID, End: now.Add(-time.Second)},
+			4: {ID: RoboticsFactoryID, End: now.Add(-time.Second)},
+		},
+		Research: construction{ID: EnergyTechnologyID, End: now.Add(-time.Minute)},
+	}
+	curr := constructionsSnapshot{
+		Buildings: map[CelestialID]construction{
+			1: {ID: MetalMineID, End: now.Add(time.Hour)},   // Next level
+			2: {},                                           // Canceled
+			3: {ID: ShipyardID, End: now.Add(-time.Second)}, // Still reported
+		},
+	}
+	events := diffConstructions(prev, curr, now)
+	assert.Equal(t, 2, len(events))
+	finished := make(map[int64]int64)
+	for _, e := range events {
+		assert.Equal(t, ConstructionFinishedEvent, e.Type)
+		data := e.Data.(EventConstructionData)
+		finished[data.CelestialID] = data.Object.ID
+	}
+	assert.Equal(t, map[int64]int64{1: int64(MetalMineID), 0: int64(EnergyTechnologyID)}, finished)
+}
+
+func TestNextConstructionsPoll(t *testing.T) {
+	now := time.Now()
+	s := constructionsSnapshot{Buildings: map[CelestialID]construction{
+		1: {ID: MetalMineID, End: now.Add(time.Minute)},
+		2: {ID: CrystalMineID, End: now.Add(-time.Minute)},
+	}}
+	assert.Equal(t, time.Minute+time.Second, nextConstructionsPoll(s, now, time.Hour))
+	assert.Equal(t, 30*time.Second, nextConstructionsPoll(s, now, 30*time.Second))
+	s.Buildings[1] = construction{ID: MetalMineID, End: now.Add(time.Second)}
+	assert.Equal(t, constructionsWatchMinInterval, nextConstructionsPoll(s, now, time.Hour))
+}
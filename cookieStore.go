@@ -0,0 +1,184 @@
+package ogame
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	cookiejar "github.com/orirawlings/persistent-cookiejar"
+)
+
+// encryptedCookiesHeader prefix of the encrypted cookies files, files without it are plain-text cookie files
+var encryptedCookiesHeader = []byte("OGAMECK1")
+
+// ErrInvalidSecretKey returned when the encrypted cookies file cannot be decrypted with the secret key
+var ErrInvalidSecretKey = errors.New("cannot decrypt cookies file, invalid secret key")
+
+// cookieEntry cookie as persisted by the cookie jar
+type cookieEntry struct {
+	Name          string
+	Value         string
+	Domain        string
+	Path          string
+	Secure        bool
+	HttpOnly      bool
+	Persistent    bool
+	HostOnly      bool
+	Expires       time.Time
+	CanonicalHost string
+}
+
+// encryptedCookieStore persists the cookies, the bearer token (gf-token cookie) included, encrypted with AES-GCM
+type encryptedCookieStore struct {
+	sync.Mutex
+	filename string
+	aead     cipher.AEAD
+}
+
+// newEncryptedCookieStore returns a store of the cookies in filename, encrypted with a key derived from secretKey
+func newEncryptedCookieStore(filename, secretKey string) (*encryptedCookieStore, error) {
+	if secretKey == "" {
+		return nil, errors.New("secret key is empty")
+	}
+	if filename == "" {
+		filename = cookiejar.DefaultCookieFile()
+	}
+	key := sha256.Sum256([]byte(secretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedCookieStore{filename: filename, aead: aead}, nil
+}
+
+func (s *encryptedCookieStore) encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	res := append([]byte{}, encryptedCookiesHeader...)
+	res = append(res, nonce...)
+	return s.aead.Seal(res, nonce, plaintext, encryptedCookiesHeader), nil
+}
+
+func (s *encryptedCookieStore) decrypt(data []byte) ([]byte, error) {
+	data = data[len(encryptedCookiesHeader):]
+	if len(data) < s.aead.NonceSize() {
+		return nil, ErrInvalidSecretKey
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, encryptedCookiesHeader)
+	if err != nil {
+		return nil, ErrInvalidSecretKey
+	}
+	return plaintext, nil
+}
+
+// load reads the cookies file into the jar. A plain-text cookies file is loaded as is, it is encrypted by the next save.
+func (s *encryptedCookieStore) load(jar *cookiejar.Jar) error {
+	s.Lock()
+	defer s.Unlock()
+	data, err := ioutil.ReadFile(s.filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(data, encryptedCookiesHeader) {
+		if data, err = s.decrypt(data); err != nil {
+			return err
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var entries []cookieEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Persistent && e.Expires.Before(time.Now()) {
+			continue
+		}
+		cookie := &http.Cookie{Name: e.Name, Value: e.Value, Path: e.Path, Secure: e.Secure, HttpOnly: e.HttpOnly}
+		if !e.HostOnly {
+			cookie.Domain = e.Domain
+		}
+		if e.Persistent {
+			cookie.Expires = e.Expires
+		}
+		jar.SetCookies(&url.URL{Scheme: "https", Host: e.CanonicalHost, Path: e.Path}, []*http.Cookie{cookie})
+	}
+	return nil
+}
+
+// save encrypts the cookies of the jar into the cookies file
+func (s *encryptedCookieStore) save(jar *cookiejar.Jar) error {
+	s.Lock()
+	defer s.Unlock()
+	plaintext, err := jar.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	data, err := s.encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.filename), 0700); err != nil {
+		return err
+	}
+	tmp := s.filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.filename)
+}
+
+// newEncryptedCookiesClient returns a client whose cookies are loaded from the encrypted cookies file
+func newEncryptedCookiesClient(store *encryptedCookieStore) (*OGameClient, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{NoPersist: true, PersistSessionCookies: true})
+	if err != nil {
+		return nil, err
+	}
+	if err := store.load(jar); err != nil {
+		return nil, err
+	}
+	// Ensure we remove any cookies that would set the mobile view
+	for _, c := range jar.AllCookies() {
+		if c.Name == "device" {
+			jar.RemoveCookie(c)
+		}
+	}
+	client := NewOGameClient()
+	client.Jar = jar
+	client.UserAgent = defaultUserAgent
+	return client, nil
+}
+
+// saveCookies persists the cookies of the bot, encrypted if a secret key is set
+func (b *OGame) saveCookies() error {
+	jar, ok := b.Client.Jar.(*cookiejar.Jar)
+	if !ok {
+		return nil
+	}
+	if b.cookieStore != nil {
+		return b.cookieStore.save(jar)
+	}
+	return jar.Save()
+}
@@ -0,0 +1,67 @@
+package ogame
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cookiejar "github.com/orirawlings/persistent-cookiejar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedCookieStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ogame")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cookies")
+	store, err := newEncryptedCookieStore(filename, "secret")
+	assert.NoError(t, err)
+
+	jar, _ := cookiejar.New(&cookiejar.Options{NoPersist: true, PersistSessionCookies: true})
+	u, _ := url.Parse("https://gameforge.com")
+	jar.SetCookies(u, []*http.Cookie{{Name: gfTokenCookieName, Value: "token123", Path: "/", Domain: ".gameforge.com", Expires: time.Now().Add(time.Hour)}})
+	assert.NoError(t, store.save(jar))
+
+	by, _ := ioutil.ReadFile(filename)
+	assert.True(t, bytes.HasPrefix(by, encryptedCookiesHeader))
+	assert.False(t, bytes.Contains(by, []byte("token123")))
+
+	client, err := newEncryptedCookiesClient(store)
+	assert.NoError(t, err)
+	cookies := client.Jar.Cookies(&url.URL{Scheme: "https", Host: "lobby.ogame.gameforge.com", Path: "/"})
+	assert.Equal(t, 1, len(cookies))
+	assert.Equal(t, "token123", cookies[0].Value)
+
+	wrongStore, _ := newEncryptedCookieStore(filename, "wrong")
+	_, err = newEncryptedCookiesClient(wrongStore)
+	assert.Equal(t, ErrInvalidSecretKey, err)
+}
+
+func TestEncryptedCookieStore_plainTextFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ogame")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cookies")
+	plainJar, _ := cookiejar.New(&cookiejar.Options{Filename: filename, PersistSessionCookies: true})
+	u, _ := url.Parse("https://gameforge.com")
+	plainJar.SetCookies(u, []*http.Cookie{{Name: gfTokenCookieName, Value: "token123", Path: "/", Domain: ".gameforge.com", Expires: time.Now().Add(time.Hour)}})
+	assert.NoError(t, plainJar.Save())
+
+	store, _ := newEncryptedCookieStore(filename, "secret")
+	client, err := newEncryptedCookiesClient(store)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(client.Jar.(*cookiejar.Jar).AllCookies()))
+
+	// The plain-text file is encrypted by the next save
+	assert.NoError(t, store.save(client.Jar.(*cookiejar.Jar)))
+	by, _ := ioutil.ReadFile(filename)
+	assert.True(t, bytes.HasPrefix(by, encryptedCookiesHeader))
+}
+
+func TestNewEncryptedCookieStore_emptyKey(t *testing.T) {
+	_, err := newEncryptedCookieStore("cookies", "")
+	assert.Error(t, err)
+}
@@ -1,5 +1,15 @@
 package ogame
 
+import "math"
+
+// Crawler production boost constants
+const (
+	CrawlerProductionBonus    = 0.0002 // Mines production bonus per crawler, 50% more for collectors
+	CrawlerMaxProductionBonus = 0.5
+	CrawlerEnergyConsumption  = 50 // Energy consumed per crawler at 100%
+	CrawlersPerMineLevel      = 8
+)
+
 type crawler struct {
 	BaseShip
 }
@@ -22,3 +32,36 @@ func newCrawler() *crawler {
 	c.Requirements = map[ID]int64{ShipyardID: 5, CombustionDriveID: 4, ArmourTechnologyID: 4, LaserTechnologyID: 4}
 	return c
 }
+
+// MaxCrawlers returns the number of crawlers that can boost the mines, 8 per mine level, 10% more with the geologist
+func (c *crawler) MaxCrawlers(buildings ResourcesBuildings, geologist bool) int64 {
+	nbr := (buildings.MetalMine + buildings.CrystalMine + buildings.DeuteriumSynthesizer) * CrawlersPerMineLevel
+	if geologist {
+		nbr = int64(float64(nbr) * 1.1)
+	}
+	return nbr
+}
+
+// crawlerSetting clamps the crawler setting, only collectors can overload the crawlers over 100%
+func crawlerSetting(setting int64, class CharacterClass) int64 {
+	max := MaxCrawlerSetting
+	if class.IsCollector() {
+		max = MaxCollectorCrawlerSetting
+	}
+	return MaxInt(MinInt(setting, max), 0)
+}
+
+// ProductionBonus returns the mines production bonus ratio (eg: 0.25 for +25%) given by nbr crawlers at setting percent
+func (c *crawler) ProductionBonus(nbr, setting int64, buildings ResourcesBuildings, class CharacterClass, geologist bool) float64 {
+	nbr = MinInt(nbr, c.MaxCrawlers(buildings, geologist))
+	bonus := CrawlerProductionBonus
+	if class.IsCollector() {
+		bonus *= 1.5
+	}
+	return math.Min(float64(nbr)*bonus*float64(crawlerSetting(setting, class))/100, CrawlerMaxProductionBonus)
+}
+
+// EnergyConsumption returns the energy consumed by nbr crawlers at setting percent
+func (c *crawler) EnergyConsumption(nbr, setting int64, class CharacterClass) int64 {
+	return int64(math.Ceil(float64(nbr*CrawlerEnergyConsumption) * float64(crawlerSetting(setting, class)) / 100))
+}
@@ -0,0 +1,58 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawler_MaxCrawlers(t *testing.T) {
+	c := newCrawler()
+	buildings := ResourcesBuildings{MetalMine: 20, CrystalMine: 15, DeuteriumSynthesizer: 10}
+	assert.Equal(t, int64(360), c.MaxCrawlers(buildings, false))
+	assert.Equal(t, int64(396), c.MaxCrawlers(buildings, true))
+}
+
+func TestCrawler_ProductionBonus(t *testing.T) {
+	c := newCrawler()
+	buildings := ResourcesBuildings{MetalMine: 20, CrystalMine: 15, DeuteriumSynthesizer: 10}
+	assert.InDelta(t, 0.02, c.ProductionBonus(100, 100, buildings, NoClass, false), 0.00001)
+	assert.InDelta(t, 0.01, c.ProductionBonus(100, 50, buildings, NoClass, false), 0.00001)
+	// Only collectors can overload the crawlers
+	assert.InDelta(t, 0.02, c.ProductionBonus(100, 150, buildings, NoClass, false), 0.00001)
+	assert.InDelta(t, 0.045, c.ProductionBonus(100, 150, buildings, Collector, false), 0.00001)
+	// Crawlers over the mines limit are useless
+	assert.InDelta(t, 0.072, c.ProductionBonus(1000, 100, buildings, NoClass, false), 0.00001)
+	assert.InDelta(t, 0.5, c.ProductionBonus(10000, 150, ResourcesBuildings{MetalMine: 500}, Collector, false), 0.00001)
+}
+
+func TestCrawler_EnergyConsumption(t *testing.T) {
+	c := newCrawler()
+	assert.Equal(t, int64(5000), c.EnergyConsumption(100, 100, NoClass))
+	assert.Equal(t, int64(5000), c.EnergyConsumption(100, 150, NoClass))
+	assert.Equal(t, int64(7500), c.EnergyConsumption(100, 150, Collector))
+	assert.Equal(t, int64(0), c.EnergyConsumption(100, 0, Collector))
+}
+
+func TestCombatSimulator_HarvestDebris(t *testing.T) {
+	attacker := newEntity()
+	attacker.Reaper = 2
+	attacker.LightFighter = 1
+	attacker.reset()
+	attacker.Units = make([]CombatUnit, attacker.TotalUnits+1)
+	attacker.init()
+	cs := newCombatSimulator(attacker, newEntity())
+	cs.Debris = price{Metal: 60000, Crystal: 40000}
+	cs.harvestDebris()
+	assert.Equal(t, price{Metal: 12000, Crystal: 8000}, cs.ReaperHarvest)
+	assert.Equal(t, price{Metal: 48000, Crystal: 32000}, cs.Debris)
+
+	// Limited by the reapers cargo
+	cs.Debris = price{Metal: 600000, Crystal: 400000}
+	cs.harvestDebris()
+	assert.Equal(t, price{Metal: 12000, Crystal: 8000}, cs.ReaperHarvest)
+	cs.Attacker.Hyperspace = 10
+	cs.Debris = price{Metal: 600000, Crystal: 400000}
+	cs.harvestDebris()
+	assert.Equal(t, price{Metal: 18000, Crystal: 12000}, cs.ReaperHarvest)
+}
@@ -0,0 +1,148 @@
+package ogame
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule schedule of a standard 5 fields cron expression: minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	expr       string
+	minute     uint64
+	hour       uint64
+	dom        uint64
+	month      uint64
+	dow        uint64
+	domStarred bool // Day of month is *, only the day of week restricts the days
+	dowStarred bool // Day of week is *, only the day of month restricts the days
+}
+
+// cronField bounds of a cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are sunday
+}
+
+// cronMaxLookahead a schedule that does not fire in this period never fires, eg: "0 0 30 2 *"
+const cronMaxLookahead = 5 * 366 * 24 * time.Hour
+
+// ParseCronSchedule parses a cron expression of 5 fields, eg: "0 */2 * * *" every two hours.
+// Every field accepts *, values, ranges (1-5), steps (*/15, 10-50/20) and lists of them (1,15,30).
+// Like cron, a day matches if either the day of month or the day of week matches when both are restricted.
+func ParseCronSchedule(expr string) (CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return CronSchedule{}, errors.New("cron expression must have 5 fields: " + expr)
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i]); err != nil {
+			return CronSchedule{}, err
+		}
+	}
+	// Sunday is both 0 and 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return CronSchedule{
+		expr:       expr,
+		minute:     bits[0],
+		hour:       bits[1],
+		dom:        bits[2],
+		month:      bits[3],
+		dow:        bits[4],
+		domStarred: strings.HasPrefix(fields[2], "*"),
+		dowStarred: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField returns the bit set of the values of a cron field
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		invalid := errors.New("invalid cron " + f.name + " " + part)
+		rng, step := part, 1
+		if idx := strings.Index(part, "/"); idx != -1 {
+			var err error
+			rng = part[:idx]
+			if step, err = strconv.Atoi(part[idx+1:]); err != nil || step <= 0 {
+				return 0, invalid
+			}
+		}
+		start, end := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, invalid
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, invalid
+				}
+			} else if step > 1 {
+				end = f.max // 5/15 means from 5 every 15
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, invalid
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// String returns the cron expression
+func (s CronSchedule) String() string {
+	return s.expr
+}
+
+func (s CronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStarred || s.dowStarred {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first time strictly after t matching the schedule, in the location of t.
+// Zero if the schedule never fires.
+func (s CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronMaxLookahead)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
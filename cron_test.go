@@ -0,0 +1,71 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	} {
+		_, err := ParseCronSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestParseCronSchedule(t *testing.T) {
+	s, err := ParseCronSchedule("*/15 1-3 1,15 * *")
+	assert.NoError(t, err)
+	assert.Equal(t, "*/15 1-3 1,15 * *", s.String())
+	assert.Equal(t, uint64(1|1<<15|1<<30|1<<45), s.minute)
+	assert.Equal(t, uint64(1<<1|1<<2|1<<3), s.hour)
+	assert.Equal(t, uint64(1<<1|1<<15), s.dom)
+
+	s, _ = ParseCronSchedule("5/20 10-20/5 * * 7")
+	assert.Equal(t, uint64(1<<5|1<<25|1<<45), s.minute)
+	assert.Equal(t, uint64(1<<10|1<<15|1<<20), s.hour)
+	assert.Equal(t, uint64(1|1<<7), s.dow)
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	date := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2020, month, day, hour, min, 0, 0, time.UTC)
+	}
+	next := func(expr string, from time.Time) time.Time {
+		s, err := ParseCronSchedule(expr)
+		assert.NoError(t, err)
+		return s.Next(from)
+	}
+	// Strictly after
+	assert.Equal(t, date(1, 1, 0, 1), next("* * * * *", date(1, 1, 0, 0)))
+	assert.Equal(t, date(1, 1, 0, 1), next("* * * * *", date(1, 1, 0, 0).Add(30*time.Second)))
+	// Every two hours
+	assert.Equal(t, date(1, 1, 2, 0), next("0 */2 * * *", date(1, 1, 0, 0)))
+	assert.Equal(t, date(1, 1, 4, 0), next("0 */2 * * *", date(1, 1, 3, 59)))
+	assert.Equal(t, date(1, 2, 0, 0), next("0 */2 * * *", date(1, 1, 22, 0)))
+	// Day of month, 2020-02-29 exists
+	assert.Equal(t, date(2, 29, 12, 30), next("30 12 29 2 *", date(1, 1, 0, 0)))
+	// Day of week, 2020-01-01 is a wednesday
+	assert.Equal(t, date(1, 5, 0, 0), next("0 0 * * 0", date(1, 1, 0, 0)))
+	assert.Equal(t, date(1, 5, 0, 0), next("0 0 * * 7", date(1, 1, 0, 0)))
+	assert.Equal(t, date(1, 6, 8, 0), next("0 8 * * 1-5", date(1, 3, 9, 0)))
+	// Day of month or day of week when both are restricted
+	assert.Equal(t, date(1, 3, 0, 0), next("0 0 15 * 5", date(1, 1, 0, 0)))
+	assert.Equal(t, date(1, 15, 0, 0), next("0 0 15 * 5", date(1, 10, 0, 0)))
+	// Never
+	assert.True(t, next("0 0 30 2 *", date(1, 1, 0, 0)).IsZero())
+}
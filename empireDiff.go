@@ -0,0 +1,138 @@
+package ogame
+
+import "time"
+
+// EmpireSnapshot state of all celestials at a given time
+type EmpireSnapshot struct {
+	Time       time.Time
+	Celestials []EmpireCelestial
+}
+
+// CelestialDiff changes on a celestial between two snapshots.
+// Maps only contain the objects for which the level/quantity changed, values are signed deltas.
+type CelestialDiff struct {
+	ID         CelestialID
+	Name       string
+	Coordinate Coordinate
+	Added      bool // Celestial only exists in the second snapshot
+	Removed    bool // Celestial only exists in the first snapshot
+	Buildings  map[ID]int64
+	Ships      map[ID]int64
+	Defenses   map[ID]int64
+	Resources  Resources // Signed delta
+}
+
+// IsEmpty returns true if nothing changed on the celestial
+func (d CelestialDiff) IsEmpty() bool {
+	return !d.Added && !d.Removed && len(d.Buildings) == 0 && len(d.Ships) == 0 && len(d.Defenses) == 0 &&
+		d.Resources == Resources{}
+}
+
+// EmpireDiff changes between two empire snapshots
+type EmpireDiff struct {
+	From       time.Time
+	To         time.Time
+	Researches map[ID]int64
+	Celestials []CelestialDiff
+}
+
+// Diff computes what changed between snapshot a and snapshot b (what was built, ships gained/lost, resources delta)
+func Diff(a, b EmpireSnapshot) EmpireDiff {
+	res := EmpireDiff{From: a.Time, To: b.Time, Researches: make(map[ID]int64)}
+
+	var researchesA, researchesB Researches
+	if len(a.Celestials) > 0 {
+		researchesA = a.Celestials[0].Researches
+	}
+	if len(b.Celestials) > 0 {
+		researchesB = b.Celestials[0].Researches
+	}
+	for _, tech := range Technologies {
+		if delta := researchesB.ByID(tech.GetID()) - researchesA.ByID(tech.GetID()); delta != 0 {
+			res.Researches[tech.GetID()] = delta
+		}
+	}
+
+	celestialsA := make(map[CelestialID]EmpireCelestial)
+	for _, c := range a.Celestials {
+		celestialsA[c.ID] = c
+	}
+	seen := make(map[CelestialID]bool)
+	for _, cb := range b.Celestials {
+		seen[cb.ID] = true
+		ca, found := celestialsA[cb.ID]
+		d := diffCelestial(ca, cb)
+		d.Added = !found
+		if !d.IsEmpty() {
+			res.Celestials = append(res.Celestials, d)
+		}
+	}
+	for _, ca := range a.Celestials {
+		if !seen[ca.ID] {
+			d := diffCelestial(ca, EmpireCelestial{})
+			d.ID, d.Name, d.Coordinate = ca.ID, ca.Name, ca.Coordinate
+			d.Removed = true
+			res.Celestials = append(res.Celestials, d)
+		}
+	}
+	return res
+}
+
+func diffCelestial(a, b EmpireCelestial) CelestialDiff {
+	d := CelestialDiff{
+		ID:         b.ID,
+		Name:       b.Name,
+		Coordinate: b.Coordinate,
+		Buildings:  make(map[ID]int64),
+		Ships:      make(map[ID]int64),
+		Defenses:   make(map[ID]int64),
+		Resources: Resources{
+			Metal:     b.Resources.Metal - a.Resources.Metal,
+			Crystal:   b.Resources.Crystal - a.Resources.Crystal,
+			Deuterium: b.Resources.Deuterium - a.Resources.Deuterium,
+			Energy:    b.Resources.Energy - a.Resources.Energy,
+		},
+	}
+	for _, building := range Buildings {
+		id := building.GetID()
+		if id == SolarSatelliteID {
+			continue // Counted with the ships
+		}
+		levelA := a.Supplies.ByID(id) + a.Facilities.ByID(id)
+		levelB := b.Supplies.ByID(id) + b.Facilities.ByID(id)
+		if delta := levelB - levelA; delta != 0 {
+			d.Buildings[id] = delta
+		}
+	}
+	for _, ship := range Ships {
+		if delta := b.Ships.ByID(ship.GetID()) - a.Ships.ByID(ship.GetID()); delta != 0 {
+			d.Ships[ship.GetID()] = delta
+		}
+	}
+	for _, defense := range Defenses {
+		if delta := b.Defenses.ByID(defense.GetID()) - a.Defenses.ByID(defense.GetID()); delta != 0 {
+			d.Defenses[defense.GetID()] = delta
+		}
+	}
+	return d
+}
+
+func (b *OGame) getEmpireSnapshot() (EmpireSnapshot, error) {
+	res := EmpireSnapshot{Time: time.Now()}
+	types := []CelestialType{PlanetType}
+	if len(b.getCachedMoons()) > 0 {
+		types = append(types, MoonType)
+	}
+	celestials := make([][]EmpireCelestial, len(types))
+	for _, i := range b.HumanizedOrder(len(types)) {
+		empire, err := b.getEmpire(types[i])
+		if err != nil {
+			return res, err
+		}
+		celestials[i] = empire
+	}
+	for _, c := range celestials {
+		res.Celestials = append(res.Celestials, c...)
+	}
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(8 * time.Hour)
+	a := EmpireSnapshot{Time: t1, Celestials: []EmpireCelestial{
+		{ID: 1, Supplies: ResourcesBuildings{MetalMine: 10}, Ships: ShipsInfos{SmallCargo: 5},
+			Resources: Resources{Metal: 1000, Crystal: 500}, Researches: Researches{EnergyTechnology: 3}},
+		{ID: 2, Facilities: Facilities{Shipyard: 2}},
+	}}
+	b := EmpireSnapshot{Time: t2, Celestials: []EmpireCelestial{
+		{ID: 1, Supplies: ResourcesBuildings{MetalMine: 11}, Ships: ShipsInfos{SmallCargo: 2},
+			Resources: Resources{Metal: 400, Crystal: 700}, Researches: Researches{EnergyTechnology: 4}},
+		{ID: 3, Defenses: DefensesInfos{RocketLauncher: 10}},
+	}}
+	diff := Diff(a, b)
+	assert.Equal(t, t1, diff.From)
+	assert.Equal(t, t2, diff.To)
+	assert.Equal(t, map[ID]int64{EnergyTechnologyID: 1}, diff.Researches)
+	assert.Equal(t, 3, len(diff.Celestials))
+
+	assert.Equal(t, CelestialID(1), diff.Celestials[0].ID)
+	assert.Equal(t, map[ID]int64{MetalMineID: 1}, diff.Celestials[0].Buildings)
+	assert.Equal(t, map[ID]int64{SmallCargoID: -3}, diff.Celestials[0].Ships)
+	assert.Equal(t, Resources{Metal: -600, Crystal: 200}, diff.Celestials[0].Resources)
+
+	assert.Equal(t, CelestialID(3), diff.Celestials[1].ID)
+	assert.True(t, diff.Celestials[1].Added)
+	assert.Equal(t, map[ID]int64{RocketLauncherID: 10}, diff.Celestials[1].Defenses)
+
+	assert.Equal(t, CelestialID(2), diff.Celestials[2].ID)
+	assert.True(t, diff.Celestials[2].Removed)
+	assert.Equal(t, map[ID]int64{ShipyardID: -2}, diff.Celestials[2].Buildings)
+
+	assert.Equal(t, 0, len(Diff(a, a).Celestials))
+}
@@ -0,0 +1,152 @@
+package ogame
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// EmpireExport complete snapshot of the empire, see ExportEmpire and ImportEmpire
+type EmpireExport struct {
+	Time       time.Time
+	Player     UserInfos
+	Planets    []Planet // Planets and their moons, as cached by the bot
+	Researches Researches
+	Celestials []EmpireCelestial // Resources, buildings, ships and defenses of every planet and moon
+	Fleets     []Fleet
+}
+
+// exportColumnIDs ids of the levels and quantities columns of the celestials CSV
+func exportColumnIDs() []ID {
+	ids := make([]ID, 0)
+	for _, b := range Buildings {
+		if b.GetID() != SolarSatelliteID {
+			ids = append(ids, b.GetID())
+		}
+	}
+	for _, s := range Ships {
+		ids = append(ids, s.GetID())
+	}
+	for _, d := range Defenses {
+		ids = append(ids, d.GetID())
+	}
+	return ids
+}
+
+// WriteCelestialsCSV writes one row per planet and moon: coordinate, resources, then every building level and
+// ships/defenses quantity. The researches are the same on every row.
+func (e EmpireExport) WriteCelestialsCSV(w io.Writer) error {
+	ids := exportColumnIDs()
+	header := []string{"ID", "Name", "Type", "Coordinate", "Diameter", "FieldsBuilt", "FieldsTotal", "TemperatureMin",
+		"TemperatureMax", "Metal", "Crystal", "Deuterium", "Energy", "Darkmatter"}
+	for _, id := range ids {
+		header = append(header, id.String())
+	}
+	for _, t := range Technologies {
+		header = append(header, t.GetID().String())
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	i64 := func(v int64) string { return strconv.FormatInt(v, 10) }
+	for _, c := range e.Celestials {
+		row := []string{i64(int64(c.ID)), c.Name, c.Type.String(), c.Coordinate.String(), i64(c.Diameter),
+			i64(c.Fields.Built), i64(c.Fields.Total), i64(c.Temperature.Min), i64(c.Temperature.Max),
+			i64(c.Resources.Metal), i64(c.Resources.Crystal), i64(c.Resources.Deuterium), i64(c.Resources.Energy),
+			i64(c.Resources.Darkmatter)}
+		for _, id := range ids {
+			var v int64
+			switch {
+			case id.IsResourceBuilding():
+				v = c.Supplies.ByID(id)
+			case id.IsFacility():
+				v = c.Facilities.ByID(id)
+			case id.IsShip():
+				v = c.Ships.ByID(id)
+			case id.IsDefense():
+				v = c.Defenses.ByID(id)
+			}
+			row = append(row, i64(v))
+		}
+		for _, t := range Technologies {
+			row = append(row, i64(e.Researches.ByID(t.GetID())))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFleetsCSV writes one row per fleet: mission, coordinates, times, resources, then every ship quantity
+func (e EmpireExport) WriteFleetsCSV(w io.Writer) error {
+	header := []string{"ID", "Mission", "ReturnFlight", "Origin", "Destination", "StartTime", "ArrivalTime", "BackTime",
+		"Metal", "Crystal", "Deuterium"}
+	for _, s := range Ships {
+		header = append(header, s.GetID().String())
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	i64 := func(v int64) string { return strconv.FormatInt(v, 10) }
+	for _, f := range e.Fleets {
+		row := []string{i64(int64(f.ID)), f.Mission.String(), strconv.FormatBool(f.ReturnFlight), f.Origin.String(),
+			f.Destination.String(), f.StartTime.Format(time.RFC3339), f.ArrivalTime.Format(time.RFC3339),
+			f.BackTime.Format(time.RFC3339), i64(f.Resources.Metal), i64(f.Resources.Crystal), i64(f.Resources.Deuterium)}
+		for _, s := range Ships {
+			row = append(row, i64(f.Ships.ByID(s.GetID())))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportEmpire builds the snapshot of the empire, see syncEmpire
+func (b *OGame) exportEmpire() (EmpireExport, error) {
+	snapshot, err := b.syncEmpire()
+	if err != nil {
+		return EmpireExport{}, err
+	}
+	fleets, _ := b.getFleets()
+	res := EmpireExport{
+		Time:       snapshot.Time,
+		Player:     b.Player,
+		Planets:    b.GetCachedPlanets(),
+		Celestials: snapshot.Celestials,
+		Fleets:     fleets,
+	}
+	if b.researches != nil {
+		res.Researches = *b.researches
+	}
+	return res, nil
+}
+
+// importEmpire restores the cached planets, researches, player and empire snapshot of an export
+func (b *OGame) importEmpire(e EmpireExport) {
+	planets := make([]Planet, len(e.Planets))
+	for i, p := range e.Planets {
+		p.ogame = b
+		if p.Moon != nil {
+			moon := *p.Moon
+			moon.ogame = b
+			p.Moon = &moon
+		}
+		planets[i] = p
+	}
+	b.planetsMu.Lock()
+	b.planets = planets
+	b.planetsCachedAt = e.Time
+	b.planetsMu.Unlock()
+	researches := e.Researches
+	b.researches = &researches
+	b.researchesCachedAt = e.Time
+	b.Player = e.Player
+	b.cacheEmpireSnapshot(EmpireSnapshot{Time: e.Time, Celestials: e.Celestials})
+}
@@ -0,0 +1,80 @@
+package ogame
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmpireExport_WriteCelestialsCSV(t *testing.T) {
+	e := EmpireExport{
+		Researches: Researches{EnergyTechnology: 8},
+		Celestials: []EmpireCelestial{{
+			ID:         123,
+			Name:       "Homeworld",
+			Type:       PlanetType,
+			Coordinate: Coordinate{Galaxy: 1, System: 2, Position: 3, Type: PlanetType},
+			Resources:  Resources{Metal: 1000},
+			Supplies:   ResourcesBuildings{MetalMine: 20},
+			Facilities: Facilities{Shipyard: 6},
+			Ships:      ShipsInfos{SmallCargo: 10, SolarSatellite: 7},
+			Defenses:   DefensesInfos{RocketLauncher: 50},
+		}},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, e.WriteCelestialsCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	header, row := strings.Split(lines[0], ","), strings.Split(lines[1], ",")
+	assert.Equal(t, len(header), len(row))
+	values := make(map[string]string)
+	for i, h := range header {
+		values[h] = row[i]
+	}
+	assert.Equal(t, "Homeworld", values["Name"])
+	assert.Equal(t, "1000", values["Metal"])
+	assert.Equal(t, "20", values[MetalMineID.String()])
+	assert.Equal(t, "6", values[ShipyardID.String()])
+	assert.Equal(t, "10", values[SmallCargoID.String()])
+	assert.Equal(t, "7", values[SolarSatelliteID.String()])
+	assert.Equal(t, "50", values[RocketLauncherID.String()])
+	assert.Equal(t, "8", values[EnergyTechnologyID.String()])
+}
+
+func TestEmpireExport_WriteFleetsCSV(t *testing.T) {
+	e := EmpireExport{Fleets: []Fleet{{ID: 5, Mission: Transport, Ships: ShipsInfos{LargeCargo: 3}, Resources: Resources{Crystal: 9}}}}
+	var buf bytes.Buffer
+	assert.NoError(t, e.WriteFleetsCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[1], "5,"+Transport.String()+",false,"))
+}
+
+func TestImportEmpire(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	now := time.Now()
+	b.ImportEmpire(EmpireExport{
+		Time:       now,
+		Player:     UserInfos{PlayerName: "Bob"},
+		Planets:    []Planet{{ID: 1, Name: "P1", Moon: &Moon{ID: 2, Name: "M1"}}},
+		Researches: Researches{ComputerTechnology: 4},
+		Celestials: []EmpireCelestial{{ID: 1, Supplies: ResourcesBuildings{MetalMine: 20}, Ships: ShipsInfos{SmallCargo: 5}}},
+	})
+	planets := b.GetCachedPlanets()
+	assert.Equal(t, 1, len(planets))
+	assert.Equal(t, "P1", planets[0].Name)
+	assert.Equal(t, 1, len(b.GetCachedMoons()))
+	assert.Equal(t, int64(4), b.GetCachedResearch().ComputerTechnology)
+	assert.Equal(t, "Bob", b.Player.PlayerName)
+	empire, ok := b.GetCachedEmpire()
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(empire.Celestials))
+	assert.Equal(t, int64(20), empire.Celestials[0].Supplies.MetalMine)
+	assert.Equal(t, int64(5), empire.Celestials[0].Ships.SmallCargo)
+	assert.NoError(t, b.invalidateCache(EmpireCache))
+	_, ok = b.GetCachedEmpire()
+	assert.False(t, ok)
+}
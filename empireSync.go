@@ -0,0 +1,105 @@
+package ogame
+
+import "time"
+
+// newEmpireCelestial returns the empire state of a celestial fetched page by page
+func newEmpireCelestial(c Celestial, resources Resources, supplies ResourcesBuildings, facilities Facilities,
+	ships ShipsInfos, defenses DefensesInfos, researches Researches) EmpireCelestial {
+	res := EmpireCelestial{
+		Name:       c.GetName(),
+		Diameter:   c.GetDiameter(),
+		ID:         c.GetID(),
+		Type:       c.GetType(),
+		Fields:     c.GetFields(),
+		Coordinate: c.GetCoordinate(),
+		Resources:  resources,
+		Supplies:   supplies,
+		Facilities: facilities,
+		Defenses:   defenses,
+		Researches: researches,
+		Ships:      ships,
+	}
+	switch v := c.(type) {
+	case Planet:
+		res.Img, res.Temperature = v.Img, v.Temperature
+	case *Planet:
+		res.Img, res.Temperature = v.Img, v.Temperature
+	case Moon:
+		res.Img = v.Img
+	case *Moon:
+		res.Img = v.Img
+	}
+	return res
+}
+
+// fetchEmpireByCelestial fetches the techs of every celestial, 1 request per celestial, and their resources if
+// withResources is true, 1 more request per celestial
+func (b *OGame) fetchEmpireByCelestial(withResources bool) (EmpireSnapshot, error) {
+	celestials := b.getCachedCelestials()
+	res := EmpireSnapshot{Time: time.Now(), Celestials: make([]EmpireCelestial, len(celestials))}
+	for _, i := range b.HumanizedOrder(len(celestials)) {
+		c := celestials[i]
+		var resources Resources
+		if withResources {
+			var err error
+			if resources, err = b.getResources(c.GetID()); err != nil {
+				return EmpireSnapshot{}, err
+			}
+		}
+		supplies, facilities, ships, defenses, researches, err := b.getTechs(c.GetID())
+		if err != nil {
+			return EmpireSnapshot{}, err
+		}
+		res.Celestials[i] = newEmpireCelestial(c, resources, supplies, facilities, ships, defenses, researches)
+	}
+	return res, nil
+}
+
+// fetchEmpire builds the state of every planet and moon. With a commander the empire pages are used (at most 2
+// requests), otherwise the celestials are fetched one by one, see fetchEmpireByCelestial.
+func (b *OGame) fetchEmpire(withResources bool) (EmpireSnapshot, error) {
+	if b.hasCommander {
+		res, err := b.getEmpireSnapshot()
+		if err == nil {
+			return res, nil
+		}
+		b.error("failed to get empire, fetching every celestial: " + err.Error())
+	}
+	return b.fetchEmpireByCelestial(withResources)
+}
+
+// syncEmpire builds the state of every planet and moon, resources included, see fetchEmpire.
+// The researches cache is refreshed.
+func (b *OGame) syncEmpire() (EmpireSnapshot, error) {
+	res, err := b.fetchEmpire(true)
+	if err != nil {
+		return res, err
+	}
+	if len(res.Celestials) > 0 {
+		researches := res.Celestials[0].Researches
+		b.researches = &researches
+		b.researchesCachedAt = res.Time
+	}
+	b.cacheEmpireSnapshot(res)
+	return res, nil
+}
+
+func (b *OGame) cacheEmpireSnapshot(snapshot EmpireSnapshot) {
+	snapshot.Celestials = append([]EmpireCelestial(nil), snapshot.Celestials...)
+	b.empireCacheMu.Lock()
+	defer b.empireCacheMu.Unlock()
+	b.empireSnapshot = &snapshot
+}
+
+// GetCachedEmpire returns the buildings, ships and defenses of every celestial from the last empire sync or import,
+// false if there is none
+func (b *OGame) GetCachedEmpire() (EmpireSnapshot, bool) {
+	b.empireCacheMu.Lock()
+	defer b.empireCacheMu.Unlock()
+	if b.empireSnapshot == nil {
+		return EmpireSnapshot{}, false
+	}
+	res := *b.empireSnapshot
+	res.Celestials = append([]EmpireCelestial(nil), res.Celestials...)
+	return res, true
+}
@@ -0,0 +1,35 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEmpireCelestial(t *testing.T) {
+	moon := &Moon{ID: 2, Img: "moon.png", Name: "Moon", Diameter: 8000, Coordinate: Coordinate{1, 2, 3, MoonType}}
+	planet := Planet{ID: 1, Img: "planet.png", Name: "Homeworld", Diameter: 12800, Coordinate: Coordinate{1, 2, 3, PlanetType},
+		Fields: Fields{Built: 100, Total: 163}, Temperature: Temperature{Min: -20, Max: 20}, Moon: moon}
+	resources := Resources{Metal: 1000, Crystal: 500}
+	supplies := ResourcesBuildings{MetalMine: 20}
+	researches := Researches{EnergyTechnology: 8}
+
+	res := newEmpireCelestial(planet, resources, supplies, Facilities{RoboticsFactory: 10}, ShipsInfos{SmallCargo: 5}, DefensesInfos{RocketLauncher: 50}, researches)
+	assert.Equal(t, CelestialID(1), res.ID)
+	assert.Equal(t, PlanetType, res.Type)
+	assert.Equal(t, "Homeworld", res.Name)
+	assert.Equal(t, "planet.png", res.Img)
+	assert.Equal(t, int64(163), res.Fields.Total)
+	assert.Equal(t, int64(-20), res.Temperature.Min)
+	assert.Equal(t, resources, res.Resources)
+	assert.Equal(t, int64(20), res.Supplies.MetalMine)
+	assert.Equal(t, int64(5), res.Ships.SmallCargo)
+	assert.Equal(t, int64(50), res.Defenses.RocketLauncher)
+	assert.Equal(t, researches, res.Researches)
+
+	res = newEmpireCelestial(moon, Resources{}, ResourcesBuildings{}, Facilities{LunarBase: 2}, ShipsInfos{}, DefensesInfos{}, researches)
+	assert.Equal(t, CelestialID(2), res.ID)
+	assert.Equal(t, MoonType, res.Type)
+	assert.Equal(t, "moon.png", res.Img)
+	assert.Equal(t, int64(2), res.Facilities.LunarBase)
+}
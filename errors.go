@@ -1,6 +1,9 @@
 package ogame
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+)
 
 // ErrNotLogged returned when the bot is not logged
 var ErrNotLogged = errors.New("not logged")
@@ -23,6 +26,15 @@ var ErrAccountNotFound = errors.New("account not found")
 // ErrAccountBlocked returned when account is banned
 var ErrAccountBlocked = errors.New("account is blocked")
 
+// ErrAccountBanned returned when the lobby reports the account as banned
+var ErrAccountBanned = errors.New("account is banned")
+
+// ErrAccountLocked returned when the lobby reports the account as blocked
+var ErrAccountLocked = errors.New("account is locked")
+
+// ErrAccountVacationForced notified when the account is put in vacation mode, the bot never enables it
+var ErrAccountVacationForced = errors.New("account was put in vacation mode")
+
 // ErrInvalidPlanetID returned when a planet id is invalid
 var ErrInvalidPlanetID = errors.New("invalid planet id")
 
@@ -35,15 +47,45 @@ var ErrBotInactive = errors.New("bot is not active")
 // ErrBotLoggedOut returned when the bot is logged out (manually logged out)
 var ErrBotLoggedOut = errors.New("bot is logged out")
 
+// ErrSafeMode returned when the bot paused all requests after too many consecutive failed tasks
+var ErrSafeMode = errors.New("bot is in safe mode")
+
+// ErrServerUnavailable returned when the circuit breaker is open after repeated server errors/timeouts (eg: maintenance)
+var ErrServerUnavailable = errors.New("ogame server unavailable")
+
 // ErrFailedExecuteCallback returned when "withRetry" failed to execute callback
 var ErrFailedExecuteCallback = errors.New("failed to execute callback")
 
+// ErrReloginFailed returned when the bot failed to restore an expired session
+var ErrReloginFailed = errors.New("failed to re-login")
+
+// ErrNoPendingCaptcha returned when trying to answer a captcha while none is waiting for an answer
+var ErrNoPendingCaptcha = errors.New("no pending captcha")
+
+// ErrBalanceUnsupported returned when the captcha solver service has no balance endpoint
+var ErrBalanceUnsupported = errors.New("captcha solver does not support balance check")
+
+// ErrQuietHours returned when a request is attempted during the humanizer quiet hours
+var ErrQuietHours = errors.New("quiet hours, no request sent")
+
+// ErrNoHealthyProxy returned when every proxy of the proxy pool is unhealthy
+var ErrNoHealthyProxy = errors.New("no healthy proxy available")
+
 // ErrDeactivateHidePictures returned when "Hide pictures in reports" is activated
 var ErrDeactivateHidePictures = errors.New("deactivate 'Hide pictures in reports'")
 
 // ErrEventsBoxNotDisplayed returned when trying to get attacks from a full page without event box
 var ErrEventsBoxNotDisplayed = errors.New("eventList box is not displayed")
 
+// ErrDifferentTargets returned when comparing espionage reports of different targets
+var ErrDifferentTargets = errors.New("espionage reports are not for the same target")
+
+// ErrNotInPhalanxRange returned when a coordinate is out of the phalanx range of a moon
+var ErrNotInPhalanxRange = errors.New("coordinate not in phalanx range")
+
+// ErrCooldown returned (wrapped in a CooldownError) when the jump gate is recharging
+var ErrCooldown = errors.New("jump gate is in recharge mode")
+
 // Send fleet errors
 var (
 	ErrUnionNotFound                      = errors.New("union not found")
@@ -61,4 +103,195 @@ var (
 	ErrNoRecyclerAvailable                = errors.New("no recycler available")
 	ErrNoEventsRunning                    = errors.New("there are currently no events running")
 	ErrPlanetAlreadyReservedForRelocation = errors.New("this planet has already been reserved for a relocation")
+	ErrFriendlyTarget                     = errors.New("target is an alliance member or a buddy")
 )
+
+// ErrInvalidCharacterClass returned when selecting a character class that does not exist
+var ErrInvalidCharacterClass = errors.New("invalid character class")
+
+// ErrNotEnoughDarkMatter returned when the dark matter available does not cover a cost
+var ErrNotEnoughDarkMatter = errors.New("not enough dark matter")
+
+// ErrNotLoggedIn returned when the bot is not logged in the game, alias of ErrNotLogged
+var ErrNotLoggedIn = ErrNotLogged
+
+// ErrNoFreeSlot returned when no fleet slot is free, alias of ErrAllSlotsInUse
+var ErrNoFreeSlot = ErrAllSlotsInUse
+
+// ErrNotEnoughResources returned when the resources available do not cover a cost
+var ErrNotEnoughResources = errors.New("not enough resources")
+
+// ErrTargetNotExists returned when the target of an action does not exist
+var ErrTargetNotExists = errors.New("target does not exist")
+
+// ErrPlayerNotFound returned when a player cannot be found in the highscore
+var ErrPlayerNotFound = errors.New("player not found")
+
+// ErrFleetInvalid returned when the game refused to send a fleet
+var ErrFleetInvalid = errors.New("invalid fleet")
+
+// ErrCaptchaRequired returned when gameforge requires a captcha to be solved
+var ErrCaptchaRequired = errors.New("captcha required")
+
+// ErrLoginBlocked returned when gameforge answered the login with a blocking page instead of the sessions api response
+var ErrLoginBlocked = errors.New("login blocked")
+
+// ErrRateLimited returned when the game server answered too many requests
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrInvalidParameters returned when the game refused the parameters of a request
+var ErrInvalidParameters = errors.New("invalid parameters")
+
+// ErrTaskDeadlineExceeded returned when a task held the bot lock longer than the task deadline
+var ErrTaskDeadlineExceeded = errors.New("task deadline exceeded")
+
+// ErrRequirementsNotMet returned when the buildings or researches required to build an object are missing
+var ErrRequirementsNotMet = errors.New("requirements not met")
+
+// ErrConstructionNotStarted returned when a construction is not in progress right after being started
+var ErrConstructionNotStarted = errors.New("construction not started")
+
+// ErrConstructionCanceled returned when a construction stopped before reaching its next level
+var ErrConstructionCanceled = errors.New("construction canceled")
+
+// ErrInvalidSpeed returned when a fleet speed cannot be selected by the character class of the player
+var ErrInvalidSpeed = errors.New("invalid speed")
+
+// ErrMaxPlanetsReached returned when the player has the max planets allowed by its astrophysics level
+var ErrMaxPlanetsReached = errors.New("max planets reached")
+
+// GameError error returned by the game, its cause is one of the sentinel errors
+type GameError struct {
+	Err     error
+	Code    int64 // Error code given by the game, 0 if none
+	Message string
+}
+
+func (e *GameError) Error() string {
+	return e.Message
+}
+
+// Cause returns the underlying sentinel error, compatible with errors.Cause
+func (e *GameError) Cause() error {
+	return e.Err
+}
+
+// ErrorCode machine-readable category of an error
+type ErrorCode string
+
+// Error codes
+const (
+	ErrCodeInternal           ErrorCode = "internal_error"
+	ErrCodeNotLoggedIn        ErrorCode = "not_logged_in"
+	ErrCodeBotUnavailable     ErrorCode = "bot_unavailable"
+	ErrCodeServerUnavailable  ErrorCode = "server_unavailable"
+	ErrCodeRateLimited        ErrorCode = "rate_limited"
+	ErrCodeCaptchaRequired    ErrorCode = "captcha_required"
+	ErrCodeBadCredentials     ErrorCode = "bad_credentials"
+	ErrCodeAccountBanned      ErrorCode = "account_banned"
+	ErrCodeNotEnoughResources ErrorCode = "not_enough_resources"
+	ErrCodeNoFreeSlot         ErrorCode = "no_free_slot"
+	ErrCodeTargetNotExists    ErrorCode = "target_not_exists"
+	ErrCodeFleetInvalid       ErrorCode = "fleet_invalid"
+	ErrCodeCooldown           ErrorCode = "cooldown"
+	ErrCodeTimeout            ErrorCode = "timeout"
+	ErrCodeInvalidRequest     ErrorCode = "invalid_request"
+	ErrCodeRequirementsNotMet ErrorCode = "requirements_not_met"
+)
+
+var errorCodes = map[error]ErrorCode{
+	ErrNotLogged:                          ErrCodeNotLoggedIn,
+	ErrBotLoggedOut:                       ErrCodeNotLoggedIn,
+	ErrReloginFailed:                      ErrCodeNotLoggedIn,
+	ErrBotInactive:                        ErrCodeBotUnavailable,
+	ErrSafeMode:                           ErrCodeBotUnavailable,
+	ErrQuietHours:                         ErrCodeBotUnavailable,
+	ErrNoHealthyProxy:                     ErrCodeBotUnavailable,
+	ErrServerUnavailable:                  ErrCodeServerUnavailable,
+	ErrRateLimited:                        ErrCodeRateLimited,
+	ErrLoginBlocked:                       ErrCodeRateLimited,
+	ErrCaptchaRequired:                    ErrCodeCaptchaRequired,
+	ErrBadCredentials:                     ErrCodeBadCredentials,
+	ErrOTPRequired:                        ErrCodeBadCredentials,
+	ErrOTPInvalid:                         ErrCodeBadCredentials,
+	ErrAccountNotFound:                    ErrCodeBadCredentials,
+	ErrAccountBlocked:                     ErrCodeAccountBanned,
+	ErrAccountBanned:                      ErrCodeAccountBanned,
+	ErrAccountLocked:                      ErrCodeAccountBanned,
+	ErrAccountVacationForced:              ErrCodeAccountBanned,
+	ErrNotEnoughResources:                 ErrCodeNotEnoughResources,
+	ErrNotEnoughDarkMatter:                ErrCodeNotEnoughResources,
+	ErrAllSlotsInUse:                      ErrCodeNoFreeSlot,
+	ErrTargetNotExists:                    ErrCodeTargetNotExists,
+	ErrPlayerNotFound:                     ErrCodeTargetNotExists,
+	ErrUninhabitedPlanet:                  ErrCodeTargetNotExists,
+	ErrNoDebrisField:                      ErrCodeTargetNotExists,
+	ErrNoMoonAvailable:                    ErrCodeTargetNotExists,
+	ErrUnionNotFound:                      ErrCodeTargetNotExists,
+	ErrFleetInvalid:                       ErrCodeFleetInvalid,
+	ErrAccountInVacationMode:              ErrCodeFleetInvalid,
+	ErrNoShipSelected:                     ErrCodeFleetInvalid,
+	ErrNotEnoughShips:                     ErrCodeFleetInvalid,
+	ErrPlayerInVacationMode:               ErrCodeFleetInvalid,
+	ErrAdminOrGM:                          ErrCodeFleetInvalid,
+	ErrNoAstrophysics:                     ErrCodeFleetInvalid,
+	ErrNoobProtection:                     ErrCodeFleetInvalid,
+	ErrPlayerTooStrong:                    ErrCodeFleetInvalid,
+	ErrNoRecyclerAvailable:                ErrCodeFleetInvalid,
+	ErrNoEventsRunning:                    ErrCodeFleetInvalid,
+	ErrPlanetAlreadyReservedForRelocation: ErrCodeFleetInvalid,
+	ErrFriendlyTarget:                     ErrCodeFleetInvalid,
+	ErrCooldown:                           ErrCodeCooldown,
+	ErrTaskDeadlineExceeded:               ErrCodeTimeout,
+	ErrInvalidPlanetID:                    ErrCodeInvalidRequest,
+	ErrInvalidCharacterClass:              ErrCodeInvalidRequest,
+	ErrInvalidParameters:                  ErrCodeInvalidRequest,
+	ErrInvalidSpeed:                       ErrCodeInvalidRequest,
+	ErrNotInPhalanxRange:                  ErrCodeInvalidRequest,
+	ErrDifferentTargets:                   ErrCodeInvalidRequest,
+	ErrNoPendingCaptcha:                   ErrCodeInvalidRequest,
+	ErrRequirementsNotMet:                 ErrCodeRequirementsNotMet,
+	ErrMaxPlanetsReached:                  ErrCodeRequirementsNotMet,
+}
+
+// GetErrorCode returns the machine-readable code of err, following its causes. Unknown errors are internal errors.
+func GetErrorCode(err error) ErrorCode {
+	type causer interface{ Cause() error }
+	for err != nil {
+		if code, ok := errorCodes[err]; ok {
+			return code
+		}
+		if _, ok := err.(*ServerError); ok {
+			return ErrCodeServerUnavailable
+		}
+		if _, ok := err.(*ServerMovedError); ok {
+			return ErrCodeServerUnavailable
+		}
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return ErrCodeInternal
+}
+
+// fleetErrorCodes sentinel errors of the error codes returned by the fleet dispatch
+var fleetErrorCodes = map[int64]error{
+	4060: ErrNotEnoughResources, // Insufficient resources
+	4049: ErrTargetNotExists,    // You have to select a valid target
+}
+
+// newFleetError returns the GameError of an error of the fleet dispatch
+func newFleetError(code int64, message string) *GameError {
+	err, ok := fleetErrorCodes[code]
+	if !ok {
+		err = ErrFleetInvalid
+	}
+	return &GameError{Err: err, Code: code, Message: message + " (" + strconv.FormatInt(code, 10) + ")"}
+}
+
+// newCaptchaRequiredError returns the error of a gameforge captcha challenge
+func newCaptchaRequiredError(challengeID string) *GameError {
+	return &GameError{Err: ErrCaptchaRequired, Message: "captcha required, " + challengeID}
+}
@@ -0,0 +1,38 @@
+package ogame
+
+import (
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetErrorCode(t *testing.T) {
+	assert.Equal(t, ErrCodeNotLoggedIn, GetErrorCode(ErrNotLoggedIn))
+	assert.Equal(t, ErrCodeNoFreeSlot, GetErrorCode(ErrNoFreeSlot))
+	assert.Equal(t, ErrCodeTargetNotExists, GetErrorCode(ErrUninhabitedPlanet))
+	assert.Equal(t, ErrCodeCooldown, GetErrorCode(&CooldownError{Remaining: 10}))
+	assert.Equal(t, ErrCodeAccountBanned, GetErrorCode(&AccountBanError{Err: ErrAccountLocked}))
+	assert.Equal(t, ErrCodeServerUnavailable, GetErrorCode(&ServerError{StatusCode: 502}))
+	assert.Equal(t, ErrCodeRateLimited, GetErrorCode(pkgerrors.Wrap(ErrRateLimited, "get page")))
+	assert.Equal(t, ErrCodeInternal, GetErrorCode(errors.New("unknown")))
+	assert.Equal(t, ErrCodeInternal, GetErrorCode(nil))
+}
+
+func TestNewFleetError(t *testing.T) {
+	err := newFleetError(4060, "Insufficient resources.")
+	assert.Equal(t, "Insufficient resources. (4060)", err.Error())
+	assert.Equal(t, ErrNotEnoughResources, pkgerrors.Cause(err))
+	assert.Equal(t, ErrCodeNotEnoughResources, GetErrorCode(err))
+
+	err = newFleetError(4029, "Not enough cargo space!")
+	assert.Equal(t, ErrFleetInvalid, pkgerrors.Cause(err))
+	assert.Equal(t, int64(4029), err.Code)
+}
+
+func TestNewCaptchaRequiredError(t *testing.T) {
+	err := newCaptchaRequiredError("c434aa65")
+	assert.Equal(t, "captcha required, c434aa65", err.Error())
+	assert.Equal(t, ErrCodeCaptchaRequired, GetErrorCode(err))
+}
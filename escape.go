@@ -0,0 +1,254 @@
+package ogame
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Escape module defaults
+const (
+	DefaultEscapeThreshold   = 5 * time.Minute
+	escapeMaxCheckInterval   = time.Minute
+	escapeMinCheckInterval   = 5 * time.Second
+	escapeRecallDelay        = 5 * time.Second
+	escapeInitiator          = "Escape"
+	escapeDestinationUnknown = "no escape destination available"
+)
+
+// EscapeRule opt-in fleet save of a celestial, the ships (and resources) leave when an attack is about to land
+type EscapeRule struct {
+	CelestialID CelestialID
+	Threshold   time.Duration // Escape when the attack lands within Threshold, DefaultEscapeThreshold if 0
+	Mission     MissionID     // Park if 0
+	Destination *Coordinate   // Closest other own celestial that is not under attack if nil
+	Speed       Speed         // TenPercent if 0
+	Resources   bool          // Also load the resources
+	Recall      bool          // Recall the fleet once the attack landed
+}
+
+func (r EscapeRule) withDefaults() EscapeRule {
+	if r.Threshold <= 0 {
+		r.Threshold = DefaultEscapeThreshold
+	}
+	if r.Mission == 0 {
+		r.Mission = Park
+	}
+	if r.Speed == 0 {
+		r.Speed = TenPercent
+	}
+	return r
+}
+
+// isEscapableAttack missiles cannot be escaped, spies do not destroy anything
+func isEscapableAttack(a AttackEvent) bool {
+	return a.MissionType == Attack || a.MissionType == GroupedAttack || a.MissionType == Destroy
+}
+
+// closestEscapeCoordinate returns the closest candidate that is not the origin and not under attack
+func closestEscapeCoordinate(origin Coordinate, candidates []Coordinate, attacked map[Coordinate]bool, distance func(a, b Coordinate) int64) (Coordinate, bool) {
+	var best Coordinate
+	found := false
+	var bestDistance int64
+	for _, c := range candidates {
+		if c.Equal(origin) || attacked[c] {
+			continue
+		}
+		if d := distance(origin, c); !found || d < bestDistance {
+			best, bestDistance, found = c, d, true
+		}
+	}
+	return best, found
+}
+
+// nextEscapeCheck returns when the attacks must be checked again, so an escape happens as soon as an attack enters
+// the threshold of its target
+func nextEscapeCheck(attacks []AttackEvent, rules map[Coordinate]EscapeRule, now time.Time) time.Duration {
+	interval := escapeMaxCheckInterval
+	for _, a := range attacks {
+		rule, ok := rules[a.Destination]
+		if !ok || !isEscapableAttack(a) {
+			continue
+		}
+		if d := a.ArrivalTime.Add(-rule.Threshold).Sub(now); d < interval {
+			interval = d
+		}
+	}
+	if interval < escapeMinCheckInterval {
+		interval = escapeMinCheckInterval
+	}
+	return interval
+}
+
+// SetEscapeRule enables the escape responder for a celestial, it replaces the previous rule of the celestial
+func (b *OGame) SetEscapeRule(rule EscapeRule) error {
+	if b.GetCachedCelestialByID(rule.CelestialID) == nil {
+		return errors.New("celestial not found")
+	}
+	rule = rule.withDefaults()
+	b.escapeMu.Lock()
+	defer b.escapeMu.Unlock()
+	if b.escapeRules == nil {
+		b.escapeRules = make(map[CelestialID]EscapeRule)
+	}
+	b.escapeRules[rule.CelestialID] = rule
+	if !b.escapeRunning {
+		b.escapeRunning = true
+		b.escapeStop = make(chan struct{})
+		go b.escapeLoop(b.escapeStop)
+	}
+	return nil
+}
+
+// RemoveEscapeRule disables the escape responder for a celestial, the loop stops with the last rule
+func (b *OGame) RemoveEscapeRule(celestialID CelestialID) {
+	b.escapeMu.Lock()
+	defer b.escapeMu.Unlock()
+	delete(b.escapeRules, celestialID)
+	if len(b.escapeRules) == 0 && b.escapeRunning {
+		b.escapeRunning = false
+		close(b.escapeStop)
+	}
+}
+
+// GetEscapeRules returns the escape rules sorted by celestial id
+func (b *OGame) GetEscapeRules() []EscapeRule {
+	b.escapeMu.Lock()
+	defer b.escapeMu.Unlock()
+	res := make([]EscapeRule, 0, len(b.escapeRules))
+	for _, r := range b.escapeRules {
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].CelestialID < res[j].CelestialID })
+	return res
+}
+
+// escapeRulesByCoord returns the rules indexed by the coordinate of their celestial
+func (b *OGame) escapeRulesByCoord() map[Coordinate]EscapeRule {
+	res := make(map[Coordinate]EscapeRule)
+	for _, r := range b.GetEscapeRules() {
+		if c := b.GetCachedCelestialByID(r.CelestialID); c != nil {
+			res[c.GetCoordinate()] = r
+		}
+	}
+	return res
+}
+
+func (b *OGame) escapeLoop(stop chan struct{}) {
+	for {
+		rules := b.escapeRulesByCoord()
+		if len(rules) == 0 {
+			b.escapeMu.Lock()
+			if len(b.escapeRules) == 0 {
+				if b.escapeStop == stop {
+					b.escapeRunning = false
+				}
+				b.escapeMu.Unlock()
+				return
+			}
+			b.escapeMu.Unlock()
+		}
+		interval := escapeMaxCheckInterval
+		if b.isEnabled() && b.IsLoggedIn() && len(rules) > 0 {
+			if attacks, err := b.WithPriority(Critical).GetAttacks(); err == nil {
+				b.escapeAttacks(attacks, rules)
+				interval = nextEscapeCheck(attacks, rules, time.Now())
+			}
+		}
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			return
+		}
+	}
+}
+
+// escapeAttacks escapes the celestials targeted by an attack landing within their threshold
+func (b *OGame) escapeAttacks(attacks []AttackEvent, rules map[Coordinate]EscapeRule) {
+	attacked := make(map[Coordinate]bool)
+	for _, a := range attacks {
+		if isEscapableAttack(a) {
+			attacked[a.Destination] = true
+		}
+	}
+	now := time.Now()
+	for _, a := range attacks {
+		rule, ok := rules[a.Destination]
+		if !ok || !isEscapableAttack(a) || a.ArrivalTime.Sub(now) > rule.Threshold || !a.ArrivalTime.After(now) {
+			continue
+		}
+		b.escapeMu.Lock()
+		if b.escapedAttacks == nil {
+			b.escapedAttacks = make(map[int64]time.Time)
+		}
+		for id, arrival := range b.escapedAttacks {
+			if arrival.Before(now) {
+				delete(b.escapedAttacks, id)
+			}
+		}
+		_, done := b.escapedAttacks[a.ID]
+		b.escapeMu.Unlock()
+		if done {
+			continue
+		}
+		// The attack is only recorded once the fleet left (or there is nothing to send), a failed escape is retried
+		// on the next check
+		fleet, err := b.escape(rule, attacked)
+		if err == nil || err == ErrNoShipSelected {
+			b.escapeMu.Lock()
+			b.escapedAttacks[a.ID] = a.ArrivalTime
+			b.escapeMu.Unlock()
+		}
+		if err != nil {
+			b.error("escape from ", a.Destination, " failed: ", err)
+			continue
+		}
+		b.info("escaped from ", a.Destination, " to ", fleet.Destination, ", attack lands at ", a.ArrivalTime)
+		if rule.Recall {
+			time.AfterFunc(time.Until(a.ArrivalTime)+escapeRecallDelay, func() {
+				if err := b.WithPriority(Critical).SetInitiator(escapeInitiator).CancelFleet(fleet.ID); err != nil {
+					b.error("failed to recall escaped fleet ", fleet.ID, ": ", err)
+				}
+			})
+		}
+	}
+}
+
+// escape sends every flyable ship (and the resources) of the rule celestial away
+func (b *OGame) escape(rule EscapeRule, attacked map[Coordinate]bool) (Fleet, error) {
+	origin := b.GetCachedCelestialByID(rule.CelestialID)
+	if origin == nil {
+		return Fleet{}, errors.New("celestial not found")
+	}
+	var destination Coordinate
+	if rule.Destination != nil {
+		destination = *rule.Destination
+	} else {
+		candidates := make([]Coordinate, 0)
+		for _, c := range b.GetCachedCelestials() {
+			candidates = append(candidates, c.GetCoordinate())
+		}
+		var ok bool
+		if destination, ok = closestEscapeCoordinate(origin.GetCoordinate(), candidates, attacked, b.Distance); !ok {
+			return Fleet{}, errors.New(escapeDestinationUnknown)
+		}
+	}
+	tx := b.WithPriority(Critical).SetInitiator(escapeInitiator).BeginNamed(escapeInitiator)
+	defer tx.Done()
+	ships, err := tx.GetShips(rule.CelestialID)
+	if err != nil {
+		return Fleet{}, err
+	}
+	if !ships.HasFlyableShips() {
+		return Fleet{}, ErrNoShipSelected
+	}
+	var resources Resources
+	if rule.Resources {
+		if resources, err = tx.GetResources(rule.CelestialID); err != nil {
+			return Fleet{}, err
+		}
+		_, fuel := tx.FlightTime(origin.GetCoordinate(), destination, rule.Speed, ships, rule.Mission)
+		resources.Deuterium = MaxInt(resources.Deuterium-fuel, 0)
+	}
+	return tx.SendFleet(rule.CelestialID, ships.ToQuantifiables(), rule.Speed, destination, rule.Mission, resources, 0, 0)
+}
@@ -0,0 +1,87 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeRuleWithDefaults(t *testing.T) {
+	rule := EscapeRule{CelestialID: 1}.withDefaults()
+	assert.Equal(t, DefaultEscapeThreshold, rule.Threshold)
+	assert.Equal(t, Park, rule.Mission)
+	assert.Equal(t, TenPercent, rule.Speed)
+
+	rule = EscapeRule{CelestialID: 1, Threshold: time.Minute, Mission: Transport, Speed: HundredPercent}.withDefaults()
+	assert.Equal(t, time.Minute, rule.Threshold)
+	assert.Equal(t, Transport, rule.Mission)
+	assert.Equal(t, HundredPercent, rule.Speed)
+}
+
+func TestIsEscapableAttack(t *testing.T) {
+	assert.True(t, isEscapableAttack(AttackEvent{MissionType: Attack}))
+	assert.True(t, isEscapableAttack(AttackEvent{MissionType: GroupedAttack}))
+	assert.True(t, isEscapableAttack(AttackEvent{MissionType: Destroy}))
+	assert.False(t, isEscapableAttack(AttackEvent{MissionType: MissileAttack}))
+	assert.False(t, isEscapableAttack(AttackEvent{MissionType: Spy}))
+}
+
+func TestClosestEscapeCoordinate(t *testing.T) {
+	distance := func(a, b Coordinate) int64 { return Distance(a, b, 9, 499, true, true) }
+	origin := Coordinate{1, 100, 8, PlanetType}
+	near := Coordinate{1, 102, 4, PlanetType}
+	nearMoon := Coordinate{1, 101, 4, MoonType}
+	far := Coordinate{3, 100, 8, PlanetType}
+	candidates := []Coordinate{origin, far, near, nearMoon}
+
+	dest, ok := closestEscapeCoordinate(origin, candidates, nil, distance)
+	assert.True(t, ok)
+	assert.Equal(t, nearMoon, dest)
+
+	dest, ok = closestEscapeCoordinate(origin, candidates, map[Coordinate]bool{nearMoon: true}, distance)
+	assert.True(t, ok)
+	assert.Equal(t, near, dest)
+
+	_, ok = closestEscapeCoordinate(origin, []Coordinate{origin}, nil, distance)
+	assert.False(t, ok)
+}
+
+func TestNextEscapeCheck(t *testing.T) {
+	now := time.Now()
+	target := Coordinate{1, 100, 8, PlanetType}
+	rules := map[Coordinate]EscapeRule{target: {CelestialID: 1, Threshold: 5 * time.Minute}}
+
+	assert.Equal(t, escapeMaxCheckInterval, nextEscapeCheck(nil, rules, now))
+
+	attacks := []AttackEvent{{MissionType: Attack, Destination: target, ArrivalTime: now.Add(5*time.Minute + 30*time.Second)}}
+	assert.Equal(t, 30*time.Second, nextEscapeCheck(attacks, rules, now))
+
+	attacks = []AttackEvent{{MissionType: Attack, Destination: target, ArrivalTime: now.Add(2 * time.Minute)}}
+	assert.Equal(t, escapeMinCheckInterval, nextEscapeCheck(attacks, rules, now))
+
+	attacks = []AttackEvent{
+		{MissionType: MissileAttack, Destination: target, ArrivalTime: now.Add(5*time.Minute + 10*time.Second)},
+		{MissionType: Attack, Destination: Coordinate{1, 100, 9, PlanetType}, ArrivalTime: now.Add(5*time.Minute + 10*time.Second)},
+	}
+	assert.Equal(t, escapeMaxCheckInterval, nextEscapeCheck(attacks, rules, now))
+}
+
+func TestRemoveEscapeRule_StopsLoop(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.escapeRules = map[CelestialID]EscapeRule{1: {CelestialID: 1}}
+	b.escapeRunning = true
+	b.escapeStop = make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		b.escapeLoop(b.escapeStop)
+		close(stopped)
+	}()
+	b.RemoveEscapeRule(1)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("escape loop still running")
+	}
+	assert.False(t, b.escapeRunning)
+}
@@ -236,3 +236,27 @@ func (r EspionageReport) IsDefenceless() bool {
 		!r.ShipsInfos().HasShips() &&
 		!r.DefensesInfos().HasShipDefense()
 }
+
+// EspionageInfoLevel level of information an espionage report contains
+type EspionageInfoLevel int64
+
+// Espionage information levels, each level includes the previous ones
+const (
+	EspionageInfoResources  EspionageInfoLevel = 1
+	EspionageInfoFleet      EspionageInfoLevel = 2
+	EspionageInfoDefenses   EspionageInfoLevel = 3
+	EspionageInfoBuildings  EspionageInfoLevel = 5
+	EspionageInfoResearches EspionageInfoLevel = 7
+)
+
+// ProbesNeeded returns the number of probes to send to get the wanted information level.
+// The information level is the number of probes, plus the square of the espionage technology difference
+// when ours is higher, or minus it when the target one is higher.
+func ProbesNeeded(ownEspTech, targetEspTech int64, wantedInfoLevel EspionageInfoLevel) int64 {
+	diff := ownEspTech - targetEspTech
+	bonus := diff * diff
+	if diff < 0 {
+		bonus = -bonus
+	}
+	return max64(int64(wantedInfoLevel)-bonus, 1)
+}
@@ -0,0 +1,137 @@
+package ogame
+
+import (
+	"errors"
+	"time"
+)
+
+// EspionageReportDiff structural changes between two espionage reports of the same target.
+// Deltas are new - old, only the changed entries are kept. A map is nil if one of the reports lacks the information.
+type EspionageReportDiff struct {
+	Coordinate Coordinate
+	OldID      int64
+	NewID      int64
+	OldDate    time.Time
+	NewDate    time.Time
+	Resources  Resources // Can be negative
+	Buildings  map[ID]int64
+	Researches map[ID]int64
+	Ships      map[ID]int64
+	Defenses   map[ID]int64
+}
+
+// HasChanges returns either or not anything changed between the two reports
+func (d EspionageReportDiff) HasChanges() bool {
+	return d.Resources != (Resources{}) || len(d.Buildings) > 0 || len(d.Researches) > 0 || len(d.Ships) > 0 || len(d.Defenses) > 0
+}
+
+// DefensesIncreased returns true if the target built defenses between the two reports
+func (d EspionageReportDiff) DefensesIncreased() bool {
+	for _, delta := range d.Defenses {
+		if delta > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func diffByID(ids []ID, oldByID, newByID func(ID) int64) map[ID]int64 {
+	deltas := make(map[ID]int64)
+	for _, id := range ids {
+		if delta := newByID(id) - oldByID(id); delta != 0 {
+			deltas[id] = delta
+		}
+	}
+	return deltas
+}
+
+// DiffEspionageReports returns the changes between two espionage reports of the same target
+func DiffEspionageReports(older, newer EspionageReport) (EspionageReportDiff, error) {
+	if !older.Coordinate.Equal(newer.Coordinate) {
+		return EspionageReportDiff{}, ErrDifferentTargets
+	}
+	diff := EspionageReportDiff{
+		Coordinate: newer.Coordinate,
+		OldID:      older.ID,
+		NewID:      newer.ID,
+		OldDate:    older.Date,
+		NewDate:    newer.Date,
+		Resources: Resources{
+			Metal:     newer.Metal - older.Metal,
+			Crystal:   newer.Crystal - older.Crystal,
+			Deuterium: newer.Deuterium - older.Deuterium,
+		},
+	}
+	if older.HasBuildingsInformation && newer.HasBuildingsInformation {
+		oldRes, newRes := older.ResourcesBuildings(), newer.ResourcesBuildings()
+		oldFac, newFac := older.Facilities(), newer.Facilities()
+		ids := make([]ID, 0, len(Buildings))
+		for _, b := range Buildings {
+			if b.GetID() != SolarSatelliteID { // Counted with the ships
+				ids = append(ids, b.GetID())
+			}
+		}
+		diff.Buildings = diffByID(ids,
+			func(id ID) int64 { return oldRes.ByID(id) + oldFac.ByID(id) },
+			func(id ID) int64 { return newRes.ByID(id) + newFac.ByID(id) })
+	}
+	if older.HasResearchesInformation && newer.HasResearchesInformation {
+		ids := make([]ID, 0, len(Technologies))
+		for _, t := range Technologies {
+			ids = append(ids, t.GetID())
+		}
+		diff.Researches = diffByID(ids, older.Researches().ByID, newer.Researches().ByID)
+	}
+	if older.HasFleetInformation && newer.HasFleetInformation {
+		ids := make([]ID, 0, len(Ships))
+		for _, s := range Ships {
+			ids = append(ids, s.GetID())
+		}
+		diff.Ships = diffByID(ids, older.ShipsInfos().ByID, newer.ShipsInfos().ByID)
+	}
+	if older.HasDefensesInformation && newer.HasDefensesInformation {
+		ids := make([]ID, 0, len(Defenses))
+		for _, d := range Defenses {
+			ids = append(ids, d.GetID())
+		}
+		diff.Defenses = diffByID(ids, older.DefensesInfos().ByID, newer.DefensesInfos().ByID)
+	}
+	return diff, nil
+}
+
+// diffLatestEspionageReports compares the two most recent espionage reports of coord
+func (b *OGame) diffLatestEspionageReports(coord Coordinate) (EspionageReportDiff, error) {
+	var tabid int64 = 20
+	var page int64 = 1
+	var nbPage int64 = 1
+	ids := make([]int64, 0, 2)
+	for page <= nbPage && len(ids) < 2 {
+		pageHTML, err := b.getPageMessages(page, tabid)
+		if err != nil {
+			return EspionageReportDiff{}, err
+		}
+		newMessages, newNbPage := b.extractor.ExtractEspionageReportMessageIDs(pageHTML)
+		for _, m := range newMessages {
+			if m.Type == Report && m.Target.Equal(coord) && len(ids) < 2 {
+				ids = append(ids, m.ID)
+			}
+		}
+		nbPage = newNbPage
+		page++
+	}
+	if len(ids) < 2 {
+		return EspionageReportDiff{}, errors.New("less than two espionage reports found for " + coord.String())
+	}
+	newReport, err := b.getEspionageReport(ids[0])
+	if err != nil {
+		return EspionageReportDiff{}, err
+	}
+	oldReport, err := b.getEspionageReport(ids[1])
+	if err != nil {
+		return EspionageReportDiff{}, err
+	}
+	if newReport.Date.Before(oldReport.Date) {
+		oldReport, newReport = newReport, oldReport
+	}
+	return DiffEspionageReports(oldReport, newReport)
+}
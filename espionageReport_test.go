@@ -48,3 +48,36 @@ func TestShipsInfos(t *testing.T) {
 	var nilShipsInfos *ShipsInfos = nil
 	assert.Equal(t, nilShipsInfos, er.ShipsInfos())
 }
+
+func TestDiffEspionageReports(t *testing.T) {
+	older := EspionageReport{ID: 1, Coordinate: Coordinate{1, 2, 3, PlanetType}, Resources: Resources{Metal: 100, Crystal: 50},
+		HasDefensesInformation: true, RocketLauncher: I64Ptr(10), HasFleetInformation: true, SmallCargo: I64Ptr(5)}
+	newer := EspionageReport{ID: 2, Coordinate: Coordinate{1, 2, 3, PlanetType}, Resources: Resources{Metal: 80, Crystal: 50, Deuterium: 10},
+		HasDefensesInformation: true, RocketLauncher: I64Ptr(25), LightLaser: I64Ptr(3), HasFleetInformation: true, SmallCargo: I64Ptr(5)}
+	diff, err := DiffEspionageReports(older, newer)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), diff.OldID)
+	assert.Equal(t, int64(2), diff.NewID)
+	assert.Equal(t, Resources{Metal: -20, Deuterium: 10}, diff.Resources)
+	assert.Equal(t, map[ID]int64{RocketLauncherID: 15, LightLaserID: 3}, diff.Defenses)
+	assert.Equal(t, map[ID]int64{}, diff.Ships)
+	assert.Nil(t, diff.Buildings)
+	assert.Nil(t, diff.Researches)
+	assert.True(t, diff.HasChanges())
+	assert.True(t, diff.DefensesIncreased())
+
+	diff, _ = DiffEspionageReports(older, older)
+	assert.False(t, diff.HasChanges())
+
+	_, err = DiffEspionageReports(older, EspionageReport{Coordinate: Coordinate{1, 2, 3, MoonType}})
+	assert.Equal(t, ErrDifferentTargets, err)
+}
+
+func TestProbesNeeded(t *testing.T) {
+	assert.Equal(t, int64(1), ProbesNeeded(10, 10, EspionageInfoResources))
+	assert.Equal(t, int64(7), ProbesNeeded(10, 10, EspionageInfoResearches))
+	assert.Equal(t, int64(4), ProbesNeeded(11, 10, EspionageInfoBuildings))
+	assert.Equal(t, int64(1), ProbesNeeded(13, 10, EspionageInfoResearches))
+	assert.Equal(t, int64(6), ProbesNeeded(10, 12, EspionageInfoFleet))
+	assert.Equal(t, int64(12), ProbesNeeded(8, 11, EspionageInfoDefenses))
+}
@@ -0,0 +1,480 @@
+package ogame
+
+import (
+	"sync"
+	"time"
+)
+
+// EventsSchemaVersion version of the public events JSON schema (see EVENTS.md).
+// It is versioned independently of the library structs, and is only incremented on breaking changes.
+const EventsSchemaVersion = 1
+
+// EventType type of an emitted event
+type EventType string
+
+// Event types
+const (
+	AttackDetectedEvent EventType = "attack.detected"
+	UnderAttackEvent    EventType = "attack.under_attack" // Sent by the ogamed webhooks
+	FleetSentEvent      EventType = "fleet.sent"
+	FleetDepartedEvent  EventType = "fleet.departed"
+	FleetReturningEvent EventType = "fleet.returning"
+	FleetReturnedEvent  EventType = "fleet.returned"
+	BuildStartedEvent   EventType = "build.started"
+	ChatMessageEvent    EventType = "message.chat"
+
+	PhalanxFleetAppearedEvent    EventType = "phalanx.fleet_appeared"
+	PhalanxFleetDisappearedEvent EventType = "phalanx.fleet_disappeared"
+
+	StorageThresholdReachedEvent EventType = "storage.threshold_reached"
+
+	MilitaryScoreDropEvent EventType = "player.military_drop"
+
+	ConstructionFinishedEvent EventType = "construction.finished"
+
+	MissileDefenseEvent EventType = "missile.defense"
+
+	ServerMovedEvent EventType = "server.moved"
+
+	RecyclersDispatchedEvent EventType = "recyclers.dispatched"
+
+	// Task lifecycle, see SubscribeTasks
+	TaskEnqueuedEvent EventType = "task.enqueued" // The task waits for the bot lock
+	TaskStartedEvent  EventType = "task.started"  // The task acquired the bot lock
+	TaskFinishedEvent EventType = "task.finished" // The task released the bot lock without errors
+	TaskFailedEvent   EventType = "task.failed"   // The task released the bot lock, some of its requests failed
+)
+
+// Event envelope shared by all the events outputs (webhook, WebSocket, MQTT, feed...)
+type Event struct {
+	SchemaVersion int         `json:"schema_version"`
+	Type          EventType   `json:"type"`
+	Time          time.Time   `json:"time"`
+	Data          interface{} `json:"data"`
+}
+
+// EventCoordinate coordinate as represented in the events schema
+type EventCoordinate struct {
+	Galaxy   int64  `json:"galaxy"`
+	System   int64  `json:"system"`
+	Position int64  `json:"position"`
+	Type     string `json:"type"` // planet, moon, debris
+}
+
+// EventQuantity object and quantity as represented in the events schema
+type EventQuantity struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Nbr  int64  `json:"nbr"`
+}
+
+// EventResources resources as represented in the events schema
+type EventResources struct {
+	Metal     int64 `json:"metal"`
+	Crystal   int64 `json:"crystal"`
+	Deuterium int64 `json:"deuterium"`
+}
+
+// EventAttackData data of an attack.detected event
+type EventAttackData struct {
+	ID              int64           `json:"id"`
+	Mission         int64           `json:"mission"`
+	MissionName     string          `json:"mission_name"`
+	Origin          EventCoordinate `json:"origin"`
+	Destination     EventCoordinate `json:"destination"`
+	DestinationName string          `json:"destination_name"`
+	ArrivalTime     time.Time       `json:"arrival_time"`
+	AttackerID      int64           `json:"attacker_id"`
+	AttackerName    string          `json:"attacker_name"`
+	UnionID         int64           `json:"union_id"`
+	Missiles        int64           `json:"missiles"`
+	Ships           []EventQuantity `json:"ships"` // null when the ships are unknown
+}
+
+// EventFleetData data of a fleet.sent event
+type EventFleetData struct {
+	ID           int64           `json:"id"`
+	Mission      int64           `json:"mission"`
+	MissionName  string          `json:"mission_name"`
+	ReturnFlight bool            `json:"return_flight"`
+	Origin       EventCoordinate `json:"origin"`
+	Destination  EventCoordinate `json:"destination"`
+	Ships        []EventQuantity `json:"ships"`
+	Resources    EventResources  `json:"resources"`
+	ArrivalTime  time.Time       `json:"arrival_time"`
+	BackTime     time.Time       `json:"back_time"`
+}
+
+// EventBuildData data of a build.started event
+type EventBuildData struct {
+	CelestialID int64         `json:"celestial_id"`
+	Object      EventQuantity `json:"object"` // Nbr is the quantity for ships/defenses, 0 for buildings/researches
+}
+
+// EventMessageData data of a message.chat event
+type EventMessageData struct {
+	ID            int64     `json:"id"`
+	SenderID      int64     `json:"sender_id"`
+	SenderName    string    `json:"sender_name"`
+	AssociationID int64     `json:"association_id"`
+	Text          string    `json:"text"`
+	Time          time.Time `json:"time"`
+}
+
+// EventPhalanxData data of the phalanx.fleet_appeared and phalanx.fleet_disappeared events
+type EventPhalanxData struct {
+	WatchID int64           `json:"watch_id"`
+	MoonID  int64           `json:"moon_id"`
+	Target  EventCoordinate `json:"target"`
+	Fleet   EventFleetData  `json:"fleet"`
+}
+
+// EventStorageData data of a storage.threshold_reached event
+type EventStorageData struct {
+	CelestialID       int64           `json:"celestial_id"`
+	Coordinate        EventCoordinate `json:"coordinate"`
+	Resource          string          `json:"resource"` // metal, crystal, deuterium
+	Available         int64           `json:"available"`
+	StorageCapacity   int64           `json:"storage_capacity"`
+	ProductionPerHour int64           `json:"production_per_hour"`
+	FillRatio         float64         `json:"fill_ratio"`
+	Threshold         float64         `json:"threshold"`
+	FullAt            *time.Time      `json:"full_at"` // null when the storage never gets full
+}
+
+// EventMilitaryDropData data of a player.military_drop event
+type EventMilitaryDropData struct {
+	PlayerID      int64   `json:"player_id"`
+	PlayerName    string  `json:"player_name"`
+	PreviousScore int64   `json:"previous_score"`
+	Score         int64   `json:"score"`
+	PreviousShips int64   `json:"previous_ships"`
+	Ships         int64   `json:"ships"`
+	Drop          float64 `json:"drop"`
+	Threshold     float64 `json:"threshold"`
+}
+
+// EventConstructionData data of a construction.finished event
+type EventConstructionData struct {
+	CelestialID int64         `json:"celestial_id"` // 0 for researches
+	Object      EventQuantity `json:"object"`       // Nbr is always 0
+	FinishedAt  time.Time     `json:"finished_at"`
+}
+
+// EventMissileDefenseData data of a missile.defense event
+type EventMissileDefenseData struct {
+	CelestialID           int64           `json:"celestial_id"`
+	Coordinate            EventCoordinate `json:"coordinate"`
+	Incoming              int64           `json:"incoming"`
+	AntiBallisticMissiles int64           `json:"anti_ballistic_missiles"`
+	Queued                int64           `json:"queued"`
+	Needed                int64           `json:"needed"`
+	Built                 int64           `json:"built"`
+	Error                 string          `json:"error"` // Empty when the anti-ballistic missiles were checked successfully
+}
+
+// EventServerMovedData data of a server.moved event
+type EventServerMovedData struct {
+	FromURL            string `json:"from_url"`
+	ToURL              string `json:"to_url"`
+	FromUniverse       string `json:"from_universe"`
+	ToUniverse         string `json:"to_universe"`
+	UnmappedCelestials int    `json:"unmapped_celestials"` // Celestials not found at the same coordinates on the new server
+}
+
+// EventRecyclersDispatchedData data of a recyclers.dispatched event
+type EventRecyclersDispatchedData struct {
+	ReportID      int64           `json:"report_id"`
+	Coordinate    EventCoordinate `json:"coordinate"`
+	DebrisMetal   int64           `json:"debris_metal"`
+	DebrisCrystal int64           `json:"debris_crystal"`
+	Needed        int64           `json:"needed"`
+	Sent          int64           `json:"sent"`
+	CelestialID   int64           `json:"celestial_id"` // 0 if no recyclers were sent
+	FleetID       int64           `json:"fleet_id"`
+	Error         string          `json:"error"` // Empty when the recyclers were sent
+}
+
+// EventTaskData data of the task.* events
+type EventTaskData struct {
+	ID         int64    `json:"id"`
+	Priority   int      `json:"priority"`
+	Name       string   `json:"name"` // Empty while enqueued
+	Initiator  string   `json:"initiator"`
+	WaitMs     int64    `json:"wait_ms"`
+	DurationMs int64    `json:"duration_ms"`
+	Errors     []string `json:"errors"`
+	Queued     int64    `json:"queued"`
+}
+
+func newEvent(typ EventType, data interface{}) Event {
+	return Event{SchemaVersion: EventsSchemaVersion, Type: typ, Time: time.Now(), Data: data}
+}
+
+func toEventCoordinate(c Coordinate) EventCoordinate {
+	typ := "planet"
+	if c.IsMoon() {
+		typ = "moon"
+	} else if c.IsDebris() {
+		typ = "debris"
+	}
+	return EventCoordinate{Galaxy: c.Galaxy, System: c.System, Position: c.Position, Type: typ}
+}
+
+func toEventQuantities(ships ShipsInfos) []EventQuantity {
+	res := make([]EventQuantity, 0)
+	for _, q := range ships.ToQuantifiables() {
+		res = append(res, EventQuantity{ID: int64(q.ID), Name: q.ID.String(), Nbr: q.Nbr})
+	}
+	return res
+}
+
+// NewAttackEvent creates an attack.detected event
+func NewAttackEvent(a AttackEvent) Event {
+	data := EventAttackData{
+		ID:              a.ID,
+		Mission:         int64(a.MissionType),
+		MissionName:     a.MissionType.String(),
+		Origin:          toEventCoordinate(a.Origin),
+		Destination:     toEventCoordinate(a.Destination),
+		DestinationName: a.DestinationName,
+		ArrivalTime:     a.ArrivalTime,
+		AttackerID:      a.AttackerID,
+		AttackerName:    a.AttackerName,
+		UnionID:         a.UnionID,
+		Missiles:        a.Missiles,
+	}
+	if a.Ships != nil {
+		data.Ships = toEventQuantities(*a.Ships)
+	}
+	return newEvent(AttackDetectedEvent, data)
+}
+
+func toEventFleetData(f Fleet) EventFleetData {
+	return EventFleetData{
+		ID:           int64(f.ID),
+		Mission:      int64(f.Mission),
+		MissionName:  f.Mission.String(),
+		ReturnFlight: f.ReturnFlight,
+		Origin:       toEventCoordinate(f.Origin),
+		Destination:  toEventCoordinate(f.Destination),
+		Ships:        toEventQuantities(f.Ships),
+		Resources:    EventResources{Metal: f.Resources.Metal, Crystal: f.Resources.Crystal, Deuterium: f.Resources.Deuterium},
+		ArrivalTime:  f.ArrivalTime,
+		BackTime:     f.BackTime,
+	}
+}
+
+// NewFleetEvent creates a fleet.sent event
+func NewFleetEvent(f Fleet) Event {
+	return newEvent(FleetSentEvent, toEventFleetData(f))
+}
+
+// NewBuildEvent creates a build.started event
+func NewBuildEvent(celestialID CelestialID, id ID, nbr int64) Event {
+	return newEvent(BuildStartedEvent, EventBuildData{
+		CelestialID: int64(celestialID),
+		Object:      EventQuantity{ID: int64(id), Name: id.String(), Nbr: nbr},
+	})
+}
+
+// NewChatMessageEvent creates a message.chat event
+func NewChatMessageEvent(m ChatMsg) Event {
+	return newEvent(ChatMessageEvent, EventMessageData{
+		ID:            m.ID,
+		SenderID:      m.SenderID,
+		SenderName:    m.SenderName,
+		AssociationID: m.AssociationID,
+		Text:          m.Text,
+		Time:          time.Unix(m.Date, 0),
+	})
+}
+
+// NewPhalanxFleetEvent creates a phalanx.fleet_appeared or phalanx.fleet_disappeared event
+func NewPhalanxFleetEvent(typ EventType, watchID int64, moonID MoonID, target Coordinate, f Fleet) Event {
+	return newEvent(typ, EventPhalanxData{
+		WatchID: watchID,
+		MoonID:  int64(moonID),
+		Target:  toEventCoordinate(target),
+		Fleet:   toEventFleetData(f),
+	})
+}
+
+// NewStorageEvent creates a storage.threshold_reached event
+func NewStorageEvent(celestialID CelestialID, coord Coordinate, resource string, eta ResourceStorageETA, threshold float64) Event {
+	data := EventStorageData{
+		CelestialID:       int64(celestialID),
+		Coordinate:        toEventCoordinate(coord),
+		Resource:          resource,
+		Available:         eta.Available,
+		StorageCapacity:   eta.StorageCapacity,
+		ProductionPerHour: eta.ProductionPerHour,
+		FillRatio:         eta.FillRatio,
+		Threshold:         threshold,
+	}
+	if !eta.FullAt.IsZero() {
+		fullAt := eta.FullAt
+		data.FullAt = &fullAt
+	}
+	return newEvent(StorageThresholdReachedEvent, data)
+}
+
+// NewMilitaryDropEvent creates a player.military_drop event
+func NewMilitaryDropEvent(previous WatchedPlayer, current HighscorePlayer, drop, threshold float64) Event {
+	return newEvent(MilitaryScoreDropEvent, EventMilitaryDropData{
+		PlayerID:      current.ID,
+		PlayerName:    current.Name,
+		PreviousScore: previous.MilitaryScore,
+		Score:         current.Score,
+		PreviousShips: previous.Ships,
+		Ships:         current.Ships,
+		Drop:          drop,
+		Threshold:     threshold,
+	})
+}
+
+// NewConstructionFinishedEvent creates a construction.finished event, celestialID is 0 for researches
+func NewConstructionFinishedEvent(celestialID CelestialID, id ID, finishedAt time.Time) Event {
+	return newEvent(ConstructionFinishedEvent, EventConstructionData{
+		CelestialID: int64(celestialID),
+		Object:      EventQuantity{ID: int64(id), Name: id.String()},
+		FinishedAt:  finishedAt,
+	})
+}
+
+// NewMissileDefenseEvent creates a missile.defense event
+func NewMissileDefenseEvent(d MissileDefense) Event {
+	data := EventMissileDefenseData{
+		CelestialID:           int64(d.CelestialID),
+		Coordinate:            toEventCoordinate(d.Coordinate),
+		Incoming:              d.Incoming,
+		AntiBallisticMissiles: d.AntiBallisticMissiles,
+		Queued:                d.Queued,
+		Needed:                d.Needed,
+		Built:                 d.Built,
+	}
+	if d.Err != nil {
+		data.Error = d.Err.Error()
+	}
+	return newEvent(MissileDefenseEvent, data)
+}
+
+// NewServerMovedEvent creates a server.moved event
+func NewServerMovedEvent(m UniverseMigration) Event {
+	return newEvent(ServerMovedEvent, EventServerMovedData{
+		FromURL:            m.OldServerURL,
+		ToURL:              m.NewServerURL,
+		FromUniverse:       m.OldServer.Name,
+		ToUniverse:         m.NewServer.Name,
+		UnmappedCelestials: len(m.Unmapped),
+	})
+}
+
+// NewRecyclersDispatchedEvent creates a recyclers.dispatched event
+func NewRecyclersDispatchedEvent(d RecyclersDispatch) Event {
+	data := EventRecyclersDispatchedData{
+		ReportID:      d.ReportID,
+		Coordinate:    toEventCoordinate(d.Coordinate),
+		DebrisMetal:   d.Debris.Metal,
+		DebrisCrystal: d.Debris.Crystal,
+		Needed:        d.Needed,
+		Sent:          d.Sent,
+		CelestialID:   int64(d.CelestialID),
+		FleetID:       int64(d.FleetID),
+	}
+	if d.Err != nil {
+		data.Error = d.Err.Error()
+	}
+	return newEvent(RecyclersDispatchedEvent, data)
+}
+
+// NewTaskEvent creates a task.* event
+func NewTaskEvent(e TaskEvent) Event {
+	data := EventTaskData{
+		ID:         e.ID,
+		Priority:   e.Priority,
+		Name:       e.Name,
+		Initiator:  e.Initiator,
+		WaitMs:     int64(e.Wait / time.Millisecond),
+		DurationMs: int64(e.Duration / time.Millisecond),
+		Errors:     e.Errors,
+		Queued:     e.Queued,
+	}
+	if data.Errors == nil {
+		data.Errors = []string{}
+	}
+	return Event{SchemaVersion: EventsSchemaVersion, Type: e.Type, Time: e.Time, Data: data}
+}
+
+// eventSubscription callback of the events of a type, of all the events if typ is empty
+type eventSubscription struct {
+	id  int64
+	typ EventType
+	clb func(Event)
+}
+
+// Subscribe registers a callback that is called for the emitted events of type typ (eg: AttackDetectedEvent,
+// FleetReturnedEvent, ConstructionFinishedEvent), or for every event if typ is empty, until unsubscribe is called.
+// The events are generated by the background watchers (StartEventsPoller, StartConstructionsWatcher...) and the bot actions.
+func (b *OGame) Subscribe(typ EventType, clb func(Event)) (unsubscribe func()) {
+	b.eventCallbacksMu.Lock()
+	defer b.eventCallbacksMu.Unlock()
+	b.eventSubscriptionID++
+	id := b.eventSubscriptionID
+	b.eventSubscriptions = append(b.eventSubscriptions, eventSubscription{id: id, typ: typ, clb: clb})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.eventCallbacksMu.Lock()
+			defer b.eventCallbacksMu.Unlock()
+			subscriptions := make([]eventSubscription, 0, len(b.eventSubscriptions))
+			for _, s := range b.eventSubscriptions {
+				if s.id != id {
+					subscriptions = append(subscriptions, s)
+				}
+			}
+			b.eventSubscriptions = subscriptions
+		})
+	}
+}
+
+// OnEvent register a callback that is called for every emitted event
+func (b *OGame) OnEvent(clb func(Event)) {
+	b.Subscribe("", clb)
+}
+
+func (b *OGame) emitEvent(e Event) {
+	b.eventCallbacksMu.RLock()
+	subscriptions := b.eventSubscriptions
+	b.eventCallbacksMu.RUnlock()
+	for _, s := range subscriptions {
+		if s.typ == "" || s.typ == e.Type {
+			go s.clb(e)
+		}
+	}
+}
+
+// emitNewAttacks emits an attack.detected event for the attacks that were not seen before
+func (b *OGame) emitNewAttacks(attacks []AttackEvent) {
+	b.eventCallbacksMu.Lock()
+	if b.seenAttacks == nil {
+		b.seenAttacks = make(map[int64]time.Time)
+	}
+	now := time.Now()
+	for id, arrival := range b.seenAttacks {
+		if arrival.Before(now) {
+			delete(b.seenAttacks, id)
+		}
+	}
+	newAttacks := make([]AttackEvent, 0)
+	for _, a := range attacks {
+		if _, ok := b.seenAttacks[a.ID]; !ok {
+			b.seenAttacks[a.ID] = a.ArrivalTime
+			newAttacks = append(newAttacks, a)
+		}
+	}
+	b.eventCallbacksMu.Unlock()
+	for _, a := range newAttacks {
+		b.emitEvent(NewAttackEvent(a))
+	}
+}
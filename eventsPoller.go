@@ -0,0 +1,96 @@
+package ogame
+
+import (
+	"sync"
+	"time"
+)
+
+// Events poller defaults
+const (
+	DefaultEventsPollerMinInterval = 30 * time.Second
+	DefaultEventsPollerMaxInterval = 5 * time.Minute
+)
+
+// diffFleets returns the fleet.departed, fleet.returning and fleet.returned events between two fleets snapshots
+func diffFleets(prev, curr []Fleet) []Event {
+	events := make([]Event, 0)
+	prevByID := make(map[FleetID]Fleet, len(prev))
+	for _, f := range prev {
+		prevByID[f.ID] = f
+	}
+	currByID := make(map[FleetID]struct{}, len(curr))
+	for _, f := range curr {
+		currByID[f.ID] = struct{}{}
+		old, ok := prevByID[f.ID]
+		if !ok {
+			events = append(events, newEvent(FleetDepartedEvent, toEventFleetData(f)))
+		} else if !old.ReturnFlight && f.ReturnFlight {
+			events = append(events, newEvent(FleetReturningEvent, toEventFleetData(f)))
+		}
+	}
+	for _, f := range prev {
+		if _, ok := currByID[f.ID]; !ok {
+			events = append(events, newEvent(FleetReturnedEvent, toEventFleetData(f)))
+		}
+	}
+	return events
+}
+
+// nextPollInterval polls faster while under attack, and wakes up when the next fleet arrives or returns
+func nextPollInterval(attacks []AttackEvent, fleets []Fleet, now time.Time, minInterval, maxInterval time.Duration) time.Duration {
+	if len(attacks) > 0 {
+		return minInterval
+	}
+	interval := maxInterval
+	for _, f := range fleets {
+		for _, t := range []time.Time{f.ArrivalTime, f.BackTime} {
+			if d := t.Sub(now) + time.Second; t.After(now) && d < interval {
+				interval = d
+			}
+		}
+	}
+	if interval < minInterval {
+		interval = minInterval
+	}
+	return interval
+}
+
+// StartEventsPoller polls the events list and the fleets movements until the returned function is called.
+// New attacks emit attack.detected events, own fleets emit fleet.departed, fleet.returning and fleet.returned events.
+// The interval adapts between minInterval (under attack) and maxInterval (nothing happening).
+func (b *OGame) StartEventsPoller(minInterval, maxInterval time.Duration) (stop func()) {
+	if minInterval <= 0 {
+		minInterval = DefaultEventsPollerMinInterval
+	}
+	if maxInterval < minInterval {
+		maxInterval = minInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		var prev []Fleet
+		for {
+			interval := maxInterval
+			if b.isEnabled() && b.IsLoggedIn() {
+				attacks, err := b.WithPriority(Low).GetAttacks()
+				fleets, slots := b.WithPriority(Low).GetFleets()
+				// Slots total is 0 when the movement page could not be loaded, the previous snapshot is kept
+				if err == nil && slots.Total > 0 {
+					if prev != nil {
+						for _, e := range diffFleets(prev, fleets) {
+							b.emitEvent(e)
+						}
+					}
+					prev = fleets
+					interval = nextPollInterval(attacks, fleets, time.Now(), minInterval, maxInterval)
+				}
+			}
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,40 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFleets(t *testing.T) {
+	f1 := Fleet{ID: 1, Mission: Transport}
+	f2 := Fleet{ID: 2, Mission: Attack}
+	f2Returning := f2
+	f2Returning.ReturnFlight = true
+	f3 := Fleet{ID: 3, Mission: Expedition}
+
+	events := diffFleets([]Fleet{f1, f2}, []Fleet{f2Returning, f3})
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, FleetReturningEvent, events[0].Type)
+	assert.Equal(t, int64(2), events[0].Data.(EventFleetData).ID)
+	assert.Equal(t, FleetDepartedEvent, events[1].Type)
+	assert.Equal(t, int64(3), events[1].Data.(EventFleetData).ID)
+	assert.Equal(t, FleetReturnedEvent, events[2].Type)
+	assert.Equal(t, int64(1), events[2].Data.(EventFleetData).ID)
+
+	assert.Equal(t, 0, len(diffFleets([]Fleet{f1}, []Fleet{f1})))
+}
+
+func TestNextPollInterval(t *testing.T) {
+	now := time.Now()
+	minInterval, maxInterval := 30*time.Second, 5*time.Minute
+	assert.Equal(t, maxInterval, nextPollInterval(nil, nil, now, minInterval, maxInterval))
+	assert.Equal(t, minInterval, nextPollInterval([]AttackEvent{{ID: 1}}, nil, now, minInterval, maxInterval))
+	fleets := []Fleet{{ArrivalTime: now.Add(-time.Minute), BackTime: now.Add(2 * time.Minute)}}
+	assert.Equal(t, 2*time.Minute+time.Second, nextPollInterval(nil, fleets, now, minInterval, maxInterval))
+	fleets = []Fleet{{ArrivalTime: now.Add(10 * time.Second)}}
+	assert.Equal(t, minInterval, nextPollInterval(nil, fleets, now, minInterval, maxInterval))
+	fleets = []Fleet{{ArrivalTime: now.Add(time.Hour)}}
+	assert.Equal(t, maxInterval, nextPollInterval(nil, fleets, now, minInterval, maxInterval))
+}
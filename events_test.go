@@ -0,0 +1,72 @@
+package ogame
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAttackEvent_JSON(t *testing.T) {
+	arrival := time.Date(2021, 5, 20, 8, 42, 7, 0, time.UTC)
+	e := NewAttackEvent(AttackEvent{
+		ID:          1,
+		MissionType: Attack,
+		Origin:      Coordinate{1, 2, 3, PlanetType},
+		Destination: Coordinate{4, 5, 6, MoonType},
+		ArrivalTime: arrival,
+		Ships:       &ShipsInfos{LightFighter: 10},
+	})
+	e.Time = arrival
+	by, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"schema_version":1,"type":"attack.detected","time":"2021-05-20T08:42:07Z","data":{
+		"id":1,"mission":1,"mission_name":"Attack",
+		"origin":{"galaxy":1,"system":2,"position":3,"type":"planet"},
+		"destination":{"galaxy":4,"system":5,"position":6,"type":"moon"},
+		"destination_name":"","arrival_time":"2021-05-20T08:42:07Z","attacker_id":0,"attacker_name":"",
+		"union_id":0,"missiles":0,"ships":[{"id":204,"name":"LightFighter","nbr":10}]}}`, string(by))
+}
+
+func TestEmitNewAttacks(t *testing.T) {
+	b := &OGame{}
+	events := make(chan Event, 10)
+	b.OnEvent(func(e Event) { events <- e })
+	future := time.Now().Add(time.Hour)
+	b.emitNewAttacks([]AttackEvent{{ID: 1, ArrivalTime: future}, {ID: 2, ArrivalTime: future}})
+	b.emitNewAttacks([]AttackEvent{{ID: 1, ArrivalTime: future}, {ID: 3, ArrivalTime: future}})
+	ids := make([]int64, 0)
+	for i := 0; i < 3; i++ {
+		e := <-events
+		assert.Equal(t, AttackDetectedEvent, e.Type)
+		ids = append(ids, e.Data.(EventAttackData).ID)
+	}
+	assert.ElementsMatch(t, []int64{1, 2, 3}, ids)
+	select {
+	case <-events:
+		t.Fatal("attack emitted twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	b := &OGame{}
+	attacks := make(chan Event, 10)
+	all := make(chan Event, 10)
+	unsubscribe := b.Subscribe(AttackDetectedEvent, func(e Event) { attacks <- e })
+	b.Subscribe("", func(e Event) { all <- e })
+	b.emitEvent(newEvent(FleetReturnedEvent, nil))
+	b.emitEvent(newEvent(AttackDetectedEvent, nil))
+	assert.Equal(t, AttackDetectedEvent, (<-attacks).Type)
+	assert.Equal(t, 2, len([]Event{<-all, <-all}))
+	unsubscribe()
+	unsubscribe()
+	b.emitEvent(newEvent(AttackDetectedEvent, nil))
+	<-all
+	select {
+	case <-attacks:
+		t.Fatal("event received after unsubscribe")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
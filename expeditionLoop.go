@@ -0,0 +1,313 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Expedition loops defaults
+const (
+	DefaultExpeditionMaxLosses    = 3                // Expeditions in a row that lost ships after which a loop stops
+	expeditionLoopRetryInterval   = 5 * time.Minute  // Wait before retrying a dispatch that failed or lacks ships
+	expeditionLoopReturnMargin    = 10 * time.Second // Wait after the return time before checking the fleet is back
+	expeditionLoopMaxSendFailures = 3                // Failed dispatches in a row after which a loop stops
+	expeditionLoopLoggedOutWait   = time.Minute      // Wait while the bot is logged out or disabled
+)
+
+// expeditionLoopInitiator initiator of the fleets sent by the expedition loops
+const expeditionLoopInitiator = "ExpeditionLoop"
+
+// ExpeditionParams parameters of an expedition dispatch
+type ExpeditionParams struct {
+	Origin      CelestialID
+	Destination Coordinate // Position 16 of a system
+	Ships       ShipsInfos
+	Speed       Speed         // HundredPercent if 0
+	HoldingTime int64         // Hours spent in the expedition, 1 if 0
+	Loop        bool          // Re-send the fleet every time it returns, see GetExpeditionLoops
+	TopUp       bool          // Loop: queue the lost ships in the origin shipyard and wait for them, instead of re-sending the survivors
+	Cooldown    time.Duration // Loop: wait after the fleet returned before re-sending it
+	MaxLosses   int64         // Loop: stop after this many expeditions in a row lost ships, DefaultExpeditionMaxLosses if 0
+}
+
+// ExpeditionLoop expedition fleet re-sent every time it returns
+type ExpeditionLoop struct {
+	ID         int64
+	Params     ExpeditionParams
+	Ships      ShipsInfos // Ships sent, the lost ones are removed unless TopUp
+	FleetID    FleetID    // Fleet in flight, 0 while waiting to re-send it
+	Sent       int64      // Expeditions sent
+	Losses     int64      // Expeditions in a row that lost ships
+	Lost       ShipsInfos // Ships lost since the loop started
+	Running    bool
+	StopReason string `json:",omitempty"`
+	LastError  string `json:",omitempty"`
+	NextSendAt time.Time
+	CreatedAt  time.Time
+}
+
+type expeditionLoop struct {
+	ExpeditionLoop
+	lastSeen     Fleet     // Last snapshot of the fleet in flight, its ships change during the expedition
+	backTime     time.Time // Return time of the fleet in flight
+	sendFailures int64     // Failed dispatches in a row
+	cancel       context.CancelFunc
+}
+
+// lostShips returns the ships of sent missing from returned
+func lostShips(sent, returned ShipsInfos) ShipsInfos {
+	var lost ShipsInfos
+	for _, s := range Ships {
+		if n := sent.ByID(s.GetID()) - returned.ByID(s.GetID()); n > 0 {
+			lost.Set(s.GetID(), n)
+		}
+	}
+	return lost
+}
+
+// nextExpeditionSlotAt returns when an expedition slot frees up, now if one is free
+func nextExpeditionSlotAt(fleets []Fleet, slots Slots, now time.Time) time.Time {
+	if slots.ExpInUse < slots.ExpTotal && slots.InUse < slots.Total {
+		return now
+	}
+	next := time.Time{}
+	for _, f := range fleets {
+		if (slots.InUse >= slots.Total || f.Mission == Expedition) && f.BackTime.After(now) && (next.IsZero() || f.BackTime.Before(next)) {
+			next = f.BackTime
+		}
+	}
+	if next.IsZero() {
+		return now.Add(expeditionLoopRetryInterval)
+	}
+	return next.Add(expeditionLoopReturnMargin)
+}
+
+func (p ExpeditionParams) normalize() (ExpeditionParams, error) {
+	if !p.Destination.IsPlanet() || p.Destination.Position != 16 {
+		return p, errors.New("invalid expedition destination " + p.Destination.String())
+	}
+	if p.Speed == 0 {
+		p.Speed = HundredPercent
+	}
+	if p.HoldingTime == 0 {
+		p.HoldingTime = 1
+	}
+	if p.MaxLosses <= 0 {
+		p.MaxLosses = DefaultExpeditionMaxLosses
+	}
+	return p, nil
+}
+
+// SendExpedition sends an expedition fleet. With params.Loop the fleet is re-sent every time it returns until the loop
+// is stopped, waiting for a free expedition slot and for the cooldown. The lost ships are queued in the origin shipyard
+// with params.TopUp, otherwise the survivors are re-sent. The loop stops by itself once params.MaxLosses expeditions
+// in a row lost ships, or when no ship is left.
+func (b *OGame) SendExpedition(params ExpeditionParams) (Fleet, error) {
+	params, err := params.normalize()
+	if err != nil {
+		return Fleet{}, err
+	}
+	fleet, err := b.WithPriority(Normal).SetInitiator(expeditionLoopInitiator).SendFleet(params.Origin,
+		params.Ships.ToQuantifiables(), params.Speed, params.Destination, Expedition, Resources{}, params.HoldingTime, 0)
+	if err != nil || !params.Loop {
+		return fleet, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.expeditionLoopsMu.Lock()
+	if b.expeditionLoops == nil {
+		b.expeditionLoops = make(map[int64]*expeditionLoop)
+	}
+	b.expeditionLoopNextID++
+	l := &expeditionLoop{cancel: cancel}
+	l.ID = b.expeditionLoopNextID
+	l.Params = params
+	l.Ships = params.Ships
+	if fleet.Ships.HasShips() { // Quantities of AllAvailable ships
+		l.Ships = fleet.Ships
+	}
+	l.FleetID = fleet.ID
+	l.Sent = 1
+	l.Running = true
+	l.CreatedAt = time.Now()
+	l.lastSeen = fleet
+	l.backTime = fleet.BackTime
+	b.expeditionLoops[l.ID] = l
+	b.expeditionLoopsMu.Unlock()
+	go b.runExpeditionLoop(ctx, l)
+	return fleet, nil
+}
+
+// GetExpeditionLoops returns the expedition loops sorted by id, running and stopped
+func (b *OGame) GetExpeditionLoops() []ExpeditionLoop {
+	b.expeditionLoopsMu.RLock()
+	defer b.expeditionLoopsMu.RUnlock()
+	res := make([]ExpeditionLoop, 0, len(b.expeditionLoops))
+	for _, l := range b.expeditionLoops {
+		res = append(res, l.ExpeditionLoop)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// StopExpeditionLoop stops and removes an expedition loop, the fleet in flight is not recalled
+func (b *OGame) StopExpeditionLoop(id int64) error {
+	b.expeditionLoopsMu.Lock()
+	defer b.expeditionLoopsMu.Unlock()
+	l, ok := b.expeditionLoops[id]
+	if !ok {
+		return errors.New("expedition loop not found")
+	}
+	l.cancel()
+	delete(b.expeditionLoops, id)
+	return nil
+}
+
+// updateExpeditionLoop applies fn to the loop under the lock
+func (b *OGame) updateExpeditionLoop(l *expeditionLoop, fn func(l *expeditionLoop)) {
+	b.expeditionLoopsMu.Lock()
+	defer b.expeditionLoopsMu.Unlock()
+	fn(l)
+}
+
+func (b *OGame) runExpeditionLoop(ctx context.Context, l *expeditionLoop) {
+	defer l.cancel()
+	for {
+		wait := expeditionLoopLoggedOutWait
+		if b.isEnabled() && b.IsLoggedIn() {
+			var stop bool
+			wait, stop = b.expeditionLoopStep(ctx, l)
+			if stop {
+				var reason string
+				b.updateExpeditionLoop(l, func(l *expeditionLoop) {
+					l.Running = false
+					reason = l.StopReason
+				})
+				b.info("expedition loop " + strconv.FormatInt(l.ID, 10) + " stopped: " + reason)
+				return
+			}
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// expeditionLoopStep waits for the fleet in flight to return, or re-sends it. Returns the time to wait before the next
+// step, or true when the loop must stop.
+func (b *OGame) expeditionLoopStep(ctx context.Context, l *expeditionLoop) (time.Duration, bool) {
+	b.expeditionLoopsMu.RLock()
+	loop, lastSeen, backTime := l.ExpeditionLoop, l.lastSeen, l.backTime
+	b.expeditionLoopsMu.RUnlock()
+	p := loop.Params
+	now := time.Now()
+
+	if loop.FleetID != 0 {
+		fleets, slots := b.WithPriority(Normal).GetFleets()
+		if slots.Total == 0 { // Movement page not loaded
+			return expeditionLoopRetryInterval, false
+		}
+		for _, f := range fleets {
+			if f.ID == loop.FleetID {
+				b.updateExpeditionLoop(l, func(l *expeditionLoop) {
+					l.lastSeen = f
+					l.backTime = f.BackTime
+				})
+				if !f.ReturnFlight {
+					return time.Until(f.ArrivalTime.Add(time.Duration(p.HoldingTime)*time.Hour)) + expeditionLoopReturnMargin, false
+				}
+				return time.Until(f.BackTime) + expeditionLoopReturnMargin, false
+			}
+		}
+		// The fleet is back, or was destroyed when it disappeared before its return time
+		returned := lastSeen.Ships
+		if backTime.After(now.Add(time.Minute)) {
+			returned = ShipsInfos{}
+		}
+		lost := lostShips(loop.Ships, returned)
+		stop := false
+		b.updateExpeditionLoop(l, func(l *expeditionLoop) {
+			l.FleetID = 0
+			l.lastSeen = Fleet{}
+			l.backTime = time.Time{}
+			l.NextSendAt = now.Add(p.Cooldown)
+			if !lost.HasShips() {
+				l.Losses = 0
+				return
+			}
+			l.Losses++
+			l.Lost.Add(lost)
+			if !p.TopUp {
+				l.Ships = lostShips(l.Ships, lost)
+			}
+			if l.Losses >= p.MaxLosses {
+				l.StopReason = strconv.FormatInt(l.Losses, 10) + " expeditions in a row lost ships"
+				stop = true
+			} else if !l.Ships.HasFlyableShips() {
+				l.StopReason = "no ships left"
+				stop = true
+			}
+		})
+		if stop {
+			return 0, true
+		}
+		if p.TopUp && lost.HasShips() {
+			for _, q := range lost.ToQuantifiables() {
+				if _, err := b.QueueShips(ctx, p.Origin, q.ID, q.Nbr); err != nil {
+					b.warn("expedition loop ", loop.ID, " failed to queue ", q.Nbr, " ", q.ID, ": ", err)
+				}
+			}
+		}
+		return p.Cooldown, false
+	}
+
+	if wait := time.Until(loop.NextSendAt); wait > 0 {
+		return wait, false
+	}
+	fleets, slots := b.WithPriority(Normal).GetFleets()
+	if next := nextExpeditionSlotAt(fleets, slots, now); next.After(now) {
+		return time.Until(next), false
+	}
+	available, err := b.WithPriority(Normal).GetShips(p.Origin)
+	if err != nil {
+		return b.expeditionLoopFailed(l, err)
+	}
+	if !available.Has(loop.Ships) {
+		// The topped up ships are not built yet, or the ships are used by another fleet
+		return expeditionLoopRetryInterval, false
+	}
+	fleet, err := b.WithPriority(Normal).SetInitiator(expeditionLoopInitiator).SendFleet(p.Origin,
+		loop.Ships.ToQuantifiables(), p.Speed, p.Destination, Expedition, Resources{}, p.HoldingTime, 0)
+	if err == ErrAllSlotsInUse {
+		return expeditionLoopRetryInterval, false
+	} else if err != nil {
+		return b.expeditionLoopFailed(l, err)
+	}
+	b.updateExpeditionLoop(l, func(l *expeditionLoop) {
+		l.FleetID = fleet.ID
+		l.lastSeen = fleet
+		l.backTime = fleet.BackTime
+		l.Sent++
+		l.LastError = ""
+		l.sendFailures = 0
+	})
+	return time.Until(fleet.ArrivalTime) + expeditionLoopReturnMargin, false
+}
+
+// expeditionLoopFailed records a failed dispatch, the loop stops after expeditionLoopMaxSendFailures in a row
+func (b *OGame) expeditionLoopFailed(l *expeditionLoop, err error) (time.Duration, bool) {
+	stop := false
+	b.updateExpeditionLoop(l, func(l *expeditionLoop) {
+		l.sendFailures++
+		l.LastError = err.Error()
+		if l.sendFailures >= expeditionLoopMaxSendFailures {
+			l.StopReason = "dispatch failed: " + err.Error()
+			stop = true
+		}
+	})
+	return expeditionLoopRetryInterval, stop
+}
@@ -0,0 +1,61 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLostShips(t *testing.T) {
+	sent := ShipsInfos{LargeCargo: 100, EspionageProbe: 1, Pathfinder: 2}
+	assert.Equal(t, ShipsInfos{}, lostShips(sent, sent))
+	// Ships found during the expedition are not losses
+	assert.Equal(t, ShipsInfos{}, lostShips(sent, ShipsInfos{LargeCargo: 100, EspionageProbe: 1, Pathfinder: 2, LightFighter: 5}))
+	assert.Equal(t, ShipsInfos{LargeCargo: 30, Pathfinder: 2}, lostShips(sent, ShipsInfos{LargeCargo: 70, EspionageProbe: 1}))
+	assert.Equal(t, sent, lostShips(sent, ShipsInfos{}))
+}
+
+func TestNextExpeditionSlotAt(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	fleets := []Fleet{
+		{Mission: Transport, BackTime: now.Add(10 * time.Minute)},
+		{Mission: Expedition, BackTime: now.Add(2 * time.Hour)},
+		{Mission: Expedition, BackTime: now.Add(time.Hour)},
+	}
+	assert.Equal(t, now, nextExpeditionSlotAt(fleets, Slots{InUse: 3, Total: 10, ExpInUse: 2, ExpTotal: 3}, now))
+	// The first expedition back frees an expedition slot
+	assert.Equal(t, now.Add(time.Hour+expeditionLoopReturnMargin),
+		nextExpeditionSlotAt(fleets, Slots{InUse: 3, Total: 10, ExpInUse: 2, ExpTotal: 2}, now))
+	// Every slot is used, the first fleet back frees one
+	assert.Equal(t, now.Add(10*time.Minute+expeditionLoopReturnMargin),
+		nextExpeditionSlotAt(fleets, Slots{InUse: 3, Total: 3, ExpInUse: 2, ExpTotal: 3}, now))
+	assert.Equal(t, now.Add(expeditionLoopRetryInterval), nextExpeditionSlotAt(nil, Slots{ExpInUse: 1, ExpTotal: 1, Total: 5}, now))
+}
+
+func TestExpeditionParams_normalize(t *testing.T) {
+	p, err := ExpeditionParams{Destination: Coordinate{1, 2, 16, PlanetType}, Ships: ShipsInfos{LargeCargo: 10}}.normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, HundredPercent, p.Speed)
+	assert.Equal(t, int64(1), p.HoldingTime)
+	assert.Equal(t, int64(DefaultExpeditionMaxLosses), p.MaxLosses)
+
+	_, err = ExpeditionParams{Destination: Coordinate{1, 2, 15, PlanetType}}.normalize()
+	assert.Error(t, err)
+}
+
+func TestStopExpeditionLoop(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	canceled := false
+	b.expeditionLoops = map[int64]*expeditionLoop{
+		2: {ExpeditionLoop: ExpeditionLoop{ID: 2}, cancel: func() { canceled = true }},
+		1: {ExpeditionLoop: ExpeditionLoop{ID: 1}, cancel: func() {}},
+	}
+	loops := b.GetExpeditionLoops()
+	assert.Equal(t, 2, len(loops))
+	assert.Equal(t, int64(1), loops[0].ID)
+	assert.NoError(t, b.StopExpeditionLoop(2))
+	assert.True(t, canceled)
+	assert.Equal(t, 1, len(b.GetExpeditionLoops()))
+	assert.Error(t, b.StopExpeditionLoop(2))
+}
@@ -0,0 +1,267 @@
+package ogame
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExpeditionOutcome outcome of an expedition
+type ExpeditionOutcome string
+
+// Expedition outcomes
+const (
+	ExpeditionResources  ExpeditionOutcome = "resources"
+	ExpeditionShips      ExpeditionOutcome = "ships"
+	ExpeditionDarkMatter ExpeditionOutcome = "darkMatter"
+	ExpeditionItem       ExpeditionOutcome = "item"
+	ExpeditionPirates    ExpeditionOutcome = "pirates"
+	ExpeditionAliens     ExpeditionOutcome = "aliens"
+	ExpeditionFight      ExpeditionOutcome = "fight" // Combat report found but pirates or aliens not recognized
+	ExpeditionBlackHole  ExpeditionOutcome = "blackHole"
+	ExpeditionNothing    ExpeditionOutcome = "nothing" // Includes the delayed and early returns
+)
+
+// ExpeditionResult outcome parsed from an expedition message
+type ExpeditionResult struct {
+	ID         int64
+	Coordinate Coordinate
+	CreatedAt  time.Time
+	Outcome    ExpeditionOutcome
+	Resources  Resources
+	DarkMatter int64
+	Ships      ShipsInfos // Ships found
+	Losses     int64      // Units lost in the fight, from the combat report
+}
+
+var (
+	expeditionParagraphRgx = regexp.MustCompile(`<br\s*/?>\s*<br\s*/?>`)
+	expeditionLineRgx      = regexp.MustCompile(`<br\s*/?>`)
+	expeditionShipRgx      = regexp.MustCompile(`^([^<>:]+):\s*([\d.,]+)$`)
+	expeditionAmountRgx    = regexp.MustCompile(`^[\d.,]*\d`)
+)
+
+// expeditionKeywords words of the expedition messages that tell the outcomes without amount, per language.
+// The outcomes are checked in order.
+var expeditionKeywords = []struct {
+	outcome ExpeditionOutcome
+	words   []string
+}{
+	{ExpeditionBlackHole, []string{"black hole", "schwarzes loch", "trou noir", "agujero negro", "buco nero", "zwart gat",
+		"czarna dziura", "buraco negro", "черная дыра", "чёрная дыра"}},
+	{ExpeditionItem, []string{"item", "gegenstand", "objet", "objeto", "oggetto", "przedmiot", "предмет"}},
+	{ExpeditionAliens, []string{"alien", "unknown species", "exotic", "außerirdisch", "fremde spezies", "extraterrestre",
+		"extraterrestri", "obcy", "obcych", "инопланет", "пришельц"}},
+	{ExpeditionPirates, []string{"pirat", "barbar", "bárbar", "piraci", "piratów", "пират", "варвар"}},
+}
+
+// darkMatterNames names of the dark matter normalized by normalizeName, per language
+var darkMatterNames = map[string]bool{
+	"darkmatter": true, "dunklematerie": true, "matierenoire": true, "materiaoscura": true, "materianegra": true,
+	"materiaescura": true, "donkerematerie": true, "ciemnamateria": true, "temnahmota": true, "mrktstof": true,
+	"morkmaterie": true, "karanlkmadde": true, "темнаяматерия": true,
+}
+
+// expeditionGain returns the resource (1 metal, 2 crystal, 3 deuterium, 4 dark matter) and the amount found in the
+// result line of an expedition message. The name is next to the amount in every language, before or after it.
+func expeditionGain(line string) (resource, amount int64) {
+	words := strings.Fields(line)
+	for i, word := range words {
+		if !expeditionAmountRgx.MatchString(word) {
+			continue
+		}
+		candidates := make([]string, 0, 4)
+		if i >= 2 {
+			candidates = append(candidates, words[i-2]+words[i-1])
+		}
+		if i >= 1 {
+			candidates = append(candidates, words[i-1])
+		}
+		if i+2 < len(words) {
+			candidates = append(candidates, words[i+1]+words[i+2])
+		}
+		if i+1 < len(words) {
+			candidates = append(candidates, words[i+1])
+		}
+		for _, candidate := range candidates {
+			if darkMatterNames[normalizeName(candidate)] {
+				return 4, ParseInt(strings.TrimRight(word, ".,"))
+			}
+			if resource := marketplaceResourceType(candidate); resource != 0 {
+				return resource, ParseInt(strings.TrimRight(word, ".,"))
+			}
+		}
+	}
+	return 0, 0
+}
+
+// ParseExpeditionMessage parses the outcome of an expedition message. The resources, dark matter and ships found are
+// read from the result lines with the localized names, the other outcomes from per-language keywords.
+func ParseExpeditionMessage(msg ExpeditionMessage) ExpeditionResult {
+	res := ExpeditionResult{ID: msg.ID, Coordinate: msg.Coordinate, CreatedAt: msg.CreatedAt, Outcome: ExpeditionNothing}
+	paragraphs := expeditionParagraphRgx.Split(msg.Content, -1)
+	if len(paragraphs) > 1 {
+		result := paragraphs[len(paragraphs)-1]
+		for _, line := range expeditionLineRgx.Split(result, -1) {
+			if m := expeditionShipRgx.FindStringSubmatch(strings.TrimSpace(line)); len(m) == 3 {
+				if shipID := ShipName2ID(m[1]); shipID.IsShip() {
+					res.Outcome = ExpeditionShips
+					res.Ships.AddShips(shipID, ParseInt(m[2]))
+				}
+			}
+		}
+		if res.Outcome == ExpeditionShips {
+			return res
+		}
+		switch resource, amount := expeditionGain(result); resource {
+		case 1:
+			res.Outcome, res.Resources.Metal = ExpeditionResources, amount
+			return res
+		case 2:
+			res.Outcome, res.Resources.Crystal = ExpeditionResources, amount
+			return res
+		case 3:
+			res.Outcome, res.Resources.Deuterium = ExpeditionResources, amount
+			return res
+		case 4:
+			res.Outcome, res.DarkMatter = ExpeditionDarkMatter, amount
+			return res
+		}
+	}
+	lower := strings.ToLower(msg.Content)
+	for _, k := range expeditionKeywords {
+		for _, word := range k.words {
+			if strings.Contains(lower, word) {
+				res.Outcome = k.outcome
+				return res
+			}
+		}
+	}
+	return res
+}
+
+// wallClock returns the date and time of t read in UTC, the combat reports dates are parsed without location
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// addExpeditionLosses sets the units lost in the fights of the expeditions from the combat reports of their
+// coordinates. The side of the bot is the one named playerName, the attacker if none is.
+// A fight whose message is not recognized gets the ExpeditionFight outcome.
+func addExpeditionLosses(results []ExpeditionResult, reports []CombatReportSummary, playerName string) {
+	used := make(map[int64]bool)
+	for i := range results {
+		r := &results[i]
+		for _, report := range reports {
+			d := report.Destination
+			if used[report.ID] || d.Galaxy != r.Coordinate.Galaxy || d.System != r.Coordinate.System || d.Position != r.Coordinate.Position {
+				continue
+			}
+			diff := wallClock(report.CreatedAt).Sub(wallClock(r.CreatedAt))
+			if diff < -time.Minute || diff > time.Minute {
+				continue
+			}
+			used[report.ID] = true
+			r.Losses = report.AttackerLosses
+			if playerName != "" && report.DefenderName == playerName {
+				r.Losses = report.DefenderLosses
+			}
+			if r.Outcome != ExpeditionPirates && r.Outcome != ExpeditionAliens {
+				r.Outcome = ExpeditionFight
+			}
+			break
+		}
+	}
+}
+
+// ExpeditionStats aggregated outcomes of the expeditions of a day or a slot
+type ExpeditionStats struct {
+	Key           string // Day (2006-01-02) or slot coordinate, empty for the total
+	Count         int64
+	Outcomes      map[ExpeditionOutcome]int64
+	Resources     Resources
+	DarkMatter    int64
+	Ships         ShipsInfos
+	Pirates       int64 // Fights against pirates
+	Aliens        int64 // Fights against aliens
+	PiratesLosses int64 // Units lost against pirates
+	AliensLosses  int64 // Units lost against aliens
+	Losses        int64 // Units lost in all the fights
+}
+
+func (s *ExpeditionStats) add(r ExpeditionResult) {
+	if s.Outcomes == nil {
+		s.Outcomes = make(map[ExpeditionOutcome]int64)
+	}
+	s.Count++
+	s.Outcomes[r.Outcome]++
+	s.Resources = s.Resources.Add(r.Resources)
+	s.DarkMatter += r.DarkMatter
+	s.Ships.Add(r.Ships)
+	s.Losses += r.Losses
+	switch r.Outcome {
+	case ExpeditionPirates:
+		s.Pirates++
+		s.PiratesLosses += r.Losses
+	case ExpeditionAliens:
+		s.Aliens++
+		s.AliensLosses += r.Losses
+	}
+}
+
+// ExpeditionStatsReport expedition statistics per day, per slot (expedition coordinate) and in total
+type ExpeditionStatsReport struct {
+	Days  []ExpeditionStats
+	Slots []ExpeditionStats
+	Total ExpeditionStats
+}
+
+// expeditionStats aggregates the results of the expeditions that happened after since
+func expeditionStats(results []ExpeditionResult, since time.Time) ExpeditionStatsReport {
+	days := make(map[string]*ExpeditionStats)
+	slots := make(map[string]*ExpeditionStats)
+	res := ExpeditionStatsReport{Days: make([]ExpeditionStats, 0), Slots: make([]ExpeditionStats, 0)}
+	for _, r := range results {
+		if r.CreatedAt.Before(since) {
+			continue
+		}
+		day := r.CreatedAt.Format("2006-01-02")
+		if _, ok := days[day]; !ok {
+			days[day] = &ExpeditionStats{Key: day}
+		}
+		days[day].add(r)
+		slot := r.Coordinate.String()
+		if _, ok := slots[slot]; !ok {
+			slots[slot] = &ExpeditionStats{Key: slot}
+		}
+		slots[slot].add(r)
+		res.Total.add(r)
+	}
+	for _, s := range days {
+		res.Days = append(res.Days, *s)
+	}
+	for _, s := range slots {
+		res.Slots = append(res.Slots, *s)
+	}
+	sort.Slice(res.Days, func(i, j int) bool { return res.Days[i].Key < res.Days[j].Key })
+	sort.Slice(res.Slots, func(i, j int) bool { return res.Slots[i].Key < res.Slots[j].Key })
+	return res
+}
+
+func (b *OGame) getExpeditionStats(since time.Time) (ExpeditionStatsReport, error) {
+	msgs, err := b.getExpeditionMessages()
+	if err != nil {
+		return ExpeditionStatsReport{}, err
+	}
+	reports, err := b.getCombatReportMessages()
+	if err != nil {
+		return ExpeditionStatsReport{}, err
+	}
+	results := make([]ExpeditionResult, 0, len(msgs))
+	for _, msg := range msgs {
+		results = append(results, ParseExpeditionMessage(msg))
+	}
+	addExpeditionLosses(results, reports, b.Player.PlayerName)
+	return expeditionStats(results, since), nil
+}
@@ -0,0 +1,95 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpeditionMessage(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7.2/en/expedition_messages.html")
+	msgs, _, _ := NewExtractorV7().ExtractExpeditionMessages(pageHTMLBytes, time.FixedZone("OGT", 3600))
+	results := make([]ExpeditionResult, 0)
+	for _, msg := range msgs {
+		results = append(results, ParseExpeditionMessage(msg))
+	}
+	assert.Equal(t, ExpeditionShips, results[0].Outcome)
+	assert.Equal(t, ShipsInfos{EspionageProbe: 1880, LightFighter: 161, SmallCargo: 156}, results[0].Ships)
+	assert.Equal(t, ExpeditionResources, results[1].Outcome)
+	assert.Equal(t, Resources{Metal: 900000}, results[1].Resources)
+	assert.Equal(t, ExpeditionNothing, results[2].Outcome)
+	assert.Equal(t, ExpeditionPirates, results[3].Outcome)
+	assert.Equal(t, ExpeditionPirates, results[5].Outcome)
+	assert.Equal(t, ExpeditionDarkMatter, results[6].Outcome)
+	assert.Equal(t, int64(371), results[6].DarkMatter)
+	assert.Equal(t, ExpeditionNothing, results[7].Outcome)
+	assert.Equal(t, ExpeditionPirates, results[9].Outcome)
+}
+
+func TestParseExpeditionMessage_Languages(t *testing.T) {
+	res := ParseExpeditionMessage(ExpeditionMessage{Content: `Votre expédition a trouvé un petit astéroïde.<br/><br/>Métal 900.000 ont été récupérés.`})
+	assert.Equal(t, ExpeditionResources, res.Outcome)
+	assert.Equal(t, Resources{Metal: 900000}, res.Resources)
+	res = ParseExpeditionMessage(ExpeditionMessage{Content: `Die Expedition hat ein Asteroid gefunden.<br/><br/>Es wurden 1.200 Kristall erbeutet.`})
+	assert.Equal(t, Resources{Crystal: 1200}, res.Resources)
+	res = ParseExpeditionMessage(ExpeditionMessage{Content: `Die Expedition folgte einigen Signalen.<br/><br/>Dunkle Materie 371 wurde erbeutet.`})
+	assert.Equal(t, ExpeditionDarkMatter, res.Outcome)
+	assert.Equal(t, int64(371), res.DarkMatter)
+	res = ParseExpeditionMessage(ExpeditionMessage{Content: `Nous avons trouvé une station abandonnée.<br/><br/>Les vaisseaux suivants ont rejoint la flotte :<br/>Chasseur léger : 149<br/>Grand transporteur : 50`})
+	assert.Equal(t, ExpeditionShips, res.Outcome)
+	assert.Equal(t, ShipsInfos{LightFighter: 149, LargeCargo: 50}, res.Ships)
+	res = ParseExpeditionMessage(ExpeditionMessage{Content: `Einige Piraten haben versucht, unsere Flotte zu kapern.`})
+	assert.Equal(t, ExpeditionPirates, res.Outcome)
+}
+
+func TestAddExpeditionLosses(t *testing.T) {
+	at := time.Date(2020, 4, 20, 10, 0, 0, 0, time.FixedZone("OGT", 3600))
+	results := []ExpeditionResult{
+		{ID: 1, Coordinate: Coordinate{1, 8, 16, PlanetType}, CreatedAt: at, Outcome: ExpeditionPirates},
+		{ID: 2, Coordinate: Coordinate{1, 9, 16, PlanetType}, CreatedAt: at, Outcome: ExpeditionNothing},
+		{ID: 3, Coordinate: Coordinate{1, 8, 16, PlanetType}, CreatedAt: at.Add(time.Hour), Outcome: ExpeditionResources},
+	}
+	reports := []CombatReportSummary{
+		{ID: 10, Destination: Coordinate{1, 8, 16, PlanetType}, CreatedAt: time.Date(2020, 4, 20, 10, 0, 1, 0, time.UTC),
+			AttackerName: "Bob", AttackerLosses: 12000, DefenderName: "Pirates", DefenderLosses: 3000},
+		{ID: 11, Destination: Coordinate{1, 9, 16, PlanetType}, CreatedAt: time.Date(2020, 4, 20, 10, 0, 0, 0, time.UTC),
+			AttackerName: "Aliens", AttackerLosses: 500, DefenderName: "Bob", DefenderLosses: 8000},
+	}
+	addExpeditionLosses(results, reports, "Bob")
+	assert.Equal(t, int64(12000), results[0].Losses)
+	assert.Equal(t, ExpeditionPirates, results[0].Outcome)
+	assert.Equal(t, int64(8000), results[1].Losses)
+	assert.Equal(t, ExpeditionFight, results[1].Outcome)
+	assert.Equal(t, int64(0), results[2].Losses)
+	stats := expeditionStats(results, time.Time{})
+	assert.Equal(t, int64(12000), stats.Total.PiratesLosses)
+	assert.Equal(t, int64(20000), stats.Total.Losses)
+}
+
+func TestExpeditionStats(t *testing.T) {
+	day1 := time.Date(2020, 4, 20, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2020, 4, 21, 10, 0, 0, 0, time.UTC)
+	results := []ExpeditionResult{
+		{Coordinate: Coordinate{1, 8, 16, PlanetType}, CreatedAt: day1.Add(-48 * time.Hour), Outcome: ExpeditionResources, Resources: Resources{Metal: 1}},
+		{Coordinate: Coordinate{1, 8, 16, PlanetType}, CreatedAt: day1, Outcome: ExpeditionResources, Resources: Resources{Metal: 1000}},
+		{Coordinate: Coordinate{1, 8, 16, PlanetType}, CreatedAt: day2, Outcome: ExpeditionPirates},
+		{Coordinate: Coordinate{1, 9, 16, PlanetType}, CreatedAt: day2, Outcome: ExpeditionShips, Ships: ShipsInfos{SmallCargo: 5}},
+		{Coordinate: Coordinate{1, 9, 16, PlanetType}, CreatedAt: day2, Outcome: ExpeditionDarkMatter, DarkMatter: 300},
+	}
+	stats := expeditionStats(results, day1.Add(-time.Hour))
+	assert.Equal(t, int64(4), stats.Total.Count)
+	assert.Equal(t, Resources{Metal: 1000}, stats.Total.Resources)
+	assert.Equal(t, int64(300), stats.Total.DarkMatter)
+	assert.Equal(t, int64(1), stats.Total.Pirates)
+	assert.Equal(t, int64(5), stats.Total.Ships.SmallCargo)
+	assert.Equal(t, 2, len(stats.Days))
+	assert.Equal(t, "2020-04-20", stats.Days[0].Key)
+	assert.Equal(t, int64(1), stats.Days[0].Count)
+	assert.Equal(t, int64(3), stats.Days[1].Count)
+	assert.Equal(t, 2, len(stats.Slots))
+	assert.Equal(t, "[P:1:8:16]", stats.Slots[0].Key)
+	assert.Equal(t, int64(2), stats.Slots[0].Count)
+	assert.Equal(t, int64(1), stats.Slots[1].Outcomes[ExpeditionDarkMatter])
+}
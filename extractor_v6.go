@@ -251,6 +251,12 @@ func (e ExtractorV6) ExtractCombatReportMessagesSummary(pageHTML []byte) ([]Comb
 	return e.ExtractCombatReportMessagesFromDoc(doc)
 }
 
+// ExtractMessages ...
+func (e ExtractorV6) ExtractMessages(pageHTML []byte, location *time.Location) ([]Message, int64) {
+	doc, _ := goquery.NewDocumentFromReader(bytes.NewReader(pageHTML))
+	return e.ExtractMessagesFromDoc(doc, location)
+}
+
 // ExtractEspionageReport ...
 func (e ExtractorV6) ExtractEspionageReport(pageHTML []byte, location *time.Location) (EspionageReport, error) {
 	doc, _ := goquery.NewDocumentFromReader(bytes.NewReader(pageHTML))
@@ -485,6 +491,11 @@ func (e ExtractorV6) ExtractCombatReportMessagesFromDoc(doc *goquery.Document) (
 	return extractCombatReportMessagesFromDocV6(doc)
 }
 
+// ExtractMessagesFromDoc ...
+func (e ExtractorV6) ExtractMessagesFromDoc(doc *goquery.Document, location *time.Location) ([]Message, int64) {
+	return extractMessagesFromDocV6(doc, location)
+}
+
 // ExtractEspionageReportFromDoc ...
 func (e ExtractorV6) ExtractEspionageReportFromDoc(doc *goquery.Document, location *time.Location) (EspionageReport, error) {
 	return extractEspionageReportFromDocV6(doc, location)
@@ -651,13 +651,19 @@ func extractEspionageReportMessageIDsFromDocV6(doc *goquery.Document) ([]Espiona
 				if spanLink.Find("figure").HasClass("moon") {
 					report.Target.Type = MoonType
 				}
+				counterEspionageRgx := regexp.MustCompile(`(\d+)%`)
 				if messageType == Report {
 					s.Find("div.compacting").Each(func(i int, s *goquery.Selection) {
 						if regexp.MustCompile(`%`).MatchString(s.Text()) {
 							report.LootPercentage, _ = strconv.ParseFloat(regexp.MustCompile(`: (\d+)%`).FindStringSubmatch(s.Text())[1], 64)
 							report.LootPercentage /= 100
+							if m := counterEspionageRgx.FindStringSubmatch(s.Find("span.fright").Text()); len(m) == 2 {
+								report.CounterEspionage, _ = strconv.ParseInt(m[1], 10, 64)
+							}
 						}
 					})
+				} else if m := counterEspionageRgx.FindStringSubmatch(s.Find("span.espionageDefText").Text()); len(m) == 2 {
+					report.CounterEspionage, _ = strconv.ParseInt(m[1], 10, 64)
 				}
 				msgs = append(msgs, report)
 
@@ -720,6 +726,36 @@ func extractCombatReportMessagesFromDocV6(doc *goquery.Document) ([]CombatReport
 	return msgs, nbPage
 }
 
+func extractMessagesFromDocV6(doc *goquery.Document, location *time.Location) ([]Message, int64) {
+	msgs := make([]Message, 0)
+	nbPage, _ := strconv.ParseInt(doc.Find("ul.pagination li").Last().AttrOr("data-page", "1"), 10, 64)
+	tabID, _ := strconv.ParseInt(doc.Find("ul.pagination li").Last().AttrOr("data-tab", "0"), 10, 64)
+	coordRgx := regexp.MustCompile(`\[(\d+):(\d+):(\d+)]`)
+	doc.Find("li.msg").Each(func(i int, s *goquery.Selection) {
+		if idStr, exists := s.Attr("data-msg-id"); exists {
+			if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
+				msg := Message{ID: id, TabID: tabID, Coordinates: make([]Coordinate, 0)}
+				msg.Title = strings.Join(strings.Fields(s.Find("span.msg_title").Text()), " ")
+				msg.Sender = strings.TrimSpace(s.Find("span.msg_sender").Text())
+				msg.Content = strings.Join(strings.Fields(s.Find("span.msg_content").Text()), " ")
+				msg.CreatedAt, _ = time.ParseInLocation("02.01.2006 15:04:05", strings.TrimSpace(s.Find(".msg_date").Text()), location)
+				for _, m := range coordRgx.FindAllStringSubmatch(msg.Title+" "+msg.Content, -1) {
+					coord := Coordinate{Type: PlanetType}
+					coord.Galaxy, _ = strconv.ParseInt(m[1], 10, 64)
+					coord.System, _ = strconv.ParseInt(m[2], 10, 64)
+					coord.Position, _ = strconv.ParseInt(m[3], 10, 64)
+					msg.Coordinates = append(msg.Coordinates, coord)
+				}
+				if len(msg.Coordinates) > 0 && s.Find("span.msg_title figure").HasClass("moon") {
+					msg.Coordinates[0].Type = MoonType
+				}
+				msgs = append(msgs, msg)
+			}
+		}
+	})
+	return msgs, nbPage
+}
+
 func extractEspionageReportFromDocV6(doc *goquery.Document, location *time.Location) (EspionageReport, error) {
 	report := EspionageReport{}
 	report.ID, _ = strconv.ParseInt(doc.Find("div.detail_msg").AttrOr("data-msg-id", "0"), 10, 64)
@@ -1070,12 +1106,8 @@ func extractResourceSettingsFromDocV6(doc *goquery.Document) (ResourceSettings,
 }
 
 func extractFleetsFromEventListFromDocV6(doc *goquery.Document) []Fleet {
-	type Tmp struct {
-		fleet Fleet
-		res   Resources
-	}
-	tmp := make([]Tmp, 0)
 	res := make([]Fleet, 0)
+	unionRgx := regexp.MustCompile(`union(\d+)`)
 	doc.Find("tr.eventFleet").Each(func(i int, s *goquery.Selection) {
 		fleet := Fleet{}
 
@@ -1096,22 +1128,40 @@ func extractFleetsFromEventListFromDocV6(doc *goquery.Document) []Fleet {
 				fleet.Ships.Set(ShipName2ID(name), nbr)
 			}
 		})
-		fleet.Origin = extractCoordV6(doc.Find("td.coordsOrigin").Text())
-		fleet.Destination = extractCoordV6(doc.Find("td.destCoords").Text())
+		missionType, _ := strconv.ParseInt(s.AttrOr("data-mission-type", ""), 10, 64)
+		arrivalTime, _ := strconv.ParseInt(s.AttrOr("data-arrival-time", ""), 10, 64)
+		fleet.Mission = MissionID(missionType)
+		fleet.ReturnFlight, _ = strconv.ParseBool(s.AttrOr("data-return-flight", ""))
+		fleet.ArrivalTime = time.Unix(arrivalTime, 0)
+		fleet.Relation = extractFleetRelationV6(s.Find("td.countDown"))
+		if m := unionRgx.FindStringSubmatch(s.AttrOr("class", "")); len(m) == 2 {
+			fleet.UnionID, _ = strconv.ParseInt(m[1], 10, 64)
+		}
+
+		fleet.Origin = extractCoordV6(strings.TrimSpace(s.Find("td.coordsOrigin").Text()))
+		fleet.Origin.Type = PlanetType
+		if s.Find("td.originFleet figure").HasClass("moon") {
+			fleet.Origin.Type = MoonType
+		}
+		fleet.Destination = extractCoordV6(strings.TrimSpace(s.Find("td.destCoords").Text()))
+		fleet.Destination.Type = PlanetType
+		if s.Find("td.destFleet figure").HasClass("moon") {
+			fleet.Destination.Type = MoonType
+		} else if s.Find("td.destFleet figure").HasClass("tf") || fleet.Mission == RecycleDebrisField {
+			fleet.Destination.Type = DebrisType
+		}
 
-		res := Resources{}
 		trs := doc2.Find("tr")
-		res.Metal = ParseInt(trs.Eq(trs.Size() - 3).Find("td").Eq(1).Text())
-		res.Crystal = ParseInt(trs.Eq(trs.Size() - 2).Find("td").Eq(1).Text())
-		res.Deuterium = ParseInt(trs.Eq(trs.Size() - 1).Find("td").Eq(1).Text())
+		if doc2.Find("th").Size() < 2 { // No shipment information for foreign fleets
+			res = append(res, fleet)
+			return
+		}
+		fleet.Resources.Metal = ParseInt(trs.Eq(trs.Size() - 3).Find("td").Eq(1).Text())
+		fleet.Resources.Crystal = ParseInt(trs.Eq(trs.Size() - 2).Find("td").Eq(1).Text())
+		fleet.Resources.Deuterium = ParseInt(trs.Eq(trs.Size() - 1).Find("td").Eq(1).Text())
 
-		tmp = append(tmp, Tmp{fleet: fleet, res: res})
+		res = append(res, fleet)
 	})
-
-	for _, t := range tmp {
-		res = append(res, t.fleet)
-	}
-
 	return res
 }
 
@@ -1143,6 +1193,9 @@ func extractFleetsFromDocV6(doc *goquery.Document, location *time.Location) (res
 		}
 
 		id, _ := strconv.ParseInt(s.Find("a.openCloseDetails").AttrOr("data-mission-id", "0"), 10, 64)
+		if id == 0 {
+			id, _ = strconv.ParseInt(strings.TrimPrefix(s.AttrOr("id", ""), "fleet"), 10, 64)
+		}
 
 		timerID := s.Find("span.timer").AttrOr("id", "")
 		m := regexp.MustCompile(`getElementByIdWithCache\("` + timerID + `"\),\s*(\d+),`).FindStringSubmatch(script)
@@ -1159,7 +1212,11 @@ func extractFleetsFromDocV6(doc *goquery.Document, location *time.Location) (res
 		}
 
 		missionType, _ := strconv.ParseInt(s.AttrOr("data-mission-type", ""), 10, 64)
-		returnFlight, _ := strconv.ParseBool(s.AttrOr("data-return-flight", ""))
+		returnFlight, err := strconv.ParseBool(s.AttrOr("data-return-flight", ""))
+		if err != nil {
+			// The attribute is empty for outward flights on newer versions, the route icon tells the direction
+			returnFlight = s.Find("span.fleetDetailButton a").HasClass("fleet_icon_reverse")
+		}
 		inDeepSpace := s.Find("span.fleetDetailButton a").HasClass("fleet_icon_forward_end")
 		arrivalTime, _ := strconv.ParseInt(s.AttrOr("data-arrival-time", ""), 10, 64)
 		endTime, _ := strconv.ParseInt(s.Find("a.openCloseDetails").AttrOr("data-end-time", ""), 10, 64)
@@ -1176,6 +1233,10 @@ func extractFleetsFromDocV6(doc *goquery.Document, location *time.Location) (res
 		targetPlanetID, _ := strconv.ParseInt(fedAttackQuery.Get("target"), 10, 64)
 		unionID, _ := strconv.ParseInt(fedAttackQuery.Get("union"), 10, 64)
 
+		if MissionID(missionType) == RecycleDebrisField {
+			dest.Type = DebrisType
+		}
+
 		fleet := Fleet{}
 		fleet.ID = FleetID(id)
 		fleet.Origin = origin
@@ -1186,6 +1247,7 @@ func extractFleetsFromDocV6(doc *goquery.Document, location *time.Location) (res
 		fleet.Resources = shipment
 		fleet.TargetPlanetID = targetPlanetID
 		fleet.UnionID = unionID
+		fleet.Relation = extractFleetRelationV6(s.Find("span.mission"))
 		fleet.ArrivalTime = time.Unix(endTime, 0)
 		fleet.BackTime = time.Unix(arrivalTime, 0)
 
@@ -1209,6 +1271,7 @@ func extractFleetsFromDocV6(doc *goquery.Document, location *time.Location) (res
 			}
 		}
 		fleet.StartTime = startTime.Local()
+		fleet.HoldingTime = fleetHoldingTime(fleet)
 
 		for i := 1; i < trs.Size()-5; i++ {
 			tds := trs.Eq(i).Find("td")
@@ -1220,9 +1283,52 @@ func extractFleetsFromDocV6(doc *goquery.Document, location *time.Location) (res
 
 		res = append(res, fleet)
 	})
+	fillFleetsUnionID(res)
 	return
 }
 
+func extractFleetRelationV6(s *goquery.Selection) FleetRelation {
+	if s.HasClass("hostile") || s.Find(".hostile").Size() > 0 {
+		return HostileFleet
+	} else if s.HasClass("friendly") || s.Find(".friendly").Size() > 0 {
+		return FriendlyFleet
+	}
+	return NeutralFleet
+}
+
+// fleetHoldingTime computes the time an outward fleet stays at its destination, the back time being the arrival time
+// plus the holding time plus the flight time
+func fleetHoldingTime(fleet Fleet) int64 {
+	if fleet.ReturnFlight || (fleet.Mission != Expedition && fleet.Mission != ParkInThatAlly) ||
+		fleet.StartTime.IsZero() || fleet.BackTime.Unix() <= 0 {
+		return 0
+	}
+	flightTime := fleet.ArrivalTime.Sub(fleet.StartTime)
+	holdingTime := int64(fleet.BackTime.Sub(fleet.ArrivalTime.Add(flightTime)).Seconds())
+	if holdingTime < 0 {
+		return 0
+	}
+	return holdingTime
+}
+
+// fillFleetsUnionID only the first fleet of an ACS attack links to its union, gives its union id to the other fleets
+// of the attack (same target and arrival time)
+func fillFleetsUnionID(fleets []Fleet) {
+	for i := range fleets {
+		if fleets[i].Mission != GroupedAttack || fleets[i].UnionID != 0 || fleets[i].ReturnFlight {
+			continue
+		}
+		for _, f := range fleets {
+			if f.Mission == GroupedAttack && f.UnionID != 0 && !f.ReturnFlight &&
+				f.Destination.Equal(fleets[i].Destination) && f.ArrivalTime.Equal(fleets[i].ArrivalTime) {
+				fleets[i].UnionID = f.UnionID
+				fleets[i].TargetPlanetID = f.TargetPlanetID
+				break
+			}
+		}
+	}
+}
+
 func extractSlotsFromDocV6(doc *goquery.Document) Slots {
 	slots := Slots{}
 	page := extractBodyIDFromDocV6(doc)
@@ -1734,13 +1840,17 @@ func extractGalaxyInfosV6(pageHTML []byte, botPlayerName string, botPlayerID, bo
 			planetInfos.Activity = extractActivity(s.Find("td:not(.moon) div.activity"))
 			planetInfos.Name = planetName
 			planetInfos.Img = planetImg
-			planetInfos.Inactive = strings.Contains(classes, "inactive_filter")
+			// The row filter classes are missing in some versions, the status abbreviations are always displayed
+			planetInfos.LongInactive = s.Find("span.status_abbr_longinactive").Size() > 0
+			planetInfos.Inactive = strings.Contains(classes, "inactive_filter") || planetInfos.LongInactive ||
+				s.Find("span.status_abbr_inactive").Size() > 0
 			planetInfos.StrongPlayer = s.Find("span.status_abbr_strong").Size() > 0
-			planetInfos.Newbie = strings.Contains(classes, "newbie_filter")
-			planetInfos.Vacation = strings.Contains(classes, "vacation_filter")
+			planetInfos.Newbie = strings.Contains(classes, "newbie_filter") || s.Find("span.status_abbr_noob").Size() > 0
+			planetInfos.Vacation = strings.Contains(classes, "vacation_filter") || s.Find("span.status_abbr_vacation").Size() > 0
 			planetInfos.HonorableTarget = s.Find("span.status_abbr_honorableTarget").Size() > 0
 			planetInfos.Administrator = s.Find("span.status_abbr_admin").Size() > 0
 			planetInfos.Banned = s.Find("td.playername a span.status_abbr_banned").Size() > 0
+			planetInfos.Outlaw = s.Find("span.status_abbr_outlaw").Size() > 0
 			tdPlayername := s.Find("td.playername span")
 			planetInfos.Player.IsBandit = tdPlayername.HasClass("rank_bandit1") || tdPlayername.HasClass("rank_bandit2") || tdPlayername.HasClass("rank_bandit3")
 			planetInfos.Player.IsStarlord = tdPlayername.HasClass("rank_starlord1") || tdPlayername.HasClass("rank_starlord2") || tdPlayername.HasClass("rank_starlord3")
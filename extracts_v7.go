@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"html"
 	"regexp"
 	"strconv"
 	"strings"
@@ -289,6 +290,17 @@ func extractCombatReportMessagesFromDocV7(doc *goquery.Document) ([]CombatReport
 					report.Crystal = ParseInt(m[2])
 					report.Deuterium = ParseInt(m[3])
 				}
+				// The titles of the sides are the units they lost, the names are in parentheses whatever the language
+				attacker := s.Find("span.msg_content div.combatLeftSide span.msg_ctn2")
+				defender := s.Find("span.msg_content div.combatRightSide span.msg_ctn2")
+				report.AttackerLosses = ParseInt(attacker.AttrOr("title", "0"))
+				report.DefenderLosses = ParseInt(defender.AttrOr("title", "0"))
+				if m := regexp.MustCompile(`\(([^)]*)\)`).FindStringSubmatch(attacker.Text()); len(m) == 2 {
+					report.AttackerName = m[1]
+				}
+				if m := regexp.MustCompile(`\(([^)]*)\)`).FindStringSubmatch(defender.Text()); len(m) == 2 {
+					report.DefenderName = m[1]
+				}
 				debrisFieldTitle := s.Find("span.msg_content div.combatLeftSide span").Eq(2).AttrOr("title", "0")
 				report.DebrisField = ParseInt(debrisFieldTitle)
 				resText := s.Find("span.msg_content div.combatLeftSide span").Eq(1).Text()
@@ -638,6 +650,17 @@ func extractCancelResearchInfosV7(pageHTML []byte) (token string, techID, listID
 	return
 }
 
+// extractCrawlerCountFromDocV7 number of crawlers from the label of the row of the crawler setting (last217 select),
+// eg: "Crawler (Number: 12)"
+func extractCrawlerCountFromDocV7(doc *goquery.Document) int64 {
+	label := doc.Find("select[name=last217]").Closest("tr").Find("td.label").Text()
+	m := regexp.MustCompile(`\(\D*([\d.,]+)\)`).FindStringSubmatch(label)
+	if len(m) != 2 {
+		return 0
+	}
+	return ParseInt(m[1])
+}
+
 func extractResourceSettingsFromDocV7(doc *goquery.Document) (ResourceSettings, error) {
 	bodyID := extractBodyIDFromDocV6(doc)
 	if bodyID == "overview" {
@@ -664,6 +687,7 @@ func extractResourceSettingsFromDocV7(doc *goquery.Document) (ResourceSettings,
 	res.FusionReactor = vals[4]
 	res.SolarSatellite = vals[5]
 	res.Crawler = vals[6]
+	res.CrawlerCount = extractCrawlerCountFromDocV7(doc)
 
 	return res, nil
 }
@@ -735,6 +759,46 @@ func extractExpeditionMessagesFromDocV7(doc *goquery.Document, location *time.Lo
 	return msgs, nbPage, nil
 }
 
+// extractMarketplaceTradeV7 extracts the traded item and the price of a marketplace message content, the lines are
+// "<label> <quantity> <item>", "<label> <price> <resource> ..." and optionally the market fee "<label> <fee> <resource>"
+func extractMarketplaceTradeV7(contentHTML string) (item string, quantity int64, priceResource string, price int64) {
+	type amount struct {
+		name string
+		nbr  int64
+	}
+	amounts := make([]amount, 0)
+	for _, line := range regexp.MustCompile(`<br\s*/?>`).Split(contentHTML, -1) {
+		if i := strings.Index(line, "<"); i >= 0 {
+			line = line[:i]
+		}
+		m := regexp.MustCompile(`(\d[\d.,]*)\s+(\S[^\d]*)`).FindStringSubmatch(html.UnescapeString(line))
+		if len(m) == 3 {
+			amounts = append(amounts, amount{name: marketplaceItemName(m[2]), nbr: ParseInt(m[1])})
+		}
+	}
+	if len(amounts) < 2 {
+		return
+	}
+	item, quantity = amounts[0].name, amounts[0].nbr
+	priceResource, price = amounts[1].name, amounts[1].nbr
+	if len(amounts) > 2 && amounts[2].name == priceResource {
+		price += amounts[2].nbr
+	}
+	return
+}
+
+// marketplaceItemName returns the resource or ship name at the start of s, s is returned trimmed if none is found
+func marketplaceItemName(s string) string {
+	words := strings.Fields(s)
+	for i := 1; i <= len(words); i++ {
+		name := strings.Join(words[:i], " ")
+		if marketplaceResourceType(name) != 0 || ShipName2ID(name).IsShip() {
+			return name
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
 func extractMarketplaceMessagesFromDocV7(doc *goquery.Document, location *time.Location) ([]MarketplaceMessage, int64, error) {
 	msgs := make([]MarketplaceMessage, 0)
 	tab, _ := strconv.ParseInt(doc.Find("ul.pagination li").Last().AttrOr("data-tab", ""), 10, 64)
@@ -759,6 +823,8 @@ func extractMarketplaceMessagesFromDocV7(doc *goquery.Document, location *time.L
 				msg.CreatedAt, _ = time.ParseInLocation("02.01.2006 15:04:05", s.Find(".msg_date").Text(), location)
 				msg.Token = token
 				msg.MarketTransactionID = marketTransactionID
+				contentHTML, _ := s.Find(".msg_content").Html()
+				msg.Item, msg.Quantity, msg.PriceResource, msg.Price = extractMarketplaceTradeV7(contentHTML)
 				msgs = append(msgs, msg)
 			}
 		}
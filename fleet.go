@@ -2,6 +2,26 @@ package ogame
 
 import "time"
 
+// FleetRelation relation of a fleet to the player, as colored by the game
+type FleetRelation int64
+
+// Fleet relations
+const (
+	NeutralFleet FleetRelation = iota
+	FriendlyFleet
+	HostileFleet
+)
+
+func (r FleetRelation) String() string {
+	switch r {
+	case FriendlyFleet:
+		return "Friendly"
+	case HostileFleet:
+		return "Hostile"
+	}
+	return "Neutral"
+}
+
 // Fleet represent a player fleet information
 type Fleet struct {
 	Mission        MissionID
@@ -10,7 +30,7 @@ type Fleet struct {
 	ID             FleetID
 	Resources      Resources
 	Origin         Coordinate
-	Destination    Coordinate
+	Destination    Coordinate // Type is DebrisType for harvest missions
 	Ships          ShipsInfos
 	StartTime      time.Time
 	ArrivalTime    time.Time
@@ -19,4 +39,6 @@ type Fleet struct {
 	BackIn         int64
 	UnionID        int64
 	TargetPlanetID int64
+	HoldingTime    int64         // Seconds spent at the destination (expedition, ACS defend), 0 when unknown
+	Relation       FleetRelation // Hostile for hostile missions (attack, espionage...)
 }
@@ -0,0 +1,75 @@
+package ogame
+
+import (
+	"errors"
+)
+
+// FleetTemplate named fleet dispatch, sent from any celestial with SendFleetTemplate
+type FleetTemplate struct {
+	Ships       []Quantifiable // Quantities can be AllAvailable
+	Mission     MissionID
+	Destination Coordinate
+	Speed       Speed     // HundredPercent if 0
+	HoldingTime int64     // Hours of an expedition or a hold mission
+	Resources   Resources // Amounts can be AllAvailable
+}
+
+// SetFleetTemplate sets a named fleet dispatch, see SendFleetTemplate
+func (b *OGame) SetFleetTemplate(name string, t FleetTemplate) error {
+	if name == "" {
+		return errors.New("invalid fleet template name")
+	}
+	if len(t.Ships) == 0 {
+		return ErrNoShipSelected
+	}
+	for _, s := range t.Ships {
+		if !s.ID.IsShip() || (s.Nbr <= 0 && s.Nbr != AllAvailable) {
+			return errors.New("invalid fleet template ship " + s.ID.String())
+		}
+	}
+	if !t.Mission.IsValid() {
+		return errors.New("invalid fleet template mission " + t.Mission.String())
+	}
+	if t.Speed == 0 {
+		t.Speed = HundredPercent
+	}
+	if t.Speed < 0 || t.Speed > HundredPercent { // The steps depend on the class, they are checked when sending
+		return ErrInvalidSpeed
+	}
+	b.fleetTemplatesMu.Lock()
+	defer b.fleetTemplatesMu.Unlock()
+	if b.fleetTemplates == nil {
+		b.fleetTemplates = make(map[string]FleetTemplate)
+	}
+	t.Ships = append([]Quantifiable{}, t.Ships...)
+	b.fleetTemplates[name] = t
+	return nil
+}
+
+// RemoveFleetTemplate removes a fleet template
+func (b *OGame) RemoveFleetTemplate(name string) {
+	b.fleetTemplatesMu.Lock()
+	defer b.fleetTemplatesMu.Unlock()
+	delete(b.fleetTemplates, name)
+}
+
+// GetFleetTemplates returns the fleet templates by name
+func (b *OGame) GetFleetTemplates() map[string]FleetTemplate {
+	b.fleetTemplatesMu.RLock()
+	defer b.fleetTemplatesMu.RUnlock()
+	res := make(map[string]FleetTemplate, len(b.fleetTemplates))
+	for name, t := range b.fleetTemplates {
+		t.Ships = append([]Quantifiable{}, t.Ships...)
+		res[name] = t
+	}
+	return res
+}
+
+// SendFleetTemplate sends the fleet of a template from a celestial
+func (b *OGame) SendFleetTemplate(name string, celestialID CelestialID) (Fleet, error) {
+	t, ok := b.GetFleetTemplates()[name]
+	if !ok {
+		return Fleet{}, errors.New("fleet template " + name + " not found")
+	}
+	return b.WithPriority(Normal).SendFleet(celestialID, t.Ships, t.Speed, t.Destination, t.Mission, t.Resources, t.HoldingTime, 0)
+}
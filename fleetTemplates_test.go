@@ -0,0 +1,40 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFleetTemplate(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	expo := FleetTemplate{
+		Ships:       []Quantifiable{{ID: LargeCargoID, Nbr: 50}, {ID: EspionageProbeID, Nbr: AllAvailable}},
+		Mission:     Expedition,
+		Destination: Coordinate{1, 50, 16, PlanetType},
+		HoldingTime: 1,
+	}
+	assert.Error(t, b.SetFleetTemplate("", expo))
+	assert.Equal(t, ErrNoShipSelected, b.SetFleetTemplate("expo1", FleetTemplate{Mission: Expedition}))
+	assert.Error(t, b.SetFleetTemplate("expo1", FleetTemplate{Ships: []Quantifiable{{ID: MetalMineID, Nbr: 1}}, Mission: Expedition}))
+	assert.Error(t, b.SetFleetTemplate("expo1", FleetTemplate{Ships: []Quantifiable{{ID: LargeCargoID, Nbr: 0}}, Mission: Expedition}))
+	assert.Error(t, b.SetFleetTemplate("expo1", FleetTemplate{Ships: expo.Ships, Mission: 99}))
+	assert.Equal(t, ErrInvalidSpeed, b.SetFleetTemplate("expo1", FleetTemplate{Ships: expo.Ships, Mission: Expedition, Speed: 11}))
+
+	assert.NoError(t, b.SetFleetTemplate("expo1", expo))
+	templates := b.GetFleetTemplates()
+	assert.Equal(t, 1, len(templates))
+	assert.Equal(t, HundredPercent, templates["expo1"].Speed)
+	assert.Equal(t, expo.Ships, templates["expo1"].Ships)
+	templates["expo1"].Ships[0].Nbr = 1
+	assert.Equal(t, int64(50), b.GetFleetTemplates()["expo1"].Ships[0].Nbr)
+
+	b.RemoveFleetTemplate("expo1")
+	assert.Equal(t, 0, len(b.GetFleetTemplates()))
+}
+
+func TestSendFleetTemplate_NotFound(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	_, err := b.SendFleetTemplate("expo1", 123)
+	assert.EqualError(t, err, "fleet template expo1 not found")
+}
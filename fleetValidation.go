@@ -0,0 +1,218 @@
+package ogame
+
+import (
+	"fmt"
+	"time"
+)
+
+// FleetValidation result of the pre-flight checks of a fleet, computed without sending it
+type FleetValidation struct {
+	Valid       bool
+	Errors      []string
+	Origin      Coordinate
+	Destination Coordinate
+	Mission     MissionID
+	Speed       Speed
+	Ships       ShipsInfos
+	FlightTime  int64 // Seconds, one way
+	Fuel        int64
+	Cargo       int64
+	Resources   Resources // Resources that would be loaded, capped to the cargo capacity
+	ArrivalTime time.Time
+}
+
+func (v *FleetValidation) addError(err error) {
+	v.Valid = false
+	v.Errors = append(v.Errors, err.Error())
+}
+
+// capResourcesToCargo loads deuterium first, then crystal, then metal, like sendFleet does
+func capResourcesToCargo(resources Resources, cargo int64) Resources {
+	if resources.Total() <= cargo {
+		return resources
+	}
+	res := Resources{}
+	res.Deuterium = MinInt(resources.Deuterium, cargo)
+	cargo -= res.Deuterium
+	res.Crystal = MinInt(resources.Crystal, cargo)
+	cargo -= res.Crystal
+	res.Metal = MinInt(resources.Metal, cargo)
+	return res
+}
+
+// AllAvailable ships quantity or resources amount meaning everything available on the celestial, resolved by
+// SendFleet right before the fleet is dispatched, like the "all" buttons of the game
+const AllAvailable int64 = -1
+
+// resolveAllShips returns a copy of ships where the AllAvailable quantities are replaced by the available ships
+func resolveAllShips(ships []Quantifiable, available ShipsInfos) []Quantifiable {
+	res := make([]Quantifiable, len(ships))
+	for i, s := range ships {
+		if s.Nbr == AllAvailable {
+			s.Nbr = available.ByID(s.ID)
+		}
+		res[i] = s
+	}
+	return res
+}
+
+// resolveAllResources replaces the AllAvailable amounts by the available resources, keeping the fuel of the flight
+func resolveAllResources(resources, available Resources, fuel int64) Resources {
+	if resources.Metal == AllAvailable {
+		resources.Metal = available.Metal
+	}
+	if resources.Crystal == AllAvailable {
+		resources.Crystal = available.Crystal
+	}
+	if resources.Deuterium == AllAvailable {
+		resources.Deuterium = MaxInt(available.Deuterium-fuel, 0)
+	}
+	return resources
+}
+
+// checkFleetTarget validates the destination against the galaxy content of its system
+func checkFleetTarget(system SystemInfos, where Coordinate, mission MissionID) error {
+	planet := system.Position(where.Position)
+	switch mission {
+	case Expedition:
+		return nil
+	case Colonize:
+		if planet != nil {
+			return fmt.Errorf("position %s is already occupied", where)
+		}
+		return nil
+	case RecycleDebrisField:
+		// Debris fields of empty positions are not in the galaxy content
+		if planet != nil && planet.Debris.Metal+planet.Debris.Crystal == 0 {
+			return ErrNoDebrisField
+		}
+		return nil
+	}
+	if planet == nil || planet.Destroyed {
+		return ErrUninhabitedPlanet
+	}
+	if where.IsMoon() && planet.Moon == nil {
+		return ErrNoMoonAvailable
+	}
+	if isHostileMission(mission) && planet.Vacation {
+		return ErrPlayerInVacationMode
+	}
+	return nil
+}
+
+// TargetError returned when the destination of a fleet fails the target validation, its cause is the reason,
+// e.g. ErrUninhabitedPlanet, ErrNoMoonAvailable, ErrNoDebrisField or ErrPlayerInVacationMode
+type TargetError struct {
+	Destination Coordinate
+	Mission     MissionID
+	Err         error
+}
+
+func (e *TargetError) Error() string {
+	return e.Err.Error() + " (" + e.Mission.String() + " to " + e.Destination.String() + ")"
+}
+
+// Cause returns the reason of the failed validation, compatible with errors.Cause
+func (e *TargetError) Cause() error {
+	return e.Err
+}
+
+// checkTarget validates the destination of a fleet against the content of its system, served by the galaxy cache
+// unless SkipCache is given
+func (b *OGame) checkTarget(where Coordinate, mission MissionID, opts ...Option) error {
+	if mission == RecycleDebrisField {
+		where.Type = DebrisType
+	} else if mission == Colonize || mission == Expedition {
+		where.Type = PlanetType
+	}
+	if mission == Expedition {
+		return nil
+	}
+	system, err := b.galaxyInfos(where.Galaxy, where.System, opts...)
+	if err != nil {
+		return err
+	}
+	if err := checkFleetTarget(system, where, mission); err != nil {
+		return &TargetError{Destination: where, Mission: mission, Err: err}
+	}
+	return nil
+}
+
+// ValidateTarget returns a Prioritizable whose SendFleet and EnsureFleet validate the destination against the galaxy
+// content before sending the fleet, see Prioritize.ValidateTarget
+func (b *OGame) ValidateTarget(opts ...Option) Prioritizable {
+	return b.WithPriority(Normal).ValidateTarget(opts...)
+}
+
+// validateFleet runs the checks of sendFleet (ships available, free slots, fuel, target, cargo capacity)
+// and computes the flight time, fuel and cargo without sending the fleet
+func (b *OGame) validateFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate,
+	mission MissionID, resources Resources) (FleetValidation, error) {
+	origin := b.GetCachedCelestialByID(celestialID)
+	if origin == nil {
+		return FleetValidation{}, ErrInvalidPlanetID
+	}
+	if mission == RecycleDebrisField {
+		where.Type = DebrisType
+	} else if mission == Colonize || mission == Expedition {
+		where.Type = PlanetType
+	}
+	v := FleetValidation{Valid: true, Errors: make([]string, 0), Origin: origin.GetCoordinate(), Destination: where, Mission: mission, Speed: speed}
+	if !speed.IsValid(b.characterClass) {
+		v.addError(ErrInvalidSpeed)
+	}
+
+	availableShips, err := b.getShips(celestialID)
+	if err != nil {
+		return FleetValidation{}, err
+	}
+	ships = resolveAllShips(ships, availableShips)
+	for _, s := range ships {
+		if !s.ID.IsFlyableShip() || s.Nbr <= 0 {
+			continue
+		}
+		if avail := availableShips.ByID(s.ID); s.Nbr > avail {
+			v.addError(fmt.Errorf("not enough ships to send, %s (%d/%d)", Objs.ByID(s.ID).GetName(), avail, s.Nbr))
+		}
+		v.Ships.Set(s.ID, s.Nbr)
+	}
+	if !v.Ships.HasFlyableShips() {
+		v.addError(ErrNoShipSelected)
+	}
+
+	_, slots := b.getFleets()
+	if slots.InUse >= slots.Total || (mission == Expedition && slots.ExpInUse >= slots.ExpTotal) {
+		v.addError(ErrAllSlotsInUse)
+	}
+
+	v.FlightTime, v.Fuel = CalcFlightTime(v.Origin, where, b.serverData.Galaxies, b.serverData.Systems,
+		b.serverData.DonutGalaxy, b.serverData.DonutSystem, b.serverData.GlobalDeuteriumSaveFactor,
+		float64(speed)/10, GetFleetSpeedForMission(b.IsV81(), b.serverData, mission), v.Ships, b.getCachedResearch(), b.characterClass)
+	v.ArrivalTime = time.Now().Add(time.Duration(v.FlightTime) * time.Second)
+	v.Cargo = b.fleetCargo(v.Ships)
+
+	available, err := b.getResources(celestialID)
+	if err != nil {
+		return FleetValidation{}, err
+	}
+	allResources := resources.Metal == AllAvailable || resources.Crystal == AllAvailable || resources.Deuterium == AllAvailable
+	resources = resolveAllResources(resources, available, v.Fuel)
+	v.Resources = capResourcesToCargo(resources, v.Cargo)
+	if resources.Total() > v.Cargo && !allResources {
+		v.addError(fmt.Errorf("cargo capacity exceeded, %d/%d", resources.Total(), v.Cargo))
+	}
+	if available.Deuterium < v.Fuel+v.Resources.Deuterium {
+		v.addError(fmt.Errorf("not enough deuterium, %d needed, %d available", v.Fuel+v.Resources.Deuterium, available.Deuterium))
+	}
+	if available.Metal < v.Resources.Metal || available.Crystal < v.Resources.Crystal {
+		v.addError(fmt.Errorf("not enough resources, %s needed, %s available", v.Resources, available))
+	}
+
+	if err := b.checkTarget(where, mission); err != nil {
+		if _, ok := err.(*TargetError); !ok {
+			return FleetValidation{}, err
+		}
+		v.addError(err)
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapResourcesToCargo(t *testing.T) {
+	assert.Equal(t, Resources{Metal: 1, Crystal: 2, Deuterium: 3}, capResourcesToCargo(Resources{Metal: 1, Crystal: 2, Deuterium: 3}, 10))
+	assert.Equal(t, Resources{Metal: 0, Crystal: 2, Deuterium: 3}, capResourcesToCargo(Resources{Metal: 10, Crystal: 2, Deuterium: 3}, 5))
+	assert.Equal(t, Resources{Metal: 0, Crystal: 0, Deuterium: 5}, capResourcesToCargo(Resources{Metal: 10, Crystal: 10, Deuterium: 10}, 5))
+}
+
+func TestResolveAllShips(t *testing.T) {
+	ships := []Quantifiable{{ID: SmallCargoID, Nbr: AllAvailable}, {ID: LargeCargoID, Nbr: 3}, {ID: CruiserID, Nbr: AllAvailable}}
+	available := ShipsInfos{SmallCargo: 10, LargeCargo: 5}
+	res := resolveAllShips(ships, available)
+	assert.Equal(t, []Quantifiable{{ID: SmallCargoID, Nbr: 10}, {ID: LargeCargoID, Nbr: 3}, {ID: CruiserID, Nbr: 0}}, res)
+	assert.Equal(t, AllAvailable, ships[0].Nbr)
+}
+
+func TestResolveAllResources(t *testing.T) {
+	available := Resources{Metal: 1000, Crystal: 2000, Deuterium: 3000}
+	assert.Equal(t, Resources{Metal: 1000, Crystal: 5, Deuterium: 2900}, resolveAllResources(Resources{Metal: AllAvailable, Crystal: 5, Deuterium: AllAvailable}, available, 100))
+	assert.Equal(t, Resources{Deuterium: 0}, resolveAllResources(Resources{Deuterium: AllAvailable}, Resources{Deuterium: 50}, 100))
+	assert.Equal(t, Resources{Metal: 1, Crystal: 2, Deuterium: 3}, resolveAllResources(Resources{Metal: 1, Crystal: 2, Deuterium: 3}, available, 100))
+}
+
+func TestCheckFleetTarget(t *testing.T) {
+	system := SystemInfos{galaxy: 1, system: 2}
+	system.planets[3] = &PlanetInfos{Coordinate: Coordinate{1, 2, 4, PlanetType}}
+	system.planets[4] = &PlanetInfos{Coordinate: Coordinate{1, 2, 5, PlanetType}, Moon: &MoonInfos{}, Vacation: true}
+	system.planets[5] = &PlanetInfos{Coordinate: Coordinate{1, 2, 6, PlanetType}}
+	system.planets[5].Debris.Metal = 1000
+
+	assert.Nil(t, checkFleetTarget(system, Coordinate{1, 2, 4, PlanetType}, Transport))
+	assert.Equal(t, ErrUninhabitedPlanet, checkFleetTarget(system, Coordinate{1, 2, 1, PlanetType}, Attack))
+	assert.Equal(t, ErrNoMoonAvailable, checkFleetTarget(system, Coordinate{1, 2, 4, MoonType}, Spy))
+	assert.Equal(t, ErrPlayerInVacationMode, checkFleetTarget(system, Coordinate{1, 2, 5, MoonType}, Attack))
+	assert.Nil(t, checkFleetTarget(system, Coordinate{1, 2, 5, MoonType}, Transport))
+	assert.Nil(t, checkFleetTarget(system, Coordinate{1, 2, 1, PlanetType}, Colonize))
+	assert.NotNil(t, checkFleetTarget(system, Coordinate{1, 2, 4, PlanetType}, Colonize))
+	assert.Equal(t, ErrNoDebrisField, checkFleetTarget(system, Coordinate{1, 2, 4, DebrisType}, RecycleDebrisField))
+	assert.Nil(t, checkFleetTarget(system, Coordinate{1, 2, 6, DebrisType}, RecycleDebrisField))
+	assert.Nil(t, checkFleetTarget(system, Coordinate{1, 2, 16, PlanetType}, Expedition))
+}
+
+func TestCheckTarget(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.server.Settings.UniverseSize = 9
+	b.serverData.Systems = 499
+	cache := newGalaxyCache(0)
+	b.SetGalaxyCache(cache)
+	system := SystemInfos{galaxy: 1, system: 2}
+	system.planets[3] = &PlanetInfos{Coordinate: Coordinate{1, 2, 4, PlanetType}}
+	assert.Nil(t, cache.Set(system))
+
+	assert.Nil(t, b.checkTarget(Coordinate{1, 2, 4, PlanetType}, Transport))
+	assert.Nil(t, b.checkTarget(Coordinate{1, 2, 16, PlanetType}, Expedition))
+
+	err := b.checkTarget(Coordinate{1, 2, 4, MoonType}, Attack)
+	targetErr, ok := err.(*TargetError)
+	assert.True(t, ok)
+	assert.Equal(t, ErrNoMoonAvailable, targetErr.Cause())
+	assert.Equal(t, ErrCodeTargetNotExists, GetErrorCode(err))
+
+	err = b.checkTarget(Coordinate{1, 2, 4, PlanetType}, RecycleDebrisField)
+	assert.Equal(t, ErrNoDebrisField, errors.Cause(err))
+	assert.Equal(t, Coordinate{1, 2, 4, DebrisType}, err.(*TargetError).Destination)
+
+	_, err = b.ValidateTarget().SendFleet(CelestialID(1), nil, HundredPercent, Coordinate{1, 2, 7, PlanetType}, Attack, Resources{}, 0, 0)
+	assert.Equal(t, ErrUninhabitedPlanet, errors.Cause(err))
+}
@@ -0,0 +1,100 @@
+package ogame
+
+import (
+	"time"
+)
+
+// Sources of the data returned with a Freshness
+const (
+	FreshnessSourceCache = "cache"
+	FreshnessSourceLive  = "live"
+)
+
+// Freshness tells if data was served from the bot cache or fetched from the game, and when it was fetched
+type Freshness struct {
+	CachedAt time.Time
+	Source   string
+}
+
+func liveFreshness() Freshness {
+	return Freshness{CachedAt: time.Now(), Source: FreshnessSourceLive}
+}
+
+// liveCachedFreshness freshness of data just fetched from the game and cached at cachedAt, the cache time is kept so
+// it identifies the same version when the data is later served from the cache
+func liveCachedFreshness(cachedAt time.Time) Freshness {
+	if cachedAt.IsZero() {
+		return liveFreshness()
+	}
+	return Freshness{CachedAt: cachedAt, Source: FreshnessSourceLive}
+}
+
+// isFresh returns true if data cached at cachedAt can be served for a request accepting maxAge old data
+func isFresh(cachedAt time.Time, maxAge time.Duration) bool {
+	return maxAge > 0 && !cachedAt.IsZero() && time.Since(cachedAt) <= maxAge
+}
+
+type cachedEmpireJSON struct {
+	data     interface{}
+	cachedAt time.Time
+}
+
+func (b *OGame) cacheEmpireJSON(nbr int64, data interface{}) {
+	b.empireCacheMu.Lock()
+	defer b.empireCacheMu.Unlock()
+	if b.empireCache == nil {
+		b.empireCache = make(map[int64]cachedEmpireJSON)
+	}
+	b.empireCache[nbr] = cachedEmpireJSON{data: data, cachedAt: time.Now()}
+}
+
+func (b *OGame) getPlanetsMaxAge(maxAge time.Duration) ([]Planet, Freshness) {
+	b.planetsMu.RLock()
+	planets, cachedAt := b.planets, b.planetsCachedAt
+	b.planetsMu.RUnlock()
+	if len(planets) > 0 && isFresh(cachedAt, maxAge) {
+		return planets, Freshness{CachedAt: cachedAt, Source: FreshnessSourceCache}
+	}
+	planets = b.getPlanets()
+	b.planetsMu.RLock()
+	cachedAt = b.planetsCachedAt
+	b.planetsMu.RUnlock()
+	return planets, liveCachedFreshness(cachedAt)
+}
+
+func (b *OGame) getResearchMaxAge(maxAge time.Duration) (Researches, Freshness) {
+	if b.researches != nil && isFresh(b.researchesCachedAt, maxAge) {
+		return *b.researches, Freshness{CachedAt: b.researchesCachedAt, Source: FreshnessSourceCache}
+	}
+	researches := b.getResearch()
+	return researches, liveCachedFreshness(b.researchesCachedAt)
+}
+
+func (b *OGame) getEmpireJSONMaxAge(nbr int64, maxAge time.Duration) (interface{}, Freshness, error) {
+	b.empireCacheMu.Lock()
+	cached, ok := b.empireCache[nbr]
+	b.empireCacheMu.Unlock()
+	if ok && isFresh(cached.cachedAt, maxAge) {
+		return cached.data, Freshness{CachedAt: cached.cachedAt, Source: FreshnessSourceCache}, nil
+	}
+	res, err := b.getEmpireJSON(nbr)
+	return res, liveFreshness(), err
+}
+
+// GetPlanetsMaxAge returns the cached planets if they are not older than maxAge, otherwise fetch them from the game.
+// A maxAge of 0 always fetches the planets.
+func (b *OGame) GetPlanetsMaxAge(maxAge time.Duration) ([]Planet, Freshness) {
+	return b.WithPriority(Normal).GetPlanetsMaxAge(maxAge)
+}
+
+// GetResearchMaxAge returns the cached researches if they are not older than maxAge, otherwise fetch them from the game.
+// A maxAge of 0 always fetches the researches.
+func (b *OGame) GetResearchMaxAge(maxAge time.Duration) (Researches, Freshness) {
+	return b.WithPriority(Normal).GetResearchMaxAge(maxAge)
+}
+
+// GetEmpireJSONMaxAge returns the last empire JSON if it is not older than maxAge, otherwise fetch it from the game.
+// A maxAge of 0 always fetches the empire.
+func (b *OGame) GetEmpireJSONMaxAge(nbr int64, maxAge time.Duration) (interface{}, Freshness, error) {
+	return b.WithPriority(Normal).GetEmpireJSONMaxAge(nbr, maxAge)
+}
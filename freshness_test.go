@@ -0,0 +1,33 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsFresh(t *testing.T) {
+	assert.False(t, isFresh(time.Now(), 0))
+	assert.False(t, isFresh(time.Time{}, time.Minute))
+	assert.True(t, isFresh(time.Now().Add(-30*time.Second), time.Minute))
+	assert.False(t, isFresh(time.Now().Add(-2*time.Minute), time.Minute))
+}
+
+func TestGetPlanetsMaxAge_Cached(t *testing.T) {
+	cachedAt := time.Now().Add(-10 * time.Second)
+	b := &OGame{planets: []Planet{{ID: 123}}, planetsCachedAt: cachedAt}
+	planets, freshness := b.getPlanetsMaxAge(time.Minute)
+	assert.Equal(t, 1, len(planets))
+	assert.Equal(t, FreshnessSourceCache, freshness.Source)
+	assert.Equal(t, cachedAt, freshness.CachedAt)
+}
+
+func TestGetEmpireJSONMaxAge_Cached(t *testing.T) {
+	b := &OGame{}
+	b.cacheEmpireJSON(1, "empire")
+	res, freshness, err := b.getEmpireJSONMaxAge(1, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "empire", res)
+	assert.Equal(t, FreshnessSourceCache, freshness.Source)
+}
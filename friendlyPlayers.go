@@ -0,0 +1,66 @@
+package ogame
+
+import (
+	"sort"
+)
+
+// isHostileMission returns true for the missions that must never hit an alliance member or a buddy by mistake
+func isHostileMission(mission MissionID) bool {
+	return mission == Attack || mission == GroupedAttack || mission == Destroy || mission == MissileAttack
+}
+
+func (b *OGame) isFriendlyPlayer(playerID int64) bool {
+	if playerID == 0 {
+		return false
+	}
+	b.friendlyPlayersMu.RLock()
+	defer b.friendlyPlayersMu.RUnlock()
+	_, ok := b.friendlyPlayers[playerID]
+	return ok
+}
+
+func (b *OGame) addFriendlyPlayers(playerIDs ...int64) {
+	b.friendlyPlayersMu.Lock()
+	defer b.friendlyPlayersMu.Unlock()
+	if b.friendlyPlayers == nil {
+		b.friendlyPlayers = make(map[int64]struct{})
+	}
+	for _, id := range playerIDs {
+		if id != 0 {
+			b.friendlyPlayers[id] = struct{}{}
+		}
+	}
+}
+
+// AddFriendlyPlayers adds players (alliance members, buddies) to the cached list of friendly players.
+// Players flagged as buddy or ally member by the game when sending a fleet are added automatically.
+func (b *OGame) AddFriendlyPlayers(playerIDs ...int64) {
+	b.addFriendlyPlayers(playerIDs...)
+}
+
+// RemoveFriendlyPlayers removes players from the cached list of friendly players
+func (b *OGame) RemoveFriendlyPlayers(playerIDs ...int64) {
+	b.friendlyPlayersMu.Lock()
+	defer b.friendlyPlayersMu.Unlock()
+	for _, id := range playerIDs {
+		delete(b.friendlyPlayers, id)
+	}
+}
+
+// GetFriendlyPlayers returns the ids of the cached friendly players.
+// SendFleet refuses hostile missions (attack, ACS attack, destroy) against them unless AllowFriendlyFire is used.
+func (b *OGame) GetFriendlyPlayers() []int64 {
+	b.friendlyPlayersMu.RLock()
+	defer b.friendlyPlayersMu.RUnlock()
+	ids := make([]int64, 0, len(b.friendlyPlayers))
+	for id := range b.friendlyPlayers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// AllowFriendlyFire returns a Prioritizable that allows hostile missions against alliance members and buddies
+func (b *OGame) AllowFriendlyFire() Prioritizable {
+	return b.WithPriority(Normal).AllowFriendlyFire()
+}
@@ -0,0 +1,27 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHostileMission(t *testing.T) {
+	assert.True(t, isHostileMission(Attack))
+	assert.True(t, isHostileMission(GroupedAttack))
+	assert.True(t, isHostileMission(Destroy))
+	assert.False(t, isHostileMission(Transport))
+	assert.False(t, isHostileMission(Spy))
+}
+
+func TestFriendlyPlayers(t *testing.T) {
+	b := &OGame{}
+	assert.False(t, b.isFriendlyPlayer(123))
+	b.AddFriendlyPlayers(456, 123, 0)
+	assert.True(t, b.isFriendlyPlayer(123))
+	assert.False(t, b.isFriendlyPlayer(0))
+	assert.Equal(t, []int64{123, 456}, b.GetFriendlyPlayers())
+	b.RemoveFriendlyPlayers(123)
+	assert.False(t, b.isFriendlyPlayer(123))
+	assert.Equal(t, []int64{456}, b.GetFriendlyPlayers())
+}
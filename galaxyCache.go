@@ -0,0 +1,180 @@
+package ogame
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultGalaxyCacheTTL time a system is served from the galaxy cache when no TTL is given
+const DefaultGalaxyCacheTTL = 5 * time.Minute
+
+type galaxyCacheKey struct {
+	galaxy int64
+	system int64
+}
+
+// galaxyCacheEntry system as persisted in the cache file, one JSON line per fetched system
+type galaxyCacheEntry struct {
+	System   SystemInfos
+	CachedAt time.Time
+}
+
+// GalaxyCache in-memory cache of the galaxy systems. When a file is used, the fetched systems are appended to it
+// and the ones that did not expire are loaded on creation.
+type GalaxyCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[galaxyCacheKey]galaxyCacheEntry
+	file    *os.File
+}
+
+func newGalaxyCache(ttl time.Duration) *GalaxyCache {
+	if ttl <= 0 {
+		ttl = DefaultGalaxyCacheTTL
+	}
+	return &GalaxyCache{ttl: ttl, entries: make(map[galaxyCacheKey]galaxyCacheEntry)}
+}
+
+// NewGalaxyCache creates a galaxy cache keeping the systems for ttl (DefaultGalaxyCacheTTL if 0),
+// backed by filename, an empty filename keeps the cache in memory only
+func NewGalaxyCache(ttl time.Duration, filename string) (*GalaxyCache, error) {
+	c := newGalaxyCache(ttl)
+	if filename == "" {
+		return c, nil
+	}
+	if f, err := os.Open(filename); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var entry galaxyCacheEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil && c.isFresh(entry, time.Now()) {
+				c.entries[galaxyCacheKey{entry.System.galaxy, entry.System.system}] = entry
+			}
+		}
+		_ = f.Close()
+	}
+	// Rewrite the file with the fresh systems only, so it does not grow forever
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range c.entries {
+		if err := writeGalaxyCacheEntry(f, entry); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	c.file = f
+	return c, nil
+}
+
+func writeGalaxyCacheEntry(f *os.File, entry galaxyCacheEntry) error {
+	by, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(by, '\n'))
+	return err
+}
+
+func (c *GalaxyCache) isFresh(entry galaxyCacheEntry, now time.Time) bool {
+	return now.Sub(entry.CachedAt) <= c.ttl
+}
+
+// Get returns the cached system if it did not expire
+func (c *GalaxyCache) Get(galaxy, system int64) (SystemInfos, bool) {
+	c.Lock()
+	defer c.Unlock()
+	key := galaxyCacheKey{galaxy, system}
+	entry, ok := c.entries[key]
+	if !ok {
+		return SystemInfos{}, false
+	}
+	if !c.isFresh(entry, time.Now()) {
+		delete(c.entries, key)
+		return SystemInfos{}, false
+	}
+	return entry.System, true
+}
+
+// CachedAt returns the time the system was fetched, zero if it is not cached or expired
+func (c *GalaxyCache) CachedAt(galaxy, system int64) time.Time {
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[galaxyCacheKey{galaxy, system}]
+	if !ok || !c.isFresh(entry, time.Now()) {
+		return time.Time{}
+	}
+	return entry.CachedAt
+}
+
+// Set caches a system
+func (c *GalaxyCache) Set(system SystemInfos) error {
+	c.Lock()
+	defer c.Unlock()
+	entry := galaxyCacheEntry{System: system, CachedAt: time.Now()}
+	c.entries[galaxyCacheKey{system.galaxy, system.system}] = entry
+	if c.file == nil {
+		return nil
+	}
+	return writeGalaxyCacheEntry(c.file, entry)
+}
+
+// Len returns the number of cached systems, including the expired ones not evicted yet
+func (c *GalaxyCache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.entries)
+}
+
+// Clear removes every system from the cache
+func (c *GalaxyCache) Clear() error {
+	c.Lock()
+	defer c.Unlock()
+	c.entries = make(map[galaxyCacheKey]galaxyCacheEntry)
+	if c.file == nil {
+		return nil
+	}
+	if err := c.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := c.file.Seek(0, 0)
+	return err
+}
+
+// Close closes the file backing the cache
+func (c *GalaxyCache) Close() error {
+	c.Lock()
+	defer c.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+func (b *OGame) getGalaxyCache() *GalaxyCache {
+	b.galaxyCacheMu.RLock()
+	defer b.galaxyCacheMu.RUnlock()
+	return b.galaxyCache
+}
+
+// SetGalaxyCache replaces the galaxy cache used by GalaxyInfos, nil disables the cache
+func (b *OGame) SetGalaxyCache(c *GalaxyCache) {
+	b.galaxyCacheMu.Lock()
+	defer b.galaxyCacheMu.Unlock()
+	b.galaxyCache = c
+}
+
+// GalaxyCachedAt returns the time the system served by GalaxyInfos was fetched, zero if it is not in the galaxy cache
+func (b *OGame) GalaxyCachedAt(galaxy, system int64) time.Time {
+	c := b.getGalaxyCache()
+	if c == nil {
+		return time.Time{}
+	}
+	return c.CachedAt(galaxy, system)
+}
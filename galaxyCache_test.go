@@ -0,0 +1,58 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGalaxyCache_GetSet(t *testing.T) {
+	c := newGalaxyCache(time.Minute)
+	_, ok := c.Get(1, 2)
+	assert.False(t, ok)
+	si := SystemInfos{galaxy: 1, system: 2}
+	si.planets[2] = &PlanetInfos{ID: 123, Name: "Homeworld"}
+	assert.NoError(t, c.Set(si))
+	cached, ok := c.Get(1, 2)
+	assert.True(t, ok)
+	assert.Equal(t, int64(123), cached.Position(3).ID)
+	_, ok = c.Get(1, 3)
+	assert.False(t, ok)
+
+	// Expired systems are evicted
+	c.entries[galaxyCacheKey{1, 2}] = galaxyCacheEntry{System: si, CachedAt: time.Now().Add(-2 * time.Minute)}
+	_, ok = c.Get(1, 2)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestGalaxyCache_File(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "galaxy")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "galaxy.cache")
+	c, err := NewGalaxyCache(time.Minute, filename)
+	assert.NoError(t, err)
+	si := SystemInfos{galaxy: 4, system: 100}
+	si.planets[7] = &PlanetInfos{ID: 456, Coordinate: Coordinate{4, 100, 8, PlanetType}, Inactive: true}
+	assert.NoError(t, c.Set(si))
+	assert.NoError(t, c.Close())
+
+	c, err = NewGalaxyCache(time.Minute, filename)
+	assert.NoError(t, err)
+	cached, ok := c.Get(4, 100)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), cached.Galaxy())
+	assert.Equal(t, int64(100), cached.System())
+	assert.True(t, cached.Position(8).Inactive)
+	assert.NoError(t, c.Clear())
+	assert.NoError(t, c.Close())
+
+	c, err = NewGalaxyCache(time.Minute, filename)
+	assert.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,120 @@
+package ogame
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestGovernor token bucket limiting the requests sent by a client to a sustained rate, with bursts.
+// It is shared by every task and every request of the client (game pages, ajax, lobby, static proxy...).
+type RequestGovernor struct {
+	mu        sync.Mutex
+	rate      float64 // Tokens added per second
+	burst     float64
+	tokens    float64
+	last      time.Time
+	throttled int64 // atomic, requests that had to wait
+}
+
+// RequestGovernorStats state of a request governor
+type RequestGovernorStats struct {
+	Rate      float64 // Sustained requests per second
+	Burst     int64
+	Tokens    float64 // Requests that can be sent right away, negative when requests are waiting
+	Throttled int64   // Requests that had to wait
+}
+
+// NewRequestGovernor returns a governor allowing rate requests per second, and bursts of burst requests (1 if <= 0)
+func NewRequestGovernor(rate float64, burst int64) *RequestGovernor {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &RequestGovernor{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns how long to wait before sending the request
+func (g *RequestGovernor) reserve(now time.Time) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.last.IsZero() {
+		g.tokens += now.Sub(g.last).Seconds() * g.rate
+		if g.tokens > g.burst {
+			g.tokens = g.burst
+		}
+	}
+	g.last = now
+	g.tokens--
+	if g.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-g.tokens / g.rate * float64(time.Second))
+}
+
+// cancel gives back the token of a request that was not sent
+func (g *RequestGovernor) cancel() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.tokens++
+	if g.tokens > g.burst {
+		g.tokens = g.burst
+	}
+}
+
+// Wait blocks until the request can be sent, or ctx is done
+func (g *RequestGovernor) Wait(ctx context.Context) error {
+	wait := g.reserve(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	atomic.AddInt64(&g.throttled, 1)
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		return ctx.Err()
+	}
+}
+
+// Stats returns the state of the governor
+func (g *RequestGovernor) Stats() RequestGovernorStats {
+	g.mu.Lock()
+	tokens := g.tokens
+	if !g.last.IsZero() {
+		tokens += time.Since(g.last).Seconds() * g.rate
+		if tokens > g.burst {
+			tokens = g.burst
+		}
+	}
+	g.mu.Unlock()
+	return RequestGovernorStats{Rate: g.rate, Burst: int64(g.burst), Tokens: tokens, Throttled: atomic.LoadInt64(&g.throttled)}
+}
+
+// SetRequestGovernor sets the governor the requests of the client wait on, nil removes it
+func (c *OGameClient) SetRequestGovernor(g *RequestGovernor) {
+	c.governorMu.Lock()
+	defer c.governorMu.Unlock()
+	c.governor = g
+}
+
+// GetRequestGovernor returns the governor of the client, nil if the requests are not limited
+func (c *OGameClient) GetRequestGovernor() *RequestGovernor {
+	c.governorMu.RLock()
+	defer c.governorMu.RUnlock()
+	return c.governor
+}
+
+// SetRateLimit limits the requests sent to OGame to rate per second, with bursts of burst requests.
+// The limit is shared by all the tasks, a rate <= 0 removes it.
+func (b *OGame) SetRateLimit(rate float64, burst int64) {
+	if b.Client == nil {
+		return
+	}
+	if rate <= 0 {
+		b.Client.SetRequestGovernor(nil)
+		return
+	}
+	b.Client.SetRequestGovernor(NewRequestGovernor(rate, burst))
+}
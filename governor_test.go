@@ -0,0 +1,50 @@
+package ogame
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestGovernorReserve(t *testing.T) {
+	g := NewRequestGovernor(2, 3)
+	now := time.Now()
+	assert.Equal(t, time.Duration(0), g.reserve(now))
+	assert.Equal(t, time.Duration(0), g.reserve(now))
+	assert.Equal(t, time.Duration(0), g.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, g.reserve(now))
+	assert.Equal(t, time.Second, g.reserve(now))
+	// 3s later the bucket refilled 6 tokens, capped to the burst
+	now = now.Add(3 * time.Second)
+	assert.Equal(t, time.Duration(0), g.reserve(now))
+	assert.Equal(t, int64(3), g.Stats().Burst)
+	assert.True(t, g.Stats().Tokens <= 2)
+}
+
+func TestRequestGovernorWait(t *testing.T) {
+	g := NewRequestGovernor(1, 1)
+	assert.Nil(t, g.Wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, g.Wait(ctx))
+	assert.Equal(t, int64(1), g.Stats().Throttled)
+	// The token of the cancelled request was given back
+	assert.True(t, g.Stats().Tokens > -0.5)
+}
+
+func TestSetRateLimit(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.SetRateLimit(1, 1)
+	assert.NotNil(t, b.Client.GetRequestGovernor())
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:1", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = b.Client.Do(req)
+	_, err := b.Client.Do(req.WithContext(ctx))
+	assert.Equal(t, context.Canceled, err)
+	b.SetRateLimit(0, 0)
+	assert.Nil(t, b.Client.GetRequestGovernor())
+}
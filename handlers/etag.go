@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+)
+
+// etagMatch returns true if the If-None-Match header value matches etag
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheETag returns the ETag of a data version, the time the data last changed.
+// key identifies the item, eg: the system of the galaxy infos. Empty if there is no version.
+func cacheETag(kind, key string, changedAt time.Time) string {
+	if changedAt.IsZero() {
+		return ""
+	}
+	return `"` + kind + key + "-" + strconv.FormatInt(changedAt.UnixNano(), 36) + `"`
+}
+
+// dataVersion last data served by an endpoint
+type dataVersion struct {
+	data      interface{}
+	cachedAt  time.Time // Time the bot cached data, zero if it was not read from a bot cache
+	changedAt time.Time // Time data last changed, the version of the data
+}
+
+type dataVersionKey struct {
+	bot *ogame.OGame
+	key string
+}
+
+// dataVersions the versions of the data served by the endpoints with an ETag. Refetching identical data from the game
+// keeps its version, so the ETag stays valid whether or not the data was served from a bot cache.
+var dataVersions = struct {
+	sync.Mutex
+	m map[dataVersionKey]dataVersion
+}{m: make(map[dataVersionKey]dataVersion)}
+
+// versionETag records data as the last data of kind/key and returns the ETag of its version.
+// cachedAt is the time the bot cached data, zero if it is not cached.
+func versionETag(bot *ogame.OGame, kind, key string, data interface{}, cachedAt time.Time) string {
+	k := dataVersionKey{bot: bot, key: kind + key}
+	dataVersions.Lock()
+	defer dataVersions.Unlock()
+	v, ok := dataVersions.m[k]
+	sameCache := ok && !cachedAt.IsZero() && v.cachedAt.Equal(cachedAt)
+	if !sameCache && (!ok || !reflect.DeepEqual(v.data, data)) {
+		// Each version gets a distinct time, even when the clock did not move
+		now := time.Now()
+		if !now.After(v.changedAt) {
+			now = v.changedAt.Add(time.Nanosecond)
+		}
+		v.changedAt = now
+	}
+	v.data, v.cachedAt = data, cachedAt
+	dataVersions.m[k] = v
+	return cacheETag(kind, key, v.changedAt)
+}
+
+// cachedVersionETag returns the ETag of the data of kind/key the bot cached at cachedAt, if it was already served.
+// It lets the handlers answer 304 before reading the data. Empty if unknown.
+func cachedVersionETag(bot *ogame.OGame, kind, key string, cachedAt time.Time) string {
+	if cachedAt.IsZero() {
+		return ""
+	}
+	dataVersions.Lock()
+	defer dataVersions.Unlock()
+	v, ok := dataVersions.m[dataVersionKey{bot: bot, key: kind + key}]
+	if !ok || !v.cachedAt.Equal(cachedAt) {
+		return ""
+	}
+	return cacheETag(kind, key, v.changedAt)
+}
+
+// notModified sets the ETag header and returns true if the client already has this version (If-None-Match).
+// It is called before reading the data when the cache can serve it, so a 304 does not reach the game.
+func notModified(c echo.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	ifNoneMatch := c.Request().Header.Get("If-None-Match")
+	return ifNoneMatch != "" && etagMatch(ifNoneMatch, etag)
+}
+
+// etagResp sends resp with the ETag of the data version it holds,
+// 304 Not Modified is returned without a body when the client already has it
+func etagResp(c echo.Context, etag string, resp APIResp) error {
+	if notModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// cacheTime returns the time the data of a cache kind was fetched, zero if it is not cached
+func cacheTime(infos []ogame.CacheInfo, kind ogame.CacheKind) time.Time {
+	for _, info := range infos {
+		if info.Kind == kind && info.Cached {
+			return info.CachedAt
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPlanetsHandler_ETag(t *testing.T) {
+	bot, _ := ogame.NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	cachedAt := time.Now().Add(-10 * time.Second)
+	bot.ImportEmpire(ogame.EmpireExport{Time: cachedAt, Planets: []ogame.Planet{{ID: 123, Coordinate: ogame.Coordinate{Galaxy: 1, System: 2, Position: 3, Type: ogame.PlanetType}}}})
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("bot", bot)
+			return next(c)
+		}
+	})
+	e.GET("/bot/planets", GetPlanetsHandler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bot/planets?max_age=60", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.NotEqual(t, "", etag)
+	assert.Equal(t, etag, cachedVersionETag(bot, "planets", "", cachedAt))
+
+	// Answered from the cache version
+	req := httptest.NewRequest(http.MethodGet, "/bot/planets?max_age=60", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, 0, rec.Body.Len())
+}
+
+func TestCacheETag(t *testing.T) {
+	assert.Equal(t, "", cacheETag("galaxy", "1:2", time.Time{}))
+	at := time.Unix(1600000000, 0)
+	assert.NotEqual(t, cacheETag("galaxy", "1:2", at), cacheETag("galaxy", "1:3", at))
+	assert.NotEqual(t, cacheETag("galaxy", "1:2", at), cacheETag("galaxy", "1:2", at.Add(time.Second)))
+	assert.True(t, etagMatch(`W/"a", `+cacheETag("galaxy", "1:2", at), cacheETag("galaxy", "1:2", at)))
+}
+
+// fakeTx serves the data of the ETag endpoints without reaching the game, it is used as the transaction of the request
+type fakeTx struct {
+	ogame.Prioritizable
+	metal int64
+	calls int
+}
+
+func (f *fakeTx) GetResources(ogame.CelestialID) (ogame.Resources, error) {
+	f.calls++
+	return ogame.Resources{Metal: f.metal}, nil
+}
+
+func (f *fakeTx) GetTechs(ogame.CelestialID) (ogame.ResourcesBuildings, ogame.Facilities, ogame.ShipsInfos, ogame.DefensesInfos, ogame.Researches, error) {
+	f.calls++
+	return ogame.ResourcesBuildings{MetalMine: f.metal}, ogame.Facilities{}, ogame.ShipsInfos{}, ogame.DefensesInfos{}, ogame.Researches{}, nil
+}
+
+func (f *fakeTx) GalaxyInfos(int64, int64, ...ogame.Option) (ogame.SystemInfos, error) {
+	f.calls++
+	var res ogame.SystemInfos
+	res.ExpeditionDebris.Metal = f.metal
+	return res, nil
+}
+
+func (f *fakeTx) GetPlanetsMaxAge(time.Duration) ([]ogame.Planet, ogame.Freshness) {
+	f.calls++
+	return []ogame.Planet{{ID: ogame.PlanetID(f.metal)}}, ogame.Freshness{CachedAt: time.Now(), Source: ogame.FreshnessSourceLive}
+}
+
+func TestETagHandlers_WithoutMaxAge(t *testing.T) {
+	for _, path := range []string{"/bot/planets", "/bot/planets/123/resources", "/bot/celestials/123/techs", "/bot/galaxy-infos/1/2"} {
+		t.Run(path, func(t *testing.T) {
+			bot, _ := ogame.NewNoLogin("", "", "", "", "", "", "", 0, nil)
+			fake := &fakeTx{metal: 1}
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set("bot", bot)
+					c.Set("tx", ogame.Prioritizable(fake))
+					return next(c)
+				}
+			})
+			e.GET("/bot/planets", GetPlanetsHandler)
+			e.GET("/bot/planets/:planetID/resources", GetResourcesHandler)
+			e.GET("/bot/celestials/:celestialID/techs", TechsHandler)
+			e.GET("/bot/galaxy-infos/:galaxy/:system", GalaxyInfosHandler)
+			get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if ifNoneMatch != "" {
+					req.Header.Set("If-None-Match", ifNoneMatch)
+				}
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				return rec
+			}
+
+			rec := get("")
+			assert.Equal(t, http.StatusOK, rec.Code)
+			etag := rec.Header().Get("ETag")
+			assert.True(t, strings.HasPrefix(etag, `"`))
+
+			// Refetched, the data did not change
+			rec = get(etag)
+			assert.Equal(t, http.StatusNotModified, rec.Code)
+			assert.Equal(t, 0, rec.Body.Len())
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+			// The data changed, a new version is served
+			fake.metal = 2
+			rec = get(etag)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+			assert.Equal(t, 3, fake.calls)
+		})
+	}
+}
+
+func TestVersionETag(t *testing.T) {
+	bot, _ := ogame.NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	cachedAt := time.Now()
+	etag := versionETag(bot, "researches", "", ogame.Researches{EnergyTechnology: 1}, cachedAt)
+	assert.Equal(t, etag, cachedVersionETag(bot, "researches", "", cachedAt))
+	assert.Equal(t, "", cachedVersionETag(bot, "researches", "", cachedAt.Add(time.Second)))
+	// Refetched with the same content, the version is kept
+	assert.Equal(t, etag, versionETag(bot, "researches", "", ogame.Researches{EnergyTechnology: 1}, cachedAt.Add(time.Second)))
+	assert.NotEqual(t, etag, versionETag(bot, "researches", "", ogame.Researches{EnergyTechnology: 2}, cachedAt.Add(2*time.Second)))
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo"
 
@@ -14,10 +20,12 @@ import (
 
 // APIResp ...
 type APIResp struct {
-	Status  string
-	Code    int
-	Message string
-	Result  interface{}
+	Status    string
+	Code      int
+	ErrorCode ogame.ErrorCode `json:",omitempty"` // Machine-readable category of the error
+	Message   string
+	Result    interface{}
+	Freshness *ogame.Freshness `json:",omitempty"`
 }
 
 // SuccessResp ...
@@ -25,11 +33,80 @@ func SuccessResp(data interface{}) APIResp {
 	return APIResp{Status: "ok", Code: 200, Result: data}
 }
 
+// FreshResp success response with the freshness of data that may come from the bot cache
+func FreshResp(data interface{}, freshness ogame.Freshness) APIResp {
+	resp := SuccessResp(data)
+	resp.Freshness = &freshness
+	return resp
+}
+
 // ErrorResp ...
 func ErrorResp(code int, message string) APIResp {
 	return APIResp{Status: "error", Code: code, Message: message}
 }
 
+var errorCodeStatuses = map[ogame.ErrorCode]int{
+	ogame.ErrCodeNotLoggedIn:        http.StatusUnauthorized,
+	ogame.ErrCodeBotUnavailable:     http.StatusServiceUnavailable,
+	ogame.ErrCodeServerUnavailable:  http.StatusServiceUnavailable,
+	ogame.ErrCodeRateLimited:        http.StatusTooManyRequests,
+	ogame.ErrCodeCaptchaRequired:    http.StatusConflict,
+	ogame.ErrCodeBadCredentials:     http.StatusBadRequest,
+	ogame.ErrCodeAccountBanned:      http.StatusForbidden,
+	ogame.ErrCodeNotEnoughResources: http.StatusBadRequest,
+	ogame.ErrCodeNoFreeSlot:         http.StatusConflict,
+	ogame.ErrCodeTargetNotExists:    http.StatusNotFound,
+	ogame.ErrCodeFleetInvalid:       http.StatusBadRequest,
+	ogame.ErrCodeCooldown:           http.StatusConflict,
+	ogame.ErrCodeTimeout:            http.StatusGatewayTimeout,
+	ogame.ErrCodeInvalidRequest:     http.StatusBadRequest,
+	ogame.ErrCodeRequirementsNotMet: http.StatusBadRequest,
+}
+
+// ErrorRespOf error response of err, the HTTP status and error code depend on the category of the error.
+// fallback is the HTTP status of the uncategorized errors.
+func ErrorRespOf(err error, fallback int) APIResp {
+	code := ogame.GetErrorCode(err)
+	status, ok := errorCodeStatuses[code]
+	if !ok {
+		status = fallback
+		if fallback == http.StatusBadRequest {
+			code = ogame.ErrCodeInvalidRequest
+		}
+	}
+	resp := ErrorResp(status, err.Error())
+	resp.ErrorCode = code
+	return resp
+}
+
+// errorJSON sends the error response of err, see ErrorRespOf
+func errorJSON(c echo.Context, err error, fallback int) error {
+	resp := ErrorRespOf(err, fallback)
+	return c.JSON(resp.Code, resp)
+}
+
+// parseMaxAge parses the max_age query parameter (seconds), cached data older than max_age is refetched.
+// Without max_age the data is always fetched from the game.
+func parseMaxAge(c echo.Context) (time.Duration, error) {
+	maxAge := c.QueryParam("max_age")
+	if maxAge == "" {
+		return 0, nil
+	}
+	secs, err := strconv.ParseInt(maxAge, 10, 64)
+	if err != nil || secs < 0 {
+		return 0, errors.New("invalid max_age")
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// parseCelestialIDParam parses a celestial id path parameter, which can also be a celestial alias
+func parseCelestialIDParam(bot *ogame.OGame, param string) (int64, error) {
+	if id, ok := bot.ResolveCelestialAlias(param); ok {
+		return int64(id), nil
+	}
+	return strconv.ParseInt(param, 10, 64)
+}
+
 // HomeHandler ...
 func HomeHandler(c echo.Context) error {
 	version := c.Get("version").(string)
@@ -48,6 +125,51 @@ func TasksHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, SuccessResp(bot.GetTasks()))
 }
 
+// TaskHistoryHandler returns the tasks that held the bot lock, with how long they waited for it and held it,
+// optionally filtered by date (unix seconds)
+// curl 127.0.0.1:1234/tasks/history?since=1600000000
+func TaskHistoryHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	var since time.Time
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		sinceUnix, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid since"))
+		}
+		since = time.Unix(sinceUnix, 0)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetTaskHistory(since)))
+}
+
+// GetAliasesHandler ...
+func GetAliasesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetCelestialAliases()))
+}
+
+// SetAliasHandler ...
+// curl 127.0.0.1:1234/bot/aliases/main -d 'celestialID=33624548'
+func SetAliasHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	alias := c.Param("alias")
+	if _, err := strconv.ParseInt(alias, 10, 64); err == nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "alias cannot be a number"))
+	}
+	celestialID, err := strconv.ParseInt(c.Request().PostFormValue("celestialID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
+	}
+	bot.SetCelestialAlias(alias, ogame.CelestialID(celestialID))
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// DeleteAliasHandler ...
+func DeleteAliasHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	bot.RemoveCelestialAlias(c.Param("alias"))
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
 // GetServerHandler ...
 func GetServerHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
@@ -60,6 +182,52 @@ func GetServerDataHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, SuccessResp(bot.GetServerData()))
 }
 
+// GetLobbyServersHandler lists the servers of the lobby, details=1 adds their server data (speeds, donut, version)
+// curl 127.0.0.1:1234/bot/lobby/servers?lang=en&details=1
+func GetLobbyServersHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	details, _ := strconv.ParseBool(c.QueryParam("details"))
+	servers, err := bot.GetLobbyServers(c.QueryParam("lang"), details)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(servers))
+}
+
+// AddAccountHandler creates an account on a server of the lobby, switch=1 logs the bot in the new account
+// curl 127.0.0.1:1234/bot/lobby/accounts -d 'number=150&lang=en&switch=1'
+func AddAccountHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	number, err := strconv.Atoi(c.Request().PostFormValue("number"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid server number"))
+	}
+	lang := c.Request().PostFormValue("lang")
+	if lang == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid lang"))
+	}
+	addAccount := bot.AddAccount
+	if switchAccount, _ := strconv.ParseBool(c.Request().PostFormValue("switch")); switchAccount {
+		addAccount = bot.AddAccountAndSwitch
+	}
+	newAccount, err := addAccount(number, lang)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	newAccount.BearerToken = ""
+	return c.JSON(http.StatusOK, SuccessResp(newAccount))
+}
+
+// GetLobbyAccountsHandler lists the game accounts of the lobby login, most recently played first
+// curl 127.0.0.1:1234/bot/lobby/accounts
+func GetLobbyAccountsHandler(c echo.Context) error {
+	accounts, err := prioritizable(c).GetLobbyAccounts()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(accounts))
+}
+
 // SetUserAgentHandler ...
 // curl 127.0.0.1:1234/bot/set-user-agent -d 'userAgent="New user agent"'
 func SetUserAgentHandler(c echo.Context) error {
@@ -81,33 +249,360 @@ func GetLanguageHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, SuccessResp(bot.GetLanguage()))
 }
 
+// GetObjNamesHandler returns the names of the objects by id, in the language of the account if lang is not given
+// curl '127.0.0.1:1234/bot/objects?lang=fr'
+func GetObjNamesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	names, err := bot.GetObjNames(c.QueryParam("lang"))
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(names))
+}
+
 // PageContentHandler ...
 // curl 127.0.0.1:1234/bot/page-content -d 'page=overview&cp=123'
 func PageContentHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	if err := c.Request().ParseForm(); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	pageHTML, _ := bot.GetPageContent(c.Request().Form)
+	pageHTML, _ := prioritizable(c).GetPageContent(c.Request().Form)
 	return c.JSON(http.StatusOK, SuccessResp(pageHTML))
 }
 
+// GetSessionCredentialsHandler returns the authenticated cookies and bearer token
+// curl 127.0.0.1:1234/bot/session
+func GetSessionCredentialsHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetSessionCredentials()))
+}
+
 // LoginHandler ...
+// otp is a one-time code of the authenticator app, used when the account has the two-factor authentication
+// curl '127.0.0.1:1234/bot/login?otp=123456'
 func LoginHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	if _, err := bot.LoginWithExistingCookies(); err != nil {
-		if err == ogame.ErrBadCredentials {
-			return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
-		}
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if otpCode := c.FormValue("otp"); otpCode != "" {
+		bot := c.Get("bot").(*ogame.OGame)
+		bot.SetOTPCode(otpCode)
+	}
+	if _, err := prioritizable(c).LoginWithExistingCookies(); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
 
 // LogoutHandler ...
 func LogoutHandler(c echo.Context) error {
+	prioritizable(c).Logout()
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// IsInSafeModeHandler ...
+func IsInSafeModeHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.IsInSafeMode()))
+}
+
+// GetProxiesHandler returns the status of the proxy pool
+// curl 127.0.0.1:1234/bot/proxies
+func GetProxiesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	pool := bot.GetProxyPool()
+	if pool == nil {
+		return c.JSON(http.StatusOK, SuccessResp([]ogame.ProxyStatus{}))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(pool.Status()))
+}
+
+// GetPhalanxWatchesHandler lists the phalanx watches and the fleet movements they saw
+// curl 127.0.0.1:1234/bot/phalanx-watches
+func GetPhalanxWatchesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetPhalanxWatches()))
+}
+
+// AddPhalanxWatchHandler scans a coordinate from a moon every interval seconds (min 60)
+// curl 127.0.0.1:1234/bot/phalanx-watches -d 'moonID=123&galaxy=1&system=2&position=3&interval=600'
+func AddPhalanxWatchHandler(c echo.Context) error {
+	if InTx(c) {
+		return TxNotSupported(c)
+	}
+	bot := c.Get("bot").(*ogame.OGame)
+	moonID, err := strconv.ParseInt(c.Request().PostFormValue("moonID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid moon id"))
+	}
+	galaxy, err := strconv.ParseInt(c.Request().PostFormValue("galaxy"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
+	}
+	system, err := strconv.ParseInt(c.Request().PostFormValue("system"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid system"))
+	}
+	position, err := strconv.ParseInt(c.Request().PostFormValue("position"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
+	}
+	interval, err := strconv.ParseInt(c.Request().PostFormValue("interval"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid interval"))
+	}
+	coord := ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position}
+	watch, err := bot.AddPhalanxWatch(ogame.MoonID(moonID), coord, time.Duration(interval)*time.Second)
+	if err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(watch))
+}
+
+// RemovePhalanxWatchHandler stops a phalanx watch
+// curl 127.0.0.1:1234/bot/phalanx-watches/1 -X DELETE
+func RemovePhalanxWatchHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid id"))
+	}
+	if err := bot.RemovePhalanxWatch(id); err != nil {
+		return c.JSON(http.StatusNotFound, ErrorResp(404, err.Error()))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetExpeditionLoopsHandler lists the expeditions re-sent every time they return, running and stopped
+// curl 127.0.0.1:1234/bot/expeditions/loops
+func GetExpeditionLoopsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	bot.Logout()
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetExpeditionLoops()))
+}
+
+// StopExpeditionLoopHandler stops an expedition loop, the fleet in flight is not recalled
+// curl 127.0.0.1:1234/bot/expeditions/loops/1 -X DELETE
+func StopExpeditionLoopHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid id"))
+	}
+	if err := bot.StopExpeditionLoop(id); err != nil {
+		return c.JSON(http.StatusNotFound, ErrorResp(404, err.Error()))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetWatchlistHandler lists the players watched by the military score alerts
+// curl 127.0.0.1:1234/bot/watchlist
+func GetWatchlistHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetWatchlist()))
+}
+
+// AddWatchedPlayerHandler adds a player to the watchlist of the military score alerts, the player must be in the
+// military highscore
+// curl 127.0.0.1:1234/bot/watchlist -d 'playerID=123'
+func AddWatchedPlayerHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	playerID, err := strconv.ParseInt(c.Request().PostFormValue("playerID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid player id"))
+	}
+	player, err := bot.AddWatchedPlayer(playerID)
+	if err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(player))
+}
+
+// RemoveWatchedPlayerHandler removes a player from the watchlist
+// curl 127.0.0.1:1234/bot/watchlist/123 -X DELETE
+func RemoveWatchedPlayerHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	playerID, err := strconv.ParseInt(c.Param("playerID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid player id"))
+	}
+	if err := bot.RemoveWatchedPlayer(playerID); err != nil {
+		return c.JSON(http.StatusNotFound, ErrorResp(404, err.Error()))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetEscapeRulesHandler lists the celestials protected by the escape responder
+// curl 127.0.0.1:1234/bot/escape-rules
+func GetEscapeRulesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetEscapeRules()))
+}
+
+// SetEscapeRuleHandler fleet saves a celestial when an attack lands within threshold seconds.
+// Destination defaults to the closest own celestial not under attack, mission to park, speed to 10%.
+// curl 127.0.0.1:1234/bot/escape-rules -d 'celestialID=123&threshold=300&resources=true&recall=true'
+// curl 127.0.0.1:1234/bot/escape-rules -d 'celestialID=123&galaxy=1&system=2&position=3&type=3&mission=4&speed=1'
+func SetEscapeRuleHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	form := c.Request()
+	celestialID, err := strconv.ParseInt(form.PostFormValue("celestialID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
+	}
+	rule := ogame.EscapeRule{CelestialID: ogame.CelestialID(celestialID)}
+	if thresholdStr := form.PostFormValue("threshold"); thresholdStr != "" {
+		threshold, err := strconv.ParseInt(thresholdStr, 10, 64)
+		if err != nil || threshold <= 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid threshold"))
+		}
+		rule.Threshold = time.Duration(threshold) * time.Second
+	}
+	if missionStr := form.PostFormValue("mission"); missionStr != "" {
+		mission, err := strconv.ParseInt(missionStr, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid mission"))
+		}
+		rule.Mission = ogame.MissionID(mission)
+	}
+	if speedStr := form.PostFormValue("speed"); speedStr != "" {
+		speed, err := strconv.ParseFloat(speedStr, 64)
+		if err != nil || !ogame.Speed(speed).IsValid(bot.CharacterClass()) {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid speed"))
+		}
+		rule.Speed = ogame.Speed(speed)
+	}
+	if form.PostFormValue("galaxy") != "" {
+		galaxy, err := strconv.ParseInt(form.PostFormValue("galaxy"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
+		}
+		system, err := strconv.ParseInt(form.PostFormValue("system"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid system"))
+		}
+		position, err := strconv.ParseInt(form.PostFormValue("position"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
+		}
+		typ := ogame.PlanetType
+		if typeStr := form.PostFormValue("type"); typeStr != "" {
+			typeInt, err := strconv.ParseInt(typeStr, 10, 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid type"))
+			}
+			typ = ogame.CelestialType(typeInt)
+		}
+		rule.Destination = &ogame.Coordinate{Galaxy: galaxy, System: system, Position: position, Type: typ}
+	}
+	rule.Resources = form.PostFormValue("resources") == "true"
+	rule.Recall = form.PostFormValue("recall") == "true"
+	if err := bot.SetEscapeRule(rule); err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// RemoveEscapeRuleHandler stops protecting a celestial with the escape responder
+// curl 127.0.0.1:1234/bot/escape-rules/123 -X DELETE
+func RemoveEscapeRuleHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	celestialID, err := strconv.ParseInt(c.Param("celestialID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
+	}
+	bot.RemoveEscapeRule(ogame.CelestialID(celestialID))
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetAuditLogHandler returns the mutating actions executed by the bot, optionally filtered by action and date (unix seconds)
+// curl 127.0.0.1:1234/bot/audit?since=1600000000&action=SendFleet
+func GetAuditLogHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	var since time.Time
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		sinceUnix, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid since"))
+		}
+		since = time.Unix(sinceUnix, 0)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetAuditLog(since, c.QueryParam("action"))))
+}
+
+// GetMarketplacePricesHandler returns the average/median unit price of the items observed on the marketplace,
+// optionally filtered by item and date (unix seconds), per interval (eg: 24h)
+// curl 127.0.0.1:1234/bot/marketplace/prices?itemID=1&since=1600000000&interval=24h
+func GetMarketplacePricesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	var since time.Time
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		sinceUnix, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid since"))
+		}
+		since = time.Unix(sinceUnix, 0)
+	}
+	var interval time.Duration
+	if intervalStr := c.QueryParam("interval"); intervalStr != "" {
+		var err error
+		if interval, err = time.ParseDuration(intervalStr); err != nil || interval < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid interval"))
+		}
+	}
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetMarketplacePrices(since, interval, c.QueryParam("itemID"))))
+}
+
+// RecordMarketplacePriceHandler records an offer of another player seen on the marketplace (kind=listing) or a
+// completed trade (kind=trade), side is buy or sell, price is the price of the whole quantity, priceType the resource
+// it is paid in (1: metal, 2: crystal, 3: deuterium). The offers of the bot and its trades are recorded automatically.
+// curl 127.0.0.1:1234/bot/marketplace/prices -d 'kind=listing&side=sell&itemID=204&quantity=100&priceType=1&price=250000'
+func RecordMarketplacePriceHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	o := ogame.MarketplaceObservation{Kind: c.Request().PostFormValue("kind"), Side: c.Request().PostFormValue("side"),
+		ItemID: c.Request().PostFormValue("itemID")}
+	var err error
+	if o.Quantity, err = strconv.ParseInt(c.Request().PostFormValue("quantity"), 10, 64); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid quantity"))
+	}
+	if o.PriceType, err = strconv.ParseInt(c.Request().PostFormValue("priceType"), 10, 64); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid priceType"))
+	}
+	if o.Price, err = strconv.ParseInt(c.Request().PostFormValue("price"), 10, 64); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid price"))
+	}
+	if err := bot.RecordMarketplaceObservation(o); err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetCacheHandler returns the state of the bot caches
+// curl 127.0.0.1:1234/bot/cache
+func GetCacheHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetCacheInfo()))
+}
+
+// InvalidateCacheHandler invalidates the given caches (planets, researches, characterClass, serverData, empire, galaxy), all if none
+// curl -X DELETE '127.0.0.1:1234/bot/cache?kind=planets&kind=researches'
+func InvalidateCacheHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	kinds := make([]ogame.CacheKind, 0)
+	for _, kindStr := range c.QueryParams()["kind"] {
+		kind, err := ogame.ParseCacheKind(kindStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		}
+		kinds = append(kinds, kind)
+	}
+	if err := prioritizable(c).InvalidateCache(kinds...); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetCacheInfo()))
+}
+
+// ResumeHandler leaves safe mode
+// curl 127.0.0.1:1234/bot/resume -X POST
+func ResumeHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	bot.Resume()
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
 
@@ -143,16 +638,14 @@ func ServerVersionHandler(c echo.Context) error {
 
 // ServerTimeHandler ...
 func ServerTimeHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	return c.JSON(http.StatusOK, SuccessResp(bot.ServerTime()))
+	return c.JSON(http.StatusOK, SuccessResp(prioritizable(c).ServerTime()))
 }
 
 // IsUnderAttackHandler ...
 func IsUnderAttackHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	isUnderAttack, err := bot.IsUnderAttack()
+	isUnderAttack, err := prioritizable(c).IsUnderAttack()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(isUnderAttack))
 }
@@ -166,8 +659,7 @@ func IsVacationModeHandler(c echo.Context) error {
 
 // GetUserInfosHandler ...
 func GetUserInfosHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	return c.JSON(http.StatusOK, SuccessResp(bot.GetUserInfos()))
+	return c.JSON(http.StatusOK, SuccessResp(prioritizable(c).GetUserInfos()))
 }
 
 // GetCharacterClassHandler ...
@@ -176,6 +668,19 @@ func GetCharacterClassHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, SuccessResp(bot.CharacterClass()))
 }
 
+// SetCharacterClassHandler switches the character class (1: collector, 2: general, 3: discoverer), costs dark matter
+// curl 127.0.0.1:1234/bot/character-class -d 'class=1'
+func SetCharacterClassHandler(c echo.Context) error {
+	class, err := strconv.ParseInt(c.Request().PostFormValue("class"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid class"))
+	}
+	if err := prioritizable(c).SetCharacterClass(ogame.CharacterClass(class)); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(ogame.CharacterClass(class)))
+}
+
 // HasCommanderHandler ...
 func HasCommanderHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
@@ -211,33 +716,257 @@ func HasTechnocratHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, SuccessResp(hasTechnocrat))
 }
 
-// GetEspionageReportMessagesHandler ...
-func GetEspionageReportMessagesHandler(c echo.Context) error {
+// GetOfficersHandler returns the hired officers and the fleet slots they give
+// curl 127.0.0.1:1234/bot/officers
+func GetOfficersHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	officers := bot.GetCachedOfficers()
+	return c.JSON(http.StatusOK, SuccessResp(struct {
+		ogame.Officers
+		CommandingStaff bool
+		MaxFleetSlots   int64
+	}{
+		Officers:        officers,
+		CommandingStaff: officers.IsCommandingStaff(),
+		MaxFleetSlots:   ogame.MaxFleetSlots(prioritizable(c).GetCachedResearch().ComputerTechnology, officers, bot.CharacterClass()),
+	}))
+}
+
+// GetEspionageReportMessagesHandler ...
+func GetEspionageReportMessagesHandler(c echo.Context) error {
+	report, err := prioritizable(c).GetEspionageReportMessages()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(report))
+}
+
+// SearchMessagesHandler searches the inbox messages by coordinate, player name and date range (unix timestamps).
+// tabs defaults to espionage, combat reports, expeditions and transports (20,21,22,23).
+// A coordinate without a P/M/D prefix matches both the planet and the moon.
+// curl '127.0.0.1:1234/bot/messages/search?coord=1:2:3&player=Bob&from=1593000000&to=1594000000&tabs=20,21&max_pages=5'
+func SearchMessagesHandler(c echo.Context) error {
+	var query ogame.MessageQuery
+	if tabsStr := c.QueryParam("tabs"); tabsStr != "" {
+		for _, tabStr := range strings.Split(tabsStr, ",") {
+			tab, err := strconv.ParseInt(strings.TrimSpace(tabStr), 10, 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid tabs"))
+			}
+			query.Tabs = append(query.Tabs, tab)
+		}
+	}
+	if coordStr := c.QueryParam("coord"); coordStr != "" {
+		coord, err := ogame.ParseCoord(coordStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid coord"))
+		}
+		if !strings.ContainsAny(strings.TrimPrefix(coordStr, "[")[:1], "PMD") {
+			coord.Type = 0
+		}
+		query.Coordinate = &coord
+	}
+	query.PlayerName = c.QueryParam("player")
+	for _, p := range []struct {
+		name string
+		dest *time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		if str := c.QueryParam(p.name); str != "" {
+			ts, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid "+p.name))
+			}
+			*p.dest = time.Unix(ts, 0)
+		}
+	}
+	if maxPagesStr := c.QueryParam("max_pages"); maxPagesStr != "" {
+		maxPages, err := strconv.ParseInt(maxPagesStr, 10, 64)
+		if err != nil || maxPages < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid max_pages"))
+		}
+		query.MaxPages = maxPages
+	}
+	msgs, err := prioritizable(c).SearchMessages(query)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(msgs))
+}
+
+// GetCombatReportMessagesHandler lists the combat reports summaries, page=N returns a single page of the messages
+// curl 127.0.0.1:1234/bot/combat-reports?page=2
+func GetCombatReportMessagesHandler(c echo.Context) error {
+	pageStr := c.QueryParam("page")
+	if pageStr == "" {
+		reports, err := prioritizable(c).GetCombatReportMessages()
+		if err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
+		}
+		return c.JSON(http.StatusOK, SuccessResp(reports))
+	}
+	page, err := strconv.ParseInt(pageStr, 10, 64)
+	if err != nil || page < 1 {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid page"))
+	}
+	reports, nbPage, err := prioritizable(c).GetCombatReportMessagesPage(page)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
+		"Page":    page,
+		"NbPage":  nbPage,
+		"Reports": reports,
+	}))
+}
+
+// GetEspionageReportHandler ...
+func GetEspionageReportHandler(c echo.Context) error {
+	msgID, err := strconv.ParseInt(c.Param("msgid"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid msgid id"))
+	}
+	espionageReport, err := prioritizable(c).GetEspionageReport(msgID)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(espionageReport))
+}
+
+// GetEspionageReportShareKeyHandler returns the share key (sr-...) of an espionage report
+// curl 127.0.0.1:1234/bot/espionage-report/123456/share-key
+func GetEspionageReportShareKeyHandler(c echo.Context) error {
+	msgID, err := strconv.ParseInt(c.Param("msgid"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid msgid id"))
+	}
+	key, err := prioritizable(c).GetEspionageReportShareKey(msgID)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(key))
+}
+
+// GetEspionageReportExportHandler converts an espionage report for a combat simulator or report tool.
+// format is trashsim (default) or ogotcha. With celestialID, the celestial and the player researches are the attacker of
+// the TrashSim simulation.
+// curl 127.0.0.1:1234/bot/espionage-report/123456/export?format=trashsim&celestialID=123
+func GetEspionageReportExportHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	msgID, err := strconv.ParseInt(c.Param("msgid"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid msgid id"))
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "trashsim"
+	}
+	if format != "trashsim" && format != "ogotcha" {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid format"))
+	}
+	var attacker *ogame.TrashSimParty
+	if c.QueryParam("celestialID") != "" {
+		celestialID, err := parseCelestialIDParam(bot, c.QueryParam("celestialID"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
+		}
+		celestial := bot.GetCachedCelestial(ogame.CelestialID(celestialID))
+		if celestial == nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
+		}
+		party := ogame.NewTrashSimParty(celestial.GetCoordinate(), prioritizable(c).GetCachedResearch(), bot.CharacterClass())
+		attacker = &party
+	}
+	espionageReport, err := prioritizable(c).GetEspionageReport(msgID)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	if format == "ogotcha" {
+		export, err := espionageReport.OGotcha()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		}
+		return c.JSON(http.StatusOK, SuccessResp(export))
+	}
+	prefill := espionageReport.TrashSim(attacker)
+	u, err := prefill.URL()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
+		"Prefill": prefill,
+		"URL":     u,
+	}))
+}
+
+// GetAPIReportHandler fetches the report of a share key (sr-, cr-, rr- or mr-) from the public API of its universe
+// curl 127.0.0.1:1234/bot/api-report/sr-en-101-0123abcd
+func GetAPIReportHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	report, err := bot.GetEspionageReportMessages()
+	if _, err := ogame.ParseShareKey(c.Param("key")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid key"))
+	}
+	report, err := bot.ParseReportFromAPIKey(c.Param("key"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusBadGateway)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(report))
 }
 
-// GetEspionageReportHandler ...
-func GetEspionageReportHandler(c echo.Context) error {
+func parseLootParams(c echo.Context, bot *ogame.OGame) (float64, ogame.CharacterClass, error) {
+	var plunderRatio float64
+	if ratioStr := c.QueryParam("plunderRatio"); ratioStr != "" {
+		var err error
+		if plunderRatio, err = strconv.ParseFloat(ratioStr, 64); err != nil || plunderRatio < 0 || plunderRatio > 1 {
+			return 0, 0, errors.New("invalid plunderRatio")
+		}
+	}
+	class := bot.CharacterClass()
+	if classStr := c.QueryParam("class"); classStr != "" {
+		classInt, err := strconv.ParseInt(classStr, 10, 64)
+		if err != nil || classInt < 0 || classInt > 3 {
+			return 0, 0, errors.New("invalid class")
+		}
+		class = ogame.CharacterClass(classInt)
+	}
+	return plunderRatio, class, nil
+}
+
+// GetEspionageReportLootHandler estimates the loot of the target of an espionage report, including the production since the report
+// curl 127.0.0.1:1234/bot/espionage-report/123456/loot?plunderRatio=0.5&class=3
+func GetEspionageReportLootHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
 	msgID, err := strconv.ParseInt(c.Param("msgid"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid msgid id"))
 	}
-	espionageReport, err := bot.GetEspionageReport(msgID)
+	plunderRatio, class, err := parseLootParams(c, bot)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	return c.JSON(http.StatusOK, SuccessResp(espionageReport))
+	espionageReport, err := prioritizable(c).GetEspionageReport(msgID)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(bot.EstimateLoot(espionageReport, plunderRatio, class)))
+}
+
+// EstimateLootHandler estimates the loot of espionage reports (JSON array) sent by the client, best target first
+// curl 127.0.0.1:1234/bot/loot-estimate?plunderRatio=0.5 -H 'Content-Type: application/json' -d '[{"Metal":100000,"Coordinate":{"Galaxy":1,"System":2,"Position":3,"Type":1}}]'
+func EstimateLootHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	plunderRatio, class, err := parseLootParams(c, bot)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	var reports []ogame.EspionageReport
+	if err := json.NewDecoder(c.Request().Body).Decode(&reports); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid reports"))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(bot.RankFarmTargets(reports, plunderRatio, class)))
 }
 
 // GetEspionageReportForHandler ...
 func GetEspionageReportForHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	galaxy, err := strconv.ParseInt(c.Param("galaxy"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
@@ -250,68 +979,82 @@ func GetEspionageReportForHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
 	}
-	planet, err := bot.GetEspionageReportFor(ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position})
+	planet, err := prioritizable(c).GetEspionageReportFor(ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(planet))
 }
 
+// DiffEspionageReportsHandler compares the two most recent espionage reports of a coordinate
+// curl 127.0.0.1:1234/bot/espionage-report/1/2/3/diff
+func DiffEspionageReportsHandler(c echo.Context) error {
+	galaxy, err := strconv.ParseInt(c.Param("galaxy"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
+	}
+	system, err := strconv.ParseInt(c.Param("system"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid system"))
+	}
+	position, err := strconv.ParseInt(c.Param("position"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
+	}
+	diff, err := prioritizable(c).DiffLatestEspionageReports(ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position})
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(diff))
+}
+
 // SendMessageHandler ...
 // curl 127.0.0.1:1234/bot/send-message -d 'playerID=123&message="Sup boi!"'
 func SendMessageHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	playerID, err := strconv.ParseInt(c.Request().PostFormValue("playerID"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
 	message := c.Request().PostFormValue("message")
-	if err := bot.SendMessage(playerID, message); err != nil {
-		if err.Error() == "invalid parameters" {
-			return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
-		}
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := prioritizable(c).SendMessage(playerID, message); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
 
 // GetFleetsHandler ...
 func GetFleetsHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	fleets, _ := bot.GetFleets()
+	fleets, _ := prioritizable(c).GetFleets()
 	return c.JSON(http.StatusOK, SuccessResp(fleets))
 }
 
 // GetSlotsHandler ...
 func GetSlotsHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	slots := bot.GetSlots()
+	slots := prioritizable(c).GetSlots()
 	return c.JSON(http.StatusOK, SuccessResp(slots))
 }
 
 // CancelFleetHandler ...
 func CancelFleetHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	fleetID, err := strconv.ParseInt(c.Param("fleetID"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	return c.JSON(http.StatusOK, SuccessResp(bot.CancelFleet(ogame.FleetID(fleetID))))
+	return c.JSON(http.StatusOK, SuccessResp(prioritizable(c).CancelFleet(ogame.FleetID(fleetID))))
 }
 
 // GetAttacksHandler ...
 func GetAttacksHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	attacks, err := bot.GetAttacks()
+	attacks, err := prioritizable(c).GetAttacks()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(attacks))
 }
 
 // GalaxyInfosHandler ...
+// curl 127.0.0.1:1234/bot/galaxy-infos/1/2?skipCache=1
 func GalaxyInfosHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	galaxy, err := strconv.ParseInt(c.Param("galaxy"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
@@ -320,42 +1063,134 @@ func GalaxyInfosHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	res, err := bot.GalaxyInfos(galaxy, system)
+	bot := c.Get("bot").(*ogame.OGame)
+	key := strconv.FormatInt(galaxy, 10) + ":" + strconv.FormatInt(system, 10)
+	opts := make([]ogame.Option, 0)
+	if skipCache, _ := strconv.ParseBool(c.QueryParam("skipCache")); skipCache {
+		opts = append(opts, ogame.SkipCache)
+	} else if notModified(c, cachedVersionETag(bot, "galaxy", key, bot.GalaxyCachedAt(galaxy, system))) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	res, err := prioritizable(c).GalaxyInfos(galaxy, system, opts...)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return etagResp(c, versionETag(bot, "galaxy", key, res, bot.GalaxyCachedAt(galaxy, system)), SuccessResp(res))
+}
+
+// GetInactiveTargetsHandler returns the planets of the inactive players from fromSystem to toSystem of a galaxy
+// curl '127.0.0.1:1234/bot/galaxy/inactives?galaxy=1&fromSystem=1&toSystem=50&longInactiveOnly=1&includeVacation=0&minRank=1000'
+func GetInactiveTargetsHandler(c echo.Context) error {
+	galaxy, err := strconv.ParseInt(c.QueryParam("galaxy"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
+	}
+	fromSystem, err := strconv.ParseInt(c.QueryParam("fromSystem"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid fromSystem"))
+	}
+	toSystem, err := strconv.ParseInt(c.QueryParam("toSystem"), 10, 64)
+	if err != nil || toSystem < fromSystem {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid toSystem"))
+	}
+	var filter ogame.InactiveTargetsFilter
+	filter.LongInactiveOnly, _ = strconv.ParseBool(c.QueryParam("longInactiveOnly"))
+	filter.IncludeVacation, _ = strconv.ParseBool(c.QueryParam("includeVacation"))
+	filter.IncludeBanned, _ = strconv.ParseBool(c.QueryParam("includeBanned"))
+	if minRankStr := c.QueryParam("minRank"); minRankStr != "" {
+		if filter.MinRank, err = strconv.ParseInt(minRankStr, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid minRank"))
+		}
+	}
+	res, err := prioritizable(c).GetInactiveTargets(galaxy, fromSystem, toSystem, filter)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(res))
+}
+
+// FindColonySpotsHandler returns the free positions to colonize from fromSystem to toSystem of a galaxy, safest first.
+// positions defaults to 8-12 then 4-6, radius is the number of systems checked on each side of a spot.
+// curl '127.0.0.1:1234/bot/colonize/spots?galaxy=1&fromSystem=1&toSystem=50&positions=8,9,10&radius=2&maxActiveNeighbors=10&safeOnly=1'
+func FindColonySpotsHandler(c echo.Context) error {
+	var galaxyRange ogame.GalaxyRange
+	var err error
+	if galaxyRange.Galaxy, err = strconv.ParseInt(c.QueryParam("galaxy"), 10, 64); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
+	}
+	if galaxyRange.FromSystem, err = strconv.ParseInt(c.QueryParam("fromSystem"), 10, 64); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid fromSystem"))
+	}
+	galaxyRange.ToSystem, err = strconv.ParseInt(c.QueryParam("toSystem"), 10, 64)
+	if err != nil || galaxyRange.ToSystem < galaxyRange.FromSystem {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid toSystem"))
+	}
+	var criteria ogame.ColonySpotsCriteria
+	if positionsStr := c.QueryParam("positions"); positionsStr != "" {
+		for _, posStr := range strings.Split(positionsStr, ",") {
+			pos, err := strconv.ParseInt(strings.TrimSpace(posStr), 10, 64)
+			if err != nil || pos < 1 || pos > 15 {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid positions"))
+			}
+			criteria.Positions = append(criteria.Positions, pos)
+		}
+	}
+	if radiusStr := c.QueryParam("radius"); radiusStr != "" {
+		if criteria.Radius, err = strconv.ParseInt(radiusStr, 10, 64); err != nil || criteria.Radius < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid radius"))
+		}
+	}
+	if maxStr := c.QueryParam("maxActiveNeighbors"); maxStr != "" {
+		if criteria.MaxActiveNeighbors, err = strconv.ParseInt(maxStr, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid maxActiveNeighbors"))
+		}
+	}
+	criteria.SafeOnly, _ = strconv.ParseBool(c.QueryParam("safeOnly"))
+	res, err := prioritizable(c).FindColonySpots(galaxyRange, criteria)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
 
 // GetResearchHandler ...
+// curl 127.0.0.1:1234/bot/get-research?max_age=60
 func GetResearchHandler(c echo.Context) error {
+	maxAge, err := parseMaxAge(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
 	bot := c.Get("bot").(*ogame.OGame)
-	return c.JSON(http.StatusOK, SuccessResp(bot.GetResearch()))
+	if cachedAt := cacheTime(bot.GetCacheInfo(), ogame.ResearchesCache); maxAge > 0 && time.Since(cachedAt) <= maxAge &&
+		notModified(c, cachedVersionETag(bot, "researches", "", cachedAt)) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	researches, freshness := prioritizable(c).GetResearchMaxAge(maxAge)
+	etag := versionETag(bot, "researches", "", researches, freshness.CachedAt)
+	return etagResp(c, etag, FreshResp(researches, freshness))
 }
 
 // BuyOfferOfTheDayHandler ...
 func BuyOfferOfTheDayHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	if err := bot.BuyOfferOfTheDay(); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	if err := prioritizable(c).BuyOfferOfTheDay(); err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
 
 // GetMoonsHandler ...
 func GetMoonsHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	return c.JSON(http.StatusOK, SuccessResp(bot.GetMoons()))
+	return c.JSON(http.StatusOK, SuccessResp(prioritizable(c).GetMoons()))
 }
 
 // GetMoonHandler ...
 func GetMoonHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	moonID, err := strconv.ParseInt(c.Param("moonID"), 10, 64)
+	moonID, err := parseCelestialIDParam(bot, c.Param("moonID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid moon id"))
 	}
-	moon, err := bot.GetMoon(moonID)
+	moon, err := prioritizable(c).GetMoon(moonID)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid moon id"))
 	}
@@ -364,7 +1199,6 @@ func GetMoonHandler(c echo.Context) error {
 
 // GetMoonByCoordHandler ...
 func GetMoonByCoordHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	galaxy, err := strconv.ParseInt(c.Param("galaxy"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
@@ -377,29 +1211,49 @@ func GetMoonByCoordHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
 	}
-	planet, err := bot.GetMoon(ogame.Coordinate{Type: ogame.MoonType, Galaxy: galaxy, System: system, Position: position})
+	planet, err := prioritizable(c).GetMoon(ogame.Coordinate{Type: ogame.MoonType, Galaxy: galaxy, System: system, Position: position})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(planet))
 }
 
 // GetPlanetsHandler ...
+// curl 127.0.0.1:1234/bot/planets?max_age=60
 func GetPlanetsHandler(c echo.Context) error {
+	maxAge, err := parseMaxAge(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
 	bot := c.Get("bot").(*ogame.OGame)
-	return c.JSON(http.StatusOK, SuccessResp(bot.GetPlanets()))
+	if cachedAt := cacheTime(bot.GetCacheInfo(), ogame.PlanetsCache); maxAge > 0 && time.Since(cachedAt) <= maxAge &&
+		notModified(c, cachedVersionETag(bot, "planets", "", cachedAt)) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	planets, freshness := prioritizable(c).GetPlanetsMaxAge(maxAge)
+	return etagResp(c, versionETag(bot, "planets", "", planets, freshness.CachedAt), FreshResp(planets, freshness))
 }
 
 // GetCelestialItemsHandler ...
 func GetCelestialItemsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	celestialID, err := strconv.ParseInt(c.Param("celestialID"), 10, 64)
+	celestialID, err := parseCelestialIDParam(bot, c.Param("celestialID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
 	}
-	items, err := bot.GetItems(ogame.CelestialID(celestialID))
+	items, err := prioritizable(c).GetItems(ogame.CelestialID(celestialID))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(items))
+}
+
+// GetAllItemsHandler returns the items inventory and the items active on every celestial with their expiry
+// curl 127.0.0.1:1234/bot/items
+func GetAllItemsHandler(c echo.Context) error {
+	items, err := prioritizable(c).GetAllItems()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(items))
 }
@@ -407,13 +1261,13 @@ func GetCelestialItemsHandler(c echo.Context) error {
 // ActivateCelestialItemHandler ...
 func ActivateCelestialItemHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	celestialID, err := strconv.ParseInt(c.Param("celestialID"), 10, 64)
+	celestialID, err := parseCelestialIDParam(bot, c.Param("celestialID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
 	}
 	ref := c.Param("itemRef")
-	if err := bot.ActivateItem(ref, ogame.CelestialID(celestialID)); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	if err := prioritizable(c).ActivateItem(ref, ogame.CelestialID(celestialID)); err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -421,20 +1275,84 @@ func ActivateCelestialItemHandler(c echo.Context) error {
 // GetPlanetHandler ...
 func GetPlanetHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	planet, err := bot.GetPlanet(ogame.PlanetID(planetID))
+	planet, err := prioritizable(c).GetPlanet(ogame.PlanetID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(planet))
 }
 
+// RenamePlanetHandler renames a planet or moon
+// curl 127.0.0.1:1234/bot/planets/123/rename -d 'name=Colony'
+func RenamePlanetHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	celestialID, err := parseCelestialIDParam(bot, c.Param("planetID"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
+	}
+	if err := prioritizable(c).RenamePlanet(ogame.CelestialID(celestialID), c.FormValue("name")); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// StartColonizeWorkflowHandler sends a colony ship, then renames the new planet, sends the transports of the feeder
+// planet and builds the template. The workflow runs in the background, see GetColonizeWorkflowsHandler.
+// curl 127.0.0.1:1234/bot/colonize -d '{"Origin":123,"Destination":{"Galaxy":1,"System":2,"Position":8,"Type":1},"Name":"Colony","Template":"colony","Feeder":123,"Transports":[{"Ships":[{"ID":203,"Nbr":5}],"Resources":{"Metal":50000}}]}'
+func StartColonizeWorkflowHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	var params ogame.ColonizeParams
+	if err := json.NewDecoder(c.Request().Body).Decode(&params); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid params"))
+	}
+	w, err := bot.StartColonizeWorkflow(params)
+	if err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(w))
+}
+
+// GetColonizeWorkflowsHandler lists the colonize workflows, running and finished
+// curl 127.0.0.1:1234/bot/colonize/workflows
+func GetColonizeWorkflowsHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetColonizeWorkflows()))
+}
+
+// CancelColonizeWorkflowHandler stops a running colonize workflow
+// curl 127.0.0.1:1234/bot/colonize/workflows/1 -X DELETE
+func CancelColonizeWorkflowHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid workflow id"))
+	}
+	if err := bot.CancelColonizeWorkflow(id); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetBuildTemplatesHandler lists the build templates usable by the colonize workflows
+// curl 127.0.0.1:1234/bot/build-templates
+func GetBuildTemplatesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetBuildTemplates()))
+}
+
+// GetFleetTemplatesHandler lists the fleet templates usable by the cron tasks
+// curl 127.0.0.1:1234/bot/fleet-templates
+func GetFleetTemplatesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetFleetTemplates()))
+}
+
 // GetPlanetByCoordHandler ...
 func GetPlanetByCoordHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	galaxy, err := strconv.ParseInt(c.Param("galaxy"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid galaxy"))
@@ -447,9 +1365,9 @@ func GetPlanetByCoordHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
 	}
-	planet, err := bot.GetPlanet(ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position})
+	planet, err := prioritizable(c).GetPlanet(ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(planet))
 }
@@ -457,95 +1375,176 @@ func GetPlanetByCoordHandler(c echo.Context) error {
 // GetResourcesDetailsHandler ...
 func GetResourcesDetailsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	resources, err := bot.GetResourcesDetails(ogame.CelestialID(planetID))
+	resources, err := prioritizable(c).GetResourcesDetails(ogame.CelestialID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(resources))
 }
 
+// GetStorageETAHandler returns the storages of every planet and when they will be full
+// curl 127.0.0.1:1234/bot/storage-eta
+func GetStorageETAHandler(c echo.Context) error {
+	etas, err := prioritizable(c).GetStorageETA()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(etas))
+}
+
+// GetExpeditionStatsHandler returns the expedition outcomes per day and per slot, since defaults to the last 7 days
+// curl 127.0.0.1:1234/bot/expeditions/stats?since=1600000000
+func GetExpeditionStatsHandler(c echo.Context) error {
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		sinceUnix, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid since"))
+		}
+		since = time.Unix(sinceUnix, 0)
+	}
+	stats, err := prioritizable(c).GetExpeditionStats(since)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(stats))
+}
+
+// GetNextBuildsHandler ranks the mines, plasma technology and astrophysics upgrades by payback time, best first
+// curl 127.0.0.1:1234/bot/advisor/next-builds?limit=5
+func GetNextBuildsHandler(c echo.Context) error {
+	recommendations, err := prioritizable(c).GetBuildRecommendations()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid limit"))
+		}
+		if limit < len(recommendations) {
+			recommendations = recommendations[:limit]
+		}
+	}
+	return c.JSON(http.StatusOK, SuccessResp(recommendations))
+}
+
 // GetResourceSettingsHandler ...
 func GetResourceSettingsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	res, err := bot.GetResourceSettings(ogame.PlanetID(planetID))
+	res, err := prioritizable(c).GetResourceSettings(ogame.PlanetID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
 
+// parseResourceSettingsForm parses the production percentages of a resource settings form
+func parseResourceSettingsForm(c echo.Context) (ogame.ResourceSettings, error) {
+	var settings ogame.ResourceSettings
+	fields := []struct {
+		name string
+		dst  *int64
+	}{
+		{"metalMine", &settings.MetalMine},
+		{"crystalMine", &settings.CrystalMine},
+		{"deuteriumSynthesizer", &settings.DeuteriumSynthesizer},
+		{"solarPlant", &settings.SolarPlant},
+		{"fusionReactor", &settings.FusionReactor},
+		{"solarSatellite", &settings.SolarSatellite},
+		{"crawler", &settings.Crawler},
+	}
+	for _, f := range fields {
+		v, err := strconv.ParseInt(c.Request().PostFormValue(f.name), 10, 64)
+		if err != nil {
+			return settings, errors.New("invalid " + f.name)
+		}
+		*f.dst = v
+	}
+	return settings, nil
+}
+
 // SetResourceSettingsHandler ...
-// curl 127.0.0.1:1234/bot/planets/123/resource-settings -d 'metalMine=100&crystalMine=100&deuteriumSynthesizer=100&solarPlant=100&fusionReactor=100&solarSatellite=100'
+// curl 127.0.0.1:1234/bot/planets/123/resource-settings -d 'metalMine=100&crystalMine=100&deuteriumSynthesizer=100&solarPlant=100&fusionReactor=100&solarSatellite=100&crawler=100'
 func SetResourceSettingsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	metalMine, err := strconv.ParseInt(c.Request().PostFormValue("metalMine"), 10, 64)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid metalMine"))
-	}
-	crystalMine, err := strconv.ParseInt(c.Request().PostFormValue("crystalMine"), 10, 64)
+	settings, err := parseResourceSettingsForm(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid crystalMine"))
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	deuteriumSynthesizer, err := strconv.ParseInt(c.Request().PostFormValue("deuteriumSynthesizer"), 10, 64)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid deuteriumSynthesizer"))
+	if err := prioritizable(c).SetResourceSettings(ogame.PlanetID(planetID), settings); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
-	solarPlant, err := strconv.ParseInt(c.Request().PostFormValue("solarPlant"), 10, 64)
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// SetResourceSettingsAllHandler applies the production percentages to every planet
+// curl 127.0.0.1:1234/bot/planets/resource-settings -d 'metalMine=100&crystalMine=100&deuteriumSynthesizer=100&solarPlant=100&fusionReactor=0&solarSatellite=100&crawler=100'
+func SetResourceSettingsAllHandler(c echo.Context) error {
+	settings, err := parseResourceSettingsForm(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid solarPlant"))
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	fusionReactor, err := strconv.ParseInt(c.Request().PostFormValue("fusionReactor"), 10, 64)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid fusionReactor"))
+	if err := prioritizable(c).SetResourceSettingsAll(settings); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
-	solarSatellite, err := strconv.ParseInt(c.Request().PostFormValue("solarSatellite"), 10, 64)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid solarSatellite"))
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// OptimizeResourceSettingsHandler computes the production percentages of every planet maximizing the objective under
+// the energy constraint. objective is deuterium (default), traderate, or metal,crystal,deuterium weights.
+// The settings are applied if apply=1.
+// curl 127.0.0.1:1234/bot/resource-settings/optimize -d 'objective=1,1.5,3&apply=1'
+func OptimizeResourceSettingsHandler(c echo.Context) error {
+	objective := ogame.DeuteriumObjective
+	switch v := c.Request().PostFormValue("objective"); v {
+	case "", "deuterium":
+	case "traderate":
+		objective = ogame.TradeRateObjective
+	default:
+		weights := strings.Split(v, ",")
+		if len(weights) != 3 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid objective"))
+		}
+		dst := []*float64{&objective.Metal, &objective.Crystal, &objective.Deuterium}
+		for i, w := range weights {
+			f, err := strconv.ParseFloat(strings.TrimSpace(w), 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid objective"))
+			}
+			*dst[i] = f
+		}
 	}
-	crawler, err := strconv.ParseInt(c.Request().PostFormValue("crawler"), 10, 64)
+	apply := c.Request().PostFormValue("apply") == "1" || c.Request().PostFormValue("apply") == "true"
+	res, err := prioritizable(c).OptimizeResourceSettings(objective, apply)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid crawler"))
-	}
-	settings := ogame.ResourceSettings{
-		MetalMine:            metalMine,
-		CrystalMine:          crystalMine,
-		DeuteriumSynthesizer: deuteriumSynthesizer,
-		SolarPlant:           solarPlant,
-		FusionReactor:        fusionReactor,
-		SolarSatellite:       solarSatellite,
-		Crawler:              crawler,
-	}
-	if err := bot.SetResourceSettings(ogame.PlanetID(planetID), settings); err != nil {
-		if err == ogame.ErrInvalidPlanetID {
-			return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
-		}
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
-	return c.JSON(http.StatusOK, SuccessResp(nil))
+	return c.JSON(http.StatusOK, SuccessResp(res))
 }
 
 // GetResourcesBuildingsHandler ...
 func GetResourcesBuildingsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	res, err := bot.GetResourcesBuildings(ogame.CelestialID(planetID))
+	res, err := prioritizable(c).GetResourcesBuildings(ogame.CelestialID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
@@ -553,13 +1552,13 @@ func GetResourcesBuildingsHandler(c echo.Context) error {
 // GetDefenseHandler ...
 func GetDefenseHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	res, err := bot.GetDefense(ogame.CelestialID(planetID))
+	res, err := prioritizable(c).GetDefense(ogame.CelestialID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
@@ -567,13 +1566,13 @@ func GetDefenseHandler(c echo.Context) error {
 // GetShipsHandler ...
 func GetShipsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	res, err := bot.GetShips(ogame.CelestialID(planetID))
+	res, err := prioritizable(c).GetShips(ogame.CelestialID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
@@ -581,21 +1580,38 @@ func GetShipsHandler(c echo.Context) error {
 // GetFacilitiesHandler ...
 func GetFacilitiesHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	res, err := bot.GetFacilities(ogame.CelestialID(planetID))
+	res, err := prioritizable(c).GetFacilities(ogame.CelestialID(planetID))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
 
+// requirementsError returns the error of the requirements of id not met on the celestial, nil if they are met.
+// The build is attempted anyway if the levels cannot be read.
+func requirementsError(c echo.Context, celestialID ogame.CelestialID, id ogame.ID) *ogame.RequirementsError {
+	missing, err := prioritizable(c).GetMissingRequirements(celestialID, id)
+	if err != nil || len(missing) == 0 {
+		return nil
+	}
+	return &ogame.RequirementsError{ID: id, Missing: missing}
+}
+
+// requirementsErrorJSON sends the error response of the requirements not met, with the missing levels as result
+func requirementsErrorJSON(c echo.Context, err *ogame.RequirementsError) error {
+	resp := ErrorRespOf(err, http.StatusBadRequest)
+	resp.Result = err.Missing
+	return c.JSON(resp.Code, resp)
+}
+
 // BuildHandler ...
 func BuildHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -607,8 +1623,11 @@ func BuildHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
 	}
-	if err := bot.Build(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).Build(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -616,7 +1635,7 @@ func BuildHandler(c echo.Context) error {
 // BuildCancelableHandler ...
 func BuildCancelableHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -624,8 +1643,11 @@ func BuildCancelableHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogame id"))
 	}
-	if err := bot.BuildCancelable(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).BuildCancelable(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -633,7 +1655,7 @@ func BuildCancelableHandler(c echo.Context) error {
 // BuildProductionHandler ...
 func BuildProductionHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -645,8 +1667,11 @@ func BuildProductionHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
 	}
-	if err := bot.BuildProduction(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).BuildProduction(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -654,7 +1679,7 @@ func BuildProductionHandler(c echo.Context) error {
 // BuildBuildingHandler ...
 func BuildBuildingHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -662,8 +1687,11 @@ func BuildBuildingHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogame id"))
 	}
-	if err := bot.BuildBuilding(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).BuildBuilding(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -671,7 +1699,7 @@ func BuildBuildingHandler(c echo.Context) error {
 // BuildTechnologyHandler ...
 func BuildTechnologyHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -679,8 +1707,11 @@ func BuildTechnologyHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogame id"))
 	}
-	if err := bot.BuildTechnology(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).BuildTechnology(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -688,7 +1719,7 @@ func BuildTechnologyHandler(c echo.Context) error {
 // BuildDefenseHandler ...
 func BuildDefenseHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -700,8 +1731,11 @@ func BuildDefenseHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
 	}
-	if err := bot.BuildDefense(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).BuildDefense(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -709,7 +1743,7 @@ func BuildDefenseHandler(c echo.Context) error {
 // BuildShipsHandler ...
 func BuildShipsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -721,8 +1755,11 @@ func BuildShipsHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
 	}
-	if err := bot.BuildShips(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	if err := prioritizable(c).BuildShips(ogame.CelestialID(planetID), ogame.ID(ogameID), nbr); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -730,13 +1767,63 @@ func BuildShipsHandler(c echo.Context) error {
 // GetProductionHandler ...
 func GetProductionHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
+	}
+	res, _, err := prioritizable(c).GetProduction(ogame.CelestialID(planetID))
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(res))
+}
+
+// GetProductionQueueHandler returns the shipyard queue with the estimated start and end of every order
+// curl 127.0.0.1:1234/bot/planets/123/production/queue
+func GetProductionQueueHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
+	}
+	res, err := prioritizable(c).GetProductionQueue(ogame.CelestialID(planetID))
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(res))
+}
+
+// QueueShipsHandler orders ships or defenses, split across as many queue submissions as needed. The request waits for
+// queue slots to free up when the shipyard queue is full, closing it stops queuing. Returns the number of units queued,
+// and the error that stopped the queuing if some units were queued.
+// curl 127.0.0.1:1234/bot/planets/123/queue-ships/204/500000 -X POST
+func QueueShipsHandler(c echo.Context) error {
+	if InTx(c) {
+		return TxNotSupported(c)
+	}
+	bot := c.Get("bot").(*ogame.OGame)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	res, _, err := bot.GetProduction(ogame.CelestialID(planetID))
+	ogameID, err := strconv.ParseInt(c.Param("ogameID"), 10, 64)
+	if err != nil || (!ogame.ID(ogameID).IsShip() && !ogame.ID(ogameID).IsDefense()) {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogame id"))
+	}
+	nbr, err := strconv.ParseInt(c.Param("nbr"), 10, 64)
+	if err != nil || nbr < 1 {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
+	}
+	if err := requirementsError(c, ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return requirementsErrorJSON(c, err)
+	}
+	queued, err := bot.QueueShips(c.Request().Context(), ogame.CelestialID(planetID), ogame.ID(ogameID), nbr)
+	if err != nil && queued == 0 {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	res := map[string]interface{}{"Queued": queued, "Complete": queued == nbr}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		res["Error"] = err.Error()
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
@@ -744,11 +1831,11 @@ func GetProductionHandler(c echo.Context) error {
 // ConstructionsBeingBuiltHandler ...
 func ConstructionsBeingBuiltHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	buildingID, buildingCountdown, researchID, researchCountdown := bot.ConstructionsBeingBuilt(ogame.CelestialID(planetID))
+	buildingID, buildingCountdown, researchID, researchCountdown := prioritizable(c).ConstructionsBeingBuilt(ogame.CelestialID(planetID))
 	return c.JSON(http.StatusOK, SuccessResp(
 		struct {
 			BuildingID        int64
@@ -767,12 +1854,12 @@ func ConstructionsBeingBuiltHandler(c echo.Context) error {
 // CancelBuildingHandler ...
 func CancelBuildingHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	if err := bot.CancelBuilding(ogame.CelestialID(planetID)); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := prioritizable(c).CancelBuilding(ogame.CelestialID(planetID)); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -780,53 +1867,252 @@ func CancelBuildingHandler(c echo.Context) error {
 // CancelResearchHandler ...
 func CancelResearchHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
+	}
+	if err := prioritizable(c).CancelResearch(ogame.CelestialID(planetID)); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// GetResourcesHandler returns the resources of a celestial, with an ETag that changes when they change
+// curl 127.0.0.1:1234/bot/planets/123/resources
+func GetResourcesHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
-	if err := bot.CancelResearch(ogame.CelestialID(planetID)); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	res, err := prioritizable(c).GetResources(ogame.CelestialID(planetID))
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	etag := versionETag(bot, "resources", strconv.FormatInt(planetID, 10), res, time.Time{})
+	return etagResp(c, etag, SuccessResp(res))
+}
+
+// priceCalculatorQueryParams query parameters that make GetPriceHandler return the whole price calculation
+var priceCalculatorQueryParams = []string{"from", "celestialID", "roboticsFactory", "naniteFactory", "shipyard", "researchLab", "energyTechnology"}
+
+// GetPriceHandler returns the price of level nbr of a building/research, or of nbr ships/defenses.
+// With details=true or any calculator parameter, it returns the energy delta and the construction time too, and the
+// cumulative cost and time from level "from" (nbr-1 by default, 0 to count every level) to nbr. With celestialID, the
+// facilities and temperature are read from the celestial and the levels missing to build it are returned too.
+// curl 127.0.0.1:1234/bot/price/1/20
+// curl '127.0.0.1:1234/bot/price/1/20?from=0&roboticsFactory=10&naniteFactory=2'
+// curl '127.0.0.1:1234/bot/price/212/100?celestialID=123'
+func GetPriceHandler(c echo.Context) error {
+	ogameID, err := strconv.ParseInt(c.Param("ogameID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogameID"))
+	}
+	nbr, err := strconv.ParseInt(c.Param("nbr"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
+	}
+	ogameObj := ogame.Objs.ByID(ogame.ID(ogameID))
+	if ogameObj == nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogameID"))
+	}
+	details := c.QueryParam("details") == "true"
+	for _, name := range priceCalculatorQueryParams {
+		details = details || c.QueryParam(name) != ""
+	}
+	if details {
+		return priceCalculation(c, ogame.ID(ogameID), nbr)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(ogameObj.GetPrice(nbr)))
+}
+
+// parsePriceCalculatorParams parses the price calculator query parameters. The facilities and temperature are read
+// from celestialID if given, then overridden by the roboticsFactory, naniteFactory, shipyard and researchLab parameters.
+// The energy technology defaults to the cached researches.
+func parsePriceCalculatorParams(c echo.Context, bot *ogame.OGame) (ogame.PriceCalculatorParams, error) {
+	params := ogame.PriceCalculatorParams{
+		Researches:         prioritizable(c).GetCachedResearch(),
+		UniverseSpeed:      bot.GetUniverseSpeed(),
+		HasTechnocrat:      bot.GetCachedHasTechnocrat(),
+		HasEngineer:        bot.GetCachedHasEngineer(),
+		HasCommandingStaff: bot.GetCachedOfficers().IsCommandingStaff(),
+		IsDiscoverer:       bot.CharacterClass() == ogame.Discoverer,
+		IsCollector:        bot.CharacterClass() == ogame.Collector,
+	}
+	if celestialIDStr := c.QueryParam("celestialID"); celestialIDStr != "" {
+		celestialID, err := parseCelestialIDParam(bot, celestialIDStr)
+		if err != nil {
+			return params, errors.New("invalid celestialID")
+		}
+		if params.Facilities, err = prioritizable(c).GetFacilities(ogame.CelestialID(celestialID)); err != nil {
+			return params, err
+		}
+		if planet, ok := bot.GetCachedCelestial(ogame.CelestialID(celestialID)).(ogame.Planet); ok {
+			params.Temperature = planet.Temperature
+		}
+	}
+	fields := []struct {
+		name string
+		dst  *int64
+	}{
+		{"roboticsFactory", &params.Facilities.RoboticsFactory},
+		{"naniteFactory", &params.Facilities.NaniteFactory},
+		{"shipyard", &params.Facilities.Shipyard},
+		{"researchLab", &params.Facilities.ResearchLab},
+		{"energyTechnology", &params.Researches.EnergyTechnology},
+	}
+	for _, f := range fields {
+		if v := c.QueryParam(f.name); v != "" {
+			lvl, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || lvl < 0 {
+				return params, errors.New("invalid " + f.name)
+			}
+			*f.dst = lvl
+		}
+	}
+	if v := c.QueryParam("from"); v != "" {
+		from, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || from < 0 {
+			return params, errors.New("invalid from")
+		}
+		params.From = &from
 	}
-	return c.JSON(http.StatusOK, SuccessResp(nil))
+	return params, nil
 }
 
-// GetResourcesHandler ...
-func GetResourcesHandler(c echo.Context) error {
+// priceCalculation answers the price calculation of GetPriceHandler
+func priceCalculation(c echo.Context, id ogame.ID, nbr int64) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	params, err := parsePriceCalculatorParams(c, bot)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
-	res, err := bot.GetResources(ogame.CelestialID(planetID))
+	res, err := ogame.CalculatePrice(id, nbr, params)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	if celestialIDStr := c.QueryParam("celestialID"); celestialIDStr != "" {
+		celestialID, _ := parseCelestialIDParam(bot, celestialIDStr)
+		if res.MissingRequirements, err = prioritizable(c).GetMissingRequirements(ogame.CelestialID(celestialID), id); err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
+		}
 	}
 	return c.JSON(http.StatusOK, SuccessResp(res))
 }
 
-// GetPriceHandler ...
-func GetPriceHandler(c echo.Context) error {
-	ogameID, err := strconv.ParseInt(c.Param("ogameID"), 10, 64)
+// FlightTimeHandler returns the flight time (seconds), fuel and arrival time of ships flying between two coordinates,
+// using the server speeds, the character class and the cached researches. speed defaults to 10 (100%), mission to 3 (transport).
+// speed is in 10% units, a General can use 5% steps (e.g. 5.5) and 0.5% steps below 10% (e.g. 0.35).
+// curl '127.0.0.1:1234/bot/flight-time?from=1:2:3&to=M:1:5:8&speed=10&mission=4' -X GET -d '{"SmallCargo":10,"LargeCargo":2}'
+func FlightTimeHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	from, err := ogame.ParseCoord(c.QueryParam("from"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogameID"))
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid from"))
 	}
-	nbr, err := strconv.ParseInt(c.Param("nbr"), 10, 64)
+	to, err := ogame.ParseCoord(c.QueryParam("to"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr"))
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid to"))
 	}
-	ogameObj := ogame.Objs.ByID(ogame.ID(ogameID))
-	if ogameObj != nil {
-		price := ogameObj.GetPrice(nbr)
-		return c.JSON(http.StatusOK, SuccessResp(price))
+	speed := ogame.HundredPercent
+	if speedStr := c.QueryParam("speed"); speedStr != "" {
+		speedFloat, err := strconv.ParseFloat(speedStr, 64)
+		if err != nil || !ogame.Speed(speedFloat).IsValid(bot.CharacterClass()) {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid speed"))
+		}
+		speed = ogame.Speed(speedFloat)
+	}
+	mission := ogame.Transport
+	if missionStr := c.QueryParam("mission"); missionStr != "" {
+		missionInt, err := strconv.ParseInt(missionStr, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid mission"))
+		}
+		mission = ogame.MissionID(missionInt)
+	}
+	var ships ogame.ShipsInfos
+	if err := json.NewDecoder(c.Request().Body).Decode(&ships); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ships"))
+	}
+	if !ships.HasFlyableShips() {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, ogame.ErrNoShipSelected.Error()))
+	}
+	secs, fuel := prioritizable(c).FlightTime(from, to, speed, ships, mission)
+	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
+		"Secs":        secs,
+		"Fuel":        fuel,
+		"ArrivalTime": time.Now().Add(time.Duration(secs) * time.Second).Unix(),
+	}))
+}
+
+// CargoCapacityHandler returns the cargo capacity of ships, with the bot researches, class and server settings.
+// hyperspaceTechnology, class (0-3), probeRaids and hyperspaceMultiplier (percent per level) override the bot values.
+// curl '127.0.0.1:1234/bot/cargo-capacity?class=1' -X GET -d '{"SmallCargo":10,"LargeCargo":2}'
+func CargoCapacityHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	var ships ogame.ShipsInfos
+	if err := json.NewDecoder(c.Request().Body).Decode(&ships); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ships"))
+	}
+	researches := prioritizable(c).GetCachedResearch()
+	if hyperspaceStr := c.QueryParam("hyperspaceTechnology"); hyperspaceStr != "" {
+		level, err := strconv.ParseInt(hyperspaceStr, 10, 64)
+		if err != nil || level < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid hyperspaceTechnology"))
+		}
+		researches.HyperspaceTechnology = level
+	}
+	class := bot.CharacterClass()
+	if classStr := c.QueryParam("class"); classStr != "" {
+		classInt, err := strconv.ParseInt(classStr, 10, 64)
+		if err != nil || classInt < 0 || classInt > 3 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid class"))
+		}
+		class = ogame.CharacterClass(classInt)
+	}
+	probeRaids := bot.GetServer().Settings.EspionageProbeRaids == 1
+	if probeRaidsStr := c.QueryParam("probeRaids"); probeRaidsStr != "" {
+		var err error
+		if probeRaids, err = strconv.ParseBool(probeRaidsStr); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid probeRaids"))
+		}
+	}
+	hyperspaceMultiplier := bot.GetCargoHyperspaceTechMultiplier()
+	if multiplierStr := c.QueryParam("hyperspaceMultiplier"); multiplierStr != "" {
+		multiplier, err := strconv.ParseInt(multiplierStr, 10, 64)
+		if err != nil || multiplier < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid hyperspaceMultiplier"))
+		}
+		hyperspaceMultiplier = multiplier
+	}
+	return c.JSON(http.StatusOK, SuccessResp(ogame.CargoCapacity(ships, researches, class, probeRaids, hyperspaceMultiplier)))
+}
+
+// parseSendFleetAmount parses a ships quantity or resources amount, "all" or -1 meaning everything available
+func parseSendFleetAmount(value string) (int64, error) {
+	if value == "all" {
+		return ogame.AllAvailable, nil
 	}
-	return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogameID"))
+	nbr, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || (nbr < 0 && nbr != ogame.AllAvailable) {
+		return 0, errors.New("invalid amount " + value)
+	}
+	return nbr, nil
 }
 
 // SendFleetHandler ...
 // curl 127.0.0.1:1234/bot/planets/123/send-fleet -d 'ships=203,1&ships=204,10&speed=10&galaxy=1&system=1&type=1&position=1&mission=3&metal=1&crystal=2&deuterium=3'
+// Ships quantities and resources amounts can be "all" (or -1) to send everything available, e.g. ships=203,all&metal=all
+// Hostile missions against alliance members and buddies are refused unless allowFriendlyFire=true is sent.
+// With validateTarget=true the destination is checked against the galaxy cache before sending the fleet,
+// validateTarget=fresh fetches the system from the game.
+// With ?dryRun=1 the fleet is only validated (ships, slots, fuel, target, cargo), the flight time, fuel and cargo are returned.
+// An expedition (mission=15) sent with loop=true is re-sent every time it returns, see GetExpeditionLoopsHandler.
+// topUp=true queues the lost ships in the shipyard, cooldown is the wait in seconds before re-sending the fleet and
+// maxLosses the expeditions in a row that lost ships after which the loop stops.
 func SendFleetHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -840,8 +2126,12 @@ func SendFleetHandler(c echo.Context) error {
 	mission := ogame.Transport
 	var duration int64
 	var unionID int64
+	allowFriendlyFire := false
+	var validateTarget []ogame.Option // nil when the target is not validated
 	payload := ogame.Resources{}
 	speed := ogame.HundredPercent
+	var loop, topUp bool
+	var cooldown, maxLosses int64
 	for key, values := range c.Request().PostForm {
 		switch key {
 		case "ships":
@@ -851,18 +2141,18 @@ func SendFleetHandler(c echo.Context) error {
 				if err != nil || !ogame.IsShipID(shipID) {
 					return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ship id "+a[0]))
 				}
-				nbr, err := strconv.ParseInt(a[1], 10, 64)
-				if err != nil || nbr < 0 {
+				nbr, err := parseSendFleetAmount(a[1])
+				if err != nil {
 					return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid nbr "+a[1]))
 				}
 				ships = append(ships, ogame.Quantifiable{ID: ogame.ID(shipID), Nbr: nbr})
 			}
 		case "speed":
-			speedInt, err := strconv.ParseInt(values[0], 10, 64)
-			if err != nil || speedInt < 0 || speedInt > 10 {
+			speedFloat, err := strconv.ParseFloat(values[0], 64)
+			if err != nil || !ogame.Speed(speedFloat).IsValid(bot.CharacterClass()) {
 				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid speed"))
 			}
-			speed = ogame.Speed(speedInt)
+			speed = ogame.Speed(speedFloat)
 		case "galaxy":
 			galaxy, err := strconv.ParseInt(values[0], 10, 64)
 			if err != nil {
@@ -903,56 +2193,112 @@ func SendFleetHandler(c echo.Context) error {
 			if err != nil {
 				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid union id"))
 			}
+		case "allowFriendlyFire":
+			allowFriendlyFire, err = strconv.ParseBool(values[0])
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid allowFriendlyFire"))
+			}
+		case "validateTarget":
+			switch values[0] {
+			case "fresh":
+				validateTarget = []ogame.Option{ogame.SkipCache}
+			default:
+				validate, err := strconv.ParseBool(values[0])
+				if err != nil {
+					return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid validateTarget"))
+				}
+				if validate {
+					validateTarget = []ogame.Option{}
+				}
+			}
 		case "metal":
-			metal, err := strconv.ParseInt(values[0], 10, 64)
-			if err != nil || metal < 0 {
+			metal, err := parseSendFleetAmount(values[0])
+			if err != nil {
 				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid metal"))
 			}
 			payload.Metal = metal
 		case "crystal":
-			crystal, err := strconv.ParseInt(values[0], 10, 64)
-			if err != nil || crystal < 0 {
+			crystal, err := parseSendFleetAmount(values[0])
+			if err != nil {
 				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid crystal"))
 			}
 			payload.Crystal = crystal
 		case "deuterium":
-			deuterium, err := strconv.ParseInt(values[0], 10, 64)
-			if err != nil || deuterium < 0 {
+			deuterium, err := parseSendFleetAmount(values[0])
+			if err != nil {
 				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid deuterium"))
 			}
 			payload.Deuterium = deuterium
+		case "loop":
+			if loop, err = strconv.ParseBool(values[0]); err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid loop"))
+			}
+		case "topUp":
+			if topUp, err = strconv.ParseBool(values[0]); err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid topUp"))
+			}
+		case "cooldown":
+			if cooldown, err = strconv.ParseInt(values[0], 10, 64); err != nil || cooldown < 0 {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid cooldown"))
+			}
+		case "maxLosses":
+			if maxLosses, err = strconv.ParseInt(values[0], 10, 64); err != nil || maxLosses < 0 {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid maxLosses"))
+			}
+		}
+	}
+	if loop && mission != ogame.Expedition {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "loop is only supported by expeditions"))
+	}
+
+	if dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun")); dryRun {
+		validation, err := prioritizable(c).ValidateFleet(ogame.CelestialID(planetID), ships, speed, where, mission, payload)
+		if err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
 		}
+		return c.JSON(http.StatusOK, SuccessResp(validation))
 	}
 
-	fleet, err := bot.SendFleet(ogame.CelestialID(planetID), ships, speed, where, mission, payload, duration, unionID)
-	if err != nil &&
-		(err == ogame.ErrInvalidPlanetID ||
-			err == ogame.ErrNoShipSelected ||
-			err == ogame.ErrUninhabitedPlanet ||
-			err == ogame.ErrNoDebrisField ||
-			err == ogame.ErrPlayerInVacationMode ||
-			err == ogame.ErrAdminOrGM ||
-			err == ogame.ErrNoAstrophysics ||
-			err == ogame.ErrNoobProtection ||
-			err == ogame.ErrPlayerTooStrong ||
-			err == ogame.ErrNoMoonAvailable ||
-			err == ogame.ErrNoRecyclerAvailable ||
-			err == ogame.ErrNoEventsRunning ||
-			err == ogame.ErrPlanetAlreadyReservedForRelocation) {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	if loop {
+		if InTx(c) {
+			return TxNotSupported(c)
+		}
+		fleet, err := bot.SendExpedition(ogame.ExpeditionParams{
+			Origin:      ogame.CelestialID(planetID),
+			Destination: where,
+			Ships:       ogame.ShipsInfos{}.FromQuantifiables(ships),
+			Speed:       speed,
+			HoldingTime: duration,
+			Loop:        true,
+			TopUp:       topUp,
+			Cooldown:    time.Duration(cooldown) * time.Second,
+			MaxLosses:   maxLosses,
+		})
+		if err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
+		}
+		return c.JSON(http.StatusOK, SuccessResp(fleet))
+	}
+
+	tx := prioritizable(c)
+	if allowFriendlyFire {
+		tx = tx.AllowFriendlyFire()
+	}
+	if validateTarget != nil {
+		tx = tx.ValidateTarget(validateTarget...)
 	}
+	fleet, err := tx.SendFleet(ogame.CelestialID(planetID), ships, speed, where, mission, payload, duration, unionID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(fleet))
 }
 
 // GetAlliancePageContentHandler ...
 func GetAlliancePageContentHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	allianceID := c.QueryParam("allianceId")
 	vals := url.Values{"allianceId": {allianceID}}
-	pageHTML, _ := bot.GetAlliancePageContent(vals)
+	pageHTML, _ := prioritizable(c).GetAlliancePageContent(vals)
 	return c.HTML(http.StatusOK, string(pageHTML))
 }
 
@@ -961,23 +2307,38 @@ func GetStaticHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
 
 	newURL := bot.ServerURL() + c.Request().URL.String()
-	req, err := http.NewRequest("GET", newURL, nil)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	cache, _ := c.Get("staticCache").(*StaticCache)
+	if cache == nil || !isCacheableStatic(c.Request().URL.Path) {
+		cache = nil
 	}
-	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
-	resp, err := bot.Client.Do(req)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	body, header, cached := []byte(nil), http.Header(nil), false
+	if cache != nil {
+		body, header, cached = cache.Get(newURL)
 	}
-	defer resp.Body.Close()
-	body, _, err := ogame.ReadBody(resp)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if !cached {
+		req, err := http.NewRequest("GET", newURL, nil)
+		if err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
+		}
+		req.Header.Add("Accept-Encoding", "gzip, deflate, br")
+		resp, err := bot.Client.Do(req)
+		if err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
+		}
+		defer resp.Body.Close()
+		body, _, err = ogame.ReadBody(resp)
+		if err != nil {
+			return errorJSON(c, err, http.StatusInternalServerError)
+		}
+		header = resp.Header
+		if cache != nil && resp.StatusCode == http.StatusOK {
+			// The raw body is stored, the hostname replacement depends on the bot and is applied on every hit
+			_ = cache.Put(newURL, body, header)
+		}
 	}
 
 	// Copy the original HTTP headers to our client
-	for k, vv := range resp.Header { // duplicate headers are acceptable in HTTP spec, so add all of them individually: https://stackoverflow.com/questions/4371328/are-duplicate-http-response-headers-acceptable
+	for k, vv := range header { // duplicate headers are acceptable in HTTP spec, so add all of them individually: https://stackoverflow.com/questions/4371328/are-duplicate-http-response-headers-acceptable
 		k = http.CanonicalHeaderKey(k)
 		if k != "Content-Length" && k != "Content-Encoding" { // https://github.com/alaingilbert/ogame/pull/80#issuecomment-674559853
 			for _, v := range vv {
@@ -1010,34 +2371,72 @@ func GetFromGameHandler(c echo.Context) error {
 	if len(c.QueryParams()) > 0 {
 		vals = c.QueryParams()
 	}
-	pageHTML, _ := bot.GetPageContent(vals)
+	pageHTML, _ := prioritizable(c).GetPageContent(vals)
 	pageHTML = ogame.ReplaceHostname(bot, pageHTML)
 	return c.HTMLBlob(http.StatusOK, pageHTML)
 }
 
 // PostToGameHandler ...
+// Url encoded forms go through PostPageContent, any other body (json, multipart...) is forwarded untouched.
 func PostToGameHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
 	vals := url.Values{"page": {"ingame"}, "component": {"overview"}}
 	if len(c.QueryParams()) > 0 {
 		vals = c.QueryParams()
 	}
-	payload, _ := c.FormParams()
-	pageHTML, _ := bot.PostPageContent(vals, payload)
-	pageHTML = ogame.ReplaceHostname(bot, pageHTML)
-	return c.HTMLBlob(http.StatusOK, pageHTML)
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if contentType == "" || strings.HasPrefix(contentType, echo.MIMEApplicationForm) {
+		payload, _ := c.FormParams()
+		pageHTML, _ := prioritizable(c).PostPageContent(vals, payload)
+		pageHTML = ogame.ReplaceHostname(bot, pageHTML)
+		return c.HTMLBlob(http.StatusOK, pageHTML)
+	}
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	pageHTML, headers, err := prioritizable(c).PostRawPageContent(vals, contentType, body)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	copyPassthroughHeaders(c.Response().Header(), headers)
+	respContentType := headers.Get(echo.HeaderContentType)
+	if respContentType == "" {
+		respContentType = echo.MIMETextHTMLCharsetUTF8
+	}
+	return c.Blob(http.StatusOK, respContentType, ogame.ReplaceHostname(bot, pageHTML))
+}
+
+// Headers that must not be forwarded from the game response, the body is decoded and rewritten by the passthrough
+var skippedPassthroughHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Set-Cookie":        true,
+}
+
+func copyPassthroughHeaders(dst, src http.Header) {
+	for k, vv := range src {
+		k = http.CanonicalHeaderKey(k)
+		if skippedPassthroughHeaders[k] {
+			continue
+		}
+		for _, v := range vv {
+			dst.Add(k, v)
+		}
+	}
 }
 
 // GetStaticHEADHandler ...
 func GetStaticHEADHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	newURL := "/api/" + strings.Join(c.ParamValues(), "") // + "?" + c.QueryString()
 	if len(c.QueryString()) > 0 {
 		newURL = newURL + "?" + c.QueryString()
 	}
-	headers, err := bot.HeadersForPage(newURL)
+	headers, err := prioritizable(c).HeadersForPage(newURL)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	if len(headers) < 1 {
 		return c.NoContent(http.StatusFailedDependency)
@@ -1053,36 +2452,172 @@ func GetStaticHEADHandler(c echo.Context) error {
 }
 
 // GetEmpireHandler ...
+// curl 127.0.0.1:1234/bot/empire/type/0?max_age=60
 func GetEmpireHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	nbr, err := strconv.ParseInt(c.Param("typeID"), 10, 64)
 	if err != nil || nbr > 1 {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid typeID"))
 	}
-	getEmpire, err := bot.GetEmpireJSON(nbr)
+	maxAge, err := parseMaxAge(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	getEmpire, freshness, err := prioritizable(c).GetEmpireJSONMaxAge(nbr, maxAge)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, FreshResp(getEmpire, freshness))
+}
+
+// SyncEmpireHandler returns the resources, buildings, ships and defenses of every planet and moon, fetched from the
+// empire pages with a commander (at most 2 requests), celestial by celestial otherwise
+// curl 127.0.0.1:1234/bot/empire/sync
+func SyncEmpireHandler(c echo.Context) error {
+	snapshot, err := prioritizable(c).SyncEmpire()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(snapshot))
+}
+
+var empireSnapshots = struct {
+	sync.Mutex
+	m map[string]ogame.EmpireSnapshot
+}{m: make(map[string]ogame.EmpireSnapshot)}
+
+// TakeEmpireSnapshotHandler stores the current empire state under a name
+// curl 127.0.0.1:1234/bot/empire/snapshots/nightly -X POST
+func TakeEmpireSnapshotHandler(c echo.Context) error {
+	snapshot, err := prioritizable(c).GetEmpireSnapshot()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	empireSnapshots.Lock()
+	empireSnapshots.m[c.Param("name")] = snapshot
+	empireSnapshots.Unlock()
+	return c.JSON(http.StatusOK, SuccessResp(snapshot.Time))
+}
+
+// GetEmpireSnapshotDiffHandler compares the current empire state with a stored snapshot
+// curl 127.0.0.1:1234/bot/empire/snapshots/nightly/diff
+func GetEmpireSnapshotDiffHandler(c echo.Context) error {
+	empireSnapshots.Lock()
+	stored, ok := empireSnapshots.m[c.Param("name")]
+	empireSnapshots.Unlock()
+	if !ok {
+		return c.JSON(http.StatusNotFound, ErrorResp(404, "snapshot not found"))
+	}
+	now, err := prioritizable(c).GetEmpireSnapshot()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(ogame.Diff(stored, now)))
+}
+
+// ExportEmpireHandler exports a complete snapshot of the empire: celestials, resources, buildings, ships, defenses,
+// researches and fleets. format is json (default) or csv, the csv table is celestials (default) or fleets.
+// curl 127.0.0.1:1234/bot/export/empire > empire.json
+// curl '127.0.0.1:1234/bot/export/empire?format=csv&table=fleets' > fleets.csv
+func ExportEmpireHandler(c echo.Context) error {
+	format := c.QueryParam("format")
+	table := c.QueryParam("table")
+	if format != "" && format != "json" && format != "csv" {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid format"))
+	}
+	if table != "" && table != "celestials" && table != "fleets" {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid table"))
+	}
+	export, err := prioritizable(c).ExportEmpire()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	if format != "csv" {
+		return c.JSON(http.StatusOK, SuccessResp(export))
+	}
+	var buf bytes.Buffer
+	if table == "fleets" {
+		err = export.WriteFleetsCSV(&buf)
+	} else {
+		err = export.WriteCelestialsCSV(&buf)
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
-	return c.JSON(http.StatusOK, SuccessResp(getEmpire))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ImportEmpireHandler restores the cached planets, researches, player and empire snapshot of an export, in the Result
+// of the export response or as is. Meant for a bot started without login to analyze the empire offline.
+// curl 127.0.0.1:1234/bot/import/empire -H 'Content-Type: application/json' -d @empire.json
+func ImportEmpireHandler(c echo.Context) error {
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid body"))
+	}
+	var resp struct {
+		Result *ogame.EmpireExport
+	}
+	var export ogame.EmpireExport
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Result != nil {
+		export = *resp.Result
+	} else if err := json.Unmarshal(body, &export); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid empire export"))
+	}
+	prioritizable(c).ImportEmpire(export)
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// RemapEmpireSnapshots rewrites the celestial ids of the stored snapshots after a universe migration
+func RemapEmpireSnapshots(migration ogame.UniverseMigration) {
+	empireSnapshots.Lock()
+	defer empireSnapshots.Unlock()
+	for _, snapshot := range empireSnapshots.m {
+		for i := range snapshot.Celestials {
+			snapshot.Celestials[i].ID = migration.RemapID(snapshot.Celestials[i].ID)
+		}
+	}
+}
+
+// MigrateUniverseHandler re-resolves the universe after a server merge and remaps the celestial ids
+// curl 127.0.0.1:1234/bot/migrate-universe -X POST
+func MigrateUniverseHandler(c echo.Context) error {
+	migration, err := prioritizable(c).MigrateUniverse()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(migration))
+}
+
+// SwitchUniverseHandler logs the bot in the account of the lobby login playing on another universe
+// curl 127.0.0.1:1234/bot/switch-universe -d 'universe=Hyperion&lang=en'
+func SwitchUniverseHandler(c echo.Context) error {
+	universe := c.Request().PostFormValue("universe")
+	lang := c.Request().PostFormValue("lang")
+	if universe == "" || lang == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid universe or lang"))
+	}
+	if err := prioritizable(c).SwitchUniverse(universe, lang); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	bot := c.Get("bot").(*ogame.OGame)
+	return c.JSON(http.StatusOK, SuccessResp(bot.GetServer()))
 }
 
 // DeleteMessageHandler ...
 func DeleteMessageHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	messageID, err := strconv.ParseInt(c.Param("messageID"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid message id"))
 	}
-	if err := bot.DeleteMessage(messageID); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	if err := prioritizable(c).DeleteMessage(messageID); err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
 
 // DeleteEspionageMessagesHandler ...
 func DeleteEspionageMessagesHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	if err := bot.DeleteAllMessagesFromTab(20); err != nil { // 20 = Espionage Reports
+	if err := prioritizable(c).DeleteAllMessagesFromTab(20); err != nil { // 20 = Espionage Reports
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "Unable to delete Espionage Reports"))
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
@@ -1090,7 +2625,6 @@ func DeleteEspionageMessagesHandler(c echo.Context) error {
 
 // DeleteMessagesFromTabHandler ...
 func DeleteMessagesFromTabHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	tabIndex, err := strconv.ParseInt(c.Param("tabIndex"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "must provide tabIndex"))
@@ -1105,7 +2639,7 @@ func DeleteMessagesFromTabHandler(c echo.Context) error {
 		*/
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid tabIndex provided"))
 	}
-	if err := bot.DeleteAllMessagesFromTab(tabIndex); err != nil {
+	if err := prioritizable(c).DeleteAllMessagesFromTab(tabIndex); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "Unable to delete message from tab "+strconv.FormatInt(tabIndex, 10)))
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
@@ -1118,7 +2652,7 @@ func SendIPMHandler(c echo.Context) error {
 	if err != nil || ipmAmount < 1 {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ipmAmount"))
 	}
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil || planetID < 1 {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -1144,17 +2678,133 @@ func SendIPMHandler(c echo.Context) error {
 	}
 	priority, _ := strconv.ParseInt(c.Request().PostFormValue("priority"), 10, 64)
 	coord := ogame.Coordinate{Type: planetType, Galaxy: galaxy, System: system, Position: position}
-	duration, err := bot.SendIPM(ogame.PlanetID(planetID), coord, ipmAmount, ogame.ID(priority))
+	duration, err := prioritizable(c).SendIPM(ogame.PlanetID(planetID), coord, ipmAmount, ogame.ID(priority))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(duration))
 }
 
+func parseIPMPlanParams(maxPerWaveStr, prioritiesStr string) (int64, []ogame.ID, error) {
+	var maxPerWave int64
+	if maxPerWaveStr != "" {
+		var err error
+		if maxPerWave, err = strconv.ParseInt(maxPerWaveStr, 10, 64); err != nil || maxPerWave < 0 {
+			return 0, nil, errors.New("invalid maxPerWave")
+		}
+	}
+	priorities := make([]ogame.ID, 0)
+	if prioritiesStr != "" {
+		for _, p := range strings.Split(prioritiesStr, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+			if err != nil || !ogame.ID(id).IsDefense() {
+				return 0, nil, errors.New("invalid priorities")
+			}
+			priorities = append(priorities, ogame.ID(id))
+		}
+	}
+	return maxPerWave, priorities, nil
+}
+
+// GetIPMPlanHandler computes the interplanetary missiles needed to destroy the defenses of an espionage report target.
+// priorities are the defenses to destroy in order (most expensive first by default), maxPerWave the missiles per launch.
+// curl '127.0.0.1:1234/bot/espionage-report/123456/ipm-plan?maxPerWave=20&priorities=408,406'
+func GetIPMPlanHandler(c echo.Context) error {
+	msgID, err := strconv.ParseInt(c.Param("msgid"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid msgid id"))
+	}
+	maxPerWave, priorities, err := parseIPMPlanParams(c.QueryParam("maxPerWave"), c.QueryParam("priorities"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	plan, err := prioritizable(c).GetIPMPlan(msgID, maxPerWave, priorities)
+	if err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(plan))
+}
+
+// ExecuteIPMPlanHandler plans the interplanetary missiles attack of an espionage report target and launches its waves
+// from the planet, waiting delay seconds between two waves. Answers once every wave is launched.
+// curl 127.0.0.1:1234/bot/planets/123/ipm-attack/123456 -d 'maxPerWave=20&priorities=408,406&delay=5'
+func ExecuteIPMPlanHandler(c echo.Context) error {
+	if InTx(c) {
+		return TxNotSupported(c)
+	}
+	bot := c.Get("bot").(*ogame.OGame)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
+	if err != nil || planetID < 1 {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
+	}
+	msgID, err := strconv.ParseInt(c.Param("msgid"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid msgid id"))
+	}
+	maxPerWave, priorities, err := parseIPMPlanParams(c.FormValue("maxPerWave"), c.FormValue("priorities"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	}
+	var delay int64
+	if delayStr := c.FormValue("delay"); delayStr != "" {
+		if delay, err = strconv.ParseInt(delayStr, 10, 64); err != nil || delay < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid delay"))
+		}
+	}
+	plan, err := prioritizable(c).GetIPMPlan(msgID, maxPerWave, priorities)
+	if err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	sent, err := bot.ExecuteIPMPlan(c.Request().Context(), ogame.PlanetID(planetID), plan, time.Duration(delay)*time.Second)
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
+		"Plan": plan,
+		"Sent": sent,
+	}))
+}
+
+// GetMoonshotPlanHandler computes the ships of the origin to sacrifice against a planet for a moon chance (20% by
+// default), and the recyclers needed to harvest the debris field. ships are the ships to sacrifice in order.
+// curl '127.0.0.1:1234/bot/moonshot/plan?origin=123&to=1:2:3&chance=20&ships=204,202'
+func GetMoonshotPlanHandler(c echo.Context) error {
+	bot := c.Get("bot").(*ogame.OGame)
+	origin, err := parseCelestialIDParam(bot, c.QueryParam("origin"))
+	if err != nil || origin < 1 {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid origin"))
+	}
+	to, err := ogame.ParseCoord(c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid to"))
+	}
+	var chance int64
+	if chanceStr := c.QueryParam("chance"); chanceStr != "" {
+		if chance, err = strconv.ParseInt(chanceStr, 10, 64); err != nil || chance < 1 || chance > ogame.MaxMoonChance {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid chance"))
+		}
+	}
+	ships := make([]ogame.ID, 0)
+	if shipsStr := c.QueryParam("ships"); shipsStr != "" {
+		for _, s := range strings.Split(shipsStr, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil || !ogame.ID(id).IsShip() {
+				return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ships"))
+			}
+			ships = append(ships, ogame.ID(id))
+		}
+	}
+	plan, err := prioritizable(c).GetMoonshotPlan(ogame.CelestialID(origin), to, chance, ships)
+	if err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, SuccessResp(plan))
+}
+
 // TeardownHandler ...
 func TeardownHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	planetID, err := strconv.ParseInt(c.Param("planetID"), 10, 64)
+	planetID, err := parseCelestialIDParam(bot, c.Param("planetID"))
 	if err != nil || planetID < 0 {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid planet id"))
 	}
@@ -1162,16 +2812,15 @@ func TeardownHandler(c echo.Context) error {
 	if err != nil || planetID < 0 {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid ogame id"))
 	}
-	if err = bot.TearDown(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+	if err = prioritizable(c).TearDown(ogame.CelestialID(planetID), ogame.ID(ogameID)); err != nil {
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
 
 // GetAuctionHandler ...
 func GetAuctionHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	auction, err := bot.GetAuction()
+	auction, err := prioritizable(c).GetAuction()
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "could not open auction page"))
 	}
@@ -1180,7 +2829,6 @@ func GetAuctionHandler(c echo.Context) error {
 
 // DoAuctionHandler (`celestialID=metal:crystal:deuterium` eg: `123456=123:456:789`)
 func DoAuctionHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
 	bid := make(map[ogame.CelestialID]ogame.Resources)
 	if err := c.Request().ParseForm(); err != nil { // Required for PostForm, not for PostFormValue
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid form"))
@@ -1198,8 +2846,8 @@ func DoAuctionHandler(c echo.Context) error {
 			bid[ogame.CelestialID(celestialIDInt)] = ogame.Resources{Metal: metal, Crystal: crystal, Deuterium: deuterium}
 		}
 	}
-	if err := bot.DoAuction(bid); err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResp(500, err.Error()))
+	if err := prioritizable(c).DoAuction(bid); err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(nil))
 }
@@ -1207,7 +2855,7 @@ func DoAuctionHandler(c echo.Context) error {
 // PhalanxHandler ...
 func PhalanxHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	moonID, err := strconv.ParseInt(c.Param("moonID"), 10, 64)
+	moonID, err := parseCelestialIDParam(bot, c.Param("moonID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid moon id"))
 	}
@@ -1224,9 +2872,9 @@ func PhalanxHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid position"))
 	}
 	coord := ogame.Coordinate{Type: ogame.PlanetType, Galaxy: galaxy, System: system, Position: position}
-	fleets, err := bot.Phalanx(ogame.MoonID(moonID), coord)
+	fleets, err := prioritizable(c).Phalanx(ogame.MoonID(moonID), coord)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(fleets))
 }
@@ -1237,7 +2885,7 @@ func JumpGateHandler(c echo.Context) error {
 	if err := c.Request().ParseForm(); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid form"))
 	}
-	moonOriginID, err := strconv.ParseInt(c.Param("moonID"), 10, 64)
+	moonOriginID, err := parseCelestialIDParam(bot, c.Param("moonID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid origin moon id"))
 	}
@@ -1263,9 +2911,9 @@ func JumpGateHandler(c echo.Context) error {
 			}
 		}
 	}
-	success, rechargeCountdown, err := bot.JumpGate(ogame.MoonID(moonOriginID), ogame.MoonID(moonDestinationID), ships)
+	success, rechargeCountdown, err := prioritizable(c).JumpGate(ogame.MoonID(moonOriginID), ogame.MoonID(moonDestinationID), ships)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
 	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
 		"success":           success,
@@ -1273,161 +2921,92 @@ func JumpGateHandler(c echo.Context) error {
 	}))
 }
 
-// TechsHandler ...
+// TechsHandler returns the techs of a celestial, with an ETag that changes when they change
+// curl 127.0.0.1:1234/bot/celestials/123/techs
 func TechsHandler(c echo.Context) error {
 	bot := c.Get("bot").(*ogame.OGame)
-	celestialID, err := strconv.ParseInt(c.Param("celestialID"), 10, 64)
+	celestialID, err := parseCelestialIDParam(bot, c.Param("celestialID"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid celestial id"))
 	}
-	supplies, facilities, ships, defenses, researches, err := bot.GetTechs(ogame.CelestialID(celestialID))
+	supplies, facilities, ships, defenses, researches, err := prioritizable(c).GetTechs(ogame.CelestialID(celestialID))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
+		return errorJSON(c, err, http.StatusBadRequest)
 	}
-	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
+	techs := map[string]interface{}{
 		"supplies":   supplies,
 		"facilities": facilities,
 		"ships":      ships,
 		"defenses":   defenses,
 		"researches": researches,
-	}))
-}
-
-/*
-// GetCaptchaHandler ...
-func GetCaptchaHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-
-	gameEnvironmentID, platformGameID, err := getConfiguration(bot)
-	if err != nil {
-		return c.HTML(http.StatusOK, err.Error())
-	}
-
-	//var out postSessionsResponse
-	payload := url.Values{
-		"autoGameAccountCreation": {"false"},
-		"gameEnvironmentId":       {gameEnvironmentID},
-		"platformGameId":          {platformGameID},
-		"gfLang":                  {"en"},
-		"locale":                  {"en_GB"},
-		"identity":                {bot.Username},
-		"password":                {bot.password},
 	}
-	req, err := http.NewRequest("POST", "https://gameforge.com/api/v1/auth/thin/sessions", strings.NewReader(payload.Encode()))
-	if err != nil {
-		return c.HTML(http.StatusOK, err.Error())
-	}
-
-	if bot.otpSecret != "" {
-		passcode, err := totp.GenerateCodeCustom(bot.otpSecret, time.Now(), totp.ValidateOpts{
-			Period:    30,
-			Skew:      1,
-			Digits:    otp.DigitsSix,
-			Algorithm: otp.AlgorithmSHA1,
-		})
-		if err != nil {
-			return c.HTML(http.StatusOK, err.Error())
-		}
-		req.Header.Add("tnt-2fa-code", passcode)
-		req.Header.Add("tnt-installation-id", "")
-	}
-
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
+	etag := versionETag(bot, "techs", strconv.FormatInt(celestialID, 10), techs, time.Time{})
+	return etagResp(c, etag, SuccessResp(techs))
+}
 
-	resp, err := bot.doReqWithLoginProxyTransport(req)
+// AllTechsHandler returns the supplies/facilities/ships/defenses of every celestial and the researches
+// curl 127.0.0.1:1234/bot/celestials/techs
+func AllTechsHandler(c echo.Context) error {
+	techs, err := prioritizable(c).GetAllTechs()
 	if err != nil {
-		return c.HTML(http.StatusOK, err.Error())
-	}
-	if resp.StatusCode == 403 {
-		defer resp.Body.Close()
-		data403, _, _ := readBody(resp)
-		return c.HTML(http.StatusOK, string(data403))
+		return errorJSON(c, err, http.StatusInternalServerError)
 	}
+	return c.JSON(http.StatusOK, SuccessResp(techs))
+}
 
-	if resp.StatusCode == 409 {
-		var temp struct {
-			ID          string `json:"id"`
-			LastUpdated int    `json:"lastUpdated"`
-			Status      string `json:"status"`
-		}
-
-		challengeID := resp.Header.Get(gfChallengeID)
-		challengeID = strings.Replace(challengeID, ";https://challenge.gameforge.com", "", -1)
-
-		req, err = http.NewRequest("GET", "https://image-drop-challenge.gameforge.com/challenge/"+challengeID+"/en-GB", strings.NewReader(payload.Encode()))
-		if err != nil {
-			return c.HTML(http.StatusOK, err.Error())
-		}
-		resp, err = bot.doReqWithLoginProxyTransport(req)
-		if err != nil {
-			return c.HTML(http.StatusOK, err.Error())
-		}
-		defer resp.Body.Close()
-
-		data, _, _ := readBody(resp)
-		if err := json.Unmarshal(data, &temp); err != nil {
-			return c.HTML(http.StatusOK, err.Error())
-		}
-
-		html := `<img style="background-color: black;" src="/bot/captcha/question/` + challengeID + `" /><br />
-<img style="background-color: black;" src="/bot/captcha/icons/` + challengeID + `" /><br />
-<form action="/bot/captcha/solve" method="POST">
-	<input type="hidden" name="challenge_id" value="` + challengeID + `" />
-	Enter 0,1,2 or 3 and press Enter <input type="number" name="answer" />" +
-</form>` + challengeID
+// captchaPageHTML uses URLs relative to /bot/captcha so the page also works when ogamed is served under a base path
+const captchaPageHTML = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>ogamed captcha</title></head>
+<body>
+<img style="background-color: black;" src="captcha/question" /><br />
+<img style="background-color: black;" src="captcha/icons" /><br />
+<form action="captcha/solve" method="POST">
+	Enter 0,1,2 or 3 and press Enter <input type="number" name="answer" min="0" max="3" autofocus />
+</form>
+</body>
+</html>`
 
-		return c.HTML(http.StatusOK, html)
+// GetCaptchaHandler displays the pending captcha challenge and a form to answer it
+func GetCaptchaHandler(c echo.Context) error {
+	solver := c.Get("captchaSolver").(*ogame.ManualSolver)
+	if _, _, ok := solver.Pending(); !ok {
+		return c.HTML(http.StatusOK, "no captcha found")
 	}
-	return c.HTML(http.StatusOK, "no captcha found")
+	return c.HTML(http.StatusOK, captchaPageHTML)
 }
+
 // GetCaptchaImgHandler ...
 func GetCaptchaImgHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	challengeID := c.Param("challengeID")
-	req, _ := http.NewRequest("GET", "https://image-drop-challenge.gameforge.com/challenge/"+challengeID+"/en-GB/drag-icons", nil)
-	resp, _ := bot.doReqWithLoginProxyTransport(req)
-	//IMG: https://image-drop-challenge.gameforge.com/challenge/9c5c46b2-e479-4f17-bd35-03bc4e5beefc/en-GB/drag-icons?1611748479816
-	defer resp.Body.Close()
-	data, _, _ := readBody(resp)
-	if data == nil {
+	solver := c.Get("captchaSolver").(*ogame.ManualSolver)
+	_, icons, ok := solver.Pending()
+	if !ok {
 		return c.HTML(http.StatusNotFound, "File not Found")
 	}
-	return c.Blob(http.StatusOK, "image/png", data)
+	return c.Blob(http.StatusOK, "image/png", icons)
 }
 
 // GetCaptchaTextHandler ...
 func GetCaptchaTextHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	challengeID := c.Param("challengeID")
-	//TEXT: https://image-drop-challenge.gameforge.com/challenge/9c5c46b2-e479-4f17-bd35-03bc4e5beefc/en-GB/text?1611748479816
-	req, _ := http.NewRequest("GET", "https://image-drop-challenge.gameforge.com/challenge/"+challengeID+"/en-GB/text", nil)
-	resp, _ := bot.doReqWithLoginProxyTransport(req)
-	defer resp.Body.Close()
-	data, _, _ := readBody(resp)
-	if data == nil {
+	solver := c.Get("captchaSolver").(*ogame.ManualSolver)
+	question, _, ok := solver.Pending()
+	if !ok {
 		return c.HTML(http.StatusNotFound, "File not Found")
 	}
-	return c.Blob(http.StatusOK, "image/png", data)
+	return c.Blob(http.StatusOK, "image/png", question)
 }
 
 // GetCaptchaSolverHandler ...
+// curl 127.0.0.1:1234/bot/captcha/solve -d 'answer=2'
 func GetCaptchaSolverHandler(c echo.Context) error {
-	bot := c.Get("bot").(*ogame.OGame)
-	challengeID := c.Request().PostFormValue("challenge_id")
-	answer := c.Request().PostFormValue("answer")
-	payload := `{"answer":` + answer + `}`
-	req, _ := http.NewRequest("POST", "https://image-drop-challenge.gameforge.com/challenge/"+challengeID+"/en-GB", strings.NewReader(payload))
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
-	resp, _ := bot.doReqWithLoginProxyTransport(req)
-	defer resp.Body.Close()
-	if !bot.IsLoggedIn() {
-		if err := bot.Login(); err != nil {
-			bot.error(err)
-		}
+	solver := c.Get("captchaSolver").(*ogame.ManualSolver)
+	answer, err := strconv.ParseInt(c.Request().PostFormValue("answer"), 10, 64)
+	if err != nil || answer < 0 || answer > 3 {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid answer"))
+	}
+	if err := solver.Answer(answer); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResp(400, err.Error()))
 	}
-	return c.Redirect(http.StatusTemporaryRedirect, "/")
+	// Relative to /bot/captcha/solve, the root of ogamed with or without base path
+	return c.Redirect(http.StatusSeeOther, "../../")
 }
-
-*/
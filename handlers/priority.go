@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	"github.com/alaingilbert/ogame"
+)
+
+var priorityNames = map[string]int{
+	"low":       ogame.Low,
+	"normal":    ogame.Normal,
+	"important": ogame.Important,
+	"critical":  ogame.Critical,
+}
+
+// parsePriority parses a priority name (low, normal, important, critical) or value (1-4)
+func parsePriority(value string) (int, bool) {
+	if priority, ok := priorityNames[strings.ToLower(value)]; ok {
+		return priority, true
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < ogame.Low || priority > ogame.Critical {
+		return 0, false
+	}
+	return priority, true
+}
+
+// PriorityMiddleware reads the task priority from the X-Priority header or the priority query param
+// curl -H "X-Priority: critical" 127.0.0.1:1234/bot/attacks
+func PriorityMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		value := c.Request().Header.Get("X-Priority")
+		if value == "" {
+			value = c.QueryParam("priority")
+		}
+		if value == "" {
+			return next(c)
+		}
+		priority, ok := parsePriority(value)
+		if !ok {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid priority"))
+		}
+		c.Set("priority", priority)
+		return next(c)
+	}
+}
+
+// prioritizable returns the transaction of the request if any,
+// otherwise the bot with the priority requested by the client (Normal by default)
+func prioritizable(c echo.Context) ogame.Prioritizable {
+	if tx, ok := c.Get("tx").(ogame.Prioritizable); ok {
+		return tx
+	}
+	bot := c.Get("bot").(*ogame.OGame)
+	priority, ok := c.Get("priority").(int)
+	if !ok {
+		priority = ogame.Normal
+	}
+	return bot.WithPriority(priority)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticCache disk cache for the AntiGame static assets (/cdn/*, /assets/*, /api/*.xml).
+// Entries expire according to the Cache-Control max-age/Expires headers sent by the game.
+type StaticCache struct {
+	sync.Mutex
+	dir string
+	now func() time.Time
+}
+
+type staticCacheMeta struct {
+	URL     string
+	Header  http.Header
+	Expires time.Time
+}
+
+// NewStaticCache creates a disk cache in dir
+func NewStaticCache(dir string) (*StaticCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &StaticCache{dir: dir, now: time.Now}, nil
+}
+
+// isCacheableStatic returns true for the static paths that are cached
+func isCacheableStatic(path string) bool {
+	return strings.HasPrefix(path, "/cdn/") ||
+		strings.HasPrefix(path, "/assets/") ||
+		(strings.HasPrefix(path, "/api/") && strings.HasSuffix(path, ".xml"))
+}
+
+// cacheExpiry computes the expiry of a response from its cache headers, zero time if it must not be cached
+func cacheExpiry(header http.Header, now time.Time) time.Time {
+	cacheControl := strings.ToLower(header.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") || strings.Contains(cacheControl, "no-cache") || strings.Contains(cacheControl, "private") {
+		return time.Time{}
+	}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if strings.HasPrefix(directive, "max-age=") {
+			if secs, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64); err == nil && secs > 0 {
+				return now.Add(time.Duration(secs) * time.Second)
+			}
+			return time.Time{}
+		}
+	}
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil && expires.After(now) {
+		return expires
+	}
+	return time.Time{}
+}
+
+func (s *StaticCache) paths(url string) (string, string) {
+	h := sha1.Sum([]byte(url))
+	name := filepath.Join(s.dir, hex.EncodeToString(h[:]))
+	return name + ".json", name + ".body"
+}
+
+// Get returns the cached body and headers of url if present and not expired
+func (s *StaticCache) Get(url string) ([]byte, http.Header, bool) {
+	s.Lock()
+	defer s.Unlock()
+	metaPath, bodyPath := s.paths(url)
+	by, err := ioutil.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	var meta staticCacheMeta
+	if err := json.Unmarshal(by, &meta); err != nil || meta.URL != url || !s.now().Before(meta.Expires) {
+		_ = os.Remove(metaPath)
+		_ = os.Remove(bodyPath)
+		return nil, nil, false
+	}
+	body, err := ioutil.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	return body, meta.Header, true
+}
+
+// Put stores a response if its cache headers allow it
+func (s *StaticCache) Put(url string, body []byte, header http.Header) error {
+	expires := cacheExpiry(header, s.now())
+	if expires.IsZero() {
+		return nil
+	}
+	meta, err := json.Marshal(staticCacheMeta{URL: url, Header: header, Expires: expires})
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	metaPath, bodyPath := s.paths(url)
+	if err := ioutil.WriteFile(bodyPath, body, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(metaPath, meta, 0644)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/alaingilbert/ogame"
+)
+
+const (
+	defaultTxTimeout = 30 * time.Second
+	maxTxTimeout     = 5 * time.Minute
+)
+
+// restTx transaction opened over REST, the bot stays locked until it is released or expires
+type restTx struct {
+	sync.Mutex // Serialize the requests made within the transaction
+	tx         ogame.Prioritizable
+	timer      *time.Timer
+	released   bool
+}
+
+var transactions = struct {
+	sync.Mutex
+	m map[string]*restTx
+}{m: make(map[string]*restTx)}
+
+func newTxToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// releaseTx releases the bot lock held by a transaction, it is safe to call it several times
+func releaseTx(token string) bool {
+	transactions.Lock()
+	t, ok := transactions.m[token]
+	delete(transactions.m, token)
+	transactions.Unlock()
+	if !ok {
+		return false
+	}
+	t.Lock()
+	defer t.Unlock()
+	if !t.released {
+		t.released = true
+		t.timer.Stop()
+		t.tx.Done()
+	}
+	return true
+}
+
+// BeginTxHandler begins a transaction and returns its token. Requests carrying the token in the X-Tx-Token header
+// (or tx query param) run under the same lock. The transaction is released after timeout seconds (default 30, max 300).
+// curl 127.0.0.1:1234/bot/tx -X POST -d 'timeout=60'
+func BeginTxHandler(c echo.Context) error {
+	timeout := defaultTxTimeout
+	if timeoutStr := c.FormValue("timeout"); timeoutStr != "" {
+		secs, err := strconv.ParseInt(timeoutStr, 10, 64)
+		if err != nil || secs <= 0 || time.Duration(secs)*time.Second > maxTxTimeout {
+			return c.JSON(http.StatusBadRequest, ErrorResp(400, "invalid timeout"))
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+	token, err := newTxToken()
+	if err != nil {
+		return errorJSON(c, err, http.StatusInternalServerError)
+	}
+	t := &restTx{tx: prioritizable(c).BeginNamed("RestTx")}
+	t.timer = time.AfterFunc(timeout, func() { releaseTx(token) })
+	transactions.Lock()
+	transactions.m[token] = t
+	transactions.Unlock()
+	return c.JSON(http.StatusOK, SuccessResp(map[string]interface{}{
+		"token":     token,
+		"expiresAt": time.Now().Add(timeout),
+	}))
+}
+
+// EndTxHandler releases a transaction
+// curl 127.0.0.1:1234/bot/tx/<token> -X DELETE
+func EndTxHandler(c echo.Context) error {
+	if !releaseTx(c.Param("token")) {
+		return c.JSON(http.StatusNotFound, ErrorResp(404, "transaction not found"))
+	}
+	return c.JSON(http.StatusOK, SuccessResp(nil))
+}
+
+// InTx returns true if the request runs within a transaction
+func InTx(c echo.Context) bool {
+	_, ok := c.Get("tx").(ogame.Prioritizable)
+	return ok
+}
+
+// TxNotSupported answers the requests of the routes that cannot join a transaction: they wait for background tasks
+// or for the bot lock outside of the request, which would wait for the transaction to be released.
+func TxNotSupported(c echo.Context) error {
+	return c.JSON(http.StatusConflict, ErrorResp(409, "this route cannot run within a transaction"))
+}
+
+// TxMiddleware runs the requests carrying a transaction token within the transaction
+func TxMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := c.Request().Header.Get("X-Tx-Token")
+		if token == "" {
+			token = c.QueryParam("tx")
+		}
+		if token == "" || c.Path() == "/bot/tx" || c.Path() == "/bot/tx/:token" {
+			return next(c)
+		}
+		transactions.Lock()
+		t, ok := transactions.m[token]
+		transactions.Unlock()
+		if !ok {
+			return c.JSON(http.StatusNotFound, ErrorResp(404, "transaction not found or expired"))
+		}
+		t.Lock()
+		defer t.Unlock()
+		if t.released {
+			return c.JSON(http.StatusNotFound, ErrorResp(404, "transaction not found or expired"))
+		}
+		c.Set("tx", t.tx)
+		return next(c)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTxTestServer(t *testing.T) (*echo.Echo, string) {
+	bot, _ := ogame.NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	bot.ImportEmpire(ogame.EmpireExport{Planets: []ogame.Planet{{ID: 123, Coordinate: ogame.Coordinate{Galaxy: 1, System: 2, Position: 3, Type: ogame.PlanetType}}}})
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("bot", bot)
+			return next(c)
+		}
+	})
+	e.Use(TxMiddleware)
+	e.POST("/bot/tx", BeginTxHandler)
+	e.DELETE("/bot/tx/:token", EndTxHandler)
+	e.GET("/bot/items", GetAllItemsHandler)
+	e.POST("/bot/planets/:planetID/ipm-attack/:msgid", ExecuteIPMPlanHandler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/bot/tx", strings.NewReader("")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Result struct {
+			Token string `json:"token"`
+		}
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Result.Token)
+	return e, resp.Result.Token
+}
+
+func TestTxMiddleware_RouteJoinsTx(t *testing.T) {
+	e, token := newTxTestServer(t)
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/bot/items", nil)
+		req.Header.Set("X-Tx-Token", token)
+		e.ServeHTTP(rec, req)
+		done <- rec.Code
+	}()
+	select {
+	case code := <-done:
+		// The bot is logged out, the items requests of the transaction fail
+		assert.Equal(t, http.StatusInternalServerError, code)
+		releaseTx(token)
+	case <-time.After(5 * time.Second):
+		t.Fatal("request within the transaction is blocked by the transaction lock")
+	}
+}
+
+func TestTxMiddleware_RouteRejectsTx(t *testing.T) {
+	e, token := newTxTestServer(t)
+	defer releaseTx(token)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/bot/planets/123/ipm-attack/456", strings.NewReader(""))
+	req.Header.Set("X-Tx-Token", token)
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
@@ -0,0 +1,150 @@
+package ogame
+
+import (
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// HumanizerConfig settings of the human behavior simulation layer.
+// It inserts randomized delays between requests, occasionally loads harmless pages,
+// randomizes the order of batch operations and enforces quiet hours.
+type HumanizerConfig struct {
+	MinDelay time.Duration // Minimum delay before each request
+	MaxDelay time.Duration // Maximum delay before each request
+	// DecoyProbability probability (0 to 1) to load a harmless page before a request
+	DecoyProbability float64
+	// DecoyPages pages used as decoys, defaults to overview and galaxy
+	DecoyPages []string
+	// RandomizeBatches randomize the order of batch operations
+	RandomizeBatches bool
+	// QuietHoursStart/QuietHoursEnd hours (0-23) during which no request is sent.
+	// Quiet hours are disabled when both values are equal. The range can wrap around midnight (eg: 23 to 7).
+	QuietHoursStart int
+	QuietHoursEnd   int
+	// Location time zone used for quiet hours, defaults to the server time zone
+	Location *time.Location
+}
+
+type humanizer struct {
+	sync.Mutex
+	cfg HumanizerConfig
+	rnd *rand.Rand
+	now func() time.Time
+}
+
+func newHumanizer(cfg HumanizerConfig) *humanizer {
+	if len(cfg.DecoyPages) == 0 {
+		cfg.DecoyPages = []string{OverviewPage, GalaxyPage}
+	}
+	if cfg.MaxDelay < cfg.MinDelay {
+		cfg.MaxDelay = cfg.MinDelay
+	}
+	return &humanizer{cfg: cfg, rnd: rand.New(rand.NewSource(time.Now().UnixNano())), now: time.Now}
+}
+
+// isQuietHour returns either or not t is within the quiet hours
+func (h *humanizer) isQuietHour(t time.Time) bool {
+	start, end := h.cfg.QuietHoursStart, h.cfg.QuietHoursEnd
+	if start == end {
+		return false
+	}
+	if h.cfg.Location != nil {
+		t = t.In(h.cfg.Location)
+	}
+	hour := t.Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+func (h *humanizer) delay() time.Duration {
+	h.Lock()
+	defer h.Unlock()
+	if h.cfg.MaxDelay <= 0 {
+		return 0
+	}
+	spread := int64(h.cfg.MaxDelay - h.cfg.MinDelay)
+	if spread <= 0 {
+		return h.cfg.MinDelay
+	}
+	return h.cfg.MinDelay + time.Duration(h.rnd.Int63n(spread))
+}
+
+// decoyPage returns a page to load before the real request, or an empty string
+func (h *humanizer) decoyPage() string {
+	h.Lock()
+	defer h.Unlock()
+	if h.cfg.DecoyProbability <= 0 || h.rnd.Float64() >= h.cfg.DecoyProbability {
+		return ""
+	}
+	return h.cfg.DecoyPages[h.rnd.Intn(len(h.cfg.DecoyPages))]
+}
+
+func (h *humanizer) order(n int) []int {
+	h.Lock()
+	defer h.Unlock()
+	if !h.cfg.RandomizeBatches {
+		return identityOrder(n)
+	}
+	return h.rnd.Perm(n)
+}
+
+func identityOrder(n int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+// SetHumanizer enables the human behavior simulation layer, a nil config disables it
+func (b *OGame) SetHumanizer(cfg *HumanizerConfig) {
+	b.humanizerMu.Lock()
+	defer b.humanizerMu.Unlock()
+	if cfg == nil {
+		b.humanizer = nil
+		return
+	}
+	b.humanizer = newHumanizer(*cfg)
+}
+
+func (b *OGame) getHumanizer() *humanizer {
+	b.humanizerMu.RLock()
+	defer b.humanizerMu.RUnlock()
+	return b.humanizer
+}
+
+// HumanizedOrder returns the order in which a batch of n operations should be executed.
+// The order is random if the humanizer is enabled with RandomizeBatches, sequential otherwise.
+func (b *OGame) HumanizedOrder(n int) []int {
+	if h := b.getHumanizer(); h != nil {
+		return h.order(n)
+	}
+	return identityOrder(n)
+}
+
+// humanize is called before every game request, it waits a random delay and occasionally loads a decoy page
+func (b *OGame) humanize(vals url.Values) error {
+	h := b.getHumanizer()
+	if h == nil {
+		return nil
+	}
+	if h.isQuietHour(h.now()) {
+		return ErrQuietHours
+	}
+	if IsAjaxPage(vals) {
+		return nil // Ajax calls are triggered by the page that is already displayed
+	}
+	time.Sleep(h.delay())
+	if page := h.decoyPage(); page != "" && page != vals.Get("page") {
+		decoyVals := url.Values{"page": {"ingame"}, "component": {page}}
+		if _, err := b.execRequest("GET", b.serverURL+"/game/index.php?"+decoyVals.Encode(), nil, decoyVals, 0); err != nil {
+			b.error("failed to load decoy page : ", err)
+		}
+		time.Sleep(h.delay())
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package ogame
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanizer_isQuietHour(t *testing.T) {
+	h := newHumanizer(HumanizerConfig{QuietHoursStart: 23, QuietHoursEnd: 7, Location: time.UTC})
+	assert.True(t, h.isQuietHour(time.Date(2020, 1, 1, 23, 30, 0, 0, time.UTC)))
+	assert.True(t, h.isQuietHour(time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC)))
+	assert.False(t, h.isQuietHour(time.Date(2020, 1, 1, 7, 0, 0, 0, time.UTC)))
+	assert.False(t, h.isQuietHour(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+	h = newHumanizer(HumanizerConfig{QuietHoursStart: 2, QuietHoursEnd: 5, Location: time.UTC})
+	assert.True(t, h.isQuietHour(time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)))
+	assert.False(t, h.isQuietHour(time.Date(2020, 1, 1, 5, 0, 0, 0, time.UTC)))
+
+	h = newHumanizer(HumanizerConfig{})
+	assert.False(t, h.isQuietHour(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestHumanizer_delay(t *testing.T) {
+	h := newHumanizer(HumanizerConfig{MinDelay: time.Second, MaxDelay: 3 * time.Second})
+	for i := 0; i < 100; i++ {
+		d := h.delay()
+		assert.True(t, d >= time.Second && d < 3*time.Second)
+	}
+	assert.Equal(t, time.Duration(0), newHumanizer(HumanizerConfig{}).delay())
+}
+
+func TestHumanizedOrder(t *testing.T) {
+	b := &OGame{}
+	assert.Equal(t, []int{0, 1, 2}, b.HumanizedOrder(3))
+	b.SetHumanizer(&HumanizerConfig{RandomizeBatches: true})
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, b.HumanizedOrder(5))
+}
+
+func TestHumanize_quietHours(t *testing.T) {
+	b := &OGame{}
+	assert.NoError(t, b.humanize(url.Values{}))
+	b.SetHumanizer(&HumanizerConfig{QuietHoursStart: 0, QuietHoursEnd: 23})
+	b.getHumanizer().now = func() time.Time { return time.Date(2020, 1, 1, 10, 0, 0, 0, time.Local) }
+	assert.Equal(t, ErrQuietHours, b.humanize(url.Values{}))
+}
@@ -0,0 +1,56 @@
+package ogame
+
+import "fmt"
+
+// InactiveTargetsFilter which inactive players are returned by GetInactiveTargets
+type InactiveTargetsFilter struct {
+	LongInactiveOnly bool // Only the players inactive for 28 days or more
+	IncludeVacation  bool // Players in vacation mode cannot be attacked
+	IncludeBanned    bool
+	MinRank          int64 // Ignore the players ranked better than MinRank (eg: too strong for the noob protection), 0 to disable
+}
+
+// isInactiveTarget returns true if the planet belongs to an inactive player matching the filter
+func isInactiveTarget(p *PlanetInfos, filter InactiveTargetsFilter, botPlayerID int64) bool {
+	if p == nil || p.Destroyed || p.Administrator || p.Player.ID == botPlayerID || !p.Inactive {
+		return false
+	}
+	if filter.LongInactiveOnly && !p.LongInactive {
+		return false
+	}
+	if (p.Vacation && !filter.IncludeVacation) || (p.Banned && !filter.IncludeBanned) {
+		return false
+	}
+	if filter.MinRank > 0 && p.Player.Rank > 0 && p.Player.Rank < filter.MinRank {
+		return false
+	}
+	return true
+}
+
+// inactiveTargets returns the planets of the system that belong to inactive players matching the filter
+func inactiveTargets(system SystemInfos, filter InactiveTargetsFilter, botPlayerID int64) []PlanetInfos {
+	res := make([]PlanetInfos, 0)
+	system.Each(func(p *PlanetInfos) {
+		if isInactiveTarget(p, filter, botPlayerID) {
+			res = append(res, *p)
+		}
+	})
+	return res
+}
+
+// getInactiveTargets scans the systems of a galaxy from fromSystem to toSystem (included) and returns the planets of
+// the inactive players. The galaxy cache is used when enabled.
+func (b *OGame) getInactiveTargets(galaxy, fromSystem, toSystem int64, filter InactiveTargetsFilter) ([]PlanetInfos, error) {
+	if fromSystem > toSystem {
+		return nil, fmt.Errorf("invalid system range %d-%d", fromSystem, toSystem)
+	}
+	res := make([]PlanetInfos, 0)
+	for system := fromSystem; system <= toSystem; system++ {
+		systemInfos, err := b.galaxyInfos(galaxy, system)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, inactiveTargets(systemInfos, filter, b.Player.PlayerID)...)
+	}
+	return res, nil
+}
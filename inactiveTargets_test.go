@@ -0,0 +1,22 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInactiveTargets(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7/galaxy_debris16_2.html")
+	infos, _ := NewExtractorV7().ExtractGalaxyInfos(pageHTMLBytes, "Commodore Nomade", 123, 456)
+	targets := inactiveTargets(infos, InactiveTargetsFilter{}, 123)
+	assert.Equal(t, 1, len(targets))
+	assert.Equal(t, int64(8), targets[0].Coordinate.Position)
+	assert.Equal(t, 2, len(inactiveTargets(infos, InactiveTargetsFilter{IncludeVacation: true}, 123)))
+
+	pageHTMLBytes, _ = ioutil.ReadFile("samples/galaxy_outlaw.html")
+	infos, _ = NewExtractorV6().ExtractGalaxyInfos(pageHTMLBytes, "Commodore Nomade", 123, 456)
+	assert.Equal(t, 1, len(inactiveTargets(infos, InactiveTargetsFilter{}, 123)))
+	assert.Equal(t, 0, len(inactiveTargets(infos, InactiveTargetsFilter{LongInactiveOnly: true}, 123)))
+}
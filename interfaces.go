@@ -1,6 +1,7 @@
 package ogame
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 	"net/url"
@@ -14,6 +15,7 @@ import (
 type Prioritizable interface {
 	RecruitOfficer(typ, days int64) error
 	Abandon(interface{}) error
+	RenamePlanet(celestialID CelestialID, name string) error
 	ActivateItem(string, CelestialID) error
 	Begin() Prioritizable
 	BeginNamed(name string) Prioritizable
@@ -29,7 +31,10 @@ type Prioritizable interface {
 	DeleteMessage(msgID int64) error
 	FlightTime(origin, destination Coordinate, speed Speed, ships ShipsInfos, mission MissionID) (secs, fuel int64)
 	GalaxyInfos(galaxy, system int64, opts ...Option) (SystemInfos, error)
+	GetInactiveTargets(galaxy, fromSystem, toSystem int64, filter InactiveTargetsFilter) ([]PlanetInfos, error)
+	FindColonySpots(galaxyRange GalaxyRange, criteria ColonySpotsCriteria) ([]ColonySpot, error)
 	GetAlliancePageContent(url.Values) ([]byte, error)
+	GetAllItems() (AllItems, error)
 	GetAllResources() (map[CelestialID]Resources, error)
 	GetAttacks(...Option) ([]AttackEvent, error)
 	GetAuction() (Auction, error)
@@ -40,11 +45,23 @@ type Prioritizable interface {
 	GetDMCosts(CelestialID) (DMCosts, error)
 	GetEmpire(CelestialType) ([]EmpireCelestial, error)
 	GetEmpireJSON(nbr int64) (interface{}, error)
+	GetEmpireJSONMaxAge(nbr int64, maxAge time.Duration) (interface{}, Freshness, error)
+	GetEmpireSnapshot() (EmpireSnapshot, error)
+	SyncEmpire() (EmpireSnapshot, error)
 	GetEspionageReport(msgID int64) (EspionageReport, error)
+	GetEspionageReportShareKey(msgID int64) (string, error)
+	GetIPMPlan(msgID int64, maxPerWave int64, priorities []ID) (IPMPlan, error)
+	GetLobbyAccounts() ([]LobbyAccount, error)
+	GetMoonshotPlan(origin CelestialID, destination Coordinate, chance int64, ships []ID) (MoonshotPlan, error)
 	GetEspionageReportFor(Coordinate) (EspionageReport, error)
 	GetEspionageReportMessages() ([]EspionageReportSummary, error)
+	DiffLatestEspionageReports(coord Coordinate) (EspionageReportDiff, error)
+	GetCombatReportMessages() ([]CombatReportSummary, error)
+	SearchMessages(query MessageQuery) ([]Message, error)
+	GetCombatReportMessagesPage(page int64) ([]CombatReportSummary, int64, error)
 	GetExpeditionMessageAt(time.Time) (ExpeditionMessage, error)
 	GetExpeditionMessages() ([]ExpeditionMessage, error)
+	GetExpeditionStats(since time.Time) (ExpeditionStatsReport, error)
 	GetFleets(...Option) ([]Fleet, Slots)
 	GetFleetsFromEventList() []Fleet
 	GetItems(CelestialID) ([]Item, error)
@@ -54,7 +71,9 @@ type Prioritizable interface {
 	GetPageContent(url.Values) ([]byte, error)
 	GetPlanet(interface{}) (Planet, error)
 	GetPlanets() []Planet
+	GetPlanetsMaxAge(maxAge time.Duration) ([]Planet, Freshness)
 	GetResearch() Researches
+	GetResearchMaxAge(maxAge time.Duration) (Researches, Freshness)
 	GetSlots() Slots
 	GetUserInfos() UserInfos
 	HeadersForPage(url string) (http.Header, error)
@@ -63,14 +82,19 @@ type Prioritizable interface {
 	Login() error
 	LoginWithBearerToken(token string) (bool, error)
 	LoginWithExistingCookies() (bool, error)
+	MigrateUniverse() (UniverseMigration, error)
 	Logout()
+	SwitchUniverse(universe, lang string) error
 	OfferBuyMarketplace(itemID interface{}, quantity, priceType, price, priceRange int64, celestialID CelestialID) error
 	OfferSellMarketplace(itemID interface{}, quantity, priceType, price, priceRange int64, celestialID CelestialID) error
 	PostPageContent(url.Values, url.Values) ([]byte, error)
+	PostRawPageContent(vals url.Values, contentType string, body []byte) ([]byte, http.Header, error)
 	SendMessage(playerID int64, message string) error
 	SendMessageAlliance(associationID int64, message string) error
 	ServerTime() time.Time
 	SetInitiator(initiator string) Prioritizable
+	AllowFriendlyFire() Prioritizable
+	ValidateTarget(opts ...Option) Prioritizable
 	Tx(clb func(tx Prioritizable) error) error
 	UseDM(string, CelestialID) error
 
@@ -89,17 +113,30 @@ type Prioritizable interface {
 	GetDefense(CelestialID, ...Option) (DefensesInfos, error)
 	GetFacilities(CelestialID, ...Option) (Facilities, error)
 	GetProduction(CelestialID) ([]Quantifiable, int64, error)
+	GetProductionQueue(CelestialID) (ProductionQueue, error)
 	GetResources(CelestialID) (Resources, error)
 	GetResourcesBuildings(CelestialID, ...Option) (ResourcesBuildings, error)
+	GetMissingRequirements(celestialID CelestialID, id ID) ([]MissingRequirement, error)
 	GetResourcesDetails(CelestialID) (ResourcesDetails, error)
+	GetStorageETA() ([]StorageETA, error)
+	GetBuildRecommendations() ([]BuildRecommendation, error)
+	SetCharacterClass(CharacterClass) error
+	InvalidateCache(kinds ...CacheKind) error
 	GetTechs(celestialID CelestialID) (ResourcesBuildings, Facilities, ShipsInfos, DefensesInfos, Researches, error)
+	GetAllTechs() (AllTechs, error)
+	ExportEmpire() (EmpireExport, error)
+	ImportEmpire(e EmpireExport)
+	SetResourceSettingsAll(settings ResourceSettings) error
+	OptimizeResourceSettings(objective ProductionObjective, apply bool) (map[PlanetID]ResourceSettings, error)
 	GetShips(CelestialID, ...Option) (ShipsInfos, error)
 	SendFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate, mission MissionID, resources Resources, holdingTime, unionID int64) (Fleet, error)
+	ValidateFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate, mission MissionID, resources Resources) (FleetValidation, error)
 	TearDown(celestialID CelestialID, id ID) error
 
 	// Planet specific functions
 	GetResourceSettings(PlanetID, ...Option) (ResourceSettings, error)
 	GetResourcesProductions(PlanetID) (Resources, error)
+	AuditProduction(PlanetID) (ProductionAudit, error)
 	GetResourcesProductionsLight(ResourcesBuildings, Researches, ResourceSettings, Temperature) Resources
 	DestroyRockets(PlanetID, int64, int64) error
 	SendIPM(PlanetID, Coordinate, int64, ID) (int64, error)
@@ -117,6 +154,9 @@ type Wrapper interface {
 	Prioritizable
 	ValidateAccount(code string) error
 	AddAccount(number int, lang string) (NewAccount, error)
+	AddAccountAndSwitch(number int, lang string) (NewAccount, error)
+	GetLobbyServers(lang string, details bool) ([]LobbyServer, error)
+	AddFriendlyPlayers(playerIDs ...int64)
 	BytesDownloaded() int64
 	BytesUploaded() int64
 	IsPioneers() bool
@@ -124,47 +164,134 @@ type Wrapper interface {
 	Disable()
 	Distance(origin, destination Coordinate) int64
 	Enable()
+	FastestRoute(origin, destination Coordinate, speed Speed, ships ShipsInfos, missionID MissionID, gates []JumpGateMoon) Route
 	FleetDeutSaveFactor() float64
 	GetCachedCelestial(interface{}) Celestial
+	GetCelestialAliases() map[string]CelestialID
 	GetCachedCelestials() []Celestial
+	GetCachedEmpire() (EmpireSnapshot, bool)
 	GetCachedMoons() []Moon
 	GetCachedPlanets() []Planet
 	GetCachedPlayer() UserInfos
 	GetCachedPreferences() Preferences
+	GetAuditLog(since time.Time, action string) []AuditEntry
+	GetMarketplacePrices(since time.Time, interval time.Duration, itemID string) []MarketplacePriceStats
+	RecordMarketplaceObservation(o MarketplaceObservation) error
+	AddPhalanxWatch(moonID MoonID, coord Coordinate, interval time.Duration) (PhalanxWatch, error)
+	GetPhalanxWatches() []PhalanxWatch
+	RemovePhalanxWatch(id int64) error
+	SendExpedition(params ExpeditionParams) (Fleet, error)
+	GetExpeditionLoops() []ExpeditionLoop
+	StopExpeditionLoop(id int64) error
 	GetClient() *OGameClient
+	GetFriendlyPlayers() []int64
+	GetJumpGateLastJump(moonID MoonID) time.Time
+	StartEventsPoller(minInterval, maxInterval time.Duration) (stop func())
+	StartConstructionsWatcher(interval time.Duration) (stop func())
+	BuildAndWait(ctx context.Context, celestialID CelestialID, id ID) error
+	QueueShips(ctx context.Context, celestialID CelestialID, id ID, nbr int64) (int64, error)
+	ParseReportFromAPIKey(key string) (APIReport, error)
+	ExecuteIPMPlan(ctx context.Context, planetID PlanetID, plan IPMPlan, delay time.Duration) (int64, error)
+	ExecuteMoonshot(ctx context.Context, plan MoonshotPlan, speed Speed, harvestDelay time.Duration) (MoonshotExecution, error)
+	SetEscapeRule(rule EscapeRule) error
+	RemoveEscapeRule(celestialID CelestialID)
+	GetEscapeRules() []EscapeRule
+	StartStorageAlerts(threshold float64, interval time.Duration) (stop func())
+	StartMilitaryScoreAlerts(threshold float64, interval time.Duration) (stop func())
+	StartAutoABM(interval time.Duration) (stop func())
+	StartAutoRecyclers(params AutoRecyclersParams) (stop func())
+	SetWorkflowStore(s *WorkflowStore)
+	SetBuildTemplate(name string, items []BuildTemplateItem) error
+	RemoveBuildTemplate(name string)
+	GetBuildTemplates() map[string][]BuildTemplateItem
+	SetFleetTemplate(name string, t FleetTemplate) error
+	RemoveFleetTemplate(name string)
+	GetFleetTemplates() map[string]FleetTemplate
+	SendFleetTemplate(name string, celestialID CelestialID) (Fleet, error)
+	StartColonizeWorkflow(params ColonizeParams) (ColonizeWorkflow, error)
+	GetColonizeWorkflows() []ColonizeWorkflow
+	CancelColonizeWorkflow(id int64) error
+	ResumeColonizeWorkflows()
+	AddWatchedPlayer(playerID int64) (WatchedPlayer, error)
+	RemoveWatchedPlayer(playerID int64) error
+	GetWatchlist() []WatchedPlayer
+	LoadWatchlist(filename string) error
+	GetCacheInfo() []CacheInfo
+	GetBearerToken() (token string, expiresAt time.Time)
+	SetOTPCode(code string)
+	RefreshBearerToken() error
+	StartBearerTokenRefresher(margin time.Duration) (stop func())
+	StartScripts(dir string) (stop func(), err error)
+	EstimateLoot(report EspionageReport, plunderRatio float64, class CharacterClass) LootEstimate
+	RankFarmTargets(reports []EspionageReport, plunderRatio float64, class CharacterClass) []LootEstimate
+	RemoveFriendlyPlayers(playerIDs ...int64)
+	SetAuditLog(l *AuditLog)
+	SetMarketplacePriceHistory(h *MarketplacePriceHistory)
+	SetGalaxyCache(c *GalaxyCache)
+	GalaxyCachedAt(galaxy, system int64) time.Time
+	SetBrowserLogin(browserLogin BrowserLogin)
+	SetRetryPolicy(policy RetryPolicy)
+	SetRequestTimeout(timeout time.Duration)
+	SetTaskDeadline(deadline time.Duration)
+	SetRateLimit(rate float64, burst int64)
+	SetCelestialAlias(alias string, celestialID CelestialID)
 	SetClient(*OGameClient)
+	SetHumanizer(cfg *HumanizerConfig)
+	SetProxyPool(pool *ProxyPool, loginOnly bool)
+	SetTLSFingerprint(fingerprint string) error
+	SetProductionAudit(enabled bool)
+	GetCachedOfficers() Officers
 	GetExtractor() Extractor
 	GetLanguage() string
 	GetNbSystems() int64
+	GetObjNames(lang string) (map[ID]string, error)
+	GetProxyPool() *ProxyPool
 	GetPublicIP() (string, error)
 	GetResearchSpeed() int64
 	GetServer() Server
 	GetServerData() ServerData
+	GetCargoHyperspaceTechMultiplier() int64
 	GetSession() string
+	GetSessionCredentials() SessionCredentials
 	GetState() (bool, string)
 	GetTasks() TasksOverview
+	GetTaskHistory(since time.Time) []TaskRecord
 	GetUniverseName() string
 	GetUniverseSpeed() int64
 	GetUniverseSpeedFleet() int64
 	GetUsername() string
+	HumanizedOrder(n int) []int
 	IsConnected() bool
 	IsDonutGalaxy() bool
 	IsDonutSystem() bool
 	ConstructionTime(id ID, nbr int64, facilities Facilities) time.Duration
 	IsEnabled() bool
+	IsInSafeMode() bool
 	IsLocked() bool
 	IsLoggedIn() bool
 	IsVacationModeEnabled() bool
 	IsV7() bool
 	Location() *time.Location
+	ObjName(id ID, lang string) (string, error)
+	OnAccountBanned(clb func(err *AccountBanError))
+	OnEvent(clb func(Event))
+	Subscribe(typ EventType, clb func(Event)) (unsubscribe func())
+	SubscribeTasks(clb func(TaskEvent)) (unsubscribe func())
+	OnSafeMode(clb func(err error))
+	OnUniverseMigrated(clb func(UniverseMigration))
+	OnSessionLost(clb func(attempts int, err error))
 	OnStateChange(clb func(locked bool, actor string))
+	ParseObjName(name, lang string) (ID, error)
 	Quiet(bool)
 	ReconnectChat() bool
 	RegisterAuctioneerCallback(func(interface{}))
 	RegisterChatCallback(func(ChatMsg))
 	RegisterHTMLInterceptor(func(method, url string, params, payload url.Values, pageHTML []byte))
 	RegisterWSCallback(string, func([]byte))
+	RemoveCelestialAlias(alias string)
 	RemoveWSCallback(string)
+	ResolveCelestialAlias(alias string) (CelestialID, bool)
+	Resume()
 	ServerURL() string
 	ServerVersion() string
 	SetLoginWrapper(func(func() (bool, error)) error)
@@ -289,6 +416,7 @@ type Extractor interface {
 	ExtractFleet1Ships(pageHTML []byte) ShipsInfos
 	ExtractEspionageReportMessageIDs(pageHTML []byte) ([]EspionageReportSummary, int64)
 	ExtractCombatReportMessagesSummary(pageHTML []byte) ([]CombatReportSummary, int64)
+	ExtractMessages(pageHTML []byte, location *time.Location) ([]Message, int64)
 	ExtractEspionageReport(pageHTML []byte, location *time.Location) (EspionageReport, error)
 	ExtractResourcesProductions(pageHTML []byte) (Resources, error)
 	ExtractPreferences(pageHTML []byte) Preferences
@@ -326,6 +454,7 @@ type Extractor interface {
 	ExtractEspionageReportMessageIDsFromDoc(doc *goquery.Document) ([]EspionageReportSummary, int64)
 	ExtractCombatReportMessagesFromDoc(doc *goquery.Document) ([]CombatReportSummary, int64)
 	ExtractExpeditionMessagesFromDoc(doc *goquery.Document, location *time.Location) ([]ExpeditionMessage, int64, error)
+	ExtractMessagesFromDoc(doc *goquery.Document, location *time.Location) ([]Message, int64)
 	ExtractEspionageReportFromDoc(doc *goquery.Document, location *time.Location) (EspionageReport, error)
 	ExtractResourcesProductionsFromDoc(doc *goquery.Document) (Resources, error)
 	ExtractPreferencesFromDoc(doc *goquery.Document) Preferences
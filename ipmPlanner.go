@@ -0,0 +1,132 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// IPMDamage returns the damage of one interplanetary missile
+func IPMDamage(weaponsTechnology int64) int64 {
+	return Objs.ByID(InterplanetaryMissilesID).(DefenderObj).GetWeaponPower(Researches{WeaponsTechnology: weaponsTechnology})
+}
+
+// IPMPlanParams parameters of an interplanetary missiles attack plan
+type IPMPlanParams struct {
+	WeaponsTechnology int64 // Weapons technology of the attacker
+	MaxPerWave        int64 // Missiles launched per wave, e.g. the missiles in the silo, 0 for no limit
+	Priorities        []ID  // Defenses to destroy, in order. Every defense of the target, most expensive first, if empty
+}
+
+// IPMTarget missiles needed to destroy the defenses of one type
+type IPMTarget struct {
+	ID       ID
+	Nbr      int64 // Defenses of the target
+	Missiles int64 // Missiles needed to destroy them, not counting the ones intercepted by anti-ballistic missiles
+}
+
+// IPMWave missiles launched at once against a primary target
+type IPMWave struct {
+	Priority    ID
+	Missiles    int64
+	Intercepted int64 // Missiles destroyed by the anti-ballistic missiles of the target
+}
+
+// IPMPlan missiles and waves needed to destroy the defenses of a target
+type IPMPlan struct {
+	Coordinate            Coordinate
+	AntiBallisticMissiles int64
+	Targets               []IPMTarget
+	Waves                 []IPMWave
+	Missiles              int64 // Total of the waves, intercepted missiles included
+}
+
+// defaultIPMPriorities returns the defenses that can be targeted by missiles, most expensive first
+func defaultIPMPriorities() []ID {
+	ids := make([]ID, 0)
+	for _, d := range Defenses {
+		if id := d.GetID(); id != AntiBallisticMissilesID && id != InterplanetaryMissilesID {
+			ids = append(ids, id)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return Objs.ByID(ids[i]).GetPrice(1).Total() > Objs.ByID(ids[j]).GetPrice(1).Total()
+	})
+	return ids
+}
+
+// PlanIPMAttack computes the missiles needed to destroy the defenses of an espionage report, by order of priority.
+// Every missile deals IPMDamage to the structural integrity of its target. The anti-ballistic missiles of the target
+// intercept the first missiles, the first waves are enlarged to absorb them.
+func PlanIPMAttack(report EspionageReport, params IPMPlanParams) (IPMPlan, error) {
+	defenses := report.DefensesInfos()
+	if defenses == nil {
+		return IPMPlan{}, errors.New("no defenses information in the espionage report")
+	}
+	var armour Researches
+	if researches := report.Researches(); researches != nil {
+		armour.ArmourTechnology = researches.ArmourTechnology
+	}
+	priorities := params.Priorities
+	if len(priorities) == 0 {
+		priorities = defaultIPMPriorities()
+	}
+	damage := IPMDamage(params.WeaponsTechnology)
+	plan := IPMPlan{Coordinate: report.Coordinate, AntiBallisticMissiles: defenses.AntiBallisticMissiles}
+	abmLeft := plan.AntiBallisticMissiles
+	for _, id := range priorities {
+		if !id.IsDefense() || id == AntiBallisticMissilesID || id == InterplanetaryMissilesID {
+			return IPMPlan{}, errors.New("invalid defense target id " + id.String())
+		}
+		nbr := defenses.ByID(id)
+		if nbr <= 0 {
+			continue
+		}
+		hull := Objs.ByID(id).(DefenderObj).GetStructuralIntegrity(armour)
+		needed := (nbr*hull + damage - 1) / damage
+		plan.Targets = append(plan.Targets, IPMTarget{ID: id, Nbr: nbr, Missiles: needed})
+		for needed > 0 {
+			wave := IPMWave{Priority: id, Missiles: needed + abmLeft}
+			if params.MaxPerWave > 0 && wave.Missiles > params.MaxPerWave {
+				wave.Missiles = params.MaxPerWave
+			}
+			wave.Intercepted = MinInt(wave.Missiles, abmLeft)
+			abmLeft -= wave.Intercepted
+			needed -= wave.Missiles - wave.Intercepted
+			plan.Waves = append(plan.Waves, wave)
+			plan.Missiles += wave.Missiles
+		}
+	}
+	return plan, nil
+}
+
+// planIPMAttack plans an attack against the target of an espionage report with the player weapons technology
+func (b *OGame) planIPMAttack(msgID int64, maxPerWave int64, priorities []ID) (IPMPlan, error) {
+	report, err := b.getEspionageReport(msgID)
+	if err != nil {
+		return IPMPlan{}, err
+	}
+	researches := b.getCachedResearch()
+	return PlanIPMAttack(report, IPMPlanParams{WeaponsTechnology: researches.WeaponsTechnology, MaxPerWave: maxPerWave, Priorities: priorities})
+}
+
+// ExecuteIPMPlan launches the waves of the plan from the planet, waiting delay between two waves.
+// Returns the number of missiles launched, stops at the first failed wave or when ctx is done.
+func (b *OGame) ExecuteIPMPlan(ctx context.Context, planetID PlanetID, plan IPMPlan, delay time.Duration) (int64, error) {
+	var sent int64
+	for i, wave := range plan.Waves {
+		if i > 0 && delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return sent, ctx.Err()
+			}
+		}
+		if _, err := b.WithPriority(Normal).SendIPM(planetID, plan.Coordinate, wave.Missiles, wave.Priority); err != nil {
+			return sent, err
+		}
+		sent += wave.Missiles
+	}
+	return sent, nil
+}
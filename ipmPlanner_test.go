@@ -0,0 +1,52 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPMDamage(t *testing.T) {
+	assert.Equal(t, int64(12000), IPMDamage(0))
+	assert.Equal(t, int64(24000), IPMDamage(10))
+}
+
+func TestPlanIPMAttack(t *testing.T) {
+	n := func(v int64) *int64 { return &v }
+	report := EspionageReport{
+		Coordinate:             Coordinate{Galaxy: 1, System: 2, Position: 3, Type: PlanetType},
+		HasDefensesInformation: true,
+		RocketLauncher:         n(10),
+		PlasmaTurret:           n(2),
+		AntiBallisticMissiles:  n(3),
+	}
+	plan, err := PlanIPMAttack(report, IPMPlanParams{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), plan.AntiBallisticMissiles)
+	assert.Equal(t, []IPMTarget{{ID: PlasmaTurretID, Nbr: 2, Missiles: 17}, {ID: RocketLauncherID, Nbr: 10, Missiles: 2}}, plan.Targets)
+	assert.Equal(t, []IPMWave{{Priority: PlasmaTurretID, Missiles: 20, Intercepted: 3}, {Priority: RocketLauncherID, Missiles: 2}}, plan.Waves)
+	assert.Equal(t, int64(22), plan.Missiles)
+	assert.Equal(t, report.Coordinate, plan.Coordinate)
+
+	plan, _ = PlanIPMAttack(report, IPMPlanParams{MaxPerWave: 10})
+	assert.Equal(t, []IPMWave{{Priority: PlasmaTurretID, Missiles: 10, Intercepted: 3}, {Priority: PlasmaTurretID, Missiles: 10},
+		{Priority: RocketLauncherID, Missiles: 2}}, plan.Waves)
+	assert.Equal(t, int64(22), plan.Missiles)
+
+	plan, _ = PlanIPMAttack(report, IPMPlanParams{MaxPerWave: 2, Priorities: []ID{RocketLauncherID}})
+	assert.Equal(t, []IPMWave{{Priority: RocketLauncherID, Missiles: 2, Intercepted: 2}, {Priority: RocketLauncherID, Missiles: 2, Intercepted: 1},
+		{Priority: RocketLauncherID, Missiles: 1}}, plan.Waves)
+
+	plan, _ = PlanIPMAttack(report, IPMPlanParams{WeaponsTechnology: 10, Priorities: []ID{PlasmaTurretID}})
+	assert.Equal(t, int64(9), plan.Targets[0].Missiles)
+
+	report.HasResearchesInformation = true
+	report.ArmourTechnology = n(10)
+	plan, _ = PlanIPMAttack(report, IPMPlanParams{Priorities: []ID{RocketLauncherID}})
+	assert.Equal(t, int64(4), plan.Targets[0].Missiles)
+
+	_, err = PlanIPMAttack(report, IPMPlanParams{Priorities: []ID{AntiBallisticMissilesID}})
+	assert.NotNil(t, err)
+	_, err = PlanIPMAttack(EspionageReport{}, IPMPlanParams{})
+	assert.NotNil(t, err)
+}
@@ -1,5 +1,11 @@
 package ogame
 
+import (
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
 // Item Is an ogame item that can be activated
 type Item struct {
 	Ref            string
@@ -44,3 +50,100 @@ type ActiveItem struct {
 	TotalDuration int64
 	ImgSmall      string
 }
+
+// CelestialActiveItem item active on a celestial and when it expires
+type CelestialActiveItem struct {
+	ActiveItem
+	ExpiresAt time.Time
+}
+
+// CelestialItems items active on a celestial
+type CelestialItems struct {
+	CelestialID CelestialID
+	Coordinate  Coordinate
+	Active      []CelestialActiveItem
+}
+
+// AllItems inventory of the account and the items active on every celestial
+type AllItems struct {
+	Inventory  []Item
+	Celestials []CelestialItems
+}
+
+func newCelestialActiveItems(items []ActiveItem, now time.Time) []CelestialActiveItem {
+	res := make([]CelestialActiveItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, CelestialActiveItem{ActiveItem: item, ExpiresAt: now.Add(time.Duration(item.TimeRemaining) * time.Second)})
+	}
+	return res
+}
+
+// cacheActiveItems keeps the items active on the celestial of an overview page,
+// so the production calculations get the boosters without loading the page again
+func (b *OGame) cacheActiveItems(doc *goquery.Document) {
+	// The active items are only extracted since v7.1
+	extractor, ok := b.extractor.(interface {
+		ExtractActiveItemsFromDoc(*goquery.Document) ([]ActiveItem, error)
+	})
+	if !ok {
+		return
+	}
+	celestialID, err := b.extractor.ExtractPlanetIDFromDoc(doc)
+	if err != nil {
+		return
+	}
+	items, err := extractor.ExtractActiveItemsFromDoc(doc)
+	if err != nil {
+		return
+	}
+	b.activeItemsMu.Lock()
+	if b.activeItems == nil {
+		b.activeItems = make(map[CelestialID][]CelestialActiveItem)
+	}
+	b.activeItems[celestialID] = newCelestialActiveItems(items, time.Now())
+	b.activeItemsMu.Unlock()
+}
+
+// getCachedActiveItems returns the items active on a celestial when its overview page was last loaded,
+// without the ones that expired since
+func (b *OGame) getCachedActiveItems(celestialID CelestialID) []ActiveItem {
+	now := time.Now()
+	b.activeItemsMu.RLock()
+	defer b.activeItemsMu.RUnlock()
+	res := make([]ActiveItem, 0)
+	for _, item := range b.activeItems[celestialID] {
+		if item.ExpiresAt.After(now) {
+			active := item.ActiveItem
+			active.TimeRemaining = int64(item.ExpiresAt.Sub(now) / time.Second)
+			res = append(res, active)
+		}
+	}
+	return res
+}
+
+// getAllItems returns the items inventory and the items active on every celestial, tx holds the bot lock
+func (b *OGame) getAllItems(tx Prioritizable) (AllItems, error) {
+	res := AllItems{Inventory: make([]Item, 0), Celestials: make([]CelestialItems, 0)}
+	celestials := b.GetCachedCelestials()
+	if len(celestials) == 0 {
+		return res, nil
+	}
+	// The inventory is shared by the celestials
+	inventory, err := tx.GetItems(celestials[0].GetID())
+	if err != nil {
+		return res, err
+	}
+	res.Inventory = inventory
+	for _, celestial := range celestials {
+		active, err := tx.GetActiveItems(celestial.GetID())
+		if err != nil {
+			return res, err
+		}
+		res.Celestials = append(res.Celestials, CelestialItems{
+			CelestialID: celestial.GetID(),
+			Coordinate:  celestial.GetCoordinate(),
+			Active:      newCelestialActiveItems(active, time.Now()),
+		})
+	}
+	return res, nil
+}
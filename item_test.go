@@ -0,0 +1,37 @@
+package ogame
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCelestialActiveItems(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	items := newCelestialActiveItems([]ActiveItem{{Ref: "ba85cc2b8a5d986bbfba6954e2164ef71af95d4a", TimeRemaining: 3600, TotalDuration: 604800}}, now)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "ba85cc2b8a5d986bbfba6954e2164ef71af95d4a", items[0].Ref)
+	assert.Equal(t, now.Add(time.Hour), items[0].ExpiresAt)
+	assert.Equal(t, 0, len(newCelestialActiveItems(nil, now)))
+}
+
+func TestCacheActiveItems(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7.6.6/en/overview_with_active_items.html")
+	doc, _ := goquery.NewDocumentFromReader(bytes.NewReader(pageHTMLBytes))
+	b.cacheActiveItems(doc)
+	items := b.getCachedActiveItems(CelestialID(33738457))
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "ba85cc2b8a5d986bbfba6954e2164ef71af95d4a", items[0].Ref)
+	assert.InDelta(t, 579307, items[0].TimeRemaining, 1)
+	assert.Equal(t, BoostersBonus{Metal: 0.2, Deuterium: 0.3}, GetBoostersBonus(items))
+	assert.Equal(t, 0, len(b.getCachedActiveItems(CelestialID(1))))
+
+	// The expired items are dropped
+	b.activeItems[CelestialID(33738457)][0].ExpiresAt = time.Now().Add(-time.Second)
+	assert.Equal(t, 1, len(b.getCachedActiveItems(CelestialID(33738457))))
+}
@@ -1,5 +1,11 @@
 package ogame
 
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
 type jumpGate struct {
 	BaseBuilding
 }
@@ -13,3 +19,76 @@ func newJumpGate() *jumpGate {
 	b.Requirements = map[ID]int64{LunarBaseID: 1, HyperspaceTechnologyID: 7}
 	return b
 }
+
+// JumpGateBaseCooldown recharge time of a level 1 jump gate after sending the whole moon fleet
+const JumpGateBaseCooldown = time.Hour
+
+// JumpGateCooldown returns the recharge time of a jump gate, each level above 1 reduces it by 30%.
+// The recharge time is proportional to shipsFraction, the share (0 to 1) of the moon ships sent through the gate.
+func JumpGateCooldown(level int64, shipsFraction float64) time.Duration {
+	if level <= 0 {
+		return 0
+	}
+	shipsFraction = math.Max(0, math.Min(1, shipsFraction))
+	return time.Duration(float64(JumpGateBaseCooldown) * math.Pow(0.7, float64(level-1)) * shipsFraction).Round(time.Second)
+}
+
+// CooldownError returned when the jump gate is recharging
+type CooldownError struct {
+	Remaining int64 // Seconds before the jump gate can be used again
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("jump gate is in recharge mode for %d seconds", e.Remaining)
+}
+
+// Cause returns ErrCooldown, compatible with errors.Cause
+func (e *CooldownError) Cause() error {
+	return ErrCooldown
+}
+
+// jumpGateCooldown returns a CooldownError if the jump gate of moonID is known to be recharging
+func (b *OGame) jumpGateCooldown(moonID MoonID) *CooldownError {
+	b.jumpGateMu.Lock()
+	defer b.jumpGateMu.Unlock()
+	readyAt, ok := b.jumpGateReadyAt[moonID]
+	if !ok {
+		return nil
+	}
+	remaining := int64(math.Ceil(time.Until(readyAt).Seconds()))
+	if remaining <= 0 {
+		delete(b.jumpGateReadyAt, moonID)
+		return nil
+	}
+	return &CooldownError{Remaining: remaining}
+}
+
+// setJumpGateCooldown records the recharge countdown (seconds) reported by the server
+func (b *OGame) setJumpGateCooldown(moonID MoonID, wait int64) *CooldownError {
+	b.jumpGateMu.Lock()
+	defer b.jumpGateMu.Unlock()
+	if b.jumpGateReadyAt == nil {
+		b.jumpGateReadyAt = make(map[MoonID]time.Time)
+	}
+	b.jumpGateReadyAt[moonID] = time.Now().Add(time.Duration(wait) * time.Second)
+	return &CooldownError{Remaining: wait}
+}
+
+// recordJump records a jump, both gates are recharging afterward
+func (b *OGame) recordJump(origin, dest MoonID) {
+	b.jumpGateMu.Lock()
+	defer b.jumpGateMu.Unlock()
+	if b.jumpGateLastJump == nil {
+		b.jumpGateLastJump = make(map[MoonID]time.Time)
+	}
+	now := time.Now()
+	b.jumpGateLastJump[origin] = now
+	b.jumpGateLastJump[dest] = now
+}
+
+// GetJumpGateLastJump returns the time of the last jump from/to a moon done by the bot, zero value if unknown
+func (b *OGame) GetJumpGateLastJump(moonID MoonID) time.Time {
+	b.jumpGateMu.Lock()
+	defer b.jumpGateMu.Unlock()
+	return b.jumpGateLastJump[moonID]
+}
@@ -0,0 +1,36 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJumpGateCooldown(t *testing.T) {
+	assert.Equal(t, time.Duration(0), JumpGateCooldown(0, 1))
+	assert.Equal(t, time.Hour, JumpGateCooldown(1, 1))
+	assert.Equal(t, 42*time.Minute, JumpGateCooldown(2, 1))
+	assert.Equal(t, 21*time.Minute, JumpGateCooldown(2, 0.5))
+	assert.Equal(t, time.Hour, JumpGateCooldown(1, 2))
+	assert.Equal(t, time.Duration(0), JumpGateCooldown(1, 0))
+}
+
+func TestJumpGateCooldownTracking(t *testing.T) {
+	bot := &OGame{}
+	assert.Nil(t, bot.jumpGateCooldown(1))
+	err := bot.setJumpGateCooldown(1, 120)
+	assert.Equal(t, int64(120), err.Remaining)
+	assert.Equal(t, ErrCooldown, errors.Cause(err))
+	assert.Equal(t, "jump gate is in recharge mode for 120 seconds", err.Error())
+	cooldownErr := bot.jumpGateCooldown(1)
+	assert.NotNil(t, cooldownErr)
+	assert.InDelta(t, 120, cooldownErr.Remaining, 1)
+	assert.Nil(t, bot.jumpGateCooldown(2))
+
+	assert.True(t, bot.GetJumpGateLastJump(1).IsZero())
+	bot.recordJump(1, 2)
+	assert.False(t, bot.GetJumpGateLastJump(1).IsZero())
+	assert.False(t, bot.GetJumpGateLastJump(2).IsZero())
+}
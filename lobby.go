@@ -0,0 +1,193 @@
+package ogame
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LobbyServer server listed by the lobby, ServerData holds the speeds, donut and version when the details are requested
+type LobbyServer struct {
+	Server
+	ServerData *ServerData `json:",omitempty"`
+}
+
+// LobbyAccount game account of the lobby login
+type LobbyAccount struct {
+	ID         int64 // Player ID
+	Name       string
+	Language   string
+	Number     int64  // Server number
+	ServerName string // Empty if the server is not listed by the lobby anymore
+	LastPlayed time.Time
+	Blocked    bool
+}
+
+// parseLobbyTime parses the dates returned by the lobby API (eg: 2020-04-21T10:12:03+0000)
+func parseLobbyTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02T15:04:05-0700", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func newLobbyAccounts(accounts []account, servers []Server) []LobbyAccount {
+	res := make([]LobbyAccount, 0, len(accounts))
+	for _, a := range accounts {
+		acc := LobbyAccount{
+			ID:         a.ID,
+			Name:       a.Name,
+			Language:   a.Server.Language,
+			Number:     a.Server.Number,
+			LastPlayed: parseLobbyTime(a.LastPlayed),
+			Blocked:    a.Blocked,
+		}
+		for _, s := range servers {
+			if s.Language == a.Server.Language && s.Number == a.Server.Number {
+				acc.ServerName = s.Name
+				break
+			}
+		}
+		res = append(res, acc)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].LastPlayed.After(res[j].LastPlayed) })
+	return res
+}
+
+// GetLobbyServers returns the servers of the lobby, filtered by language if not empty.
+// With details, the server data (speeds, donut, version) of every returned server is fetched too.
+func (b *OGame) GetLobbyServers(lang string, details bool) ([]LobbyServer, error) {
+	servers, err := getServers(b)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]LobbyServer, 0, len(servers))
+	for _, s := range servers {
+		if lang != "" && s.Language != lang {
+			continue
+		}
+		server := LobbyServer{Server: s}
+		if details {
+			serverData, err := b.getServerDataOf(s.Number, s.Language)
+			if err != nil {
+				return res, err
+			}
+			server.ServerData = &serverData
+		}
+		res = append(res, server)
+	}
+	return res, nil
+}
+
+func (b *OGame) getLobbyAccounts() ([]LobbyAccount, error) {
+	token, _ := b.GetBearerToken()
+	if token == "" {
+		return nil, errors.New("no bearer token available, login first")
+	}
+	accounts, err := getUserAccounts(b, token)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := getServers(b)
+	if err != nil {
+		return nil, err
+	}
+	return newLobbyAccounts(accounts, servers), nil
+}
+
+// resetAccountCaches drops the cached state of the current account before switching to another one
+func (b *OGame) resetAccountCaches() {
+	b.planetsMu.Lock()
+	b.planets = nil
+	b.planetsCachedAt = time.Time{}
+	b.planetsMu.Unlock()
+	b.researches = nil
+	b.researchesCachedAt = time.Time{}
+	b.activeItemsMu.Lock()
+	b.activeItems = nil
+	b.activeItemsMu.Unlock()
+	b.empireCacheMu.Lock()
+	b.empireCache = nil
+	b.empireSnapshot = nil
+	b.empireCacheMu.Unlock()
+	if galaxyCache := b.getGalaxyCache(); galaxyCache != nil {
+		if err := galaxyCache.Clear(); err != nil {
+			b.error("failed to clear galaxy cache: " + err.Error())
+		}
+	}
+}
+
+// stopAccountLoops stops the expedition loops, escape rules and phalanx watches of the current account before
+// switching to another one, their celestials belong to the previous account
+func (b *OGame) stopAccountLoops() {
+	for _, l := range b.GetExpeditionLoops() {
+		_ = b.StopExpeditionLoop(l.ID)
+	}
+	for _, r := range b.GetEscapeRules() {
+		b.RemoveEscapeRule(r.CelestialID)
+	}
+	for _, w := range b.GetPhalanxWatches() {
+		_ = b.RemovePhalanxWatch(w.ID)
+	}
+}
+
+// switchAccount logs out and logs in the account of the lobby login playing on universe/lang, playerID 0 picks the
+// first one. The bot logs back in the previous account if the new one cannot be logged in. The expedition loops,
+// escape rules and phalanx watches of the previous account are stopped.
+func (b *OGame) switchAccount(universe, lang string, playerID int64) error {
+	prevUniverse, prevLang, prevPlayerID := b.Universe, b.language, b.playerID
+	b.stopAccountLoops()
+	if b.IsLoggedIn() {
+		b.logout()
+	}
+	b.Universe = universe
+	b.language = lang
+	b.playerID = playerID
+	b.resetAccountCaches()
+	_, err := b.wrapLoginWithExistingCookies()
+	if err != nil && prevUniverse != "" {
+		b.Universe, b.language, b.playerID = prevUniverse, prevLang, prevPlayerID
+		b.resetAccountCaches()
+		if _, loginErr := b.wrapLoginWithExistingCookies(); loginErr != nil {
+			b.error("failed to log back in " + prevUniverse + ": " + loginErr.Error())
+		}
+	}
+	return err
+}
+
+// switchUniverse logs in the account of the lobby login playing on another universe
+func (b *OGame) switchUniverse(universe, lang string) error {
+	if universe == "" || lang == "" {
+		return errors.New("universe and lang are required")
+	}
+	return b.switchAccount(universe, lang, 0)
+}
+
+// AddAccountAndSwitch creates an account on the server number/lang of the lobby, then logs the bot in it
+func (b *OGame) AddAccountAndSwitch(number int, lang string) (NewAccount, error) {
+	tx := b.withPriority(Normal).begin("AddAccountAndSwitch")
+	defer tx.done()
+	newAccount, err := b.addAccountAndSwitch(number, lang)
+	tx.audit("AddAccountAndSwitch", err, AuditParams{"number": number, "lang": lang})
+	return newAccount, err
+}
+
+func (b *OGame) addAccountAndSwitch(number int, lang string) (NewAccount, error) {
+	newAccount, err := b.addAccount(number, lang)
+	if err != nil {
+		return newAccount, err
+	}
+	servers, err := getServers(b)
+	if err != nil {
+		return newAccount, err
+	}
+	for _, s := range servers {
+		if s.Language == newAccount.Server.Language && s.Number == int64(newAccount.Server.Number) {
+			return newAccount, b.switchAccount(s.Name, s.Language, int64(newAccount.ID))
+		}
+	}
+	return newAccount, fmt.Errorf("server %d, %s not found", newAccount.Server.Number, newAccount.Server.Language)
+}
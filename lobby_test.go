@@ -0,0 +1,69 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLobbyTime(t *testing.T) {
+	assert.Equal(t, time.Date(2020, 4, 21, 10, 12, 3, 0, time.UTC), parseLobbyTime("2020-04-21T10:12:03+0000").UTC())
+	assert.Equal(t, time.Date(2020, 4, 21, 10, 12, 3, 0, time.UTC), parseLobbyTime("2020-04-21T10:12:03Z").UTC())
+	assert.True(t, parseLobbyTime("").IsZero())
+}
+
+func TestNewLobbyAccounts(t *testing.T) {
+	var a1, a2 account
+	a1.ID, a1.Name, a1.LastPlayed = 1, "Bob", "2020-04-20T10:00:00+0000"
+	a1.Server.Language, a1.Server.Number = "en", 150
+	a2.ID, a2.Name, a2.LastPlayed, a2.Blocked = 2, "Bob", "2020-04-21T10:00:00+0000", true
+	a2.Server.Language, a2.Server.Number = "fr", 1
+	servers := []Server{{Language: "en", Number: 150, Name: "Hyperion"}}
+	accounts := newLobbyAccounts([]account{a1, a2}, servers)
+	assert.Equal(t, 2, len(accounts))
+	assert.Equal(t, int64(2), accounts[0].ID)
+	assert.True(t, accounts[0].Blocked)
+	assert.Equal(t, "", accounts[0].ServerName)
+	assert.Equal(t, "Hyperion", accounts[1].ServerName)
+	assert.Equal(t, int64(150), accounts[1].Number)
+	assert.Equal(t, time.Date(2020, 4, 20, 10, 0, 0, 0, time.UTC), accounts[1].LastPlayed.UTC())
+}
+
+func TestResetAccountCaches(t *testing.T) {
+	b := &OGame{}
+	b.planets = []Planet{{ID: 1}}
+	b.researches = &Researches{EnergyTechnology: 1}
+	b.empireCache = map[int64]cachedEmpireJSON{0: {}}
+	galaxyCache := newGalaxyCache(time.Minute)
+	_ = galaxyCache.Set(SystemInfos{galaxy: 1, system: 1})
+	b.SetGalaxyCache(galaxyCache)
+	b.resetAccountCaches()
+	assert.Equal(t, 0, len(b.planets))
+	assert.Nil(t, b.researches)
+	assert.Nil(t, b.empireCache)
+	assert.Equal(t, 0, galaxyCache.Len())
+}
+
+func TestSwitchUniverse_invalid(t *testing.T) {
+	b := &OGame{}
+	assert.NotNil(t, b.switchUniverse("", "en"))
+	assert.NotNil(t, b.switchUniverse("Hyperion", ""))
+}
+
+func TestStopAccountLoops(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	loopCanceled, watchCanceled := false, false
+	b.expeditionLoops = map[int64]*expeditionLoop{1: {ExpeditionLoop: ExpeditionLoop{ID: 1}, cancel: func() { loopCanceled = true }}}
+	b.phalanxWatches = map[int64]*phalanxWatch{1: {PhalanxWatch: PhalanxWatch{ID: 1}, cancel: func() { watchCanceled = true }}}
+	b.escapeRules = map[CelestialID]EscapeRule{1: {CelestialID: 1}}
+	b.escapeRunning = true
+	b.escapeStop = make(chan struct{})
+	b.stopAccountLoops()
+	assert.True(t, loopCanceled)
+	assert.True(t, watchCanceled)
+	assert.Equal(t, 0, len(b.GetExpeditionLoops()))
+	assert.Equal(t, 0, len(b.GetPhalanxWatches()))
+	assert.Equal(t, 0, len(b.GetEscapeRules()))
+	assert.False(t, b.escapeRunning)
+}
@@ -0,0 +1,162 @@
+package ogame
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// localizationXML api result from https://s1-en.ogame.gameforge.com/api/localization.xml
+type localizationXML struct {
+	Techs []struct {
+		ID   int64  `xml:"id,attr"`
+		Name string `xml:",chardata"`
+	} `xml:"techs>name"`
+}
+
+// parseLocalizationXML returns the names of the objects (buildings, researches, ships, defenses) by id
+func parseLocalizationXML(by []byte) (map[ID]string, error) {
+	var res localizationXML
+	if err := xml.Unmarshal(by, &res); err != nil {
+		return nil, err
+	}
+	names := make(map[ID]string)
+	for _, tech := range res.Techs {
+		if ID(tech.ID).IsValid() {
+			names[ID(tech.ID)] = strings.TrimSpace(tech.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no object names found in localization")
+	}
+	return names, nil
+}
+
+// normalizeObjName lower case name without accents, spaces and punctuation, eg: "Mine de métal" -> "minedemetal"
+func normalizeObjName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	name, _, _ = transform.String(t, name)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// parseObjName finds the id of a localized object name, the names of ID.String() are also accepted (eg: MetalMine)
+func parseObjName(names map[ID]string, name string) (ID, error) {
+	normalized := normalizeObjName(name)
+	if normalized != "" {
+		for id, n := range names {
+			if normalizeObjName(n) == normalized || normalizeObjName(id.String()) == normalized {
+				return id, nil
+			}
+		}
+	}
+	return 0, errors.New("unknown object name " + name)
+}
+
+// getLocalization fetches the object names of a language from the localization of the server, or of another server
+// of that language
+func (b *OGame) getLocalization(lang string) (map[ID]string, error) {
+	number := b.server.Number
+	if lang != b.language {
+		servers, err := getServers(b)
+		if err != nil {
+			return nil, err
+		}
+		serverLang := lang
+		if serverLang == "ba" {
+			serverLang = "yu"
+		}
+		number = 0
+		for _, s := range servers {
+			if s.Language == serverLang {
+				number = s.Number
+				break
+			}
+		}
+		if number == 0 {
+			return nil, errors.New("unsupported language " + lang)
+		}
+	}
+	req, err := http.NewRequest("GET", "https://s"+strconv.FormatInt(number, 10)+"-"+lang+".ogame.gameforge.com/api/localization.xml", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
+	req = req.WithContext(b.ctx)
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			b.error(err)
+		}
+	}()
+	by, err := wrapperReadBody(b, resp)
+	if err != nil {
+		return nil, err
+	}
+	b.bytesUploaded += req.ContentLength
+	return parseLocalizationXML(by)
+}
+
+// GetObjNames returns the names of the objects by id in a language, the language of the account if empty.
+// The localizations are fetched once per language.
+func (b *OGame) GetObjNames(lang string) (map[ID]string, error) {
+	lang = strings.ToLower(lang)
+	if lang == "" {
+		lang = b.language
+	}
+	b.localizationsMu.Lock()
+	defer b.localizationsMu.Unlock()
+	names, ok := b.localizations[lang]
+	if !ok {
+		var err error
+		if names, err = b.getLocalization(lang); err != nil {
+			return nil, err
+		}
+		if b.localizations == nil {
+			b.localizations = make(map[string]map[ID]string)
+		}
+		b.localizations[lang] = names
+	}
+	res := make(map[ID]string, len(names))
+	for id, name := range names {
+		res[id] = name
+	}
+	return res, nil
+}
+
+// ObjName returns the name of an object in a language, the language of the account if empty
+func (b *OGame) ObjName(id ID, lang string) (string, error) {
+	names, err := b.GetObjNames(lang)
+	if err != nil {
+		return "", err
+	}
+	name, ok := names[id]
+	if !ok {
+		return "", errors.New("unknown object id " + strconv.FormatInt(int64(id), 10))
+	}
+	return name, nil
+}
+
+// ParseObjName returns the id of an object from its name in a language, the language of the account if empty.
+// The case, accents and spaces are ignored.
+func (b *OGame) ParseObjName(name, lang string) (ID, error) {
+	names, err := b.GetObjNames(lang)
+	if err != nil {
+		return 0, err
+	}
+	return parseObjName(names, name)
+}
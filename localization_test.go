@@ -0,0 +1,57 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocalizationXML(t *testing.T) {
+	by := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<localization xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" timestamp="1600000000" serverId="fr1">
+	<techs>
+		<name id="1">Mine de métal</name>
+		<name id="202">Petit transporteur</name>
+		<name id="11101">Quartiers résidentiels</name>
+	</techs>
+	<missions>
+		<name id="1">Attaquer</name>
+	</missions>
+</localization>`)
+	names, err := parseLocalizationXML(by)
+	assert.NoError(t, err)
+	assert.Equal(t, map[ID]string{MetalMineID: "Mine de métal", SmallCargoID: "Petit transporteur"}, names)
+
+	_, err = parseLocalizationXML([]byte(`<localization></localization>`))
+	assert.Error(t, err)
+}
+
+func TestParseObjName(t *testing.T) {
+	names := map[ID]string{MetalMineID: "Mine de métal", SmallCargoID: "Petit transporteur"}
+	id, err := parseObjName(names, "mine de metal")
+	assert.NoError(t, err)
+	assert.Equal(t, MetalMineID, id)
+	id, err = parseObjName(names, "PetitTransporteur")
+	assert.NoError(t, err)
+	assert.Equal(t, SmallCargoID, id)
+	id, err = parseObjName(names, "SmallCargo")
+	assert.NoError(t, err)
+	assert.Equal(t, SmallCargoID, id)
+	_, err = parseObjName(names, "Grand transporteur")
+	assert.EqualError(t, err, "unknown object name Grand transporteur")
+	_, err = parseObjName(names, " ")
+	assert.Error(t, err)
+}
+
+func TestObjName_Cached(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.localizations = map[string]map[ID]string{"fr": {MetalMineID: "Mine de métal"}}
+	name, err := b.ObjName(MetalMineID, "FR")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mine de métal", name)
+	_, err = b.ObjName(CrystalMineID, "fr")
+	assert.EqualError(t, err, "unknown object id 2")
+	id, err := b.ParseObjName("Mine de Metal", "fr")
+	assert.NoError(t, err)
+	assert.Equal(t, MetalMineID, id)
+}
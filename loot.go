@@ -0,0 +1,107 @@
+package ogame
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LootWaves number of successive attacks estimated by EstimateLoot
+const LootWaves = 3
+
+// planetMaxTemperatures average maximum temperature of a planet per position
+var planetMaxTemperatures = [15]int64{240, 190, 140, 90, 80, 70, 60, 50, 40, 30, 20, 10, -30, -70, -110}
+
+// estimatedTemperature average temperature of a planet at a position, moons and unknown positions use the middle
+func estimatedTemperature(position int64) Temperature {
+	max := planetMaxTemperatures[7]
+	if position >= 1 && position <= 15 {
+		max = planetMaxTemperatures[position-1]
+	}
+	return Temperature{Min: max - 40, Max: max}
+}
+
+// LootEstimate resources that can be looted from an espionage report target
+type LootEstimate struct {
+	Coordinate   Coordinate
+	PlunderRatio float64
+	Production   Resources // Hourly production of the target, zero when the report has no buildings information
+	Regrowth     Resources // Produced since the report, within the storages capacity
+	Available    Resources // Resources on the target now
+	Waves        []Resources
+	Total        Resources // Sum of the waves
+}
+
+// regrow returns what a resource production adds in hours, without overflowing the storage
+func regrow(available, production, capacity int64, hours float64) int64 {
+	if production <= 0 || hours <= 0 || (capacity > 0 && available >= capacity) {
+		return 0
+	}
+	grown := int64(float64(production) * hours)
+	if capacity > 0 {
+		grown = MinInt(grown, capacity-available)
+	}
+	return grown
+}
+
+// estimateLoot computes the loot of LootWaves successive attacks landing at now, a plunder ratio of 0 uses the
+// report plunder ratio for the class. The resources grow from the report date when the report has the buildings.
+func estimateLoot(report EspionageReport, plunderRatio float64, class CharacterClass, universeSpeed int64, now time.Time) LootEstimate {
+	if plunderRatio <= 0 {
+		plunderRatio = report.PlunderRatio(class)
+	}
+	res := LootEstimate{Coordinate: report.Coordinate, PlunderRatio: plunderRatio, Waves: make([]Resources, 0, LootWaves)}
+	res.Available = Resources{Metal: report.Metal, Crystal: report.Crystal, Deuterium: report.Deuterium}
+	if buildings := report.ResourcesBuildings(); buildings != nil && !report.Coordinate.IsMoon() && universeSpeed > 0 {
+		var researches Researches
+		if r := report.Researches(); r != nil {
+			researches = *r
+		}
+		settings := ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100, FusionReactor: 100, SolarSatellite: 100}
+		res.Production, _ = computeAuditedProduction(ProductionAuditInputs{
+			Buildings:     *buildings,
+			Settings:      settings,
+			Researches:    researches,
+			Temperature:   estimatedTemperature(report.Coordinate.Position),
+			UniverseSpeed: universeSpeed,
+			Collector:     report.CharacterClass.IsCollector(),
+		})
+		res.Production.Energy = 0
+		hours := now.Sub(report.Date).Hours()
+		res.Regrowth = Resources{
+			Metal:     regrow(report.Metal, res.Production.Metal, MetalStorage.Capacity(buildings.MetalStorage), hours),
+			Crystal:   regrow(report.Crystal, res.Production.Crystal, CrystalStorage.Capacity(buildings.CrystalStorage), hours),
+			Deuterium: regrow(report.Deuterium, res.Production.Deuterium, DeuteriumTank.Capacity(buildings.DeuteriumTank), hours),
+		}
+		res.Available = res.Available.Add(res.Regrowth)
+	}
+	remaining := res.Available
+	for i := 0; i < LootWaves; i++ {
+		wave := Resources{
+			Metal:     int64(math.Floor(float64(remaining.Metal) * plunderRatio)),
+			Crystal:   int64(math.Floor(float64(remaining.Crystal) * plunderRatio)),
+			Deuterium: int64(math.Floor(float64(remaining.Deuterium) * plunderRatio)),
+		}
+		res.Waves = append(res.Waves, wave)
+		res.Total = res.Total.Add(wave)
+		remaining = remaining.Sub(wave)
+	}
+	return res
+}
+
+// EstimateLoot computes the loot of LootWaves successive attacks landing now on the target of an espionage report,
+// including what the target produced since the report. A plunder ratio of 0 uses the report plunder ratio for class.
+func (b *OGame) EstimateLoot(report EspionageReport, plunderRatio float64, class CharacterClass) LootEstimate {
+	return estimateLoot(report, plunderRatio, class, b.serverData.Speed, time.Now())
+}
+
+// RankFarmTargets estimates the loot of every report and sorts the targets by first wave loot, best first
+func (b *OGame) RankFarmTargets(reports []EspionageReport, plunderRatio float64, class CharacterClass) []LootEstimate {
+	now := time.Now()
+	res := make([]LootEstimate, 0, len(reports))
+	for _, report := range reports {
+		res = append(res, estimateLoot(report, plunderRatio, class, b.serverData.Speed, now))
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Waves[0].Total() > res[j].Waves[0].Total() })
+	return res
+}
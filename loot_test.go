@@ -0,0 +1,61 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatedTemperature(t *testing.T) {
+	assert.Equal(t, Temperature{Min: 200, Max: 240}, estimatedTemperature(1))
+	assert.Equal(t, Temperature{Min: -150, Max: -110}, estimatedTemperature(15))
+	assert.Equal(t, Temperature{Min: 10, Max: 50}, estimatedTemperature(0))
+}
+
+func TestRegrow(t *testing.T) {
+	assert.Equal(t, int64(2000), regrow(1000, 1000, 100000, 2))
+	assert.Equal(t, int64(500), regrow(99500, 1000, 100000, 2))
+	assert.Equal(t, int64(0), regrow(200000, 1000, 100000, 2))
+	assert.Equal(t, int64(0), regrow(1000, 1000, 100000, -1))
+}
+
+func TestEstimateLoot(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	report := EspionageReport{Resources: Resources{Metal: 100000, Crystal: 50000, Deuterium: 10000}, Coordinate: Coordinate{1, 2, 8, PlanetType}, Date: now.Add(-time.Hour)}
+	estimate := estimateLoot(report, 0, NoClass, 1, now)
+	assert.Equal(t, 0.5, estimate.PlunderRatio)
+	assert.Equal(t, Resources{}, estimate.Regrowth)
+	assert.Equal(t, 3, len(estimate.Waves))
+	assert.Equal(t, Resources{Metal: 50000, Crystal: 25000, Deuterium: 5000}, estimate.Waves[0])
+	assert.Equal(t, Resources{Metal: 25000, Crystal: 12500, Deuterium: 2500}, estimate.Waves[1])
+	assert.Equal(t, Resources{Metal: 87500, Crystal: 43750, Deuterium: 8750}, estimate.Total)
+
+	// With the buildings, the production since the report is added
+	report.HasBuildingsInformation = true
+	metalMine, crystalMine, deuteriumSynthesizer, solarPlant := int64(10), int64(8), int64(5), int64(15)
+	report.MetalMine, report.CrystalMine, report.DeuteriumSynthesizer, report.SolarPlant = &metalMine, &crystalMine, &deuteriumSynthesizer, &solarPlant
+	report.Metal = 1000
+	estimate = estimateLoot(report, 1, NoClass, 1, now)
+	assert.True(t, estimate.Production.Metal > 0)
+	assert.Equal(t, estimate.Production.Metal, estimate.Regrowth.Metal)
+	// The crystal storage is full (level 0 holds 10000)
+	assert.Equal(t, int64(0), estimate.Regrowth.Crystal)
+	assert.Equal(t, Resources{Metal: 1000 + estimate.Regrowth.Metal, Crystal: 50000, Deuterium: 10000}, estimate.Waves[0])
+	assert.Equal(t, Resources{}, estimate.Waves[1])
+}
+
+func TestRankFarmTargets(t *testing.T) {
+	b := &OGame{}
+	b.serverData.Speed = 1
+	reports := []EspionageReport{
+		{Resources: Resources{Metal: 1000}, Coordinate: Coordinate{1, 1, 1, PlanetType}},
+		{Resources: Resources{Metal: 5000}, Coordinate: Coordinate{1, 1, 2, PlanetType}},
+		{Resources: Resources{Crystal: 3000}, Coordinate: Coordinate{1, 1, 3, PlanetType}},
+	}
+	res := b.RankFarmTargets(reports, 0.5, NoClass)
+	assert.Equal(t, 3, len(res))
+	assert.Equal(t, Coordinate{1, 1, 2, PlanetType}, res[0].Coordinate)
+	assert.Equal(t, Coordinate{1, 1, 3, PlanetType}, res[1].Coordinate)
+	assert.Equal(t, Coordinate{1, 1, 1, PlanetType}, res[2].Coordinate)
+}
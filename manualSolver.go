@@ -0,0 +1,63 @@
+package ogame
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ManualSolver captcha solver that waits for a human to answer the challenge (eg: through the ogamed web UI)
+type ManualSolver struct {
+	sync.Mutex
+	timeout  time.Duration
+	question []byte
+	icons    []byte
+	answerCh chan int64
+}
+
+// NewManualSolver creates a manual solver, a challenge not answered within timeout makes the login fail
+func NewManualSolver(timeout time.Duration) *ManualSolver {
+	return &ManualSolver{timeout: timeout}
+}
+
+// Callback returns the CaptchaCallback to give to the bot
+func (s *ManualSolver) Callback() CaptchaCallback {
+	return func(question, icons []byte) (int64, error) {
+		answerCh := make(chan int64, 1)
+		s.Lock()
+		s.question, s.icons, s.answerCh = question, icons, answerCh
+		s.Unlock()
+		defer func() {
+			s.Lock()
+			s.question, s.icons, s.answerCh = nil, nil, nil
+			s.Unlock()
+		}()
+		select {
+		case answer := <-answerCh:
+			return answer, nil
+		case <-time.After(s.timeout):
+			return 0, errors.New("captcha was not answered in time")
+		}
+	}
+}
+
+// Pending returns the question and icons images of the challenge waiting for an answer
+func (s *ManualSolver) Pending() (question, icons []byte, ok bool) {
+	s.Lock()
+	defer s.Unlock()
+	return s.question, s.icons, s.answerCh != nil
+}
+
+// Answer submits the answer (0 indexed icon) of the pending challenge
+func (s *ManualSolver) Answer(answer int64) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.answerCh == nil {
+		return ErrNoPendingCaptcha
+	}
+	select {
+	case s.answerCh <- answer:
+	default:
+	}
+	return nil
+}
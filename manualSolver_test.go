@@ -0,0 +1,38 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManualSolver(t *testing.T) {
+	solver := NewManualSolver(time.Second)
+	assert.Equal(t, ErrNoPendingCaptcha, solver.Answer(1))
+	_, _, ok := solver.Pending()
+	assert.False(t, ok)
+
+	resCh := make(chan int64)
+	go func() {
+		answer, _ := solver.Callback()([]byte("question"), []byte("icons"))
+		resCh <- answer
+	}()
+	for {
+		if _, _, ok := solver.Pending(); ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	question, icons, _ := solver.Pending()
+	assert.Equal(t, []byte("question"), question)
+	assert.Equal(t, []byte("icons"), icons)
+	assert.Nil(t, solver.Answer(2))
+	assert.Equal(t, int64(2), <-resCh)
+}
+
+func TestManualSolver_Timeout(t *testing.T) {
+	solver := NewManualSolver(time.Millisecond)
+	_, err := solver.Callback()(nil, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,330 @@
+package ogame
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MaxMarketplaceObservations number of marketplace observations kept in memory
+const MaxMarketplaceObservations = 100000
+
+// Kinds of marketplace observations
+const (
+	MarketplaceOffer   = "offer"   // Offer created by the bot
+	MarketplaceListing = "listing" // Offer of another player seen on the marketplace
+	MarketplaceTrade   = "trade"   // Completed trade
+)
+
+// Sides of marketplace observations
+const (
+	MarketplaceBuy  = "buy"
+	MarketplaceSell = "sell"
+)
+
+// MarketplaceObservation offer seen on the marketplace, or trade completed on it
+type MarketplaceObservation struct {
+	Time      time.Time
+	Kind      string // offer | listing | trade
+	Side      string // buy | sell, side of the offer or of the bot in the trade
+	ItemID    string // Resource id (1: metal, 2: crystal, 3: deuterium), ship id or item ref
+	Quantity  int64
+	PriceType int64 // Resource the price is paid in (1: metal, 2: crystal, 3: deuterium)
+	Price     int64 // Price of the whole quantity
+	MessageID int64 `json:",omitempty"` // Marketplace message of a trade, a message is recorded once
+}
+
+// UnitPrice price of one unit of the item
+func (o MarketplaceObservation) UnitPrice() float64 {
+	if o.Quantity <= 0 {
+		return 0
+	}
+	return float64(o.Price) / float64(o.Quantity)
+}
+
+// MarketplacePriceStats unit price of an item, in a resource, over a period
+type MarketplacePriceStats struct {
+	ItemID    string
+	Side      string
+	PriceType int64
+	From      time.Time
+	To        time.Time
+	Count     int
+	Average   float64
+	Median    float64
+	Min       float64
+	Max       float64
+}
+
+// MarketplacePriceHistory append-only history of the marketplace offers, listings and trades.
+// When a file is used, each observation is appended as a JSON line and the previous ones are loaded on creation.
+type MarketplacePriceHistory struct {
+	sync.RWMutex
+	observations []MarketplaceObservation
+	messageIDs   map[int64]struct{}
+	file         *os.File
+}
+
+func newMarketplacePriceHistory() *MarketplacePriceHistory {
+	return &MarketplacePriceHistory{observations: make([]MarketplaceObservation, 0), messageIDs: make(map[int64]struct{})}
+}
+
+// NewMarketplacePriceHistory creates a history backed by filename, an empty filename keeps the history in memory only
+func NewMarketplacePriceHistory(filename string) (*MarketplacePriceHistory, error) {
+	h := newMarketplacePriceHistory()
+	if filename == "" {
+		return h, nil
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var o MarketplaceObservation
+		if err := json.Unmarshal(scanner.Bytes(), &o); err == nil {
+			h.append(o)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	h.file = f
+	return h, nil
+}
+
+func (h *MarketplacePriceHistory) append(o MarketplaceObservation) {
+	if o.MessageID != 0 {
+		h.messageIDs[o.MessageID] = struct{}{}
+	}
+	h.observations = append(h.observations, o)
+	if len(h.observations) > MaxMarketplaceObservations {
+		h.observations = h.observations[len(h.observations)-MaxMarketplaceObservations:]
+	}
+}
+
+// Record adds an observation to the history, a trade of an already recorded message is ignored
+func (h *MarketplacePriceHistory) Record(o MarketplaceObservation) error {
+	if o.Kind != MarketplaceOffer && o.Kind != MarketplaceListing && o.Kind != MarketplaceTrade {
+		return fmt.Errorf("invalid observation kind %q", o.Kind)
+	}
+	if o.Side != MarketplaceBuy && o.Side != MarketplaceSell {
+		return fmt.Errorf("invalid observation side %q", o.Side)
+	}
+	if o.ItemID == "" || o.Quantity <= 0 || o.Price <= 0 || o.PriceType < 1 || o.PriceType > 3 {
+		return fmt.Errorf("invalid observation")
+	}
+	if o.Time.IsZero() {
+		o.Time = time.Now()
+	}
+	h.Lock()
+	defer h.Unlock()
+	if _, ok := h.messageIDs[o.MessageID]; ok && o.MessageID != 0 {
+		return nil
+	}
+	h.append(o)
+	if h.file == nil {
+		return nil
+	}
+	by, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	_, err = h.file.Write(append(by, '\n'))
+	return err
+}
+
+// Observations returns the observations that happened after since, filtered by item if not empty
+func (h *MarketplacePriceHistory) Observations(since time.Time, itemID string) []MarketplaceObservation {
+	h.RLock()
+	defer h.RUnlock()
+	res := make([]MarketplaceObservation, 0)
+	for _, o := range h.observations {
+		if o.Time.Before(since) || (itemID != "" && o.ItemID != itemID) {
+			continue
+		}
+		res = append(res, o)
+	}
+	return res
+}
+
+// Stats returns the unit price statistics of the observations that happened after since, per item, side, price
+// resource and interval. An interval of 0 computes a single period.
+func (h *MarketplacePriceHistory) Stats(since time.Time, interval time.Duration, itemID string) []MarketplacePriceStats {
+	return marketplacePriceStats(h.Observations(since, itemID), interval)
+}
+
+// Close closes the file backing the history
+func (h *MarketplacePriceHistory) Close() error {
+	h.Lock()
+	defer h.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
+
+func marketplacePriceStats(observations []MarketplaceObservation, interval time.Duration) []MarketplacePriceStats {
+	type key struct {
+		itemID    string
+		side      string
+		priceType int64
+		from      int64
+	}
+	groups := make(map[key][]MarketplaceObservation)
+	keys := make([]key, 0)
+	for _, o := range observations {
+		k := key{itemID: o.ItemID, side: o.Side, priceType: o.PriceType}
+		if interval > 0 {
+			k.from = o.Time.Truncate(interval).Unix()
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], o)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].itemID != keys[j].itemID {
+			return keys[i].itemID < keys[j].itemID
+		}
+		if keys[i].side != keys[j].side {
+			return keys[i].side < keys[j].side
+		}
+		if keys[i].priceType != keys[j].priceType {
+			return keys[i].priceType < keys[j].priceType
+		}
+		return keys[i].from < keys[j].from
+	})
+	res := make([]MarketplacePriceStats, 0, len(keys))
+	for _, k := range keys {
+		group := groups[k]
+		prices := make([]float64, len(group))
+		stats := MarketplacePriceStats{ItemID: k.itemID, Side: k.side, PriceType: k.priceType, Count: len(group),
+			From: group[0].Time, To: group[0].Time}
+		sum := 0.0
+		for i, o := range group {
+			prices[i] = o.UnitPrice()
+			sum += prices[i]
+			if o.Time.Before(stats.From) {
+				stats.From = o.Time
+			}
+			if o.Time.After(stats.To) {
+				stats.To = o.Time
+			}
+		}
+		if interval > 0 {
+			stats.From = time.Unix(k.from, 0)
+			stats.To = stats.From.Add(interval)
+		}
+		sort.Float64s(prices)
+		stats.Average = sum / float64(len(prices))
+		stats.Min, stats.Max = prices[0], prices[len(prices)-1]
+		if n := len(prices); n%2 == 1 {
+			stats.Median = prices[n/2]
+		} else {
+			stats.Median = (prices[n/2-1] + prices[n/2]) / 2
+		}
+		res = append(res, stats)
+	}
+	return res
+}
+
+// recordMarketplaceOffer records an offer created by the bot
+func (b *OGame) recordMarketplaceOffer(side string, itemID interface{}, quantity, priceType, price int64) {
+	o := MarketplaceObservation{Kind: MarketplaceOffer, Side: side, ItemID: fmt.Sprint(itemID), Quantity: quantity,
+		PriceType: priceType, Price: price}
+	if err := b.RecordMarketplaceObservation(o); err != nil {
+		b.error("failed to record marketplace offer: " + err.Error())
+	}
+}
+
+// recordMarketplaceTrades records the trades completed by the bot from its marketplace messages, the messages which
+// item or price cannot be resolved are skipped
+func (b *OGame) recordMarketplaceTrades(msgs []MarketplaceMessage) {
+	for _, msg := range msgs {
+		o, ok := marketplaceTradeObservation(msg)
+		if !ok {
+			continue
+		}
+		if err := b.RecordMarketplaceObservation(o); err != nil {
+			b.error("failed to record marketplace trade: " + err.Error())
+		}
+	}
+}
+
+// marketplaceTradeObservation returns the trade of a purchase or sale message
+func marketplaceTradeObservation(msg MarketplaceMessage) (MarketplaceObservation, bool) {
+	o := MarketplaceObservation{Time: msg.CreatedAt, Kind: MarketplaceTrade, Quantity: msg.Quantity, Price: msg.Price,
+		MessageID: msg.ID}
+	switch msg.Type {
+	case 26:
+		o.Side = MarketplaceBuy
+	case 27:
+		o.Side = MarketplaceSell
+	default:
+		return o, false
+	}
+	if o.PriceType = marketplaceResourceType(msg.PriceResource); o.PriceType == 0 {
+		return o, false
+	}
+	if resourceType := marketplaceResourceType(msg.Item); resourceType != 0 {
+		o.ItemID = strconv.FormatInt(resourceType, 10)
+	} else if shipID := ShipName2ID(msg.Item); shipID.IsShip() {
+		o.ItemID = strconv.FormatInt(int64(shipID), 10)
+	} else {
+		return o, false
+	}
+	return o, o.Quantity > 0 && o.Price > 0
+}
+
+// marketplaceResourceType returns the marketplace type of a resource name (1: metal, 2: crystal, 3: deuterium), 0 if
+// name is not a resource
+func marketplaceResourceType(name string) int64 {
+	return map[string]int64{
+		"metal": 1, "metall": 1, "metallo": 1, "metaal": 1, "metalli": 1, "kov": 1, "fem": 1, "металл": 1, "μεταλλο": 1,
+		"crystal": 2, "kristall": 2, "cristal": 2, "cristallo": 2, "kristal": 2, "kryszta": 2, "krystal": 2,
+		"krystaly": 2, "kristaly": 2, "kristalli": 2, "кристалл": 2, "κρυσταλλο": 2,
+		"deuterium": 3, "deuterio": 3, "deuter": 3, "deuteriu": 3, "дейтерий": 3, "δευτεριο": 3,
+	}[normalizeName(name)]
+}
+
+func (b *OGame) getMarketplacePriceHistory() *MarketplacePriceHistory {
+	b.marketplaceHistoryMu.RLock()
+	defer b.marketplaceHistoryMu.RUnlock()
+	return b.marketplaceHistory
+}
+
+// SetMarketplacePriceHistory replaces the marketplace price history, nil disables the recording
+func (b *OGame) SetMarketplacePriceHistory(h *MarketplacePriceHistory) {
+	b.marketplaceHistoryMu.Lock()
+	defer b.marketplaceHistoryMu.Unlock()
+	b.marketplaceHistory = h
+}
+
+// RecordMarketplaceObservation records an offer seen on the marketplace or a completed trade.
+// The offers created by the bot and the trades of its marketplace messages are recorded automatically.
+func (b *OGame) RecordMarketplaceObservation(o MarketplaceObservation) error {
+	h := b.getMarketplacePriceHistory()
+	if h == nil {
+		return nil
+	}
+	return h.Record(o)
+}
+
+// GetMarketplacePrices returns the average/median unit price of the items observed on the marketplace since a date,
+// per interval (0 for a single period), filtered by item if not empty
+func (b *OGame) GetMarketplacePrices(since time.Time, interval time.Duration, itemID string) []MarketplacePriceStats {
+	h := b.getMarketplacePriceHistory()
+	if h == nil {
+		return []MarketplacePriceStats{}
+	}
+	return h.Stats(since, interval, itemID)
+}
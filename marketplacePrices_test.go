@@ -0,0 +1,104 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketplacePriceHistory_Record(t *testing.T) {
+	h := newMarketplacePriceHistory()
+	assert.Error(t, h.Record(MarketplaceObservation{Kind: MarketplaceListing, Side: "auction", ItemID: "1", Quantity: 1, PriceType: 2, Price: 1}))
+	assert.Error(t, h.Record(MarketplaceObservation{Kind: "auction", Side: MarketplaceSell, ItemID: "1", Quantity: 1, PriceType: 2, Price: 1}))
+	assert.Error(t, h.Record(MarketplaceObservation{Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "1", Quantity: 0, PriceType: 2, Price: 1}))
+	assert.Error(t, h.Record(MarketplaceObservation{Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "1", Quantity: 1, PriceType: 4, Price: 1}))
+	assert.NoError(t, h.Record(MarketplaceObservation{Kind: MarketplaceTrade, Side: MarketplaceSell, ItemID: "1", Quantity: 1000, PriceType: 2, Price: 500}))
+	observations := h.Observations(time.Time{}, "")
+	assert.Equal(t, 1, len(observations))
+	assert.False(t, observations[0].Time.IsZero())
+	assert.Equal(t, 0.5, observations[0].UnitPrice())
+}
+
+func TestMarketplacePriceHistory_Stats(t *testing.T) {
+	h := newMarketplacePriceHistory()
+	day := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	_ = h.Record(MarketplaceObservation{Time: day.Add(time.Hour), Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "204", Quantity: 10, PriceType: 1, Price: 20000})
+	_ = h.Record(MarketplaceObservation{Time: day.Add(2 * time.Hour), Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "204", Quantity: 10, PriceType: 1, Price: 40000})
+	_ = h.Record(MarketplaceObservation{Time: day.Add(3 * time.Hour), Kind: MarketplaceTrade, Side: MarketplaceSell, ItemID: "204", Quantity: 10, PriceType: 1, Price: 90000})
+	_ = h.Record(MarketplaceObservation{Time: day.Add(25 * time.Hour), Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "204", Quantity: 10, PriceType: 1, Price: 30000})
+	_ = h.Record(MarketplaceObservation{Time: day.Add(time.Hour), Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "1", Quantity: 1000, PriceType: 2, Price: 500})
+
+	stats := h.Stats(time.Time{}, 0, "204")
+	assert.Equal(t, 1, len(stats))
+	assert.Equal(t, 4, stats[0].Count)
+	assert.Equal(t, 4500.0, stats[0].Average)
+	assert.Equal(t, 3500.0, stats[0].Median)
+	assert.Equal(t, 2000.0, stats[0].Min)
+	assert.Equal(t, 9000.0, stats[0].Max)
+	assert.Equal(t, day.Add(time.Hour), stats[0].From)
+	assert.Equal(t, day.Add(25*time.Hour), stats[0].To)
+
+	stats = h.Stats(time.Time{}, 24*time.Hour, "")
+	assert.Equal(t, 3, len(stats))
+	assert.Equal(t, "1", stats[0].ItemID)
+	assert.Equal(t, "204", stats[1].ItemID)
+	assert.Equal(t, 3, stats[1].Count)
+	assert.Equal(t, 4000.0, stats[1].Median)
+	assert.Equal(t, day.Unix(), stats[1].From.Unix())
+	assert.Equal(t, day.Add(24*time.Hour).Unix(), stats[1].To.Unix())
+	assert.Equal(t, 1, stats[2].Count)
+
+	_ = h.Record(MarketplaceObservation{Time: day, Kind: MarketplaceListing, Side: MarketplaceBuy, ItemID: "204", Quantity: 10, PriceType: 1, Price: 10000})
+	stats = h.Stats(time.Time{}, 0, "204")
+	assert.Equal(t, 2, len(stats))
+	assert.Equal(t, MarketplaceBuy, stats[0].Side)
+	assert.Equal(t, 1000.0, stats[0].Median)
+	assert.Equal(t, MarketplaceSell, stats[1].Side)
+	assert.Equal(t, 4, stats[1].Count)
+}
+
+func TestMarketplaceTradeObservation(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7.2/en/sales_messages.html")
+	msgs, _, _ := NewExtractorV7().ExtractMarketplaceMessages(pageHTMLBytes, time.FixedZone("OGT", 3600))
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.recordMarketplaceTrades(msgs)
+	b.recordMarketplaceTrades(msgs)
+	observations := b.getMarketplacePriceHistory().Observations(time.Time{}, "")
+	assert.Equal(t, len(msgs), len(observations))
+	assert.Equal(t, MarketplaceTrade, observations[0].Kind)
+	assert.Equal(t, MarketplaceSell, observations[0].Side)
+	assert.Equal(t, "1", observations[0].ItemID)
+	assert.Equal(t, int64(100), observations[0].Quantity)
+	assert.Equal(t, int64(2), observations[0].PriceType)
+	assert.Equal(t, int64(60), observations[0].Price)
+	assert.Equal(t, msgs[0].ID, observations[0].MessageID)
+
+	o, ok := marketplaceTradeObservation(MarketplaceMessage{ID: 1, Type: 26, Item: "Kleiner Transporter", Quantity: 5, PriceResource: "Kristall", Price: 9000})
+	assert.True(t, ok)
+	assert.Equal(t, MarketplaceBuy, o.Side)
+	assert.Equal(t, "202", o.ItemID)
+	assert.Equal(t, int64(2), o.PriceType)
+	_, ok = marketplaceTradeObservation(MarketplaceMessage{ID: 2, Type: 26, Item: "Booster", Quantity: 1, PriceResource: "Metal", Price: 1})
+	assert.False(t, ok)
+}
+
+func TestMarketplacePriceHistory_File(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "marketplace")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "marketplace.log")
+	h, err := NewMarketplacePriceHistory(filename)
+	assert.NoError(t, err)
+	_ = h.Record(MarketplaceObservation{Kind: MarketplaceOffer, Side: MarketplaceSell, ItemID: "202", Quantity: 5, PriceType: 3, Price: 1000})
+	assert.NoError(t, h.Close())
+
+	h, err = NewMarketplacePriceHistory(filename)
+	assert.NoError(t, err)
+	defer h.Close()
+	observations := h.Observations(time.Time{}, "202")
+	assert.Equal(t, 1, len(observations))
+	assert.Equal(t, int64(1000), observations[0].Price)
+}
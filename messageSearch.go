@@ -0,0 +1,96 @@
+package ogame
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultSearchMessagesTabs tabs searched when the query does not specify any
+var DefaultSearchMessagesTabs = []int64{EspionageMessagesTab, CombatReportMessagesTab, ExpeditionMessagesTab, TransportMessagesTab}
+
+// MessageQuery filters the inbox messages, zero values are ignored
+type MessageQuery struct {
+	Tabs       []int64     // Tabs to search, DefaultSearchMessagesTabs if empty
+	Coordinate *Coordinate // Matches galaxy/system/position, the celestial type is only checked if set
+	PlayerName string      // Case insensitive, searched in the title, the sender and the content
+	From       time.Time
+	To         time.Time
+	MaxPages   int64 // Max pages fetched per tab, 0 for all pages
+}
+
+// Matches returns either or not a message satisfies the query
+func (q MessageQuery) Matches(msg Message) bool {
+	if !q.From.IsZero() && msg.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && msg.CreatedAt.After(q.To) {
+		return false
+	}
+	if q.PlayerName != "" {
+		name := strings.ToLower(q.PlayerName)
+		if !strings.Contains(strings.ToLower(msg.Title+" "+msg.Sender+" "+msg.Content), name) {
+			return false
+		}
+	}
+	if q.Coordinate != nil {
+		found := false
+		for _, coord := range msg.Coordinates {
+			if coord.Galaxy == q.Coordinate.Galaxy && coord.System == q.Coordinate.System && coord.Position == q.Coordinate.Position &&
+				(q.Coordinate.Type == 0 || coord.Type == q.Coordinate.Type) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *OGame) getMessagesPage(tabid, page int64) ([]Message, int64, error) {
+	pageHTML, err := b.getPageMessages(page, tabid)
+	if err != nil {
+		return []Message{}, 0, err
+	}
+	msgs, nbPage := b.extractor.ExtractMessages(pageHTML, b.location)
+	for i := range msgs {
+		msgs[i].TabID = tabid
+	}
+	return msgs, nbPage, nil
+}
+
+// searchMessages pages through the requested tabs. Messages are sorted newest first,
+// so a tab stops being fetched as soon as a page only contains messages older than the query From.
+func (b *OGame) searchMessages(query MessageQuery) ([]Message, error) {
+	tabs := query.Tabs
+	if len(tabs) == 0 {
+		tabs = DefaultSearchMessagesTabs
+	}
+	res := make([]Message, 0)
+	for _, tabid := range tabs {
+		var page int64 = 1
+		var nbPage int64 = 1
+		for page <= nbPage && (query.MaxPages <= 0 || page <= query.MaxPages) {
+			msgs, newNbPage, err := b.getMessagesPage(tabid, page)
+			if err != nil {
+				return res, err
+			}
+			tooOld := len(msgs) > 0
+			for _, msg := range msgs {
+				if query.Matches(msg) {
+					res = append(res, msg)
+				}
+				if query.From.IsZero() || !msg.CreatedAt.Before(query.From) {
+					tooOld = false
+				}
+			}
+			if tooOld {
+				break
+			}
+			nbPage = newNbPage
+			page++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,41 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMessages(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/messages.html")
+	msgs, nbPage := NewExtractorV6().ExtractMessages(pageHTMLBytes, time.UTC)
+	assert.Equal(t, int64(1), nbPage)
+	assert.Equal(t, 2, len(msgs))
+	assert.Equal(t, int64(6384072), msgs[0].ID)
+	assert.Equal(t, EspionageMessagesTab, msgs[0].TabID)
+	assert.Equal(t, "Fleet Command", msgs[0].Sender)
+	assert.Equal(t, Coordinate{4, 117, 6, PlanetType}, msgs[0].Coordinates[0])
+	assert.Equal(t, time.Date(2018, 7, 8, 2, 16, 16, 0, time.UTC), msgs[0].CreatedAt)
+	assert.Contains(t, msgs[0].Content, "LightningGoN")
+}
+
+func TestMessageQueryMatches(t *testing.T) {
+	msg := Message{
+		Title:       "Espionage report from Lust [4:117:6]",
+		Content:     "Player: LightningGoN (i)",
+		Coordinates: []Coordinate{{4, 117, 6, MoonType}},
+		CreatedAt:   time.Date(2018, 7, 8, 2, 16, 16, 0, time.UTC),
+	}
+	assert.True(t, MessageQuery{}.Matches(msg))
+	assert.True(t, MessageQuery{PlayerName: "lightninggon"}.Matches(msg))
+	assert.False(t, MessageQuery{PlayerName: "Bob"}.Matches(msg))
+	assert.True(t, MessageQuery{Coordinate: &Coordinate{Galaxy: 4, System: 117, Position: 6}}.Matches(msg))
+	assert.True(t, MessageQuery{Coordinate: &Coordinate{4, 117, 6, MoonType}}.Matches(msg))
+	assert.False(t, MessageQuery{Coordinate: &Coordinate{4, 117, 6, PlanetType}}.Matches(msg))
+	assert.False(t, MessageQuery{Coordinate: &Coordinate{4, 117, 7, 0}}.Matches(msg))
+	assert.True(t, MessageQuery{From: time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2018, 7, 9, 0, 0, 0, 0, time.UTC)}.Matches(msg))
+	assert.False(t, MessageQuery{From: time.Date(2018, 7, 9, 0, 0, 0, 0, time.UTC)}.Matches(msg))
+	assert.False(t, MessageQuery{To: time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)}.Matches(msg))
+}
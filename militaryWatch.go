@@ -0,0 +1,299 @@
+package ogame
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Military score alerts defaults
+const (
+	DefaultMilitaryDropThreshold = 0.2 // Military points ratio lost between two checks at which player.military_drop events are emitted
+	DefaultMilitaryWatchInterval = 30 * time.Minute
+)
+
+// Highscore category and type of the military ranking
+const (
+	highscorePlayerCategory int64 = 1
+	highscoreMilitaryType   int64 = 3
+)
+
+// WatchedPlayer player of the watchlist and its military score at the last check
+type WatchedPlayer struct {
+	PlayerID      int64
+	Name          string
+	MilitaryScore int64
+	Ships         int64
+	Page          int64 // Military highscore page the player was found on
+	AddedAt       time.Time
+	LastCheck     time.Time // Zero until the player is found in the highscore
+}
+
+// findMilitaryHighscorePlayer reads the military highscore page of a player, the game returns the page of the player
+// given as searchRelId. Returns ErrPlayerNotFound if the player is not in the highscore.
+func (b *OGame) findMilitaryHighscorePlayer(playerID int64) (Highscore, HighscorePlayer, error) {
+	vals := url.Values{
+		"page":        {HighscoreContentAjaxPage},
+		"category":    {strconv.FormatInt(highscorePlayerCategory, 10)},
+		"type":        {strconv.FormatInt(highscoreMilitaryType, 10)},
+		"searchRelId": {strconv.FormatInt(playerID, 10)},
+	}
+	pageHTML, err := b.postPageContent(vals, url.Values{})
+	if err != nil {
+		return Highscore{}, HighscorePlayer{}, err
+	}
+	highscore, err := b.extractor.ExtractHighscore(pageHTML)
+	if err != nil {
+		return highscore, HighscorePlayer{}, err
+	}
+	for _, hp := range highscore.Players {
+		if hp.ID == playerID {
+			return highscore, hp, nil
+		}
+	}
+	return highscore, HighscorePlayer{}, ErrPlayerNotFound
+}
+
+// lookupMilitaryHighscorePlayer finds the military highscore page of a player under the bot lock
+func (b *OGame) lookupMilitaryHighscorePlayer(priority int, playerID int64) (Highscore, HighscorePlayer, error) {
+	tx := b.withPriority(priority).begin("FindHighscorePlayer")
+	defer tx.done()
+	return b.findMilitaryHighscorePlayer(playerID)
+}
+
+// AddWatchedPlayer adds a player to the watchlist of the military score alerts, the player is looked up in the
+// military highscore first and ErrPlayerNotFound is returned if it is not there
+func (b *OGame) AddWatchedPlayer(playerID int64) (WatchedPlayer, error) {
+	if playerID <= 0 {
+		return WatchedPlayer{}, errors.New("invalid player id")
+	}
+	b.watchlistMu.Lock()
+	p, ok := b.watchlist[playerID]
+	b.watchlistMu.Unlock()
+	if ok {
+		return *p, nil
+	}
+	highscore, hp, err := b.lookupMilitaryHighscorePlayer(Normal, playerID)
+	if err != nil {
+		return WatchedPlayer{}, err
+	}
+	return b.addWatchedPlayer(hp, highscore.CurrPage, time.Now())
+}
+
+// addWatchedPlayer adds a player found in the military highscore to the watchlist
+func (b *OGame) addWatchedPlayer(hp HighscorePlayer, page int64, now time.Time) (WatchedPlayer, error) {
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	if b.watchlist == nil {
+		b.watchlist = make(map[int64]*WatchedPlayer)
+	}
+	if p, ok := b.watchlist[hp.ID]; ok {
+		return *p, nil
+	}
+	p := &WatchedPlayer{PlayerID: hp.ID, Name: hp.Name, MilitaryScore: hp.Score, Ships: hp.Ships, Page: page,
+		AddedAt: now, LastCheck: now}
+	b.watchlist[hp.ID] = p
+	return *p, b.saveWatchlist()
+}
+
+// RemoveWatchedPlayer removes a player from the watchlist
+func (b *OGame) RemoveWatchedPlayer(playerID int64) error {
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	if _, ok := b.watchlist[playerID]; !ok {
+		return errors.New("player not watched")
+	}
+	delete(b.watchlist, playerID)
+	return b.saveWatchlist()
+}
+
+// LoadWatchlist restores the watchlist saved in filename, then saves it there on every change. A missing file starts
+// an empty watchlist.
+func (b *OGame) LoadWatchlist(filename string) error {
+	watchlist := make(map[int64]*WatchedPlayer)
+	by, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		var players []WatchedPlayer
+		if err := json.Unmarshal(by, &players); err != nil {
+			return err
+		}
+		for i := range players {
+			p := players[i]
+			watchlist[p.PlayerID] = &p
+		}
+	}
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	b.watchlist = watchlist
+	b.watchlistFilename = filename
+	return nil
+}
+
+// saveWatchlist writes the watchlist to its file, the caller must hold watchlistMu
+func (b *OGame) saveWatchlist() error {
+	if b.watchlistFilename == "" {
+		return nil
+	}
+	players := make([]WatchedPlayer, 0, len(b.watchlist))
+	for _, p := range b.watchlist {
+		players = append(players, *p)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].PlayerID < players[j].PlayerID })
+	by, err := json.Marshal(players)
+	if err != nil {
+		return err
+	}
+	tmp := b.watchlistFilename + ".tmp"
+	if err := ioutil.WriteFile(tmp, by, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.watchlistFilename)
+}
+
+// GetWatchlist returns the watched players
+func (b *OGame) GetWatchlist() []WatchedPlayer {
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	res := make([]WatchedPlayer, 0, len(b.watchlist))
+	for _, p := range b.watchlist {
+		res = append(res, *p)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].PlayerID < res[j].PlayerID })
+	return res
+}
+
+// updateWatchlist records the military scores of the watched players found in a highscore page and returns the
+// player.military_drop events of the players that lost at least threshold of their points since the last check
+func (b *OGame) updateWatchlist(players []HighscorePlayer, page int64, threshold float64, now time.Time) []Event {
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	events := make([]Event, 0)
+	updated := false
+	for _, hp := range players {
+		p, ok := b.watchlist[hp.ID]
+		if !ok {
+			continue
+		}
+		updated = true
+		if !p.LastCheck.IsZero() && p.MilitaryScore > 0 {
+			if drop := float64(p.MilitaryScore-hp.Score) / float64(p.MilitaryScore); drop >= threshold {
+				events = append(events, NewMilitaryDropEvent(*p, hp, drop, threshold))
+			}
+		}
+		p.Name = hp.Name
+		p.MilitaryScore = hp.Score
+		p.Ships = hp.Ships
+		p.Page = page
+		p.LastCheck = now
+	}
+	if updated {
+		if err := b.saveWatchlist(); err != nil {
+			b.error("failed to save watchlist: " + err.Error())
+		}
+	}
+	return events
+}
+
+// watchlistPages returns the highscore pages to check first, the ones the watched players were last seen on
+func (b *OGame) watchlistPages() (pages []int64, count int) {
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	seen := make(map[int64]bool)
+	for _, p := range b.watchlist {
+		if p.Page > 0 && !seen[p.Page] {
+			seen[p.Page] = true
+			pages = append(pages, p.Page)
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i] < pages[j] })
+	return pages, len(b.watchlist)
+}
+
+// checkWatchlist reads the military highscore pages of the watched players and returns the drop events.
+// The pages the players were last seen on are read first, then the page of each player still missing is looked up,
+// so a check reads at most one page per watched player. A player that left the highscore is skipped.
+func (b *OGame) checkWatchlist(threshold float64) ([]Event, error) {
+	pages, count := b.watchlistPages()
+	if count == 0 {
+		return []Event{}, nil
+	}
+	events := make([]Event, 0)
+	found := make(map[int64]bool)
+	update := func(highscore Highscore, page int64) {
+		events = append(events, b.updateWatchlist(highscore.Players, page, threshold, time.Now())...)
+		for _, hp := range highscore.Players {
+			found[hp.ID] = true
+		}
+	}
+	for _, page := range pages {
+		highscore, err := b.WithPriority(Low).Highscore(highscorePlayerCategory, highscoreMilitaryType, page)
+		if err != nil {
+			return events, err
+		}
+		update(highscore, page)
+	}
+	for _, playerID := range b.missingWatchedPlayers(found) {
+		if found[playerID] {
+			continue // Found on the page of another missing player
+		}
+		highscore, _, err := b.lookupMilitaryHighscorePlayer(Low, playerID)
+		if err != nil && err != ErrPlayerNotFound {
+			return events, err
+		}
+		update(highscore, highscore.CurrPage)
+	}
+	return events, nil
+}
+
+// missingWatchedPlayers returns the ids of the watched players not in found
+func (b *OGame) missingWatchedPlayers(found map[int64]bool) []int64 {
+	b.watchlistMu.Lock()
+	defer b.watchlistMu.Unlock()
+	res := make([]int64, 0)
+	for id := range b.watchlist {
+		if !found[id] {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// StartMilitaryScoreAlerts checks the military score of the watched players every interval until the returned
+// function is called, a player.military_drop event is emitted when a player lost at least threshold (eg: 0.2) of
+// its military points since the previous check (likely a fleet crash).
+func (b *OGame) StartMilitaryScoreAlerts(threshold float64, interval time.Duration) (stop func()) {
+	if threshold <= 0 {
+		threshold = DefaultMilitaryDropThreshold
+	}
+	if interval <= 0 {
+		interval = DefaultMilitaryWatchInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			if b.isEnabled() && b.IsLoggedIn() {
+				// The drops found before an error are still reported
+				events, _ := b.checkWatchlist(threshold)
+				for _, e := range events {
+					b.emitEvent(e)
+				}
+			}
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,81 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchlist(t *testing.T) {
+	b := &OGame{}
+	_, err := b.AddWatchedPlayer(0)
+	assert.NotNil(t, err)
+	now := time.Now()
+	p, _ := b.addWatchedPlayer(HighscorePlayer{ID: 123, Name: "Bob", Score: 1000}, 2, now)
+	assert.Equal(t, int64(123), p.PlayerID)
+	assert.Equal(t, int64(1000), p.MilitaryScore)
+	assert.Equal(t, int64(2), p.Page)
+	_, _ = b.addWatchedPlayer(HighscorePlayer{ID: 42}, 1, now)
+	p, _ = b.addWatchedPlayer(HighscorePlayer{ID: 123, Score: 5}, 1, now)
+	assert.Equal(t, int64(1000), p.MilitaryScore)
+	watchlist := b.GetWatchlist()
+	assert.Equal(t, 2, len(watchlist))
+	assert.Equal(t, int64(42), watchlist[0].PlayerID)
+	assert.Nil(t, b.RemoveWatchedPlayer(42))
+	assert.NotNil(t, b.RemoveWatchedPlayer(42))
+	assert.Equal(t, 1, len(b.GetWatchlist()))
+}
+
+func TestUpdateWatchlist(t *testing.T) {
+	b := &OGame{}
+	now := time.Now()
+	_, _ = b.addWatchedPlayer(HighscorePlayer{ID: 123, Name: "Bob", Score: 100000, Ships: 500}, 2, now)
+
+	events := b.updateWatchlist([]HighscorePlayer{{ID: 123, Name: "Bob", Score: 100000, Ships: 500}, {ID: 7, Score: 10}}, 3, 0.2, now)
+	assert.Equal(t, 0, len(events))
+	p := b.GetWatchlist()[0]
+	assert.Equal(t, "Bob", p.Name)
+	assert.Equal(t, int64(100000), p.MilitaryScore)
+	assert.Equal(t, int64(3), p.Page)
+	pages, count := b.watchlistPages()
+	assert.Equal(t, []int64{3}, pages)
+	assert.Equal(t, 1, count)
+
+	// A small drop is ignored
+	events = b.updateWatchlist([]HighscorePlayer{{ID: 123, Name: "Bob", Score: 90000, Ships: 450}}, 3, 0.2, now)
+	assert.Equal(t, 0, len(events))
+
+	events = b.updateWatchlist([]HighscorePlayer{{ID: 123, Name: "Bob", Score: 45000, Ships: 10}}, 4, 0.2, now)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, MilitaryScoreDropEvent, events[0].Type)
+	data := events[0].Data.(EventMilitaryDropData)
+	assert.Equal(t, int64(90000), data.PreviousScore)
+	assert.Equal(t, int64(45000), data.Score)
+	assert.Equal(t, 0.5, data.Drop)
+	assert.Equal(t, []int64{123}, b.missingWatchedPlayers(map[int64]bool{}))
+	assert.Equal(t, 0, len(b.missingWatchedPlayers(map[int64]bool{123: true})))
+}
+
+func TestLoadWatchlist(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "watchlist")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "watchlist.json")
+	b := &OGame{}
+	assert.NoError(t, b.LoadWatchlist(filename))
+	_, _ = b.addWatchedPlayer(HighscorePlayer{ID: 123, Name: "Bob", Score: 1000}, 2, time.Now())
+	_, _ = b.addWatchedPlayer(HighscorePlayer{ID: 42}, 1, time.Now())
+	assert.NoError(t, b.RemoveWatchedPlayer(42))
+	b.updateWatchlist([]HighscorePlayer{{ID: 123, Name: "Bob", Score: 900}}, 3, 0.2, time.Now())
+
+	b = &OGame{}
+	assert.NoError(t, b.LoadWatchlist(filename))
+	watchlist := b.GetWatchlist()
+	assert.Equal(t, 1, len(watchlist))
+	assert.Equal(t, "Bob", watchlist[0].Name)
+	assert.Equal(t, int64(900), watchlist[0].MilitaryScore)
+	assert.Equal(t, int64(3), watchlist[0].Page)
+}
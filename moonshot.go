@@ -0,0 +1,187 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Moon creation
+const (
+	MaxMoonChance       = 20     // Moon chance in percent of a debris field of 2,000,000 units or more
+	DebrisPerMoonChance = 100000 // Debris units giving 1% of moon chance
+	defaultDebrisFactor = 0.3    // Part of the destroyed ships cost turned into debris, when the server one is unknown
+)
+
+// MoonChance returns the chance in percent that a combat creates a moon, 1% per 100,000 units of metal and crystal in
+// the debris field it created, 20% at most
+func MoonChance(debrisMetal, debrisCrystal int64) int64 {
+	return MinInt((debrisMetal+debrisCrystal)/DebrisPerMoonChance, MaxMoonChance)
+}
+
+// DebrisForMoonChance returns the debris needed for a moon chance in percent, capped to MaxMoonChance
+func DebrisForMoonChance(chance int64) int64 {
+	return MaxInt(MinInt(chance, MaxMoonChance), 0) * DebrisPerMoonChance
+}
+
+// ShipDebris returns the debris created by a destroyed ship
+func ShipDebris(id ID, debrisFactor float64) Resources {
+	price := Objs.ByID(id).GetPrice(1)
+	return Resources{
+		Metal:   int64(debrisFactor * float64(price.Metal)),
+		Crystal: int64(debrisFactor * float64(price.Crystal)),
+	}
+}
+
+// MoonshotParams parameters of a moonshot, ships sacrificed against a planet so the combat creates a moon
+type MoonshotParams struct {
+	Origin        CelestialID // Celestial the sacrificed ships and the recyclers are sent from
+	Destination   Coordinate  // Planet the moon is created for, it must destroy the sacrificed ships
+	Chance        int64       // Moon chance in percent, MaxMoonChance if 0
+	Ships         []ID        // Ships to sacrifice, in order. Every ship of the origin, cheapest first, if empty
+	Available     ShipsInfos  // Ships of the origin
+	DebrisFactor  float64     // Part of the destroyed ships cost turned into debris (server debrisFactor), 0.3 if 0
+	RecyclerCargo int64       // Cargo capacity of one recycler
+}
+
+// MoonshotPlan ships to sacrifice and recyclers to send to create a moon with a chance
+type MoonshotPlan struct {
+	Origin      CelestialID
+	Destination Coordinate
+	Chance      int64      // Moon chance of the debris field
+	Debris      Resources  // Debris field created by the sacrificed ships
+	Sacrificed  ShipsInfos // Ships sent to attack the destination
+	Recyclers   int64      // Recyclers needed to harvest the debris field
+}
+
+// defaultMoonshotShips returns the ships that can be sacrificed, cheapest first so the debris is close to the needed one
+func defaultMoonshotShips() []ID {
+	ids := make([]ID, 0)
+	for _, s := range Ships {
+		if id := s.GetID(); isMoonshotShip(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return Objs.ByID(ids[i]).GetPrice(1).Total() < Objs.ByID(ids[j]).GetPrice(1).Total()
+	})
+	return ids
+}
+
+// isMoonshotShip returns true if the ship can be sent to attack and is not needed to harvest the debris
+func isMoonshotShip(id ID) bool {
+	return id.IsShip() && id != SolarSatelliteID && id != CrawlerID && id != RecyclerID
+}
+
+// PlanMoonshot computes the ships to sacrifice to create a debris field with the moon chance, and the recyclers needed
+// to harvest it. The destination must destroy every sacrificed ship, eg: a planet with enough defenses, the debris of
+// its own ships is not counted.
+func PlanMoonshot(params MoonshotParams) (MoonshotPlan, error) {
+	chance := params.Chance
+	if chance == 0 {
+		chance = MaxMoonChance
+	}
+	if chance < 0 || chance > MaxMoonChance {
+		return MoonshotPlan{}, errors.New("invalid moon chance " + strconv.FormatInt(chance, 10))
+	}
+	if !params.Destination.IsPlanet() {
+		return MoonshotPlan{}, errors.New("invalid destination " + params.Destination.String())
+	}
+	debrisFactor := params.DebrisFactor
+	if debrisFactor <= 0 {
+		debrisFactor = defaultDebrisFactor
+	}
+	ships := params.Ships
+	if len(ships) == 0 {
+		ships = defaultMoonshotShips()
+	}
+	plan := MoonshotPlan{Origin: params.Origin, Destination: params.Destination}
+	needed := DebrisForMoonChance(chance)
+	for _, id := range ships {
+		if !isMoonshotShip(id) {
+			return MoonshotPlan{}, errors.New("invalid moonshot ship id " + id.String())
+		}
+		remaining := needed - plan.Debris.Metal - plan.Debris.Crystal
+		if remaining <= 0 {
+			break
+		}
+		debris := ShipDebris(id, debrisFactor)
+		perShip := debris.Metal + debris.Crystal
+		if perShip <= 0 {
+			continue
+		}
+		nbr := MinInt((remaining+perShip-1)/perShip, params.Available.ByID(id))
+		if nbr <= 0 {
+			continue
+		}
+		plan.Sacrificed.AddShips(id, nbr)
+		plan.Debris = plan.Debris.Add(debris.Mul(nbr))
+	}
+	plan.Chance = MoonChance(plan.Debris.Metal, plan.Debris.Crystal)
+	if plan.Chance < chance {
+		return plan, errors.New("not enough ships for a moon chance of " + strconv.FormatInt(chance, 10) +
+			"%, the ships give " + strconv.FormatInt(plan.Chance, 10) + "%")
+	}
+	if params.RecyclerCargo > 0 {
+		plan.Recyclers = int64(math.Ceil(float64(plan.Debris.Metal+plan.Debris.Crystal) / float64(params.RecyclerCargo)))
+	}
+	return plan, nil
+}
+
+// planMoonshot plans a moonshot with the ships of the origin and the player researches
+func (b *OGame) planMoonshot(origin CelestialID, destination Coordinate, chance int64, ships []ID) (MoonshotPlan, error) {
+	available, err := b.getShips(origin)
+	if err != nil {
+		return MoonshotPlan{}, err
+	}
+	return PlanMoonshot(MoonshotParams{
+		Origin:        origin,
+		Destination:   destination,
+		Chance:        chance,
+		Ships:         ships,
+		Available:     available,
+		DebrisFactor:  b.serverData.DebrisFactor,
+		RecyclerCargo: b.fleetCargo(ShipsInfos{Recycler: 1}),
+	})
+}
+
+// MoonshotExecution fleets sent by ExecuteMoonshot
+type MoonshotExecution struct {
+	Attack  Fleet // Sacrificed ships
+	Recycle Fleet // Recyclers, they arrive harvestDelay after the combat
+}
+
+// ExecuteMoonshot sends the sacrificed ships of the plan to attack the destination, then sends the recyclers so they
+// reach the debris field harvestDelay after the combat. Both fleets fly at speed. Returns once the recyclers are sent,
+// stops at the first failed fleet or when ctx is done.
+func (b *OGame) ExecuteMoonshot(ctx context.Context, plan MoonshotPlan, speed Speed, harvestDelay time.Duration) (MoonshotExecution, error) {
+	var res MoonshotExecution
+	origin := b.GetCachedCelestialByID(plan.Origin)
+	if origin == nil {
+		return res, errors.New("origin celestial not found")
+	}
+	if !plan.Sacrificed.HasShips() {
+		return res, errors.New("no ships to sacrifice")
+	}
+	var err error
+	if res.Attack, err = b.WithPriority(Normal).SendFleet(plan.Origin, plan.Sacrificed.ToQuantifiables(), speed, plan.Destination, Attack, Resources{}, 0, 0); err != nil {
+		return res, err
+	}
+	if plan.Recyclers <= 0 {
+		return res, nil
+	}
+	recyclers := ShipsInfos{Recycler: plan.Recyclers}
+	debris := plan.Destination.Debris()
+	secs, _ := b.FlightTime(origin.GetCoordinate(), debris, speed, recyclers, RecycleDebrisField)
+	departure := res.Attack.ArrivalTime.Add(harvestDelay - time.Duration(secs)*time.Second)
+	select {
+	case <-time.After(time.Until(departure)):
+	case <-ctx.Done():
+		return res, ctx.Err()
+	}
+	res.Recycle, err = b.WithPriority(Normal).SendFleet(plan.Origin, recyclers.ToQuantifiables(), speed, debris, RecycleDebrisField, Resources{}, 0, 0)
+	return res, err
+}
@@ -0,0 +1,78 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoonChance(t *testing.T) {
+	assert.Equal(t, int64(0), MoonChance(99999, 0))
+	assert.Equal(t, int64(1), MoonChance(60000, 40000))
+	assert.Equal(t, int64(15), MoonChance(1000000, 500000))
+	assert.Equal(t, int64(20), MoonChance(5000000, 5000000))
+}
+
+func TestDebrisForMoonChance(t *testing.T) {
+	assert.Equal(t, int64(500000), DebrisForMoonChance(5))
+	assert.Equal(t, int64(2000000), DebrisForMoonChance(30))
+	assert.Equal(t, int64(0), DebrisForMoonChance(-1))
+	assert.Equal(t, MoonChance(DebrisForMoonChance(12), 0), int64(12))
+}
+
+func TestShipDebris(t *testing.T) {
+	assert.Equal(t, Resources{Metal: 900, Crystal: 300}, ShipDebris(LightFighterID, 0.3))
+	assert.Equal(t, Resources{Metal: 22500, Crystal: 7500}, ShipDebris(BattleshipID, 0.5))
+}
+
+func TestPlanMoonshot(t *testing.T) {
+	dest := Coordinate{Galaxy: 1, System: 2, Position: 3, Type: PlanetType}
+	params := MoonshotParams{
+		Destination:   dest,
+		Ships:         []ID{LightFighterID},
+		Available:     ShipsInfos{LightFighter: 2000, SmallCargo: 100},
+		RecyclerCargo: 20000,
+	}
+	plan, err := PlanMoonshot(params)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), plan.Chance)
+	assert.Equal(t, ShipsInfos{LightFighter: 1667}, plan.Sacrificed)
+	assert.Equal(t, Resources{Metal: 1500300, Crystal: 500100}, plan.Debris)
+	assert.Equal(t, int64(101), plan.Recyclers)
+
+	// The next ships complete the debris of the first ones
+	params.Ships = []ID{SmallCargoID, LightFighterID}
+	params.Chance = 5
+	params.DebrisFactor = 0.5
+	plan, _ = PlanMoonshot(params)
+	assert.Equal(t, int64(5), plan.Chance)
+	assert.Equal(t, ShipsInfos{SmallCargo: 100, LightFighter: 150}, plan.Sacrificed)
+
+	params.Available = ShipsInfos{LightFighter: 100}
+	plan, err = PlanMoonshot(params)
+	assert.EqualError(t, err, "not enough ships for a moon chance of 5%, the ships give 2%")
+	assert.Equal(t, int64(2), plan.Chance)
+
+	params.Ships = []ID{RecyclerID}
+	_, err = PlanMoonshot(params)
+	assert.Error(t, err)
+
+	params.Ships = nil
+	params.Chance = 21
+	_, err = PlanMoonshot(params)
+	assert.Error(t, err)
+
+	params.Chance = 1
+	params.Destination = dest.Debris()
+	_, err = PlanMoonshot(params)
+	assert.Error(t, err)
+}
+
+func TestDefaultMoonshotShips(t *testing.T) {
+	ships := defaultMoonshotShips()
+	assert.Equal(t, EspionageProbeID, ships[0])
+	assert.Equal(t, DeathstarID, ships[len(ships)-1])
+	assert.NotContains(t, ships, RecyclerID)
+	assert.NotContains(t, ships, SolarSatelliteID)
+	assert.NotContains(t, ships, CrawlerID)
+}
@@ -0,0 +1,187 @@
+package ogame
+
+import (
+	"math"
+	"regexp"
+)
+
+// Officers bonuses
+const (
+	GeologistProductionBonus       = 0.1  // Mines production
+	EngineerEnergyBonus            = 0.1  // Energy production
+	CommandingStaffProductionBonus = 0.02 // Mines production, when all the officers are hired
+	CommandingStaffEnergyBonus     = 0.02 // Energy production, when all the officers are hired
+	CollectorProductionBonus       = 0.25 // Mines production of the collector class
+	CollectorEnergyBonus           = 0.1  // Energy production of the collector class
+	AdmiralFleetSlots              = 2
+	CommandingStaffFleetSlots      = 1
+	GeneralFleetSlots              = 2
+)
+
+// Officers hired by the player, the commanding staff is hired when all of them are
+type Officers struct {
+	Commander  bool
+	Admiral    bool
+	Engineer   bool
+	Geologist  bool
+	Technocrat bool
+}
+
+// IsCommandingStaff returns true if all the officers are hired
+func (o Officers) IsCommandingStaff() bool {
+	return o.Commander && o.Admiral && o.Engineer && o.Geologist && o.Technocrat
+}
+
+// MinesProductionBonus returns the bonus of the mines production given by the officers and the class.
+// The crawlers and the boosters are not included.
+func MinesProductionBonus(officers Officers, class CharacterClass) float64 {
+	bonus := 0.0
+	if officers.Geologist {
+		bonus += GeologistProductionBonus
+	}
+	if officers.IsCommandingStaff() {
+		bonus += CommandingStaffProductionBonus
+	}
+	if class.IsCollector() {
+		bonus += CollectorProductionBonus
+	}
+	return bonus
+}
+
+// EnergyProductionBonus returns the bonus of the energy production given by the officers and the class
+func EnergyProductionBonus(officers Officers, class CharacterClass) float64 {
+	bonus := 0.0
+	if officers.Engineer {
+		bonus += EngineerEnergyBonus
+	}
+	if officers.IsCommandingStaff() {
+		bonus += CommandingStaffEnergyBonus
+	}
+	if class.IsCollector() {
+		bonus += CollectorEnergyBonus
+	}
+	return bonus
+}
+
+// MaxFleetSlots returns the fleet slots of the player, expeditions included
+func MaxFleetSlots(computerTechnology int64, officers Officers, class CharacterClass) int64 {
+	slots := computerTechnology + 1
+	if officers.Admiral {
+		slots += AdmiralFleetSlots
+	}
+	if officers.IsCommandingStaff() {
+		slots += CommandingStaffFleetSlots
+	}
+	if class.IsGeneral() {
+		slots += GeneralFleetSlots
+	}
+	return slots
+}
+
+// BoostersBonus bonus of the mines production given by the active booster items of a planet
+type BoostersBonus struct {
+	Metal     float64
+	Crystal   float64
+	Deuterium float64
+}
+
+// booster resource and bonus of a booster item
+type booster struct {
+	resource ID // MetalMineID, CrystalMineID or DeuteriumSynthesizerID
+	bonus    float64
+}
+
+// boosterRefs known refs of the booster items, each booster has one ref by duration.
+// They are the booster refs of the itemNames map of the game pages (see samples/v7/overview.html).
+var boosterRefs = map[string]booster{
+	"de922af379061263a56d7204d1c395cefcfb7d75": {MetalMineID, 0.1},            // Bronze metal booster
+	"b956c46faa8e4e5d8775701c69dbfbf53309b279": {MetalMineID, 0.1},            // Bronze metal booster
+	"ba85cc2b8a5d986bbfba6954e2164ef71af95d4a": {MetalMineID, 0.2},            // Silver metal booster
+	"05294270032e5dc968672425ab5611998c409166": {MetalMineID, 0.3},            // Gold metal booster
+	"090a969b05d1b5dc458a6b1080da7ba08b84ec7f": {CrystalMineID, 0.1},          // Bronze crystal booster
+	"3c9f85221807b8d593fa5276cdf7af9913c4a35d": {CrystalMineID, 0.1},          // Bronze crystal booster
+	"422db99aac4ec594d483d8ef7faadc5d40d6f7d3": {CrystalMineID, 0.2},          // Silver crystal booster
+	"118d34e685b5d1472267696d1010a393a59aed03": {CrystalMineID, 0.3},          // Gold crystal booster
+	"d9fa5f359e80ff4f4c97545d07c66dbadab1d1be": {DeuteriumSynthesizerID, 0.1}, // Bronze deuterium booster
+	"e254352ac599de4dd1f20f0719df0a070c623ca8": {DeuteriumSynthesizerID, 0.1}, // Bronze deuterium booster
+	"e4b78acddfa6fd0234bcb814b676271898b0dbb3": {DeuteriumSynthesizerID, 0.2}, // Silver deuterium booster
+	"5560a1580a0330e8aadf05cb5bfe6bc3200406e2": {DeuteriumSynthesizerID, 0.3}, // Gold deuterium booster
+}
+
+var boosterNameRgx = regexp.MustCompile(`^(Bronze|Silver|Gold|Platinum) (Metal|Crystal|Deuterium) Booster$`)
+
+// parseBooster returns the resource and bonus of a booster item, the unknown refs are recognized by their english name
+func parseBooster(item ActiveItem) (booster, bool) {
+	if b, ok := boosterRefs[item.Ref]; ok {
+		return b, true
+	}
+	m := boosterNameRgx.FindStringSubmatch(item.Name)
+	if len(m) != 3 {
+		return booster{}, false
+	}
+	bonus := map[string]float64{"Bronze": 0.1, "Silver": 0.2, "Gold": 0.3, "Platinum": 0.4}[m[1]]
+	resource := map[string]ID{"Metal": MetalMineID, "Crystal": CrystalMineID, "Deuterium": DeuteriumSynthesizerID}[m[2]]
+	return booster{resource, bonus}, true
+}
+
+// GetBoostersBonus returns the production bonus of the active booster items of a planet.
+// Only the best booster of each resource counts, activating another one replaces it.
+func GetBoostersBonus(items []ActiveItem) BoostersBonus {
+	var res BoostersBonus
+	for _, item := range items {
+		b, ok := parseBooster(item)
+		if !ok {
+			continue
+		}
+		switch b.resource {
+		case MetalMineID:
+			res.Metal = math.Max(res.Metal, b.bonus)
+		case CrystalMineID:
+			res.Crystal = math.Max(res.Crystal, b.bonus)
+		case DeuteriumSynthesizerID:
+			res.Deuterium = math.Max(res.Deuterium, b.bonus)
+		}
+	}
+	return res
+}
+
+func (b *OGame) getOfficers() Officers {
+	return Officers{
+		Commander:  b.hasCommander,
+		Admiral:    b.hasAdmiral,
+		Engineer:   b.hasEngineer,
+		Geologist:  b.hasGeologist,
+		Technocrat: b.hasTechnocrat,
+	}
+}
+
+// GetCachedOfficers returns the cached officers
+func (b *OGame) GetCachedOfficers() Officers {
+	return b.getOfficers()
+}
+
+// productionInputs production inputs of a planet with the officers and the class of the player
+func (b *OGame) productionInputs(buildings ResourcesBuildings, settings ResourceSettings, researches Researches,
+	temp Temperature, activeItems []ActiveItem) ProductionAuditInputs {
+	officers := b.getOfficers()
+	return ProductionAuditInputs{
+		Buildings:       buildings,
+		Settings:        settings,
+		Researches:      researches,
+		Temperature:     temp,
+		UniverseSpeed:   b.serverData.Speed,
+		Geologist:       officers.Geologist,
+		Engineer:        officers.Engineer,
+		CommandingStaff: officers.IsCommandingStaff(),
+		Collector:       b.isCollector(),
+		ActiveItems:     activeItems,
+	}
+}
+
+// productionWithBonuses hourly production including the officers, class, crawlers and boosters bonuses.
+// Energy is the energy balance, like getProductions.
+func productionWithBonuses(in ProductionAuditInputs) Resources {
+	prod, energyUse := computeAuditedProduction(in)
+	prod.Energy -= energyUse
+	return prod
+}
@@ -0,0 +1,71 @@
+package ogame
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var allOfficers = Officers{Commander: true, Admiral: true, Engineer: true, Geologist: true, Technocrat: true}
+
+func TestOfficers_IsCommandingStaff(t *testing.T) {
+	assert.True(t, allOfficers.IsCommandingStaff())
+	officers := allOfficers
+	officers.Commander = false
+	assert.False(t, officers.IsCommandingStaff())
+}
+
+func TestMinesProductionBonus(t *testing.T) {
+	assert.Equal(t, 0.0, MinesProductionBonus(Officers{}, NoClass))
+	assert.Equal(t, 0.1, MinesProductionBonus(Officers{Geologist: true}, NoClass))
+	assert.InDelta(t, 0.12, MinesProductionBonus(allOfficers, NoClass), 1e-9)
+	assert.InDelta(t, 0.37, MinesProductionBonus(allOfficers, Collector), 1e-9)
+}
+
+func TestEnergyProductionBonus(t *testing.T) {
+	assert.Equal(t, 0.0, EnergyProductionBonus(Officers{Geologist: true}, General))
+	assert.Equal(t, 0.1, EnergyProductionBonus(Officers{Engineer: true}, NoClass))
+	assert.InDelta(t, 0.22, EnergyProductionBonus(allOfficers, Collector), 1e-9)
+}
+
+func TestMaxFleetSlots(t *testing.T) {
+	assert.Equal(t, int64(11), MaxFleetSlots(10, Officers{}, NoClass))
+	assert.Equal(t, int64(13), MaxFleetSlots(10, Officers{Admiral: true}, NoClass))
+	assert.Equal(t, int64(16), MaxFleetSlots(10, allOfficers, General))
+}
+
+func TestGetBoostersBonus(t *testing.T) {
+	items := []ActiveItem{
+		{Ref: "ba85cc2b8a5d986bbfba6954e2164ef71af95d4a", Name: "Silver Metal Booster"},
+		{Ref: "de922af379061263a56d7204d1c395cefcfb7d75"}, // Bronze metal booster, the best one counts
+		{Ref: "5560a1580a0330e8aadf05cb5bfe6bc3200406e2"},
+		{Ref: "unknown", Name: "Platinum Crystal Booster"},
+		{Ref: "unknown", Name: "KRAKEN"},
+	}
+	assert.Equal(t, BoostersBonus{Metal: 0.2, Crystal: 0.4, Deuterium: 0.3}, GetBoostersBonus(items))
+	assert.Equal(t, BoostersBonus{}, GetBoostersBonus(nil))
+}
+
+func TestBoosterRefs(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7/overview.html")
+	m := regexp.MustCompile(`itemNames = ({[^}]*})`).FindSubmatch(pageHTMLBytes)
+	assert.Equal(t, 2, len(m))
+	var itemNames map[string]string
+	assert.NoError(t, json.Unmarshal(m[1], &itemNames))
+	for ref, expected := range boosterRefs {
+		name, ok := itemNames[ref]
+		assert.True(t, ok, ref)
+		b, _ := parseBooster(ActiveItem{Name: name})
+		assert.Equal(t, expected, b, name)
+	}
+}
+
+func TestGetCachedOfficers(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.hasAdmiral = true
+	b.hasGeologist = true
+	assert.Equal(t, Officers{Admiral: true, Geologist: true}, b.GetCachedOfficers())
+}
@@ -18,7 +18,7 @@ import (
 	"io/ioutil"
 	"log"
 	"math"
-	"mime/multipart"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -36,8 +36,6 @@ import (
 	version "github.com/hashicorp/go-version"
 	cookiejar "github.com/orirawlings/persistent-cookiejar"
 	"github.com/pkg/errors"
-	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
 	lua "github.com/yuin/gopher-lua"
 	"golang.org/x/net/proxy"
 	"golang.org/x/net/websocket"
@@ -47,63 +45,147 @@ import (
 // multiple goroutines (thread-safe)
 type OGame struct {
 	sync.Mutex
-	isEnabledAtom         int32  // atomic, prevent auto re login if we manually logged out
-	isLoggedInAtom        int32  // atomic, prevent auto re login if we manually logged out
-	isConnectedAtom       int32  // atomic, either or not communication between the bot and OGame is possible
-	lockedAtom            int32  // atomic, bot state locked/unlocked
-	chatConnectedAtom     int32  // atomic, either or not the chat is connected
-	state                 string // keep name of the function that currently lock the bot
-	ctx                   context.Context
-	cancelCtx             context.CancelFunc
-	stateChangeCallbacks  []func(locked bool, actor string)
-	quiet                 bool
-	Player                UserInfos
-	CachedPreferences     Preferences
-	isVacationModeEnabled bool
-	researches            *Researches
-	planets               []Planet
-	planetsMu             sync.RWMutex
-	ajaxChatToken         string
-	Universe              string
-	Username              string
-	password              string
-	otpSecret             string
-	bearerToken           string
-	language              string
-	playerID              int64
-	lobby                 string
-	ogameSession          string
-	sessionChatCounter    int64
-	server                Server
-	serverData            ServerData
-	location              *time.Location
-	serverURL             string
-	Client                *OGameClient
-	logger                *log.Logger
-	chatCallbacks         []func(msg ChatMsg)
-	wsCallbacks           map[string]func(msg []byte)
-	auctioneerCallbacks   []func(interface{})
-	interceptorCallbacks  []func(method, url string, params, payload url.Values, pageHTML []byte)
-	closeChatCh           chan struct{}
-	chatRetry             *ExponentialBackoff
-	ws                    *websocket.Conn
-	tasks                 priorityQueue
-	tasksLock             sync.Mutex
-	tasksPushCh           chan *item
-	tasksPopCh            chan struct{}
-	loginWrapper          func(func() (bool, error)) error
-	loginProxyTransport   http.RoundTripper
-	bytesUploaded         int64
-	bytesDownloaded       int64
-	extractor             Extractor
-	apiNewHostname        string
-	characterClass        CharacterClass
-	hasCommander          bool
-	hasAdmiral            bool
-	hasEngineer           bool
-	hasGeologist          bool
-	hasTechnocrat         bool
-	captchaCallback       CaptchaCallback
+	isEnabledAtom          int32  // atomic, prevent auto re login if we manually logged out
+	isLoggedInAtom         int32  // atomic, prevent auto re login if we manually logged out
+	isConnectedAtom        int32  // atomic, either or not communication between the bot and OGame is possible
+	lockedAtom             int32  // atomic, bot state locked/unlocked
+	chatConnectedAtom      int32  // atomic, either or not the chat is connected
+	state                  string // keep name of the function that currently lock the bot
+	ctx                    context.Context
+	cancelCtx              context.CancelFunc
+	stateChangeCallbacks   []func(locked bool, actor string)
+	sessionLostCallbacks   []func(attempts int, err error)
+	accountBannedCallbacks []func(err *AccountBanError)
+	quiet                  bool
+	Player                 UserInfos
+	CachedPreferences      Preferences
+	isVacationModeEnabled  bool
+	researches             *Researches
+	planets                []Planet
+	planetsMu              sync.RWMutex
+	ajaxChatToken          string
+	Universe               string
+	Username               string
+	password               string
+	otpSecret              string
+	bearerToken            string
+	language               string
+	playerID               int64
+	lobby                  string
+	ogameSession           string
+	sessionChatCounter     int64
+	server                 Server
+	serverData             ServerData
+	location               *time.Location
+	serverURL              string
+	Client                 *OGameClient
+	logger                 *log.Logger
+	chatCallbacks          []func(msg ChatMsg)
+	wsCallbacks            map[string]func(msg []byte)
+	auctioneerCallbacks    []func(interface{})
+	interceptorCallbacks   []func(method, url string, params, payload url.Values, pageHTML []byte)
+	closeChatCh            chan struct{}
+	chatRetry              *ExponentialBackoff
+	ws                     *websocket.Conn
+	tasks                  priorityQueue
+	tasksLock              sync.Mutex
+	tasksPushCh            chan *item
+	tasksPopCh             chan struct{}
+	loginWrapper           func(func() (bool, error)) error
+	loginProxyTransport    http.RoundTripper
+	bytesUploaded          int64
+	bytesDownloaded        int64
+	extractor              Extractor
+	apiNewHostname         string
+	characterClass         CharacterClass
+	hasCommander           bool
+	hasAdmiral             bool
+	hasEngineer            bool
+	hasGeologist           bool
+	hasTechnocrat          bool
+	captchaCallback        CaptchaCallback
+	otpCallback            OTPCallback
+	otpCode                string
+	otpMu                  sync.Mutex
+	safeModeAtom           int32 // atomic, either or not requests are paused after too many consecutive failed tasks
+	consecutiveFailedTasks int32 // atomic, number of consecutive failed tasks
+	safeModeThreshold      int32 // 0 if safe mode is disabled
+	safeModeCallbacks      []func(err error)
+	aliases                map[string]CelestialID
+	aliasesMu              sync.RWMutex
+	localizations          map[string]map[ID]string // object names by language
+	localizationsMu        sync.Mutex
+	humanizer              *humanizer
+	humanizerMu            sync.RWMutex
+	productionAuditAtom    int32 // atomic, 1 if production audit is enabled
+	eventSubscriptions     []eventSubscription
+	eventSubscriptionID    int64
+	eventCallbacksMu       sync.RWMutex
+	seenAttacks            map[int64]time.Time
+	proxyPool              *ProxyPool
+	tlsFingerprint         string
+	migratedCallbacks      []func(UniverseMigration)
+	migratedCallbacksMu    sync.RWMutex
+	friendlyPlayers        map[int64]struct{}
+	friendlyPlayersMu      sync.RWMutex
+	planetsCachedAt        time.Time
+	researchesCachedAt     time.Time
+	activeItems            map[CelestialID][]CelestialActiveItem // Items active on the celestials, read from their overview page
+	activeItemsMu          sync.RWMutex
+	serverDataCachedAt     time.Time
+	empireCache            map[int64]cachedEmpireJSON
+	empireSnapshot         *EmpireSnapshot
+	empireCacheMu          sync.Mutex
+	auditLog               *AuditLog
+	auditLogMu             sync.RWMutex
+	taskHistory            *TaskHistory
+	currentTask            *TaskRecord
+	currentTaskID          int64
+	currentTaskErr         error // Last error of the current task
+	taskHistoryMu          sync.Mutex
+	taskSeq                int64 // atomic
+	taskSubscriptions      []taskSubscription
+	taskSubscriptionID     int64
+	taskSubscriptionsMu    sync.RWMutex
+	circuitBreaker         *circuitBreaker
+	retryPolicy            RetryPolicy
+	retryPolicyMu          sync.RWMutex
+	phalanxWatches         map[int64]*phalanxWatch
+	phalanxWatchesMu       sync.RWMutex
+	phalanxWatchNextID     int64
+	jumpGateLastJump       map[MoonID]time.Time
+	jumpGateReadyAt        map[MoonID]time.Time
+	jumpGateMu             sync.Mutex
+	escapeRules            map[CelestialID]EscapeRule
+	escapedAttacks         map[int64]time.Time
+	escapeRunning          bool
+	escapeStop             chan struct{}
+	escapeMu               sync.Mutex
+	marketplaceHistory     *MarketplacePriceHistory
+	marketplaceHistoryMu   sync.RWMutex
+	galaxyCache            *GalaxyCache
+	galaxyCacheMu          sync.RWMutex
+	watchlist              map[int64]*WatchedPlayer
+	watchlistMu            sync.Mutex
+	watchlistFilename      string
+	bearerTokenExpiresAt   time.Time
+	bearerTokenMu          sync.RWMutex
+	browserLogin           BrowserLogin
+	browserLoginMu         sync.RWMutex
+	requestTimeout         time.Duration
+	taskDeadline           time.Duration
+	task                   *taskContext
+	timeoutsMu             sync.Mutex
+	cookieStore            *encryptedCookieStore
+	workflows              *WorkflowStore
+	workflowsMu            sync.RWMutex
+	buildTemplates         map[string][]BuildTemplateItem
+	buildTemplatesMu       sync.RWMutex
+	expeditionLoops        map[int64]*expeditionLoop
+	expeditionLoopsMu      sync.RWMutex
+	expeditionLoopNextID   int64
+	fleetTemplates         map[string]FleetTemplate
+	fleetTemplatesMu       sync.RWMutex
 }
 
 // CaptchaCallback ...
@@ -155,7 +237,9 @@ const defaultUserAgent = "" +
 type options struct {
 	SkipInterceptor bool
 	SkipRetry       bool
-	ChangePlanet    CelestialID // cp parameter
+	SkipCache       bool
+	ChangePlanet    CelestialID   // cp parameter
+	Timeout         time.Duration // Request timeout, the bot request timeout if 0
 }
 
 // Option functions to be passed to public interface to change behaviors
@@ -171,6 +255,11 @@ func SkipRetry(opt *options) {
 	opt.SkipRetry = true
 }
 
+// SkipCache option to skip the galaxy cache and always fetch the system from the game
+func SkipCache(opt *options) {
+	opt.SkipCache = true
+}
+
 // ChangePlanet set the cp parameter
 func ChangePlanet(celestialID CelestialID) Option {
 	return func(opt *options) {
@@ -178,6 +267,13 @@ func ChangePlanet(celestialID CelestialID) Option {
 	}
 }
 
+// WithTimeout set the timeout of the requests to the OGame server, within the http client timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(opt *options) {
+		opt.Timeout = timeout
+	}
+}
+
 // CelestialID represent either a PlanetID or a MoonID
 type CelestialID int64
 
@@ -187,6 +283,7 @@ type Params struct {
 	Password        string
 	BearerToken     string // Gameforge auth bearer token
 	OTPSecret       string
+	OTPCode         string // One-time code of the authenticator app, used by the first login only
 	Universe        string
 	Lang            string
 	PlayerID        int64
@@ -202,6 +299,56 @@ type Params struct {
 	CookiesFilename string
 	Client          *OGameClient
 	CaptchaCallback CaptchaCallback
+	// OTPCallback asks for a one-time code when the account has the two-factor authentication and no OTPSecret is set
+	OTPCallback OTPCallback
+	// SafeModeThreshold number of consecutive failed tasks before entering safe mode (eg: DefaultSafeModeThreshold).
+	// Safe mode is disabled if 0 or negative.
+	SafeModeThreshold int32
+	// Humanizer optional human behavior simulation (random delays, decoy pages, quiet hours)
+	Humanizer *HumanizerConfig
+	// ProxyPool list of proxies with failover, used instead of Proxy when set
+	ProxyPool *ProxyPool
+	// TLSFingerprint TLS ClientHello to mimic (chrome, firefox, ios, randomized), golang default if empty
+	TLSFingerprint string
+	// TransportWrapper optional function wrapping the http transport (logging, caching, mTLS, request signing...)
+	TransportWrapper TransportWrapper
+	// AuditLogFilename file where the mutating actions are appended (JSON lines), in memory only if empty
+	AuditLogFilename string
+	// MarketplaceHistoryFilename file where the marketplace offers, listings and trades are appended (JSON lines), in memory only if empty
+	MarketplaceHistoryFilename string
+	// TaskHistoryFilename file where the tasks that held the bot lock are appended (JSON lines), in memory only if empty
+	TaskHistoryFilename string
+	// TaskHistoryMaxAge time the tasks are kept in the history, DefaultTaskHistoryMaxAge if 0
+	TaskHistoryMaxAge time.Duration
+	// TaskHistoryMaxRecords tasks kept in the history, DefaultTaskHistoryMaxRecords if 0
+	TaskHistoryMaxRecords int
+	// GalaxyCacheTTL time the systems fetched by GalaxyInfos are served from the cache, the cache is disabled if 0
+	GalaxyCacheTTL time.Duration
+	// GalaxyCacheFilename file where the galaxy cache is persisted, in memory only if empty
+	GalaxyCacheFilename string
+	// WorkflowsFilename file where the colonize workflows are saved, in memory only if empty
+	WorkflowsFilename string
+	// WatchlistFilename file where the players watched by the military score alerts are saved, in memory only if empty
+	WatchlistFilename string
+	// CircuitBreakerThreshold consecutive 5xx/timeouts before requests fail fast with ErrServerUnavailable.
+	// 0 uses DefaultCircuitBreakerThreshold, a negative value disables the circuit breaker.
+	CircuitBreakerThreshold int
+	// CircuitBreakerCooldown time before probing the server again, DefaultCircuitBreakerCooldown if 0
+	CircuitBreakerCooldown time.Duration
+	// RetryPolicy how transient failures (timeouts, 502/503...) are retried, DefaultRetryPolicy if nil
+	RetryPolicy *RetryPolicy
+	// BrowserLogin optional headless browser login used when the HTTP login flow fails
+	BrowserLogin BrowserLogin
+	// RequestTimeout time an HTTP request to the OGame server can take, within the http client timeout (30s)
+	RequestTimeout time.Duration
+	// TaskDeadline time a task can hold the bot lock and keep sending requests, no deadline if 0
+	TaskDeadline time.Duration
+	// CookiesSecretKey key the cookies file (bearer token included) is encrypted with, plain-text if empty
+	CookiesSecretKey string
+	// RateLimit sustained requests per second sent to OGame by all the tasks, not limited if 0
+	RateLimit float64
+	// RateLimitBurst requests that can be sent at once above the rate limit
+	RateLimitBurst int64
 }
 
 // Lobby constants
@@ -260,7 +407,7 @@ func Register(lobby, email, password, challengeID, lang string, client *http.Cli
 		if gfChallengeID != "" {
 			parts := strings.Split(gfChallengeID, ";")
 			challengeID := parts[0]
-			return errors.New("captcha required, " + challengeID)
+			return newCaptchaRequiredError(challengeID)
 		}
 	}
 	by, _, err := readBody(resp)
@@ -434,11 +581,90 @@ func New(universe, username, password, lang string) (*OGame, error) {
 
 // NewWithParams create a new OGame instance with full control over the possible parameters
 func NewWithParams(params Params) (*OGame, error) {
+	var cookieStore *encryptedCookieStore
+	if params.CookiesSecretKey != "" && params.Client == nil {
+		var err error
+		if cookieStore, err = newEncryptedCookieStore(params.CookiesFilename, params.CookiesSecretKey); err != nil {
+			return nil, err
+		}
+		if params.Client, err = newEncryptedCookiesClient(cookieStore); err != nil {
+			return nil, err
+		}
+	}
 	b, err := NewNoLogin(params.Username, params.Password, params.OTPSecret, params.BearerToken, params.Universe, params.Lang, params.CookiesFilename, params.PlayerID, params.Client)
 	if err != nil {
 		return nil, err
 	}
+	b.cookieStore = cookieStore
 	b.captchaCallback = params.CaptchaCallback
+	b.otpCallback = params.OTPCallback
+	if params.OTPCode != "" {
+		b.SetOTPCode(params.OTPCode)
+	}
+	b.SetBrowserLogin(params.BrowserLogin)
+	if params.TransportWrapper != nil {
+		b.Client.SetTransportWrapper(params.TransportWrapper)
+	}
+	if params.SafeModeThreshold > 0 {
+		b.safeModeThreshold = params.SafeModeThreshold
+	}
+	if params.CircuitBreakerThreshold != 0 || params.CircuitBreakerCooldown != 0 {
+		threshold := params.CircuitBreakerThreshold
+		if threshold == 0 {
+			threshold = DefaultCircuitBreakerThreshold
+		}
+		b.circuitBreaker = newCircuitBreaker(threshold, params.CircuitBreakerCooldown)
+		b.circuitBreaker.onChange = b.onCircuitBreakerChange
+	}
+	if params.RetryPolicy != nil {
+		b.SetRetryPolicy(*params.RetryPolicy)
+	}
+	b.SetRequestTimeout(params.RequestTimeout)
+	b.SetTaskDeadline(params.TaskDeadline)
+	if params.RateLimit > 0 {
+		b.SetRateLimit(params.RateLimit, params.RateLimitBurst)
+	}
+	b.SetHumanizer(params.Humanizer)
+	if params.AuditLogFilename != "" {
+		auditLog, err := NewAuditLog(params.AuditLogFilename)
+		if err != nil {
+			return nil, err
+		}
+		b.SetAuditLog(auditLog)
+	}
+	if params.TaskHistoryFilename != "" || params.TaskHistoryMaxAge > 0 || params.TaskHistoryMaxRecords > 0 {
+		taskHistory, err := NewTaskHistory(params.TaskHistoryFilename, params.TaskHistoryMaxAge, params.TaskHistoryMaxRecords)
+		if err != nil {
+			return nil, err
+		}
+		b.SetTaskHistory(taskHistory)
+	}
+	if params.MarketplaceHistoryFilename != "" {
+		history, err := NewMarketplacePriceHistory(params.MarketplaceHistoryFilename)
+		if err != nil {
+			return nil, err
+		}
+		b.SetMarketplacePriceHistory(history)
+	}
+	if params.GalaxyCacheTTL > 0 {
+		galaxyCache, err := NewGalaxyCache(params.GalaxyCacheTTL, params.GalaxyCacheFilename)
+		if err != nil {
+			return nil, err
+		}
+		b.SetGalaxyCache(galaxyCache)
+	}
+	if params.WorkflowsFilename != "" {
+		workflows, err := NewWorkflowStore(params.WorkflowsFilename)
+		if err != nil {
+			return nil, err
+		}
+		b.SetWorkflowStore(workflows)
+	}
+	if params.WatchlistFilename != "" {
+		if err := b.LoadWatchlist(params.WatchlistFilename); err != nil {
+			return nil, err
+		}
+	}
 	b.setOGameLobby(params.Lobby)
 	b.apiNewHostname = params.APINewHostname
 	if params.Proxy != "" {
@@ -446,6 +672,14 @@ func NewWithParams(params Params) (*OGame, error) {
 			return nil, err
 		}
 	}
+	if params.ProxyPool != nil {
+		b.SetProxyPool(params.ProxyPool, params.ProxyLoginOnly)
+	}
+	if params.TLSFingerprint != "" {
+		if err := b.SetTLSFingerprint(params.TLSFingerprint); err != nil {
+			return nil, err
+		}
+	}
 	if params.AutoLogin {
 		if params.BearerToken != "" {
 			if _, err := b.LoginWithBearerToken(params.BearerToken); err != nil {
@@ -473,6 +707,13 @@ func NewNoLogin(username, password, otpSecret, bearerToken, universe, lang, cook
 	b.setOGameLobby(Lobby)
 	b.language = lang
 	b.playerID = playerID
+	b.auditLog = newAuditLog()
+	b.taskHistory = newTaskHistory(DefaultTaskHistoryMaxAge, DefaultTaskHistoryMaxRecords)
+	b.marketplaceHistory = newMarketplacePriceHistory()
+	b.workflows = newWorkflowStore()
+	b.circuitBreaker = newCircuitBreaker(DefaultCircuitBreakerThreshold, DefaultCircuitBreakerCooldown)
+	b.retryPolicy = DefaultRetryPolicy
+	b.circuitBreaker.onChange = b.onCircuitBreakerChange
 
 	b.extractor = NewExtractorV71()
 
@@ -549,11 +790,13 @@ type account struct {
 		Language string
 		Number   int64
 	}
-	ID         int64 // player ID
-	Name       string
-	LastPlayed string
-	Blocked    bool
-	Details    []struct {
+	ID           int64 // player ID
+	Name         string
+	LastPlayed   string
+	Blocked      bool
+	BannedUntil  *string
+	BannedReason *string
+	Details      []struct {
 		Type  string
 		Title string
 		Value interface{} // Can be string or int
@@ -809,8 +1052,13 @@ type ServerData struct {
 
 // gets the server data from xml api
 func (b *OGame) getServerData() (ServerData, error) {
+	return b.getServerDataOf(b.server.Number, b.server.Language)
+}
+
+// gets the server data of any server from xml api
+func (b *OGame) getServerDataOf(number int64, lang string) (ServerData, error) {
 	var serverData ServerData
-	req, err := http.NewRequest("GET", "https://s"+strconv.FormatInt(b.server.Number, 10)+"-"+b.server.Language+".ogame.gameforge.com/api/serverData.xml", nil)
+	req, err := http.NewRequest("GET", "https://s"+strconv.FormatInt(number, 10)+"-"+lang+".ogame.gameforge.com/api/serverData.xml", nil)
 	if err != nil {
 		return serverData, err
 	}
@@ -846,7 +1094,7 @@ func (b *OGame) loginWithBearerToken(token string) (bool, error) {
 	if err2.Is(err, context.Canceled) {
 		return false, err
 	}
-	if err == ErrAccountBlocked {
+	if _, ok := err.(*AccountBanError); ok {
 		return false, err
 	}
 	if err != nil {
@@ -882,7 +1130,7 @@ func (b *OGame) loginWithBearerToken(token string) (bool, error) {
 			if err := b.loginPart3(userAccount, pageHTML); err != nil {
 				return false, err
 			}
-			if err := b.Client.Jar.(*cookiejar.Jar).Save(); err != nil {
+			if err := b.saveCookies(); err != nil {
 				return false, err
 			}
 			for _, fn := range b.interceptorCallbacks {
@@ -901,18 +1149,7 @@ func (b *OGame) loginWithBearerToken(token string) (bool, error) {
 
 // Return either or not the bot logged in using the existing cookies.
 func (b *OGame) loginWithExistingCookies() (bool, error) {
-	token := ""
-	if b.bearerToken != "" {
-		token = b.bearerToken
-	} else {
-		cookies := b.Client.Jar.(*cookiejar.Jar).AllCookies()
-		for _, c := range cookies {
-			if c.Name == gfTokenCookieName {
-				token = c.Value
-				break
-			}
-		}
-	}
+	token, _ := b.GetBearerToken()
 	return b.loginWithBearerToken(token)
 }
 
@@ -1035,40 +1272,6 @@ func TelegramSolver(tgBotToken string, tgChatID int64) CaptchaCallback {
 	}
 }
 
-// NinjaSolver direct integration of ogame.ninja captcha auto solver service
-func NinjaSolver(apiKey string) CaptchaCallback {
-	return func(question, icons []byte) (int64, error) {
-		body := &bytes.Buffer{}
-		writer := multipart.NewWriter(body)
-		part, _ := writer.CreateFormFile("question", "question.png")
-		_, _ = io.Copy(part, bytes.NewReader(question))
-		part1, _ := writer.CreateFormFile("icons", "icons.png")
-		_, _ = io.Copy(part1, bytes.NewReader(icons))
-		_ = writer.Close()
-
-		req, _ := http.NewRequest(http.MethodPost, "https://www.ogame.ninja/api/v1/captcha/solve", body)
-		req.Header.Add("Content-Type", writer.FormDataContentType())
-		req.Header.Set("NJA_API_KEY", apiKey)
-		resp, _ := http.DefaultClient.Do(req)
-		defer resp.Body.Close()
-		if resp.StatusCode != 200 {
-			by, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				return 0, errors.New("failed to auto solve captcha: " + err.Error())
-			}
-			return 0, errors.New("failed to auto solve captcha: " + string(by))
-		}
-		by, _ := ioutil.ReadAll(resp.Body)
-		var answerJson struct {
-			Answer int64 `json:"answer"`
-		}
-		if err := json.Unmarshal(by, &answerJson); err != nil {
-			return 0, errors.New("failed to auto solve captcha: " + err.Error())
-		}
-		return answerJson.Answer, nil
-	}
-}
-
 type postSessionsResponse struct {
 	Token                     string `json:"token"`
 	IsPlatformLogin           bool   `json:"isPlatformLogin"`
@@ -1089,6 +1292,7 @@ func postSessions(b *OGame, gameEnvironmentID, platformGameID, username, passwor
 
 	challengeID := ""
 	tried := false
+	otpAsked := false
 	for {
 		var out postSessionsResponse
 		payload := url.Values{
@@ -1108,16 +1312,11 @@ func postSessions(b *OGame, gameEnvironmentID, platformGameID, username, passwor
 			return out, err
 		}
 
-		if otpSecret != "" {
-			passcode, err := totp.GenerateCodeCustom(otpSecret, time.Now(), totp.ValidateOpts{
-				Period:    30,
-				Skew:      1,
-				Digits:    otp.DigitsSix,
-				Algorithm: otp.AlgorithmSHA1,
-			})
-			if err != nil {
-				return out, err
-			}
+		passcode, err := b.takeOTPCode(otpSecret)
+		if err != nil {
+			return out, err
+		}
+		if passcode != "" {
 			req.Header.Add("tnt-2fa-code", passcode)
 			req.Header.Add("tnt-installation-id", "")
 		}
@@ -1142,7 +1341,7 @@ func postSessions(b *OGame, gameEnvironmentID, platformGameID, username, passwor
 				challengeID = parts[0]
 
 				if tried {
-					return out, errors.New("captcha required, " + challengeID)
+					return out, newCaptchaRequiredError(challengeID)
 				}
 				tried = true
 
@@ -1161,7 +1360,7 @@ func postSessions(b *OGame, gameEnvironmentID, platformGameID, username, passwor
 					continue
 				}
 
-				return out, errors.New("captcha required, " + challengeID)
+				return out, newCaptchaRequiredError(challengeID)
 			}
 		}
 
@@ -1171,13 +1370,26 @@ func postSessions(b *OGame, gameEnvironmentID, platformGameID, username, passwor
 
 		by, _, err := readBody(resp)
 		if resp.StatusCode != 201 {
-			if string(by) == `{"reason":"OTP_REQUIRED"}` {
+			switch sessionsErrorReason(by) {
+			case otpRequiredReason:
+				// Complete the second factor with a code given by the callback
+				if passcode == "" && !otpAsked && b.otpCallback != nil {
+					otpAsked = true
+					code, err := b.otpCallback()
+					if err != nil {
+						return out, errors.New("failed to get otp code: " + err.Error())
+					}
+					b.SetOTPCode(code)
+					continue
+				}
 				return out, ErrOTPRequired
-			}
-			if string(by) == `{"reason":"OTP_INVALID"}` {
+			case otpInvalidReason:
 				return out, ErrOTPInvalid
 			}
 			b.error(resp.StatusCode, string(by), err)
+			if isLoginBlockedResponse(resp.StatusCode, by) {
+				return out, ErrLoginBlocked
+			}
 			return out, ErrBadCredentials
 		}
 
@@ -1187,16 +1399,7 @@ func postSessions(b *OGame, gameEnvironmentID, platformGameID, username, passwor
 		}
 
 		// put in cookie jar so that we can re-login reusing the cookies
-		u, _ := url.Parse("https://gameforge.com")
-		cookies := b.Client.Jar.Cookies(u)
-		cookie := &http.Cookie{
-			Name:   gfTokenCookieName,
-			Value:  out.Token,
-			Path:   "/",
-			Domain: ".gameforge.com",
-		}
-		cookies = append(cookies, cookie)
-		b.Client.Jar.SetCookies(u, cookies)
+		b.setBearerToken(out.Token, time.Now().Add(DefaultBearerTokenLifetime))
 
 		return out, nil
 	}
@@ -1264,12 +1467,7 @@ func postSessions2(client *http.Client, gameEnvironmentID, platformGameID, usern
 	}
 
 	if otpSecret != "" {
-		passcode, err := totp.GenerateCodeCustom(otpSecret, time.Now(), totp.ValidateOpts{
-			Period:    30,
-			Skew:      1,
-			Digits:    otp.DigitsSix,
-			Algorithm: otp.AlgorithmSHA1,
-		})
+		passcode, err := generateOTPCode(otpSecret)
 		if err != nil {
 			return out, err
 		}
@@ -1291,7 +1489,7 @@ func postSessions2(client *http.Client, gameEnvironmentID, platformGameID, usern
 		if gfChallengeID != "" {
 			parts := strings.Split(gfChallengeID, ";")
 			challengeID := parts[0]
-			return out, errors.New("captcha required, " + challengeID)
+			return out, newCaptchaRequiredError(challengeID)
 		}
 	}
 
@@ -1301,10 +1499,10 @@ func postSessions2(client *http.Client, gameEnvironmentID, platformGameID, usern
 
 	by, _, _ := readBody(resp)
 	if resp.StatusCode != 201 {
-		if string(by) == `{"reason":"OTP_REQUIRED"}` {
+		switch sessionsErrorReason(by) {
+		case otpRequiredReason:
 			return out, ErrOTPRequired
-		}
-		if string(by) == `{"reason":"OTP_INVALID"}` {
+		case otpInvalidReason:
 			return out, ErrOTPInvalid
 		}
 		return out, ErrBadCredentials
@@ -1326,7 +1524,14 @@ func (b *OGame) login() error {
 	b.debug("post sessions")
 	postSessionsRes, err := postSessions(b, gameEnvironmentID, platformGameID, b.Username, b.password, b.otpSecret)
 	if err != nil {
-		return err
+		browserLogin := b.getBrowserLogin()
+		if browserLogin == nil || isCredentialsError(err) {
+			return err
+		}
+		b.error("http login failed, falling back to browser login: " + err.Error())
+		if postSessionsRes.Token, err = b.loginWithBrowser(browserLogin); err != nil {
+			return err
+		}
 	}
 
 	server, userAccount, err := b.loginPart1(postSessionsRes.Token)
@@ -1351,7 +1556,7 @@ func (b *OGame) login() error {
 		return err
 	}
 
-	if err := b.Client.Jar.(*cookiejar.Jar).Save(); err != nil {
+	if err := b.saveCookies(); err != nil {
 		return err
 	}
 	for _, fn := range b.interceptorCallbacks {
@@ -1376,19 +1581,16 @@ func (b *OGame) loginPart1(token string) (server Server, userAccount account, er
 	if err != nil {
 		return
 	}
-	if userAccount.Blocked {
-		return server, userAccount, ErrAccountBlocked
+	if banErr := accountBanFromLobby(userAccount); banErr != nil {
+		b.notifyAccountBanned(banErr)
+		return server, userAccount, banErr
 	}
 	b.debug("Players online: " + strconv.FormatInt(server.PlayersOnline, 10) + ", Players: " + strconv.FormatInt(server.PlayerCount, 10))
 	return
 }
 
-func (b *OGame) loginPart2(server Server, userAccount account) error {
-	atomic.StoreInt32(&b.isLoggedInAtom, 1) // At this point, we are logged in
-	atomic.StoreInt32(&b.isConnectedAtom, 1)
-	// Get server data
-	start := time.Now()
-	b.server = server
+// loadServerData fetches the server data, the missing fleet speeds default to 1
+func (b *OGame) loadServerData() error {
 	serverData, err := b.getServerData()
 	if err != nil {
 		return err
@@ -1406,6 +1608,19 @@ func (b *OGame) loginPart2(server Server, userAccount account) error {
 		serverData.SpeedFleet = serverData.SpeedFleetPeaceful
 	}
 	b.serverData = serverData
+	b.serverDataCachedAt = time.Now()
+	return nil
+}
+
+func (b *OGame) loginPart2(server Server, userAccount account) error {
+	atomic.StoreInt32(&b.isLoggedInAtom, 1) // At this point, we are logged in
+	atomic.StoreInt32(&b.isConnectedAtom, 1)
+	// Get server data
+	start := time.Now()
+	b.server = server
+	if err := b.loadServerData(); err != nil {
+		return err
+	}
 	lang := server.Language
 	if server.Language == "yu" {
 		lang = "ba"
@@ -1480,8 +1695,9 @@ func (b *OGame) cacheFullPageInfo(page string, pageHTML []byte) {
 	doc, _ := goquery.NewDocumentFromReader(bytes.NewReader(pageHTML))
 	b.planetsMu.Lock()
 	b.planets = b.extractor.ExtractPlanetsFromDoc(doc, b)
+	b.planetsCachedAt = time.Now()
 	b.planetsMu.Unlock()
-	b.isVacationModeEnabled = b.extractor.ExtractIsInVacationFromDoc(doc)
+	b.updateVacationMode(doc)
 	b.ajaxChatToken, _ = b.extractor.ExtractAjaxChatToken(pageHTML)
 	b.characterClass, _ = b.extractor.ExtractCharacterClassFromDoc(doc)
 	b.hasCommander = b.extractor.ExtractCommanderFromDoc(doc)
@@ -1492,11 +1708,13 @@ func (b *OGame) cacheFullPageInfo(page string, pageHTML []byte) {
 
 	if page == "overview" {
 		b.Player, _ = b.extractor.ExtractUserInfos(pageHTML, b.language)
+		b.cacheActiveItems(doc)
 	} else if page == "preferences" {
 		b.CachedPreferences = b.extractor.ExtractPreferencesFromDoc(doc)
 	} else if page == "research" {
 		researches := b.extractor.ExtractResearchFromDoc(doc)
 		b.researches = &researches
+		b.researchesCachedAt = time.Now()
 	}
 }
 
@@ -1536,7 +1754,10 @@ func (b *OGame) SetOGameCredentials(username, password, otpSecret, bearerToken s
 	b.Username = username
 	b.password = password
 	b.otpSecret = otpSecret
+	b.bearerTokenMu.Lock()
 	b.bearerToken = bearerToken
+	b.bearerTokenExpiresAt = time.Time{}
+	b.bearerTokenMu.Unlock()
 }
 
 func (b *OGame) setOGameLobby(lobby string) {
@@ -1614,9 +1835,13 @@ func (b *OGame) setProxy(proxyAddress, username, password, proxyType string, log
 	if proxyType == "" {
 		proxyType = "socks5"
 	}
+	b.proxyPool = nil
 	if proxyAddress == "" {
 		b.loginProxyTransport = nil
 		b.Client.Transport = http.DefaultTransport
+		if b.tlsFingerprint != "" {
+			return b.SetTLSFingerprint(b.tlsFingerprint)
+		}
 		return nil
 	}
 	transport, err := getTransport(proxyAddress, username, password, proxyType, config)
@@ -1625,6 +1850,9 @@ func (b *OGame) setProxy(proxyAddress, username, password, proxyType string, log
 	if loginOnly {
 		b.Client.Transport = http.DefaultTransport
 	}
+	if err == nil && b.tlsFingerprint != "" {
+		err = b.SetTLSFingerprint(b.tlsFingerprint)
+	}
 	return err
 }
 
@@ -1751,6 +1979,7 @@ LOOP:
 			for _, clb := range b.chatCallbacks {
 				clb(chatMsg)
 			}
+			b.emitEvent(NewChatMessageEvent(chatMsg))
 		} else if regexp.MustCompile(`^\d+/auctioneer`).Match(msg) {
 			// 42/auctioneer,["timeLeft","<span style=\"color:#99CC00;\"><b>approx. 30m</b></span> remaining until the auction ends"] // every minute
 			// 42/auctioneer,["timeLeft","Next auction in:<br />\n<span class=\"nextAuction\" id=\"nextAuction\">117</span>"]
@@ -1997,6 +2226,7 @@ LOOP:
 				for _, clb := range b.chatCallbacks {
 					clb(chatMsg)
 				}
+				b.emitEvent(NewChatMessageEvent(chatMsg))
 			}
 		} else {
 			b.error("unknown message received:", string(buf))
@@ -2102,7 +2332,7 @@ func (m ChatMsg) String() string {
 
 func (b *OGame) logout() {
 	_, _ = b.getPage(LogoutPage, CelestialID(0))
-	b.Client.Jar.(*cookiejar.Jar).Save()
+	_ = b.saveCookies()
 	if atomic.CompareAndSwapInt32(&b.isLoggedInAtom, 1, 0) {
 		select {
 		case <-b.closeChatCh:
@@ -2201,36 +2431,72 @@ func (b *OGame) preRequestChecks() error {
 	if !b.IsLoggedIn() {
 		return ErrBotLoggedOut
 	}
+	if b.IsInSafeMode() {
+		return ErrSafeMode
+	}
 	if b.serverURL == "" {
 		return errors.New("serverURL is empty")
 	}
 	return nil
 }
 
-func (b *OGame) execRequest(method, finalURL string, payload, vals url.Values) ([]byte, error) {
+func (b *OGame) execRequest(method, finalURL string, payload, vals url.Values, timeout time.Duration) ([]byte, error) {
+	var body []byte
+	contentType := ""
+	if method == "POST" {
+		body = []byte(payload.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+	by, _, err := b.execRawRequestWithTimeout(method, finalURL, contentType, body, vals, timeout)
+	return by, err
+}
+
+// execRawRequest sends body as is with the provided content type, and returns the response headers
+func (b *OGame) execRawRequest(method, finalURL, contentType string, body []byte, vals url.Values) ([]byte, http.Header, error) {
+	return b.execRawRequestWithTimeout(method, finalURL, contentType, body, vals, 0)
+}
+
+// execRawRequestWithTimeout same as execRawRequest, timeout replaces the request timeout if not 0
+func (b *OGame) execRawRequestWithTimeout(method, finalURL, contentType string, body []byte, vals url.Values, timeout time.Duration) ([]byte, http.Header, error) {
+	// The error is only attached to the task that held the bot lock during the whole request
+	taskID := b.lockedTaskID()
+	by, header, err := b.doRawRequest(method, finalURL, contentType, body, vals, timeout)
+	if err != nil {
+		b.recordTaskError(taskID, err)
+	}
+	return by, header, err
+}
+
+// doRawRequest sends the request of execRawRequestWithTimeout
+func (b *OGame) doRawRequest(method, finalURL, contentType string, body []byte, vals url.Values, timeout time.Duration) ([]byte, http.Header, error) {
 	var req *http.Request
 	var err error
 	if method == "GET" {
 		req, err = http.NewRequest(method, finalURL, nil)
 	} else {
-		req, err = http.NewRequest(method, finalURL, strings.NewReader(payload.Encode()))
+		req, err = http.NewRequest(method, finalURL, bytes.NewReader(body))
 	}
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, nil, err
 	}
 
-	if method == "POST" {
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	if contentType != "" {
+		req.Header.Add("Content-Type", contentType)
 	}
 	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
 	if IsAjaxPage(vals) {
 		req.Header.Add("X-Requested-With", "XMLHttpRequest")
 	}
 
-	req = req.WithContext(b.ctx)
-	resp, err := b.Client.Do(req)
+	ctx, cancel, err := b.requestContext(timeout)
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, nil, err
+	}
+	defer cancel()
+	req = req.WithContext(ctx)
+	resp, err := b.doWithBreaker(req)
+	if err != nil {
+		return []byte{}, nil, err
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -2238,15 +2504,21 @@ func (b *OGame) execRequest(method, finalURL string, payload, vals url.Values) (
 		}
 	}()
 
+	if to := movedServerHost(req, resp); to != "" {
+		return []byte{}, resp.Header, &ServerMovedError{From: req.URL.Hostname(), To: to}
+	}
 	if resp.StatusCode >= 500 {
-		return []byte{}, err
+		return []byte{}, resp.Header, &ServerError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return []byte{}, resp.Header, ErrRateLimited
 	}
 	by, err := wrapperReadBody(b, resp)
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, resp.Header, err
 	}
 	b.bytesUploaded += req.ContentLength
-	return by, nil
+	return by, resp.Header, nil
 }
 
 func (b *OGame) getPageContent(vals url.Values, opts ...Option) ([]byte, error) {
@@ -2258,6 +2530,9 @@ func (b *OGame) getPageContent(vals url.Values, opts ...Option) ([]byte, error)
 	if err := b.preRequestChecks(); err != nil {
 		return []byte{}, err
 	}
+	if err := b.humanize(vals); err != nil {
+		return []byte{}, err
+	}
 
 	if vals.Get("cp") == "" {
 		if cfg.ChangePlanet != 0 {
@@ -2265,12 +2540,8 @@ func (b *OGame) getPageContent(vals url.Values, opts ...Option) ([]byte, error)
 		}
 	}
 
-	finalURL := b.serverURL + "/game/index.php?" + vals.Encode()
-
+	var finalURL string
 	allianceID := vals.Get("allianceId")
-	if allianceID != "" {
-		finalURL = b.serverURL + "/game/allianceInfo.php?allianceID=" + allianceID
-	}
 
 	page := vals.Get("page")
 	if page == "ingame" ||
@@ -2281,7 +2552,12 @@ func (b *OGame) getPageContent(vals url.Values, opts ...Option) ([]byte, error)
 	var pageHTMLBytes []byte
 
 	clb := func() (err error) {
-		pageHTMLBytes, err = b.execRequest("GET", finalURL, nil, vals)
+		// Built on each try, the server URL changes if the universe moved
+		finalURL = b.serverURL + "/game/index.php?" + vals.Encode()
+		if allianceID != "" {
+			finalURL = b.serverURL + "/game/allianceInfo.php?allianceID=" + allianceID
+		}
+		pageHTMLBytes, err = b.execRequest("GET", finalURL, nil, vals, cfg.Timeout)
 		if err != nil {
 			return err
 		}
@@ -2342,6 +2618,9 @@ func (b *OGame) postPageContent(vals, payload url.Values, opts ...Option) ([]byt
 	if err := b.preRequestChecks(); err != nil {
 		return []byte{}, err
 	}
+	if err := b.humanize(vals); err != nil {
+		return []byte{}, err
+	}
 
 	if vals.Get("cp") == "" {
 		if cfg.ChangePlanet != 0 {
@@ -2353,20 +2632,21 @@ func (b *OGame) postPageContent(vals, payload url.Values, opts ...Option) ([]byt
 		payload.Set("token", b.ajaxChatToken)
 	}
 
-	finalURL := b.serverURL + "/game/index.php?" + vals.Encode()
+	var finalURL string
 	page := vals.Get("page")
 	if page == "ingame" {
 		page = vals.Get("component")
 	}
 	var pageHTMLBytes []byte
 
-	if err := b.withRetry(func() (err error) {
+	err := b.withRetry(func() (err error) {
 		// Needs to be inside the withRetry, so if we need to re-login the redirect is back for the login call
 		// Prevent redirect (301) https://stackoverflow.com/a/38150816/4196220
 		b.Client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
 		defer func() { b.Client.CheckRedirect = nil }()
 
-		pageHTMLBytes, err = b.execRequest("POST", finalURL, payload, vals)
+		finalURL = b.serverURL + "/game/index.php?" + vals.Encode()
+		pageHTMLBytes, err = b.execRequest("POST", finalURL, payload, vals, cfg.Timeout)
 		if err != nil {
 			return err
 		}
@@ -2379,7 +2659,8 @@ func (b *OGame) postPageContent(vals, payload url.Values, opts ...Option) ([]byt
 		}
 
 		return nil
-	}); err != nil {
+	})
+	if err != nil {
 		b.error(err)
 		return []byte{}, err
 	}
@@ -2405,12 +2686,68 @@ func (b *OGame) postPageContent(vals, payload url.Values, opts ...Option) ([]byt
 	return pageHTMLBytes, nil
 }
 
+// postRawPageContent sends a POST request with an arbitrary body (json, multipart...) and returns the response headers.
+// The content type and body are forwarded untouched, which is needed to proxy browser plugins ajax calls.
+func (b *OGame) postRawPageContent(vals url.Values, contentType string, body []byte, opts ...Option) ([]byte, http.Header, error) {
+	var cfg options
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := b.preRequestChecks(); err != nil {
+		return []byte{}, nil, err
+	}
+	if err := b.humanize(vals); err != nil {
+		return []byte{}, nil, err
+	}
+
+	if vals.Get("cp") == "" {
+		if cfg.ChangePlanet != 0 {
+			vals.Set("cp", strconv.FormatInt(int64(cfg.ChangePlanet), 10))
+		}
+	}
+
+	var finalURL string
+	var pageHTMLBytes []byte
+	var headers http.Header
+
+	err := b.withRetry(func() (err error) {
+		// Prevent redirect (301) https://stackoverflow.com/a/38150816/4196220
+		b.Client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+		defer func() { b.Client.CheckRedirect = nil }()
+		finalURL = b.serverURL + "/game/index.php?" + vals.Encode()
+		pageHTMLBytes, headers, err = b.execRawRequestWithTimeout("POST", finalURL, contentType, body, vals, cfg.Timeout)
+		return err
+	})
+	if err != nil {
+		b.error(err)
+		return []byte{}, nil, err
+	}
+
+	if !cfg.SkipInterceptor {
+		var payload url.Values
+		if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+			payload, _ = url.ParseQuery(string(body))
+		}
+		go func() {
+			for _, fn := range b.interceptorCallbacks {
+				fn("POST", finalURL, vals, payload, pageHTMLBytes)
+			}
+		}()
+	}
+
+	return pageHTMLBytes, headers, nil
+}
+
 func (b *OGame) getAlliancePageContent(vals url.Values) ([]byte, error) {
 	if err := b.preRequestChecks(); err != nil {
 		return []byte{}, err
 	}
+	if err := b.humanize(vals); err != nil {
+		return []byte{}, err
+	}
 	finalURL := b.serverURL + "/game/allianceInfo.php?" + vals.Encode()
-	return b.execRequest("GET", finalURL, nil, vals)
+	return b.execRequest("GET", finalURL, nil, vals, 0)
 }
 
 type eventboxResp struct {
@@ -2419,27 +2756,14 @@ type eventboxResp struct {
 	Friendly int
 }
 
+// withRetry executes fn, transient failures are retried according to the retry policy
+// and an expired session is restored before retrying once.
 func (b *OGame) withRetry(fn func() error) error {
-	maxRetry := 10
-	retryInterval := 1
-	retry := func(err error) error {
-		b.error(err.Error())
-		select {
-		case <-time.After(time.Duration(retryInterval) * time.Second):
-		case <-b.ctx.Done():
-			return ErrBotInactive
-		}
-		retryInterval *= 2
-		if retryInterval > 60 {
-			retryInterval = 60
-		}
-		return nil
-	}
-
-	for {
+	policy := b.getRetryPolicy()
+	for attempt := 0; ; attempt++ {
 		err := fn()
 		if err == nil {
-			break
+			return nil
 		}
 		// If we manually logged out, do not try to auto re login.
 		if !b.IsEnabled() {
@@ -2448,29 +2772,88 @@ func (b *OGame) withRetry(fn func() error) error {
 		if !b.IsLoggedIn() {
 			return ErrBotLoggedOut
 		}
-		maxRetry--
-		if maxRetry <= 0 {
-			return errors.Wrap(err, ErrFailedExecuteCallback.Error())
+		// Retrying will not help if the account is banned or locked
+		if _, ok := err.(*AccountBanError); ok {
+			return err
+		}
+		// Fail fast while the server is down, the circuit breaker probes it
+		if err == ErrServerUnavailable || err == ErrTaskDeadlineExceeded {
+			return err
 		}
 
-		if retryErr := retry(err); retryErr != nil {
-			return retryErr
+		// The universe moved to another server, log in on it and retry once
+		if movedErr, ok := err.(*ServerMovedError); ok {
+			if relocateErr := b.relocateServer(movedErr); relocateErr != nil {
+				return relocateErr
+			}
+			return fn()
 		}
 
 		if err == ErrNotLogged {
-			if _, loginErr := b.wrapLoginWithExistingCookies(); loginErr != nil {
-				b.error(loginErr.Error()) // log error
-				if loginErr == ErrAccountNotFound ||
-					loginErr == ErrAccountBlocked ||
-					loginErr == ErrBadCredentials ||
-					loginErr == ErrOTPRequired ||
-					loginErr == ErrOTPInvalid {
-					return loginErr
-				}
+			if loginErr := b.relogin(); loginErr != nil {
+				return loginErr
+			}
+			// Session restored, retry the failed request once
+			if err = fn(); err == ErrNotLogged {
+				return ErrReloginFailed
 			}
+			return err
+		}
+
+		if !policy.isTransient(err) {
+			return err
+		}
+		if attempt >= policy.MaxRetries {
+			return errors.Wrap(err, ErrFailedExecuteCallback.Error())
+		}
+		b.error(err.Error())
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-b.ctx.Done():
+			return ErrBotInactive
+		}
+	}
+}
+
+const maxReloginAttempts = 5
+
+// relogin restores an expired session (or invalidated lobby token) using exponential backoff with jitter between attempts.
+// Callbacks registered with OnSessionLost are notified after every failed attempt.
+func (b *OGame) relogin() error {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		_, loginErr := b.wrapLoginWithExistingCookies()
+		if loginErr == nil {
+			return nil
+		}
+		b.error(loginErr.Error()) // log error
+		for _, clb := range b.sessionLostCallbacks {
+			clb(attempt, loginErr)
+		}
+		if loginErr == ErrAccountNotFound ||
+			loginErr == ErrAccountBlocked ||
+			loginErr == ErrBadCredentials ||
+			loginErr == ErrOTPRequired ||
+			loginErr == ErrOTPInvalid {
+			return loginErr
+		}
+		if _, ok := loginErr.(*AccountBanError); ok {
+			return loginErr
+		}
+		if attempt >= maxReloginAttempts {
+			return errors.Wrap(loginErr, ErrReloginFailed.Error())
+		}
+		jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+		select {
+		case <-time.After(backoff + jitter):
+		case <-b.ctx.Done():
+			return ErrBotInactive
+		}
+		backoff *= 2
+		if backoff > time.Minute {
+			backoff = time.Minute
 		}
 	}
-	return nil
 }
 
 func (b *OGame) getPageJSON(vals url.Values, v interface{}) error {
@@ -2602,6 +2985,7 @@ func (b *OGame) getPlanets() []Planet {
 }
 
 func (b *OGame) getPlanet(v interface{}) (Planet, error) {
+	v = b.resolveAlias(v)
 	pageHTML, _ := b.getPage(OverviewPage, CelestialID(0))
 	return b.extractor.ExtractPlanet(pageHTML, v, b)
 }
@@ -2612,6 +2996,7 @@ func (b *OGame) getMoons() []Moon {
 }
 
 func (b *OGame) getMoon(v interface{}) (Moon, error) {
+	v = b.resolveAlias(v)
 	pageHTML, _ := b.getPage(OverviewPage, CelestialID(0))
 	return b.extractor.ExtractMoon(pageHTML, b, v)
 }
@@ -2622,6 +3007,7 @@ func (b *OGame) getCelestials() ([]Celestial, error) {
 }
 
 func (b *OGame) getCelestial(v interface{}) (Celestial, error) {
+	v = b.resolveAlias(v)
 	pageHTML, _ := b.getPage(OverviewPage, CelestialID(0))
 	return b.extractor.ExtractCelestial(pageHTML, b, v)
 }
@@ -2650,6 +3036,7 @@ func (b *OGame) recruitOfficer(typ, days int64) error {
 }
 
 func (b *OGame) abandon(v interface{}) error {
+	v = b.resolveAlias(v)
 	pageHTML, _ := b.getPage(OverviewPage, CelestialID(0))
 	var planetID PlanetID
 	if coordStr, ok := v.(string); ok {
@@ -2711,6 +3098,35 @@ func (b *OGame) abandon(v interface{}) error {
 	return err
 }
 
+func (b *OGame) renamePlanet(celestialID CelestialID, name string) error {
+	name = strings.TrimSpace(name)
+	if len(name) < 2 || len(name) > 20 {
+		return errors.New("planet name must be 2 to 20 characters long")
+	}
+	pageHTML, err := b.getPage(PlanetlayerPage, celestialID)
+	if err != nil {
+		return err
+	}
+	doc, _ := goquery.NewDocumentFromReader(bytes.NewReader(pageHTML))
+	token := doc.Find("form#planetMaintenance input[name=token]").AttrOr("value", "")
+	payload := url.Values{
+		"newPlanetName": {name},
+		"token":         {token},
+	}
+	vals := url.Values{"page": {PlanetRenameAjaxPage}, "cp": {strconv.FormatInt(int64(celestialID), 10)}}
+	by, err := b.postPageContent(vals, payload)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(by, &resp); err != nil || resp.Status != "success" {
+		return errors.New("failed to rename planet")
+	}
+	return nil
+}
+
 func (b *OGame) serverTime() time.Time {
 	pageHTML, _ := b.getPage(OverviewPage, CelestialID(0))
 	serverTime, err := b.extractor.ExtractServerTime(pageHTML)
@@ -2758,7 +3174,7 @@ func (b *OGame) sendMessage(id int64, message string, isPlayer bool) error {
 		return err
 	}
 	if strings.Contains(string(bobyBytes), "INVALID_PARAMETERS") {
-		return errors.New("invalid parameters")
+		return ErrInvalidParameters
 	}
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(string(bobyBytes)))
 	if doc.Find("title").Text() == "OGame Lobby" {
@@ -2824,6 +3240,13 @@ func galaxyDistance(galaxy1, galaxy2, universeSize int64, donutGalaxy bool) (dis
 	return int64(20000 * val)
 }
 
+// isInPhalanxRange returns either or not coord can be scanned by a phalanx of level phalanxLvl on moonCoord
+func (b *OGame) isInPhalanxRange(moonCoord Coordinate, phalanxLvl int64, coord Coordinate) bool {
+	phalanxRange := SensorPhalanx.GetRange(phalanxLvl, b.isDiscoverer())
+	return moonCoord.Galaxy == coord.Galaxy &&
+		systemDistance(b.serverData.Systems, moonCoord.System, coord.System, b.serverData.DonutSystem) <= phalanxRange
+}
+
 func systemDistance(nbSystems, system1, system2 int64, donutSystem bool) (distance int64) {
 	if !donutSystem {
 		return int64(math.Abs(float64(system2 - system1)))
@@ -2933,14 +3356,12 @@ func (b *OGame) getPhalanx(moonID MoonID, coord Coordinate) ([]Fleet, error) {
 
 	// Ensure we have the resources to scan the planet
 	if resources.Deuterium < SensorPhalanx.ScanConsumption() {
-		return res, errors.New("not enough deuterium")
+		return res, &GameError{Err: ErrNotEnoughResources, Message: "not enough deuterium"}
 	}
 
 	// Verify that coordinate is in phalanx range
-	phalanxRange := SensorPhalanx.GetRange(phalanxLvl, b.isDiscoverer())
-	if moon.Coordinate.Galaxy != coord.Galaxy ||
-		systemDistance(b.serverData.Systems, moon.Coordinate.System, coord.System, b.serverData.DonutSystem) > phalanxRange {
-		return res, errors.New("coordinate not in phalanx range")
+	if !b.isInPhalanxRange(moon.Coordinate, phalanxLvl, coord) {
+		return res, ErrNotInPhalanxRange
 	}
 
 	// Get galaxy planets information, verify coordinate is valid planet (second call to ogame server)
@@ -3023,16 +3444,19 @@ func (b *OGame) jumpGateDestinations(originMoonID MoonID) ([]MoonID, int64, erro
 	pageHTML, _ := b.getPage(JumpgatelayerPage, originMoonID.Celestial())
 	_, _, dests, wait := b.extractor.ExtractJumpGate(pageHTML)
 	if wait > 0 {
-		return dests, wait, fmt.Errorf("jump gate is in recharge mode for %d seconds", wait)
+		return dests, wait, b.setJumpGateCooldown(originMoonID, wait)
 	}
 	return dests, wait, nil
 }
 
 func (b *OGame) executeJumpGate(originMoonID, destMoonID MoonID, ships ShipsInfos) (bool, int64, error) {
+	if cooldownErr := b.jumpGateCooldown(originMoonID); cooldownErr != nil {
+		return false, cooldownErr.Remaining, cooldownErr
+	}
 	pageHTML, _ := b.getPage(JumpgatelayerPage, originMoonID.Celestial())
 	availShips, token, dests, wait := b.extractor.ExtractJumpGate(pageHTML)
 	if wait > 0 {
-		return false, wait, fmt.Errorf("jump gate is in recharge mode for %d seconds", wait)
+		return false, wait, b.setJumpGateCooldown(originMoonID, wait)
 	}
 
 	// Validate destination moon id
@@ -3054,6 +3478,7 @@ func (b *OGame) executeJumpGate(originMoonID, destMoonID MoonID, ships ShipsInfo
 	if _, err := b.postPageContent(url.Values{"page": {"jumpgate_execute"}}, payload); err != nil {
 		return false, 0, err
 	}
+	b.recordJump(originMoonID, destMoonID)
 	return true, 0, nil
 }
 
@@ -3085,7 +3510,11 @@ func (b *OGame) getEmpireJSON(nbr int64) (interface{}, error) {
 	}
 	// Replace the Ogame hostname with our custom hostname
 	pageHTML := strings.Replace(string(pageHTMLBytes), b.serverURL, b.apiNewHostname, -1)
-	return b.extractor.ExtractEmpireJSON([]byte(pageHTML))
+	res, err := b.extractor.ExtractEmpireJSON([]byte(pageHTML))
+	if err == nil {
+		b.cacheEmpireJSON(nbr, res)
+	}
+	return res, err
 }
 
 func (b *OGame) createUnion(fleet Fleet, unionUsers []string) (int64, error) {
@@ -3301,6 +3730,11 @@ func (b *OGame) offerMarketplace(marketItemType int64, itemID interface{}, quant
 	if len(res.Errors) > 0 {
 		return errors.New(strconv.FormatInt(res.Errors[0].Error, 10) + " : " + res.Errors[0].Message)
 	}
+	side := MarketplaceSell
+	if marketItemType == 3 {
+		side = MarketplaceBuy
+	}
+	b.recordMarketplaceOffer(side, itemIDPayload, quantity, priceType, price)
 	return err
 }
 
@@ -3662,10 +4096,11 @@ func (b *OGame) getAttacks(opts ...Option) (out []AttackEvent, err error) {
 	}
 	planets := b.GetCachedPlanets()
 	fixAttackEvents(out, planets)
+	b.emitNewAttacks(out)
 	return
 }
 
-func (b *OGame) galaxyInfos(galaxy, system int64, options ...Option) (SystemInfos, error) {
+func (b *OGame) galaxyInfos(galaxy, system int64, opts ...Option) (SystemInfos, error) {
 	var res SystemInfos
 	if galaxy < 1 || galaxy > b.server.Settings.UniverseSize {
 		return res, fmt.Errorf("galaxy must be within [1, %d]", b.server.Settings.UniverseSize)
@@ -3673,12 +4108,22 @@ func (b *OGame) galaxyInfos(galaxy, system int64, options ...Option) (SystemInfo
 	if system < 1 || system > b.serverData.Systems {
 		return res, errors.New("system must be within [1, " + strconv.FormatInt(b.serverData.Systems, 10) + "]")
 	}
+	var cfg options
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cache := b.getGalaxyCache()
+	if cache != nil && !cfg.SkipCache {
+		if cached, ok := cache.Get(galaxy, system); ok {
+			return cached, nil
+		}
+	}
 	payload := url.Values{
 		"galaxy": {strconv.FormatInt(galaxy, 10)},
 		"system": {strconv.FormatInt(system, 10)},
 	}
 	vals := url.Values{"page": {"ingame"}, "component": {"galaxyContent"}, "ajax": {"1"}}
-	pageHTML, err := b.postPageContent(vals, payload, options...)
+	pageHTML, err := b.postPageContent(vals, payload, opts...)
 	if err != nil {
 		return res, err
 	}
@@ -3687,7 +4132,12 @@ func (b *OGame) galaxyInfos(galaxy, system int64, options ...Option) (SystemInfo
 		return res, err
 	}
 	if res.galaxy != galaxy || res.system != system {
-		return SystemInfos{}, errors.New("not enough deuterium")
+		return SystemInfos{}, &GameError{Err: ErrNotEnoughResources, Message: "not enough deuterium"}
+	}
+	if cache != nil {
+		if err := cache.Set(res); err != nil {
+			b.error("failed to cache galaxy system: " + err.Error())
+		}
 	}
 	return res, err
 }
@@ -3708,6 +4158,11 @@ func (b *OGame) setResourceSettings(planetID PlanetID, settings ResourceSettings
 	if !exists {
 		return errors.New("unable to find token")
 	}
+	// Crawlers can be overloaded over 100% by collectors (v8+), the page only offers the allowed values
+	if crawlerSelect := doc.Find("select[name=last217]"); crawlerSelect.Length() > 0 &&
+		crawlerSelect.Find("option[value='"+strconv.FormatInt(settings.Crawler, 10)+"']").Length() == 0 {
+		return fmt.Errorf("invalid crawler setting %d%%", settings.Crawler)
+	}
 	payload := url.Values{
 		"saveSettings": {"1"},
 		"token":        {token},
@@ -3763,6 +4218,7 @@ func (b *OGame) getResearch() Researches {
 	pageHTML, _ := b.getPage(ResearchPage, CelestialID(0))
 	researches := b.extractor.ExtractResearch(pageHTML)
 	b.researches = &researches
+	b.researchesCachedAt = time.Now()
 	return researches
 }
 
@@ -3909,6 +4365,7 @@ func (b *OGame) build(celestialID CelestialID, id ID, nbr int64) error {
 		var maximumNbr int64 = 99999
 		var err error
 		var token string
+		total := nbr
 		for nbr > 0 {
 			tmp := int64(math.Min(float64(nbr), float64(maximumNbr)))
 			vals.Set("menge", strconv.FormatInt(tmp, 10))
@@ -3923,10 +4380,16 @@ func (b *OGame) build(celestialID CelestialID, id ID, nbr int64) error {
 			vals.Set("token", token)
 			nbr -= maximumNbr
 		}
+		if err == nil {
+			b.emitEvent(NewBuildEvent(celestialID, id, total))
+		}
 		return err
 	}
 
 	_, err := b.getPageContent(vals)
+	if err == nil {
+		b.emitEvent(NewBuildEvent(celestialID, id, 0))
+	}
 	return err
 }
 
@@ -4194,7 +4657,11 @@ type CheckTargetResponse struct {
 }
 
 func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate,
-	mission MissionID, resources Resources, holdingTime, unionID int64, ensure bool) (Fleet, error) {
+	mission MissionID, resources Resources, holdingTime, unionID int64, ensure, friendlyFire bool) (Fleet, error) {
+
+	if !speed.IsValid(b.characterClass) {
+		return Fleet{}, ErrInvalidSpeed
+	}
 
 	// Get existing fleet, so we can ensure new fleet ID is greater
 	initialFleets, slots := b.getFleets()
@@ -4255,6 +4722,7 @@ func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed S
 	}
 
 	availableShips := b.extractor.ExtractFleet1ShipsFromDoc(fleet1Doc)
+	ships = resolveAllShips(ships, availableShips)
 
 	atLeastOneShipSelected := false
 	if !ensure {
@@ -4270,7 +4738,9 @@ func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed S
 			if ship.Nbr > availableShips.ByID(ship.ID) {
 				return Fleet{}, fmt.Errorf("not enough ships to send, %s", Objs.ByID(ship.ID).GetName())
 			}
-			atLeastOneShipSelected = true
+			if ship.Nbr > 0 {
+				atLeastOneShipSelected = true
+			}
 		}
 	}
 	if !atLeastOneShipSelected {
@@ -4338,12 +4808,33 @@ func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed S
 
 	if !checkRes.TargetOk {
 		if len(checkRes.Errors) > 0 {
-			return Fleet{}, errors.New(checkRes.Errors[0].Message + " (" + strconv.Itoa(checkRes.Errors[0].Error) + ")")
+			return Fleet{}, newFleetError(int64(checkRes.Errors[0].Error), checkRes.Errors[0].Message)
+		}
+		return Fleet{}, &GameError{Err: ErrTargetNotExists, Message: "target is not ok"}
+	}
+
+	if checkRes.TargetIsBuddyOrAllyMember {
+		b.addFriendlyPlayers(int64(checkRes.TargetPlayerID))
+	}
+	if !friendlyFire && isHostileMission(mission) && b.isFriendlyPlayer(int64(checkRes.TargetPlayerID)) {
+		return Fleet{}, ErrFriendlyTarget
+	}
+
+	if resources.Metal == AllAvailable || resources.Crystal == AllAvailable || resources.Deuterium == AllAvailable {
+		var origin Coordinate
+		for _, c := range myCelestials {
+			if c.GetID() == celestialID {
+				origin = c.GetCoordinate()
+			}
 		}
-		return Fleet{}, errors.New("target is not ok")
+		_, fuel := CalcFlightTime(origin, where, b.serverData.Galaxies, b.serverData.Systems, b.serverData.DonutGalaxy,
+			b.serverData.DonutSystem, b.serverData.GlobalDeuteriumSaveFactor, float64(speed)/10,
+			GetFleetSpeedForMission(b.IsV81(), b.serverData, mission), ShipsInfos{}.FromQuantifiables(ships),
+			b.getCachedResearch(), b.characterClass)
+		resources = resolveAllResources(resources, b.extractor.ExtractResourcesFromDoc(fleet1Doc), fuel)
 	}
 
-	cargo := ShipsInfos{}.FromQuantifiables(ships).Cargo(b.getCachedResearch(), b.server.Settings.EspionageProbeRaids == 1, b.isCollector(), b.IsPioneers())
+	cargo := b.fleetCargo(ShipsInfos{}.FromQuantifiables(ships))
 	newResources := Resources{}
 	if resources.Total() > cargo {
 		newResources.Deuterium = int64(math.Min(float64(resources.Deuterium), float64(cargo)))
@@ -4363,7 +4854,7 @@ func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed S
 	if b.IsV8() {
 		payload.Set("token", checkRes.NewAjaxToken)
 	}
-	payload.Set("speed", strconv.FormatInt(int64(speed), 10))
+	payload.Set("speed", speed.Payload())
 	payload.Set("crystal", strconv.FormatInt(newResources.Crystal, 10))
 	payload.Set("deuterium", strconv.FormatInt(newResources.Deuterium, 10))
 	payload.Set("metal", strconv.FormatInt(newResources.Metal, 10))
@@ -4410,7 +4901,7 @@ func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed S
 	}
 
 	if len(resStruct.Errors) > 0 {
-		return Fleet{}, errors.New(resStruct.Errors[0].Message + " (" + strconv.FormatInt(resStruct.Errors[0].Error, 10) + ")")
+		return Fleet{}, newFleetError(resStruct.Errors[0].Error, resStruct.Errors[0].Message)
 	}
 
 	// Page 5
@@ -4430,6 +4921,7 @@ func (b *OGame) sendFleet(celestialID CelestialID, ships []Quantifiable, speed S
 			}
 		}
 		if max.ID > maxInitialFleetID {
+			b.emitEvent(NewFleetEvent(max))
 			return max, nil
 		}
 	}
@@ -4461,27 +4953,30 @@ const Report EspionageReportType = 1
 
 // CombatReportSummary summary of combat report
 type CombatReportSummary struct {
-	ID           int64
-	APIKey       string
-	Origin       *Coordinate
-	Destination  Coordinate
-	AttackerName string
-	DefenderName string
-	Loot         int64
-	Metal        int64
-	Crystal      int64
-	Deuterium    int64
-	DebrisField  int64
-	CreatedAt    time.Time
+	ID             int64
+	APIKey         string
+	Origin         *Coordinate
+	Destination    Coordinate
+	AttackerName   string
+	DefenderName   string
+	AttackerLosses int64 // Units lost by the attacker
+	DefenderLosses int64 // Units lost by the defender
+	Loot           int64
+	Metal          int64
+	Crystal        int64
+	Deuterium      int64
+	DebrisField    int64
+	CreatedAt      time.Time
 }
 
 // EspionageReportSummary summary of espionage report
 type EspionageReportSummary struct {
-	ID             int64
-	Type           EspionageReportType
-	From           string // Fleet Command | Space Monitoring
-	Target         Coordinate
-	LootPercentage float64
+	ID               int64
+	Type             EspionageReportType
+	From             string // Fleet Command | Space Monitoring
+	Target           Coordinate
+	LootPercentage   float64
+	CounterEspionage int64 // Chance of counter-espionage (percent)
 }
 
 // ExpeditionMessage ...
@@ -4492,6 +4987,17 @@ type ExpeditionMessage struct {
 	CreatedAt  time.Time
 }
 
+// Message generic inbox message
+type Message struct {
+	ID          int64
+	TabID       int64
+	Title       string
+	Sender      string
+	Content     string       // Text content of the message
+	Coordinates []Coordinate // Coordinates found in the title and the content
+	CreatedAt   time.Time
+}
+
 // MarketplaceMessage ...
 type MarketplaceMessage struct {
 	ID                  int64
@@ -4499,6 +5005,10 @@ type MarketplaceMessage struct {
 	CreatedAt           time.Time
 	Token               string
 	MarketTransactionID int64
+	Item                string // Name of the item sold or bought, in the server language
+	Quantity            int64
+	PriceResource       string // Name of the resource paid, in the server language
+	Price               int64  // Price paid by the buyer, market fee included
 }
 
 func (b *OGame) getPageMessages(page, tabid int64) ([]byte, error) {
@@ -4528,13 +5038,11 @@ func (b *OGame) getEspionageReportMessages() ([]EspionageReportSummary, error) {
 }
 
 func (b *OGame) getCombatReportMessages() ([]CombatReportSummary, error) {
-	var tabid int64 = 21
 	var page int64 = 1
 	var nbPage int64 = 1
 	msgs := make([]CombatReportSummary, 0)
 	for page <= nbPage {
-		pageHTML, _ := b.getPageMessages(page, tabid)
-		newMessages, newNbPage := b.extractor.ExtractCombatReportMessagesSummary(pageHTML)
+		newMessages, newNbPage, _ := b.getCombatReportMessagesPage(page)
 		msgs = append(msgs, newMessages...)
 		nbPage = newNbPage
 		page++
@@ -4542,6 +5050,19 @@ func (b *OGame) getCombatReportMessages() ([]CombatReportSummary, error) {
 	return msgs, nil
 }
 
+func (b *OGame) getCombatReportMessagesPage(page int64) ([]CombatReportSummary, int64, error) {
+	var tabid int64 = 21
+	if page < 1 {
+		page = 1
+	}
+	pageHTML, err := b.getPageMessages(page, tabid)
+	if err != nil {
+		return []CombatReportSummary{}, 0, err
+	}
+	msgs, nbPage := b.extractor.ExtractCombatReportMessagesSummary(pageHTML)
+	return msgs, nbPage, nil
+}
+
 func (b *OGame) getExpeditionMessages() ([]ExpeditionMessage, error) {
 	var tabid int64 = 22
 	var page int64 = 1
@@ -4626,6 +5147,7 @@ func (b *OGame) getMarketplaceMessages(tabID int64) ([]MarketplaceMessage, error
 	for page <= nbPage {
 		pageHTML, _ := b.getPageMessages(page, tabid)
 		newMessages, newNbPage, _ := b.extractor.ExtractMarketplaceMessages(pageHTML, b.location)
+		b.recordMarketplaceTrades(newMessages)
 		msgs = append(msgs, newMessages...)
 		nbPage = newNbPage
 		page++
@@ -4796,10 +5318,15 @@ func (b *OGame) getResourcesProductions(planetID PlanetID) (Resources, error) {
 	planet, _ := b.getPlanet(planetID)
 	resBuildings, _ := b.getResourcesBuildings(planetID.Celestial())
 	researches := b.getResearch()
-	universeSpeed := b.serverData.Speed
 	resSettings, _ := b.getResourceSettings(planetID)
-	ratio := productionRatio(planet.Temperature, resBuildings, resSettings, researches.EnergyTechnology)
-	productions := getProductions(resBuildings, resSettings, researches, universeSpeed, planet.Temperature, ratio)
+	// The boosters are the ones active when the overview of the planet was last loaded
+	activeItems := b.getCachedActiveItems(planetID.Celestial())
+	productions := productionWithBonuses(b.productionInputs(resBuildings, resSettings, researches, planet.Temperature, activeItems))
+	if b.isProductionAuditEnabled() {
+		if _, err := b.auditProduction(planetID); err != nil {
+			b.error("failed to audit production : ", err)
+		}
+	}
 	return productions, nil
 }
 
@@ -4846,6 +5373,7 @@ func (b *OGame) stateChanged(locked bool, actor string) {
 
 func (b *OGame) botLock(lockedBy string) {
 	b.Lock()
+	b.startTask()
 	if atomic.CompareAndSwapInt32(&b.lockedAtom, 0, 1) {
 		b.state = lockedBy
 		b.stateChanged(true, lockedBy)
@@ -4853,6 +5381,7 @@ func (b *OGame) botLock(lockedBy string) {
 }
 
 func (b *OGame) botUnlock(unlockedBy string) {
+	b.endTask()
 	b.Unlock()
 	if atomic.CompareAndSwapInt32(&b.lockedAtom, 1, 0) {
 		b.state = unlockedBy
@@ -4887,6 +5416,10 @@ func (b *OGame) addAccount(number int, lang string) (NewAccount, error) {
 	if err != nil {
 		return newAccount, err
 	}
+	if token, _ := b.GetBearerToken(); token != "" {
+		newAccount.BearerToken = token
+		req.Header.Add("authorization", "Bearer "+token)
+	}
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
 	req = req.WithContext(b.ctx)
@@ -4905,9 +5438,15 @@ func (b *OGame) addAccount(number int, lang string) (NewAccount, error) {
 	}
 	b.bytesUploaded += req.ContentLength
 	b.bytesDownloaded += int64(len(by))
+	if resp.StatusCode == http.StatusBadRequest {
+		return newAccount, errors.New("invalid request, account already in lobby ?")
+	}
 	if err := json.Unmarshal(by, &newAccount); err != nil {
 		return newAccount, errors.New(err.Error() + " : " + string(by))
 	}
+	if newAccount.Error != "" {
+		return newAccount, errors.New(newAccount.Error)
+	}
 	return newAccount, nil
 }
 
@@ -4932,6 +5471,7 @@ func (b *OGame) taskRunner() {
 }
 
 func (b *OGame) getCachedCelestial(v interface{}) Celestial {
+	v = b.resolveAlias(v)
 	if celestial, ok := v.(Celestial); ok {
 		return celestial
 	} else if planet, ok := v.(Planet); ok {
@@ -5022,9 +5562,11 @@ func (b *OGame) withPriority(priority int) *Prioritize {
 	task.priority = priority
 	task.canBeProcessedCh = canBeProcessedCh
 	task.isDoneCh = taskIsDoneCh
+	queuedAt := time.Now()
+	taskID := b.taskEnqueued(priority, queuedAt)
 	b.tasksPushCh <- task
 	<-canBeProcessedCh
-	return &Prioritize{bot: b, taskIsDoneCh: taskIsDoneCh}
+	return &Prioritize{bot: b, taskIsDoneCh: taskIsDoneCh, taskID: taskID, priority: priority, queuedAt: queuedAt}
 }
 
 // TasksOverview overview of tasks in heap
@@ -5112,6 +5654,11 @@ func (b *OGame) OnStateChange(clb func(locked bool, actor string)) {
 	b.stateChangeCallbacks = append(b.stateChangeCallbacks, clb)
 }
 
+// OnSessionLost register a callback that is notified every time the bot fails to restore an expired session
+func (b *OGame) OnSessionLost(clb func(attempts int, err error)) {
+	b.sessionLostCallbacks = append(b.sessionLostCallbacks, clb)
+}
+
 // GetState returns the current bot state
 func (b *OGame) GetState() (bool, string) {
 	return atomic.LoadInt32(&b.lockedAtom) == 1, b.state
@@ -5127,6 +5674,29 @@ func (b *OGame) GetSession() string {
 	return b.ogameSession
 }
 
+// SessionCredentials everything needed to talk to the game with an already authenticated session
+type SessionCredentials struct {
+	BearerToken string
+	ServerURL   string
+	Session     string
+	UserAgent   string
+	Cookies     []*http.Cookie
+}
+
+// GetSessionCredentials returns the authenticated cookies and gameforge bearer token
+func (b *OGame) GetSessionCredentials() SessionCredentials {
+	res := SessionCredentials{
+		ServerURL: b.serverURL,
+		Session:   b.ogameSession,
+		UserAgent: b.Client.UserAgent,
+	}
+	res.BearerToken, _ = b.GetBearerToken()
+	if jar, ok := b.Client.Jar.(*cookiejar.Jar); ok {
+		res.Cookies = jar.AllCookies()
+	}
+	return res
+}
+
 // AddAccount add a new account (server) to your list of accounts
 func (b *OGame) AddAccount(number int, lang string) (NewAccount, error) {
 	return b.addAccount(number, lang)
@@ -5170,6 +5740,19 @@ func (b *OGame) GetServerData() ServerData {
 	return b.serverData
 }
 
+// GetCargoHyperspaceTechMultiplier returns the cargo bonus in percent per hyperspace technology level of the server
+func (b *OGame) GetCargoHyperspaceTechMultiplier() int64 {
+	if b.serverData.CargoHyperspaceTechMultiplier > 0 {
+		return b.serverData.CargoHyperspaceTechMultiplier
+	}
+	return defaultCargoHyperspaceTechMultiplier(b.IsPioneers())
+}
+
+// fleetCargo returns the cargo capacity of ships with the cached researches, the class and the server settings
+func (b *OGame) fleetCargo(ships ShipsInfos) int64 {
+	return CargoCapacity(ships, b.getCachedResearch(), b.characterClass, b.server.Settings.EspionageProbeRaids == 1, b.GetCargoHyperspaceTechMultiplier())
+}
+
 // ServerURL get the ogame server specific url
 func (b *OGame) ServerURL() string {
 	return b.serverURL
@@ -5204,6 +5787,12 @@ func (b *OGame) Login() error {
 // Logout the bot from ogame server
 func (b *OGame) Logout() { b.WithPriority(Normal).Logout() }
 
+// SwitchUniverse logs out and logs in the account of the lobby login playing on another universe,
+// the cached state of the previous account is dropped
+func (b *OGame) SwitchUniverse(universe, lang string) error {
+	return b.WithPriority(Normal).SwitchUniverse(universe, lang)
+}
+
 // BytesDownloaded returns the amount of bytes downloaded
 func (b *OGame) BytesDownloaded() int64 {
 	return b.bytesDownloaded
@@ -5285,6 +5874,12 @@ func (b *OGame) PostPageContent(vals, payload url.Values) ([]byte, error) {
 	return b.WithPriority(Normal).PostPageContent(vals, payload)
 }
 
+// PostRawPageContent make a post request to ogame server with an arbitrary body and content type (json, multipart...)
+// and returns the response headers. This is useful when proxying a web browser.
+func (b *OGame) PostRawPageContent(vals url.Values, contentType string, body []byte) ([]byte, http.Header, error) {
+	return b.WithPriority(Normal).PostRawPageContent(vals, contentType, body)
+}
+
 // IsUnderAttack returns true if the user is under attack, false otherwise
 func (b *OGame) IsUnderAttack() (bool, error) {
 	return b.WithPriority(Normal).IsUnderAttack()
@@ -5390,6 +5985,11 @@ func (b *OGame) Abandon(v interface{}) error {
 	return b.WithPriority(Normal).Abandon(v)
 }
 
+// RenamePlanet renames a planet or moon
+func (b *OGame) RenamePlanet(celestialID CelestialID, name string) error {
+	return b.WithPriority(Normal).RenamePlanet(celestialID, name)
+}
+
 // GetCelestial get the player's planet/moon using the coordinate
 func (b *OGame) GetCelestial(v interface{}) (Celestial, error) {
 	return b.WithPriority(Normal).GetCelestial(v)
@@ -5446,6 +6046,16 @@ func (b *OGame) GetAttacks(opts ...Option) ([]AttackEvent, error) {
 	return b.WithPriority(Normal).GetAttacks(opts...)
 }
 
+// GetInactiveTargets returns the planets of the inactive players from fromSystem to toSystem of a galaxy
+func (b *OGame) GetInactiveTargets(galaxy, fromSystem, toSystem int64, filter InactiveTargetsFilter) ([]PlanetInfos, error) {
+	return b.WithPriority(Normal).GetInactiveTargets(galaxy, fromSystem, toSystem, filter)
+}
+
+// FindColonySpots returns the free positions of a galaxy range matching the criteria, safest first
+func (b *OGame) FindColonySpots(galaxyRange GalaxyRange, criteria ColonySpotsCriteria) ([]ColonySpot, error) {
+	return b.WithPriority(Normal).FindColonySpots(galaxyRange, criteria)
+}
+
 // GalaxyInfos get information of all planets and moons of a solar system
 func (b *OGame) GalaxyInfos(galaxy, system int64, options ...Option) (SystemInfos, error) {
 	return b.WithPriority(Normal).GalaxyInfos(galaxy, system, options...)
@@ -5461,6 +6071,11 @@ func (b *OGame) SetResourceSettings(planetID PlanetID, settings ResourceSettings
 	return b.WithPriority(Normal).SetResourceSettings(planetID, settings)
 }
 
+// GetMissingRequirements returns the buildings and research levels missing to build id on the celestial
+func (b *OGame) GetMissingRequirements(celestialID CelestialID, id ID) ([]MissingRequirement, error) {
+	return b.WithPriority(Normal).GetMissingRequirements(celestialID, id)
+}
+
 // GetResourcesBuildings gets the resources buildings levels
 func (b *OGame) GetResourcesBuildings(celestialID CelestialID, options ...Option) (ResourcesBuildings, error) {
 	return b.WithPriority(Normal).GetResourcesBuildings(celestialID, options...)
@@ -5488,6 +6103,11 @@ func (b *OGame) GetProduction(celestialID CelestialID) ([]Quantifiable, int64, e
 	return b.WithPriority(Normal).GetProduction(celestialID)
 }
 
+// GetProductionQueue gets the shipyard queue with the estimated start and end of every order
+func (b *OGame) GetProductionQueue(celestialID CelestialID) (ProductionQueue, error) {
+	return b.WithPriority(Normal).GetProductionQueue(celestialID)
+}
+
 // GetCachedResearch returns cached researches
 func (b *OGame) GetCachedResearch() Researches {
 	return b.WithPriority(Normal).GetCachedResearch()
@@ -5568,12 +6188,60 @@ func (b *OGame) GetResourcesDetails(celestialID CelestialID) (ResourcesDetails,
 	return b.WithPriority(Normal).GetResourcesDetails(celestialID)
 }
 
+// GetBuildRecommendations ranks the mines, plasma technology and astrophysics upgrades by payback time
+func (b *OGame) GetBuildRecommendations() ([]BuildRecommendation, error) {
+	return b.WithPriority(Normal).GetBuildRecommendations()
+}
+
+// GetStorageETA returns the storages of every planet and when they will be full
+func (b *OGame) GetStorageETA() ([]StorageETA, error) {
+	return b.WithPriority(Normal).GetStorageETA()
+}
+
+// SetCharacterClass switches the character class, it costs CharacterClassChangeCost dark matter unless no class is selected
+func (b *OGame) SetCharacterClass(class CharacterClass) error {
+	return b.WithPriority(Normal).SetCharacterClass(class)
+}
+
+// InvalidateCache drops the cached state of the given kinds (every kind if none), see CacheKinds
+func (b *OGame) InvalidateCache(kinds ...CacheKind) error {
+	return b.WithPriority(Normal).InvalidateCache(kinds...)
+}
+
 // GetTechs gets a celestial supplies/facilities/ships/researches
 func (b *OGame) GetTechs(celestialID CelestialID) (ResourcesBuildings, Facilities, ShipsInfos, DefensesInfos, Researches, error) {
 	return b.WithPriority(Normal).GetTechs(celestialID)
 }
 
-// SendFleet sends a fleet
+// SetResourceSettingsAll applies the production percentages to every planet in one transaction
+func (b *OGame) SetResourceSettingsAll(settings ResourceSettings) error {
+	return b.WithPriority(Normal).SetResourceSettingsAll(settings)
+}
+
+// OptimizeResourceSettings computes the production percentages of every planet maximizing the objective under the
+// energy constraint, and applies them if apply is true
+func (b *OGame) OptimizeResourceSettings(objective ProductionObjective, apply bool) (map[PlanetID]ResourceSettings, error) {
+	return b.WithPriority(Normal).OptimizeResourceSettings(objective, apply)
+}
+
+// ExportEmpire builds a complete snapshot of the empire: celestials, resources, buildings, ships, defenses,
+// researches and fleets
+func (b *OGame) ExportEmpire() (EmpireExport, error) {
+	return b.WithPriority(Normal).ExportEmpire()
+}
+
+// ImportEmpire restores the cached planets, researches, player and empire snapshot of an export, e.g. in a bot created
+// with NewNoLogin to analyze the empire offline
+func (b *OGame) ImportEmpire(e EmpireExport) {
+	b.WithPriority(Normal).ImportEmpire(e)
+}
+
+// GetAllTechs gets the supplies/facilities/ships/defenses of every celestial and the researches in one transaction
+func (b *OGame) GetAllTechs() (AllTechs, error) {
+	return b.WithPriority(Normal).GetAllTechs()
+}
+
+// SendFleet sends a fleet. AllAvailable as a ships quantity or a resources amount sends everything available.
 func (b *OGame) SendFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate,
 	mission MissionID, resources Resources, holdingTime, unionID int64) (Fleet, error) {
 	return b.WithPriority(Normal).SendFleet(celestialID, ships, speed, where, mission, resources, holdingTime, unionID)
@@ -5615,6 +6283,11 @@ func (b *OGame) GetExpeditionMessageAt(t time.Time) (ExpeditionMessage, error) {
 	return b.WithPriority(Normal).GetExpeditionMessageAt(t)
 }
 
+// GetExpeditionStats aggregates the outcomes of the expedition messages received after since per day and per slot
+func (b *OGame) GetExpeditionStats(since time.Time) (ExpeditionStatsReport, error) {
+	return b.WithPriority(Normal).GetExpeditionStats(since)
+}
+
 // CollectAllMarketplaceMessages collect all marketplace messages
 func (b *OGame) CollectAllMarketplaceMessages() error {
 	return b.WithPriority(Normal).CollectAllMarketplaceMessages()
@@ -5630,11 +6303,46 @@ func (b *OGame) GetEspionageReportMessages() ([]EspionageReportSummary, error) {
 	return b.WithPriority(Normal).GetEspionageReportMessages()
 }
 
+// SearchMessages searches the inbox messages matching the query
+func (b *OGame) SearchMessages(query MessageQuery) ([]Message, error) {
+	return b.WithPriority(Normal).SearchMessages(query)
+}
+
+// GetCombatReportMessages gets the summary of each combat reports
+func (b *OGame) GetCombatReportMessages() ([]CombatReportSummary, error) {
+	return b.WithPriority(Normal).GetCombatReportMessages()
+}
+
+// GetCombatReportMessagesPage gets the summary of the combat reports on a page of the messages, also returns the number of pages
+func (b *OGame) GetCombatReportMessagesPage(page int64) ([]CombatReportSummary, int64, error) {
+	return b.WithPriority(Normal).GetCombatReportMessagesPage(page)
+}
+
+// DiffLatestEspionageReports compares the two most recent espionage reports of a coordinate
+func (b *OGame) DiffLatestEspionageReports(coord Coordinate) (EspionageReportDiff, error) {
+	return b.WithPriority(Normal).DiffLatestEspionageReports(coord)
+}
+
 // GetEspionageReport gets a detailed espionage report
 func (b *OGame) GetEspionageReport(msgID int64) (EspionageReport, error) {
 	return b.WithPriority(Normal).GetEspionageReport(msgID)
 }
 
+// GetEspionageReportShareKey gets the share key (sr-...) of an espionage report
+func (b *OGame) GetEspionageReportShareKey(msgID int64) (string, error) {
+	return b.WithPriority(Normal).GetEspionageReportShareKey(msgID)
+}
+
+// GetIPMPlan plans the interplanetary missiles attack of the target of an espionage report, see PlanIPMAttack
+func (b *OGame) GetIPMPlan(msgID int64, maxPerWave int64, priorities []ID) (IPMPlan, error) {
+	return b.WithPriority(Normal).GetIPMPlan(msgID, maxPerWave, priorities)
+}
+
+// GetMoonshotPlan plans a moonshot from the ships of the origin, see PlanMoonshot
+func (b *OGame) GetMoonshotPlan(origin CelestialID, destination Coordinate, chance int64, ships []ID) (MoonshotPlan, error) {
+	return b.WithPriority(Normal).GetMoonshotPlan(origin, destination, chance, ships)
+}
+
 // DeleteMessage deletes a message from the mail box
 func (b *OGame) DeleteMessage(msgID int64) error {
 	return b.WithPriority(Normal).DeleteMessage(msgID)
@@ -5645,17 +6353,29 @@ func (b *OGame) DeleteAllMessagesFromTab(tabID int64) error {
 	return b.WithPriority(Normal).DeleteAllMessagesFromTab(tabID)
 }
 
-// GetResourcesProductions gets the planet resources production
+// GetResourcesProductions gets the planet resources production.
+// The boosters counted are the ones active when the overview of the planet was last loaded.
 func (b *OGame) GetResourcesProductions(planetID PlanetID) (Resources, error) {
 	return b.WithPriority(Normal).GetResourcesProductions(planetID)
 }
 
+// AuditProduction compares the computed production/energy of a planet against the values displayed by the game
+func (b *OGame) AuditProduction(planetID PlanetID) (ProductionAudit, error) {
+	return b.WithPriority(Normal).AuditProduction(planetID)
+}
+
 // GetResourcesProductionsLight gets the planet resources production
 func (b *OGame) GetResourcesProductionsLight(resBuildings ResourcesBuildings, researches Researches,
 	resSettings ResourceSettings, temp Temperature) Resources {
 	return b.WithPriority(Normal).GetResourcesProductionsLight(resBuildings, researches, resSettings, temp)
 }
 
+// ValidateFleet runs the pre-flight checks of SendFleet and computes the flight time, fuel and cargo without sending the fleet
+func (b *OGame) ValidateFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate,
+	mission MissionID, resources Resources) (FleetValidation, error) {
+	return b.WithPriority(Normal).ValidateFleet(celestialID, ships, speed, where, mission, resources)
+}
+
 // FlightTime calculate flight time and fuel needed
 func (b *OGame) FlightTime(origin, destination Coordinate, speed Speed, ships ShipsInfos, missionID MissionID) (secs, fuel int64) {
 	return b.WithPriority(Normal).FlightTime(origin, destination, speed, ships, missionID)
@@ -5738,6 +6458,17 @@ func (b *OGame) GetEmpire(celestialType CelestialType) ([]EmpireCelestial, error
 	return b.WithPriority(Normal).GetEmpire(celestialType)
 }
 
+// GetEmpireSnapshot retrieves the empire of all planets and moons (Commander only)
+func (b *OGame) GetEmpireSnapshot() (EmpireSnapshot, error) {
+	return b.WithPriority(Normal).GetEmpireSnapshot()
+}
+
+// SyncEmpire builds the resources/supplies/facilities/ships/defenses of every planet and moon, from the empire pages
+// with a commander (at most 2 requests), celestial by celestial otherwise
+func (b *OGame) SyncEmpire() (EmpireSnapshot, error) {
+	return b.WithPriority(Normal).SyncEmpire()
+}
+
 // GetEmpireJSON retrieves JSON from Empire page (Commander only).
 func (b *OGame) GetEmpireJSON(nbr int64) (interface{}, error) {
 	return b.WithPriority(Normal).GetEmpireJSON(nbr)
@@ -5768,6 +6499,16 @@ func (b *OGame) GetAllResources() (map[CelestialID]Resources, error) {
 	return b.WithPriority(Normal).GetAllResources()
 }
 
+// GetAllItems returns the items inventory and the items active on every celestial, in a single transaction
+func (b *OGame) GetAllItems() (AllItems, error) {
+	return b.WithPriority(Normal).GetAllItems()
+}
+
+// GetLobbyAccounts returns the game accounts of the lobby login, most recently played first
+func (b *OGame) GetLobbyAccounts() ([]LobbyAccount, error) {
+	return b.WithPriority(Normal).GetLobbyAccounts()
+}
+
 // GetTasks return how many tasks are queued in the heap.
 func (b *OGame) GetTasks() TasksOverview {
 	return b.getTasks()
@@ -3,6 +3,8 @@ package ogame
 import (
 	"bytes"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"regexp"
 	"testing"
 	"time"
@@ -1005,6 +1007,10 @@ func TestExtractMarketplaceMessages(t *testing.T) {
 	assert.Equal(t, int64(27), msgs[3].Type)
 	assert.Equal(t, int64(1379), msgs[3].MarketTransactionID)
 	assert.Equal(t, "164ba9f6e5cbfdaa03c061730767d779", msgs[3].Token)
+	assert.Equal(t, "Metal", msgs[3].Item)
+	assert.Equal(t, int64(100), msgs[3].Quantity)
+	assert.Equal(t, "Crystal", msgs[3].PriceResource)
+	assert.Equal(t, int64(60), msgs[3].Price)
 }
 
 func TestExtractEspionageReportMessageIDs(t *testing.T) {
@@ -1018,6 +1024,8 @@ func TestExtractEspionageReportMessageIDs(t *testing.T) {
 	assert.Equal(t, Action, msgs[1].Type)
 	assert.Equal(t, "Space Monitoring", msgs[1].From)
 	assert.Equal(t, Coordinate{4, 117, 9, PlanetType}, msgs[1].Target)
+	assert.Equal(t, int64(0), msgs[0].CounterEspionage)
+	assert.Equal(t, int64(10), msgs[1].CounterEspionage)
 }
 
 func TestExtractEspionageReportMessageIDsLootPercentage(t *testing.T) {
@@ -1026,6 +1034,9 @@ func TestExtractEspionageReportMessageIDsLootPercentage(t *testing.T) {
 	assert.Equal(t, 1.0, msgs[0].LootPercentage)
 	assert.Equal(t, 0.5, msgs[1].LootPercentage)
 	assert.Equal(t, 0.5, msgs[2].LootPercentage)
+	assert.Equal(t, int64(0), msgs[0].CounterEspionage)
+	assert.Equal(t, int64(80), msgs[1].CounterEspionage)
+	assert.Equal(t, int64(0), msgs[2].CounterEspionage)
 }
 
 func TestV71ExtractEspionageReportMessages(t *testing.T) {
@@ -1046,6 +1057,10 @@ func TestExtractCombatReportMessagesV7_Debris(t *testing.T) {
 	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7/combat_reports_debris.html")
 	msgs, _ := NewExtractorV7().ExtractCombatReportMessagesSummary(pageHTMLBytes)
 	assert.Equal(t, int64(2400), msgs[0].DebrisField)
+	assert.Equal(t, "Admiral Castor", msgs[0].AttackerName)
+	assert.Equal(t, int64(0), msgs[0].AttackerLosses)
+	assert.Equal(t, "Procurator Serpentis", msgs[0].DefenderName)
+	assert.Equal(t, int64(4000), msgs[0].DefenderLosses)
 }
 
 func TestExtractCombatReportMessagesV71(t *testing.T) {
@@ -1108,6 +1123,13 @@ func TestExtractResourceSettingsV7(t *testing.T) {
 	assert.Equal(t, ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100, FusionReactor: 0, SolarSatellite: 0, Crawler: 0}, settings)
 }
 
+func TestExtractResourceSettingsV7_CrawlerCount(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7/resource_settings_crawlers.html")
+	settings, _ := NewExtractorV7().ExtractResourceSettings(pageHTMLBytes)
+	assert.Equal(t, int64(1250), settings.CrawlerCount)
+	assert.Equal(t, int64(100), settings.MetalMine)
+}
+
 func TestExtractNbProbes(t *testing.T) {
 	pageHTMLBytes, _ := ioutil.ReadFile("samples/preferences.html")
 	probes := NewExtractorV6().ExtractSpioAnz(pageHTMLBytes)
@@ -2416,6 +2438,60 @@ func TestExtractFleet_unionID(t *testing.T) {
 	assert.Equal(t, int64(13558), fleets[0].UnionID)
 }
 
+func TestExtractFleet_unionIDPartner(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/fleets_union_two.html")
+	fleets := NewExtractorV6().ExtractFleets(pageHTMLBytes, time.FixedZone("OGT", 3600))
+	assert.Equal(t, 2, len(fleets))
+	assert.Equal(t, int64(13558), fleets[0].UnionID)
+	assert.Equal(t, int64(13558), fleets[1].UnionID)
+	assert.Equal(t, fleets[0].TargetPlanetID, fleets[1].TargetPlanetID)
+	assert.Equal(t, HostileFleet, fleets[1].Relation)
+}
+
+func TestExtractFleet_holdingTime(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7.2/en/fleets_expeditions.html")
+	fleets := NewExtractorV6().ExtractFleets(pageHTMLBytes, time.FixedZone("OGT", 3600))
+	assert.True(t, fleets[0].ReturnFlight)
+	assert.Equal(t, int64(0), fleets[0].HoldingTime)
+	assert.False(t, fleets[1].ReturnFlight)
+	assert.Equal(t, int64(3600), fleets[1].HoldingTime)
+	assert.Equal(t, int64(3600), fleets[2].HoldingTime)
+	assert.Equal(t, NeutralFleet, fleets[1].Relation)
+}
+
+func TestExtractFleet_emptyReturnFlight(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/v7.6.7/en/movement.html")
+	fleets := NewExtractorV6().ExtractFleets(pageHTMLBytes, time.FixedZone("OGT", 3600))
+	assert.False(t, fleets[0].ReturnFlight)
+	assert.Equal(t, FleetID(11704403), fleets[0].ID)
+}
+
+func TestExtractFleetsFromEventList_details(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/eventlist_test.html")
+	fleets := NewExtractorV6().ExtractFleetsFromEventList(pageHTMLBytes)
+	assert.Equal(t, 3, len(fleets))
+	assert.True(t, fleets[0].ReturnFlight)
+	assert.False(t, fleets[1].ReturnFlight)
+	assert.Equal(t, Transport, fleets[1].Mission)
+	assert.Equal(t, FriendlyFleet, fleets[1].Relation)
+	assert.Equal(t, Coordinate{4, 208, 8, PlanetType}, fleets[1].Origin)
+	assert.Equal(t, Coordinate{4, 212, 8, PlanetType}, fleets[1].Destination)
+	assert.Equal(t, int64(150), fleets[1].Ships.LargeCargo)
+	assert.Equal(t, Resources{Metal: 166907, Crystal: 73985, Deuterium: 39822}, fleets[1].Resources)
+	assert.Equal(t, time.Unix(1540517902, 0), fleets[1].ArrivalTime)
+
+	pageHTMLBytes, _ = ioutil.ReadFile("samples/eventlist_acs.html")
+	fleets = NewExtractorV6().ExtractFleetsFromEventList(pageHTMLBytes)
+	assert.Equal(t, int64(19205235), fleets[0].UnionID)
+	assert.Equal(t, HostileFleet, fleets[0].Relation)
+	assert.Equal(t, Resources{}, fleets[0].Resources)
+
+	pageHTMLBytes, _ = ioutil.ReadFile("samples/eventlist_harvest.html")
+	fleets = NewExtractorV6().ExtractFleetsFromEventList(pageHTMLBytes)
+	assert.Equal(t, Coordinate{4, 116, 12, MoonType}, fleets[0].Origin)
+	assert.Equal(t, Coordinate{4, 116, 8, DebrisType}, fleets[0].Destination)
+}
+
 func TestExtractOverviewProduction(t *testing.T) {
 	pageHTMLBytes, _ := ioutil.ReadFile("samples/overview_shipyard_queue_full.html")
 	prods, countdown, _ := NewExtractorV6().ExtractOverviewProduction(pageHTMLBytes)
@@ -3250,3 +3326,36 @@ func TestExtractActiveItems(t *testing.T) {
 	assert.Equal(t, int64(579827), items[1].TimeRemaining)
 	assert.Equal(t, "https://s152-en.ogame.gameforge.com/cdn/img/item-images/db408084e3b2b7b0e1fe13d9f234d2ebd76f11c5-small.png", items[1].ImgSmall)
 }
+
+func TestGetSessionCredentials(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	u, _ := url.Parse("https://lobby.ogame.gameforge.com")
+	b.Client.Jar.SetCookies(u, []*http.Cookie{{Name: gfTokenCookieName, Value: "token123", Domain: "gameforge.com", Path: "/"}})
+	creds := b.GetSessionCredentials()
+	assert.Equal(t, "token123", creds.BearerToken)
+	assert.Equal(t, 1, len(creds.Cookies))
+
+	b.SetOGameCredentials("", "", "", "explicit")
+	assert.Equal(t, "explicit", b.GetSessionCredentials().BearerToken)
+}
+
+func TestExtractGalaxyInfos_statusFlags(t *testing.T) {
+	pageHTMLBytes, _ := ioutil.ReadFile("samples/galaxy_inactive_emperor.html")
+	infos, _ := NewExtractorV6().ExtractGalaxyInfos(pageHTMLBytes, "Commodore Nomade", 123, 456)
+	assert.True(t, infos.Position(4).Inactive)
+	assert.True(t, infos.Position(4).LongInactive)
+	assert.False(t, infos.Position(5).Inactive)
+	assert.True(t, infos.Position(5).HonorableTarget)
+
+	pageHTMLBytes, _ = ioutil.ReadFile("samples/v7/galaxy_debris16_2.html")
+	infos, _ = NewExtractorV7().ExtractGalaxyInfos(pageHTMLBytes, "Commodore Nomade", 123, 456)
+	assert.True(t, infos.Position(10).LongInactive)
+	assert.True(t, infos.Position(10).Vacation)
+
+	pageHTMLBytes, _ = ioutil.ReadFile("samples/galaxy_outlaw.html")
+	infos, _ = NewExtractorV6().ExtractGalaxyInfos(pageHTMLBytes, "Commodore Nomade", 123, 456)
+	assert.True(t, infos.Position(8).Inactive)
+	assert.False(t, infos.Position(8).LongInactive)
+	assert.True(t, infos.Position(10).Outlaw)
+	assert.False(t, infos.Position(8).Outlaw)
+}
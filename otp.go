@@ -0,0 +1,59 @@
+package ogame
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Reasons given by the Gameforge sessions endpoint for the second factor
+const (
+	otpRequiredReason = "OTP_REQUIRED"
+	otpInvalidReason  = "OTP_INVALID"
+)
+
+// OTPCallback returns a one-time code of the authenticator app. It is called when the login requires the second
+// factor and neither an OTP secret nor an OTP code is set, eg: to ask the code to the user.
+type OTPCallback func() (string, error)
+
+// SetOTPCode sets a one-time code of the authenticator app, it is used by the next login only
+func (b *OGame) SetOTPCode(code string) {
+	b.otpMu.Lock()
+	defer b.otpMu.Unlock()
+	b.otpCode = strings.TrimSpace(code)
+}
+
+// generateOTPCode returns the current code of a TOTP secret
+func generateOTPCode(secret string) (string, error) {
+	return totp.GenerateCodeCustom(secret, time.Now(), totp.ValidateOpts{
+		Period:    30,
+		Skew:      1,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+}
+
+// takeOTPCode returns the code to send with the login: the one-time code if one is set, which is consumed, or else
+// the current code of the secret. Empty if there is neither.
+func (b *OGame) takeOTPCode(secret string) (string, error) {
+	b.otpMu.Lock()
+	code := b.otpCode
+	b.otpCode = ""
+	b.otpMu.Unlock()
+	if code != "" || secret == "" {
+		return code, nil
+	}
+	return generateOTPCode(secret)
+}
+
+// sessionsErrorReason returns the reason of a failed sessions response, eg: OTP_REQUIRED
+func sessionsErrorReason(by []byte) string {
+	var res struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(by, &res)
+	return res.Reason
+}
@@ -0,0 +1,33 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeOTPCode(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	code, err := b.takeOTPCode("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", code)
+
+	// The one-time code is used once, before the secret
+	b.SetOTPCode(" 123456 ")
+	code, err = b.takeOTPCode("JBSWY3DPEHPK3PXP")
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", code)
+	code, err = b.takeOTPCode("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", code)
+
+	code, err = b.takeOTPCode("JBSWY3DPEHPK3PXP")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(code))
+}
+
+func TestSessionsErrorReason(t *testing.T) {
+	assert.Equal(t, otpRequiredReason, sessionsErrorReason([]byte(`{"reason":"OTP_REQUIRED"}`)))
+	assert.Equal(t, otpInvalidReason, sessionsErrorReason([]byte(`{ "reason": "OTP_INVALID", "message": "invalid code" }`)))
+	assert.Equal(t, "", sessionsErrorReason([]byte(`<html></html>`)))
+}
@@ -0,0 +1,50 @@
+package ogame
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostRawPageContent(t *testing.T) {
+	var gotContentType, gotQuery string
+	var gotBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotQuery = r.URL.RawQuery
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("X-Custom", "1")
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer ts.Close()
+
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.serverURL = ts.URL
+	atomic.StoreInt32(&b.isLoggedInAtom, 1)
+
+	// Recorded AntiGame ajax call using a json body
+	vals := url.Values{"page": {"ingame"}, "component": {"fleetdispatch"}, "action": {"checkTarget"}, "ajax": {"1"}, "asJson": {"1"}}
+	jsonBody := []byte(`{"galaxy":1,"system":2,"position":3,"type":1,"union":0}`)
+	by, headers, err := b.postRawPageContent(vals, "application/json", jsonBody, SkipInterceptor)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"status":"success"}`, string(by))
+	assert.Equal(t, "application/json", gotContentType)
+	assert.Equal(t, vals.Encode(), gotQuery)
+	assert.Equal(t, jsonBody, gotBody)
+	assert.Equal(t, "1", headers.Get("X-Custom"))
+	assert.Equal(t, "application/json; charset=utf-8", headers.Get("Content-Type"))
+
+	// Multipart form
+	multipartContentType := "multipart/form-data; boundary=----WebKitFormBoundary7MA4YWxkTrZu0gW"
+	multipartBody := []byte("------WebKitFormBoundary7MA4YWxkTrZu0gW\r\nContent-Disposition: form-data; name=\"token\"\r\n\r\nabc\r\n------WebKitFormBoundary7MA4YWxkTrZu0gW--\r\n")
+	_, _, err = b.postRawPageContent(url.Values{"page": {"messages"}}, multipartContentType, multipartBody, SkipInterceptor)
+	assert.Nil(t, err)
+	assert.Equal(t, multipartContentType, gotContentType)
+	assert.Equal(t, multipartBody, gotBody)
+}
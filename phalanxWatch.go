@@ -0,0 +1,197 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxPhalanxWatchMovements number of fleet movements kept per phalanx watch
+const MaxPhalanxWatchMovements = 1000
+
+// MinPhalanxWatchInterval minimum interval between two scans of a phalanx watch
+const MinPhalanxWatchInterval = time.Minute
+
+// PhalanxMovement fleet movement seen by a phalanx watch
+type PhalanxMovement struct {
+	Fleet     Fleet
+	Hostile   bool
+	Active    bool // Seen during the last scan
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// PhalanxWatch coordinate scanned from a moon on an interval
+type PhalanxWatch struct {
+	ID             int64
+	MoonID         MoonID
+	Coordinate     Coordinate
+	Interval       time.Duration
+	CreatedAt      time.Time
+	LastScan       time.Time
+	LastError      string `json:",omitempty"`
+	Scans          int64
+	DeuteriumSpent int64
+	Movements      []PhalanxMovement // Oldest first
+}
+
+type phalanxWatch struct {
+	PhalanxWatch
+	cancel context.CancelFunc
+}
+
+func phalanxMovementKey(f Fleet) string {
+	return fmt.Sprintf("%d|%s|%s|%d|%t", f.Mission, f.Origin, f.Destination, f.ArrivalTime.Unix(), f.ReturnFlight)
+}
+
+func isHostileMovement(f Fleet) bool {
+	return isHostileMission(f.Mission) && !f.ReturnFlight
+}
+
+// update records the fleets seen by a scan, returns the hostile fleets that appeared and disappeared since the last scan
+func (w *PhalanxWatch) update(fleets []Fleet, now time.Time) (appeared, disappeared []Fleet) {
+	seen := make(map[string]struct{})
+	idx := make(map[string]int)
+	for i, m := range w.Movements {
+		idx[phalanxMovementKey(m.Fleet)] = i
+	}
+	for _, f := range fleets {
+		key := phalanxMovementKey(f)
+		seen[key] = struct{}{}
+		if i, ok := idx[key]; ok {
+			w.Movements[i].Fleet = f
+			w.Movements[i].LastSeen = now
+			if !w.Movements[i].Active && w.Movements[i].Hostile {
+				appeared = append(appeared, f)
+			}
+			w.Movements[i].Active = true
+			continue
+		}
+		m := PhalanxMovement{Fleet: f, Hostile: isHostileMovement(f), Active: true, FirstSeen: now, LastSeen: now}
+		idx[key] = len(w.Movements)
+		w.Movements = append(w.Movements, m)
+		if m.Hostile {
+			appeared = append(appeared, f)
+		}
+	}
+	for i, m := range w.Movements {
+		if _, ok := seen[phalanxMovementKey(m.Fleet)]; !ok && m.Active {
+			w.Movements[i].Active = false
+			if m.Hostile {
+				disappeared = append(disappeared, m.Fleet)
+			}
+		}
+	}
+	if len(w.Movements) > MaxPhalanxWatchMovements {
+		w.Movements = w.Movements[len(w.Movements)-MaxPhalanxWatchMovements:]
+	}
+	return
+}
+
+// AddPhalanxWatch scans coord from the moon every interval, hostile fleets appearing/disappearing emit
+// phalanx.fleet_appeared/phalanx.fleet_disappeared events. The coordinate must be in the phalanx range.
+func (b *OGame) AddPhalanxWatch(moonID MoonID, coord Coordinate, interval time.Duration) (PhalanxWatch, error) {
+	if interval < MinPhalanxWatchInterval {
+		return PhalanxWatch{}, fmt.Errorf("interval must be at least %s", MinPhalanxWatchInterval)
+	}
+	moon := b.GetCachedCelestialByID(moonID.Celestial())
+	if moon == nil || !moon.GetCoordinate().IsMoon() {
+		return PhalanxWatch{}, errors.New("moon not found")
+	}
+	facilities, err := b.GetFacilities(moonID.Celestial())
+	if err != nil {
+		return PhalanxWatch{}, err
+	}
+	if !b.isInPhalanxRange(moon.GetCoordinate(), facilities.SensorPhalanx, coord) {
+		return PhalanxWatch{}, ErrNotInPhalanxRange
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.phalanxWatchesMu.Lock()
+	if b.phalanxWatches == nil {
+		b.phalanxWatches = make(map[int64]*phalanxWatch)
+	}
+	b.phalanxWatchNextID++
+	w := &phalanxWatch{cancel: cancel}
+	w.ID = b.phalanxWatchNextID
+	w.MoonID = moonID
+	w.Coordinate = coord
+	w.Interval = interval
+	w.CreatedAt = time.Now()
+	w.Movements = make([]PhalanxMovement, 0)
+	b.phalanxWatches[w.ID] = w
+	watch := w.PhalanxWatch
+	b.phalanxWatchesMu.Unlock()
+	go b.runPhalanxWatch(ctx, w)
+	return watch, nil
+}
+
+// RemovePhalanxWatch stops and removes a phalanx watch
+func (b *OGame) RemovePhalanxWatch(id int64) error {
+	b.phalanxWatchesMu.Lock()
+	defer b.phalanxWatchesMu.Unlock()
+	w, ok := b.phalanxWatches[id]
+	if !ok {
+		return errors.New("phalanx watch not found")
+	}
+	w.cancel()
+	delete(b.phalanxWatches, id)
+	return nil
+}
+
+// GetPhalanxWatches returns the phalanx watches sorted by id
+func (b *OGame) GetPhalanxWatches() []PhalanxWatch {
+	b.phalanxWatchesMu.RLock()
+	defer b.phalanxWatchesMu.RUnlock()
+	res := make([]PhalanxWatch, 0, len(b.phalanxWatches))
+	for _, w := range b.phalanxWatches {
+		watch := w.PhalanxWatch
+		watch.Movements = append([]PhalanxMovement{}, w.Movements...)
+		res = append(res, watch)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+func (b *OGame) runPhalanxWatch(ctx context.Context, w *phalanxWatch) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if b.isEnabled() {
+			b.scanPhalanxWatch(ctx, w)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *OGame) scanPhalanxWatch(ctx context.Context, w *phalanxWatch) {
+	fleets, err := b.WithPriority(Low).Phalanx(w.MoonID, w.Coordinate)
+	if ctx.Err() != nil {
+		return
+	}
+	now := time.Now()
+	b.phalanxWatchesMu.Lock()
+	w.LastScan = now
+	if err != nil {
+		w.LastError = err.Error()
+		b.phalanxWatchesMu.Unlock()
+		b.warn("phalanx watch ", w.ID, " failed: ", err)
+		return
+	}
+	w.LastError = ""
+	w.Scans++
+	w.DeuteriumSpent += SensorPhalanx.ScanConsumption()
+	appeared, disappeared := w.update(fleets, now)
+	b.phalanxWatchesMu.Unlock()
+	for _, f := range appeared {
+		b.emitEvent(NewPhalanxFleetEvent(PhalanxFleetAppearedEvent, w.ID, w.MoonID, w.Coordinate, f))
+	}
+	for _, f := range disappeared {
+		b.emitEvent(NewPhalanxFleetEvent(PhalanxFleetDisappearedEvent, w.ID, w.MoonID, w.Coordinate, f))
+	}
+}
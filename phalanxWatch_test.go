@@ -0,0 +1,35 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhalanxWatch_update(t *testing.T) {
+	now := time.Now()
+	attack := Fleet{Mission: Attack, Origin: Coordinate{1, 2, 3, PlanetType}, Destination: Coordinate{1, 5, 6, PlanetType}, ArrivalTime: now.Add(time.Hour)}
+	transport := Fleet{Mission: Transport, Origin: Coordinate{1, 5, 6, PlanetType}, Destination: Coordinate{1, 2, 3, PlanetType}, ArrivalTime: now.Add(time.Hour)}
+	returning := attack
+	returning.ReturnFlight = true
+	w := &PhalanxWatch{}
+
+	appeared, disappeared := w.update([]Fleet{attack, transport, returning}, now)
+	assert.Equal(t, []Fleet{attack}, appeared)
+	assert.Nil(t, disappeared)
+	assert.Equal(t, 3, len(w.Movements))
+
+	appeared, disappeared = w.update([]Fleet{attack, transport}, now.Add(time.Minute))
+	assert.Nil(t, appeared)
+	assert.Nil(t, disappeared)
+	assert.False(t, w.Movements[2].Active)
+	assert.Equal(t, now.Add(time.Minute), w.Movements[0].LastSeen)
+	assert.Equal(t, now, w.Movements[0].FirstSeen)
+
+	appeared, disappeared = w.update([]Fleet{transport}, now.Add(2*time.Minute))
+	assert.Nil(t, appeared)
+	assert.Equal(t, []Fleet{attack}, disappeared)
+	assert.False(t, w.Movements[0].Active)
+	assert.Equal(t, 3, len(w.Movements))
+}
@@ -68,6 +68,30 @@ func (s SystemInfos) MarshalJSON() ([]byte, error) {
 	return json.Marshal(tmp)
 }
 
+// UnmarshalJSON reads the private fields exported by MarshalJSON
+func (s *SystemInfos) UnmarshalJSON(data []byte) error {
+	var tmp struct {
+		Galaxy           int64
+		System           int64
+		Planets          [15]*PlanetInfos
+		ExpeditionDebris struct {
+			Metal             int64
+			Crystal           int64
+			PathfindersNeeded int64
+		}
+	}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	s.galaxy = tmp.Galaxy
+	s.system = tmp.System
+	s.planets = tmp.Planets
+	s.ExpeditionDebris.Metal = tmp.ExpeditionDebris.Metal
+	s.ExpeditionDebris.Crystal = tmp.ExpeditionDebris.Crystal
+	s.ExpeditionDebris.PathfindersNeeded = tmp.ExpeditionDebris.PathfindersNeeded
+	return nil
+}
+
 // MoonInfos public information of a moon in the galaxy page
 type MoonInfos struct {
 	ID       int64
@@ -92,12 +116,14 @@ type PlanetInfos struct {
 	Coordinate      Coordinate
 	Administrator   bool
 	Destroyed       bool
-	Inactive        bool
+	Inactive        bool // Inactive for 7 days or more (also true for long inactive players)
+	LongInactive    bool // Inactive for 28 days or more
 	Vacation        bool
 	StrongPlayer    bool
 	Newbie          bool
 	HonorableTarget bool
 	Banned          bool
+	Outlaw          bool
 	Debris          struct {
 		Metal           int64
 		Crystal         int64
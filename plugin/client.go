@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"reflect"
+
+	"github.com/alaingilbert/ogame"
+	hclog "github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Manifest operator settings of a plugin, read from the <executable>.json file next to the plugin executable
+type Manifest struct {
+	// Methods ogame.Wrapper methods the plugin may call (eg: "GetResources"), "*" for all of them, none if empty
+	Methods []string `json:"methods"`
+}
+
+// LoadManifest reads the manifest of the plugin executable at path, a plugin without manifest cannot call the bot
+func LoadManifest(path string) (Manifest, error) {
+	var manifest Manifest
+	by, err := ioutil.ReadFile(path + ".json")
+	if os.IsNotExist(err) {
+		return manifest, nil
+	} else if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(by, &manifest); err != nil {
+		return manifest, fmt.Errorf("plugin %s manifest: %v", path, err)
+	}
+	for _, method := range manifest.Methods {
+		if _, ok := wrapperType.MethodByName(method); !ok && method != "*" {
+			return manifest, fmt.Errorf("plugin %s manifest: unknown method %s", path, method)
+		}
+	}
+	return manifest, nil
+}
+
+// Client ogamed side of a started plugin
+type Client struct {
+	Path     string
+	Info     Info
+	Manifest Manifest
+
+	client *goplugin.Client
+	plugin *rpcPlugin
+}
+
+// Start starts the plugin executable at path, serves bot to it, limited to the methods of the manifest, and calls
+// its Init. The plugin stderr is written to stderr.
+func Start(path string, manifest Manifest, bot ogame.Wrapper, stderr io.Writer) (*Client, error) {
+	c := &Client{Path: path, Manifest: manifest}
+	c.client = goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          pluginSet(nil),
+		Cmd:              exec.Command(path),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolNetRPC},
+		Stderr:           stderr,
+		// The plugin stderr is already written to stderr, only the errors of go-plugin are logged
+		Logger: hclog.New(&hclog.LoggerOptions{Name: "plugin", Output: stderr, Level: hclog.Error}),
+	})
+	rpcClient, err := c.client.Client()
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("plugin %s: %v", path, err)
+	}
+	raw, err := rpcClient.Dispense(pluginName)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("plugin %s: %v", path, err)
+	}
+	c.plugin = raw.(*rpcPlugin)
+	allowed := make(map[string]bool)
+	for _, method := range manifest.Methods {
+		allowed[method] = true
+	}
+	if c.Info, err = c.plugin.Init(&botService{bot: bot, allowed: allowed}); err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("plugin %s init: %v", path, err)
+	}
+	if c.Info.Name == "" {
+		c.Kill()
+		return nil, errors.New("plugin " + path + " has no name")
+	}
+	return c, nil
+}
+
+// OnEvent sends an event to the plugin
+func (c *Client) OnEvent(e ogame.Event) error {
+	return c.plugin.OnEvent(e)
+}
+
+// ServeHTTP forwards a request to the plugin
+func (c *Client) ServeHTTP(req Request) (Response, error) {
+	return c.plugin.ServeHTTP(req)
+}
+
+// RunTask runs a task of the plugin
+func (c *Client) RunTask(name string) error {
+	return c.plugin.RunTask(name)
+}
+
+// Kill stops the plugin
+func (c *Client) Kill() {
+	c.client.Kill()
+}
+
+// rpcPlugin ogamed side of the plugin rpc service
+type rpcPlugin struct {
+	client *rpc.Client
+	broker *goplugin.MuxBroker
+}
+
+// Init serves bot on a broker connection and calls the plugin Init with it
+func (p *rpcPlugin) Init(bot *botService) (Info, error) {
+	botID := p.broker.NextId()
+	go p.broker.AcceptAndServe(botID, bot)
+	var info Info
+	err := p.client.Call("Plugin.Init", botID, &info)
+	return info, err
+}
+
+// OnEvent sends the event JSON encoded, its Data holds values of any type
+func (p *rpcPlugin) OnEvent(e ogame.Event) error {
+	by, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return p.client.Call("Plugin.OnEvent", by, &Empty{})
+}
+
+func (p *rpcPlugin) ServeHTTP(req Request) (Response, error) {
+	var resp Response
+	err := p.client.Call("Plugin.ServeHTTP", req, &resp)
+	return resp, err
+}
+
+func (p *rpcPlugin) RunTask(name string) error {
+	return p.client.Call("Plugin.RunTask", name, &Empty{})
+}
+
+var (
+	wrapperType = reflect.TypeOf((*ogame.Wrapper)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// botService rpc service the plugins call the bot with
+type botService struct {
+	bot     ogame.Wrapper
+	allowed map[string]bool // Methods of the manifest
+}
+
+// Call calls a ogame.Wrapper method allowed by the manifest with JSON encoded arguments
+func (s *botService) Call(args CallArgs, reply *CallReply) (err error) {
+	if _, ok := wrapperType.MethodByName(args.Method); !ok {
+		return errors.New("unknown method " + args.Method)
+	}
+	if !s.allowed["*"] && !s.allowed[args.Method] {
+		return errors.New("method " + args.Method + " not allowed by the plugin manifest")
+	}
+	method := reflect.ValueOf(s.bot).MethodByName(args.Method)
+	mt := method.Type()
+	if (!mt.IsVariadic() && len(args.Args) != mt.NumIn()) || (mt.IsVariadic() && len(args.Args) < mt.NumIn()-1) {
+		return fmt.Errorf("%s expects %d arguments, got %d", args.Method, mt.NumIn(), len(args.Args))
+	}
+	in := make([]reflect.Value, 0, len(args.Args))
+	for i, arg := range args.Args {
+		var t reflect.Type
+		if mt.IsVariadic() && i >= mt.NumIn()-1 {
+			t = mt.In(mt.NumIn() - 1).Elem()
+		} else {
+			t = mt.In(i)
+		}
+		switch t.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			return fmt.Errorf("%s: %s arguments are not supported", args.Method, t)
+		}
+		v := reflect.New(t)
+		if err := json.Unmarshal(arg, v.Interface()); err != nil {
+			return fmt.Errorf("%s argument %d: %v", args.Method, i+1, err)
+		}
+		in = append(in, v.Elem())
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %v", args.Method, r)
+		}
+	}()
+	for i, v := range method.Call(in) {
+		if mt.Out(i) == errorType {
+			if e, _ := v.Interface().(error); e != nil {
+				reply.Error = e.Error()
+			}
+			continue
+		}
+		by, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Errorf("%s result %d: %v", args.Method, i+1, err)
+		}
+		reply.Results = append(reply.Results, by)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package plugin lets external programs extend ogamed with event hooks, HTTP routes and scheduled tasks.
+//
+// A plugin is an executable started by ogamed with hashicorp/go-plugin. It calls Serve with its implementation,
+// ogamed checks the Handshake (magic cookie and protocol version) and then calls it over net/rpc (the plugin stdout
+// is reserved for the handshake, its logs must go to stderr). The plugin reaches the bot through the Bot given to
+// Init, limited to the methods listed in the <executable>.json manifest next to the executable.
+//
+//	func main() {
+//		plugin.Serve(&farmer{})
+//	}
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/rpc"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// ProtocolVersion version of the protocol between ogamed and its plugins, a plugin built for another version is
+// refused by the handshake
+const ProtocolVersion = 1
+
+// Handshake shared by ogamed and the plugins. The magic cookie makes Serve refuse to run when not started by ogamed.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  ProtocolVersion,
+	MagicCookieKey:   "OGAMED_PLUGIN",
+	MagicCookieValue: "ogamed-plugin-v1",
+}
+
+// pluginName name the plugin is dispensed under
+const pluginName = "ogamed"
+
+// Route HTTP route of a plugin, served by ogamed under /plugins/<plugin name><Path>
+type Route struct {
+	Method string
+	Path   string // Echo path (eg: /targets/:id)
+}
+
+// Task task of a plugin, run by ogamed every Every
+type Task struct {
+	Name  string
+	Every time.Duration
+}
+
+// Info what the plugin hooks into, returned by Init
+type Info struct {
+	Name    string
+	Version string
+	Events  []ogame.EventType // Events sent to OnEvent
+	Routes  []Route
+	Tasks   []Task
+}
+
+// Request HTTP request forwarded to a plugin
+type Request struct {
+	Method string
+	Path   string // Path without the /plugins/<plugin name> prefix
+	Params map[string]string
+	Query  map[string][]string
+	Header http.Header // Without the credentials of the ogamed API (Authorization, X-API-Key, Cookie)
+	Body   []byte
+}
+
+// Response HTTP response of a plugin
+type Response struct {
+	Status int // 200 if 0
+	Header http.Header
+	Body   []byte
+}
+
+// Plugin implemented by the plugins
+type Plugin interface {
+	Init(bot *Bot) (Info, error)
+	OnEvent(e ogame.Event) error
+	ServeHTTP(req Request) (Response, error)
+	RunTask(name string) error
+}
+
+// Empty argument or reply of the calls without one
+type Empty struct{}
+
+// CallArgs call of a bot method, the arguments are JSON encoded
+type CallArgs struct {
+	Method string
+	Args   []json.RawMessage
+}
+
+// CallReply results of a bot method call, the error result is in Error
+type CallReply struct {
+	Results []json.RawMessage
+	Error   string
+}
+
+// Bot calls the ogame.Wrapper methods of the bot of ogamed
+type Bot struct {
+	client *rpc.Client
+}
+
+// Call calls the bot method with args and decodes its results, the error result excluded, in results.
+// eg: bot.Call("GetResources", []interface{}{planetID}, &resources)
+func (b *Bot) Call(method string, args []interface{}, results ...interface{}) error {
+	callArgs := CallArgs{Method: method, Args: make([]json.RawMessage, 0, len(args))}
+	for _, arg := range args {
+		by, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		callArgs.Args = append(callArgs.Args, by)
+	}
+	var reply CallReply
+	// The broker serves the bot under the "Plugin" name
+	if err := b.client.Call("Plugin.Call", callArgs, &reply); err != nil {
+		return err
+	}
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+	for i, res := range results {
+		if i >= len(reply.Results) {
+			break
+		}
+		if err := json.Unmarshal(reply.Results[i], res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ogamedPlugin go-plugin definition of the plugins, impl is only set on the plugin side
+type ogamedPlugin struct {
+	impl Plugin
+}
+
+// Server ...
+func (p *ogamedPlugin) Server(broker *goplugin.MuxBroker) (interface{}, error) {
+	return &server{impl: p.impl, broker: broker}, nil
+}
+
+// Client ...
+func (p *ogamedPlugin) Client(broker *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &rpcPlugin{client: c, broker: broker}, nil
+}
+
+func pluginSet(impl Plugin) goplugin.PluginSet {
+	return goplugin.PluginSet{pluginName: &ogamedPlugin{impl: impl}}
+}
+
+// server rpc service of the plugin side
+type server struct {
+	impl   Plugin
+	broker *goplugin.MuxBroker
+}
+
+// Init connects to the bot served by ogamed on the broker connection botID
+func (s *server) Init(botID uint32, reply *Info) (err error) {
+	conn, err := s.broker.Dial(botID)
+	if err != nil {
+		return err
+	}
+	*reply, err = s.impl.Init(&Bot{client: rpc.NewClient(conn)})
+	return err
+}
+
+// OnEvent receives the JSON encoded event, its Data holds the JSON values of the events schema
+func (s *server) OnEvent(event []byte, _ *Empty) error {
+	var e ogame.Event
+	if err := json.Unmarshal(event, &e); err != nil {
+		return err
+	}
+	return s.impl.OnEvent(e)
+}
+
+// ServeHTTP ...
+func (s *server) ServeHTTP(req Request, reply *Response) (err error) {
+	*reply, err = s.impl.ServeHTTP(req)
+	return err
+}
+
+// RunTask ...
+func (s *server) RunTask(name string, _ *Empty) error {
+	return s.impl.RunTask(name)
+}
+
+// Serve serves the plugin to ogamed until ogamed stops it, it exits when not started by ogamed
+func Serve(impl Plugin) {
+	goplugin.Serve(&goplugin.ServeConfig{HandshakeConfig: Handshake, Plugins: pluginSet(impl)})
+}
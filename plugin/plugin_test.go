@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alaingilbert/ogame"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+// helperEnv makes the test binary serve testPlugin, with the given protocol version, when started as a plugin
+const helperEnv = "OGAMED_PLUGIN_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	if version := os.Getenv(helperEnv); version != "" {
+		handshake := Handshake
+		if version == "2" {
+			handshake.ProtocolVersion = 2
+		}
+		goplugin.Serve(&goplugin.ServeConfig{HandshakeConfig: handshake, Plugins: pluginSet(&testPlugin{})})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeBot bot of the tests, only GetResources is implemented
+type fakeBot struct {
+	ogame.Wrapper
+}
+
+func (fakeBot) GetResources(celestialID ogame.CelestialID) (ogame.Resources, error) {
+	return ogame.Resources{Metal: int64(celestialID)}, nil
+}
+
+// testPlugin calls the bot method named by the request path and replies with its results or error
+type testPlugin struct {
+	bot    *Bot
+	events chan ogame.Event
+}
+
+func (p *testPlugin) Init(bot *Bot) (Info, error) {
+	p.bot = bot
+	return Info{Name: "test", Version: "1.0", Events: []ogame.EventType{"attack.started"}}, nil
+}
+
+func (p *testPlugin) OnEvent(e ogame.Event) error {
+	p.events <- e
+	return nil
+}
+
+func (p *testPlugin) ServeHTTP(req Request) (Response, error) {
+	var res ogame.Resources
+	if err := p.bot.Call(req.Path, []interface{}{123}, &res); err != nil {
+		return Response{Status: 403, Body: []byte(err.Error())}, nil
+	}
+	by, _ := json.Marshal(res)
+	return Response{Body: by}, nil
+}
+
+func (p *testPlugin) RunTask(name string) error {
+	return nil
+}
+
+func TestPluginRPC(t *testing.T) {
+	impl := &testPlugin{events: make(chan ogame.Event, 1)}
+	client, _ := goplugin.TestPluginRPCConn(t, pluginSet(impl), nil)
+	defer client.Close()
+	raw, err := client.Dispense(pluginName)
+	assert.NoError(t, err)
+	p := raw.(*rpcPlugin)
+
+	info, err := p.Init(&botService{bot: fakeBot{}, allowed: map[string]bool{"GetResources": true}})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", info.Name)
+	assert.Equal(t, []ogame.EventType{"attack.started"}, info.Events)
+
+	// The plugin calls the bot back over the broker
+	resp, err := p.ServeHTTP(Request{Path: "GetResources"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Metal":123,"Crystal":0,"Deuterium":0,"Energy":0,"Darkmatter":0}`, string(resp.Body))
+	resp, _ = p.ServeHTTP(Request{Path: "GetPlanets"})
+	assert.Equal(t, 403, resp.Status)
+	assert.Equal(t, "method GetPlanets not allowed by the plugin manifest", string(resp.Body))
+
+	// The event data is sent with its JSON schema
+	now := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, p.OnEvent(ogame.Event{SchemaVersion: 1, Type: "attack.started", Time: now, Data: ogame.EventCoordinate{Galaxy: 1}}))
+	e := <-impl.events
+	assert.Equal(t, ogame.EventType("attack.started"), e.Type)
+	assert.True(t, now.Equal(e.Time))
+	assert.Equal(t, float64(1), e.Data.(map[string]interface{})["galaxy"])
+}
+
+func TestStart(t *testing.T) {
+	_ = os.Setenv(helperEnv, "1")
+	defer os.Unsetenv(helperEnv)
+	c, err := Start(os.Args[0], Manifest{Methods: []string{"*"}}, fakeBot{}, ioutil.Discard)
+	assert.NoError(t, err)
+	defer c.Kill()
+	assert.Equal(t, "test", c.Info.Name)
+	resp, err := c.ServeHTTP(Request{Path: "GetResources"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, resp.Status)
+}
+
+func TestStart_ProtocolVersionMismatch(t *testing.T) {
+	_ = os.Setenv(helperEnv, "2")
+	defer os.Unsetenv(helperEnv)
+	_, err := Start(os.Args[0], Manifest{}, fakeBot{}, ioutil.Discard)
+	assert.Error(t, err)
+}
+
+func TestBotService_Call(t *testing.T) {
+	s := &botService{bot: fakeBot{}, allowed: map[string]bool{"GetResources": true}}
+	var reply CallReply
+	assert.NoError(t, s.Call(CallArgs{Method: "GetResources", Args: []json.RawMessage{[]byte("7")}}, &reply))
+	assert.Equal(t, `{"Metal":7,"Crystal":0,"Deuterium":0,"Energy":0,"Darkmatter":0}`, string(reply.Results[0]))
+	assert.EqualError(t, s.Call(CallArgs{Method: "GetPlanets"}, &reply), "method GetPlanets not allowed by the plugin manifest")
+	assert.EqualError(t, s.Call(CallArgs{Method: "Unknown"}, &reply), "unknown method Unknown")
+	assert.Error(t, s.Call(CallArgs{Method: "GetResources"}, &reply))
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "plugins")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "farmer")
+
+	manifest, err := LoadManifest(path)
+	assert.NoError(t, err)
+	assert.Empty(t, manifest.Methods)
+
+	_ = ioutil.WriteFile(path+".json", []byte(`{"methods": ["GetResources", "*"]}`), 0600)
+	manifest, err = LoadManifest(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GetResources", "*"}, manifest.Methods)
+
+	_ = ioutil.WriteFile(path+".json", []byte(`{"methods": ["DeleteEverything"]}`), 0600)
+	_, err = LoadManifest(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package ogame
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// PriceCalculatorParams facilities, researches and server settings the price calculations depend on
+type PriceCalculatorParams struct {
+	Facilities         Facilities  // Robotics factory, nanite factory, shipyard and research lab levels
+	Researches         Researches  // Energy technology, for the fusion reactor production
+	Temperature        Temperature // Planet temperature, for the solar satellites production
+	UniverseSpeed      int64       // 1 if <= 0
+	HasTechnocrat      bool
+	HasEngineer        bool
+	HasCommandingStaff bool // All the officers are hired
+	IsDiscoverer       bool
+	IsCollector        bool
+	From               *int64 // Current level of the building/research, the cumulative values go from From+1 to the wanted level. level-1 if nil.
+}
+
+// PriceCalculation cost, energy and construction time of a building/research level, or of a number of ships/defenses
+type PriceCalculation struct {
+	ID                         ID
+	Name                       string
+	Nbr                        int64     // Wanted level of the building/research, or number of ships/defenses
+	From                       int64     // Current level of the building/research
+	Price                      Resources // Price of the level, or of the units
+	ConstructionTime           int64     // Seconds to build the level, or the units
+	CumulativePrice            Resources // Price of the levels From+1 to Nbr
+	CumulativeConstructionTime int64     // Seconds to build the levels From+1 to Nbr
+	EnergyDelta                int64     // Energy produced (positive) or consumed (negative) at Nbr compared to From
+
+	MissingRequirements []MissingRequirement `json:",omitempty"` // Levels missing to build it, filled by the callers knowing the celestial
+}
+
+// CalculatePrice returns the cost, energy delta and construction time of level nbr of a building/research, or of nbr
+// ships/defenses. For buildings and researches, the cumulative cost and time are computed from level params.From.
+func CalculatePrice(id ID, nbr int64, params PriceCalculatorParams) (PriceCalculation, error) {
+	obj := Objs.ByID(id)
+	if obj == nil {
+		return PriceCalculation{}, errors.New("invalid ogame id")
+	}
+	if nbr < 1 {
+		return PriceCalculation{}, errors.New("invalid nbr")
+	}
+	universeSpeed := params.UniverseSpeed
+	if universeSpeed <= 0 {
+		universeSpeed = 1
+	}
+	constructionTime := func(nbr int64) int64 {
+		return int64(obj.ConstructionTime(nbr, universeSpeed, params.Facilities, params.HasTechnocrat, params.IsDiscoverer).Seconds())
+	}
+	res := PriceCalculation{
+		ID:               id,
+		Name:             obj.GetName(),
+		Nbr:              nbr,
+		Price:            obj.GetPrice(nbr),
+		ConstructionTime: constructionTime(nbr),
+	}
+	if !id.IsBuilding() && !id.IsTech() {
+		res.CumulativePrice = res.Price
+		res.CumulativeConstructionTime = res.ConstructionTime
+		res.EnergyDelta = energyDelta(id, 0, nbr, params)
+		return res, nil
+	}
+	res.From = nbr - 1
+	if params.From != nil {
+		res.From = *params.From
+	}
+	if res.From < 0 || res.From >= nbr {
+		return PriceCalculation{}, errors.New("from must be between 0 and nbr-1")
+	}
+	for lvl := res.From + 1; lvl <= nbr; lvl++ {
+		res.CumulativePrice = res.CumulativePrice.Add(obj.GetPrice(lvl))
+		res.CumulativeConstructionTime += constructionTime(lvl)
+	}
+	res.EnergyDelta = energyDelta(id, res.From, nbr, params)
+	return res, nil
+}
+
+// energyDelta returns the energy produced (positive) or consumed (negative) at level/number to compared to from.
+// The energy produced includes the officers and class bonuses.
+func energyDelta(id ID, from, to int64, params PriceCalculatorParams) int64 {
+	officers := Officers{Engineer: params.HasEngineer}
+	if params.HasCommandingStaff {
+		officers = Officers{Commander: true, Admiral: true, Engineer: true, Geologist: true, Technocrat: true}
+	}
+	// The collector bonus of the solar satellites is included in their production
+	bonus := EnergyProductionBonus(officers, NoClass)
+	if params.IsCollector && id != SolarSatelliteID {
+		bonus += CollectorEnergyBonus
+	}
+	withBonus := func(energy int64) int64 {
+		return energy + int64(math.Round(float64(energy)*bonus))
+	}
+	switch id {
+	case MetalMineID:
+		return MetalMine.EnergyConsumption(from) - MetalMine.EnergyConsumption(to)
+	case CrystalMineID:
+		return CrystalMine.EnergyConsumption(from) - CrystalMine.EnergyConsumption(to)
+	case DeuteriumSynthesizerID:
+		return DeuteriumSynthesizer.EnergyConsumption(from) - DeuteriumSynthesizer.EnergyConsumption(to)
+	case SolarPlantID:
+		return withBonus(SolarPlant.Production(to)) - withBonus(SolarPlant.Production(from))
+	case FusionReactorID:
+		energyTechnology := params.Researches.EnergyTechnology
+		return withBonus(FusionReactor.Production(energyTechnology, to)) - withBonus(FusionReactor.Production(energyTechnology, from))
+	case SolarSatelliteID:
+		return withBonus(SolarSatellite.Production(params.Temperature, to, params.IsCollector)) - withBonus(SolarSatellite.Production(params.Temperature, from, params.IsCollector))
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package ogame
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculatePrice(t *testing.T) {
+	params := PriceCalculatorParams{Facilities: Facilities{RoboticsFactory: 10, NaniteFactory: 2}, UniverseSpeed: 1}
+	res, err := CalculatePrice(MetalMineID, 20, params)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(19), res.From)
+	assert.Equal(t, MetalMine.GetPrice(20), res.Price)
+	assert.Equal(t, res.Price, res.CumulativePrice)
+	assert.Equal(t, int64(MetalMine.ConstructionTime(20, 1, params.Facilities, false, false).Seconds()), res.ConstructionTime)
+	assert.Equal(t, MetalMine.EnergyConsumption(19)-MetalMine.EnergyConsumption(20), res.EnergyDelta)
+
+	from := int64(17)
+	params.From = &from
+	res, err = CalculatePrice(MetalMineID, 20, params)
+	assert.NoError(t, err)
+	assert.Equal(t, MetalMine.GetPrice(18).Add(MetalMine.GetPrice(19)).Add(MetalMine.GetPrice(20)), res.CumulativePrice)
+	assert.True(t, res.CumulativeConstructionTime > res.ConstructionTime)
+	assert.Equal(t, MetalMine.EnergyConsumption(17)-MetalMine.EnergyConsumption(20), res.EnergyDelta)
+
+	from = 0
+	res, err = CalculatePrice(MetalMineID, 3, params)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), res.From)
+	assert.Equal(t, MetalMine.GetPrice(1).Add(MetalMine.GetPrice(2)).Add(MetalMine.GetPrice(3)), res.CumulativePrice)
+
+	from = 20
+	_, err = CalculatePrice(MetalMineID, 20, params)
+	assert.Error(t, err)
+
+	params.From = nil
+	params.Researches.EnergyTechnology = 12
+	res, _ = CalculatePrice(FusionReactorID, 10, params)
+	assert.Equal(t, FusionReactor.Production(12, 10)-FusionReactor.Production(12, 9), res.EnergyDelta)
+
+	params.Temperature = Temperature{Min: 10, Max: 50}
+	res, _ = CalculatePrice(SolarSatelliteID, 100, params)
+	assert.Equal(t, int64(0), res.From)
+	assert.Equal(t, SolarSatellite.GetPrice(100), res.CumulativePrice)
+	assert.Equal(t, SolarSatellite.Production(params.Temperature, 100, false), res.EnergyDelta)
+
+	_, err = CalculatePrice(ID(9999), 1, params)
+	assert.Error(t, err)
+	_, err = CalculatePrice(MetalMineID, 0, params)
+	assert.Error(t, err)
+}
+
+func TestCalculatePrice_EnergyBonuses(t *testing.T) {
+	params := PriceCalculatorParams{UniverseSpeed: 1, HasEngineer: true}
+	res, _ := CalculatePrice(SolarPlantID, 20, params)
+	withBonus := func(energy int64, bonus float64) int64 {
+		return energy + int64(math.Round(float64(energy)*bonus))
+	}
+	assert.Equal(t, withBonus(SolarPlant.Production(20), 0.1)-withBonus(SolarPlant.Production(19), 0.1), res.EnergyDelta)
+
+	params.IsCollector = true
+	res, _ = CalculatePrice(SolarPlantID, 20, params)
+	assert.Equal(t, withBonus(SolarPlant.Production(20), 0.2)-withBonus(SolarPlant.Production(19), 0.2), res.EnergyDelta)
+
+	// The collector bonus of the satellites is not counted twice
+	params.Temperature = Temperature{Min: 10, Max: 50}
+	res, _ = CalculatePrice(SolarSatelliteID, 100, params)
+	assert.Equal(t, withBonus(SolarSatellite.Production(params.Temperature, 100, true), 0.1), res.EnergyDelta)
+
+	// The consumption is not affected
+	params.HasCommandingStaff = true
+	res, _ = CalculatePrice(MetalMineID, 20, params)
+	assert.Equal(t, MetalMine.EnergyConsumption(19)-MetalMine.EnergyConsumption(20), res.EnergyDelta)
+}
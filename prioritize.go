@@ -21,7 +21,14 @@ type Prioritize struct {
 	initiator    string
 	name         string
 	taskIsDoneCh chan struct{}
+	taskID       int64
+	priority     int
+	queuedAt     time.Time
 	isTx         int32
+	friendlyFire bool
+
+	validateTarget     bool
+	validateTargetOpts []Option
 }
 
 // SetInitiator ...
@@ -30,6 +37,22 @@ func (b *Prioritize) SetInitiator(initiator string) Prioritizable {
 	return b
 }
 
+// AllowFriendlyFire allows hostile missions against alliance members and buddies for the following calls
+func (b *Prioritize) AllowFriendlyFire() Prioritizable {
+	b.friendlyFire = true
+	return b
+}
+
+// ValidateTarget validates the destination of the following SendFleet and EnsureFleet calls against the galaxy
+// content (planet exists, has a moon, has a debris field, is not in vacation mode) before contacting the fleet
+// dispatch. A *TargetError is returned if the destination is not valid. The galaxy cache is used unless SkipCache
+// is given.
+func (b *Prioritize) ValidateTarget(opts ...Option) Prioritizable {
+	b.validateTarget = true
+	b.validateTargetOpts = opts
+	return b
+}
+
 // Begin a new transaction. "Done" must be called to release the lock.
 func (b *Prioritize) Begin() Prioritizable {
 	return b.BeginNamed("Tx")
@@ -55,14 +78,18 @@ func (b *Prioritize) begin(name string) *Prioritize {
 		}
 		b.name += name
 		b.bot.botLock(b.name)
+		b.bot.taskStarted(b.taskID, b.priority, b.initiator, name, b.queuedAt)
 	}
 	return b
 }
 
 func (b *Prioritize) done() {
 	if atomic.AddInt32(&b.isTx, -1) == 0 {
-		defer close(b.taskIsDoneCh)
+		record := b.bot.taskFinished()
 		b.bot.botUnlock(b.name)
+		close(b.taskIsDoneCh)
+		// Written once the next task can acquire the lock, the history file is not part of the lock hold time
+		b.bot.appendTaskRecord(record)
 	}
 }
 
@@ -71,6 +98,9 @@ func (b *Prioritize) Tx(clb func(Prioritizable) error) error {
 	tx := b.Begin()
 	defer tx.Done()
 	err := clb(tx)
+	if err != nil {
+		b.bot.recordTaskError(b.taskID, err)
+	}
 	return err
 }
 
@@ -98,6 +128,23 @@ func (b *Prioritize) Login() error {
 	return b.bot.wrapLogin()
 }
 
+// MigrateUniverse re-resolves the universe through the lobby after a server merge
+func (b *Prioritize) MigrateUniverse() (UniverseMigration, error) {
+	b.begin("MigrateUniverse")
+	defer b.done()
+	return b.bot.migrateUniverse()
+}
+
+// SwitchUniverse logs out and logs in the account of the lobby login playing on another universe,
+// the cached state of the previous account is dropped
+func (b *Prioritize) SwitchUniverse(universe, lang string) error {
+	b.begin("SwitchUniverse")
+	defer b.done()
+	err := b.bot.switchUniverse(universe, lang)
+	b.audit("SwitchUniverse", err, AuditParams{"universe": universe, "lang": lang})
+	return err
+}
+
 // Logout the bot from ogame server
 func (b *Prioritize) Logout() {
 	b.begin("Logout")
@@ -127,6 +174,13 @@ func (b *Prioritize) PostPageContent(vals, payload url.Values) ([]byte, error) {
 	return b.bot.postPageContent(vals, payload)
 }
 
+// PostRawPageContent make a post request to ogame server with an arbitrary body and content type
+func (b *Prioritize) PostRawPageContent(vals url.Values, contentType string, body []byte) ([]byte, http.Header, error) {
+	b.begin("PostRawPageContent")
+	defer b.done()
+	return b.bot.postRawPageContent(vals, contentType, body)
+}
+
 // IsUnderAttack returns true if the user is under attack, false otherwise
 func (b *Prioritize) IsUnderAttack() (bool, error) {
 	b.begin("IsUnderAttack")
@@ -134,6 +188,13 @@ func (b *Prioritize) IsUnderAttack() (bool, error) {
 	return b.bot.isUnderAttack()
 }
 
+// GetPlanetsMaxAge returns the cached planets if they are not older than maxAge, otherwise fetch them from the game
+func (b *Prioritize) GetPlanetsMaxAge(maxAge time.Duration) ([]Planet, Freshness) {
+	b.begin("GetPlanetsMaxAge")
+	defer b.done()
+	return b.bot.getPlanetsMaxAge(maxAge)
+}
+
 // GetPlanets returns the user planets
 func (b *Prioritize) GetPlanets() []Planet {
 	b.begin("GetPlanets")
@@ -176,14 +237,27 @@ func (b *Prioritize) GetCelestials() ([]Celestial, error) {
 func (b *Prioritize) RecruitOfficer(typ, days int64) error {
 	b.begin("RecruitOfficer")
 	defer b.done()
-	return b.bot.recruitOfficer(typ, days)
+	err := b.bot.recruitOfficer(typ, days)
+	b.audit("RecruitOfficer", err, AuditParams{"typ": typ, "days": days})
+	return err
 }
 
 // Abandon a planet. Warning: this is irreversible
 func (b *Prioritize) Abandon(v interface{}) error {
 	b.begin("Abandon")
 	defer b.done()
-	return b.bot.abandon(v)
+	err := b.bot.abandon(v)
+	b.audit("Abandon", err, AuditParams{"celestial": v})
+	return err
+}
+
+// RenamePlanet renames a planet or moon
+func (b *Prioritize) RenamePlanet(celestialID CelestialID, name string) error {
+	b.begin("RenamePlanet")
+	defer b.done()
+	err := b.bot.renamePlanet(celestialID, name)
+	b.audit("RenamePlanet", err, AuditParams{"celestialID": celestialID, "name": name})
+	return err
 }
 
 // GetCelestial get the player's planet/moon using the coordinate
@@ -212,14 +286,18 @@ func (b *Prioritize) GetUserInfos() UserInfos {
 func (b *Prioritize) SendMessage(playerID int64, message string) error {
 	b.begin("SendMessage")
 	defer b.done()
-	return b.bot.sendMessage(playerID, message, true)
+	err := b.bot.sendMessage(playerID, message, true)
+	b.audit("SendMessage", err, AuditParams{"playerID": playerID, "message": message})
+	return err
 }
 
 // SendMessageAlliance sends a message to associationID
 func (b *Prioritize) SendMessageAlliance(associationID int64, message string) error {
 	b.begin("SendMessageAlliance")
 	defer b.done()
-	return b.bot.sendMessage(associationID, message, false)
+	err := b.bot.sendMessage(associationID, message, false)
+	b.audit("SendMessageAlliance", err, AuditParams{"associationID": associationID, "message": message})
+	return err
 }
 
 // GetFleets get the player's own fleets activities
@@ -240,7 +318,9 @@ func (b *Prioritize) GetFleetsFromEventList() []Fleet {
 func (b *Prioritize) CancelFleet(fleetID FleetID) error {
 	b.begin("CancelFleet")
 	defer b.done()
-	return b.bot.cancelFleet(fleetID)
+	err := b.bot.cancelFleet(fleetID)
+	b.audit("CancelFleet", err, AuditParams{"fleetID": fleetID})
+	return err
 }
 
 // GetAttacks get enemy fleets attacking you
@@ -250,6 +330,20 @@ func (b *Prioritize) GetAttacks(opts ...Option) ([]AttackEvent, error) {
 	return b.bot.getAttacks(opts...)
 }
 
+// GetInactiveTargets returns the planets of the inactive players from fromSystem to toSystem of a galaxy
+func (b *Prioritize) GetInactiveTargets(galaxy, fromSystem, toSystem int64, filter InactiveTargetsFilter) ([]PlanetInfos, error) {
+	b.begin("GetInactiveTargets")
+	defer b.done()
+	return b.bot.getInactiveTargets(galaxy, fromSystem, toSystem, filter)
+}
+
+// FindColonySpots returns the free positions of a galaxy range matching the criteria, safest first
+func (b *Prioritize) FindColonySpots(galaxyRange GalaxyRange, criteria ColonySpotsCriteria) ([]ColonySpot, error) {
+	b.begin("FindColonySpots")
+	defer b.done()
+	return b.bot.findColonySpots(galaxyRange, criteria)
+}
+
 // GalaxyInfos get information of all planets and moons of a solar system
 func (b *Prioritize) GalaxyInfos(galaxy, system int64, options ...Option) (SystemInfos, error) {
 	b.begin("GalaxyInfos")
@@ -268,7 +362,16 @@ func (b *Prioritize) GetResourceSettings(planetID PlanetID, options ...Option) (
 func (b *Prioritize) SetResourceSettings(planetID PlanetID, settings ResourceSettings) error {
 	b.begin("SetResourceSettings")
 	defer b.done()
-	return b.bot.setResourceSettings(planetID, settings)
+	err := b.bot.setResourceSettings(planetID, settings)
+	b.audit("SetResourceSettings", err, AuditParams{"planetID": planetID, "settings": settings})
+	return err
+}
+
+// GetMissingRequirements returns the buildings and research levels missing to build id on the celestial
+func (b *Prioritize) GetMissingRequirements(celestialID CelestialID, id ID) ([]MissingRequirement, error) {
+	b.begin("GetMissingRequirements")
+	defer b.done()
+	return b.bot.getMissingRequirements(celestialID, id)
 }
 
 // GetResourcesBuildings gets the resources buildings levels
@@ -308,6 +411,13 @@ func (b *Prioritize) GetProduction(celestialID CelestialID) ([]Quantifiable, int
 	return b.bot.getProduction(celestialID)
 }
 
+// GetProductionQueue gets the shipyard queue with the estimated start and end of every order
+func (b *Prioritize) GetProductionQueue(celestialID CelestialID) (ProductionQueue, error) {
+	b.begin("GetProductionQueue")
+	defer b.done()
+	return b.bot.getProductionQueue(celestialID)
+}
+
 // GetCachedResearch gets the player cached researches information
 func (b *Prioritize) GetCachedResearch() Researches {
 	b.begin("GetCachedResearch")
@@ -315,6 +425,13 @@ func (b *Prioritize) GetCachedResearch() Researches {
 	return b.bot.getCachedResearch()
 }
 
+// GetResearchMaxAge returns the cached researches if they are not older than maxAge, otherwise fetch them from the game
+func (b *Prioritize) GetResearchMaxAge(maxAge time.Duration) (Researches, Freshness) {
+	b.begin("GetResearchMaxAge")
+	defer b.done()
+	return b.bot.getResearchMaxAge(maxAge)
+}
+
 // GetResearch gets the player researches information
 func (b *Prioritize) GetResearch() Researches {
 	b.begin("GetResearch")
@@ -333,49 +450,63 @@ func (b *Prioritize) GetSlots() Slots {
 func (b *Prioritize) Build(celestialID CelestialID, id ID, nbr int64) error {
 	b.begin("Build")
 	defer b.done()
-	return b.bot.build(celestialID, id, nbr)
+	err := b.bot.build(celestialID, id, nbr)
+	b.audit("Build", err, AuditParams{"celestialID": celestialID, "id": id, "nbr": nbr})
+	return err
 }
 
 // TearDown tears down any ogame building
 func (b *Prioritize) TearDown(celestialID CelestialID, id ID) error {
 	b.begin("TearDown")
 	defer b.done()
-	return b.bot.tearDown(celestialID, id)
+	err := b.bot.tearDown(celestialID, id)
+	b.audit("TearDown", err, AuditParams{"celestialID": celestialID, "id": id})
+	return err
 }
 
 // BuildCancelable builds any cancelable ogame objects (building, technology)
 func (b *Prioritize) BuildCancelable(celestialID CelestialID, id ID) error {
 	b.begin("BuildCancelable")
 	defer b.done()
-	return b.bot.buildCancelable(celestialID, id)
+	err := b.bot.buildCancelable(celestialID, id)
+	b.audit("BuildCancelable", err, AuditParams{"celestialID": celestialID, "id": id})
+	return err
 }
 
 // BuildProduction builds any line production ogame objects (ship, defence)
 func (b *Prioritize) BuildProduction(celestialID CelestialID, id ID, nbr int64) error {
 	b.begin("BuildProduction")
 	defer b.done()
-	return b.bot.buildProduction(celestialID, id, nbr)
+	err := b.bot.buildProduction(celestialID, id, nbr)
+	b.audit("BuildProduction", err, AuditParams{"celestialID": celestialID, "id": id, "nbr": nbr})
+	return err
 }
 
 // BuildBuilding ensure what is being built is a building
 func (b *Prioritize) BuildBuilding(celestialID CelestialID, buildingID ID) error {
 	b.begin("BuildBuilding")
 	defer b.done()
-	return b.bot.buildBuilding(celestialID, buildingID)
+	err := b.bot.buildBuilding(celestialID, buildingID)
+	b.audit("BuildBuilding", err, AuditParams{"celestialID": celestialID, "buildingID": buildingID})
+	return err
 }
 
 // BuildDefense builds a defense unit
 func (b *Prioritize) BuildDefense(celestialID CelestialID, defenseID ID, nbr int64) error {
 	b.begin("BuildDefense")
 	defer b.done()
-	return b.bot.buildDefense(celestialID, defenseID, nbr)
+	err := b.bot.buildDefense(celestialID, defenseID, nbr)
+	b.audit("BuildDefense", err, AuditParams{"celestialID": celestialID, "defenseID": defenseID, "nbr": nbr})
+	return err
 }
 
 // BuildShips builds a ship unit
 func (b *Prioritize) BuildShips(celestialID CelestialID, shipID ID, nbr int64) error {
 	b.begin("BuildShips")
 	defer b.done()
-	return b.bot.buildShips(celestialID, shipID, nbr)
+	err := b.bot.buildShips(celestialID, shipID, nbr)
+	b.audit("BuildShips", err, AuditParams{"celestialID": celestialID, "shipID": shipID, "nbr": nbr})
+	return err
 }
 
 // ConstructionsBeingBuilt returns the building & research being built, and the time remaining (secs)
@@ -389,21 +520,27 @@ func (b *Prioritize) ConstructionsBeingBuilt(celestialID CelestialID) (ID, int64
 func (b *Prioritize) CancelBuilding(celestialID CelestialID) error {
 	b.begin("CancelBuilding")
 	defer b.done()
-	return b.bot.cancelBuilding(celestialID)
+	err := b.bot.cancelBuilding(celestialID)
+	b.audit("CancelBuilding", err, AuditParams{"celestialID": celestialID})
+	return err
 }
 
 // CancelResearch cancel the research
 func (b *Prioritize) CancelResearch(celestialID CelestialID) error {
 	b.begin("CancelResearch")
 	defer b.done()
-	return b.bot.cancelResearch(celestialID)
+	err := b.bot.cancelResearch(celestialID)
+	b.audit("CancelResearch", err, AuditParams{"celestialID": celestialID})
+	return err
 }
 
 // BuildTechnology ensure that we're trying to build a technology
 func (b *Prioritize) BuildTechnology(celestialID CelestialID, technologyID ID) error {
 	b.begin("BuildTechnology")
 	defer b.done()
-	return b.bot.buildTechnology(celestialID, technologyID)
+	err := b.bot.buildTechnology(celestialID, technologyID)
+	b.audit("BuildTechnology", err, AuditParams{"celestialID": celestialID, "technologyID": technologyID})
+	return err
 }
 
 // GetResources gets user resources
@@ -420,6 +557,36 @@ func (b *Prioritize) GetResourcesDetails(celestialID CelestialID) (ResourcesDeta
 	return b.bot.getResourcesDetails(celestialID)
 }
 
+// GetBuildRecommendations ranks the mines, plasma technology and astrophysics upgrades by payback time
+func (b *Prioritize) GetBuildRecommendations() ([]BuildRecommendation, error) {
+	b.begin("GetBuildRecommendations")
+	defer b.done()
+	return b.bot.getBuildRecommendations()
+}
+
+// GetStorageETA returns the storages of every planet and when they will be full
+func (b *Prioritize) GetStorageETA() ([]StorageETA, error) {
+	b.begin("GetStorageETA")
+	defer b.done()
+	return b.bot.getStorageETA()
+}
+
+// SetCharacterClass switches the character class, it costs CharacterClassChangeCost dark matter unless no class is selected
+func (b *Prioritize) SetCharacterClass(class CharacterClass) error {
+	b.begin("SetCharacterClass")
+	defer b.done()
+	err := b.bot.setCharacterClass(class)
+	b.audit("SetCharacterClass", err, AuditParams{"class": class})
+	return err
+}
+
+// InvalidateCache drops the cached state of the given kinds (every kind if none), see CacheKinds
+func (b *Prioritize) InvalidateCache(kinds ...CacheKind) error {
+	b.begin("InvalidateCache")
+	defer b.done()
+	return b.bot.invalidateCache(kinds...)
+}
+
 // GetTechs gets a celestial supplies/facilities/ships/researches
 func (b *Prioritize) GetTechs(celestialID CelestialID) (ResourcesBuildings, Facilities, ShipsInfos, DefensesInfos, Researches, error) {
 	b.begin("GetTechs")
@@ -427,12 +594,65 @@ func (b *Prioritize) GetTechs(celestialID CelestialID) (ResourcesBuildings, Faci
 	return b.bot.getTechs(celestialID)
 }
 
-// SendFleet sends a fleet
+// SetResourceSettingsAll applies the production percentages to every planet in one transaction
+func (b *Prioritize) SetResourceSettingsAll(settings ResourceSettings) error {
+	b.begin("SetResourceSettingsAll")
+	defer b.done()
+	err := b.bot.setResourceSettingsAll(settings)
+	b.audit("SetResourceSettingsAll", err, AuditParams{"settings": settings})
+	return err
+}
+
+// OptimizeResourceSettings computes the production percentages of every planet maximizing the objective under the
+// energy constraint, and applies them if apply is true
+func (b *Prioritize) OptimizeResourceSettings(objective ProductionObjective, apply bool) (map[PlanetID]ResourceSettings, error) {
+	b.begin("OptimizeResourceSettings")
+	defer b.done()
+	res, err := b.bot.optimizeResourceSettings(objective, apply)
+	if apply {
+		b.audit("OptimizeResourceSettings", err, AuditParams{"objective": objective})
+	}
+	return res, err
+}
+
+// ExportEmpire builds a complete snapshot of the empire: celestials, resources, buildings, ships, defenses,
+// researches and fleets
+func (b *Prioritize) ExportEmpire() (EmpireExport, error) {
+	b.begin("ExportEmpire")
+	defer b.done()
+	return b.bot.exportEmpire()
+}
+
+// ImportEmpire restores the cached planets, researches, player and empire snapshot of an export, e.g. in a bot created
+// with NewNoLogin to analyze the empire offline
+func (b *Prioritize) ImportEmpire(e EmpireExport) {
+	b.begin("ImportEmpire")
+	defer b.done()
+	b.bot.importEmpire(e)
+}
+
+// GetAllTechs gets the supplies/facilities/ships/defenses of every celestial and the researches in one transaction
+func (b *Prioritize) GetAllTechs() (AllTechs, error) {
+	b.begin("GetAllTechs")
+	defer b.done()
+	return b.bot.getAllTechs()
+}
+
+// SendFleet sends a fleet. AllAvailable as a ships quantity or a resources amount sends everything available.
 func (b *Prioritize) SendFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate,
 	mission MissionID, resources Resources, holdingTime, unionID int64) (Fleet, error) {
 	b.begin("SendFleet")
 	defer b.done()
-	return b.bot.sendFleet(celestialID, ships, speed, where, mission, resources, holdingTime, unionID, false)
+	var fleet Fleet
+	var err error
+	if b.validateTarget {
+		err = b.bot.checkTarget(where, mission, b.validateTargetOpts...)
+	}
+	if err == nil {
+		fleet, err = b.bot.sendFleet(celestialID, ships, speed, where, mission, resources, holdingTime, unionID, false, b.friendlyFire)
+	}
+	b.audit("SendFleet", err, AuditParams{"celestialID": celestialID, "ships": ships, "speed": speed, "where": where, "mission": mission, "resources": resources, "holdingTime": holdingTime, "unionID": unionID})
+	return fleet, err
 }
 
 // EnsureFleet either sends all the requested ships or fail
@@ -440,21 +660,34 @@ func (b *Prioritize) EnsureFleet(celestialID CelestialID, ships []Quantifiable,
 	mission MissionID, resources Resources, holdingTime, unionID int64) (Fleet, error) {
 	b.begin("EnsureFleet")
 	defer b.done()
-	return b.bot.sendFleet(celestialID, ships, speed, where, mission, resources, holdingTime, unionID, true)
+	var fleet Fleet
+	var err error
+	if b.validateTarget {
+		err = b.bot.checkTarget(where, mission, b.validateTargetOpts...)
+	}
+	if err == nil {
+		fleet, err = b.bot.sendFleet(celestialID, ships, speed, where, mission, resources, holdingTime, unionID, true, b.friendlyFire)
+	}
+	b.audit("EnsureFleet", err, AuditParams{"celestialID": celestialID, "ships": ships, "speed": speed, "where": where, "mission": mission, "resources": resources, "holdingTime": holdingTime, "unionID": unionID})
+	return fleet, err
 }
 
 // DestroyRockets destroys anti-ballistic & inter-planetary missiles
 func (b *Prioritize) DestroyRockets(planetID PlanetID, abm, ipm int64) error {
 	b.begin("DestroyRockets")
 	defer b.done()
-	return b.bot.destroyRockets(planetID, abm, ipm)
+	err := b.bot.destroyRockets(planetID, abm, ipm)
+	b.audit("DestroyRockets", err, AuditParams{"planetID": planetID, "abm": abm, "ipm": ipm})
+	return err
 }
 
 // SendIPM sends IPM
 func (b *Prioritize) SendIPM(planetID PlanetID, coord Coordinate, nbr int64, priority ID) (int64, error) {
 	b.begin("SendIPM")
 	defer b.done()
-	return b.bot.sendIPM(planetID, coord, nbr, priority)
+	duration, err := b.bot.sendIPM(planetID, coord, nbr, priority)
+	b.audit("SendIPM", err, AuditParams{"planetID": planetID, "coord": coord, "nbr": nbr, "priority": priority})
+	return duration, err
 }
 
 // GetCombatReportSummaryFor gets the latest combat report for a given coordinate
@@ -471,6 +704,13 @@ func (b *Prioritize) GetEspionageReportFor(coord Coordinate) (EspionageReport, e
 	return b.bot.getEspionageReportFor(coord)
 }
 
+// DiffLatestEspionageReports compares the two most recent espionage reports of a coordinate
+func (b *Prioritize) DiffLatestEspionageReports(coord Coordinate) (EspionageReportDiff, error) {
+	b.begin("DiffLatestEspionageReports")
+	defer b.done()
+	return b.bot.diffLatestEspionageReports(coord)
+}
+
 // GetEspionageReportMessages gets the summary of each espionage reports
 func (b *Prioritize) GetEspionageReportMessages() ([]EspionageReportSummary, error) {
 	b.begin("GetEspionageReportMessages")
@@ -478,6 +718,27 @@ func (b *Prioritize) GetEspionageReportMessages() ([]EspionageReportSummary, err
 	return b.bot.getEspionageReportMessages()
 }
 
+// SearchMessages searches the inbox messages matching the query
+func (b *Prioritize) SearchMessages(query MessageQuery) ([]Message, error) {
+	b.begin("SearchMessages")
+	defer b.done()
+	return b.bot.searchMessages(query)
+}
+
+// GetCombatReportMessages gets the summary of each combat reports
+func (b *Prioritize) GetCombatReportMessages() ([]CombatReportSummary, error) {
+	b.begin("GetCombatReportMessages")
+	defer b.done()
+	return b.bot.getCombatReportMessages()
+}
+
+// GetCombatReportMessagesPage gets the summary of the combat reports on a page of the messages, also returns the number of pages
+func (b *Prioritize) GetCombatReportMessagesPage(page int64) ([]CombatReportSummary, int64, error) {
+	b.begin("GetCombatReportMessagesPage")
+	defer b.done()
+	return b.bot.getCombatReportMessagesPage(page)
+}
+
 // CollectAllMarketplaceMessages collect all marketplace messages
 func (b *Prioritize) CollectAllMarketplaceMessages() error {
 	b.begin("CollectAllMarketplaceMessages")
@@ -507,6 +768,13 @@ func (b *Prioritize) GetExpeditionMessageAt(t time.Time) (ExpeditionMessage, err
 	return b.bot.getExpeditionMessageAt(t)
 }
 
+// GetExpeditionStats aggregates the outcomes of the expedition messages received after since per day and per slot
+func (b *Prioritize) GetExpeditionStats(since time.Time) (ExpeditionStatsReport, error) {
+	b.begin("GetExpeditionStats")
+	defer b.done()
+	return b.bot.getExpeditionStats(since)
+}
+
 // GetEspionageReport gets a detailed espionage report
 func (b *Prioritize) GetEspionageReport(msgID int64) (EspionageReport, error) {
 	b.begin("GetEspionageReport")
@@ -514,18 +782,43 @@ func (b *Prioritize) GetEspionageReport(msgID int64) (EspionageReport, error) {
 	return b.bot.getEspionageReport(msgID)
 }
 
+// GetEspionageReportShareKey gets the share key (sr-...) of an espionage report
+func (b *Prioritize) GetEspionageReportShareKey(msgID int64) (string, error) {
+	b.begin("GetEspionageReportShareKey")
+	defer b.done()
+	return b.bot.getEspionageReportShareKey(msgID)
+}
+
+// GetIPMPlan plans the interplanetary missiles attack of the target of an espionage report, see PlanIPMAttack
+func (b *Prioritize) GetIPMPlan(msgID int64, maxPerWave int64, priorities []ID) (IPMPlan, error) {
+	b.begin("GetIPMPlan")
+	defer b.done()
+	return b.bot.planIPMAttack(msgID, maxPerWave, priorities)
+}
+
+// GetMoonshotPlan plans a moonshot from the ships of the origin, see PlanMoonshot
+func (b *Prioritize) GetMoonshotPlan(origin CelestialID, destination Coordinate, chance int64, ships []ID) (MoonshotPlan, error) {
+	b.begin("GetMoonshotPlan")
+	defer b.done()
+	return b.bot.planMoonshot(origin, destination, chance, ships)
+}
+
 // DeleteMessage deletes a message from the mail box
 func (b *Prioritize) DeleteMessage(msgID int64) error {
 	b.begin("DeleteMessage")
 	defer b.done()
-	return b.bot.deleteMessage(msgID)
+	err := b.bot.deleteMessage(msgID)
+	b.audit("DeleteMessage", err, AuditParams{"msgID": msgID})
+	return err
 }
 
 // DeleteAllMessagesFromTab ...
 func (b *Prioritize) DeleteAllMessagesFromTab(tabID int64) error {
 	b.begin("DeleteAllMessagesFromTab")
 	defer b.done()
-	return b.bot.deleteAllMessagesFromTab(tabID)
+	err := b.bot.deleteAllMessagesFromTab(tabID)
+	b.audit("DeleteAllMessagesFromTab", err, AuditParams{"tabID": tabID})
+	return err
 }
 
 // GetResourcesProductions gets the planet resources production
@@ -535,12 +828,27 @@ func (b *Prioritize) GetResourcesProductions(planetID PlanetID) (Resources, erro
 	return b.bot.getResourcesProductions(planetID)
 }
 
+// AuditProduction compares the computed production/energy of a planet against the values displayed by the game
+func (b *Prioritize) AuditProduction(planetID PlanetID) (ProductionAudit, error) {
+	b.begin("AuditProduction")
+	defer b.done()
+	return b.bot.auditProduction(planetID)
+}
+
 // GetResourcesProductionsLight gets the planet resources production
 func (b *Prioritize) GetResourcesProductionsLight(resBuildings ResourcesBuildings, researches Researches,
 	resSettings ResourceSettings, temp Temperature) Resources {
 	b.begin("GetResourcesProductionsLight")
 	defer b.done()
-	return getResourcesProductionsLight(resBuildings, researches, resSettings, temp, b.bot.serverData.Speed)
+	return productionWithBonuses(b.bot.productionInputs(resBuildings, resSettings, researches, temp, nil))
+}
+
+// ValidateFleet runs the pre-flight checks of SendFleet and computes the flight time, fuel and cargo without sending the fleet
+func (b *Prioritize) ValidateFleet(celestialID CelestialID, ships []Quantifiable, speed Speed, where Coordinate,
+	mission MissionID, resources Resources) (FleetValidation, error) {
+	b.begin("ValidateFleet")
+	defer b.done()
+	return b.bot.validateFleet(celestialID, ships, speed, where, mission, resources)
 }
 
 // FlightTime calculate flight time and fuel needed
@@ -574,7 +882,9 @@ func (b *Prioritize) UnsafePhalanx(moonID MoonID, coord Coordinate) ([]Fleet, er
 func (b *Prioritize) JumpGate(origin, dest MoonID, ships ShipsInfos) (bool, int64, error) {
 	b.begin("JumpGate")
 	defer b.done()
-	return b.bot.executeJumpGate(origin, dest, ships)
+	success, rechargeCountdown, err := b.bot.executeJumpGate(origin, dest, ships)
+	b.audit("JumpGate", err, AuditParams{"origin": origin, "dest": dest, "ships": ships})
+	return success, rechargeCountdown, err
 }
 
 // JumpGateDestinations returns available destinations for jump gate.
@@ -588,14 +898,18 @@ func (b *Prioritize) JumpGateDestinations(origin MoonID) ([]MoonID, int64, error
 func (b *Prioritize) BuyOfferOfTheDay() error {
 	b.begin("BuyOfferOfTheDay")
 	defer b.done()
-	return b.bot.buyOfferOfTheDay()
+	err := b.bot.buyOfferOfTheDay()
+	b.audit("BuyOfferOfTheDay", err, nil)
+	return err
 }
 
 // CreateUnion creates a union
 func (b *Prioritize) CreateUnion(fleet Fleet, users []string) (int64, error) {
 	b.begin("CreateUnion")
 	defer b.done()
-	return b.bot.createUnion(fleet, users)
+	unionID, err := b.bot.createUnion(fleet, users)
+	b.audit("CreateUnion", err, AuditParams{"fleet": fleet, "users": users})
+	return unionID, err
 }
 
 // HeadersForPage gets the headers for a specific ogame page
@@ -612,6 +926,28 @@ func (b *Prioritize) GetEmpire(celestialType CelestialType) ([]EmpireCelestial,
 	return b.bot.getEmpire(celestialType)
 }
 
+// GetEmpireSnapshot retrieves the empire of all planets and moons (Commander only)
+func (b *Prioritize) GetEmpireSnapshot() (EmpireSnapshot, error) {
+	b.begin("GetEmpireSnapshot")
+	defer b.done()
+	return b.bot.getEmpireSnapshot()
+}
+
+// SyncEmpire builds the resources/supplies/facilities/ships/defenses of every planet and moon, from the empire pages
+// with a commander (at most 2 requests), celestial by celestial otherwise
+func (b *Prioritize) SyncEmpire() (EmpireSnapshot, error) {
+	b.begin("SyncEmpire")
+	defer b.done()
+	return b.bot.syncEmpire()
+}
+
+// GetEmpireJSONMaxAge returns the last empire JSON if it is not older than maxAge, otherwise fetch it from the game
+func (b *Prioritize) GetEmpireJSONMaxAge(nbr int64, maxAge time.Duration) (interface{}, Freshness, error) {
+	b.begin("GetEmpireJSONMaxAge")
+	defer b.done()
+	return b.bot.getEmpireJSONMaxAge(nbr, maxAge)
+}
+
 // GetEmpireJSON retrieves JSON from Empire page (Commander only).
 func (b *Prioritize) GetEmpireJSON(nbr int64) (interface{}, error) {
 	b.begin("GetEmpireJSON")
@@ -630,7 +966,9 @@ func (b *Prioritize) GetAuction() (Auction, error) {
 func (b *Prioritize) DoAuction(bid map[CelestialID]Resources) error {
 	b.begin("DoAuction")
 	defer b.done()
-	return b.bot.doAuction(CelestialID(0), bid)
+	err := b.bot.doAuction(CelestialID(0), bid)
+	b.audit("DoAuction", err, AuditParams{"bid": bid})
+	return err
 }
 
 // Highscore ...
@@ -658,7 +996,23 @@ func (b *Prioritize) GetDMCosts(celestialID CelestialID) (DMCosts, error) {
 func (b *Prioritize) UseDM(typ string, celestialID CelestialID) error {
 	b.begin("UseDM")
 	defer b.done()
-	return b.bot.useDM(typ, celestialID)
+	err := b.bot.useDM(typ, celestialID)
+	b.audit("UseDM", err, AuditParams{"typ": typ, "celestialID": celestialID})
+	return err
+}
+
+// GetAllItems returns the items inventory and the items active on every celestial, in a single transaction
+func (b *Prioritize) GetAllItems() (AllItems, error) {
+	b.begin("GetAllItems")
+	defer b.done()
+	return b.bot.getAllItems(b)
+}
+
+// GetLobbyAccounts returns the game accounts of the lobby login, most recently played first
+func (b *Prioritize) GetLobbyAccounts() ([]LobbyAccount, error) {
+	b.begin("GetLobbyAccounts")
+	defer b.done()
+	return b.bot.getLobbyAccounts()
 }
 
 // GetItems get all items information
@@ -679,26 +1033,34 @@ func (b *Prioritize) GetActiveItems(celestialID CelestialID) ([]ActiveItem, erro
 func (b *Prioritize) ActivateItem(ref string, celestialID CelestialID) error {
 	b.begin("ActivateItem")
 	defer b.done()
-	return b.bot.activateItem(ref, celestialID)
+	err := b.bot.activateItem(ref, celestialID)
+	b.audit("ActivateItem", err, AuditParams{"ref": ref, "celestialID": celestialID})
+	return err
 }
 
 // BuyMarketplace buy an item on the marketplace
 func (b *Prioritize) BuyMarketplace(itemID int64, celestialID CelestialID) error {
 	b.begin("BuyMarketplace")
 	defer b.done()
-	return b.bot.buyMarketplace(itemID, celestialID)
+	err := b.bot.buyMarketplace(itemID, celestialID)
+	b.audit("BuyMarketplace", err, AuditParams{"itemID": itemID, "celestialID": celestialID})
+	return err
 }
 
 // OfferSellMarketplace ...
 func (b *Prioritize) OfferSellMarketplace(itemID interface{}, quantity, priceType, price, priceRange int64, celestialID CelestialID) error {
 	b.begin("OfferSellMarketplace")
 	defer b.done()
-	return b.bot.offerMarketplace(4, itemID, quantity, priceType, price, priceRange, celestialID)
+	err := b.bot.offerMarketplace(4, itemID, quantity, priceType, price, priceRange, celestialID)
+	b.audit("OfferSellMarketplace", err, AuditParams{"itemID": itemID, "quantity": quantity, "priceType": priceType, "price": price, "priceRange": priceRange, "celestialID": celestialID})
+	return err
 }
 
 // OfferBuyMarketplace ...
 func (b *Prioritize) OfferBuyMarketplace(itemID interface{}, quantity, priceType, price, priceRange int64, celestialID CelestialID) error {
 	b.begin("OfferBuyMarketplace")
 	defer b.done()
-	return b.bot.offerMarketplace(3, itemID, quantity, priceType, price, priceRange, celestialID)
+	err := b.bot.offerMarketplace(3, itemID, quantity, priceType, price, priceRange, celestialID)
+	b.audit("OfferBuyMarketplace", err, AuditParams{"itemID": itemID, "quantity": quantity, "priceType": priceType, "price": price, "priceRange": priceRange, "celestialID": celestialID})
+	return err
 }
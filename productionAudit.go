@@ -0,0 +1,161 @@
+package ogame
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// ProductionAuditTolerance relative difference tolerated between computed and observed values (rounding)
+const ProductionAuditTolerance = 0.01
+
+// ProductionAuditInputs values used to compute the expected hourly production of a planet
+type ProductionAuditInputs struct {
+	Buildings       ResourcesBuildings
+	Settings        ResourceSettings
+	Researches      Researches
+	Temperature     Temperature
+	UniverseSpeed   int64
+	Geologist       bool
+	Engineer        bool
+	CommandingStaff bool // All the officers are hired
+	Collector       bool
+	ActiveItems     []ActiveItem // Active booster items of the planet
+}
+
+// ProductionDiscrepancy a value for which the library formula and the game disagree
+type ProductionDiscrepancy struct {
+	Value    string // metal, crystal, deuterium, energy_production, energy_consumption
+	Computed int64
+	Observed int64
+}
+
+func (d ProductionDiscrepancy) String() string {
+	return fmt.Sprintf("%s: computed %d, observed %d", d.Value, d.Computed, d.Observed)
+}
+
+// ProductionAudit result of the comparison between computed and observed production of a planet
+type ProductionAudit struct {
+	PlanetID          PlanetID
+	Inputs            ProductionAuditInputs
+	Computed          Resources // Hourly production, Energy is the energy produced
+	ComputedEnergyUse int64
+	Observed          Resources
+	ObservedEnergyUse int64
+	Discrepancies     []ProductionDiscrepancy
+}
+
+// OK returns true if no discrepancy was found
+func (a ProductionAudit) OK() bool {
+	return len(a.Discrepancies) == 0
+}
+
+// computeAuditedProduction computes the hourly production including the officers, class, crawlers and boosters
+// bonuses that getProductions ignores. Returns the production (Energy is the energy produced) and the energy consumption.
+func computeAuditedProduction(in ProductionAuditInputs) (Resources, int64) {
+	class := NoClass
+	if in.Collector {
+		class = Collector
+	}
+	officers := Officers{Geologist: in.Geologist, Engineer: in.Engineer}
+	if in.CommandingStaff {
+		officers = Officers{Commander: true, Admiral: true, Engineer: true, Geologist: true, Technocrat: true}
+	}
+	applyBonus := func(total, base int64, bonus float64) int64 {
+		return total + int64(math.Round(float64(total-base)*bonus))
+	}
+	// The energy bonuses count to power the mines
+	produced := applyBonus(energyProduced(in.Temperature, in.Buildings, in.Settings, in.Researches.EnergyTechnology), 0, EnergyProductionBonus(officers, class))
+	crawlersEnergy := Crawler.EnergyConsumption(in.Settings.CrawlerCount, in.Settings.Crawler, class)
+	needed := energyNeeded(in.Buildings, in.Settings) + crawlersEnergy
+	ratio := 1.0
+	if needed > produced {
+		ratio = float64(produced) / float64(needed)
+	}
+	prod := getProductions(in.Buildings, in.Settings, in.Researches, in.UniverseSpeed, in.Temperature, ratio)
+	noMines := in.Buildings
+	noMines.MetalMine, noMines.CrystalMine, noMines.DeuteriumSynthesizer = 0, 0, 0
+	base := getProductions(noMines, in.Settings, in.Researches, in.UniverseSpeed, in.Temperature, ratio)
+
+	minesBonus := MinesProductionBonus(officers, class)
+	minesBonus += Crawler.ProductionBonus(in.Settings.CrawlerCount, in.Settings.Crawler, in.Buildings, class, in.Geologist)
+	boosters := GetBoostersBonus(in.ActiveItems)
+	return Resources{
+		Metal:     applyBonus(prod.Metal, base.Metal, minesBonus+boosters.Metal),
+		Crystal:   applyBonus(prod.Crystal, base.Crystal, minesBonus+boosters.Crystal),
+		Deuterium: applyBonus(prod.Deuterium, base.Deuterium, minesBonus+boosters.Deuterium),
+		Energy:    produced,
+	}, needed
+}
+
+// auditProduction compares the computed values against the values displayed by the game
+func auditProduction(planetID PlanetID, in ProductionAuditInputs, details ResourcesDetails) ProductionAudit {
+	computed, energyUse := computeAuditedProduction(in)
+	a := ProductionAudit{
+		PlanetID:          planetID,
+		Inputs:            in,
+		Computed:          computed,
+		ComputedEnergyUse: energyUse,
+		Observed: Resources{
+			Metal:     details.Metal.CurrentProduction,
+			Crystal:   details.Crystal.CurrentProduction,
+			Deuterium: details.Deuterium.CurrentProduction,
+			Energy:    details.Energy.CurrentProduction,
+		},
+		ObservedEnergyUse: details.Energy.Consumption,
+	}
+	check := func(value string, computed, observed int64) {
+		diff := math.Abs(float64(computed - observed))
+		if diff > 1 && diff > math.Abs(float64(observed))*ProductionAuditTolerance {
+			a.Discrepancies = append(a.Discrepancies, ProductionDiscrepancy{Value: value, Computed: computed, Observed: observed})
+		}
+	}
+	check("metal", a.Computed.Metal, a.Observed.Metal)
+	check("crystal", a.Computed.Crystal, a.Observed.Crystal)
+	check("deuterium", a.Computed.Deuterium, a.Observed.Deuterium)
+	check("energy_production", a.Computed.Energy, a.Observed.Energy)
+	if a.ObservedEnergyUse != 0 {
+		check("energy_consumption", a.ComputedEnergyUse, a.ObservedEnergyUse)
+	}
+	return a
+}
+
+func (b *OGame) auditProduction(planetID PlanetID) (ProductionAudit, error) {
+	planet, err := b.getPlanet(planetID)
+	if err != nil {
+		return ProductionAudit{}, err
+	}
+	resBuildings, err := b.getResourcesBuildings(planetID.Celestial())
+	if err != nil {
+		return ProductionAudit{}, err
+	}
+	resSettings, err := b.getResourceSettings(planetID)
+	if err != nil {
+		return ProductionAudit{}, err
+	}
+	details, err := b.getResourcesDetails(planetID.Celestial())
+	if err != nil {
+		return ProductionAudit{}, err
+	}
+	activeItems, _ := b.getActiveItems(planetID.Celestial())
+	in := b.productionInputs(resBuildings, resSettings, b.getResearch(), planet.Temperature, activeItems)
+	audit := auditProduction(planetID, in, details)
+	if !audit.OK() {
+		b.warn(fmt.Sprintf("production formula drift on planet %d: %v, inputs: %+v", planetID, audit.Discrepancies, in))
+	}
+	return audit, nil
+}
+
+// SetProductionAudit when enabled, every GetResourcesProductions call also audits the library formulas
+// against the values displayed by the game and logs the discrepancies.
+func (b *OGame) SetProductionAudit(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&b.productionAuditAtom, v)
+}
+
+func (b *OGame) isProductionAuditEnabled() bool {
+	return atomic.LoadInt32(&b.productionAuditAtom) == 1
+}
@@ -0,0 +1,87 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeAuditedProduction(t *testing.T) {
+	in := ProductionAuditInputs{
+		Buildings:     ResourcesBuildings{MetalMine: 20, CrystalMine: 15, DeuteriumSynthesizer: 10, SolarPlant: 25},
+		Settings:      ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100, FusionReactor: 100, SolarSatellite: 100},
+		Temperature:   Temperature{Min: 10, Max: 50},
+		UniverseSpeed: 1,
+	}
+	plain, energyUse := computeAuditedProduction(in)
+	ratio := productionRatio(in.Temperature, in.Buildings, in.Settings, 0)
+	expected := getProductions(in.Buildings, in.Settings, in.Researches, in.UniverseSpeed, in.Temperature, ratio)
+	assert.Equal(t, expected.Metal, plain.Metal)
+	assert.Equal(t, energyNeeded(in.Buildings, in.Settings), energyUse)
+
+	in.Geologist = true
+	withGeologist, _ := computeAuditedProduction(in)
+	basicIncome := int64(30)
+	assert.Equal(t, plain.Metal+int64(float64(plain.Metal-basicIncome)*0.1+0.5), withGeologist.Metal)
+	assert.Equal(t, plain.Energy, withGeologist.Energy)
+
+	in.Engineer = true
+	withEngineer, _ := computeAuditedProduction(in)
+	assert.True(t, withEngineer.Energy > plain.Energy)
+}
+
+func TestAuditProduction(t *testing.T) {
+	in := ProductionAuditInputs{
+		Buildings:     ResourcesBuildings{MetalMine: 20, SolarPlant: 20},
+		Settings:      ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100},
+		UniverseSpeed: 1,
+	}
+	computed, energyUse := computeAuditedProduction(in)
+	var details ResourcesDetails
+	details.Metal.CurrentProduction = computed.Metal
+	details.Crystal.CurrentProduction = computed.Crystal
+	details.Deuterium.CurrentProduction = computed.Deuterium
+	details.Energy.CurrentProduction = computed.Energy
+	details.Energy.Consumption = energyUse
+	assert.True(t, auditProduction(1, in, details).OK())
+
+	details.Metal.CurrentProduction = computed.Metal * 2
+	audit := auditProduction(1, in, details)
+	assert.False(t, audit.OK())
+	assert.Equal(t, []ProductionDiscrepancy{{Value: "metal", Computed: computed.Metal, Observed: computed.Metal * 2}}, audit.Discrepancies)
+}
+
+func TestComputeAuditedProduction_Bonuses(t *testing.T) {
+	in := ProductionAuditInputs{
+		Buildings:     ResourcesBuildings{MetalMine: 20, CrystalMine: 15, DeuteriumSynthesizer: 10, SolarPlant: 25},
+		Settings:      ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100, FusionReactor: 100, SolarSatellite: 100},
+		Temperature:   Temperature{Min: 10, Max: 50},
+		UniverseSpeed: 1,
+	}
+	plain, _ := computeAuditedProduction(in)
+	basicIncome := int64(30)
+
+	in.ActiveItems = []ActiveItem{{Ref: "05294270032e5dc968672425ab5611998c409166"}} // Gold metal booster
+	boosted, _ := computeAuditedProduction(in)
+	assert.Equal(t, plain.Metal+int64(float64(plain.Metal-basicIncome)*0.3+0.5), boosted.Metal)
+	assert.Equal(t, plain.Crystal, boosted.Crystal)
+
+	in.ActiveItems = nil
+	in.CommandingStaff = true
+	staff, _ := computeAuditedProduction(in)
+	assert.Equal(t, plain.Metal+int64(float64(plain.Metal-basicIncome)*0.12+0.5), staff.Metal)
+	assert.Equal(t, plain.Energy+int64(float64(plain.Energy)*0.12+0.5), staff.Energy)
+}
+
+func TestComputeAuditedProduction_EnergyBonusPowersMines(t *testing.T) {
+	in := ProductionAuditInputs{
+		Buildings:     ResourcesBuildings{MetalMine: 20, SolarPlant: 15},
+		Settings:      ResourceSettings{MetalMine: 100, SolarPlant: 100},
+		UniverseSpeed: 1,
+	}
+	plain, energyUse := computeAuditedProduction(in)
+	assert.True(t, plain.Energy < energyUse)
+	in.Engineer = true
+	withEngineer, _ := computeAuditedProduction(in)
+	assert.True(t, withEngineer.Metal > plain.Metal)
+}
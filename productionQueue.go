@@ -0,0 +1,113 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MaxProductionQueueItems number of orders the shipyard queue of a celestial can hold
+const MaxProductionQueueItems = 99
+
+// maxProductionOrder number of units of a single shipyard order
+const maxProductionOrder = 99999
+
+// ProductionQueueItem ships or defenses order of the shipyard queue
+type ProductionQueueItem struct {
+	Quantifiable
+	StartAt   time.Time // Estimated start of the order, in the past for the order being built
+	EndAt     time.Time // Estimated end of the order
+	Remaining int64     // Seconds until the end of the order
+}
+
+// ProductionQueue ships and defenses being built on a celestial
+type ProductionQueue struct {
+	Items []ProductionQueueItem
+	EndAt time.Time // End of the whole queue
+	Full  bool      // The queue holds MaxProductionQueueItems orders, nothing more can be queued
+}
+
+// Count returns the number of units of id in the queue
+func (q ProductionQueue) Count(id ID) (nbr int64) {
+	for _, item := range q.Items {
+		if item.ID == id {
+			nbr += item.Nbr
+		}
+	}
+	return
+}
+
+// newProductionQueue estimates the start and end of every order. The orders are laid out backward from the end of
+// the queue, given by the countdown of the page, using the construction time of each order.
+func newProductionQueue(production []Quantifiable, countdown int64, now time.Time, duration func(Quantifiable) time.Duration) ProductionQueue {
+	q := ProductionQueue{
+		Items: make([]ProductionQueueItem, len(production)),
+		EndAt: now.Add(time.Duration(countdown) * time.Second),
+		Full:  len(production) >= MaxProductionQueueItems,
+	}
+	end := q.EndAt
+	for i := len(production) - 1; i >= 0; i-- {
+		start := end.Add(-duration(production[i]))
+		if i == 0 && start.After(now) {
+			start = now
+		}
+		q.Items[i] = ProductionQueueItem{Quantifiable: production[i], StartAt: start, EndAt: end, Remaining: int64(end.Sub(now).Seconds())}
+		end = start
+	}
+	return q
+}
+
+func (b *OGame) getProductionQueue(celestialID CelestialID) (ProductionQueue, error) {
+	production, countdown, err := b.getProduction(celestialID)
+	if err != nil {
+		return ProductionQueue{}, err
+	}
+	facilities, err := b.getFacilities(celestialID)
+	if err != nil {
+		return ProductionQueue{}, err
+	}
+	return newProductionQueue(production, countdown, time.Now(), func(item Quantifiable) time.Duration {
+		return b.constructionTime(item.ID, item.Nbr, facilities)
+	}), nil
+}
+
+// QueueShips orders nbr ships or defenses id on the celestial. The order is split across as many queue submissions
+// as needed, waiting for queue slots to free up when the queue is full. Returns the number of units queued, which is
+// less than nbr if ctx is done first or an order is not accepted (e.g. not enough resources).
+func (b *OGame) QueueShips(ctx context.Context, celestialID CelestialID, id ID, nbr int64) (int64, error) {
+	if !id.IsShip() && !id.IsDefense() {
+		return 0, errors.New("invalid id " + id.String())
+	}
+	var queued int64
+	for queued < nbr {
+		q, err := b.WithPriority(Normal).GetProductionQueue(celestialID)
+		if err != nil {
+			return queued, err
+		}
+		if q.Full {
+			wait := time.Until(q.Items[0].EndAt) + time.Second
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return queued, ctx.Err()
+			}
+			continue
+		}
+		// The shipyard takes at most maxProductionOrder units per order
+		batch := MinInt(nbr-queued, maxProductionOrder)
+		if err := b.WithPriority(Normal).BuildProduction(celestialID, id, batch); err != nil {
+			return queued, err
+		}
+		after, err := b.WithPriority(Normal).GetProductionQueue(celestialID)
+		if err != nil {
+			return queued, err
+		}
+		// The game can accept less than ordered (e.g. not enough resources), the queue tells how many were queued
+		accepted := MinInt(after.Count(id)-q.Count(id), batch)
+		if accepted <= 0 {
+			return queued, errors.New("order of " + id.String() + " not accepted by the shipyard")
+		}
+		queued += accepted
+	}
+	return queued, nil
+}
@@ -0,0 +1,35 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProductionQueue(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	production := []Quantifiable{{ID: LightFighterID, Nbr: 5}, {ID: RocketLauncherID, Nbr: 10}, {ID: LightFighterID, Nbr: 2}}
+	duration := func(item Quantifiable) time.Duration { return time.Duration(item.Nbr) * time.Minute }
+	q := newProductionQueue(production, 16*60, now, duration)
+	assert.False(t, q.Full)
+	assert.Equal(t, now.Add(16*time.Minute), q.EndAt)
+	assert.Equal(t, int64(7), q.Count(LightFighterID))
+	assert.Equal(t, 3, len(q.Items))
+	assert.Equal(t, now.Add(14*time.Minute), q.Items[2].StartAt)
+	assert.Equal(t, int64(16*60), q.Items[2].Remaining)
+	assert.Equal(t, now.Add(4*time.Minute), q.Items[1].StartAt)
+	assert.Equal(t, now.Add(14*time.Minute), q.Items[1].EndAt)
+	// The order being built is partially done
+	assert.Equal(t, now.Add(-time.Minute), q.Items[0].StartAt)
+	assert.Equal(t, int64(4*60), q.Items[0].Remaining)
+
+	// The first order never starts in the future
+	q = newProductionQueue(production[:1], 10*60, now, duration)
+	assert.Equal(t, now, q.Items[0].StartAt)
+
+	q = newProductionQueue(make([]Quantifiable, MaxProductionQueueItems), 0, now, duration)
+	assert.True(t, q.Full)
+	q = newProductionQueue(nil, 0, now, duration)
+	assert.Equal(t, 0, len(q.Items))
+}
@@ -0,0 +1,261 @@
+package ogame
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyConfig a proxy of a ProxyPool
+type ProxyConfig struct {
+	Address  string
+	Username string
+	Password string
+	Type     string // socks5 or http
+}
+
+// ProxyStatus health of a proxy of a ProxyPool
+type ProxyStatus struct {
+	Address   string
+	Type      string
+	Healthy   bool
+	Active    bool // Proxy currently used when rotation is disabled
+	Requests  int64
+	Failures  int64
+	LastError string
+	LastCheck time.Time
+}
+
+type poolProxy struct {
+	cfg       ProxyConfig
+	transport http.RoundTripper
+	healthy   bool
+	requests  int64
+	failures  int64
+	lastError string
+	lastCheck time.Time
+}
+
+// ProxyPool http.RoundTripper that sends requests through a list of proxies.
+// On connect errors the proxy is marked unhealthy and the request fails over to the next healthy proxy.
+// When rotate is enabled, every request uses the next healthy proxy.
+type ProxyPool struct {
+	sync.Mutex
+	proxies []*poolProxy
+	current int
+	rotate  bool
+}
+
+// NewProxyPool creates a proxy pool, all proxies start healthy
+func NewProxyPool(proxies []ProxyConfig, rotate bool, config *tls.Config) (*ProxyPool, error) {
+	if len(proxies) == 0 {
+		return nil, errors.New("empty proxy list")
+	}
+	p := &ProxyPool{rotate: rotate}
+	for _, cfg := range proxies {
+		if cfg.Type == "" {
+			cfg.Type = "socks5"
+		}
+		transport, err := getTransport(cfg.Address, cfg.Username, cfg.Password, cfg.Type, config)
+		if err != nil {
+			return nil, err
+		}
+		p.proxies = append(p.proxies, &poolProxy{cfg: cfg, transport: transport, healthy: true})
+	}
+	return p, nil
+}
+
+// ParseProxyList parses a proxies list, one proxy per line in the form [type://][username:password@]host:port.
+// Empty lines and lines starting with # are ignored. The type defaults to socks5.
+func ParseProxyList(r io.Reader) ([]ProxyConfig, error) {
+	res := make([]ProxyConfig, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, "://") {
+			line = "socks5://" + line
+		}
+		u, err := url.Parse(line)
+		if err != nil {
+			return nil, err
+		}
+		cfg := ProxyConfig{Type: u.Scheme}
+		if u.User != nil {
+			cfg.Username = u.User.Username()
+			cfg.Password, _ = u.User.Password()
+		}
+		if cfg.Type == "http" {
+			cfg.Address = u.Scheme + "://" + u.Host // http proxies are given as url
+		} else {
+			cfg.Address = u.Host
+		}
+		res = append(res, cfg)
+	}
+	return res, scanner.Err()
+}
+
+// isProxyConnectError returns true if the error happened while connecting to the proxy
+func isProxyConnectError(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		return opErr.Op == "dial" || opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks")
+	}
+	return false
+}
+
+// pick returns the index of the proxy to use, -1 if none is healthy
+func (p *ProxyPool) pick(exclude map[int]bool) int {
+	p.Lock()
+	defer p.Unlock()
+	start := p.current
+	if p.rotate {
+		start = (p.current + 1) % len(p.proxies)
+	}
+	for i := 0; i < len(p.proxies); i++ {
+		idx := (start + i) % len(p.proxies)
+		if p.proxies[idx].healthy && !exclude[idx] {
+			p.current = idx
+			p.proxies[idx].requests++
+			return idx
+		}
+	}
+	return -1
+}
+
+func (p *ProxyPool) report(idx int, err error) {
+	p.Lock()
+	defer p.Unlock()
+	proxy := p.proxies[idx]
+	proxy.lastCheck = time.Now()
+	if err == nil {
+		proxy.healthy = true
+		proxy.lastError = ""
+		return
+	}
+	proxy.healthy = false
+	proxy.failures++
+	proxy.lastError = err.Error()
+}
+
+// RoundTrip implements http.RoundTripper
+func (p *ProxyPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	tried := make(map[int]bool)
+	for {
+		idx := p.pick(tried)
+		if idx == -1 {
+			return nil, ErrNoHealthyProxy
+		}
+		tried[idx] = true
+		resp, err := p.proxies[idx].transport.RoundTrip(req)
+		if err == nil || !isProxyConnectError(err) {
+			if err == nil {
+				p.report(idx, nil)
+			}
+			return resp, err
+		}
+		p.report(idx, err)
+		// The body was consumed by the failed attempt, only retry if it can be replayed
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// HealthCheck sends a request to testURL through every proxy and updates their health
+func (p *ProxyPool) HealthCheck(testURL string) {
+	var wg sync.WaitGroup
+	for idx := range p.proxies {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			client := &http.Client{Transport: p.proxies[idx].transport, Timeout: 15 * time.Second}
+			resp, err := client.Get(testURL)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			p.report(idx, err)
+		}(idx)
+	}
+	wg.Wait()
+}
+
+// StartHealthChecks runs HealthCheck every interval until the returned function is called
+func (p *ProxyPool) StartHealthChecks(interval time.Duration, testURL string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.HealthCheck(testURL)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Status returns the health of every proxy of the pool
+func (p *ProxyPool) Status() []ProxyStatus {
+	p.Lock()
+	defer p.Unlock()
+	res := make([]ProxyStatus, 0, len(p.proxies))
+	for idx, proxy := range p.proxies {
+		res = append(res, ProxyStatus{
+			Address:   proxy.cfg.Address,
+			Type:      proxy.cfg.Type,
+			Healthy:   proxy.healthy,
+			Active:    !p.rotate && idx == p.current,
+			Requests:  proxy.requests,
+			Failures:  proxy.failures,
+			LastError: proxy.lastError,
+			LastCheck: proxy.lastCheck,
+		})
+	}
+	return res
+}
+
+// SetProxyPool uses a proxy pool instead of a single proxy, a nil pool resets the client transport to default value
+func (b *OGame) SetProxyPool(pool *ProxyPool, loginOnly bool) {
+	b.proxyPool = pool
+	if pool == nil {
+		b.loginProxyTransport = nil
+		b.Client.Transport = http.DefaultTransport
+		return
+	}
+	b.loginProxyTransport = pool
+	b.Client.Transport = pool
+	if loginOnly {
+		b.Client.Transport = http.DefaultTransport
+	}
+	if b.tlsFingerprint != "" {
+		if err := b.SetTLSFingerprint(b.tlsFingerprint); err != nil {
+			b.error("failed to apply tls fingerprint : ", err)
+		}
+	}
+}
+
+// GetProxyPool returns the proxy pool in use, nil if none
+func (b *OGame) GetProxyPool() *ProxyPool {
+	return b.proxyPool
+}
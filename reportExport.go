@@ -0,0 +1,113 @@
+package ogame
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+)
+
+// TrashSimURL url of the TrashSim combat simulator, the prefill data is given in the fragment
+var TrashSimURL = "https://trashsim.universeview.be/en#prefill="
+
+// TrashSimLevel research level in the TrashSim format
+type TrashSimLevel struct {
+	Level int64 `json:"level"`
+}
+
+// TrashSimCount ships or defenses quantity in the TrashSim format
+type TrashSimCount struct {
+	Count int64 `json:"count"`
+}
+
+// TrashSimPlanet coordinate in the TrashSim format
+type TrashSimPlanet struct {
+	Galaxy   int64 `json:"galaxy"`
+	System   int64 `json:"system"`
+	Position int64 `json:"position"`
+}
+
+// TrashSimResources resources in the TrashSim format
+type TrashSimResources struct {
+	Metal     int64 `json:"metal"`
+	Crystal   int64 `json:"crystal"`
+	Deuterium int64 `json:"deuterium"`
+}
+
+// TrashSimParty attacker or defender of a TrashSim simulation, maps are keyed by ogame id
+type TrashSimParty struct {
+	Class     CharacterClass           `json:"class"`
+	Planet    TrashSimPlanet           `json:"planet"`
+	Research  map[string]TrashSimLevel `json:"research,omitempty"`
+	Ships     map[string]TrashSimCount `json:"ships,omitempty"` // Ships and defenses
+	Resources *TrashSimResources       `json:"resources,omitempty"`
+}
+
+// TrashSimPrefill TrashSim prefill data, "0" holds the attackers and "1" the defenders
+type TrashSimPrefill map[string][]TrashSimParty
+
+// URL returns the TrashSim url opening the simulator with the prefill data
+func (p TrashSimPrefill) URL() (string, error) {
+	by, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return TrashSimURL + base64.StdEncoding.EncodeToString(by), nil
+}
+
+// NewTrashSimParty returns a TrashSim party with the researches relevant to combat
+func NewTrashSimParty(coord Coordinate, researches Researches, class CharacterClass) TrashSimParty {
+	p := TrashSimParty{
+		Class:    class,
+		Planet:   TrashSimPlanet{Galaxy: coord.Galaxy, System: coord.System, Position: coord.Position},
+		Research: make(map[string]TrashSimLevel),
+		Ships:    make(map[string]TrashSimCount),
+	}
+	for _, id := range []ID{WeaponsTechnologyID, ShieldingTechnologyID, ArmourTechnologyID, CombustionDriveID,
+		ImpulseDriveID, HyperspaceDriveID, HyperspaceTechnologyID} {
+		p.Research[strconv.FormatInt(int64(id), 10)] = TrashSimLevel{Level: researches.ByID(id)}
+	}
+	return p
+}
+
+// TrashSim converts the report to the TrashSim format, the target being the defender.
+// attacker is optional, e.g. NewTrashSimParty of the attacking planet with the player researches.
+func (r EspionageReport) TrashSim(attacker *TrashSimParty) TrashSimPrefill {
+	var researches Researches
+	if res := r.Researches(); res != nil {
+		researches = *res
+	}
+	defender := NewTrashSimParty(r.Coordinate, researches, r.CharacterClass)
+	defender.Resources = &TrashSimResources{Metal: r.Metal, Crystal: r.Crystal, Deuterium: r.Deuterium}
+	if ships := r.ShipsInfos(); ships != nil {
+		for _, s := range Ships {
+			if nbr := ships.ByID(s.GetID()); nbr > 0 {
+				defender.Ships[strconv.FormatInt(int64(s.GetID()), 10)] = TrashSimCount{Count: nbr}
+			}
+		}
+	}
+	if defenses := r.DefensesInfos(); defenses != nil {
+		for _, d := range Defenses {
+			if nbr := defenses.ByID(d.GetID()); nbr > 0 && d.GetID() != AntiBallisticMissilesID && d.GetID() != InterplanetaryMissilesID {
+				defender.Ships[strconv.FormatInt(int64(d.GetID()), 10)] = TrashSimCount{Count: nbr}
+			}
+		}
+	}
+	attackers := make([]TrashSimParty, 0)
+	if attacker != nil {
+		attackers = append(attackers, *attacker)
+	}
+	return TrashSimPrefill{"0": attackers, "1": []TrashSimParty{defender}}
+}
+
+// OGotchaExport report in the OGotcha import format, OGotcha reads the reports from their share keys
+type OGotchaExport struct {
+	APIKeys []string `json:"api_keys"`
+}
+
+// OGotcha converts the report to the OGotcha import format, fails if the report has no share key
+func (r EspionageReport) OGotcha() (OGotchaExport, error) {
+	if _, err := ParseShareKey(r.APIKey); err != nil {
+		return OGotchaExport{}, err
+	}
+	return OGotchaExport{APIKeys: []string{r.APIKey}}, nil
+}
@@ -0,0 +1,68 @@
+package ogame
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEspionageReport_TrashSim(t *testing.T) {
+	n := func(v int64) *int64 { return &v }
+	r := EspionageReport{
+		Resources:                Resources{Metal: 1000, Crystal: 2000, Deuterium: 3000},
+		Coordinate:               Coordinate{Galaxy: 1, System: 2, Position: 3, Type: PlanetType},
+		CharacterClass:           General,
+		HasFleetInformation:      true,
+		HasDefensesInformation:   true,
+		HasResearchesInformation: true,
+		WeaponsTechnology:        n(10),
+		ShieldingTechnology:      n(9),
+		ArmourTechnology:         n(8),
+		SmallCargo:               n(5),
+		RocketLauncher:           n(20),
+		AntiBallisticMissiles:    n(4),
+	}
+	attacker := NewTrashSimParty(Coordinate{Galaxy: 4, System: 5, Position: 6}, Researches{WeaponsTechnology: 12}, Collector)
+	prefill := r.TrashSim(&attacker)
+
+	assert.Equal(t, 1, len(prefill["0"]))
+	assert.Equal(t, int64(12), prefill["0"][0].Research["109"].Level)
+	assert.Equal(t, int64(4), prefill["0"][0].Planet.Galaxy)
+	assert.Nil(t, prefill["0"][0].Resources)
+
+	defender := prefill["1"][0]
+	assert.Equal(t, General, defender.Class)
+	assert.Equal(t, TrashSimPlanet{Galaxy: 1, System: 2, Position: 3}, defender.Planet)
+	assert.Equal(t, int64(10), defender.Research["109"].Level)
+	assert.Equal(t, int64(9), defender.Research["110"].Level)
+	assert.Equal(t, int64(8), defender.Research["111"].Level)
+	assert.Equal(t, map[string]TrashSimCount{"202": {Count: 5}, "401": {Count: 20}}, defender.Ships)
+	assert.Equal(t, &TrashSimResources{Metal: 1000, Crystal: 2000, Deuterium: 3000}, defender.Resources)
+
+	u, err := prefill.URL()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(u, TrashSimURL))
+	by, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(u, TrashSimURL))
+	assert.Nil(t, err)
+	var decoded TrashSimPrefill
+	assert.Nil(t, json.Unmarshal(by, &decoded))
+	assert.Equal(t, int64(20), decoded["1"][0].Ships["401"].Count)
+}
+
+func TestEspionageReport_TrashSimNoInformation(t *testing.T) {
+	prefill := EspionageReport{Coordinate: Coordinate{Galaxy: 1, System: 2, Position: 3}}.TrashSim(nil)
+	assert.Equal(t, 0, len(prefill["0"]))
+	assert.Equal(t, 0, len(prefill["1"][0].Ships))
+	assert.Equal(t, int64(0), prefill["1"][0].Research["109"].Level)
+}
+
+func TestEspionageReport_OGotcha(t *testing.T) {
+	export, err := EspionageReport{APIKey: "sr-en-101-0123abcd"}.OGotcha()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sr-en-101-0123abcd"}, export.APIKeys)
+	_, err = EspionageReport{}.OGotcha()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,96 @@
+package ogame
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MissingRequirement building or research level missing to build an object
+type MissingRequirement struct {
+	ID      ID
+	Name    string
+	Level   int64 // Level needed
+	Current int64 // Current level
+}
+
+// RequirementsError returned when the requirements of an object are not met, its cause is ErrRequirementsNotMet
+type RequirementsError struct {
+	ID      ID
+	Missing []MissingRequirement
+}
+
+func (e *RequirementsError) Error() string {
+	missing := make([]string, 0, len(e.Missing))
+	for _, m := range e.Missing {
+		missing = append(missing, m.Name+" "+strconv.FormatInt(m.Level, 10)+" (current "+strconv.FormatInt(m.Current, 10)+")")
+	}
+	name := e.ID.String()
+	if obj := Objs.ByID(e.ID); obj != nil {
+		name = obj.GetName()
+	}
+	return ErrRequirementsNotMet.Error() + " for " + name + ": " + strings.Join(missing, ", ")
+}
+
+// Cause returns ErrRequirementsNotMet, compatible with errors.Cause
+func (e *RequirementsError) Cause() error {
+	return ErrRequirementsNotMet
+}
+
+// MissingRequirements returns the buildings and research levels missing to build id, sorted by id.
+// The requirements of a missing requirement are checked too, e.g. the research lab level needed by a missing research.
+func MissingRequirements(id ID, resBuildings ResourcesBuildings, facilities Facilities, researches Researches) []MissingRequirement {
+	obj := Objs.ByID(id)
+	if obj == nil {
+		return nil
+	}
+	currentLevel := func(id ID) int64 {
+		if id.IsResourceBuilding() {
+			return resBuildings.ByID(id)
+		} else if id.IsFacility() {
+			return facilities.ByID(id)
+		}
+		return researches.ByID(id)
+	}
+	missing := make(map[ID]MissingRequirement)
+	var check func(requirements map[ID]int64)
+	check = func(requirements map[ID]int64) {
+		for reqID, level := range requirements {
+			current := currentLevel(reqID)
+			if current >= level {
+				continue
+			}
+			if m, ok := missing[reqID]; ok && m.Level >= level {
+				continue
+			}
+			reqObj := Objs.ByID(reqID)
+			missing[reqID] = MissingRequirement{ID: reqID, Name: reqObj.GetName(), Level: level, Current: current}
+			check(reqObj.GetRequirements())
+		}
+	}
+	check(obj.GetRequirements())
+	res := make([]MissingRequirement, 0, len(missing))
+	for _, m := range missing {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// RequirementsMet returns true if the buildings and researches levels meet the requirements of id
+func RequirementsMet(id ID, resBuildings ResourcesBuildings, facilities Facilities, researches Researches) bool {
+	return Objs.ByID(id) != nil && len(MissingRequirements(id, resBuildings, facilities, researches)) == 0
+}
+
+// getMissingRequirements returns the buildings and research levels missing to build id on the celestial
+func (b *OGame) getMissingRequirements(celestialID CelestialID, id ID) ([]MissingRequirement, error) {
+	resBuildings, err := b.getResourcesBuildings(celestialID)
+	if err != nil {
+		return nil, err
+	}
+	facilities, err := b.getFacilities(celestialID)
+	if err != nil {
+		return nil, err
+	}
+	return MissingRequirements(id, resBuildings, facilities, b.getResearch()), nil
+}
@@ -0,0 +1,30 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingRequirements(t *testing.T) {
+	assert.True(t, RequirementsMet(MetalMineID, ResourcesBuildings{}, Facilities{}, Researches{}))
+	assert.False(t, RequirementsMet(ID(9999), ResourcesBuildings{}, Facilities{}, Researches{}))
+
+	facilities := Facilities{RoboticsFactory: 2, Shipyard: 2}
+	researches := Researches{CombustionDrive: 6, EnergyTechnology: 1}
+	assert.True(t, RequirementsMet(SmallCargoID, ResourcesBuildings{}, facilities, researches))
+	assert.False(t, RequirementsMet(LargeCargoID, ResourcesBuildings{}, facilities, researches))
+	assert.Equal(t, []MissingRequirement{{ID: ShipyardID, Name: "shipyard", Level: 4, Current: 2}},
+		MissingRequirements(LargeCargoID, ResourcesBuildings{}, facilities, researches))
+
+	// The requirements of the missing research are missing too
+	missing := MissingRequirements(ImpulseDriveID, ResourcesBuildings{}, Facilities{}, Researches{})
+	assert.Equal(t, []MissingRequirement{
+		{ID: ResearchLabID, Name: "research lab", Level: 2, Current: 0},
+		{ID: EnergyTechnologyID, Name: "energy technology", Level: 1, Current: 0},
+	}, missing)
+
+	err := &RequirementsError{ID: ImpulseDriveID, Missing: missing}
+	assert.Equal(t, ErrCodeRequirementsNotMet, GetErrorCode(err))
+	assert.Equal(t, "requirements not met for impulse drive: research lab 2 (current 0), energy technology 1 (current 0)", err.Error())
+}
@@ -1,6 +1,16 @@
 package ogame
 
-import "strconv"
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Crawler setting limits, collectors can overload their crawlers up to 150%
+const (
+	MaxCrawlerSetting          int64 = 100
+	MaxCollectorCrawlerSetting int64 = 150
+)
 
 // ResourceSettings represent a planet resource settings
 type ResourceSettings struct {
@@ -11,6 +21,7 @@ type ResourceSettings struct {
 	FusionReactor        int64
 	SolarSatellite       int64
 	Crawler              int64
+	CrawlerCount         int64 // Number of crawlers on the planet, read only
 }
 
 func (r ResourceSettings) String() string {
@@ -23,3 +34,94 @@ func (r ResourceSettings) String() string {
 		"      Solar Satellite: " + strconv.FormatInt(r.SolarSatellite, 10) + "\n" +
 		"              Crawler: " + strconv.FormatInt(r.Crawler, 10)
 }
+
+// ProductionObjective weights of the resources in the production maximized by OptimizeResourceSettings
+type ProductionObjective struct {
+	Metal     float64
+	Crystal   float64
+	Deuterium float64
+}
+
+// Production objectives
+var (
+	DeuteriumObjective = ProductionObjective{Metal: 0, Crystal: 0, Deuterium: 1}
+	TradeRateObjective = ProductionObjective{Metal: 1, Crystal: 1.5, Deuterium: 3} // Metal equivalent at a 3:2:1 trade rate
+)
+
+func (o ProductionObjective) value(r Resources) float64 {
+	return o.Metal*float64(r.Metal) + o.Crystal*float64(r.Crystal) + o.Deuterium*float64(r.Deuterium)
+}
+
+// OptimizeResourceSettings returns the mines and fusion reactor percentages (10% steps) that maximize the objective
+// with a non-negative energy balance. The production and the energy include the officers, class, crawlers and boosters
+// bonuses of in. The solar plant and satellites are set to 100%, the crawler setting of in.Settings is kept.
+// Ties are broken by the total production.
+func OptimizeResourceSettings(in ProductionAuditInputs, objective ProductionObjective) ResourceSettings {
+	resBuildings := in.Buildings
+	best := ResourceSettings{SolarPlant: 100, SolarSatellite: 100, Crawler: in.Settings.Crawler, CrawlerCount: in.Settings.CrawlerCount}
+	bestValue, bestTotal := 0.0, int64(0)
+	first := true
+	steps := func(level int64) []int64 {
+		if level == 0 {
+			return []int64{0}
+		}
+		return []int64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	}
+	for _, fusion := range steps(resBuildings.FusionReactor) {
+		for _, metal := range steps(resBuildings.MetalMine) {
+			for _, crystal := range steps(resBuildings.CrystalMine) {
+				for _, deut := range steps(resBuildings.DeuteriumSynthesizer) {
+					settings := best
+					settings.MetalMine, settings.CrystalMine, settings.DeuteriumSynthesizer, settings.FusionReactor = metal, crystal, deut, fusion
+					candidate := in
+					candidate.Settings = settings
+					prod := productionWithBonuses(candidate)
+					if prod.Energy < 0 {
+						continue
+					}
+					value, total := objective.value(prod), prod.Metal+prod.Crystal+prod.Deuterium
+					if first || value > bestValue || (value == bestValue && total > bestTotal) {
+						best, bestValue, bestTotal, first = settings, value, total, false
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// setResourceSettingsAll applies the settings to every planet, the crawler count is ignored
+func (b *OGame) setResourceSettingsAll(settings ResourceSettings) error {
+	for _, planet := range b.getPlanets() {
+		if err := b.setResourceSettings(planet.ID, settings); err != nil {
+			return errors.Wrap(err, "planet "+strconv.FormatInt(int64(planet.ID), 10))
+		}
+	}
+	return nil
+}
+
+// optimizeResourceSettings computes the optimal settings of every planet, and applies them if apply is true
+func (b *OGame) optimizeResourceSettings(objective ProductionObjective, apply bool) (map[PlanetID]ResourceSettings, error) {
+	res := make(map[PlanetID]ResourceSettings)
+	researches := b.getResearch()
+	for _, planet := range b.getPlanets() {
+		resBuildings, err := b.getResourcesBuildings(planet.ID.Celestial())
+		if err != nil {
+			return res, err
+		}
+		current, err := b.getResourceSettings(planet.ID)
+		if err != nil {
+			return res, err
+		}
+		activeItems := b.getCachedActiveItems(planet.ID.Celestial())
+		in := b.productionInputs(resBuildings, current, researches, planet.Temperature, activeItems)
+		settings := OptimizeResourceSettings(in, objective)
+		if apply && settings != current {
+			if err := b.setResourceSettings(planet.ID, settings); err != nil {
+				return res, errors.Wrap(err, "planet "+strconv.FormatInt(int64(planet.ID), 10))
+			}
+		}
+		res[planet.ID] = settings
+	}
+	return res, nil
+}
@@ -26,3 +26,63 @@ func TestResourceSettings_String(t *testing.T) {
 		"              Crawler: 7"
 	assert.Equal(t, expected, r.String())
 }
+
+func TestOptimizeResourceSettings(t *testing.T) {
+	resBuildings := ResourcesBuildings{MetalMine: 20, CrystalMine: 18, DeuteriumSynthesizer: 15, SolarPlant: 20}
+	researches := Researches{EnergyTechnology: 10}
+	temp := Temperature{Min: 10, Max: 50}
+	current := ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100, SolarSatellite: 100, Crawler: 150}
+	in := ProductionAuditInputs{Buildings: resBuildings, Settings: current, Researches: researches, Temperature: temp, UniverseSpeed: 1}
+
+	res := OptimizeResourceSettings(in, DeuteriumObjective)
+	assert.Equal(t, int64(100), res.DeuteriumSynthesizer)
+	assert.Equal(t, int64(100), res.SolarPlant)
+	assert.Equal(t, int64(150), res.Crawler)
+	assert.Equal(t, int64(0), res.FusionReactor)
+	assert.True(t, getProductions(resBuildings, res, researches, 1, temp, 1).Energy >= 0)
+
+	// Not enough energy for every mine, the metal mine is cut first when maximizing deuterium
+	in.Buildings.SolarPlant = 17
+	res = OptimizeResourceSettings(in, DeuteriumObjective)
+	assert.Equal(t, int64(100), res.DeuteriumSynthesizer)
+	assert.True(t, res.MetalMine < 100)
+	assert.True(t, getProductions(in.Buildings, res, researches, 1, temp, 1).Energy >= 0)
+
+	res = OptimizeResourceSettings(in, ProductionObjective{Metal: 1})
+	assert.Equal(t, int64(100), res.MetalMine)
+	assert.True(t, res.DeuteriumSynthesizer < 100)
+	assert.True(t, getProductions(in.Buildings, res, researches, 1, temp, 1).Energy >= 0)
+}
+
+func TestOptimizeResourceSettings_Bonuses(t *testing.T) {
+	current := ResourceSettings{MetalMine: 100, CrystalMine: 100, DeuteriumSynthesizer: 100, SolarPlant: 100, SolarSatellite: 100, Crawler: 100}
+	in := ProductionAuditInputs{
+		Buildings:     ResourcesBuildings{MetalMine: 20, CrystalMine: 18, DeuteriumSynthesizer: 15, SolarPlant: 21},
+		Settings:      current,
+		Researches:    Researches{EnergyTechnology: 10},
+		Temperature:   Temperature{Min: 10, Max: 50},
+		UniverseSpeed: 1,
+	}
+	withSettings := func(in ProductionAuditInputs, settings ResourceSettings) ProductionAuditInputs {
+		in.Settings = settings
+		return in
+	}
+
+	// Without the bonuses, the solar plant cannot power every mine
+	res := OptimizeResourceSettings(in, ProductionObjective{Metal: 1})
+	assert.True(t, res.DeuteriumSynthesizer < 100)
+
+	// The energy bonuses of the engineer and the collector class power every mine
+	in.Engineer, in.Collector = true, true
+	res = OptimizeResourceSettings(in, ProductionObjective{Metal: 1})
+	assert.Equal(t, current, res)
+	assert.True(t, productionWithBonuses(withSettings(in, res)).Energy >= 0)
+
+	// The crawlers energy is taken from the mines
+	in.Settings.CrawlerCount = 10
+	res = OptimizeResourceSettings(in, ProductionObjective{Metal: 1})
+	assert.Equal(t, int64(100), res.MetalMine)
+	assert.True(t, res.DeuteriumSynthesizer < 100)
+	assert.Equal(t, int64(10), res.CrawlerCount)
+	assert.True(t, productionWithBonuses(withSettings(in, res)).Energy >= 0)
+}
@@ -0,0 +1,104 @@
+package ogame
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ServerError returned when the OGame server answers with a 5xx status code
+type ServerError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error implements the error interface
+func (e *ServerError) Error() string {
+	return "ogame server error: " + e.Status
+}
+
+// RetryPolicy controls how requests failing with a transient error are retried
+type RetryPolicy struct {
+	// MaxRetries number of retries after the first attempt, 0 disables retries
+	MaxRetries int
+	// InitialInterval wait before the first retry
+	InitialInterval time.Duration
+	// MaxInterval upper bound of the wait between retries
+	MaxInterval time.Duration
+	// Multiplier growth of the wait between two retries
+	Multiplier float64
+	// Jitter random variation of the wait, 0.2 means +/- 20%
+	Jitter float64
+	// IsTransient returns true for the errors worth retrying, IsTransientError if nil
+	IsTransient func(error) bool
+}
+
+// DefaultRetryPolicy retries up to 9 times, waiting 1s, 2s, 4s... up to 1 minute between retries
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:      9,
+	InitialInterval: time.Second,
+	MaxInterval:     time.Minute,
+	Multiplier:      2,
+}
+
+// IsTransientError returns true for timeouts, connection errors, 500/502/503/504 and truncated responses
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	if serverErr, ok := err.(*ServerError); ok {
+		switch serverErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return isServerFailure(err)
+}
+
+func (p RetryPolicy) isTransient(err error) bool {
+	if p.IsTransient != nil {
+		return p.IsTransient(err)
+	}
+	return IsTransientError(err)
+}
+
+// backoff returns the wait before the retry number attempt (starting at 0)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	interval := float64(p.InitialInterval)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 0; i < attempt; i++ {
+		interval *= multiplier
+		if p.MaxInterval > 0 && interval >= float64(p.MaxInterval) {
+			interval = float64(p.MaxInterval)
+			break
+		}
+	}
+	if p.Jitter > 0 {
+		interval += interval * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	return time.Duration(interval)
+}
+
+func (b *OGame) getRetryPolicy() RetryPolicy {
+	b.retryPolicyMu.RLock()
+	defer b.retryPolicyMu.RUnlock()
+	return b.retryPolicy
+}
+
+// SetRetryPolicy sets how transient failures are retried
+func (b *OGame) SetRetryPolicy(policy RetryPolicy) {
+	b.retryPolicyMu.Lock()
+	defer b.retryPolicyMu.Unlock()
+	b.retryPolicy = policy
+}
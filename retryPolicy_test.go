@@ -0,0 +1,66 @@
+package ogame
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransientError(t *testing.T) {
+	assert.False(t, IsTransientError(nil))
+	assert.False(t, IsTransientError(errors.New("invalid planet id")))
+	assert.True(t, IsTransientError(io.ErrUnexpectedEOF))
+	assert.True(t, IsTransientError(&ServerError{StatusCode: 502, Status: "502 Bad Gateway"}))
+	assert.True(t, IsTransientError(&ServerError{StatusCode: 503, Status: "503 Service Unavailable"}))
+	assert.False(t, IsTransientError(&ServerError{StatusCode: 501, Status: "501 Not Implemented"}))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := DefaultRetryPolicy
+	assert.Equal(t, time.Second, p.backoff(0))
+	assert.Equal(t, 2*time.Second, p.backoff(1))
+	assert.Equal(t, 4*time.Second, p.backoff(2))
+	assert.Equal(t, time.Minute, p.backoff(10))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1)
+		assert.True(t, d >= time.Second && d <= 3*time.Second)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.isLoggedInAtom = 1
+	b.SetRetryPolicy(RetryPolicy{MaxRetries: 2, InitialInterval: time.Millisecond})
+
+	calls := 0
+	err := b.withRetry(func() error {
+		calls++
+		return &ServerError{StatusCode: 503, Status: "503 Service Unavailable"}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = b.withRetry(func() error {
+		calls++
+		return errors.New("permanent")
+	})
+	assert.EqualError(t, err, "permanent")
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = b.withRetry(func() error {
+		calls++
+		if calls < 2 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
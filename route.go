@@ -0,0 +1,73 @@
+package ogame
+
+// JumpGateMoon a moon equipped with a jump gate that can be used as a shortcut when routing fleets
+type JumpGateMoon struct {
+	Coordinate Coordinate
+	Cooldown   int64 // Seconds before the jump gate can be used again
+}
+
+// RouteLeg a single step of a route, either a normal flight or a jump gate jump
+type RouteLeg struct {
+	Origin      Coordinate
+	Destination Coordinate
+	IsJump      bool
+	Wait        int64 // Seconds spent waiting for a jump gate cooldown before that leg
+	Secs        int64
+	Fuel        int64
+}
+
+// Route the legs needed to go from an origin to a destination
+type Route struct {
+	Legs []RouteLeg
+	Secs int64
+	Fuel int64
+}
+
+// FastestRoute computes the fastest way to go from origin to destination.
+// It compares the direct flight with every possible path going through a pair of jump gates
+// (fly to a gate moon, jump, fly from the destination moon).
+// flightTime is used to compute the duration and fuel consumption of the normal flight legs.
+func FastestRoute(origin, destination Coordinate, gates []JumpGateMoon,
+	flightTime func(origin, destination Coordinate) (secs, fuel int64)) Route {
+	fly := func(from, to Coordinate) RouteLeg {
+		secs, fuel := flightTime(from, to)
+		return RouteLeg{Origin: from, Destination: to, Secs: secs, Fuel: fuel}
+	}
+	newRoute := func(legs ...RouteLeg) Route {
+		r := Route{}
+		for _, leg := range legs {
+			if !leg.IsJump && leg.Origin.Equal(leg.Destination) {
+				continue
+			}
+			r.Legs = append(r.Legs, leg)
+			r.Secs += leg.Wait + leg.Secs
+			r.Fuel += leg.Fuel
+		}
+		return r
+	}
+
+	best := newRoute(fly(origin, destination))
+	for _, from := range gates {
+		for _, to := range gates {
+			if from.Coordinate.Equal(to.Coordinate) {
+				continue
+			}
+			toGate := fly(origin, from.Coordinate)
+			// Both gates must be ready before jumping, the cooldowns run while we fly to the first gate
+			wait := MaxInt(0, from.Cooldown-toGate.Secs, to.Cooldown-toGate.Secs)
+			jump := RouteLeg{Origin: from.Coordinate, Destination: to.Coordinate, IsJump: true, Wait: wait}
+			candidate := newRoute(toGate, jump, fly(to.Coordinate, destination))
+			if candidate.Secs < best.Secs || (candidate.Secs == best.Secs && candidate.Fuel < best.Fuel) {
+				best = candidate
+			}
+		}
+	}
+	return best
+}
+
+// FastestRoute computes the fastest way to go from origin to destination using the provided jump gates
+func (b *OGame) FastestRoute(origin, destination Coordinate, speed Speed, ships ShipsInfos, missionID MissionID, gates []JumpGateMoon) Route {
+	return FastestRoute(origin, destination, gates, func(o, d Coordinate) (secs, fuel int64) {
+		return b.FlightTime(o, d, speed, ships, missionID)
+	})
+}
@@ -0,0 +1,50 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFastestRoute(t *testing.T) {
+	flightTime := func(origin, destination Coordinate) (int64, int64) {
+		d := Distance(origin, destination, 9, 499, true, true)
+		return d, d / 10
+	}
+	origin := Coordinate{1, 100, 8, PlanetType}
+	destination := Coordinate{5, 200, 8, PlanetType}
+
+	// No gates, direct flight
+	route := FastestRoute(origin, destination, nil, flightTime)
+	assert.Equal(t, 1, len(route.Legs))
+	assert.Equal(t, int64(80000), route.Secs)
+	assert.Equal(t, int64(8000), route.Fuel)
+
+	// Gates next to origin and destination
+	gates := []JumpGateMoon{
+		{Coordinate: Coordinate{1, 100, 8, MoonType}},
+		{Coordinate: Coordinate{5, 200, 8, MoonType}},
+	}
+	route = FastestRoute(origin, destination, gates, flightTime)
+	assert.Equal(t, 3, len(route.Legs))
+	assert.True(t, route.Legs[1].IsJump)
+	assert.Equal(t, int64(10), route.Secs)
+
+	// Cooldown is taken into account
+	gates[1].Cooldown = 100
+	route = FastestRoute(origin, destination, gates, flightTime)
+	assert.Equal(t, int64(95), route.Legs[1].Wait)
+	assert.Equal(t, int64(105), route.Secs)
+
+	// Cooldown too long, direct flight is faster
+	gates[1].Cooldown = 100000
+	route = FastestRoute(origin, destination, gates, flightTime)
+	assert.Equal(t, 1, len(route.Legs))
+	assert.False(t, route.Legs[0].IsJump)
+
+	// Starting from a gate moon skips the first flight leg
+	gates[1].Cooldown = 0
+	route = FastestRoute(gates[0].Coordinate, destination, gates, flightTime)
+	assert.Equal(t, 2, len(route.Legs))
+	assert.Equal(t, int64(5), route.Secs)
+}
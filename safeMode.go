@@ -0,0 +1,51 @@
+package ogame
+
+import "sync/atomic"
+
+// DefaultSafeModeThreshold suggested number of consecutive failed tasks before the bot enters safe mode.
+// Safe mode is disabled unless Params.SafeModeThreshold is set.
+const DefaultSafeModeThreshold = 10
+
+// trackTaskResult counts the consecutive failed tasks and enters safe mode once the threshold is reached.
+// err is the last error of the task, nil if it did not fail.
+func (b *OGame) trackTaskResult(err error) {
+	if err == nil {
+		atomic.StoreInt32(&b.consecutiveFailedTasks, 0)
+		return
+	}
+	if err == ErrBotInactive || err == ErrBotLoggedOut || err == ErrSafeMode || err == ErrServerUnavailable {
+		return
+	}
+	nbFailed := atomic.AddInt32(&b.consecutiveFailedTasks, 1)
+	if b.safeModeThreshold > 0 && nbFailed >= b.safeModeThreshold {
+		b.enterSafeMode(err)
+	}
+}
+
+func (b *OGame) enterSafeMode(err error) {
+	if !atomic.CompareAndSwapInt32(&b.safeModeAtom, 0, 1) {
+		return
+	}
+	b.critical("entering safe mode, all requests are paused until Resume is called. last error:", err)
+	for _, clb := range b.safeModeCallbacks {
+		clb(err)
+	}
+}
+
+// IsInSafeMode returns either or not the bot paused all requests after too many consecutive failed tasks
+func (b *OGame) IsInSafeMode() bool {
+	return atomic.LoadInt32(&b.safeModeAtom) == 1
+}
+
+// Resume leaves safe mode. The session is kept, so requests can be executed right away.
+func (b *OGame) Resume() {
+	atomic.StoreInt32(&b.consecutiveFailedTasks, 0)
+	if atomic.CompareAndSwapInt32(&b.safeModeAtom, 1, 0) {
+		b.info("leaving safe mode")
+	}
+}
+
+// OnSafeMode register a callback that is notified when the bot enters safe mode
+func (b *OGame) OnSafeMode(clb func(err error)) {
+	b.safeModeCallbacks = append(b.safeModeCallbacks, clb)
+}
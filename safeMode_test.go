@@ -0,0 +1,51 @@
+package ogame
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeMode(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.safeModeThreshold = 3
+	var notified error
+	b.OnSafeMode(func(err error) { notified = err })
+	someErr := errors.New("some error")
+	// runTask runs a task that got err from its requests
+	runTask := func(errs ...error) {
+		_ = b.WithPriority(Normal).Tx(func(tx Prioritizable) error {
+			taskID := b.lockedTaskID()
+			for _, err := range errs {
+				b.recordTaskError(taskID, err)
+			}
+			return nil
+		})
+	}
+	runTask(someErr, someErr, someErr) // Requests failures of a single task
+	runTask(someErr)
+	runTask()
+	runTask(someErr)
+	runTask(ErrBotLoggedOut)
+	runTask(someErr)
+	assert.False(t, b.IsInSafeMode())
+	runTask(someErr)
+	assert.True(t, b.IsInSafeMode())
+	assert.Equal(t, someErr, notified)
+
+	// Resume clears the count of failed tasks
+	b.Resume()
+	assert.False(t, b.IsInSafeMode())
+	runTask(someErr)
+	runTask(someErr)
+	assert.False(t, b.IsInSafeMode())
+}
+
+func TestSafeMode_DisabledByDefault(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	for i := 0; i < DefaultSafeModeThreshold+1; i++ {
+		_ = b.WithPriority(Normal).Tx(func(tx Prioritizable) error { return errors.New("some error") })
+	}
+	assert.False(t, b.IsInSafeMode())
+}
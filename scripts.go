@@ -0,0 +1,460 @@
+package ogame
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Script lua script of the scripts directory.
+// The script defines a run(event) function, and a trigger table to run it every interval and/or on events:
+//
+//	trigger = { every = "10m", on = { "attack.detected" } }
+//	function run(event) ... end
+//
+// The trigger is read by running the top level of the script without the bot, log and sleep globals, and only the
+// base, table, string and math libraries. They can only be used in run.
+type Script struct {
+	Name   string
+	Path   string
+	Every  time.Duration
+	Events []EventType
+}
+
+func (s *Script) handles(t EventType) bool {
+	for _, e := range s.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// luaObject Go value exposed to lua, only the methods of the interface typ can be called
+type luaObject struct {
+	val reflect.Value
+	typ reflect.Type
+}
+
+const luaObjectTypeName = "ogame.object"
+
+var (
+	wrapperType = reflect.TypeOf((*Wrapper)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// newLuaState returns a lua state exposing the bot (the Wrapper API), log(...) and sleep(seconds) to the script name
+func (b *OGame) newLuaState(ctx context.Context, name string) *lua.LState {
+	L := lua.NewState()
+	L.SetContext(ctx)
+	mt := L.NewTypeMetatable(luaObjectTypeName)
+	L.SetField(mt, "__index", L.NewFunction(luaObjectIndex))
+	L.SetGlobal("bot", newLuaObject(L, reflect.ValueOf(Wrapper(b)), wrapperType))
+	L.SetGlobal("log", L.NewFunction(func(L *lua.LState) int {
+		args := make([]string, 0, L.GetTop())
+		for i := 1; i <= L.GetTop(); i++ {
+			args = append(args, L.Get(i).String())
+		}
+		b.info("script " + name + ": " + strings.Join(args, " "))
+		return 0
+	}))
+	L.SetGlobal("sleep", L.NewFunction(func(L *lua.LState) int {
+		select {
+		case <-time.After(time.Duration(float64(L.CheckNumber(1)) * float64(time.Second))):
+		case <-ctx.Done():
+			L.RaiseError("script stopped")
+		}
+		return 0
+	}))
+	return L
+}
+
+// scriptLoadTimeout time the top level of a script can run when its trigger is read
+const scriptLoadTimeout = 5 * time.Second
+
+// newLuaLoadState returns the sandbox lua state in which the trigger of a script is read. The bot, log and sleep
+// globals raise an error, so a script does not act on the bot when it is loaded.
+func newLuaLoadState(ctx context.Context) *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	L.SetContext(ctx)
+	libs := []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	}
+	for _, lib := range libs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	L.SetGlobal("dofile", lua.LNil)
+	L.SetGlobal("loadfile", lua.LNil)
+	unavailable := func(name string) *lua.LFunction {
+		return L.NewFunction(func(L *lua.LState) int {
+			L.RaiseError(name + " can only be used in run")
+			return 0
+		})
+	}
+	bot := L.NewTable()
+	mt := L.NewTable()
+	L.SetField(mt, "__index", unavailable("bot"))
+	L.SetMetatable(bot, mt)
+	L.SetGlobal("bot", bot)
+	L.SetGlobal("log", unavailable("log"))
+	L.SetGlobal("sleep", unavailable("sleep"))
+	return L
+}
+
+func newLuaObject(L *lua.LState, val reflect.Value, typ reflect.Type) *lua.LUserData {
+	ud := L.NewUserData()
+	ud.Value = luaObject{val: val, typ: typ}
+	L.SetMetatable(ud, L.GetTypeMetatable(luaObjectTypeName))
+	return ud
+}
+
+// luaObjectIndex returns the method of a luaObject, it can be called with obj.Method(...) or obj:Method(...)
+func luaObjectIndex(L *lua.LState) int {
+	ud := L.CheckUserData(1)
+	obj, ok := ud.Value.(luaObject)
+	if !ok {
+		L.ArgError(1, "object expected")
+	}
+	name := L.CheckString(2)
+	if _, ok := obj.typ.MethodByName(name); !ok {
+		L.Push(lua.LNil)
+		return 1
+	}
+	method := obj.val.MethodByName(name)
+	L.Push(L.NewFunction(func(L *lua.LState) int {
+		args := make([]lua.LValue, 0, L.GetTop())
+		for i := 1; i <= L.GetTop(); i++ {
+			args = append(args, L.Get(i))
+		}
+		if len(args) > 0 && args[0] == ud {
+			args = args[1:]
+		}
+		return callLuaMethod(L, name, method, args)
+	}))
+	return 1
+}
+
+// callLuaMethod calls a Go method with lua arguments, an error result is returned last as a string (nil if no error)
+func callLuaMethod(L *lua.LState, name string, method reflect.Value, args []lua.LValue) (n int) {
+	mt := method.Type()
+	nbIn := mt.NumIn()
+	if (!mt.IsVariadic() && len(args) != nbIn) || (mt.IsVariadic() && len(args) < nbIn-1) {
+		L.RaiseError("%s expects %d arguments, got %d", name, nbIn, len(args))
+	}
+	in := make([]reflect.Value, 0, len(args))
+	for i, arg := range args {
+		var t reflect.Type
+		if mt.IsVariadic() && i >= nbIn-1 {
+			t = mt.In(nbIn - 1).Elem()
+		} else {
+			t = mt.In(i)
+		}
+		v, err := luaToGo(arg, t)
+		if err != nil {
+			L.RaiseError("%s argument %d: %s", name, i+1, err.Error())
+		}
+		in = append(in, v)
+	}
+	var out []reflect.Value
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				L.RaiseError("%s: %v", name, r)
+			}
+		}()
+		out = method.Call(in)
+	}()
+	for i, v := range out {
+		if mt.Out(i) == errorType {
+			if err, _ := v.Interface().(error); err != nil {
+				L.Push(lua.LString(err.Error()))
+			} else {
+				L.Push(lua.LNil)
+			}
+		} else if mt.Out(i).Kind() == reflect.Interface && mt.Out(i).NumMethod() > 0 && !v.IsNil() {
+			L.Push(newLuaObject(L, v, mt.Out(i)))
+		} else {
+			lv, err := goToLua(L, v.Interface())
+			if err != nil {
+				L.RaiseError("%s result %d: %s", name, i+1, err.Error())
+			}
+			L.Push(lv)
+		}
+		n++
+	}
+	return n
+}
+
+// luaToInterface converts a lua value to the Go value of its JSON representation
+func luaToInterface(lv lua.LValue) interface{} {
+	switch v := lv.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.MaxN(); n > 0 {
+			arr := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				arr = append(arr, luaToInterface(v.RawGetInt(i)))
+			}
+			return arr
+		}
+		m := make(map[string]interface{})
+		v.ForEach(func(k, val lua.LValue) { m[k.String()] = luaToInterface(val) })
+		return m
+	case *lua.LUserData:
+		if obj, ok := v.Value.(luaObject); ok {
+			return obj.val.Interface()
+		}
+	}
+	return nil
+}
+
+// luaToGo converts a lua value to a Go value of type t, coordinates can be given as strings (eg: "1:2:3", "M:1:2:3")
+// and structs as tables with the field names as keys (eg: { ID = 203, Nbr = 10 })
+func luaToGo(lv lua.LValue, t reflect.Type) (reflect.Value, error) {
+	if s, ok := lv.(lua.LString); ok && t == reflect.TypeOf(Coordinate{}) {
+		coord, err := ParseCoord(string(s))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(coord), nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		n, ok := lv.(lua.LNumber)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("number expected, got %s", lv.Type())
+		}
+		return reflect.ValueOf(float64(n)).Convert(t), nil
+	case reflect.String:
+		s, ok := lv.(lua.LString)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("string expected, got %s", lv.Type())
+		}
+		return reflect.ValueOf(string(s)).Convert(t), nil
+	case reflect.Bool:
+		return reflect.ValueOf(lua.LVAsBool(lv)).Convert(t), nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return reflect.Value{}, fmt.Errorf("%s arguments are not supported", t)
+	case reflect.Interface:
+		v := luaToInterface(lv)
+		if v == nil {
+			return reflect.Zero(t), nil
+		}
+		if !reflect.TypeOf(v).Implements(t) {
+			return reflect.Value{}, fmt.Errorf("%s expected, got %s", t, lv.Type())
+		}
+		return reflect.ValueOf(v), nil
+	case reflect.Slice:
+		if tbl, ok := lv.(*lua.LTable); ok {
+			if k, _ := tbl.Next(lua.LNil); k == lua.LNil {
+				return reflect.MakeSlice(t, 0, 0), nil
+			}
+		}
+	}
+	by, err := json.Marshal(luaToInterface(lv))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(by, v.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid %s: %v", t, err)
+	}
+	return v.Elem(), nil
+}
+
+// goToLua converts a Go value to lua through its JSON representation
+func goToLua(L *lua.LState, v interface{}) (lua.LValue, error) {
+	by, err := json.Marshal(v)
+	if err != nil {
+		return lua.LNil, err
+	}
+	var i interface{}
+	if err := json.Unmarshal(by, &i); err != nil {
+		return lua.LNil, err
+	}
+	return interfaceToLua(L, i), nil
+}
+
+func interfaceToLua(L *lua.LState, v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []interface{}:
+		tbl := L.NewTable()
+		for _, e := range v {
+			tbl.Append(interfaceToLua(L, e))
+		}
+		return tbl
+	case map[string]interface{}:
+		tbl := L.NewTable()
+		for k, e := range v {
+			tbl.RawSetString(k, interfaceToLua(L, e))
+		}
+		return tbl
+	}
+	return lua.LNil
+}
+
+// loadScript reads the trigger of a script, the script must define a run function
+func (b *OGame) loadScript(path string) (*Script, error) {
+	script := &Script{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), Path: path}
+	ctx, cancel := context.WithTimeout(context.Background(), scriptLoadTimeout)
+	defer cancel()
+	L := newLuaLoadState(ctx)
+	defer L.Close()
+	if err := L.DoFile(path); err != nil {
+		return nil, err
+	}
+	if L.GetGlobal("run").Type() != lua.LTFunction {
+		return nil, errors.New(script.Name + ": run function not defined")
+	}
+	trigger, ok := L.GetGlobal("trigger").(*lua.LTable)
+	if !ok {
+		return script, nil
+	}
+	if every := trigger.RawGetString("every"); every != lua.LNil {
+		d, err := time.ParseDuration(every.String())
+		if err != nil || d <= 0 {
+			return nil, errors.New(script.Name + ": invalid trigger interval " + every.String())
+		}
+		script.Every = d
+	}
+	switch on := trigger.RawGetString("on").(type) {
+	case lua.LString:
+		script.Events = append(script.Events, EventType(on))
+	case *lua.LTable:
+		on.ForEach(func(_, v lua.LValue) { script.Events = append(script.Events, EventType(v.String())) })
+	}
+	return script, nil
+}
+
+// loadScripts loads the .lua scripts of dir, sorted by name
+func (b *OGame) loadScripts(dir string) ([]*Script, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	scripts := make([]*Script, 0)
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".lua" {
+			continue
+		}
+		script, err := b.loadScript(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+	sort.Slice(scripts, func(i, j int) bool { return scripts[i].Name < scripts[j].Name })
+	return scripts, nil
+}
+
+// runScript runs the run function of a script, with the event that triggered it (nil for a scheduled run)
+func (b *OGame) runScript(ctx context.Context, script *Script, event *Event) error {
+	L := b.newLuaState(ctx, script.Name)
+	defer L.Close()
+	if err := L.DoFile(script.Path); err != nil {
+		return err
+	}
+	arg := lua.LValue(lua.LNil)
+	if event != nil {
+		lv, err := goToLua(L, event)
+		if err != nil {
+			return err
+		}
+		arg = lv
+	}
+	return L.CallByParam(lua.P{Fn: L.GetGlobal("run"), NRet: 0, Protect: true}, arg)
+}
+
+// StartScripts loads the lua scripts of dir and runs them on their schedule and events until the returned function
+// is called. The scripts use the Wrapper API through the bot global (eg: bot.GetPlanets(), bot.WithPriority(3).SendFleet(...)),
+// the methods return their error last as a string. A script is not run again while a previous run is not done.
+func (b *OGame) StartScripts(dir string) (stop func(), err error) {
+	scripts, err := b.loadScripts(dir)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(b.ctx)
+	var runningMu sync.Mutex
+	running := make(map[string]bool)
+	run := func(script *Script, event *Event) {
+		runningMu.Lock()
+		if running[script.Name] {
+			runningMu.Unlock()
+			b.warn("script " + script.Name + " is still running, skipped")
+			return
+		}
+		running[script.Name] = true
+		runningMu.Unlock()
+		err := b.runScript(ctx, script, event)
+		runningMu.Lock()
+		running[script.Name] = false
+		runningMu.Unlock()
+		if err != nil && ctx.Err() == nil {
+			b.error("script " + script.Name + " failed: " + err.Error())
+		}
+	}
+	for _, script := range scripts {
+		if script.Every <= 0 && len(script.Events) == 0 {
+			b.warn("script " + script.Name + " has no trigger, it will not run")
+		}
+		if script.Every > 0 {
+			go func(script *Script) {
+				for {
+					select {
+					case <-time.After(script.Every):
+					case <-ctx.Done():
+						return
+					}
+					if b.isEnabled() {
+						run(script, nil)
+					}
+				}
+			}(script)
+		}
+	}
+	unsubscribe := b.Subscribe("", func(e Event) {
+		for _, script := range scripts {
+			if script.handles(e.Type) {
+				go run(script, &e)
+			}
+		}
+	})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			cancel()
+		})
+	}, nil
+}
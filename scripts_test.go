@@ -0,0 +1,135 @@
+package ogame
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func writeScript(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadScripts(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ogame-scripts")
+	defer os.RemoveAll(dir)
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	writeScript(t, dir, "escape.lua", `trigger = { on = "attack.detected" }
+function run(event) end`)
+	writeScript(t, dir, "farm.lua", `trigger = { every = "10m", on = { "fleet.returned", "fleet.sent" } }
+function run(event) end`)
+	writeScript(t, dir, "notes.txt", `not a script`)
+	scripts, err := b.loadScripts(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scripts))
+	assert.Equal(t, "escape", scripts[0].Name)
+	assert.Equal(t, []EventType{AttackDetectedEvent}, scripts[0].Events)
+	assert.Equal(t, time.Duration(0), scripts[0].Every)
+	assert.Equal(t, 10*time.Minute, scripts[1].Every)
+	assert.True(t, scripts[1].handles(FleetSentEvent))
+	assert.False(t, scripts[1].handles(AttackDetectedEvent))
+
+	writeScript(t, dir, "broken.lua", `trigger = { every = "soon" }
+function run(event) end`)
+	_, err = b.loadScripts(dir)
+	assert.NotNil(t, err)
+	writeScript(t, dir, "broken.lua", `trigger = { every = "1m" }`)
+	_, err = b.loadScripts(dir)
+	assert.NotNil(t, err)
+}
+
+func TestLoadScript_Sandbox(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ogame-scripts")
+	defer os.RemoveAll(dir)
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+
+	// The top level of the script does not reach the bot
+	path := writeScript(t, dir, "alias.lua", `bot.SetCelestialAlias("main", 1)
+trigger = { every = "1m" }
+function run(event) end`)
+	_, err := b.loadScript(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bot can only be used in run")
+	_, ok := b.ResolveCelestialAlias("main")
+	assert.False(t, ok)
+
+	path = writeScript(t, dir, "os.lua", `os.remove("file")
+function run(event) end`)
+	_, err = b.loadScript(path)
+	assert.Error(t, err)
+
+	// The trigger can still be computed
+	path = writeScript(t, dir, "computed.lua", `local events = {}
+table.insert(events, string.format("%s.%s", "attack", "detected"))
+trigger = { every = math.max(1, 2) .. "m", on = events }
+function run(event) end`)
+	script, err := b.loadScript(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, script.Every)
+	assert.Equal(t, []EventType{AttackDetectedEvent}, script.Events)
+}
+
+func TestRunScript(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ogame-scripts")
+	defer os.RemoveAll(dir)
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	path := writeScript(t, dir, "alias.lua", `function run(event)
+	bot.SetCelestialAlias(event.type, event.data.id)
+	local id, ok = bot:ResolveCelestialAlias(event.type)
+	if not ok or id ~= event.data.id then error("alias not set") end
+	local err = bot.SetTLSFingerprint("unknown")
+	if err == nil then error("error expected") end
+	local tx = bot.WithPriority(3).Begin()
+	tx:Done()
+end`)
+	script, err := b.loadScript(path)
+	assert.Nil(t, err)
+	event := newEvent(AttackDetectedEvent, EventAttackData{ID: 123})
+	assert.Nil(t, b.runScript(context.Background(), script, &event))
+	id, ok := b.ResolveCelestialAlias(string(AttackDetectedEvent))
+	assert.True(t, ok)
+	assert.Equal(t, CelestialID(123), id)
+
+	path = writeScript(t, dir, "fail.lua", `function run(event) bot.NotAMethod() end`)
+	script, _ = b.loadScript(path)
+	assert.NotNil(t, b.runScript(context.Background(), script, nil))
+}
+
+func TestLuaToGo(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	v, err := luaToGo(lua.LString("M:1:2:3"), reflect.TypeOf(Coordinate{}))
+	assert.Nil(t, err)
+	assert.Equal(t, Coordinate{1, 2, 3, MoonType}, v.Interface())
+
+	v, err = luaToGo(lua.LNumber(123), reflect.TypeOf(CelestialID(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, CelestialID(123), v.Interface())
+
+	ships := L.NewTable()
+	ship := L.NewTable()
+	ship.RawSetString("ID", lua.LNumber(LargeCargoID))
+	ship.RawSetString("Nbr", lua.LNumber(10))
+	ships.Append(ship)
+	v, err = luaToGo(ships, reflect.TypeOf([]Quantifiable{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []Quantifiable{{ID: LargeCargoID, Nbr: 10}}, v.Interface())
+
+	v, err = luaToGo(L.NewTable(), reflect.TypeOf([]Quantifiable{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []Quantifiable{}, v.Interface())
+
+	_, err = luaToGo(lua.LString("abc"), reflect.TypeOf(int64(0)))
+	assert.NotNil(t, err)
+	_, err = luaToGo(lua.LNil, reflect.TypeOf(func() {}))
+	assert.NotNil(t, err)
+}
@@ -23,6 +23,11 @@ func (p sensorPhalanx) ScanConsumption() int64 {
 	return p.scanConsumption
 }
 
+// PhalanxRange returns the range (in systems) of a sensor phalanx, without the Discoverer bonus
+func PhalanxRange(level int64) int64 {
+	return SensorPhalanx.GetRange(level, false)
+}
+
 // GetRange gets sensor range
 func (p sensorPhalanx) GetRange(lvl int64, isDiscoverer bool) int64 {
 	var phalanxRange int64
@@ -0,0 +1,80 @@
+package ogame
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// ServerMovedError returned when a game request is redirected to another game server, eg: the universe was merged
+// into another one or renamed. From and To are the server hosts.
+type ServerMovedError struct {
+	From string
+	To   string
+}
+
+func (e *ServerMovedError) Error() string {
+	return "ogame server moved from " + e.From + " to " + e.To
+}
+
+var serverHostRgx = regexp.MustCompile(`^s(\d+)-([a-z]+)\.ogame\.gameforge\.com$`)
+
+// parseServerHost returns the number and language of a game server host, eg: s123-en.ogame.gameforge.com
+func parseServerHost(host string) (number int64, lang string, ok bool) {
+	m := serverHostRgx.FindStringSubmatch(host)
+	if len(m) != 3 {
+		return 0, "", false
+	}
+	number, _ = strconv.ParseInt(m[1], 10, 64)
+	return number, m[2], true
+}
+
+// movedServerHost returns the game server host a game request was redirected to, either followed or not.
+// Empty if the request was not redirected to another game server, eg: the expired sessions redirect to the lobby.
+func movedServerHost(req *http.Request, resp *http.Response) string {
+	if _, _, ok := parseServerHost(req.URL.Hostname()); !ok {
+		return ""
+	}
+	to := resp.Request.URL
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if location, err := resp.Location(); err == nil {
+			to = location
+		}
+	}
+	if to.Hostname() == req.URL.Hostname() {
+		return ""
+	}
+	if _, _, ok := parseServerHost(to.Hostname()); !ok {
+		return ""
+	}
+	return to.Hostname()
+}
+
+// findServerByHost finds the lobby server of a game server host
+func findServerByHost(servers []Server, host string) (Server, bool) {
+	number, lang, ok := parseServerHost(host)
+	if !ok {
+		return Server{}, false
+	}
+	if lang == "ba" {
+		lang = "yu"
+	}
+	for _, s := range servers {
+		if s.Number == number && s.Language == lang {
+			return s, true
+		}
+	}
+	return Server{}, false
+}
+
+// relocateServer logs in the game server the universe moved to, see MigrateUniverse
+func (b *OGame) relocateServer(moved *ServerMovedError) error {
+	b.warn(moved.Error())
+	migration, err := b.migrateUniverseTo(moved.To)
+	if err != nil {
+		return err
+	}
+	b.info("universe " + migration.OldServer.Name + " moved to " + migration.NewServer.Name + " (" + migration.NewServerURL + ")")
+	b.emitEvent(NewServerMovedEvent(migration))
+	return nil
+}
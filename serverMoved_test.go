@@ -0,0 +1,83 @@
+package ogame
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServerHost(t *testing.T) {
+	number, lang, ok := parseServerHost("s123-en.ogame.gameforge.com")
+	assert.True(t, ok)
+	assert.Equal(t, int64(123), number)
+	assert.Equal(t, "en", lang)
+	_, _, ok = parseServerHost("lobby.ogame.gameforge.com")
+	assert.False(t, ok)
+	_, _, ok = parseServerHost("127.0.0.1")
+	assert.False(t, ok)
+}
+
+func newTestRedirect(from, to string, statusCode int, location string) (*http.Request, *http.Response) {
+	req, _ := http.NewRequest("GET", from, nil)
+	last, _ := http.NewRequest("GET", to, nil)
+	resp := &http.Response{StatusCode: statusCode, Request: last, Header: http.Header{}}
+	if location != "" {
+		resp.Header.Set("Location", location)
+	}
+	return req, resp
+}
+
+func TestMovedServerHost(t *testing.T) {
+	// Followed redirect
+	req, resp := newTestRedirect("https://s1-en.ogame.gameforge.com/game/index.php", "https://s2-en.ogame.gameforge.com/game/index.php", 200, "")
+	assert.Equal(t, "s2-en.ogame.gameforge.com", movedServerHost(req, resp))
+
+	// Redirect not followed
+	req, resp = newTestRedirect("https://s1-en.ogame.gameforge.com/game/index.php", "https://s1-en.ogame.gameforge.com/game/index.php", 302, "https://s2-en.ogame.gameforge.com/game/index.php")
+	assert.Equal(t, "s2-en.ogame.gameforge.com", movedServerHost(req, resp))
+	req, resp = newTestRedirect("https://s1-en.ogame.gameforge.com/game/index.php", "https://s1-en.ogame.gameforge.com/game/index.php", 302, "/game/index.php?page=overview")
+	assert.Equal(t, "", movedServerHost(req, resp))
+
+	// Expired session
+	req, resp = newTestRedirect("https://s1-en.ogame.gameforge.com/game/index.php", "https://lobby.ogame.gameforge.com/en_GB/", 200, "")
+	assert.Equal(t, "", movedServerHost(req, resp))
+
+	// Not a game request
+	req, resp = newTestRedirect("http://127.0.0.1/game/index.php", "https://s2-en.ogame.gameforge.com/game/index.php", 200, "")
+	assert.Equal(t, "", movedServerHost(req, resp))
+}
+
+func TestFindServerByHost(t *testing.T) {
+	servers := []Server{{Number: 1, Language: "en", Name: "Andromeda"}, {Number: 2, Language: "yu", Name: "Barym"}}
+	server, ok := findServerByHost(servers, "s1-en.ogame.gameforge.com")
+	assert.True(t, ok)
+	assert.Equal(t, "Andromeda", server.Name)
+	server, ok = findServerByHost(servers, "s2-ba.ogame.gameforge.com")
+	assert.True(t, ok)
+	assert.Equal(t, "Barym", server.Name)
+	_, ok = findServerByHost(servers, "s3-en.ogame.gameforge.com")
+	assert.False(t, ok)
+}
+
+func TestServerMovedError(t *testing.T) {
+	err := &ServerMovedError{From: "s1-en.ogame.gameforge.com", To: "s2-en.ogame.gameforge.com"}
+	assert.Equal(t, "ogame server moved from s1-en.ogame.gameforge.com to s2-en.ogame.gameforge.com", err.Error())
+	assert.Equal(t, ErrCodeServerUnavailable, GetErrorCode(err))
+}
+
+func TestNewServerMovedEvent_JSON(t *testing.T) {
+	e := NewServerMovedEvent(UniverseMigration{
+		OldServer:    Server{Name: "Andromeda"},
+		NewServer:    Server{Name: "Barym"},
+		OldServerURL: "https://s1-en.ogame.gameforge.com",
+		NewServerURL: "https://s2-en.ogame.gameforge.com",
+		Unmapped:     []CelestialID{123},
+	})
+	assert.Equal(t, ServerMovedEvent, e.Type)
+	by, err := json.Marshal(e.Data)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"from_url":"https://s1-en.ogame.gameforge.com","to_url":"https://s2-en.ogame.gameforge.com",
+		"from_universe":"Andromeda","to_universe":"Barym","unmapped_celestials":1}`, string(by))
+}
@@ -0,0 +1,102 @@
+package ogame
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// Share key types
+const (
+	EspionageShareKey = "sr"
+	CombatShareKey    = "cr"
+	RecycleShareKey   = "rr"
+	MissileShareKey   = "mr"
+)
+
+// ReportAPIURLFormat url of the public API serving the report of a share key, formatted with the server number,
+// the server language, the key type and the key
+var ReportAPIURLFormat = "https://s%d-%s.ogame.gameforge.com/api/v1/%s?api_key=%s"
+
+var shareKeyRgx = regexp.MustCompile(`^(sr|cr|rr|mr)-([a-z]{2})-(\d+)-([0-9a-f]+)$`)
+
+// ShareKey key sharing a report with other players and tools (Ogotcha, TrashSim...), e.g. sr-en-101-0123abcd
+type ShareKey struct {
+	Type   string // sr (espionage), cr (combat), rr (recycle) or mr (missile)
+	Lang   string
+	Server int64
+	Hash   string
+}
+
+// ParseShareKey parses a report share key
+func ParseShareKey(key string) (ShareKey, error) {
+	m := shareKeyRgx.FindStringSubmatch(key)
+	if len(m) != 5 {
+		return ShareKey{}, errors.New("invalid share key " + key)
+	}
+	server, _ := strconv.ParseInt(m[3], 10, 64)
+	return ShareKey{Type: m[1], Lang: m[2], Server: server, Hash: m[4]}, nil
+}
+
+func (k ShareKey) String() string {
+	return k.Type + "-" + k.Lang + "-" + strconv.FormatInt(k.Server, 10) + "-" + k.Hash
+}
+
+// APIURL returns the url of the public API serving the report of the key, see ReportAPIURLFormat
+func (k ShareKey) APIURL() string {
+	return fmt.Sprintf(ReportAPIURLFormat, k.Server, k.Lang, k.Type, k.String())
+}
+
+// APIReport report fetched from the public API with its share key
+type APIReport struct {
+	Key  ShareKey
+	Data json.RawMessage // Report as returned by the API
+}
+
+func (b *OGame) getEspionageReportShareKey(msgID int64) (string, error) {
+	report, err := b.getEspionageReport(msgID)
+	if err != nil {
+		return "", err
+	}
+	if report.APIKey == "" {
+		return "", errors.New("no share key in espionage report " + strconv.FormatInt(msgID, 10))
+	}
+	return report.APIKey, nil
+}
+
+// ParseReportFromAPIKey fetches the report of a share key from the public API of its universe
+func (b *OGame) ParseReportFromAPIKey(key string) (APIReport, error) {
+	shareKey, err := ParseShareKey(key)
+	if err != nil {
+		return APIReport{}, err
+	}
+	req, err := http.NewRequest("GET", shareKey.APIURL(), nil)
+	if err != nil {
+		return APIReport{}, err
+	}
+	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
+	req = req.WithContext(b.ctx)
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return APIReport{}, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			b.error(err)
+		}
+	}()
+	by, err := wrapperReadBody(b, resp)
+	if err != nil {
+		return APIReport{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return APIReport{}, errors.New("report api: unexpected status " + resp.Status)
+	}
+	if !json.Valid(by) {
+		return APIReport{}, errors.New("report api: invalid response")
+	}
+	return APIReport{Key: shareKey, Data: json.RawMessage(by)}, nil
+}
@@ -0,0 +1,50 @@
+package ogame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShareKey(t *testing.T) {
+	key, err := ParseShareKey("sr-en-101-0123abcdef")
+	assert.NoError(t, err)
+	assert.Equal(t, ShareKey{Type: EspionageShareKey, Lang: "en", Server: 101, Hash: "0123abcdef"}, key)
+	assert.Equal(t, "sr-en-101-0123abcdef", key.String())
+	assert.Equal(t, "https://s101-en.ogame.gameforge.com/api/v1/sr?api_key=sr-en-101-0123abcdef", key.APIURL())
+
+	key, err = ParseShareKey("cr-fr-1-ff")
+	assert.NoError(t, err)
+	assert.Equal(t, CombatShareKey, key.Type)
+
+	_, err = ParseShareKey("xx-en-101-0123")
+	assert.Error(t, err)
+	_, err = ParseShareKey("sr-en-abc-0123")
+	assert.Error(t, err)
+}
+
+func TestParseReportFromAPIKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/101/en/sr" && r.URL.Query().Get("api_key") == "sr-en-101-abc" {
+			_, _ = w.Write([]byte(`{"generic":{"defender_name":"Bob"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	defer func(format string) { ReportAPIURLFormat = format }(ReportAPIURLFormat)
+	ReportAPIURLFormat = ts.URL + "/%d/%s/%s?api_key=%s"
+
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	report, err := b.ParseReportFromAPIKey("sr-en-101-abc")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), report.Key.Server)
+	assert.JSONEq(t, `{"generic":{"defender_name":"Bob"}}`, string(report.Data))
+
+	_, err = b.ParseReportFromAPIKey("sr-en-102-abc")
+	assert.Error(t, err)
+	_, err = b.ParseReportFromAPIKey("invalid")
+	assert.Error(t, err)
+}
@@ -107,10 +107,25 @@ func (s ShipsInfos) FromQuantifiables(in []Quantifiable) (out ShipsInfos) {
 	return
 }
 
-// Cargo returns the total cargo of the ships
-func (s ShipsInfos) Cargo(techs Researches, probeRaids, isCollector, isPioneers bool) (out int64) {
+// Cargo returns the total cargo of the ships with the default hyperspace technology bonus of the server kind,
+// see CargoCapacity to use the bonus of the server settings
+func (s ShipsInfos) Cargo(techs Researches, probeRaids, isCollector, isPioneers bool) int64 {
+	class := NoClass
+	if isCollector {
+		class = Collector
+	}
+	return CargoCapacity(s, techs, class, probeRaids, defaultCargoHyperspaceTechMultiplier(isPioneers))
+}
+
+// CargoCapacity returns the cargo capacity of the ships.
+// Collectors get a 25% bonus on cargo ships, probes only carry resources on probe raids servers and
+// hyperspaceMultiplier is the bonus in percent per hyperspace technology level (server cargoHyperspaceTechMultiplier setting).
+func CargoCapacity(ships ShipsInfos, researches Researches, class CharacterClass, probeRaids bool, hyperspaceMultiplier int64) (out int64) {
 	for _, ship := range Ships {
-		out += ship.GetCargoCapacity(techs, probeRaids, isCollector, isPioneers) * s.ByID(ship.GetID())
+		if nbr := ships.ByID(ship.GetID()); nbr > 0 {
+			baseCargoCapacity := ship.GetCargoCapacity(Researches{}, true, false, false)
+			out += cargoCapacity(ship.GetID(), baseCargoCapacity, researches, probeRaids, class.IsCollector(), hyperspaceMultiplier) * nbr
+		}
 	}
 	return
 }
@@ -161,3 +161,23 @@ func TestShipsInfos_ToPtr(t *testing.T) {
 	shipsPtr := ships.ToPtr()
 	assert.Equal(t, &ships, shipsPtr)
 }
+
+func TestCargoCapacity(t *testing.T) {
+	ships := ShipsInfos{SmallCargo: 10, LargeCargo: 2, EspionageProbe: 4}
+	assert.Equal(t, int64(10*5000+2*25000), CargoCapacity(ships, Researches{}, NoClass, false, 5))
+	assert.Equal(t, int64(10*5000+2*25000+4*5), CargoCapacity(ships, Researches{}, NoClass, true, 5))
+	assert.Equal(t, int64(10*6250+2*31250), CargoCapacity(ships, Researches{}, Collector, false, 5))
+	assert.Equal(t, int64(10*7500+2*37500+4*7), CargoCapacity(ships, Researches{HyperspaceTechnology: 10}, NoClass, true, 5))
+	assert.Equal(t, int64(10*6000+2*30000), CargoCapacity(ships, Researches{HyperspaceTechnology: 10}, NoClass, false, 2))
+	assert.Equal(t, ships.Cargo(Researches{HyperspaceTechnology: 8}, true, true, false),
+		CargoCapacity(ships, Researches{HyperspaceTechnology: 8}, Collector, true, DefaultCargoHyperspaceTechMultiplier))
+}
+
+func TestGetCargoHyperspaceTechMultiplier(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	assert.Equal(t, DefaultCargoHyperspaceTechMultiplier, b.GetCargoHyperspaceTechMultiplier())
+	b.serverData.CargoHyperspaceTechMultiplier = 3
+	b.researches = &Researches{HyperspaceTechnology: 10}
+	assert.Equal(t, int64(3), b.GetCargoHyperspaceTechMultiplier())
+	assert.Equal(t, int64(2*25000*130/100), b.fleetCargo(ShipsInfos{LargeCargo: 2}))
+}
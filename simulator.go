@@ -722,8 +722,15 @@ type combatSimulator struct {
 	IsLogging     bool
 	Logs          string
 	Debris        price
+	ReaperHarvest price // Part of the debris collected by the attacker reapers that survived
 }
 
+// Reapers collect a part of the debris field at the end of the combat, within their cargo capacity
+const (
+	reaperHarvestRatio = 0.3
+	reaperBaseCargo    = 10000
+)
+
 func (simulator *combatSimulator) hasExploded(entity *entity, defendingUnit *CombatUnit) bool {
 	exploded := false
 	unitPrice := getUnitPrice(getUnitID(defendingUnit))
@@ -954,8 +961,8 @@ func (simulator *combatSimulator) isCombatDone() bool {
 }
 
 func (simulator *combatSimulator) getMoonchance() int {
-	debris := float64(simulator.Debris.Metal) + float64(simulator.Debris.Crystal)
-	return int(math.Min(debris/100000.0, 20.0))
+	// The moon chance is computed on the whole debris field, before the reapers harvest
+	return int(MoonChance(int64(simulator.Debris.Metal+simulator.ReaperHarvest.Metal), int64(simulator.Debris.Crystal+simulator.ReaperHarvest.Crystal)))
 }
 
 func (simulator *combatSimulator) printWinner() {
@@ -1001,6 +1008,31 @@ func (simulator *combatSimulator) Simulate() {
 		}
 	}
 	simulator.printWinner()
+	simulator.harvestDebris()
+}
+
+// harvestDebris moves up to 30% of the debris into the cargo of the attacker surviving reapers
+func (simulator *combatSimulator) harvestDebris() {
+	simulator.ReaperHarvest = price{}
+	reapers := 0
+	for i := 0; i < simulator.Attacker.TotalUnits; i++ {
+		if getUnitID(&simulator.Attacker.Units[i]) == reaperConst {
+			reapers++
+		}
+	}
+	total := simulator.Debris.Metal + simulator.Debris.Crystal
+	if reapers == 0 || total == 0 {
+		return
+	}
+	cargo := float64(reapers*reaperBaseCargo) * (1 + 0.05*float64(simulator.Attacker.Hyperspace))
+	harvest := math.Min(float64(total)*reaperHarvestRatio, cargo)
+	simulator.ReaperHarvest.Metal = int(harvest * float64(simulator.Debris.Metal) / float64(total))
+	simulator.ReaperHarvest.Crystal = int(harvest * float64(simulator.Debris.Crystal) / float64(total))
+	simulator.Debris.Metal -= simulator.ReaperHarvest.Metal
+	simulator.Debris.Crystal -= simulator.ReaperHarvest.Crystal
+	if simulator.IsLogging {
+		simulator.Logs += fmt.Sprintf("%d reapers harvested %d metal and %d crystal\n", reapers, simulator.ReaperHarvest.Metal, simulator.ReaperHarvest.Crystal)
+	}
 }
 
 func newCombatSimulator(attacker *entity, defender *entity) *combatSimulator {
@@ -1149,6 +1181,7 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 	attackerLosses := price{}
 	defenderLosses := price{}
 	debris := price{}
+	harvest := price{}
 	rounds := 0
 	moonchance := 0
 
@@ -1156,6 +1189,7 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 	attacker.Weapon = attackerParam.Weapon
 	attacker.Shield = attackerParam.Shield
 	attacker.Armour = attackerParam.Armour
+	attacker.Hyperspace = attackerParam.Hyperspace
 	attacker.SmallCargo = int(attackerParam.SmallCargo)
 	attacker.LargeCargo = int(attackerParam.LargeCargo)
 	attacker.LightFighter = int(attackerParam.LightFighter)
@@ -1170,6 +1204,9 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 	attacker.Destroyer = int(attackerParam.Destroyer)
 	attacker.Deathstar = int(attackerParam.Deathstar)
 	attacker.Battlecruiser = int(attackerParam.Battlecruiser)
+	attacker.Reaper = int(attackerParam.Reaper)
+	attacker.Pathfinder = int(attackerParam.Pathfinder)
+	attacker.Crawler = 0
 	attacker.RocketLauncher = 0
 	attacker.LightLaser = 0
 	attacker.HeavyLaser = 0
@@ -1199,6 +1236,9 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 	defender.Destroyer = int(defenderParam.Destroyer)
 	defender.Deathstar = int(defenderParam.Deathstar)
 	defender.Battlecruiser = int(defenderParam.Battlecruiser)
+	defender.Reaper = int(defenderParam.Reaper)
+	defender.Pathfinder = int(defenderParam.Pathfinder)
+	defender.Crawler = int(defenderParam.Crawler)
 	defender.RocketLauncher = int(defenderParam.RocketLauncher)
 	defender.LightLaser = int(defenderParam.LightLaser)
 	defender.HeavyLaser = int(defenderParam.HeavyLaser)
@@ -1229,6 +1269,7 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 		attackerLosses.add(cs.Attacker.Losses)
 		defenderLosses.add(cs.Defender.Losses)
 		debris.add(cs.Debris)
+		harvest.add(cs.ReaperHarvest)
 		rounds += cs.Rounds
 		moonchance += cs.getMoonchance()
 	}
@@ -1250,6 +1291,9 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 	result.Debris = price{}
 	result.Debris.Metal = int(float64(debris.Metal) / float64(nbSimulations))
 	result.Debris.Crystal = int(float64(debris.Crystal) / float64(nbSimulations))
+	result.ReaperHarvest = price{}
+	result.ReaperHarvest.Metal = int(float64(harvest.Metal) / float64(nbSimulations))
+	result.ReaperHarvest.Crystal = int(float64(harvest.Crystal) / float64(nbSimulations))
 	result.Recycler = int(math.Ceil((float64(debris.Metal+debris.Crystal) / float64(nbSimulations)) / 20000.0))
 	result.Moonchance = int(float64(moonchance) / float64(nbSimulations))
 
@@ -1260,9 +1304,10 @@ func Simulate(attackerParam Attacker, defenderParam Defender, params SimulatorPa
 
 // Attacker ...
 type Attacker struct {
-	Weapon int
-	Shield int
-	Armour int
+	Weapon     int
+	Shield     int
+	Armour     int
+	Hyperspace int // Hyperspace technology, increases the reapers cargo
 	ShipsInfos
 }
 
@@ -1293,7 +1338,8 @@ type SimulatorResult struct {
 	Rounds         int
 	AttackerLosses price
 	DefenderLosses price
-	Debris         price
+	Debris         price // Debris left after the reapers harvest
+	ReaperHarvest  price
 	Recycler       int
 	Moonchance     int
 	Logs           string
@@ -1310,6 +1356,7 @@ func (s SimulatorResult) String() string {
 		"AttackerLosses: " + s.AttackerLosses.String() + "\n" +
 		"DefenderLosses: " + s.DefenderLosses.String() + "\n" +
 		"        Debris: " + s.Debris.String() + "\n" +
+		" ReaperHarvest: " + s.ReaperHarvest.String() + "\n" +
 		"      Recycler: " + strconv.Itoa(s.Recycler) + "\n" +
 		"    Moonchance: " + strconv.Itoa(s.Moonchance) + "\n"
 }
@@ -0,0 +1,127 @@
+package ogame
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Storage alerts defaults
+const (
+	DefaultStorageAlertThreshold = 0.9 // Fill ratio at which storage.threshold_reached events are emitted
+	DefaultStorageAlertInterval  = 10 * time.Minute
+)
+
+// ResourceStorageETA storage of a resource of a celestial and when it will be full
+type ResourceStorageETA struct {
+	Available         int64
+	StorageCapacity   int64
+	ProductionPerHour int64
+	FillRatio         float64   // Available / StorageCapacity
+	FullAt            time.Time // Zero when the storage never gets full (no production)
+	Full              bool
+}
+
+// StorageETA storages of a planet
+type StorageETA struct {
+	CelestialID CelestialID
+	Coordinate  Coordinate
+	Metal       ResourceStorageETA
+	Crystal     ResourceStorageETA
+	Deuterium   ResourceStorageETA
+}
+
+func newResourceStorageETA(available, storageCapacity, productionPerHour int64, now time.Time) ResourceStorageETA {
+	res := ResourceStorageETA{Available: available, StorageCapacity: storageCapacity, ProductionPerHour: productionPerHour}
+	if storageCapacity <= 0 {
+		return res
+	}
+	res.FillRatio = float64(available) / float64(storageCapacity)
+	if available >= storageCapacity {
+		res.Full = true
+		res.FullAt = now
+	} else if productionPerHour > 0 {
+		hours := float64(storageCapacity-available) / float64(productionPerHour)
+		res.FullAt = now.Add(time.Duration(math.Ceil(hours*3600)) * time.Second)
+	}
+	return res
+}
+
+func newStorageETA(celestialID CelestialID, coord Coordinate, details ResourcesDetails, now time.Time) StorageETA {
+	return StorageETA{
+		CelestialID: celestialID,
+		Coordinate:  coord,
+		Metal:       newResourceStorageETA(details.Metal.Available, details.Metal.StorageCapacity, details.Metal.CurrentProduction, now),
+		Crystal:     newResourceStorageETA(details.Crystal.Available, details.Crystal.StorageCapacity, details.Crystal.CurrentProduction, now),
+		Deuterium:   newResourceStorageETA(details.Deuterium.Available, details.Deuterium.StorageCapacity, details.Deuterium.CurrentProduction, now),
+	}
+}
+
+// getStorageETA returns the storages of every planet, moons do not produce resources
+func (b *OGame) getStorageETA() ([]StorageETA, error) {
+	res := make([]StorageETA, 0)
+	for _, planet := range b.GetCachedPlanets() {
+		details, err := b.getResourcesDetails(planet.GetID())
+		if err != nil {
+			return res, err
+		}
+		res = append(res, newStorageETA(planet.GetID(), planet.GetCoordinate(), details, time.Now()))
+	}
+	return res, nil
+}
+
+// storageAlerts returns the storage.threshold_reached events of the storages that reached the threshold.
+// alerted holds the storages already reported, a storage is reported again once it went back under the threshold.
+func storageAlerts(etas []StorageETA, threshold float64, alerted map[string]bool) []Event {
+	events := make([]Event, 0)
+	for _, eta := range etas {
+		for _, r := range []struct {
+			name string
+			eta  ResourceStorageETA
+		}{{"metal", eta.Metal}, {"crystal", eta.Crystal}, {"deuterium", eta.Deuterium}} {
+			key := fmt.Sprintf("%d|%s", eta.CelestialID, r.name)
+			if r.eta.StorageCapacity <= 0 || r.eta.FillRatio < threshold {
+				delete(alerted, key)
+				continue
+			}
+			if alerted[key] {
+				continue
+			}
+			alerted[key] = true
+			events = append(events, NewStorageEvent(eta.CelestialID, eta.Coordinate, r.name, r.eta, threshold))
+		}
+	}
+	return events
+}
+
+// StartStorageAlerts checks the planets storages every interval until the returned function is called,
+// a storage.threshold_reached event is emitted when a storage fill ratio reaches threshold (eg: 0.9).
+func (b *OGame) StartStorageAlerts(threshold float64, interval time.Duration) (stop func()) {
+	if threshold <= 0 {
+		threshold = DefaultStorageAlertThreshold
+	}
+	if interval <= 0 {
+		interval = DefaultStorageAlertInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		alerted := make(map[string]bool)
+		for {
+			if b.isEnabled() && b.IsLoggedIn() {
+				if etas, err := b.WithPriority(Low).GetStorageETA(); err == nil {
+					for _, e := range storageAlerts(etas, threshold, alerted) {
+						b.emitEvent(e)
+					}
+				}
+			}
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,58 @@
+package ogame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResourceStorageETA(t *testing.T) {
+	now := time.Date(2021, 5, 20, 8, 0, 0, 0, time.UTC)
+
+	eta := newResourceStorageETA(5000, 10000, 1000, now)
+	assert.Equal(t, 0.5, eta.FillRatio)
+	assert.False(t, eta.Full)
+	assert.Equal(t, now.Add(5*time.Hour), eta.FullAt)
+
+	eta = newResourceStorageETA(10500, 10000, 1000, now)
+	assert.True(t, eta.Full)
+	assert.Equal(t, now, eta.FullAt)
+
+	eta = newResourceStorageETA(5000, 10000, 0, now)
+	assert.True(t, eta.FullAt.IsZero())
+
+	eta = newResourceStorageETA(5000, 0, 1000, now)
+	assert.Equal(t, 0.0, eta.FillRatio)
+	assert.True(t, eta.FullAt.IsZero())
+}
+
+func TestStorageAlerts(t *testing.T) {
+	now := time.Now()
+	etas := []StorageETA{{
+		CelestialID: 1,
+		Coordinate:  Coordinate{1, 2, 3, PlanetType},
+		Metal:       newResourceStorageETA(9500, 10000, 1000, now),
+		Crystal:     newResourceStorageETA(5000, 10000, 1000, now),
+		Deuterium:   newResourceStorageETA(10000, 10000, 0, now),
+	}}
+	alerted := make(map[string]bool)
+	events := storageAlerts(etas, 0.9, alerted)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, StorageThresholdReachedEvent, events[0].Type)
+	assert.Equal(t, "metal", events[0].Data.(EventStorageData).Resource)
+	assert.NotNil(t, events[0].Data.(EventStorageData).FullAt)
+	assert.Equal(t, "deuterium", events[1].Data.(EventStorageData).Resource)
+	assert.NotNil(t, events[1].Data.(EventStorageData).FullAt)
+
+	// Already reported
+	assert.Equal(t, 0, len(storageAlerts(etas, 0.9, alerted)))
+
+	// Reported again after going back under the threshold
+	etas[0].Metal = newResourceStorageETA(1000, 10000, 1000, now)
+	assert.Equal(t, 0, len(storageAlerts(etas, 0.9, alerted)))
+	etas[0].Metal = newResourceStorageETA(9500, 10000, 1000, now)
+	events = storageAlerts(etas, 0.9, alerted)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "metal", events[0].Data.(EventStorageData).Resource)
+}
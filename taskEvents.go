@@ -0,0 +1,80 @@
+package ogame
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TaskEvent lifecycle event of a task (TaskEnqueuedEvent, TaskStartedEvent, TaskFinishedEvent, TaskFailedEvent),
+// the events of a task share its ID
+type TaskEvent struct {
+	Type      EventType
+	Time      time.Time
+	ID        int64
+	Priority  int
+	Name      string        `json:",omitempty"` // Unknown while enqueued
+	Initiator string        `json:",omitempty"`
+	Wait      time.Duration `json:",omitempty"` // Time spent waiting for the bot lock, once started
+	Duration  time.Duration `json:",omitempty"` // Time the bot lock was held, once finished
+	Errors    []string      `json:",omitempty"`
+	Queued    int64         // Tasks waiting for the bot lock
+}
+
+type taskSubscription struct {
+	id  int64
+	clb func(TaskEvent)
+}
+
+// SubscribeTasks registers a callback called with the lifecycle events of every task, in order, until unsubscribe is
+// called. The callback is called synchronously and must not block. The task events are not sent to Subscribe, there
+// are several of them per request.
+func (b *OGame) SubscribeTasks(clb func(TaskEvent)) (unsubscribe func()) {
+	b.taskSubscriptionsMu.Lock()
+	defer b.taskSubscriptionsMu.Unlock()
+	b.taskSubscriptionID++
+	id := b.taskSubscriptionID
+	b.taskSubscriptions = append(b.taskSubscriptions, taskSubscription{id: id, clb: clb})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.taskSubscriptionsMu.Lock()
+			defer b.taskSubscriptionsMu.Unlock()
+			subscriptions := make([]taskSubscription, 0, len(b.taskSubscriptions))
+			for _, s := range b.taskSubscriptions {
+				if s.id != id {
+					subscriptions = append(subscriptions, s)
+				}
+			}
+			b.taskSubscriptions = subscriptions
+		})
+	}
+}
+
+func (b *OGame) hasTaskSubscriptions() bool {
+	b.taskSubscriptionsMu.RLock()
+	defer b.taskSubscriptionsMu.RUnlock()
+	return len(b.taskSubscriptions) > 0
+}
+
+func (b *OGame) emitTaskEvent(e TaskEvent) {
+	b.taskSubscriptionsMu.RLock()
+	subscriptions := b.taskSubscriptions
+	b.taskSubscriptionsMu.RUnlock()
+	if len(subscriptions) == 0 {
+		return
+	}
+	e.Queued = b.getTasks().Total
+	for _, s := range subscriptions {
+		s.clb(e)
+	}
+}
+
+// taskEnqueued returns the id of a task waiting for the bot lock
+func (b *OGame) taskEnqueued(priority int, queuedAt time.Time) int64 {
+	id := atomic.AddInt64(&b.taskSeq, 1)
+	if b.hasTaskSubscriptions() {
+		b.emitTaskEvent(TaskEvent{Type: TaskEnqueuedEvent, Time: queuedAt, ID: id, Priority: priority})
+	}
+	return id
+}
@@ -0,0 +1,62 @@
+package ogame
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeTasks(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	var mu sync.Mutex
+	events := make([]TaskEvent, 0)
+	unsubscribe := b.SubscribeTasks(func(e TaskEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+	_ = b.WithPriority(Important).SetInitiator("farmer").Tx(func(tx Prioritizable) error { return nil })
+	_ = b.WithPriority(Normal).Tx(func(tx Prioritizable) error { return errors.New("failed") })
+	unsubscribe()
+	_ = b.WithPriority(Normal).Tx(func(tx Prioritizable) error { return nil })
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 6, len(events))
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	assert.Equal(t, []EventType{TaskEnqueuedEvent, TaskStartedEvent, TaskFinishedEvent,
+		TaskEnqueuedEvent, TaskStartedEvent, TaskFailedEvent}, types)
+	assert.Equal(t, events[0].ID, events[2].ID)
+	assert.NotEqual(t, events[0].ID, events[3].ID)
+	assert.Equal(t, Important, events[0].Priority)
+	assert.Equal(t, "", events[0].Name)
+	assert.Equal(t, "Tx", events[1].Name)
+	assert.Equal(t, "farmer", events[2].Initiator)
+	assert.Equal(t, []string{"failed"}, events[5].Errors)
+}
+
+func TestNewTaskEvent_JSON(t *testing.T) {
+	e := NewTaskEvent(TaskEvent{
+		Type:      TaskFinishedEvent,
+		Time:      time.Date(2021, 5, 20, 8, 42, 7, 0, time.UTC),
+		ID:        12,
+		Priority:  Normal,
+		Name:      "GetFleets",
+		Initiator: "farmer",
+		Wait:      1500 * time.Millisecond,
+		Duration:  250 * time.Millisecond,
+		Queued:    3,
+	})
+	by, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"schema_version":1,"type":"task.finished","time":"2021-05-20T08:42:07Z","data":{
+		"id":12,"priority":2,"name":"GetFleets","initiator":"farmer","wait_ms":1500,"duration_ms":250,
+		"errors":[],"queued":3}}`, string(by))
+}
@@ -0,0 +1,279 @@
+package ogame
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Task history retention defaults
+const (
+	DefaultTaskHistoryMaxAge     = 7 * 24 * time.Hour
+	DefaultTaskHistoryMaxRecords = 10000
+	maxTaskRecordErrors          = 10 // Errors kept per task
+)
+
+// TaskRecord task that held the bot lock
+type TaskRecord struct {
+	Name      string // Method or transaction name, eg: "GetFleets"
+	Initiator string `json:",omitempty"`
+	Priority  int
+	QueuedAt  time.Time
+	StartedAt time.Time     // Time the bot lock was acquired
+	Wait      time.Duration // Time spent waiting for the bot lock
+	Duration  time.Duration // Time the bot lock was held
+	Errors    []string      `json:",omitempty"` // Requests of the task that failed, and the error of a transaction
+}
+
+// TaskHistory completed tasks kept for maxAge, at most maxRecords of them.
+// When a file is used, each record is appended as a JSON line and the retained records are loaded on creation.
+type TaskHistory struct {
+	sync.RWMutex
+	records    []TaskRecord
+	maxAge     time.Duration
+	maxRecords int
+	filename   string
+	file       *os.File
+	appended   int // Records appended to the file since it was compacted
+}
+
+func newTaskHistory(maxAge time.Duration, maxRecords int) *TaskHistory {
+	if maxAge <= 0 {
+		maxAge = DefaultTaskHistoryMaxAge
+	}
+	if maxRecords <= 0 {
+		maxRecords = DefaultTaskHistoryMaxRecords
+	}
+	return &TaskHistory{records: make([]TaskRecord, 0), maxAge: maxAge, maxRecords: maxRecords}
+}
+
+// NewTaskHistory creates a task history backed by filename, an empty filename keeps the history in memory only.
+// maxAge and maxRecords are the retention, DefaultTaskHistoryMaxAge and DefaultTaskHistoryMaxRecords if 0.
+func NewTaskHistory(filename string, maxAge time.Duration, maxRecords int) (*TaskHistory, error) {
+	h := newTaskHistory(maxAge, maxRecords)
+	if filename == "" {
+		return h, nil
+	}
+	h.filename = filename
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record TaskRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			h.append(record)
+		}
+	}
+	_ = f.Close()
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := h.compact(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// append adds a record and drops the ones out of the retention. The records are appended once the bot lock is
+// released, a record can arrive after the one of the next task and is inserted in start order.
+func (h *TaskHistory) append(record TaskRecord) {
+	i := len(h.records)
+	for i > 0 && h.records[i-1].StartedAt.After(record.StartedAt) {
+		i--
+	}
+	h.records = append(h.records, TaskRecord{})
+	copy(h.records[i+1:], h.records[i:])
+	h.records[i] = record
+	if len(h.records) > h.maxRecords {
+		h.records = h.records[len(h.records)-h.maxRecords:]
+	}
+	limit := time.Now().Add(-h.maxAge)
+	i = 0
+	for i < len(h.records) && h.records[i].StartedAt.Before(limit) {
+		i++
+	}
+	h.records = h.records[i:]
+}
+
+// compact rewrites the file with the retained records only
+func (h *TaskHistory) compact() error {
+	if h.file != nil {
+		_ = h.file.Close()
+		h.file = nil
+	}
+	tmp := h.filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, record := range h.records {
+		by, err := json.Marshal(record)
+		if err != nil {
+			continue
+		}
+		_, _ = w.Write(append(by, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, h.filename); err != nil {
+		return err
+	}
+	h.file, err = os.OpenFile(h.filename, os.O_WRONLY|os.O_APPEND, 0600)
+	h.appended = 0
+	return err
+}
+
+// Append adds a record to the history, the file is compacted every maxRecords records
+func (h *TaskHistory) Append(record TaskRecord) error {
+	h.Lock()
+	defer h.Unlock()
+	h.append(record)
+	if h.file == nil {
+		return nil
+	}
+	h.appended++
+	if h.appended >= h.maxRecords {
+		return h.compact()
+	}
+	by, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = h.file.Write(append(by, '\n'))
+	return err
+}
+
+// Records returns the records of the tasks started after since
+func (h *TaskHistory) Records(since time.Time) []TaskRecord {
+	h.RLock()
+	defer h.RUnlock()
+	res := make([]TaskRecord, 0)
+	for _, r := range h.records {
+		if !r.StartedAt.Before(since) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Close closes the file backing the history
+func (h *TaskHistory) Close() error {
+	h.Lock()
+	defer h.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
+
+// taskStarted starts the record of the task that acquired the bot lock
+func (b *OGame) taskStarted(id int64, priority int, initiator, name string, queuedAt time.Time) {
+	now := time.Now()
+	record := &TaskRecord{Name: name, Initiator: initiator, Priority: priority, QueuedAt: queuedAt, StartedAt: now}
+	if !queuedAt.IsZero() {
+		record.Wait = now.Sub(queuedAt)
+	}
+	b.taskHistoryMu.Lock()
+	b.currentTask = record
+	b.currentTaskID = id
+	b.currentTaskErr = nil
+	b.taskHistoryMu.Unlock()
+	b.emitTaskEvent(TaskEvent{Type: TaskStartedEvent, Time: now, ID: id, Priority: priority, Name: name, Initiator: initiator, Wait: record.Wait})
+}
+
+// taskFinished completes the record of the task releasing the bot lock, it is added to the history by
+// appendTaskRecord once the lock is released
+func (b *OGame) taskFinished() *TaskRecord {
+	b.taskHistoryMu.Lock()
+	record, id, lastErr := b.currentTask, b.currentTaskID, b.currentTaskErr
+	b.currentTask = nil
+	b.currentTaskErr = nil
+	b.taskHistoryMu.Unlock()
+	if record == nil {
+		return nil
+	}
+	b.trackTaskResult(lastErr)
+	record.Duration = time.Since(record.StartedAt)
+	typ := TaskFinishedEvent
+	if len(record.Errors) > 0 {
+		typ = TaskFailedEvent
+	}
+	b.emitTaskEvent(TaskEvent{Type: typ, Time: time.Now(), ID: id, Priority: record.Priority, Name: record.Name,
+		Initiator: record.Initiator, Wait: record.Wait, Duration: record.Duration, Errors: record.Errors})
+	return record
+}
+
+// appendTaskRecord adds the record of a finished task to the history. It is called once the bot lock is released, so
+// the file writes and compactions of the history are not part of the lock hold times.
+func (b *OGame) appendTaskRecord(record *TaskRecord) {
+	if record == nil {
+		return
+	}
+	b.taskHistoryMu.Lock()
+	h := b.taskHistory
+	b.taskHistoryMu.Unlock()
+	if h == nil {
+		return
+	}
+	if err := h.Append(*record); err != nil {
+		b.error("failed to write task history: " + err.Error())
+	}
+}
+
+// lockedTaskID returns the id of the task holding the bot lock, 0 if none
+func (b *OGame) lockedTaskID() int64 {
+	b.taskHistoryMu.Lock()
+	defer b.taskHistoryMu.Unlock()
+	if b.currentTask == nil {
+		return 0
+	}
+	return b.currentTaskID
+}
+
+// recordTaskError adds an error to the record of the task taskID if it still holds the bot lock
+func (b *OGame) recordTaskError(taskID int64, err error) {
+	b.taskHistoryMu.Lock()
+	defer b.taskHistoryMu.Unlock()
+	if taskID == 0 || b.currentTask == nil || b.currentTaskID != taskID {
+		return
+	}
+	b.currentTaskErr = err
+	if len(b.currentTask.Errors) >= maxTaskRecordErrors {
+		return
+	}
+	if n := len(b.currentTask.Errors); n > 0 && b.currentTask.Errors[n-1] == err.Error() {
+		return
+	}
+	b.currentTask.Errors = append(b.currentTask.Errors, err.Error())
+}
+
+// SetTaskHistory replaces the task history, nil disables it
+func (b *OGame) SetTaskHistory(h *TaskHistory) {
+	b.taskHistoryMu.Lock()
+	defer b.taskHistoryMu.Unlock()
+	b.taskHistory = h
+}
+
+// GetTaskHistory returns the tasks that held the bot lock and started after since, oldest first
+func (b *OGame) GetTaskHistory(since time.Time) []TaskRecord {
+	b.taskHistoryMu.Lock()
+	h := b.taskHistory
+	b.taskHistoryMu.Unlock()
+	if h == nil {
+		return []TaskRecord{}
+	}
+	return h.Records(since)
+}
@@ -0,0 +1,89 @@
+package ogame
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskHistory_Retention(t *testing.T) {
+	now := time.Now()
+	h := newTaskHistory(time.Hour, 3)
+	_ = h.Append(TaskRecord{Name: "old", StartedAt: now.Add(-2 * time.Hour)})
+	_ = h.Append(TaskRecord{Name: "a", StartedAt: now.Add(-30 * time.Minute)})
+	assert.Equal(t, 1, len(h.Records(time.Time{})))
+	_ = h.Append(TaskRecord{Name: "b", StartedAt: now.Add(-20 * time.Minute)})
+	_ = h.Append(TaskRecord{Name: "c", StartedAt: now.Add(-10 * time.Minute)})
+	_ = h.Append(TaskRecord{Name: "d", StartedAt: now})
+	records := h.Records(time.Time{})
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "b", records[0].Name)
+	records = h.Records(now.Add(-15 * time.Minute))
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "c", records[0].Name)
+
+	_ = h.Append(TaskRecord{Name: "late", StartedAt: now.Add(-5 * time.Minute)})
+	records = h.Records(time.Time{})
+	assert.Equal(t, []string{"c", "late", "d"}, []string{records[0].Name, records[1].Name, records[2].Name})
+}
+
+func TestTaskHistory_File(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tasks")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "tasks.log")
+	h, err := NewTaskHistory(filename, 0, 2)
+	assert.NoError(t, err)
+	_ = h.Append(TaskRecord{Name: "GetFleets", Initiator: "farmer", StartedAt: time.Now(), Duration: time.Second, Errors: []string{"timeout"}})
+	assert.NoError(t, h.Close())
+
+	h, err = NewTaskHistory(filename, 0, 2)
+	assert.NoError(t, err)
+	records := h.Records(time.Time{})
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "farmer", records[0].Initiator)
+	assert.Equal(t, time.Second, records[0].Duration)
+	assert.Equal(t, []string{"timeout"}, records[0].Errors)
+	_ = h.Append(TaskRecord{Name: "GetShips", StartedAt: time.Now()})
+	_ = h.Append(TaskRecord{Name: "SendFleet", StartedAt: time.Now()}) // Compacts the file
+	assert.NoError(t, h.Close())
+
+	by, _ := ioutil.ReadFile(filename)
+	assert.Equal(t, 2, strings.Count(string(by), "\n"))
+	h, err = NewTaskHistory(filename, 0, 2)
+	assert.NoError(t, err)
+	defer h.Close()
+	records = h.Records(time.Time{})
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "GetShips", records[0].Name)
+}
+
+func TestPrioritize_TaskHistory(t *testing.T) {
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	start := time.Now()
+	err := b.WithPriority(Normal).SetInitiator("farmer").Tx(func(tx Prioritizable) error {
+		taskID := b.lockedTaskID()
+		assert.NotEqual(t, int64(0), taskID)
+		b.recordTaskError(taskID, errors.New("request failed"))
+		b.recordTaskError(taskID, errors.New("request failed"))
+		b.recordTaskError(taskID+1, errors.New("request of another task"))
+		b.recordTaskError(0, errors.New("request outside the lock"))
+		return errors.New("tx failed")
+	})
+	assert.Error(t, err)
+	records := b.GetTaskHistory(start)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "Tx", records[0].Name)
+	assert.Equal(t, "farmer", records[0].Initiator)
+	assert.False(t, records[0].QueuedAt.After(records[0].StartedAt))
+	assert.Equal(t, []string{"request failed", "tx failed"}, records[0].Errors)
+
+	assert.Equal(t, int64(0), b.lockedTaskID())
+	b.recordTaskError(0, errors.New("no task"))
+	assert.Equal(t, 0, len(b.GetTaskHistory(time.Now().Add(time.Minute))))
+}
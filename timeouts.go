@@ -0,0 +1,72 @@
+package ogame
+
+import (
+	"context"
+	"time"
+)
+
+// taskContext deadline of the task holding the bot lock
+type taskContext struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// SetRequestTimeout sets the time an HTTP request to the OGame server can take, within the http client timeout.
+// 0 only uses the http client timeout.
+func (b *OGame) SetRequestTimeout(timeout time.Duration) {
+	b.timeoutsMu.Lock()
+	defer b.timeoutsMu.Unlock()
+	b.requestTimeout = timeout
+}
+
+// SetTaskDeadline sets the time a task can hold the bot lock and keep sending requests, no deadline if 0.
+// Once the deadline is exceeded, the requests of the task fail with ErrTaskDeadlineExceeded so it releases the lock.
+func (b *OGame) SetTaskDeadline(deadline time.Duration) {
+	b.timeoutsMu.Lock()
+	defer b.timeoutsMu.Unlock()
+	b.taskDeadline = deadline
+}
+
+// startTask starts the deadline of the task that acquired the bot lock
+func (b *OGame) startTask() {
+	b.timeoutsMu.Lock()
+	defer b.timeoutsMu.Unlock()
+	if b.taskDeadline <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, b.taskDeadline)
+	b.task = &taskContext{ctx: ctx, cancel: cancel}
+}
+
+// endTask stops the deadline of the task releasing the bot lock
+func (b *OGame) endTask() {
+	b.timeoutsMu.Lock()
+	defer b.timeoutsMu.Unlock()
+	if b.task != nil {
+		b.task.cancel()
+		b.task = nil
+	}
+}
+
+// requestContext returns the context of a request to the OGame server, bounded by the task deadline and timeout
+// (the request timeout if 0)
+func (b *OGame) requestContext(timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	b.timeoutsMu.Lock()
+	ctx := b.ctx
+	if b.task != nil {
+		ctx = b.task.ctx
+	}
+	if timeout <= 0 {
+		timeout = b.requestTimeout
+	}
+	b.timeoutsMu.Unlock()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, nil, ErrTaskDeadlineExceeded
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
@@ -0,0 +1,51 @@
+package ogame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecRawRequest_RequestTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.SetRequestTimeout(20 * time.Millisecond)
+	start := time.Now()
+	_, _, err := b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.Error(t, err)
+	assert.True(t, isServerFailure(err))
+	assert.True(t, time.Since(start) < 150*time.Millisecond)
+
+	// The per call timeout replaces the bot one
+	b.SetRequestTimeout(0)
+	start = time.Now()
+	_, _, err = b.execRawRequestWithTimeout("GET", ts.URL, "", nil, nil, 20*time.Millisecond)
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 150*time.Millisecond)
+}
+
+func TestTaskDeadline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	b, _ := NewNoLogin("", "", "", "", "", "", "", 0, nil)
+	b.SetTaskDeadline(20 * time.Millisecond)
+	b.botLock("test")
+	_, _, err := b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	_, _, err = b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.Equal(t, ErrTaskDeadlineExceeded, err)
+	b.botUnlock("test")
+
+	// The deadline starts again with the next task
+	b.botLock("test")
+	_, _, err = b.execRawRequest("GET", ts.URL, "", nil, nil)
+	assert.NoError(t, err)
+	b.botUnlock("test")
+}
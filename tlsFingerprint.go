@@ -0,0 +1,139 @@
+package ogame
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// TLS ClientHello fingerprints that can be mimicked
+const (
+	TLSFingerprintGolang     = "golang"
+	TLSFingerprintChrome     = "chrome"
+	TLSFingerprintFirefox    = "firefox"
+	TLSFingerprintIOS        = "ios"
+	TLSFingerprintRandomized = "randomized"
+)
+
+func getClientHelloID(fingerprint string) (utls.ClientHelloID, error) {
+	switch strings.ToLower(fingerprint) {
+	case TLSFingerprintGolang:
+		return utls.HelloGolang, nil
+	case TLSFingerprintChrome:
+		return utls.HelloChrome_Auto, nil
+	case TLSFingerprintFirefox:
+		return utls.HelloFirefox_Auto, nil
+	case TLSFingerprintIOS:
+		return utls.HelloIOS_Auto, nil
+	case TLSFingerprintRandomized:
+		return utls.HelloRandomizedNoALPN, nil
+	}
+	return utls.ClientHelloID{}, errors.New("unknown tls fingerprint " + fingerprint)
+}
+
+// WithTLSFingerprint returns a copy of transport that uses the ClientHello of a real browser (chrome, firefox, ios)
+// for direct and socks5 connections. Connections through an http proxy keep the golang TLS handshake.
+func WithTLSFingerprint(transport *http.Transport, fingerprint string) (*http.Transport, error) {
+	helloID, err := getClientHelloID(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	t := transport.Clone()
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	t.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		config := &utls.Config{ServerName: host}
+		if t.TLSClientConfig != nil {
+			config.InsecureSkipVerify = t.TLSClientConfig.InsecureSkipVerify
+			config.RootCAs = t.TLSClientConfig.RootCAs
+		}
+		uconn := utls.UClient(conn, config, helloID)
+		if err := uconn.BuildHandshakeState(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		// The transport speaks http/1.1 on custom tls connections, never negotiate h2
+		for _, ext := range uconn.Extensions {
+			if alpn, ok := ext.(*utls.ALPNExtension); ok {
+				alpn.AlpnProtocols = []string{"http/1.1"}
+			}
+		}
+		if err := uconn.BuildHandshakeState(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+			defer func() { _ = conn.SetDeadline(time.Time{}) }()
+		}
+		if err := uconn.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return uconn, nil
+	}
+	return t, nil
+}
+
+// SetTLSFingerprint makes the bot TLS ClientHello match a real browser (chrome, firefox, ios, randomized, golang)
+func (b *OGame) SetTLSFingerprint(fingerprint string) error {
+	apply := func(rt http.RoundTripper) (http.RoundTripper, error) {
+		if rt == nil {
+			return WithTLSFingerprint(nil, fingerprint)
+		}
+		if t, ok := rt.(*http.Transport); ok {
+			return WithTLSFingerprint(t, fingerprint)
+		}
+		if pool, ok := rt.(*ProxyPool); ok {
+			return pool, pool.setTLSFingerprint(fingerprint)
+		}
+		return nil, errors.New("tls fingerprint is not supported by this transport")
+	}
+	clientTransport, err := apply(b.Client.Transport)
+	if err != nil {
+		return err
+	}
+	if b.loginProxyTransport != nil {
+		if b.loginProxyTransport, err = apply(b.loginProxyTransport); err != nil {
+			return err
+		}
+	}
+	b.Client.Transport = clientTransport
+	b.tlsFingerprint = fingerprint
+	return nil
+}
+
+func (p *ProxyPool) setTLSFingerprint(fingerprint string) error {
+	p.Lock()
+	defer p.Unlock()
+	for _, proxy := range p.proxies {
+		t, ok := proxy.transport.(*http.Transport)
+		if !ok {
+			continue
+		}
+		newTransport, err := WithTLSFingerprint(t, fingerprint)
+		if err != nil {
+			return err
+		}
+		proxy.transport = newTransport
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package ogame
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTLSFingerprint(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	defer srv.Close()
+	for _, fingerprint := range []string{TLSFingerprintChrome, TLSFingerprintFirefox, TLSFingerprintGolang} {
+		transport, err := WithTLSFingerprint(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, fingerprint)
+		assert.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+		if assert.NoError(t, err, fingerprint) {
+			by, _ := ioutil.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			assert.Equal(t, "HTTP/1.1", string(by))
+		}
+	}
+	_, err := WithTLSFingerprint(nil, "netscape")
+	assert.Error(t, err)
+}
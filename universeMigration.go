@@ -0,0 +1,164 @@
+package ogame
+
+import (
+	"errors"
+)
+
+// UniverseMigration result of a migration after a server merge
+type UniverseMigration struct {
+	OldServer    Server
+	NewServer    Server
+	OldServerURL string
+	NewServerURL string
+	// CelestialIDs old celestial id -> new celestial id, matched by coordinates
+	CelestialIDs map[CelestialID]CelestialID
+	// Unmapped old celestials for which no celestial exists anymore at the same coordinates
+	Unmapped []CelestialID
+}
+
+// RemapID returns the new id of an old celestial id, ids that are not part of the migration are returned unchanged
+func (m UniverseMigration) RemapID(id CelestialID) CelestialID {
+	if newID, ok := m.CelestialIDs[id]; ok {
+		return newID
+	}
+	return id
+}
+
+// findMergedAccount finds the account of the player after a server merge.
+// The configured universe is tried first, then the account with the same player name in the same language.
+func findMergedAccount(universe, lang, playerName string, playerID int64, accounts []account, servers []Server) (account, Server, error) {
+	if acc, server, err := findAccount(universe, lang, playerID, accounts, servers); err == nil {
+		return acc, server, nil
+	}
+	if lang == "ba" {
+		lang = "yu"
+	}
+	for _, a := range accounts {
+		if a.Server.Language != lang || a.Name != playerName {
+			continue
+		}
+		for _, s := range servers {
+			if s.Language == a.Server.Language && s.Number == a.Server.Number {
+				return a, s, nil
+			}
+		}
+	}
+	return account{}, Server{}, ErrAccountNotFound
+}
+
+// findMovedAccount finds the account of the player on the game server host the universe moved to.
+// The account with the same player ID or name is preferred, then any account on that server.
+func findMovedAccount(host, playerName string, playerID int64, accounts []account, servers []Server) (account, Server, error) {
+	server, ok := findServerByHost(servers, host)
+	if !ok {
+		return account{}, Server{}, errors.New("server " + host + " not found in the lobby")
+	}
+	found := false
+	var res account
+	for _, a := range accounts {
+		if a.Server.Language != server.Language || a.Server.Number != server.Number {
+			continue
+		}
+		if (playerID != 0 && a.ID == playerID) || (playerName != "" && a.Name == playerName) {
+			return a, server, nil
+		}
+		if !found {
+			found, res = true, a
+		}
+	}
+	if !found {
+		return account{}, Server{}, ErrAccountNotFound
+	}
+	return res, server, nil
+}
+
+// remapCelestials matches the old and new celestials by coordinates
+func remapCelestials(oldCelestials, newCelestials []Celestial) (map[CelestialID]CelestialID, []CelestialID) {
+	byCoord := make(map[Coordinate]CelestialID)
+	for _, c := range newCelestials {
+		byCoord[c.GetCoordinate()] = c.GetID()
+	}
+	mapping := make(map[CelestialID]CelestialID)
+	unmapped := make([]CelestialID, 0)
+	for _, c := range oldCelestials {
+		if newID, ok := byCoord[c.GetCoordinate()]; ok {
+			mapping[c.GetID()] = newID
+		} else {
+			unmapped = append(unmapped, c.GetID())
+		}
+	}
+	return mapping, unmapped
+}
+
+func (b *OGame) migrateUniverse() (UniverseMigration, error) {
+	return b.migrateUniverseTo("")
+}
+
+// migrateUniverseTo same as migrateUniverse, host is the game server the universe moved to, empty if unknown
+func (b *OGame) migrateUniverseTo(host string) (UniverseMigration, error) {
+	res := UniverseMigration{
+		OldServer:    b.server,
+		OldServerURL: b.serverURL,
+	}
+	oldCelestials := b.getCachedCelestials()
+	playerName := b.Player.PlayerName
+
+	token := b.GetSessionCredentials().BearerToken
+	if token == "" {
+		return res, errors.New("no bearer token available, login first")
+	}
+	accounts, err := getUserAccounts(b, token)
+	if err != nil {
+		return res, err
+	}
+	servers, err := getServers(b)
+	if err != nil {
+		return res, err
+	}
+	var acc account
+	var server Server
+	if host != "" {
+		acc, server, err = findMovedAccount(host, playerName, b.playerID, accounts, servers)
+	} else {
+		acc, server, err = findMergedAccount(b.Universe, b.language, playerName, b.playerID, accounts, servers)
+	}
+	if err != nil {
+		return res, err
+	}
+	b.Universe = server.Name
+	if b.playerID != 0 {
+		b.playerID = acc.ID
+	}
+	if err := b.loginWrapper(func() (bool, error) { return b.loginWithBearerToken(token) }); err != nil {
+		return res, err
+	}
+
+	res.NewServer = b.server
+	res.NewServerURL = b.serverURL
+	res.CelestialIDs, res.Unmapped = remapCelestials(oldCelestials, b.getCachedCelestials())
+
+	for alias, id := range b.GetCelestialAliases() {
+		b.SetCelestialAlias(alias, res.RemapID(id))
+	}
+	b.migratedCallbacksMu.RLock()
+	callbacks := b.migratedCallbacks
+	b.migratedCallbacksMu.RUnlock()
+	for _, clb := range callbacks {
+		clb(res)
+	}
+	return res, nil
+}
+
+// OnUniverseMigrated register a callback that is called after a universe migration,
+// so stored celestial references can be rewritten with UniverseMigration.RemapID
+func (b *OGame) OnUniverseMigrated(clb func(UniverseMigration)) {
+	b.migratedCallbacksMu.Lock()
+	defer b.migratedCallbacksMu.Unlock()
+	b.migratedCallbacks = append(b.migratedCallbacks, clb)
+}
+
+// MigrateUniverse re-resolves the universe through the lobby after a server merge, logs in the new server
+// and remaps the celestial ids (aliases and registered storages) by coordinates.
+func (b *OGame) MigrateUniverse() (UniverseMigration, error) {
+	return b.WithPriority(Normal).MigrateUniverse()
+}
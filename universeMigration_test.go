@@ -0,0 +1,79 @@
+package ogame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMergedAccount(t *testing.T) {
+	servers := []Server{
+		{Language: "en", Number: 150, Name: "Target"},
+		{Language: "fr", Number: 150, Name: "Target"},
+	}
+	acc := account{ID: 123, Name: "Bob"}
+	acc.Server.Language = "en"
+	acc.Server.Number = 150
+	accounts := []account{acc}
+
+	// "Source" was merged into "Target"
+	foundAcc, server, err := findMergedAccount("Source", "en", "Bob", 0, accounts, servers)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), foundAcc.ID)
+	assert.Equal(t, "Target", server.Name)
+
+	_, server, err = findMergedAccount("Target", "en", "", 0, accounts, servers)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), server.Number)
+
+	_, _, err = findMergedAccount("Source", "fr", "Bob", 0, accounts, servers)
+	assert.Equal(t, ErrAccountNotFound, err)
+}
+
+func TestFindMovedAccount(t *testing.T) {
+	servers := []Server{
+		{Language: "en", Number: 150, Name: "Target"},
+		{Language: "en", Number: 151, Name: "Other"},
+	}
+	newAccount := func(id int64, name string, number int64) account {
+		acc := account{ID: id, Name: name}
+		acc.Server.Language = "en"
+		acc.Server.Number = number
+		return acc
+	}
+	accounts := []account{newAccount(1, "Alice", 151), newAccount(2, "Carol", 150), newAccount(3, "Bob", 150)}
+
+	acc, server, err := findMovedAccount("s150-en.ogame.gameforge.com", "Bob", 0, accounts, servers)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), acc.ID)
+	assert.Equal(t, "Target", server.Name)
+
+	// The player was renamed, first account of the server
+	acc, _, err = findMovedAccount("s150-en.ogame.gameforge.com", "Dave", 0, accounts, servers)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), acc.ID)
+
+	_, _, err = findMovedAccount("s151-en.ogame.gameforge.com", "Bob", 0, accounts[1:], servers)
+	assert.Equal(t, ErrAccountNotFound, err)
+	_, _, err = findMovedAccount("s152-en.ogame.gameforge.com", "Bob", 0, accounts, servers)
+	assert.EqualError(t, err, "server s152-en.ogame.gameforge.com not found in the lobby")
+}
+
+func TestRemapCelestials(t *testing.T) {
+	oldCelestials := []Celestial{
+		Planet{ID: 1, Coordinate: Coordinate{1, 2, 3, PlanetType}},
+		Moon{ID: 2, Coordinate: Coordinate{1, 2, 3, MoonType}},
+		Planet{ID: 3, Coordinate: Coordinate{4, 5, 6, PlanetType}},
+	}
+	newCelestials := []Celestial{
+		Planet{ID: 10, Coordinate: Coordinate{1, 2, 3, PlanetType}},
+		Moon{ID: 20, Coordinate: Coordinate{1, 2, 3, MoonType}},
+	}
+	mapping, unmapped := remapCelestials(oldCelestials, newCelestials)
+	assert.Equal(t, map[CelestialID]CelestialID{1: 10, 2: 20}, mapping)
+	assert.Equal(t, []CelestialID{3}, unmapped)
+
+	m := UniverseMigration{CelestialIDs: mapping}
+	assert.Equal(t, CelestialID(20), m.RemapID(2))
+	assert.Equal(t, CelestialID(99), m.RemapID(99))
+}